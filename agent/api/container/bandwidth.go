@@ -0,0 +1,103 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// BandwidthLimitsLabel is the docker label holding the JSON configuration of the network
+	// bandwidth limits of a container, e.g. {"ingress":"100mbit","egress":"20mbit"}
+	BandwidthLimitsLabel = "com.amazonaws.ecs.bandwidth-limits"
+
+	// minBandwidthBitsPerSecond is the lowest bandwidth limit accepted, lower limits can't carry
+	// a single packet of the maximum size in a reasonable time
+	minBandwidthBitsPerSecond = 8000
+)
+
+// bandwidthRateRegex matches the rates of the bandwidth limits, in the units used by tc
+var bandwidthRateRegex = regexp.MustCompile(`^([0-9]+)(bit|kbit|mbit|gbit)?$`)
+
+var bandwidthRateUnits = map[string]uint64{
+	"":     1,
+	"bit":  1,
+	"kbit": 1000,
+	"mbit": 1000 * 1000,
+	"gbit": 1000 * 1000 * 1000,
+}
+
+// BandwidthLimits are the network bandwidth limits of a container. In awsvpc network mode, the containers
+// of a task share the task network interface, and the limits apply to the whole task. In bridge network
+// mode, the limits apply to the virtual interface of the container.
+type BandwidthLimits struct {
+	// IngressBitsPerSecond limits the traffic received by the container, it's not limited if it's 0
+	IngressBitsPerSecond uint64 `json:"ingressBitsPerSecond,omitempty"`
+	// EgressBitsPerSecond limits the traffic sent by the container, it's not limited if it's 0
+	EgressBitsPerSecond uint64 `json:"egressBitsPerSecond,omitempty"`
+}
+
+// ParseBandwidthLimits parses the bandwidth limits from the labels of a container. It returns nil if
+// the container doesn't have bandwidth limits.
+func ParseBandwidthLimits(labels map[string]string) (*BandwidthLimits, error) {
+	value, ok := labels[BandwidthLimitsLabel]
+	if !ok {
+		return nil, nil
+	}
+	var config struct {
+		Ingress string `json:"ingress"`
+		Egress  string `json:"egress"`
+	}
+	if err := json.Unmarshal([]byte(value), &config); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse %s label", BandwidthLimitsLabel)
+	}
+	if config.Ingress == "" && config.Egress == "" {
+		return nil, errors.Errorf("invalid %s label: ingress or egress is required", BandwidthLimitsLabel)
+	}
+
+	limits := &BandwidthLimits{}
+	var err error
+	if limits.IngressBitsPerSecond, err = parseBandwidthRate(config.Ingress); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s label", BandwidthLimitsLabel)
+	}
+	if limits.EgressBitsPerSecond, err = parseBandwidthRate(config.Egress); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s label", BandwidthLimitsLabel)
+	}
+	return limits, nil
+}
+
+// parseBandwidthRate parses a rate such as "100mbit" into bits per second. An empty rate is 0.
+func parseBandwidthRate(rate string) (uint64, error) {
+	if rate == "" {
+		return 0, nil
+	}
+	matches := bandwidthRateRegex.FindStringSubmatch(strings.ToLower(rate))
+	if matches == nil {
+		return 0, errors.Errorf("invalid rate %q", rate)
+	}
+	value, err := strconv.ParseUint(matches[1], 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid rate %q", rate)
+	}
+	bitsPerSecond := value * bandwidthRateUnits[matches[2]]
+	if bitsPerSecond < minBandwidthBitsPerSecond {
+		return 0, errors.Errorf("rate %q is lower than %dbit", rate, minBandwidthBitsPerSecond)
+	}
+	return bitsPerSecond, nil
+}
@@ -0,0 +1,61 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBandwidthLimits(t *testing.T) {
+	limits, err := ParseBandwidthLimits(map[string]string{
+		BandwidthLimitsLabel: `{"ingress":"100mbit","egress":"1Gbit"}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &BandwidthLimits{
+		IngressBitsPerSecond: 100000000,
+		EgressBitsPerSecond:  1000000000,
+	}, limits)
+
+	limits, err = ParseBandwidthLimits(map[string]string{BandwidthLimitsLabel: `{"egress":"64kbit"}`})
+	require.NoError(t, err)
+	assert.Equal(t, &BandwidthLimits{EgressBitsPerSecond: 64000}, limits)
+}
+
+func TestParseBandwidthLimitsNoLabel(t *testing.T) {
+	limits, err := ParseBandwidthLimits(map[string]string{"foo": "bar"})
+	require.NoError(t, err)
+	assert.Nil(t, limits)
+}
+
+func TestParseBandwidthLimitsInvalid(t *testing.T) {
+	for _, value := range []string{
+		`{`,
+		`{}`,
+		`{"ingress":"fast"}`,
+		`{"ingress":"100mbps"}`,
+		`{"egress":"-1mbit"}`,
+		`{"egress":"1kbit"}`,
+	} {
+		t.Run(value, func(t *testing.T) {
+			_, err := ParseBandwidthLimits(map[string]string{BandwidthLimitsLabel: value})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -51,6 +51,9 @@ const (
 	// DockerHealthCheckType is the type of container health check provided by docker
 	DockerHealthCheckType = "docker"
 
+	// maxHealthCheckHistoryLength is the number of health check results kept per container
+	maxHealthCheckHistoryLength = 10
+
 	// AuthTypeECR is to use image pull auth over ECR
 	AuthTypeECR = "ecr"
 
@@ -125,6 +128,20 @@ type HealthStatus struct {
 	ExitCode int `json:"exitCode,omitempty"`
 	// Output is the output of health check
 	Output string `json:"output,omitempty"`
+	// Results are the latest health check results returned by docker, oldest first
+	Results []HealthCheckResult `json:"-"`
+}
+
+// HealthCheckResult is the result of a single health check probe
+type HealthCheckResult struct {
+	// Start is the time the health check probe started
+	Start time.Time `json:"start"`
+	// End is the time the health check probe finished
+	End time.Time `json:"end"`
+	// ExitCode is the exit code of the health check probe
+	ExitCode int `json:"exitCode"`
+	// Output is the truncated output of the health check probe
+	Output string `json:"output,omitempty"`
 }
 
 type ManagedAgentState struct {
@@ -214,14 +231,34 @@ type Container struct {
 	// HealthCheckType is the mechanism to use for the container health check
 	// currently it only supports 'DOCKER'
 	HealthCheckType string `json:"healthCheckType,omitempty"`
+	// HealthProbe is the configuration of the health probe run by the agent when
+	// HealthCheckType is AgentHealthCheckType
+	HealthProbe *HealthProbe `json:"healthProbe,omitempty"`
+	// BandwidthLimits are the network bandwidth limits of the container, configured with a docker label
+	BandwidthLimits *BandwidthLimits `json:"bandwidthLimits,omitempty"`
+	// HostResourceRequests are the host resources the container requests with docker labels, which are
+	// accounted for when admitting the task
+	HostResourceRequests HostResourceRequests `json:"hostResourceRequests"`
+	// MemoryControls are the cgroup v2 memory controls of the container, configured with docker labels
+	MemoryControls *MemoryControls `json:"memoryControls,omitempty"`
 	// Health contains the health check information of container health check
 	Health HealthStatus `json:"-"`
+	// HealthHistoryUnsafe contains the latest health check results of the container, oldest first
+	//
+	// NOTE: Do not access HealthHistoryUnsafe directly. Instead, use `GetHealthHistory`.
+	HealthHistoryUnsafe []HealthCheckResult `json:"healthHistory,omitempty"`
+	// ReadinessConditionsMetUnsafe lists the readiness conditions of the dependent containers which
+	// the container has met, e.g. "PORT:8080"
+	//
+	// NOTE: Do not access ReadinessConditionsMetUnsafe directly. Instead, use `IsReadinessConditionMet`
+	// and `SetReadinessConditionMet`.
+	ReadinessConditionsMetUnsafe []string `json:"readinessConditionsMet,omitempty"`
 	// LogsAuthStrategy specifies how the logs driver for the container will be
 	// authenticated
 	LogsAuthStrategy string
 	// StartTimeout specifies the time value after which if a container has a dependency
-	// on another container and the dependency conditions are 'SUCCESS', 'COMPLETE', 'HEALTHY',
-	// then that dependency will not be resolved.
+	// on another container and the dependency conditions are 'SUCCESS', 'COMPLETE', 'HEALTHY'
+	// or a readiness condition ('PORT:<port>', 'FILE:<path>'), then that dependency will not be resolved.
 	StartTimeout uint
 	// StopTimeout specifies the time value to be passed as StopContainer api call
 	StopTimeout uint
@@ -852,18 +889,27 @@ func (c *Container) GetNetworkMode() string {
 }
 
 // HealthStatusShouldBeReported returns true if the health check is defined in
-// the task definition
+// the task definition or run by the agent with a health probe
 func (c *Container) HealthStatusShouldBeReported() bool {
-	return c.HealthCheckType == DockerHealthCheckType
+	return c.HealthCheckType == DockerHealthCheckType || c.HasAgentHealthProbe()
 }
 
-// SetHealthStatus sets the container health status
-func (c *Container) SetHealthStatus(health HealthStatus) {
+// HasAgentHealthProbe returns true if the health of the container is checked by the agent
+// with a health probe instead of by docker
+func (c *Container) HasAgentHealthProbe() bool {
+	return c.HealthCheckType == AgentHealthCheckType && c.HealthProbe != nil
+}
+
+// SetHealthStatus sets the container health status and adds the health check results
+// that are new to the health history of the container. It returns true if the health
+// status changed.
+func (c *Container) SetHealthStatus(health HealthStatus) bool {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
+	c.addHealthCheckResults(health.Results)
 	if c.Health.Status == health.Status {
-		return
+		return false
 	}
 
 	c.Health.Status = health.Status
@@ -874,6 +920,34 @@ func (c *Container) SetHealthStatus(health HealthStatus) {
 	if c.Health.Status == apicontainerstatus.ContainerUnhealthy {
 		c.Health.ExitCode = health.ExitCode
 	}
+	return true
+}
+
+// addHealthCheckResults appends the results that started after the newest result in the
+// health history, keeping at most maxHealthCheckHistoryLength results.
+func (c *Container) addHealthCheckResults(results []HealthCheckResult) {
+	var newest time.Time
+	if len(c.HealthHistoryUnsafe) > 0 {
+		newest = c.HealthHistoryUnsafe[len(c.HealthHistoryUnsafe)-1].Start
+	}
+	for _, result := range results {
+		if result.Start.After(newest) {
+			c.HealthHistoryUnsafe = append(c.HealthHistoryUnsafe, result)
+			newest = result.Start
+		}
+	}
+	if len(c.HealthHistoryUnsafe) > maxHealthCheckHistoryLength {
+		c.HealthHistoryUnsafe = append([]HealthCheckResult(nil),
+			c.HealthHistoryUnsafe[len(c.HealthHistoryUnsafe)-maxHealthCheckHistoryLength:]...)
+	}
+}
+
+// GetHealthHistory returns a copy of the latest health check results of the container, oldest first
+func (c *Container) GetHealthHistory() []HealthCheckResult {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return append([]HealthCheckResult(nil), c.HealthHistoryUnsafe...)
 }
 
 // GetHealthStatus returns the container health information
@@ -896,6 +970,9 @@ func (c *Container) GetHealthStatus() HealthStatus {
 func (c *Container) BuildContainerDependency(contName string,
 	satisfiedStatus apicontainerstatus.ContainerStatus,
 	dependentStatus apicontainerstatus.ContainerStatus) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
 	contDep := ContainerDependency{
 		ContainerName:   contName,
 		SatisfiedStatus: satisfiedStatus,
@@ -908,6 +985,30 @@ func (c *Container) BuildContainerDependency(contName string,
 	c.TransitionDependenciesMap[dependentStatus] = deps
 }
 
+// GetTransitionDependencies returns a copy of the transition dependencies of the container.
+func (c *Container) GetTransitionDependencies() TransitionDependenciesMap {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	dependencies := make(TransitionDependenciesMap, len(c.TransitionDependenciesMap))
+	for status, set := range c.TransitionDependenciesMap {
+		dependencies[status] = TransitionDependencySet{
+			ContainerDependencies: append([]ContainerDependency(nil), set.ContainerDependencies...),
+			ResourceDependencies:  append([]ResourceDependency(nil), set.ResourceDependencies...),
+		}
+	}
+	return dependencies
+}
+
+// GetSteadyStateDependencies returns the names of the containers that must reach their steady state
+// before the container is created.
+func (c *Container) GetSteadyStateDependencies() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.SteadyStateDependencies
+}
+
 // BuildResourceDependency adds a new resource dependency by taking in the required status
 // of the resource that satisfies the dependency and the dependent container status,
 // whose transition is dependent on the resource.
@@ -916,6 +1017,8 @@ func (c *Container) BuildContainerDependency(contName string,
 func (c *Container) BuildResourceDependency(resourceName string,
 	requiredStatus resourcestatus.ResourceStatus,
 	dependentStatus apicontainerstatus.ContainerStatus) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
 	resourceDep := ResourceDependency{
 		Name:           resourceName,
@@ -1214,6 +1317,33 @@ func (c *Container) AddContainerDependency(name string, condition string) {
 	})
 }
 
+// IsReadinessConditionMet returns whether the container has met the readiness condition.
+func (c *Container) IsReadinessConditionMet(condition string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, met := range c.ReadinessConditionsMetUnsafe {
+		if met == condition {
+			return true
+		}
+	}
+	return false
+}
+
+// SetReadinessConditionMet records that the container has met the readiness condition. A met
+// condition stays met for the lifetime of the container.
+func (c *Container) SetReadinessConditionMet(condition string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, met := range c.ReadinessConditionsMetUnsafe {
+		if met == condition {
+			return
+		}
+	}
+	c.ReadinessConditionsMetUnsafe = append(c.ReadinessConditionsMetUnsafe, condition)
+}
+
 // GetLogDriver returns the log driver used by the container.
 func (c *Container) GetLogDriver() string {
 	c.lock.RLock()
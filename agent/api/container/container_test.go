@@ -253,6 +253,36 @@ func TestSetHealthStatus(t *testing.T) {
 	assert.NotEqual(t, health3.Since, health2.Since)
 }
 
+func TestSetHealthStatusHealthHistory(t *testing.T) {
+	container := Container{}
+	start := time.Now()
+	result := func(i int) HealthCheckResult {
+		return HealthCheckResult{
+			Start:    start.Add(time.Duration(i) * time.Second),
+			End:      start.Add(time.Duration(i)*time.Second + time.Millisecond),
+			ExitCode: i % 2,
+		}
+	}
+
+	assert.True(t, container.SetHealthStatus(HealthStatus{
+		Status:  apicontainerstatus.ContainerHealthy,
+		Results: []HealthCheckResult{result(0), result(1)},
+	}))
+	// Results already in the history are not added again, even if the status doesn't change
+	assert.False(t, container.SetHealthStatus(HealthStatus{
+		Status:  apicontainerstatus.ContainerHealthy,
+		Results: []HealthCheckResult{result(1), result(2)},
+	}))
+	assert.Equal(t, []HealthCheckResult{result(0), result(1), result(2)}, container.GetHealthHistory())
+
+	var results []HealthCheckResult
+	for i := 3; i < 3+maxHealthCheckHistoryLength; i++ {
+		results = append(results, result(i))
+	}
+	container.SetHealthStatus(HealthStatus{Status: apicontainerstatus.ContainerUnhealthy, Results: results})
+	assert.Equal(t, results, container.GetHealthHistory())
+}
+
 func TestHealthStatusShouldBeReported(t *testing.T) {
 	container := Container{}
 	assert.False(t, container.HealthStatusShouldBeReported(), "Health status of container that does not have HealthCheckType set should not be reported")
@@ -288,6 +318,19 @@ func TestBuildResourceDependency(t *testing.T) {
 	assert.Equal(t, resourcestatus.ResourceStatus(1), resourceDep[0].GetRequiredStatus())
 }
 
+func TestGetTransitionDependencies(t *testing.T) {
+	container := Container{TransitionDependenciesMap: make(map[apicontainerstatus.ContainerStatus]TransitionDependencySet)}
+	container.BuildContainerDependency("dep", apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerCreated)
+
+	dependencies := container.GetTransitionDependencies()
+	require.Len(t, dependencies[apicontainerstatus.ContainerCreated].ContainerDependencies, 1)
+
+	// The copy isn't affected by the dependencies added afterwards.
+	container.BuildContainerDependency("other", apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerCreated)
+	assert.Len(t, dependencies[apicontainerstatus.ContainerCreated].ContainerDependencies, 1)
+	assert.Len(t, container.GetTransitionDependencies()[apicontainerstatus.ContainerCreated].ContainerDependencies, 2)
+}
+
 func TestShouldPullWithASMAuth(t *testing.T) {
 	container := Container{
 		Name:  "myName",
@@ -0,0 +1,140 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// AgentHealthCheckType is the type of container health check run by the agent with a health probe
+	AgentHealthCheckType = "agent"
+
+	// HealthProbeLabel is the docker label holding the JSON configuration of the agent health probe
+	// of a container
+	HealthProbeLabel = "com.amazonaws.ecs.health-probe"
+
+	// HealthProbeTypeHTTP probes the container with an HTTP GET request
+	HealthProbeTypeHTTP = "http"
+	// HealthProbeTypeTCP probes the container by opening a TCP connection
+	HealthProbeTypeTCP = "tcp"
+	// HealthProbeTypeGRPC probes the container with the gRPC health checking protocol
+	HealthProbeTypeGRPC = "grpc"
+
+	// The defaults match the defaults of ECS container health checks
+	defaultHealthProbeIntervalSeconds = 30
+	defaultHealthProbeTimeoutSeconds  = 5
+	defaultHealthProbeRetries         = 3
+)
+
+// HealthProbe is the configuration of a health probe run by the agent against a container. The
+// probe connects to the port on the loopback address of the network namespace of the container.
+type HealthProbe struct {
+	// Type is the type of the probe, one of "http", "tcp" or "grpc"
+	Type string `json:"type"`
+	// Port is the container port to probe
+	Port uint16 `json:"port"`
+	// Path is the path requested by HTTP probes
+	Path string `json:"path,omitempty"`
+	// ExpectedStatus lists the HTTP status codes considered healthy. Any 2xx or 3xx status is
+	// considered healthy if it's empty.
+	ExpectedStatus []int `json:"expectedStatus,omitempty"`
+	// Service is the service name sent by gRPC probes
+	Service string `json:"service,omitempty"`
+	// Interval is the time in seconds between two probes
+	Interval int `json:"interval,omitempty"`
+	// Timeout is the time in seconds after which a probe fails
+	Timeout int `json:"timeout,omitempty"`
+	// Retries is the number of consecutive failed probes after which the container is unhealthy
+	Retries int `json:"retries,omitempty"`
+	// StartPeriod is the time in seconds after the container starts during which failed probes
+	// are not counted towards Retries
+	StartPeriod int `json:"startPeriod,omitempty"`
+}
+
+// ParseHealthProbe parses the health probe configuration from the labels of a container. It
+// returns nil if the container doesn't have a health probe.
+func ParseHealthProbe(labels map[string]string) (*HealthProbe, error) {
+	value, ok := labels[HealthProbeLabel]
+	if !ok {
+		return nil, nil
+	}
+	probe := &HealthProbe{
+		Interval: defaultHealthProbeIntervalSeconds,
+		Timeout:  defaultHealthProbeTimeoutSeconds,
+		Retries:  defaultHealthProbeRetries,
+	}
+	if err := json.Unmarshal([]byte(value), probe); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse %s label", HealthProbeLabel)
+	}
+	if err := probe.validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s label", HealthProbeLabel)
+	}
+	return probe, nil
+}
+
+func (probe *HealthProbe) validate() error {
+	switch probe.Type {
+	case HealthProbeTypeHTTP, HealthProbeTypeTCP, HealthProbeTypeGRPC:
+	default:
+		return errors.Errorf("unsupported probe type %q", probe.Type)
+	}
+	if probe.Port == 0 {
+		return errors.New("port is required")
+	}
+	for _, status := range probe.ExpectedStatus {
+		if status < 100 || status > 599 {
+			return errors.Errorf("invalid expected status %d", status)
+		}
+	}
+	if probe.Interval <= 0 || probe.Timeout <= 0 || probe.Retries <= 0 || probe.StartPeriod < 0 {
+		return errors.New("interval, timeout and retries must be positive and startPeriod must not be negative")
+	}
+	if probe.Timeout > probe.Interval {
+		return errors.New("timeout must not be greater than interval")
+	}
+	return nil
+}
+
+// IntervalDuration returns the time between two probes
+func (probe *HealthProbe) IntervalDuration() time.Duration {
+	return time.Duration(probe.Interval) * time.Second
+}
+
+// TimeoutDuration returns the time after which a probe fails
+func (probe *HealthProbe) TimeoutDuration() time.Duration {
+	return time.Duration(probe.Timeout) * time.Second
+}
+
+// StartPeriodDuration returns the grace period after the container starts
+func (probe *HealthProbe) StartPeriodDuration() time.Duration {
+	return time.Duration(probe.StartPeriod) * time.Second
+}
+
+// IsExpectedStatus returns whether the HTTP status code is considered healthy
+func (probe *HealthProbe) IsExpectedStatus(status int) bool {
+	if len(probe.ExpectedStatus) == 0 {
+		return status >= http.StatusOK && status < http.StatusBadRequest
+	}
+	for _, expected := range probe.ExpectedStatus {
+		if status == expected {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHealthProbe(t *testing.T) {
+	probe, err := ParseHealthProbe(map[string]string{
+		HealthProbeLabel: `{"type":"http","port":8080,"path":"/health","expectedStatus":[200,204],"interval":10}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &HealthProbe{
+		Type:           HealthProbeTypeHTTP,
+		Port:           8080,
+		Path:           "/health",
+		ExpectedStatus: []int{200, 204},
+		Interval:       10,
+		Timeout:        defaultHealthProbeTimeoutSeconds,
+		Retries:        defaultHealthProbeRetries,
+	}, probe)
+	assert.True(t, probe.IsExpectedStatus(204))
+	assert.False(t, probe.IsExpectedStatus(301))
+}
+
+func TestParseHealthProbeNoLabel(t *testing.T) {
+	probe, err := ParseHealthProbe(map[string]string{"foo": "bar"})
+	assert.NoError(t, err)
+	assert.Nil(t, probe)
+}
+
+func TestParseHealthProbeInvalid(t *testing.T) {
+	testCases := map[string]string{
+		"invalid json":            `{`,
+		"unsupported type":        `{"type":"udp","port":53}`,
+		"missing port":            `{"type":"tcp"}`,
+		"invalid expected status": `{"type":"http","port":80,"expectedStatus":[42]}`,
+		"negative retries":        `{"type":"tcp","port":80,"retries":-1}`,
+		"timeout above interval":  `{"type":"tcp","port":80,"interval":5,"timeout":10}`,
+	}
+	for name, label := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseHealthProbe(map[string]string{HealthProbeLabel: label})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestHealthProbeDefaultExpectedStatus(t *testing.T) {
+	probe := &HealthProbe{}
+	assert.True(t, probe.IsExpectedStatus(200))
+	assert.True(t, probe.IsExpectedStatus(302))
+	assert.False(t, probe.IsExpectedStatus(404))
+	assert.False(t, probe.IsExpectedStatus(503))
+}
+
+func TestHealthStatusShouldBeReportedAgentHealthProbe(t *testing.T) {
+	container := &Container{HealthCheckType: AgentHealthCheckType}
+	assert.False(t, container.HealthStatusShouldBeReported())
+	container.HealthProbe = &HealthProbe{Type: HealthProbeTypeTCP, Port: 80}
+	assert.True(t, container.HealthStatusShouldBeReported())
+	assert.True(t, container.HasAgentHealthProbe())
+}
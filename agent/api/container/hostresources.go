@@ -0,0 +1,63 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// EphemeralStorageLabel is the docker label holding the space in MiB the container uses on the
+	// filesystem of the docker data root, for its writable layer, logs and local volumes
+	EphemeralStorageLabel = "com.amazonaws.ecs.ephemeral-storage"
+	// HugePagesLabel is the docker label holding the memory in MiB the container uses in huge pages
+	HugePagesLabel = "com.amazonaws.ecs.hugepages"
+)
+
+// HostResourceRequests are the host resources a container requests with docker labels, as the task payloads
+// have no fields for them. The ephemeral storage of local task definitions is passed on as a label.
+type HostResourceRequests struct {
+	// EphemeralStorageMiB is the space the container uses on the filesystem of the docker data root
+	EphemeralStorageMiB int32 `json:"ephemeralStorageMiB,omitempty"`
+	// HugePagesMiB is the memory the container uses in huge pages
+	HugePagesMiB int32 `json:"hugePagesMiB,omitempty"`
+}
+
+// ParseHostResourceRequests parses the host resources requested with the labels of a container.
+func ParseHostResourceRequests(labels map[string]string) (HostResourceRequests, error) {
+	var requests HostResourceRequests
+	var err error
+	if requests.EphemeralStorageMiB, err = parseMiBLabel(labels, EphemeralStorageLabel); err != nil {
+		return HostResourceRequests{}, err
+	}
+	if requests.HugePagesMiB, err = parseMiBLabel(labels, HugePagesLabel); err != nil {
+		return HostResourceRequests{}, err
+	}
+	return requests, nil
+}
+
+// parseMiBLabel parses a label holding an amount in MiB. A missing label is 0.
+func parseMiBLabel(labels map[string]string, label string) (int32, error) {
+	value, ok := labels[label]
+	if !ok {
+		return 0, nil
+	}
+	mib, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || mib < 0 {
+		return 0, errors.Errorf("invalid %s label %q, it must be a number of MiB", label, value)
+	}
+	return int32(mib), nil
+}
@@ -0,0 +1,62 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHostResourceRequests(t *testing.T) {
+	testCases := []struct {
+		name          string
+		labels        map[string]string
+		expected      HostResourceRequests
+		expectedError string
+	}{
+		{
+			name:   "no labels",
+			labels: map[string]string{"foo": "bar"},
+		},
+		{
+			name:     "ephemeral storage and huge pages",
+			labels:   map[string]string{EphemeralStorageLabel: "10240", HugePagesLabel: "64"},
+			expected: HostResourceRequests{EphemeralStorageMiB: 10240, HugePagesMiB: 64},
+		},
+		{
+			name:          "invalid size",
+			labels:        map[string]string{EphemeralStorageLabel: "10GiB"},
+			expectedError: `invalid com.amazonaws.ecs.ephemeral-storage label "10GiB", it must be a number of MiB`,
+		},
+		{
+			name:          "negative size",
+			labels:        map[string]string{HugePagesLabel: "-1"},
+			expectedError: `invalid com.amazonaws.ecs.hugepages label "-1", it must be a number of MiB`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests, err := ParseHostResourceRequests(tc.labels)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, requests)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MemoryHighLabel is the docker label holding the memory in MiB above which the processes of the
+	// container are throttled and their memory reclaimed, the memory.high of cgroup v2
+	MemoryHighLabel = "com.amazonaws.ecs.memory-high"
+	// MemorySwapMaxLabel is the docker label holding the swap in MiB the container may use
+	MemorySwapMaxLabel = "com.amazonaws.ecs.memory-swap-max"
+	// MemoryOOMGroupLabel is the docker label holding whether the OOM killer kills all the processes
+	// of the task at once instead of a single process, the memory.oom.group of cgroup v2
+	MemoryOOMGroupLabel = "com.amazonaws.ecs.memory-oom-group"
+)
+
+// MemoryControls are the cgroup v2 memory controls on top of the hard memory limit and the memory
+// reservation. A nil control is left to the default of the kernel.
+type MemoryControls struct {
+	// HighMiB is the memory above which processes are throttled and their memory reclaimed
+	HighMiB *int64 `json:"highMiB,omitempty"`
+	// SwapMaxMiB is the swap processes may use
+	SwapMaxMiB *int64 `json:"swapMaxMiB,omitempty"`
+	// OOMGroup kills all the processes at once on OOM instead of a single process
+	OOMGroup *bool `json:"oomGroup,omitempty"`
+}
+
+// ParseMemoryControls parses the memory controls from the labels of a container. It returns nil if
+// the container doesn't have memory controls.
+func ParseMemoryControls(labels map[string]string) (*MemoryControls, error) {
+	controls := &MemoryControls{}
+	var err error
+	if controls.HighMiB, err = parseOptionalMiBLabel(labels, MemoryHighLabel); err != nil {
+		return nil, err
+	}
+	if controls.SwapMaxMiB, err = parseOptionalMiBLabel(labels, MemorySwapMaxLabel); err != nil {
+		return nil, err
+	}
+	if value, ok := labels[MemoryOOMGroupLabel]; ok {
+		oomGroup, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.Errorf("invalid %s label %q, it must be true or false", MemoryOOMGroupLabel, value)
+		}
+		controls.OOMGroup = &oomGroup
+	}
+	if controls.HighMiB == nil && controls.SwapMaxMiB == nil && controls.OOMGroup == nil {
+		return nil, nil
+	}
+	return controls, nil
+}
+
+// parseOptionalMiBLabel parses a label holding an amount in MiB. A missing label is nil.
+func parseOptionalMiBLabel(labels map[string]string, label string) (*int64, error) {
+	value, ok := labels[label]
+	if !ok {
+		return nil, nil
+	}
+	mib, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || mib < 0 {
+		return nil, errors.Errorf("invalid %s label %q, it must be a number of MiB", label, value)
+	}
+	return &mib, nil
+}
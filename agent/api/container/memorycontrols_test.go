@@ -0,0 +1,78 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMemoryControls(t *testing.T) {
+	testCases := []struct {
+		name          string
+		labels        map[string]string
+		expected      *MemoryControls
+		expectedError string
+	}{
+		{
+			name:   "no labels",
+			labels: map[string]string{"foo": "bar"},
+		},
+		{
+			name: "all controls",
+			labels: map[string]string{
+				MemoryHighLabel:     "768",
+				MemorySwapMaxLabel:  "0",
+				MemoryOOMGroupLabel: "true",
+			},
+			expected: &MemoryControls{HighMiB: aws.Int64(768), SwapMaxMiB: aws.Int64(0), OOMGroup: aws.Bool(true)},
+		},
+		{
+			name:     "swap only",
+			labels:   map[string]string{MemorySwapMaxLabel: "1024"},
+			expected: &MemoryControls{SwapMaxMiB: aws.Int64(1024)},
+		},
+		{
+			name:          "invalid memory high",
+			labels:        map[string]string{MemoryHighLabel: "1GiB"},
+			expectedError: `invalid com.amazonaws.ecs.memory-high label "1GiB", it must be a number of MiB`,
+		},
+		{
+			name:          "negative swap",
+			labels:        map[string]string{MemorySwapMaxLabel: "-1"},
+			expectedError: `invalid com.amazonaws.ecs.memory-swap-max label "-1", it must be a number of MiB`,
+		},
+		{
+			name:          "invalid oom group",
+			labels:        map[string]string{MemoryOOMGroupLabel: "always"},
+			expectedError: `invalid com.amazonaws.ecs.memory-oom-group label "always", it must be true or false`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			controls, err := ParseMemoryControls(tc.labels)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, controls)
+		})
+	}
+}
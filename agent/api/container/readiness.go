@@ -0,0 +1,109 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+import (
+	"encoding/json"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ReadinessConditionPortPrefix prefixes the dependency conditions satisfied when the dependency
+	// container listens on a TCP port in its network namespace, e.g. "PORT:8080"
+	ReadinessConditionPortPrefix = "PORT:"
+	// ReadinessConditionFilePrefix prefixes the dependency conditions satisfied when a path exists
+	// in the filesystem of the dependency container, e.g. "FILE:/shared/ready"
+	ReadinessConditionFilePrefix = "FILE:"
+
+	// ReadinessDependenciesLabel is the docker label holding the JSON list of the readiness
+	// dependencies of a container, in the format of the dependsOn field of the task definition,
+	// e.g. [{"containerName": "sidecar", "condition": "PORT:8080"}]
+	ReadinessDependenciesLabel = "com.amazonaws.ecs.readiness-depends-on"
+)
+
+// ReadinessCondition is a dependency condition checked by the agent against the dependency
+// container, either a TCP port the container listens on or a path which exists in the
+// container, typically on a volume shared by the task.
+//
+// ECS only accepts the START, COMPLETE, SUCCESS and HEALTHY conditions when a task definition is
+// registered, so the readiness dependencies of the containers of ECS tasks are set with the
+// ReadinessDependenciesLabel docker label. The task definition files of ECS_LOCAL_TASKS_DIR can
+// also use them as dependency conditions.
+type ReadinessCondition struct {
+	// Port is the TCP port the container listens on
+	Port uint16
+	// Path is the absolute path which exists in the container
+	Path string
+}
+
+// ParseReadinessCondition parses a dependency condition. It returns nil if the condition isn't a
+// readiness condition.
+func ParseReadinessCondition(condition string) (*ReadinessCondition, error) {
+	switch {
+	case strings.HasPrefix(condition, ReadinessConditionPortPrefix):
+		port, err := strconv.ParseUint(strings.TrimPrefix(condition, ReadinessConditionPortPrefix), 10, 16)
+		if err != nil || port == 0 {
+			return nil, errors.Errorf("invalid dependency condition %q: port must be between 1 and 65535",
+				condition)
+		}
+		return &ReadinessCondition{Port: uint16(port)}, nil
+	case strings.HasPrefix(condition, ReadinessConditionFilePrefix):
+		p := strings.TrimPrefix(condition, ReadinessConditionFilePrefix)
+		if !path.IsAbs(p) || path.Clean(p) != p {
+			return nil, errors.Errorf("invalid dependency condition %q: path must be absolute and clean",
+				condition)
+		}
+		return &ReadinessCondition{Path: p}, nil
+	}
+	return nil, nil
+}
+
+// String describes what the dependency container does to satisfy the condition
+func (r *ReadinessCondition) String() string {
+	if r.Path != "" {
+		return "create " + r.Path
+	}
+	return "listen on TCP port " + strconv.Itoa(int(r.Port))
+}
+
+// ParseReadinessDependencies parses the readiness dependencies of a container from its labels. It
+// returns nil if the container doesn't have readiness dependencies.
+func ParseReadinessDependencies(labels map[string]string) ([]DependsOn, error) {
+	value, ok := labels[ReadinessDependenciesLabel]
+	if !ok {
+		return nil, nil
+	}
+	var dependencies []DependsOn
+	if err := json.Unmarshal([]byte(value), &dependencies); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse %s label", ReadinessDependenciesLabel)
+	}
+	for _, dependency := range dependencies {
+		if dependency.ContainerName == "" {
+			return nil, errors.Errorf("invalid %s label: container name is required", ReadinessDependenciesLabel)
+		}
+		readiness, err := ParseReadinessCondition(dependency.Condition)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s label", ReadinessDependenciesLabel)
+		}
+		if readiness == nil {
+			return nil, errors.Errorf("invalid %s label: condition %q is not a readiness condition",
+				ReadinessDependenciesLabel, dependency.Condition)
+		}
+	}
+	return dependencies, nil
+}
@@ -0,0 +1,95 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReadinessCondition(t *testing.T) {
+	testCases := []struct {
+		condition   string
+		expected    *ReadinessCondition
+		description string
+		expectError bool
+	}{
+		{condition: "HEALTHY"},
+		{condition: "PORT:8080", expected: &ReadinessCondition{Port: 8080}, description: "listen on TCP port 8080"},
+		{condition: "FILE:/shared/ready", expected: &ReadinessCondition{Path: "/shared/ready"},
+			description: "create /shared/ready"},
+		{condition: "PORT:0", expectError: true},
+		{condition: "PORT:65536", expectError: true},
+		{condition: "PORT:http", expectError: true},
+		{condition: "FILE:shared/ready", expectError: true},
+		{condition: "FILE:/shared/../ready", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.condition, func(t *testing.T) {
+			readiness, err := ParseReadinessCondition(tc.condition)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, readiness)
+			if tc.expected != nil {
+				assert.Equal(t, tc.description, readiness.String())
+			}
+		})
+	}
+}
+
+func TestParseReadinessDependencies(t *testing.T) {
+	dependencies, err := ParseReadinessDependencies(map[string]string{
+		ReadinessDependenciesLabel: `[{"containerName":"sidecar","condition":"PORT:8080"},` +
+			`{"containerName":"init","condition":"FILE:/shared/ready"}]`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []DependsOn{
+		{ContainerName: "sidecar", Condition: "PORT:8080"},
+		{ContainerName: "init", Condition: "FILE:/shared/ready"},
+	}, dependencies)
+
+	dependencies, err = ParseReadinessDependencies(map[string]string{"other": "label"})
+	require.NoError(t, err)
+	assert.Nil(t, dependencies)
+
+	for _, value := range []string{
+		`{"containerName":"sidecar"}`,
+		`[{"condition":"PORT:8080"}]`,
+		`[{"containerName":"sidecar","condition":"PORT:0"}]`,
+		`[{"containerName":"sidecar","condition":"HEALTHY"}]`,
+	} {
+		_, err := ParseReadinessDependencies(map[string]string{ReadinessDependenciesLabel: value})
+		assert.Error(t, err, value)
+	}
+}
+
+func TestSetReadinessConditionMet(t *testing.T) {
+	container := &Container{}
+	assert.False(t, container.IsReadinessConditionMet("PORT:8080"))
+
+	container.SetReadinessConditionMet("PORT:8080")
+	container.SetReadinessConditionMet("PORT:8080")
+	assert.True(t, container.IsReadinessConditionMet("PORT:8080"))
+	assert.False(t, container.IsReadinessConditionMet("FILE:/ready"))
+	assert.Equal(t, []string{"PORT:8080"}, container.ReadinessConditionsMetUnsafe)
+}
@@ -0,0 +1,86 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package task
+
+import (
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// AdmissionPriorityLabel is the docker label setting the admission priority class of the task. When
+	// containers of the task set different classes, the highest one applies.
+	AdmissionPriorityLabel = "com.amazonaws.ecs.admission-priority"
+
+	// AdmissionPriorityDaemon is the priority class of daemon tasks, which are admitted first
+	AdmissionPriorityDaemon = "daemon"
+	// AdmissionPriorityService is the priority class of tasks which don't set one
+	AdmissionPriorityService = "service"
+	// AdmissionPriorityBatch is the priority class of tasks which can wait for other tasks to be admitted
+	AdmissionPriorityBatch = "batch"
+)
+
+// admissionPriorities lists the admission priority classes from the highest to the lowest
+var admissionPriorities = []string{AdmissionPriorityDaemon, AdmissionPriorityService, AdmissionPriorityBatch}
+
+// AdmissionPriorityRank returns the rank of the admission priority class, tasks of lower ranks are admitted
+// first. Unknown classes rank like AdmissionPriorityService.
+func AdmissionPriorityRank(priority string) int {
+	if rank := slices.Index(admissionPriorities, priority); rank >= 0 {
+		return rank
+	}
+	return slices.Index(admissionPriorities, AdmissionPriorityService)
+}
+
+// AdmissionPriorityForRank returns the admission priority class of the rank. Ranks are capped to the
+// highest and lowest classes.
+func AdmissionPriorityForRank(rank int) string {
+	return admissionPriorities[max(0, min(rank, len(admissionPriorities)-1))]
+}
+
+// GetAdmissionPriority returns the admission priority class of the task, which orders the tasks waiting
+// for host resources.
+func (task *Task) GetAdmissionPriority() string {
+	if task.IsInternal {
+		return AdmissionPriorityDaemon
+	}
+	if task.AdmissionPriority == "" {
+		return AdmissionPriorityService
+	}
+	return task.AdmissionPriority
+}
+
+// initializeAdmissionPriority sets the admission priority class of the task from the docker labels of its
+// containers
+func (task *Task) initializeAdmissionPriority() error {
+	for _, container := range task.Containers {
+		labels, err := containerLabels(container)
+		if err != nil {
+			return err
+		}
+		priority, ok := labels[AdmissionPriorityLabel]
+		if !ok {
+			continue
+		}
+		if !slices.Contains(admissionPriorities, priority) {
+			return errors.Errorf("container %s: invalid %s label %q, it must be one of %v", container.Name,
+				AdmissionPriorityLabel, priority, admissionPriorities)
+		}
+		if task.AdmissionPriority == "" || AdmissionPriorityRank(priority) < AdmissionPriorityRank(task.AdmissionPriority) {
+			task.AdmissionPriority = priority
+		}
+	}
+	return nil
+}
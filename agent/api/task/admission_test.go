@@ -0,0 +1,91 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package task
+
+import (
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func containerWithLabels(name, labels string) *apicontainer.Container {
+	return &apicontainer.Container{
+		Name: name,
+		DockerConfig: apicontainer.DockerConfig{
+			Config: strptr(`{"Labels":` + labels + `}`),
+		},
+	}
+}
+
+func TestInitializeAdmissionPriority(t *testing.T) {
+	testCases := []struct {
+		name             string
+		containers       []*apicontainer.Container
+		expectedPriority string
+		expectedError    string
+	}{
+		{
+			name:             "no label",
+			containers:       []*apicontainer.Container{{Name: "c1"}, containerWithLabels("c2", `{"foo":"bar"}`)},
+			expectedPriority: AdmissionPriorityService,
+		},
+		{
+			name: "highest class of the containers",
+			containers: []*apicontainer.Container{
+				containerWithLabels("c1", `{"`+AdmissionPriorityLabel+`":"batch"}`),
+				containerWithLabels("c2", `{"`+AdmissionPriorityLabel+`":"daemon"}`),
+				containerWithLabels("c3", `{"`+AdmissionPriorityLabel+`":"service"}`),
+			},
+			expectedPriority: AdmissionPriorityDaemon,
+		},
+		{
+			name: "invalid class",
+			containers: []*apicontainer.Container{
+				containerWithLabels("c1", `{"`+AdmissionPriorityLabel+`":"urgent"}`),
+			},
+			expectedError: `container c1: invalid com.amazonaws.ecs.admission-priority label "urgent", ` +
+				`it must be one of [daemon service batch]`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := &Task{Containers: tc.containers}
+			err := task.initializeAdmissionPriority()
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPriority, task.GetAdmissionPriority())
+		})
+	}
+}
+
+func TestGetAdmissionPriorityInternalTask(t *testing.T) {
+	task := &Task{IsInternal: true, AdmissionPriority: AdmissionPriorityBatch}
+	assert.Equal(t, AdmissionPriorityDaemon, task.GetAdmissionPriority())
+}
+
+func TestAdmissionPriorityRank(t *testing.T) {
+	assert.Equal(t, 0, AdmissionPriorityRank(AdmissionPriorityDaemon))
+	assert.Equal(t, 1, AdmissionPriorityRank("unknown"))
+	assert.Equal(t, 2, AdmissionPriorityRank(AdmissionPriorityBatch))
+	assert.Equal(t, AdmissionPriorityDaemon, AdmissionPriorityForRank(-1))
+	assert.Equal(t, AdmissionPriorityBatch, AdmissionPriorityForRank(5))
+}
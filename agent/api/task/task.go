@@ -310,6 +310,14 @@ type Task struct {
 	// For all other network modes (i.e. bridge, none, etc.), DefaultIfname is currently not being initialized/set. In order to use this task field for these
 	// network modes, changes will need to be made in the corresponding task provisioning workflows.
 	DefaultIfname string `json:"DefaultIfname,omitempty"`
+
+	// AdmissionPriority is the admission priority class of the task, configured with a docker label. Use
+	// GetAdmissionPriority to get the class that applies to the task.
+	AdmissionPriority string `json:"AdmissionPriority,omitempty"`
+
+	// MemoryControls are the cgroup v2 memory controls of the task cgroup, configured with docker labels on
+	// containers or the agent memory policy. It's nil when the task cgroup doesn't have memory controls.
+	MemoryControls *apicontainer.MemoryControls `json:"MemoryControls,omitempty"`
 }
 
 // TaskFromACS translates ecsacs.Task to apitask.Task by first marshaling the received
@@ -390,6 +398,15 @@ func (task *Task) PostUnmarshalTask(cfg *config.Config,
 
 	task.adjustForPlatform(cfg)
 
+	// The memory controls are part of the cgroup resource spec
+	if err := task.initializeMemoryControls(cfg.TaskMemoryPolicy); err != nil {
+		logger.Error("Could not initialize memory controls", logger.Fields{
+			field.TaskID: task.GetID(),
+			field.Error:  err,
+		})
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
+
 	// Initialize cgroup resource spec definition for later cgroup resource creation.
 	// This sets up the cgroup spec for cpu, memory, and pids limits for the task.
 	// Actual cgroup creation happens later.
@@ -417,6 +434,46 @@ func (task *Task) PostUnmarshalTask(cfg *config.Config,
 		return apierrors.NewResourceInitError(task.Arn, err)
 	}
 
+	if err := task.initializeContainerReadinessDependencies(); err != nil {
+		logger.Error("Could not initialize container readiness dependencies", logger.Fields{
+			field.TaskID: task.GetID(),
+			field.Error:  err,
+		})
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
+
+	if err := task.initializeContainerHealthProbes(); err != nil {
+		logger.Error("Could not initialize container health probes", logger.Fields{
+			field.TaskID: task.GetID(),
+			field.Error:  err,
+		})
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
+
+	if err := task.initializeContainerBandwidthLimits(); err != nil {
+		logger.Error("Could not initialize container bandwidth limits", logger.Fields{
+			field.TaskID: task.GetID(),
+			field.Error:  err,
+		})
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
+
+	if err := task.initializeContainerHostResourceRequests(); err != nil {
+		logger.Error("Could not initialize container host resource requests", logger.Fields{
+			field.TaskID: task.GetID(),
+			field.Error:  err,
+		})
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
+
+	if err := task.initializeAdmissionPriority(); err != nil {
+		logger.Error("Could not initialize task admission priority", logger.Fields{
+			field.TaskID: task.GetID(),
+			field.Error:  err,
+		})
+		return apierrors.NewResourceInitError(task.Arn, err)
+	}
+
 	task.initSecretResources(credentialsManager, resourceFields)
 
 	task.initializeCredentialsEndpoint(credentialsManager)
@@ -1967,6 +2024,10 @@ func (task *Task) dockerHostConfig(container *apicontainer.Container, dockerCont
 		return nil, &apierrors.HostConfigError{Msg: err.Error()}
 	}
 
+	if err := task.overrideContainerMemorySwap(container, hostConfig); err != nil {
+		return nil, &apierrors.HostConfigError{Msg: err.Error()}
+	}
+
 	// Determine if network mode should be overridden and override it if needed
 	ok, networkMode := task.shouldOverrideNetworkMode(container, dockerContainerMap)
 	if ok {
@@ -2321,6 +2382,205 @@ func (task *Task) ipcModeOverride(container *apicontainer.Container, dockerConta
 	}
 }
 
+// containerLabels returns the docker labels set in the docker config of the container. The labels
+// configure the features the task definition has no field for.
+func containerLabels(container *apicontainer.Container) (map[string]string, error) {
+	if container.DockerConfig.Config == nil {
+		return nil, nil
+	}
+	var containerConfig dockercontainer.Config
+	if err := json.Unmarshal([]byte(aws.ToString(container.DockerConfig.Config)), &containerConfig); err != nil {
+		return nil, errors.Wrapf(err, "container %s: unable to decode docker config", container.Name)
+	}
+	return containerConfig.Labels, nil
+}
+
+// initializeContainerReadinessDependencies adds the readiness dependencies configured with docker
+// labels to the dependencies of the containers
+func (task *Task) initializeContainerReadinessDependencies() error {
+	for _, container := range task.Containers {
+		labels, err := containerLabels(container)
+		if err != nil {
+			return err
+		}
+		dependencies, err := apicontainer.ParseReadinessDependencies(labels)
+		if err != nil {
+			return errors.Wrapf(err, "container %s", container.Name)
+		}
+		for _, dependency := range dependencies {
+			if _, ok := task.ContainerByName(dependency.ContainerName); !ok ||
+				dependency.ContainerName == container.Name {
+				return errors.Errorf("container %s: invalid readiness dependency on container %s",
+					container.Name, dependency.ContainerName)
+			}
+			if !hasDependency(container, dependency) {
+				container.SetDependsOn(append(container.GetDependsOn(), dependency))
+			}
+		}
+	}
+	return nil
+}
+
+func hasDependency(container *apicontainer.Container, dependency apicontainer.DependsOn) bool {
+	for _, dependsOn := range container.GetDependsOn() {
+		if dependsOn == dependency {
+			return true
+		}
+	}
+	return false
+}
+
+// initializeContainerHealthProbes sets up the agent health probes configured with docker labels
+// on containers that don't have a health check in the task definition
+func (task *Task) initializeContainerHealthProbes() error {
+	for _, container := range task.Containers {
+		if container.HealthCheckType != "" {
+			continue
+		}
+		labels, err := containerLabels(container)
+		if err != nil {
+			return err
+		}
+		probe, err := apicontainer.ParseHealthProbe(labels)
+		if err != nil {
+			return errors.Wrapf(err, "container %s", container.Name)
+		}
+		if probe != nil {
+			container.HealthCheckType = apicontainer.AgentHealthCheckType
+			container.HealthProbe = probe
+		}
+	}
+	return nil
+}
+
+// initializeContainerBandwidthLimits sets up the network bandwidth limits configured with docker labels
+// on containers
+func (task *Task) initializeContainerBandwidthLimits() error {
+	var taskLimits *apicontainer.BandwidthLimits
+	for _, container := range task.Containers {
+		labels, err := containerLabels(container)
+		if err != nil {
+			return err
+		}
+		limits, err := apicontainer.ParseBandwidthLimits(labels)
+		if err != nil {
+			return errors.Wrapf(err, "container %s", container.Name)
+		}
+		if limits == nil {
+			continue
+		}
+		switch {
+		case task.IsNetworkModeAWSVPC():
+			// The containers share the task network interface, so they have to agree on the limits
+			if taskLimits != nil && *taskLimits != *limits {
+				return errors.Errorf("container %s: bandwidth limits differ from the limits of other containers "+
+					"of the task", container.Name)
+			}
+			taskLimits = limits
+		case task.IsNetworkModeBridge():
+		default:
+			return errors.Errorf("container %s: bandwidth limits are not supported in %s network mode",
+				container.Name, task.GetNetworkMode())
+		}
+		container.BandwidthLimits = limits
+	}
+	if taskLimits != nil {
+		// The limits of the task network interface apply to every container of the task
+		for _, container := range task.Containers {
+			container.BandwidthLimits = taskLimits
+		}
+	}
+	return nil
+}
+
+// initializeContainerHostResourceRequests sets the host resources requested with docker labels on containers
+func (task *Task) initializeContainerHostResourceRequests() error {
+	for _, container := range task.Containers {
+		labels, err := containerLabels(container)
+		if err != nil {
+			return err
+		}
+		requests, err := apicontainer.ParseHostResourceRequests(labels)
+		if err != nil {
+			return errors.Wrapf(err, "container %s", container.Name)
+		}
+		container.HostResourceRequests = requests
+	}
+	return nil
+}
+
+// initializeMemoryControls sets up the memory controls configured with docker labels on containers, and
+// the memory controls of the task cgroup when it's a cgroup v2 cgroup. The memory.high and the swap of the
+// task are the sums of those of the containers setting them, and OOM group is enabled if a container
+// enables it. The task controls no container sets come from the agent memory policy.
+func (task *Task) initializeMemoryControls(policy *config.TaskMemoryPolicy) error {
+	var taskControls apicontainer.MemoryControls
+	for _, container := range task.Containers {
+		labels, err := containerLabels(container)
+		if err != nil {
+			return err
+		}
+		controls, err := apicontainer.ParseMemoryControls(labels)
+		if err != nil {
+			return errors.Wrapf(err, "container %s", container.Name)
+		}
+		container.MemoryControls = controls
+		if controls == nil {
+			continue
+		}
+		if controls.HighMiB != nil {
+			taskControls.HighMiB = aws.Int64(aws.ToInt64(taskControls.HighMiB) + *controls.HighMiB)
+		}
+		if controls.SwapMaxMiB != nil {
+			taskControls.SwapMaxMiB = aws.Int64(aws.ToInt64(taskControls.SwapMaxMiB) + *controls.SwapMaxMiB)
+		}
+		if controls.OOMGroup != nil {
+			taskControls.OOMGroup = aws.Bool(aws.ToBool(taskControls.OOMGroup) || *controls.OOMGroup)
+		}
+	}
+
+	if !config.CgroupV2 || !task.MemoryCPULimitsEnabled {
+		return nil
+	}
+	if policy != nil {
+		if taskControls.HighMiB == nil && policy.MemoryHighPercent != nil && task.Memory > 0 {
+			taskControls.HighMiB = aws.Int64(task.Memory * *policy.MemoryHighPercent / 100)
+		}
+		if taskControls.SwapMaxMiB == nil {
+			taskControls.SwapMaxMiB = policy.SwapMaxMiB
+		}
+		if taskControls.OOMGroup == nil {
+			taskControls.OOMGroup = policy.OOMGroup
+		}
+	}
+	if taskControls != (apicontainer.MemoryControls{}) {
+		task.MemoryControls = &taskControls
+	}
+	return nil
+}
+
+// GetMemoryControls returns the cgroup v2 memory controls of the task cgroup. It returns nil if the task
+// cgroup doesn't have memory controls.
+func (task *Task) GetMemoryControls() *apicontainer.MemoryControls {
+	task.lock.RLock()
+	defer task.lock.RUnlock()
+	return task.MemoryControls
+}
+
+// GetBandwidthLimits returns the network bandwidth limits of the task network interface in awsvpc network
+// mode. It returns nil if the task isn't limited.
+func (task *Task) GetBandwidthLimits() *apicontainer.BandwidthLimits {
+	if !task.IsNetworkModeAWSVPC() {
+		return nil
+	}
+	for _, container := range task.Containers {
+		if container.BandwidthLimits != nil {
+			return container.BandwidthLimits
+		}
+	}
+	return nil
+}
+
 func (task *Task) initializeContainerOrdering() error {
 	// Handle ordering for Service Connect
 	if task.IsServiceConnectEnabled() {
@@ -2398,8 +2658,10 @@ func (task *Task) dockerLinks(container *apicontainer.Container, dockerContainer
 	return dockerLinkArr, nil
 }
 
-var getHostPortRange = utils.GetHostPortRange
-var getHostPort = utils.GetHostPort
+// getHostPortRange and getHostPort reserve the host ports they find for the container of the task, so that they
+// are not assigned to another task until the task stops.
+var getHostPortRange = utils.ReserveHostPortRange
+var getHostPort = utils.ReserveHostPort
 
 // In buildPortMapWithSCIngressConfig, the dockerPortMap and the containerPortSet will be constructed
 // for ingress listeners under two service connect bridge mode cases:
@@ -2408,7 +2670,8 @@ var getHostPort = utils.GetHostPort
 //
 //	Instead, ECS Agent finds host ports within the given dynamic host port range. An error will be returned for case (2) if
 //	ECS Agent cannot find an available host port within range.
-func (task *Task) buildPortMapWithSCIngressConfig(dynamicHostPortRange string) (nat.PortMap, error) {
+func (task *Task) buildPortMapWithSCIngressConfig(pauseContainer *apicontainer.Container,
+	dynamicHostPortRange string) (nat.PortMap, error) {
 	var err error
 	ingressDockerPortMap := nat.PortMap{}
 	ingressContainerPortSet := make(map[int]struct{})
@@ -2429,7 +2692,7 @@ func (task *Task) buildPortMapWithSCIngressConfig(dynamicHostPortRange string) (
 			// thus the host port will be assigned by ECS Agent.
 			// ECS Agent will find an available host port within the given dynamic host port range,
 			// or return an error if no host port is available within the range.
-			hostPortStr, err = getHostPort(protocolStr, dynamicHostPortRange)
+			hostPortStr, err = getHostPort(task.Arn, pauseContainer.Name, protocolStr, dynamicHostPortRange)
 			if err != nil {
 				return nil, err
 			}
@@ -2469,6 +2732,9 @@ func (task *Task) dockerPortMap(container *apicontainer.Container, dynamicHostPo
 	containerPortSet := make(map[int]struct{})
 	containerPortRangeMap := make(map[string]string)
 
+	// Release the host ports assigned to the container by a previous attempt to create it
+	utils.ReleaseContainerHostPorts(task.Arn, container.Name)
+
 	// For service connect bridge network mode task, we will create port bindings for task containers,
 	// including both application containers and service connect AppNet container, and let them be published
 	// by the associated pause containers.
@@ -2486,7 +2752,7 @@ func (task *Task) dockerPortMap(container *apicontainer.Container, dynamicHostPo
 				// create port binding(s) for ingress listener ports based on its ingress config.
 				// Note that there is no need to do this for egress listener ports as they won't be accessed
 				// from host level or from outside.
-				dockerPortMap, err := task.buildPortMapWithSCIngressConfig(dynamicHostPortRange)
+				dockerPortMap, err := task.buildPortMapWithSCIngressConfig(container, dynamicHostPortRange)
 				if err != nil {
 					logger.Error("Failed to build a port map with service connect ingress config", logger.Fields{
 						field.TaskID:           task.GetID(),
@@ -2535,7 +2801,7 @@ func (task *Task) dockerPortMap(container *apicontainer.Container, dynamicHostPo
 					field.Container:        containerToCheck.Name,
 					"dynamicHostPortRange": dynamicHostPortRange,
 				})
-				hostPortStr, err = getHostPort(protocolStr, dynamicHostPortRange)
+				hostPortStr, err = getHostPort(task.Arn, container.Name, protocolStr, dynamicHostPortRange)
 				if err != nil {
 					logger.Error("Unable to find a host port for container within the given dynamic host port range", logger.Fields{
 						field.TaskID:           task.GetID(),
@@ -2566,7 +2832,7 @@ func (task *Task) dockerPortMap(container *apicontainer.Container, dynamicHostPo
 			// This is to ensure that docker maps host ports in a contiguous manner, and
 			// we are guaranteed to have the entire hostPortRange in a single network binding while sending this info to ECS;
 			// therefore, an error will be returned if we cannot find a contiguous set of host ports.
-			hostPortRange, err := getHostPortRange(numberOfPorts, protocol, dynamicHostPortRange)
+			hostPortRange, err := getHostPortRange(task.Arn, container.Name, numberOfPorts, protocol, dynamicHostPortRange)
 			if err != nil {
 				logger.Error("Unable to find contiguous host ports for container", logger.Fields{
 					field.TaskID:         task.GetID(),
@@ -3665,6 +3931,12 @@ func (task *Task) IsLaunchTypeFargate() bool {
 //
 // * GPU
 //   - Concatenate each container's gpu ids
+//
+// * Ephemeral storage and huge pages
+//   - Add up the container requests set with docker labels, only when requested
+//
+// * ENI
+//   - One network interface for awsvpc tasks
 func (task *Task) ToHostResources() map[string]ecstypes.Resource {
 	resources := make(map[string]ecstypes.Resource)
 	// CPU
@@ -3768,13 +4040,46 @@ func (task *Task) ToHostResources() map[string]ecstypes.Resource {
 		Type:           utils.Strptr("STRINGSET"),
 		StringSetValue: gpus,
 	}
+
+	// EPHEMERAL_STORAGE and HUGEPAGES
+	var ephemeralStorage, hugePages int32
+	for _, c := range task.Containers {
+		ephemeralStorage += c.HostResourceRequests.EphemeralStorageMiB
+		hugePages += c.HostResourceRequests.HugePagesMiB
+	}
+	if ephemeralStorage > 0 {
+		resources["EPHEMERAL_STORAGE"] = ecstypes.Resource{
+			Name:         utils.Strptr("EPHEMERAL_STORAGE"),
+			Type:         utils.Strptr("INTEGER"),
+			IntegerValue: ephemeralStorage,
+		}
+	}
+	if hugePages > 0 {
+		resources["HUGEPAGES"] = ecstypes.Resource{
+			Name:         utils.Strptr("HUGEPAGES"),
+			Type:         utils.Strptr("INTEGER"),
+			IntegerValue: hugePages,
+		}
+	}
+
+	// ENI
+	if task.IsNetworkModeAWSVPC() {
+		resources["ENI"] = ecstypes.Resource{
+			Name:         utils.Strptr("ENI"),
+			Type:         utils.Strptr("INTEGER"),
+			IntegerValue: 1,
+		}
+	}
 	logger.Debug("Task host resources to account for", logger.Fields{
-		"taskArn":   task.Arn,
-		"CPU":       resources["CPU"].IntegerValue,
-		"MEMORY":    resources["MEMORY"].IntegerValue,
-		"PORTS_TCP": resources["PORTS_TCP"].StringSetValue,
-		"PORTS_UDP": resources["PORTS_UDP"].StringSetValue,
-		"GPU":       resources["GPU"].StringSetValue,
+		"taskArn":           task.Arn,
+		"CPU":               resources["CPU"].IntegerValue,
+		"MEMORY":            resources["MEMORY"].IntegerValue,
+		"PORTS_TCP":         resources["PORTS_TCP"].StringSetValue,
+		"PORTS_UDP":         resources["PORTS_UDP"].StringSetValue,
+		"GPU":               resources["GPU"].StringSetValue,
+		"EPHEMERAL_STORAGE": resources["EPHEMERAL_STORAGE"].IntegerValue,
+		"HUGEPAGES":         resources["HUGEPAGES"].IntegerValue,
+		"ENI":               resources["ENI"].IntegerValue,
 	})
 	return resources
 }
@@ -19,11 +19,13 @@ package task
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
 
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/ecscni"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
@@ -51,6 +53,11 @@ const (
 
 	minimumCPUPercent = 0
 	bytesPerMegabyte  = 1024 * 1024
+
+	// cgroup v2 files of the memory controls of the task cgroup
+	memoryHighFile     = "memory.high"
+	memorySwapMaxFile  = "memory.swap.max"
+	memoryOOMGroupFile = "memory.oom.group"
 )
 
 // PlatformFields consists of fields specific to Linux for a task
@@ -84,6 +91,7 @@ func (task *Task) initializeCgroupResourceSpec(cgroupPath string, cGroupCPUPerio
 	}
 	cgroupResource := cgroup.NewCgroupResource(task.Arn, resourceFields.Control,
 		resourceFields.IOUtil, cgroupRoot, cgroupPath, resSpec)
+	cgroupResource.SetCPUSetLedger(resourceFields.CPUSetLedger)
 	task.AddResource(resourcetype.CgroupKey, cgroupResource)
 	for _, container := range task.Containers {
 		container.BuildResourceDependency(cgroupResource.GetName(),
@@ -160,6 +168,11 @@ func (task *Task) BuildLinuxResourceSpec(cGroupCPUPeriod time.Duration, taskPids
 		linuxResourceSpec.Pids = pidsLimit
 	}
 
+	// Set the memory controls which only exist in cgroup v2
+	if config.CgroupV2 && task.MemoryControls != nil {
+		linuxResourceSpec.Unified = buildUnifiedMemorySpec(task.MemoryControls)
+	}
+
 	return linuxResourceSpec, nil
 }
 
@@ -220,6 +233,24 @@ func (task *Task) buildLinuxMemorySpec() (specs.LinuxMemory, error) {
 	}, nil
 }
 
+// buildUnifiedMemorySpec builds the cgroup v2 files of the memory controls of the task
+func buildUnifiedMemorySpec(controls *apicontainer.MemoryControls) map[string]string {
+	unified := make(map[string]string)
+	if controls.HighMiB != nil {
+		unified[memoryHighFile] = strconv.FormatInt(*controls.HighMiB*bytesPerMegabyte, 10)
+	}
+	if controls.SwapMaxMiB != nil {
+		unified[memorySwapMaxFile] = strconv.FormatInt(*controls.SwapMaxMiB*bytesPerMegabyte, 10)
+	}
+	if controls.OOMGroup != nil {
+		unified[memoryOOMGroupFile] = "0"
+		if *controls.OOMGroup {
+			unified[memoryOOMGroupFile] = "1"
+		}
+	}
+	return unified
+}
+
 // platformHostConfigOverride to override platform specific feature sets
 func (task *Task) platformHostConfigOverride(hostConfig *dockercontainer.HostConfig) error {
 	// Override cgroup parent
@@ -241,6 +272,21 @@ func (task *Task) overrideCgroupParent(hostConfig *dockercontainer.HostConfig) e
 	return nil
 }
 
+// overrideContainerMemorySwap sets the swap of the container configured with a docker label. Docker limits
+// the memory and the swap of containers together, so the container needs a hard memory limit. Like the other
+// memory controls, the swap label is ignored outside of cgroup v2.
+func (task *Task) overrideContainerMemorySwap(container *apicontainer.Container, hostConfig *dockercontainer.HostConfig) error {
+	if !config.CgroupV2 || container.MemoryControls == nil || container.MemoryControls.SwapMaxMiB == nil {
+		return nil
+	}
+	if hostConfig.Memory <= 0 {
+		return errors.Errorf("container %s: the %s label requires a container memory limit",
+			container.Name, apicontainer.MemorySwapMaxLabel)
+	}
+	hostConfig.MemorySwap = hostConfig.Memory + *container.MemoryControls.SwapMaxMiB*bytesPerMegabyte
+	return nil
+}
+
 // dockerCPUShares converts containerCPU shares if needed as per the logic stated below:
 // Docker silently converts 0 to 1024 CPU shares, which is probably not what we
 // want.  Instead, we convert 0 to 2 to be closer to expected behavior. The
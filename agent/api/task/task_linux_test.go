@@ -1739,3 +1739,158 @@ func TestDockerCPUShares(t *testing.T) {
 		})
 	}
 }
+
+func TestInitializeMemoryControls(t *testing.T) {
+	defer func(cgroupV2 bool) { config.CgroupV2 = cgroupV2 }(config.CgroupV2)
+	config.CgroupV2 = true
+
+	task := &Task{
+		Arn:                    validTaskArn,
+		Memory:                 1024,
+		MemoryCPULimitsEnabled: true,
+		Containers: []*apicontainer.Container{
+			{
+				Name: "c1",
+				DockerConfig: apicontainer.DockerConfig{
+					Config: aws.String(`{"Labels":{"` + apicontainer.MemoryHighLabel + `":"256","` +
+						apicontainer.MemoryOOMGroupLabel + `":"true"}}`),
+				},
+			},
+			{
+				Name: "c2",
+				DockerConfig: apicontainer.DockerConfig{
+					Config: aws.String(`{"Labels":{"` + apicontainer.MemoryHighLabel + `":"512","` +
+						apicontainer.MemoryOOMGroupLabel + `":"false"}}`),
+				},
+			},
+			{
+				Name: "c3",
+			},
+		},
+	}
+	policy := &config.TaskMemoryPolicy{
+		MemoryHighPercent: aws.Int64(90),
+		SwapMaxMiB:        aws.Int64(0),
+		OOMGroup:          aws.Bool(false),
+	}
+	require.NoError(t, task.initializeMemoryControls(policy))
+
+	assert.Equal(t, &apicontainer.MemoryControls{HighMiB: aws.Int64(256), OOMGroup: aws.Bool(true)},
+		task.Containers[0].MemoryControls)
+	assert.Nil(t, task.Containers[2].MemoryControls)
+	// the containers set memory.high and OOM group, the policy sets the swap
+	assert.Equal(t, &apicontainer.MemoryControls{
+		HighMiB:    aws.Int64(768),
+		SwapMaxMiB: aws.Int64(0),
+		OOMGroup:   aws.Bool(true),
+	}, task.GetMemoryControls())
+
+	linuxResourceSpec, err := task.BuildLinuxResourceSpec(defaultCPUPeriod, 0)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"memory.high":      "805306368",
+		"memory.swap.max":  "0",
+		"memory.oom.group": "1",
+	}, linuxResourceSpec.Unified)
+}
+
+func TestInitializeMemoryControlsFromPolicy(t *testing.T) {
+	defer func(cgroupV2 bool) { config.CgroupV2 = cgroupV2 }(config.CgroupV2)
+	config.CgroupV2 = true
+
+	task := &Task{
+		Arn:                    validTaskArn,
+		Memory:                 1000,
+		MemoryCPULimitsEnabled: true,
+		Containers:             []*apicontainer.Container{{Name: "c1"}},
+	}
+	require.NoError(t, task.initializeMemoryControls(&config.TaskMemoryPolicy{MemoryHighPercent: aws.Int64(90)}))
+	assert.Equal(t, &apicontainer.MemoryControls{HighMiB: aws.Int64(900)}, task.GetMemoryControls())
+
+	// tasks without memory controls don't get unified resources
+	task = &Task{
+		Arn:                    validTaskArn,
+		MemoryCPULimitsEnabled: true,
+		Containers:             []*apicontainer.Container{{Name: "c1"}},
+	}
+	require.NoError(t, task.initializeMemoryControls(&config.TaskMemoryPolicy{MemoryHighPercent: aws.Int64(90)}))
+	assert.Nil(t, task.GetMemoryControls())
+	linuxResourceSpec, err := task.BuildLinuxResourceSpec(defaultCPUPeriod, 0)
+	require.NoError(t, err)
+	assert.Nil(t, linuxResourceSpec.Unified)
+}
+
+func TestInitializeMemoryControlsCgroupV1(t *testing.T) {
+	defer func(cgroupV2 bool) { config.CgroupV2 = cgroupV2 }(config.CgroupV2)
+	config.CgroupV2 = false
+
+	task := &Task{
+		Arn:                    validTaskArn,
+		Memory:                 1024,
+		MemoryCPULimitsEnabled: true,
+		Containers: []*apicontainer.Container{
+			{
+				Name: "c1",
+				DockerConfig: apicontainer.DockerConfig{
+					Config: aws.String(`{"Labels":{"` + apicontainer.MemorySwapMaxLabel + `":"256"}}`),
+				},
+			},
+		},
+	}
+	require.NoError(t, task.initializeMemoryControls(&config.TaskMemoryPolicy{OOMGroup: aws.Bool(true)}))
+	// the controls of the container are still parsed, but neither they nor task controls are applied in cgroup v1
+	assert.Equal(t, &apicontainer.MemoryControls{SwapMaxMiB: aws.Int64(256)}, task.Containers[0].MemoryControls)
+	assert.Nil(t, task.GetMemoryControls())
+}
+
+func TestInitializeMemoryControlsInvalidLabel(t *testing.T) {
+	task := &Task{
+		Containers: []*apicontainer.Container{
+			{
+				Name: "c1",
+				DockerConfig: apicontainer.DockerConfig{
+					Config: aws.String(`{"Labels":{"` + apicontainer.MemoryOOMGroupLabel + `":"yes please"}}`),
+				},
+			},
+		},
+	}
+	assert.EqualError(t, task.initializeMemoryControls(nil),
+		`container c1: invalid com.amazonaws.ecs.memory-oom-group label "yes please", it must be true or false`)
+}
+
+func TestOverrideContainerMemorySwap(t *testing.T) {
+	defer func(cgroupV2 bool) { config.CgroupV2 = cgroupV2 }(config.CgroupV2)
+	config.CgroupV2 = true
+
+	task := &Task{Arn: validTaskArn}
+	container := &apicontainer.Container{
+		Name:           "c1",
+		MemoryControls: &apicontainer.MemoryControls{SwapMaxMiB: aws.Int64(256)},
+	}
+
+	hostConfig := &dockercontainer.HostConfig{Resources: dockercontainer.Resources{Memory: 512 * bytesPerMegabyte}}
+	require.NoError(t, task.overrideContainerMemorySwap(container, hostConfig))
+	assert.Equal(t, int64(768*bytesPerMegabyte), hostConfig.MemorySwap)
+
+	hostConfig = &dockercontainer.HostConfig{}
+	assert.EqualError(t, task.overrideContainerMemorySwap(container, hostConfig),
+		"container c1: the com.amazonaws.ecs.memory-swap-max label requires a container memory limit")
+
+	hostConfig = &dockercontainer.HostConfig{}
+	require.NoError(t, task.overrideContainerMemorySwap(&apicontainer.Container{Name: "c2"}, hostConfig))
+	assert.Zero(t, hostConfig.MemorySwap)
+}
+
+func TestOverrideContainerMemorySwapCgroupV1(t *testing.T) {
+	defer func(cgroupV2 bool) { config.CgroupV2 = cgroupV2 }(config.CgroupV2)
+	config.CgroupV2 = false
+
+	task := &Task{Arn: validTaskArn}
+	container := &apicontainer.Container{
+		Name:           "c1",
+		MemoryControls: &apicontainer.MemoryControls{SwapMaxMiB: aws.Int64(256)},
+	}
+	hostConfig := &dockercontainer.HostConfig{Resources: dockercontainer.Resources{Memory: 512 * bytesPerMegabyte}}
+	require.NoError(t, task.overrideContainerMemorySwap(container, hostConfig))
+	assert.Zero(t, hostConfig.MemorySwap)
+}
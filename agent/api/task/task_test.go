@@ -402,7 +402,7 @@ func TestDockerHostConfigPortBinding(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.testName, func(t *testing.T) {
 			defer func() {
-				getHostPortRange = utils.GetHostPortRange
+				getHostPortRange = utils.ReserveHostPortRange
 			}()
 
 			// Get the Docker host config for the task container
@@ -436,6 +436,37 @@ func TestDockerHostConfigPortBinding(t *testing.T) {
 	}
 }
 
+func TestDockerHostConfigPortBindingRetryReplacesReservation(t *testing.T) {
+	testTask := &Task{
+		Arn: "arn:aws:ecs:us-west-2:1234567890:task/test-cluster/retry",
+		Containers: []*apicontainer.Container{
+			{
+				Name: "c1",
+				Ports: []apicontainer.PortBinding{
+					{ContainerPort: 10, Protocol: apicontainer.TransportProtocolTCP},
+				},
+			},
+		},
+	}
+	defer utils.ReleaseHostPorts(testTask.Arn)
+
+	// Creating the container again, e.g. after a failed attempt, must not keep the host port of the previous one
+	for i := 0; i < 2; i++ {
+		_, err := testTask.DockerHostConfig(testTask.Containers[0], dockerMap(testTask),
+			defaultDockerClientAPIVersion, &config.Config{DynamicHostPortRange: "40000-40010"})
+		require.Nil(t, err)
+	}
+
+	var ranges []utils.HostPortRange
+	for _, reservation := range utils.GetHostPortReservations() {
+		if reservation.TaskARN == testTask.Arn {
+			ranges = append(ranges, reservation.Ranges...)
+		}
+	}
+	require.Len(t, ranges, 1)
+	assert.Equal(t, "c1", ranges[0].ContainerName)
+}
+
 var (
 	SCTaskContainerPort1            uint16 = 8080
 	SCTaskContainerPort2            uint16 = 9090
@@ -3719,6 +3750,160 @@ func TestTaskGPUDisabled(t *testing.T) {
 	assert.False(t, testTask.isGPUEnabled())
 }
 
+func TestContainerLabels(t *testing.T) {
+	labels, err := containerLabels(&apicontainer.Container{
+		DockerConfig: apicontainer.DockerConfig{Config: aws.String(`{"Labels":{"key":"value"}}`)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key": "value"}, labels)
+
+	labels, err = containerLabels(&apicontainer.Container{})
+	require.NoError(t, err)
+	assert.Empty(t, labels)
+
+	// the labels of an invalid docker config fail the initialization of the task instead of being ignored
+	task := &Task{
+		Containers: []*apicontainer.Container{{
+			Name:         "invalid",
+			DockerConfig: apicontainer.DockerConfig{Config: aws.String(`{"Labels":`)},
+		}},
+	}
+	assert.Error(t, task.initializeContainerReadinessDependencies())
+	assert.Error(t, task.initializeContainerHealthProbes())
+	assert.Error(t, task.initializeContainerBandwidthLimits())
+	assert.Error(t, task.initializeContainerHostResourceRequests())
+	assert.Error(t, task.initializeAdmissionPriority())
+}
+
+func TestInitializeContainerReadinessDependencies(t *testing.T) {
+	readinessConfig := aws.String(`{"Labels":{"com.amazonaws.ecs.readiness-depends-on":` +
+		`"[{\"containerName\":\"sidecar\",\"condition\":\"PORT:8080\"}]"}}`)
+	task := &Task{
+		Containers: []*apicontainer.Container{
+			{
+				Name:            "app",
+				DependsOnUnsafe: []apicontainer.DependsOn{{ContainerName: "init", Condition: "SUCCESS"}},
+				DockerConfig:    apicontainer.DockerConfig{Config: readinessConfig},
+			},
+			{Name: "sidecar"},
+			{Name: "init"},
+		},
+	}
+	require.NoError(t, task.initializeContainerReadinessDependencies())
+	expected := []apicontainer.DependsOn{
+		{ContainerName: "init", Condition: "SUCCESS"},
+		{ContainerName: "sidecar", Condition: "PORT:8080"},
+	}
+	assert.Equal(t, expected, task.Containers[0].GetDependsOn())
+	assert.Empty(t, task.Containers[1].GetDependsOn())
+
+	// the dependencies are added once when the task is initialized again
+	require.NoError(t, task.initializeContainerReadinessDependencies())
+	assert.Equal(t, expected, task.Containers[0].GetDependsOn())
+}
+
+func TestInitializeContainerReadinessDependenciesUnknownContainer(t *testing.T) {
+	for _, dependency := range []string{"missing", "app"} {
+		task := &Task{
+			Containers: []*apicontainer.Container{{
+				Name: "app",
+				DockerConfig: apicontainer.DockerConfig{
+					Config: aws.String(`{"Labels":{"com.amazonaws.ecs.readiness-depends-on":` +
+						`"[{\"containerName\":\"` + dependency + `\",\"condition\":\"FILE:/ready\"}]"}}`),
+				},
+			}},
+		}
+		assert.Error(t, task.initializeContainerReadinessDependencies(), dependency)
+	}
+}
+
+func TestInitializeContainerHealthProbes(t *testing.T) {
+	probeConfig := aws.String(`{"Labels":{"com.amazonaws.ecs.health-probe":"{\"type\":\"tcp\",\"port\":8080}"}}`)
+	task := &Task{
+		Arn: "arn:aws:ecs:region:account-id:task/task-id",
+		Containers: []*apicontainer.Container{
+			{Name: "probed", DockerConfig: apicontainer.DockerConfig{Config: probeConfig}},
+			{Name: "docker", HealthCheckType: apicontainer.DockerHealthCheckType,
+				DockerConfig: apicontainer.DockerConfig{Config: probeConfig}},
+			{Name: "none"},
+		},
+	}
+	require.NoError(t, task.initializeContainerHealthProbes())
+
+	assert.Equal(t, apicontainer.AgentHealthCheckType, task.Containers[0].HealthCheckType)
+	assert.Equal(t, uint16(8080), task.Containers[0].HealthProbe.Port)
+	assert.Equal(t, apicontainer.DockerHealthCheckType, task.Containers[1].HealthCheckType)
+	assert.Nil(t, task.Containers[1].HealthProbe)
+	assert.Empty(t, task.Containers[2].HealthCheckType)
+}
+
+func TestInitializeContainerHealthProbesInvalidLabel(t *testing.T) {
+	task := &Task{
+		Containers: []*apicontainer.Container{{
+			Name: "probed",
+			DockerConfig: apicontainer.DockerConfig{
+				Config: aws.String(`{"Labels":{"com.amazonaws.ecs.health-probe":"{\"type\":\"udp\"}"}}`),
+			},
+		}},
+	}
+	assert.Error(t, task.initializeContainerHealthProbes())
+}
+
+func TestInitializeContainerBandwidthLimits(t *testing.T) {
+	limitsConfig := aws.String(`{"Labels":{"com.amazonaws.ecs.bandwidth-limits":"{\"egress\":\"20mbit\"}"}}`)
+	expected := &apicontainer.BandwidthLimits{EgressBitsPerSecond: 20000000}
+
+	t.Run("awsvpc", func(t *testing.T) {
+		task := &Task{
+			NetworkMode: AWSVPCNetworkMode,
+			Containers: []*apicontainer.Container{
+				{Name: "app", DockerConfig: apicontainer.DockerConfig{Config: limitsConfig}},
+				{Name: "sidecar"},
+			},
+		}
+		require.NoError(t, task.initializeContainerBandwidthLimits())
+		assert.Equal(t, expected, task.GetBandwidthLimits())
+		assert.Equal(t, expected, task.Containers[1].BandwidthLimits)
+	})
+	t.Run("bridge", func(t *testing.T) {
+		task := &Task{
+			NetworkMode: BridgeNetworkMode,
+			Containers: []*apicontainer.Container{
+				{Name: "app", DockerConfig: apicontainer.DockerConfig{Config: limitsConfig}},
+				{Name: "sidecar"},
+			},
+		}
+		require.NoError(t, task.initializeContainerBandwidthLimits())
+		assert.Nil(t, task.GetBandwidthLimits())
+		assert.Equal(t, expected, task.Containers[0].BandwidthLimits)
+		assert.Nil(t, task.Containers[1].BandwidthLimits)
+	})
+	t.Run("host", func(t *testing.T) {
+		task := &Task{
+			NetworkMode: HostNetworkMode,
+			Containers: []*apicontainer.Container{
+				{Name: "app", DockerConfig: apicontainer.DockerConfig{Config: limitsConfig}},
+			},
+		}
+		assert.Error(t, task.initializeContainerBandwidthLimits())
+	})
+}
+
+func TestInitializeContainerBandwidthLimitsDifferentLimits(t *testing.T) {
+	task := &Task{
+		NetworkMode: AWSVPCNetworkMode,
+		Containers: []*apicontainer.Container{
+			{Name: "app", DockerConfig: apicontainer.DockerConfig{
+				Config: aws.String(`{"Labels":{"com.amazonaws.ecs.bandwidth-limits":"{\"egress\":\"20mbit\"}"}}`),
+			}},
+			{Name: "sidecar", DockerConfig: apicontainer.DockerConfig{
+				Config: aws.String(`{"Labels":{"com.amazonaws.ecs.bandwidth-limits":"{\"egress\":\"10mbit\"}"}}`),
+			}},
+		},
+	}
+	assert.Error(t, task.initializeContainerBandwidthLimits())
+}
+
 func TestInitializeContainerOrderingWithLinksAndVolumesFrom(t *testing.T) {
 	containerWithOnlyVolume := &apicontainer.Container{
 		Name:        "myName",
@@ -5338,6 +5523,60 @@ func TestToHostResources(t *testing.T) {
 	}
 }
 
+func TestToHostResourcesAgentAccountedResources(t *testing.T) {
+	task := &Task{
+		Arn: "arn",
+		Containers: []*apicontainer.Container{
+			{
+				Name: "c1",
+				DockerConfig: apicontainer.DockerConfig{
+					Config: strptr(`{"Labels":{"` + apicontainer.EphemeralStorageLabel + `":"1024","` +
+						apicontainer.HugePagesLabel + `":"64"}}`),
+				},
+			},
+			{
+				Name: "c2",
+				DockerConfig: apicontainer.DockerConfig{
+					Config: strptr(`{"Labels":{"` + apicontainer.EphemeralStorageLabel + `":"512"}}`),
+				},
+			},
+			{
+				Name: "c3",
+			},
+		},
+	}
+	require.NoError(t, task.initializeContainerHostResourceRequests())
+
+	resources := task.ToHostResources()
+	assert.Equal(t, int32(1536), resources["EPHEMERAL_STORAGE"].IntegerValue)
+	assert.Equal(t, int32(64), resources["HUGEPAGES"].IntegerValue)
+	assert.NotContains(t, resources, "ENI", "Tasks not in awsvpc network mode don't use an ENI")
+
+	task.NetworkMode = AWSVPCNetworkMode
+	resources = task.ToHostResources()
+	assert.Equal(t, int32(1), resources["ENI"].IntegerValue)
+
+	task.Containers = task.Containers[2:]
+	resources = task.ToHostResources()
+	assert.NotContains(t, resources, "EPHEMERAL_STORAGE")
+	assert.NotContains(t, resources, "HUGEPAGES")
+}
+
+func TestInitializeContainerHostResourceRequestsInvalidLabel(t *testing.T) {
+	task := &Task{
+		Containers: []*apicontainer.Container{
+			{
+				Name: "c1",
+				DockerConfig: apicontainer.DockerConfig{
+					Config: strptr(`{"Labels":{"` + apicontainer.HugePagesLabel + `":"lots"}}`),
+				},
+			},
+		},
+	}
+	assert.EqualError(t, task.initializeContainerHostResourceRequests(),
+		`container c1: invalid com.amazonaws.ecs.hugepages label "lots", it must be a number of MiB`)
+}
+
 func TestRemoveVolumes(t *testing.T) {
 	task := &Task{
 		Volumes: []TaskVolume{
@@ -18,6 +18,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
 	"time"
 
 	agentacs "github.com/aws/amazon-ecs-agent/agent/acs/session"
@@ -30,6 +33,8 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/sdkclientfactory"
 	dockerdoctor "github.com/aws/amazon-ecs-agent/agent/doctor" // for Docker specific container instance health checks
+	"github.com/aws/amazon-ecs-agent/agent/doctor/remediation"
+	"github.com/aws/amazon-ecs-agent/agent/drain"
 	"github.com/aws/amazon-ecs-agent/agent/ebs"
 	"github.com/aws/amazon-ecs-agent/agent/ecscni"
 	"github.com/aws/amazon-ecs-agent/agent/engine"
@@ -41,6 +46,8 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/eni/watcher"
 	"github.com/aws/amazon-ecs-agent/agent/eventhandler"
 	"github.com/aws/amazon-ecs-agent/agent/handlers"
+	v1 "github.com/aws/amazon-ecs-agent/agent/handlers/v1"
+	"github.com/aws/amazon-ecs-agent/agent/hostresources"
 	"github.com/aws/amazon-ecs-agent/agent/sighandlers"
 	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
 	"github.com/aws/amazon-ecs-agent/agent/statemanager"
@@ -90,6 +97,14 @@ const (
 
 	blackholed = "blackholed"
 
+	// remediationAuditFileName is the file in the data directory that the audit trail of the remediation
+	// actions is appended to when the remediation policy doesn't set one.
+	remediationAuditFileName = "remediation-audit.log"
+
+	// drainFileName is the file in the data directory whose creation drains the container instance, when
+	// the drain policy has the drain-file trigger and doesn't set the file
+	drainFileName = "drain"
+
 	instanceIdBackoffMin      = time.Second
 	instanceIdBackoffMax      = time.Second * 5
 	instanceIdBackoffJitter   = 0.2
@@ -145,6 +160,7 @@ type ecsAgent struct {
 	cfg                         *config.Config
 	dataClient                  data.Client
 	dockerClient                dockerapi.DockerClient
+	dockerRootDir               string
 	containerInstanceARN        string
 	credentialProvider          *aws_credentials.Credentials
 	credentialsCache            *aws.CredentialsCache
@@ -165,6 +181,10 @@ type ecsAgent struct {
 	resourceFields              *taskresource.ResourceFields
 	availabilityZone            string
 	latestSeqNumberTaskManifest *int64
+	drainer                     *drain.Drainer
+	drainerLock                 sync.Mutex
+	// drainRequestTrigger is the trigger of the drain endpoint, nil unless the drain policy has it
+	drainRequestTrigger *drain.RequestTrigger
 }
 
 // newAgent returns a new ecsAgent object, but does not start anything
@@ -234,7 +254,7 @@ func newAgent(blackholeEC2Metadata bool, acceptInsecureCert *bool) (agent, error
 
 	var dataClient data.Client
 	if cfg.Checkpoint.Enabled() {
-		dataClient, err = data.New(cfg.DataDir)
+		dataClient, err = data.NewWithBackupFallback(cfg.DataDir, cfg.StateBackupDir)
 		if err != nil {
 			logger.Critical("Error creating data client", logger.Fields{
 				field.Error: err,
@@ -260,7 +280,7 @@ func newAgent(blackholeEC2Metadata bool, acceptInsecureCert *bool) (agent, error
 		nil,
 	)
 	initialSeqNumber := int64(-1)
-	return &ecsAgent{
+	agent := &ecsAgent{
 		ctx:               ctx,
 		cancel:            cancel,
 		ec2MetadataClient: ec2MetadataClient,
@@ -280,10 +300,11 @@ func newAgent(blackholeEC2Metadata bool, acceptInsecureCert *bool) (agent, error
 		daemonManagers:              make(map[string]dm.DaemonManager),
 		cniClient:                   ecscni.NewClient(cfg.CNIPluginsPath),
 		metadataManager:             metadataManager,
-		terminationHandler:          sighandlers.StartDefaultTerminationHandler,
 		mobyPlugins:                 mobypkgwrapper.NewPlugins(),
 		latestSeqNumberTaskManifest: &initialSeqNumber,
-	}, nil
+	}
+	agent.terminationHandler = sighandlers.NewTerminationHandler(agent.drainOnShutdown)
+	return agent, nil
 }
 
 func (agent *ecsAgent) getConfig() *config.Config {
@@ -324,7 +345,7 @@ func (agent *ecsAgent) start() int {
 		return exitcodes.ExitError
 	}
 	clientFactory := ecsclient.NewECSClientFactory(agent.credentialsCache, cfgAccessor, agent.ec2MetadataClient,
-		version.String(), ecsclient.WithIPv6PortBindingExcluded(true))
+		version.String(), ecsclient.WithIPv6PortBindingExcluded(agent.shouldExcludeIPv6PortBinding()))
 	client, err := clientFactory.NewClient()
 	if err != nil {
 		logger.Critical("Unable to create new ECS client", logger.Fields{
@@ -336,6 +357,17 @@ func (agent *ecsAgent) start() int {
 	return agent.doStart(containerChangeEventStream, credentialsManager, state, imageManager, client, execcmd.NewManager())
 }
 
+// shouldExcludeIPv6PortBinding returns whether the IPv6 port bindings reported by docker should be excluded
+// from the container state changes sent to ECS. They are always reported on IPv6-only instances, where they
+// are the only port bindings of bridge mode containers.
+func (agent *ecsAgent) shouldExcludeIPv6PortBinding() bool {
+	cfg := agent.getConfig()
+	if cfg.InstanceIPCompatibility.IsIPv6Only() {
+		return false
+	}
+	return cfg.ShouldExcludeIPv6PortBinding.Enabled()
+}
+
 // doStart is the worker invoked by start for starting the ECS Agent. This involves
 // initializing the docker task engine, state saver, image manager, credentials
 // manager, poll and telemetry sessions, api handler etc
@@ -383,6 +415,7 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 		Type:           utils.Strptr("STRINGSET"),
 		StringSetValue: gpuIDs,
 	}
+	agent.appendAgentAccountedHostResources(hostResources)
 
 	// Create the task engine
 	taskEngine, currentEC2InstanceID, err := agent.newTaskEngine(
@@ -396,6 +429,12 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 	// Start termination handler in goroutine
 	go agent.terminationHandler(state, agent.dataClient, taskEngine, agent.cancel)
 
+	// Run the tasks of the local task definition files instead of registering with ECS
+	if agent.cfg.LocalTasksDir != "" {
+		return agent.startLocalTasks(containerChangeEventStream, credentialsManager, state, imageManager,
+			taskEngine, client)
+	}
+
 	// If part of ASG, wait until instance is being set up to go in service before registering with cluster
 	if agent.cfg.WarmPoolsSupport.Enabled() {
 		err := agent.waitUntilInstanceInService(asgLifecyclePollWait, asgLifecyclePollMax, targetLifecycleMaxRetryCount)
@@ -510,7 +549,9 @@ func (agent *ecsAgent) doStart(containerChangeEventStream *eventstream.EventStre
 		seelog.Warnf("Error starting doctor, healthchecks won't be running: %v", err)
 	} else {
 		seelog.Debug("Doctor healthchecks set up properly.")
+		agent.startRemediation(doctor, client, imageManager)
 	}
+	agent.startDraining(doctor, client, state)
 
 	// Begin listening to the docker daemon and saving changes
 	taskEngine.SetDataClient(agent.dataClient)
@@ -596,6 +637,69 @@ func (agent *ecsAgent) getTargetLifecycle(maxRetries int) (string, error) {
 	return targetState, err
 }
 
+// appendAgentAccountedHostResources adds the host resources which only the agent accounts for: the space
+// available on the filesystem of the docker data root, the huge pages and the ENI slots for awsvpc tasks.
+// Resources whose capacity can't be found aren't accounted for.
+func (agent *ecsAgent) appendAgentAccountedHostResources(hostResources map[string]types.Resource) {
+	info, err := agent.dockerClient.Info(agent.ctx, dockerclient.InfoTimeout)
+	if err == nil {
+		agent.dockerRootDir = info.DockerRootDir
+		var storage int32
+		storage, err = hostresources.EphemeralStorageMiB(info.DockerRootDir)
+		if err == nil {
+			hostResources[engine.EPHEMERALSTORAGE] = types.Resource{
+				Name:         utils.Strptr(engine.EPHEMERALSTORAGE),
+				Type:         utils.Strptr("INTEGER"),
+				IntegerValue: storage,
+			}
+		}
+	}
+	if err != nil {
+		logger.Info("Ephemeral storage is not accounted for", logger.Fields{field.Error: err})
+	}
+
+	if hugePages, err := hostresources.HugePagesMiB(); err != nil {
+		logger.Info("Huge pages are not accounted for", logger.Fields{field.Error: err})
+	} else if hugePages > 0 {
+		hostResources[engine.HUGEPAGES] = types.Resource{
+			Name:         utils.Strptr(engine.HUGEPAGES),
+			Type:         utils.Strptr("INTEGER"),
+			IntegerValue: hugePages,
+		}
+	}
+
+	if slots := agent.taskENISlots(); slots > 0 {
+		hostResources[engine.ENI] = types.Resource{
+			Name:         utils.Strptr(engine.ENI),
+			Type:         utils.Strptr("INTEGER"),
+			IntegerValue: slots,
+		}
+	}
+}
+
+// taskENISlots returns the number of awsvpc tasks the instance can host. ECS_TASK_ENI_SLOTS takes precedence.
+// Without ENI trunking the slots are the ENIs the instance type supports minus the primary ENI; with ENI
+// trunking the number of branch ENIs isn't known to the instance, so it has to be configured.
+func (agent *ecsAgent) taskENISlots() int32 {
+	if agent.cfg.TaskENISlots > 0 {
+		return int32(agent.cfg.TaskENISlots)
+	}
+	if !agent.cfg.TaskENIEnabled.Enabled() || agent.cfg.ENITrunkingEnabled.Enabled() || agent.cfg.External.Enabled() {
+		return 0
+	}
+	iid, err := agent.ec2MetadataClient.InstanceIdentityDocument()
+	if err != nil {
+		logger.Info("ENI slots are not accounted for, unable to get the instance type", logger.Fields{field.Error: err})
+		return 0
+	}
+	maxENIs, err := agent.ec2Client.DescribeMaximumNetworkInterfaces(iid.InstanceType)
+	if err != nil {
+		logger.Info("ENI slots are not accounted for", logger.Fields{field.Error: err})
+		return 0
+	}
+	return maxENIs - 1
+}
+
 // newTaskEngine creates a new docker task engine object. It tries to load the
 // local state if needed, else initializes a new one
 func (agent *ecsAgent) newTaskEngine(containerChangeEventStream *eventstream.EventStream,
@@ -671,11 +775,123 @@ func (agent *ecsAgent) newDoctorWithHealthchecks(cluster, containerInstanceARN s
 	healthcheckList := []doctor.Healthcheck{
 		runtimeHealthCheck,
 	}
+	healthcheckList = append(healthcheckList, newDependencyHealthchecks(agent.cfg, agent.dockerRootDir)...)
+	if agent.cfg.Checkpoint.Enabled() {
+		healthcheckList = append(healthcheckList, dockerdoctor.NewDataStoreHealthcheck(agent.dataClient))
+	}
 
 	// set up the doctor and return it
 	return doctor.NewDoctor(healthcheckList, cluster, containerInstanceARN)
 }
 
+// policyHasAction returns whether a remediation policy takes an action for any healthcheck.
+func policyHasAction(policy *config.RemediationPolicy, name string) bool {
+	for _, names := range policy.Actions {
+		if slices.Contains(names, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// startRemediation makes the doctor take the remediation actions of the remediation policy when its
+// healthchecks keep failing. No action is taken when there's no remediation policy.
+func (agent *ecsAgent) startRemediation(doctor *doctor.Doctor, client ecs.ECSClient, imageManager engine.ImageManager) {
+	policy := agent.cfg.RemediationPolicy
+	if policy == nil {
+		return
+	}
+	// Without checkpointing the guard rails don't outlive the agent restart that restarting docker causes, and
+	// nothing would prevent a restart loop
+	if !agent.cfg.Checkpoint.Enabled() && !policy.DryRun && policyHasAction(policy, remediation.RestartDockerActionName) {
+		logger.Error("Remediation policy restarts docker, which requires checkpointing (ECS_CHECKPOINT), no remediation action will be taken")
+		return
+	}
+	auditFile := policy.AuditFile
+	if auditFile == "" {
+		auditFile = filepath.Join(agent.cfg.DataDir, remediationAuditFileName)
+	}
+	remediator, err := remediation.NewRemediator(agent.ctx, policy, remediation.NewAuditFile(auditFile),
+		agent.dataClient, []remediation.Action{
+			remediation.NewRestartDockerAction(),
+			remediation.NewClearImageCacheAction(imageManager),
+			remediation.NewDrainInstanceAction(client, agent.containerInstanceARN),
+		})
+	if err != nil {
+		logger.Error("Unable to set up remediation, no remediation action will be taken", logger.Fields{
+			field.Error: err,
+		})
+		return
+	}
+	doctor.AddResultsHandler(remediator.HandleResults)
+	logger.Info("Remediation of failed healthchecks enabled", logger.Fields{
+		"dryRun":    policy.DryRun,
+		"auditFile": auditFile,
+	})
+}
+
+// startDraining starts draining the container instance when one of the triggers of the drain policy fires.
+// Nothing is started when there's no drain policy.
+func (agent *ecsAgent) startDraining(doctor *doctor.Doctor, client ecs.ECSClient, state dockerstate.TaskEngineState) {
+	policy := agent.cfg.DrainPolicy
+	if policy == nil {
+		return
+	}
+	drainFile := policy.DrainFile
+	if drainFile == "" {
+		drainFile = filepath.Join(agent.cfg.DataDir, drainFileName)
+	}
+	healthcheckTrigger := drain.NewHealthcheckTrigger(policy.HealthcheckFailureThreshold)
+	requestTrigger := drain.NewRequestTrigger()
+	drainer, err := drain.NewDrainer(policy, []drain.Trigger{
+		drain.NewMaintenanceEventTrigger(agent.ec2MetadataClient),
+		drain.NewASGTerminationTrigger(agent.ec2MetadataClient),
+		drain.NewDrainFileTrigger(drainFile),
+		healthcheckTrigger,
+		requestTrigger,
+	}, client, agent.containerInstanceARN, state)
+	if err != nil {
+		logger.Error("Invalid drain policy, the container instance won't be drained", logger.Fields{
+			field.Error: err,
+		})
+		return
+	}
+	if doctor != nil {
+		doctor.AddResultsHandler(healthcheckTrigger.HandleResults)
+	}
+	if slices.Contains(policy.Triggers, drain.TriggerDrainEndpoint) {
+		agent.drainRequestTrigger = requestTrigger
+	}
+	agent.drainerLock.Lock()
+	agent.drainer = drainer
+	agent.drainerLock.Unlock()
+	go drainer.Start(agent.ctx)
+	logger.Info("Draining of the container instance enabled", logger.Fields{
+		"triggers":  policy.Triggers,
+		"drainFile": drainFile,
+	})
+}
+
+// drainRequester returns what the drain endpoint of the introspection server requests drains from, nil when
+// the drain policy doesn't have the drain endpoint trigger.
+func (agent *ecsAgent) drainRequester() v1.DrainRequester {
+	if agent.drainRequestTrigger == nil {
+		return nil
+	}
+	return agent.drainRequestTrigger
+}
+
+// drainOnShutdown is called by the termination handler before the agent exits. It drains the container
+// instance and waits for its tasks to stop when the host is shutting down and the drain policy asks for it.
+func (agent *ecsAgent) drainOnShutdown() {
+	agent.drainerLock.Lock()
+	drainer := agent.drainer
+	agent.drainerLock.Unlock()
+	if drainer != nil {
+		drainer.DrainOnShutdown()
+	}
+}
+
 // setClusterInConfig sets the cluster name in the config object based on
 // previous state. It returns an error if there's a mismatch between the
 // the current cluster name with what's restored from the cluster state
@@ -959,13 +1175,19 @@ func (agent *ecsAgent) startAsyncRoutines(
 		go imageManager.StartImageCleanupProcess(agent.ctx)
 	}
 
+	// Start the periodic state database backups
+	if agent.cfg.Checkpoint.Enabled() && agent.cfg.StateBackupInterval > 0 {
+		go agent.startStateBackups(agent.ctx)
+	}
+
 	// Start automatic spot instance draining poller routine
 	if agent.cfg.SpotInstanceDrainingEnabled.Enabled() {
 		go agent.startSpotInstanceDrainingPoller(agent.ctx, client)
 	}
 
 	// Agent introspection api
-	go handlers.ServeIntrospectionHTTPEndpoint(agent.ctx, &agent.containerInstanceARN, taskEngine, agent.cfg)
+	go handlers.ServeIntrospectionHTTPEndpoint(agent.ctx, &agent.containerInstanceARN, taskEngine, agent.cfg,
+		agent.drainRequester())
 
 	telemetryMessages := make(chan ecstcs.TelemetryMessage, telemetryChannelDefaultBufferSize)
 	healthMessages := make(chan ecstcs.HealthMessage, telemetryChannelDefaultBufferSize)
@@ -975,9 +1197,9 @@ func (agent *ecsAgent) startAsyncRoutines(
 	// Start serving the endpoint to fetch IAM Role credentials and other task metadata
 	if agent.cfg.TaskMetadataAZDisabled {
 		// send empty availability zone
-		go handlers.ServeTaskHTTPEndpoint(agent.ctx, credentialsManager, state, client, agent.containerInstanceARN, agent.cfg, statsEngine, "", agent.vpc)
+		go handlers.ServeTaskHTTPEndpoint(agent.ctx, credentialsManager, state, client, agent.containerInstanceARN, agent.cfg, statsEngine, "", agent.vpc, agent.dataClient)
 	} else {
-		go handlers.ServeTaskHTTPEndpoint(agent.ctx, credentialsManager, state, client, agent.containerInstanceARN, agent.cfg, statsEngine, agent.availabilityZone, agent.vpc)
+		go handlers.ServeTaskHTTPEndpoint(agent.ctx, credentialsManager, state, client, agent.containerInstanceARN, agent.cfg, statsEngine, agent.availabilityZone, agent.vpc, agent.dataClient)
 	}
 
 	// Start sending events to the backend
@@ -1000,6 +1222,9 @@ func (agent *ecsAgent) startAsyncRoutines(
 		seelog.Infof("Metrics disabled on the instance.")
 		return
 	}
+	if doctor != nil {
+		doctor.AddHealthcheck(dockerdoctor.NewTCSSessionHealthcheck(session, dockerdoctor.DefaultMaxSessionConnectionAge))
+	}
 
 	go session.Start(agent.ctx)
 }
@@ -1015,6 +1240,30 @@ func (agent *ecsAgent) startSpotInstanceDrainingPoller(ctx context.Context, clie
 	}
 }
 
+// startStateBackups periodically writes a snapshot of the state database to the backup directory
+// until the context is cancelled.
+func (agent *ecsAgent) startStateBackups(ctx context.Context) {
+	ticker := time.NewTicker(agent.cfg.StateBackupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			backupPath, err := agent.dataClient.Backup(agent.cfg.StateBackupDir, agent.cfg.StateBackupCount)
+			if err != nil {
+				logger.Error("Unable to back up state database", logger.Fields{
+					field.Error: err,
+				})
+				continue
+			}
+			logger.Debug("Backed up state database", logger.Fields{
+				"backup": backupPath,
+			})
+		}
+	}
+}
+
 // spotInstanceDrainingPoller returns true if spot instance interruption has been
 // set AND the container instance state is successfully updated to DRAINING.
 func (agent *ecsAgent) spotInstanceDrainingPoller(client ecs.ECSClient) bool {
@@ -1121,6 +1370,9 @@ func (agent *ecsAgent) startACSSession(
 		agent.ebsWatcher,
 		updater.NewUpdater(agent.cfg, state, agent.dataClient, taskEngine).AddAgentUpdateHandlers,
 	)
+	if doctor != nil {
+		doctor.AddHealthcheck(dockerdoctor.NewACSSessionHealthcheck(acsSession, dockerdoctor.DefaultMaxSessionConnectionAge))
+	}
 	logger.Info("Beginning Polling for updates")
 	sessionEndReason := acsSession.Start(agent.ctx)
 	if sessionEndReason == nil {
@@ -28,6 +28,7 @@ import (
 	mock_factory "github.com/aws/amazon-ecs-agent/agent/app/factory/mocks"
 	app_mocks "github.com/aws/amazon-ecs-agent/agent/app/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/config/ipcompatibility"
 	mock_containermetadata "github.com/aws/amazon-ecs-agent/agent/containermetadata/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/data"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
@@ -49,6 +50,9 @@ import (
 	mock_loader "github.com/aws/amazon-ecs-agent/agent/utils/loader/mocks"
 	mock_mobypkgwrapper "github.com/aws/amazon-ecs-agent/agent/utils/mobypkgwrapper/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/version"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
+	ecsclient "github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs/client"
 	mock_ecs "github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs/mocks"
 	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
 	mock_credentials "github.com/aws/amazon-ecs-agent/ecs-agent/credentials/mocks"
@@ -57,6 +61,8 @@ import (
 	md "github.com/aws/amazon-ecs-agent/ecs-agent/manageddaemon"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	ecsservice "github.com/aws/aws-sdk-go-v2/service/ecs"
 	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/smithy-go"
@@ -209,6 +215,7 @@ func TestDoStartNewTaskEngineError(t *testing.T) {
 	gomock.InOrder(
 		dockerClient.EXPECT().SupportedVersions().Return(apiVersions),
 		client.EXPECT().GetHostResources().Return(testHostResource, nil),
+		dockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 		saveableOptionFactory.EXPECT().AddSaveable("TaskEngine", gomock.Any()).Return(nil),
 		saveableOptionFactory.EXPECT().AddSaveable("ContainerInstanceArn", gomock.Any()).Return(nil),
 		saveableOptionFactory.EXPECT().AddSaveable("Cluster", gomock.Any()).Return(nil),
@@ -272,6 +279,7 @@ func TestDoStartRegisterContainerInstanceErrorTerminal(t *testing.T) {
 
 	gomock.InOrder(
 		client.EXPECT().GetHostResources().Return(testHostResource, nil),
+		dockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 		mockCredentialsProvider.EXPECT().Retrieve(gomock.Any()).Return(aws.Credentials{}, nil),
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{""}, nil),
 		dockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
@@ -288,6 +296,7 @@ func TestDoStartRegisterContainerInstanceErrorTerminal(t *testing.T) {
 	// Cancel the context to cancel async routines
 	defer cancel()
 	agent := &ecsAgent{
+		dataClient:        data.NewNoopClient(),
 		ctx:               ctx,
 		cfg:               &cfg,
 		pauseLoader:       mockPauseLoader,
@@ -334,6 +343,7 @@ func TestDoStartRegisterContainerInstanceErrorNonTerminal(t *testing.T) {
 
 	gomock.InOrder(
 		client.EXPECT().GetHostResources().Return(testHostResource, nil),
+		dockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 		mockCredentialsProvider.EXPECT().Retrieve(gomock.Any()).Return(aws.Credentials{}, nil),
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{""}, nil),
 		dockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
@@ -349,6 +359,7 @@ func TestDoStartRegisterContainerInstanceErrorNonTerminal(t *testing.T) {
 	// Cancel the context to cancel async routines
 	defer cancel()
 	agent := &ecsAgent{
+		dataClient:        data.NewNoopClient(),
 		ctx:               ctx,
 		cfg:               &cfg,
 		dockerClient:      dockerClient,
@@ -375,6 +386,7 @@ func TestDoStartWarmPoolsError(t *testing.T) {
 	gomock.InOrder(
 		dockerClient.EXPECT().SupportedVersions().Return(apiVersions),
 		client.EXPECT().GetHostResources().Return(testHostResource, nil),
+		dockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 	)
 
 	cfg := getTestConfig()
@@ -385,6 +397,7 @@ func TestDoStartWarmPoolsError(t *testing.T) {
 	terminationHandlerChan := make(chan bool)
 	terminationHandlerInvoked := false
 	agent := &ecsAgent{
+		dataClient:        data.NewNoopClient(),
 		ctx:               ctx,
 		cfg:               &cfg,
 		dockerClient:      dockerClient,
@@ -501,6 +514,7 @@ func testDoStartHappyPathWithConditions(t *testing.T, blackholed bool, warmPools
 
 	gomock.InOrder(
 		client.EXPECT().GetHostResources().Return(testHostResource, nil),
+		dockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 		mockCredentialsProvider.EXPECT().Retrieve(gomock.Any()).Return(aws.Credentials{}, nil),
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{""}, nil),
 		dockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
@@ -1549,6 +1563,7 @@ func TestRegisterContainerInstanceInvalidParameterTerminalError(t *testing.T) {
 
 	gomock.InOrder(
 		client.EXPECT().GetHostResources().Return(testHostResource, nil),
+		dockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 		mockCredentialsProvider.EXPECT().Retrieve(gomock.Any()).Return(aws.Credentials{}, nil),
 		mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
 		dockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
@@ -1563,6 +1578,7 @@ func TestRegisterContainerInstanceInvalidParameterTerminalError(t *testing.T) {
 	// Cancel the context to cancel async routines
 	defer cancel()
 	agent := &ecsAgent{
+		dataClient:        data.NewNoopClient(),
 		ctx:               ctx,
 		ec2MetadataClient: mockEC2Metadata,
 		cfg:               &cfg,
@@ -1638,6 +1654,7 @@ func TestRegisterContainerInstanceExceptionErrors(t *testing.T) {
 
 			gomock.InOrder(
 				client.EXPECT().GetHostResources().Return(testHostResource, nil),
+				dockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{}, nil),
 				mockCredentialsProvider.EXPECT().Retrieve(gomock.Any()).Return(aws.Credentials{}, nil),
 				mockMobyPlugins.EXPECT().Scan().AnyTimes().Return([]string{}, nil),
 				dockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
@@ -1656,6 +1673,7 @@ func TestRegisterContainerInstanceExceptionErrors(t *testing.T) {
 			defer cancel()
 
 			agent := &ecsAgent{
+				dataClient:        data.NewNoopClient(),
 				ctx:               ctx,
 				ec2MetadataClient: mockEC2Metadata,
 				cfg:               &cfg,
@@ -1784,6 +1802,46 @@ func TestGetContainerInstanceTagsFromEC2APIFailToDescribeECSTagsForInstance(t *t
 	assert.Nil(t, resTags)
 }
 
+func TestTaskENISlots(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+	ec2Client := mock_ec2.NewMockClient(ctrl)
+	cfg := getTestConfig()
+	cfg.TaskENIEnabled = config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled}
+	cfg.ENITrunkingEnabled = config.BooleanDefaultTrue{Value: config.ExplicitlyDisabled}
+	agent := &ecsAgent{
+		cfg:               &cfg,
+		ec2MetadataClient: ec2MetadataClient,
+		ec2Client:         ec2Client,
+	}
+
+	// Without ENI trunking, the slots are the ENIs of the instance type minus the primary ENI
+	gomock.InOrder(
+		ec2MetadataClient.EXPECT().InstanceIdentityDocument().Return(imds.InstanceIdentityDocument{
+			InstanceType: "m5.large",
+		}, nil),
+		ec2Client.EXPECT().DescribeMaximumNetworkInterfaces("m5.large").Return(int32(3), nil),
+	)
+	assert.Equal(t, int32(2), agent.taskENISlots())
+
+	ec2MetadataClient.EXPECT().InstanceIdentityDocument().Return(imds.InstanceIdentityDocument{
+		InstanceType: "m5.large",
+	}, nil)
+	ec2Client.EXPECT().DescribeMaximumNetworkInterfaces("m5.large").Return(int32(0), errors.New("error"))
+	assert.Zero(t, agent.taskENISlots(), "Slots should not be accounted for when the instance type can't be described")
+
+	// The configured slots take precedence
+	cfg.TaskENISlots = 15
+	assert.Equal(t, int32(15), agent.taskENISlots())
+
+	// The number of branch ENIs isn't known to the instance
+	cfg.TaskENISlots = 0
+	cfg.ENITrunkingEnabled = config.BooleanDefaultTrue{Value: config.ExplicitlyEnabled}
+	assert.Zero(t, agent.taskENISlots())
+}
+
 func TestGetHostPrivateIPv4AddressFromEC2Metadata(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -2090,3 +2148,89 @@ func TestLoadManagedDaemonImage(t *testing.T) {
 		})
 	}
 }
+
+func TestShouldExcludeIPv6PortBinding(t *testing.T) {
+	tcs := []struct {
+		name            string
+		ipCompatibility ipcompatibility.IPCompatibility
+		exclude         config.BooleanDefaultTrue
+		expected        bool
+	}{
+		{
+			name:            "dual stack instance excludes IPv6 port bindings by default",
+			ipCompatibility: ipcompatibility.NewIPCompatibility(true, true),
+			exclude:         config.BooleanDefaultTrue{Value: config.NotSet},
+			expected:        true,
+		},
+		{
+			name:            "dual stack instance with exclusion disabled",
+			ipCompatibility: ipcompatibility.NewIPCompatibility(true, true),
+			exclude:         config.BooleanDefaultTrue{Value: config.ExplicitlyDisabled},
+			expected:        false,
+		},
+		{
+			name:            "IPv4-only instance excludes IPv6 port bindings",
+			ipCompatibility: ipcompatibility.NewIPv4OnlyCompatibility(),
+			exclude:         config.BooleanDefaultTrue{Value: config.ExplicitlyEnabled},
+			expected:        true,
+		},
+		{
+			name:            "IPv6-only instance always reports IPv6 port bindings",
+			ipCompatibility: ipcompatibility.NewIPv6OnlyCompatibility(),
+			exclude:         config.BooleanDefaultTrue{Value: config.ExplicitlyEnabled},
+			expected:        false,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			agent := &ecsAgent{cfg: &config.Config{
+				InstanceIPCompatibility:      tc.ipCompatibility,
+				ShouldExcludeIPv6PortBinding: tc.exclude,
+			}}
+			assert.Equal(t, tc.expected, agent.shouldExcludeIPv6PortBinding())
+		})
+	}
+}
+
+func TestIPv6PortBindingReportedOnIPv6OnlyInstance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	// A CA bundle from the environment can't be added to the HTTP client of the ECS client
+	t.Setenv("AWS_CA_BUNDLE", "")
+
+	cfg := config.DefaultConfig()
+	cfg.AWSRegion = "us-west-2"
+	cfg.Cluster = "cluster"
+	cfg.InstanceIPCompatibility = ipcompatibility.NewIPv6OnlyCompatibility()
+	agent := &ecsAgent{cfg: &cfg}
+	cfgAccessor, err := config.NewAgentConfigAccessor(&cfg)
+	require.NoError(t, err)
+
+	ipv6PortBinding := ecstypes.NetworkBinding{
+		BindIP:        aws.String("::"),
+		ContainerPort: aws.Int32(80),
+		HostPort:      aws.Int32(32768),
+		Protocol:      ecstypes.TransportProtocolTcp,
+	}
+	mockSubmitStateClient := mock_ecs.NewMockECSSubmitStateSDK(ctrl)
+	mockSubmitStateClient.EXPECT().SubmitContainerStateChange(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *ecsservice.SubmitContainerStateChangeInput,
+			_ ...func(*ecsservice.Options)) (*ecsservice.SubmitContainerStateChangeOutput, error) {
+			assert.Equal(t, []ecstypes.NetworkBinding{ipv6PortBinding}, input.NetworkBindings,
+				"Expect the IPv6 port binding of the bridge mode container to be reported")
+			return &ecsservice.SubmitContainerStateChangeOutput{}, nil
+		})
+	client, err := ecsclient.NewECSClient(aws.NewCredentialsCache(aws.AnonymousCredentials{}), cfgAccessor,
+		mock_ec2.NewMockEC2MetadataClient(ctrl), version.String(),
+		ecsclient.WithIPv6PortBindingExcluded(agent.shouldExcludeIPv6PortBinding()),
+		ecsclient.WithStandardClient(mock_ecs.NewMockECSStandardSDK(ctrl)),
+		ecsclient.WithSubmitStateChangeClient(mockSubmitStateClient))
+	require.NoError(t, err)
+
+	assert.NoError(t, client.SubmitContainerStateChange(ecs.ContainerStateChange{
+		TaskArn:         "task-arn",
+		ContainerName:   "container",
+		Status:          apicontainerstatus.ContainerRunning,
+		NetworkBindings: []ecstypes.NetworkBinding{ipv6PortBinding},
+	}))
+}
@@ -28,11 +28,13 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/eni/watcher"
 	"github.com/aws/amazon-ecs-agent/agent/gpu"
+	"github.com/aws/amazon-ecs-agent/agent/hostresources"
 	s3factory "github.com/aws/amazon-ecs-agent/agent/s3/factory"
 	ssmfactory "github.com/aws/amazon-ecs-agent/agent/ssm/factory"
 	"github.com/aws/amazon-ecs-agent/agent/statechange"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	cgroup "github.com/aws/amazon-ecs-agent/agent/taskresource/cgroup/control"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/cgroup/cpuset"
 	"github.com/aws/amazon-ecs-agent/agent/utils/ioutilwrapper"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
 
@@ -167,8 +169,9 @@ func (agent *ecsAgent) startEBSWatcher(
 // initializeResourceFields exists mainly for testing doStart() to use mock Control
 // object
 func (agent *ecsAgent) initializeResourceFields(credentialsManager credentials.Manager) {
+	control := cgroup.New()
 	agent.resourceFields = &taskresource.ResourceFields{
-		Control: cgroup.New(),
+		Control: control,
 		ResourceFieldsCommon: &taskresource.ResourceFieldsCommon{
 			IOUtil:             ioutilwrapper.NewIOUtil(),
 			ASMClientCreator:   asmfactory.NewClientCreator(),
@@ -180,9 +183,28 @@ func (agent *ecsAgent) initializeResourceFields(credentialsManager credentials.M
 		Ctx:              agent.ctx,
 		DockerClient:     agent.dockerClient,
 		NvidiaGPUManager: gpu.NewNvidiaGPUManager(),
+		CPUSetLedger:     agent.newCPUSetLedger(control),
 	}
 }
 
+// newCPUSetLedger returns the ledger assigning exclusive CPUs to tasks when CPU pinning is enabled, and nil
+// otherwise
+func (agent *ecsAgent) newCPUSetLedger(control cgroup.Control) *cpuset.Ledger {
+	if !agent.cfg.CPUPinningEnabled.Enabled() {
+		return nil
+	}
+	if !agent.cfg.TaskCPUMemLimit.Enabled() {
+		seelog.Warn("Disabling CPU pinning, which requires task-level CPU and memory limits")
+		return nil
+	}
+	ledger, err := cpuset.NewLedger(control)
+	if err != nil {
+		seelog.Warnf("Disabling CPU pinning: %v", err)
+		return nil
+	}
+	return ledger
+}
+
 func (agent *ecsAgent) cgroupInit() error {
 	err := agent.resourceFields.Control.Init()
 	// When task CPU and memory limits are enabled, all tasks are placed
@@ -220,3 +242,13 @@ func (agent *ecsAgent) loadPauseContainer() error {
 
 	return err
 }
+
+// diskSpaceHealthcheckPaths returns the paths whose filesystems are checked by the disk space health check:
+// the data directory and the docker data root, if it's known.
+func diskSpaceHealthcheckPaths(cfg *config.Config, dockerRootDir string) []string {
+	paths := []string{cfg.DataDir}
+	if dockerRootDir != "" {
+		paths = append(paths, hostresources.HostPath(dockerRootDir))
+	}
+	return paths
+}
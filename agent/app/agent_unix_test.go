@@ -49,6 +49,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	dockertypes "github.com/docker/docker/api/types"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -123,6 +124,7 @@ func TestDoStartTaskENIHappyPath(t *testing.T) {
 	imageManager.EXPECT().AddImageToCleanUpExclusionList(gomock.Eq("service_connect_agent:v1")).Times(1)
 	mockUdevMonitor.EXPECT().Monitor(gomock.Any()).Return(monitoShutdownEvents).AnyTimes()
 	client.EXPECT().GetHostResources().Return(testHostResource, nil).Times(1)
+	dockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(dockertypes.Info{}, nil).Times(1)
 
 	gomock.InOrder(
 		mockMetadata.EXPECT().PrimaryENIMAC().Return(mac, nil),
@@ -473,6 +475,7 @@ func TestDoStartCgroupInitHappyPath(t *testing.T) {
 
 	imageManager.EXPECT().AddImageToCleanUpExclusionList(gomock.Eq("service_connect_agent:v1")).Times(1)
 	client.EXPECT().GetHostResources().Return(testHostResource, nil).Times(1)
+	dockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(dockertypes.Info{}, nil).Times(1)
 
 	gomock.InOrder(
 		mockControl.EXPECT().Init().Return(nil),
@@ -507,6 +510,7 @@ func TestDoStartCgroupInitHappyPath(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.TODO())
 	// Cancel the context to cancel async routines
 	agent := &ecsAgent{
+		dataClient:       data.NewNoopClient(),
 		ctx:              ctx,
 		cfg:              &cfg,
 		credentialsCache: aws.NewCredentialsCache(mockCredentialsProvider),
@@ -576,6 +580,7 @@ func TestDoStartCgroupInitErrorPath(t *testing.T) {
 	// Cancel the context to cancel async routines
 	defer cancel()
 	agent := &ecsAgent{
+		dataClient:       data.NewNoopClient(),
 		ctx:              ctx,
 		cfg:              &cfg,
 		credentialsCache: aws.NewCredentialsCache(mockCredentialsProvider),
@@ -648,6 +653,7 @@ func TestDoStartGPUManagerHappyPath(t *testing.T) {
 
 	imageManager.EXPECT().AddImageToCleanUpExclusionList(gomock.Eq("service_connect_agent:v1")).Times(1)
 	client.EXPECT().GetHostResources().Return(testHostResource, nil).Times(1)
+	dockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(dockertypes.Info{}, nil).Times(1)
 	mockGPUManager.EXPECT().GetDevices().Return(devices).AnyTimes()
 
 	gomock.InOrder(
@@ -686,6 +692,7 @@ func TestDoStartGPUManagerHappyPath(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.TODO())
 	// Cancel the context to cancel async routines
 	agent := &ecsAgent{
+		dataClient:       data.NewNoopClient(),
 		ctx:              ctx,
 		cfg:              &cfg,
 		credentialsCache: aws.NewCredentialsCache(mockCredentialsProvider),
@@ -748,6 +755,7 @@ func TestDoStartGPUManagerInitError(t *testing.T) {
 	// Cancel the context to cancel async routines
 	defer cancel()
 	agent := &ecsAgent{
+		dataClient:       data.NewNoopClient(),
 		ctx:              ctx,
 		cfg:              &cfg,
 		credentialsCache: aws.NewCredentialsCache(mockCredentialsProvider),
@@ -790,12 +798,14 @@ func TestDoStartTaskENIPauseError(t *testing.T) {
 	imageManager.EXPECT().StartImageCleanupProcess(gomock.Any()).MaxTimes(1)
 	mockPauseLoader.EXPECT().LoadImage(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("error")).AnyTimes()
 	client.EXPECT().GetHostResources().Return(testHostResource, nil).Times(1)
+	dockerClient.EXPECT().Info(gomock.Any(), gomock.Any()).Return(dockertypes.Info{}, nil).Times(1)
 
 	cfg := getTestConfig()
 	cfg.TaskENIEnabled = config.BooleanDefaultFalse{Value: config.ExplicitlyEnabled}
 	cfg.ENITrunkingEnabled = config.BooleanDefaultTrue{Value: config.ExplicitlyEnabled}
 	ctx, _ := context.WithCancel(context.TODO())
 	agent := &ecsAgent{
+		dataClient:        data.NewNoopClient(),
 		ctx:               ctx,
 		cfg:               &cfg,
 		credentialsCache:  aws.NewCredentialsCache(mockCredentialsProvider),
@@ -19,6 +19,7 @@ package app
 import (
 	"errors"
 
+	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/engine"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
@@ -54,3 +55,8 @@ func (agent *ecsAgent) getPlatformDevices() []types.PlatformDevice {
 func (agent *ecsAgent) loadPauseContainer() error {
 	return nil
 }
+
+// diskSpaceHealthcheckPaths returns no path, as the disk space is only checked on Linux
+func diskSpaceHealthcheckPaths(*config.Config, string) []string {
+	return nil
+}
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	asmfactory "github.com/aws/amazon-ecs-agent/agent/asm/factory"
+	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/data"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	ebs "github.com/aws/amazon-ecs-agent/agent/ebs"
@@ -381,3 +382,8 @@ func (agent *ecsAgent) verifyCNIPluginsCapabilities() error {
 	}
 	return nil
 }
+
+// diskSpaceHealthcheckPaths returns no path, as the disk space is only checked on Linux
+func diskSpaceHealthcheckPaths(*config.Config, string) []string {
+	return nil
+}
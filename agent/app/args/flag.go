@@ -28,6 +28,8 @@ const (
 	blacholeEC2MetadataUsage = "Blackhole the EC2 Metadata requests. Setting this option can cause the ECS Agent to fail to work properly.  We do not recommend setting this option"
 	windowsServiceUsage      = "Run the ECS agent as a Windows Service"
 	healthcheckServiceUsage  = "Run the agent healthcheck"
+	doctorUsage              = "Run the health checks of the agent dependencies once, print the results as JSON and exit"
+	stateDBUsage             = "Inspect or repair the agent state database while the agent is stopped, print the result as JSON and exit: [<dump>|<tasks>|<containers>|<images>|<eni-attachments>|<resource-attachments>|<host-ports>|<metadata>|<remove-orphaned-containers>|<remove-stale-images>|<compact>|<backup>]"
 
 	versionFlagName              = "version"
 	logLevelFlagName             = "loglevel"
@@ -39,6 +41,8 @@ const (
 	blackholeEC2MetadataFlagName = "blackhole-ec2-metadata"
 	windowsServiceFlagName       = "windows-service"
 	healthCheckFlagName          = "healthcheck"
+	stateDBFlagName              = "state-db"
+	doctorFlagName               = "doctor"
 )
 
 // Args wraps various ECS Agent arguments
@@ -65,6 +69,10 @@ type Args struct {
 	WindowsService *bool
 	// Healthcheck indicates that agent should run healthcheck
 	Healthcheck *bool
+	// StateDB is the state database inspection or repair command to run
+	StateDB *string
+	// Doctor indicates that the agent should run the health checks of its dependencies
+	Doctor *bool
 }
 
 // New creates a new Args object from the argument list
@@ -82,6 +90,8 @@ func New(arguments []string) (*Args, error) {
 		ECSAttributes:        flagset.Bool(ecsAttributesFlagName, false, ecsAttributesUsage),
 		WindowsService:       flagset.Bool(windowsServiceFlagName, false, windowsServiceUsage),
 		Healthcheck:          flagset.Bool(healthCheckFlagName, false, healthcheckServiceUsage),
+		StateDB:              flagset.String(stateDBFlagName, "", stateDBUsage),
+		Doctor:               flagset.Bool(doctorFlagName, false, doctorUsage),
 	}
 
 	err := flagset.Parse(arguments)
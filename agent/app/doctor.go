@@ -0,0 +1,174 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/agent/awsrulesfn"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/sdkclientfactory"
+	dockerdoctor "github.com/aws/amazon-ecs-agent/agent/doctor"
+	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ec2"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds"
+
+	"github.com/cihub/seelog"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// doctorResult is the JSON representation of the result of a health check run by the doctor mode (-doctor).
+type doctorResult struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// doctorTool runs the health checks of the agent dependencies once, e.g. to troubleshoot an instance.
+type doctorTool struct {
+	healthchecks []doctor.Healthcheck
+	out          io.Writer
+}
+
+// newDependencyHealthchecks creates the health checks of the agent dependencies which don't depend on a
+// session with the backend: the disk space, the task credentials endpoint and the clock skew.
+func newDependencyHealthchecks(cfg *config.Config, dockerRootDir string) []doctor.Healthcheck {
+	healthchecks := []doctor.Healthcheck{
+		dockerdoctor.NewCredentialsEndpointHealthcheck(
+			fmt.Sprintf("http://%s%s", tmds.AddressIPv4(), credentials.V2CredentialsPath),
+			dockerdoctor.DefaultCredentialsEndpointRequestTimeout),
+	}
+	if paths := diskSpaceHealthcheckPaths(cfg, dockerRootDir); len(paths) > 0 {
+		healthchecks = append(healthchecks,
+			dockerdoctor.NewDiskSpaceHealthcheck(paths, dockerdoctor.DefaultMinAvailableDiskSpaceMiB))
+	}
+	if referenceURL := ecsEndpointURL(cfg); referenceURL != "" {
+		healthchecks = append(healthchecks, dockerdoctor.NewClockSkewHealthcheck(referenceURL,
+			dockerdoctor.DefaultMaxClockSkew, dockerdoctor.DefaultClockSkewRequestTimeout))
+	}
+	return healthchecks
+}
+
+// ecsEndpointURL returns the URL of the ECS endpoint of the region of the instance, or an empty string
+// if the region isn't known.
+func ecsEndpointURL(cfg *config.Config) string {
+	endpoint := cfg.APIEndpoint
+	if endpoint == "" {
+		if cfg.AWSRegion == "" {
+			return ""
+		}
+		dnsSuffix := awsrulesfn.DnsSuffix
+		if partition := awsrulesfn.GetPartitionForRegion(cfg.AWSRegion); partition != nil {
+			dnsSuffix = partition.DefaultConfig.DnsSuffix
+		}
+		endpoint = fmt.Sprintf("ecs.%s.%s", cfg.AWSRegion, dnsSuffix)
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	return endpoint
+}
+
+// runDoctor runs the health checks of the agent dependencies once and prints the results as JSON to
+// stdout. The sessions with ACS and TCS are only checked by a running agent.
+func runDoctor() int {
+	ec2MetadataClient, err := ec2.NewEC2MetadataClient(nil)
+	if err != nil {
+		ec2MetadataClient = ec2.NewBlackholeEC2MetadataClient()
+	}
+	// Errors about settings which aren't used by the health checks are not fatal here.
+	cfg, err := config.NewConfig(ec2MetadataClient)
+	if cfg == nil {
+		seelog.Errorf("Unable to load configuration: %v", err)
+		return exitcodes.ExitTerminal
+	}
+	if err != nil {
+		seelog.Warnf("Configuration loaded with errors: %v", err)
+	}
+
+	healthy := true
+	var healthchecks []doctor.Healthcheck
+	ctx := context.Background()
+	dockerClient, err := dockerapi.NewDockerGoClient(sdkclientfactory.NewFactory(ctx, cfg.DockerEndpoint), cfg, ctx)
+	if err != nil {
+		seelog.Errorf("Unable to connect to docker: %v", err)
+		healthy = false
+	} else {
+		healthchecks = append(healthchecks, dockerdoctor.NewDockerRuntimeHealthcheck(dockerClient))
+		var dockerRootDir string
+		if info, err := dockerClient.Info(ctx, dockerclient.InfoTimeout); err != nil {
+			seelog.Warnf("Unable to find the docker data root: %v", err)
+		} else {
+			dockerRootDir = info.DockerRootDir
+		}
+		healthchecks = append(healthchecks, newDependencyHealthchecks(cfg, dockerRootDir)...)
+	}
+
+	if cfg.Checkpoint.Enabled() {
+		dataClient, err := data.NewOffline(cfg.DataDir, true)
+		switch {
+		case err == nil:
+			defer dataClient.Close()
+			healthchecks = append(healthchecks, dockerdoctor.NewDataStoreHealthcheck(dataClient))
+		case errors.Is(err, bolt.ErrTimeout):
+			seelog.Infof("The state database is in use by the running agent, skipping its health check")
+		case errors.Is(err, os.ErrNotExist):
+			seelog.Infof("The state database doesn't exist yet, skipping its health check")
+		default:
+			seelog.Errorf("Unable to open the state database: %v", err)
+			healthy = false
+		}
+	}
+
+	tool := &doctorTool{
+		healthchecks: healthchecks,
+		out:          os.Stdout,
+	}
+	ok, err := tool.run()
+	if err != nil {
+		seelog.Errorf("Unable to print the health check results: %v", err)
+		return exitcodes.ExitError
+	}
+	if !ok || !healthy {
+		return exitcodes.ExitError
+	}
+	return exitcodes.ExitSuccess
+}
+
+// run runs every health check once, prints the results and returns whether they all passed.
+func (tool *doctorTool) run() (bool, error) {
+	ok := true
+	results := []doctorResult{}
+	for _, healthcheck := range tool.healthchecks {
+		status := healthcheck.RunCheck()
+		ok = ok && status.Ok()
+		results = append(results, doctorResult{
+			Type:   healthcheck.GetHealthcheckType(),
+			Status: status.String(),
+		})
+	}
+	encoder := json.NewEncoder(tool.out)
+	encoder.SetIndent("", "  ")
+	return ok, encoder.Encode(results)
+}
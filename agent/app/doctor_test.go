@@ -0,0 +1,173 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	dockerdoctor "github.com/aws/amazon-ecs-agent/agent/doctor"
+	"github.com/aws/amazon-ecs-agent/agent/doctor/remediation"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctorToolRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	dockerClient := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	for _, tc := range []struct {
+		name            string
+		pingErr         error
+		expectedOk      bool
+		expectedResults []doctorResult
+	}{
+		{
+			name:       "all healthy",
+			expectedOk: true,
+			expectedResults: []doctorResult{
+				{Type: doctor.HealthcheckTypeContainerRuntime, Status: "OK"},
+				{Type: doctor.HealthcheckTypeDataStore, Status: "OK"},
+			},
+		},
+		{
+			name:       "docker unreachable",
+			pingErr:    errors.New("connection refused"),
+			expectedOk: false,
+			expectedResults: []doctorResult{
+				{Type: doctor.HealthcheckTypeContainerRuntime, Status: "IMPAIRED"},
+				{Type: doctor.HealthcheckTypeDataStore, Status: "OK"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dockerClient.EXPECT().SystemPing(gomock.Any(), gomock.Any()).Return(dockerapi.PingResponse{Error: tc.pingErr})
+			out := &bytes.Buffer{}
+			tool := &doctorTool{
+				healthchecks: []doctor.Healthcheck{
+					dockerdoctor.NewDockerRuntimeHealthcheck(dockerClient),
+					dockerdoctor.NewDataStoreHealthcheck(data.NewNoopClient()),
+				},
+				out: out,
+			}
+
+			ok, err := tool.run()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedOk, ok)
+			var results []doctorResult
+			require.NoError(t, json.Unmarshal(out.Bytes(), &results))
+			assert.Equal(t, tc.expectedResults, results)
+		})
+	}
+}
+
+func TestECSEndpointURL(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		cfg      *config.Config
+		expected string
+	}{
+		{
+			name:     "region",
+			cfg:      &config.Config{AWSRegion: "us-west-2"},
+			expected: "https://ecs.us-west-2.amazonaws.com",
+		},
+		{
+			name:     "region in another partition",
+			cfg:      &config.Config{AWSRegion: "cn-north-1"},
+			expected: "https://ecs.cn-north-1.amazonaws.com.cn",
+		},
+		{
+			name:     "endpoint override",
+			cfg:      &config.Config{AWSRegion: "us-west-2", APIEndpoint: "ecs.example.com"},
+			expected: "https://ecs.example.com",
+		},
+		{
+			name:     "endpoint override with scheme",
+			cfg:      &config.Config{APIEndpoint: "http://localhost:8080"},
+			expected: "http://localhost:8080",
+		},
+		{
+			name: "unknown region",
+			cfg:  &config.Config{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ecsEndpointURL(tc.cfg))
+		})
+	}
+}
+
+func TestStartRemediationRestartDockerWithoutCheckpoint(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		dryRun       bool
+		expectRecord bool
+	}{
+		{name: "refused", dryRun: false, expectRecord: false},
+		{name: "dry run", dryRun: true, expectRecord: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			dockerClient := mock_dockerapi.NewMockDockerClient(ctrl)
+			dockerClient.EXPECT().SystemPing(gomock.Any(), gomock.Any()).
+				Return(dockerapi.PingResponse{Error: errors.New("ping failed")})
+
+			auditFile := filepath.Join(t.TempDir(), "remediation-audit.log")
+			cfg := getTestConfig()
+			cfg.Checkpoint = config.BooleanDefaultFalse{Value: config.ExplicitlyDisabled}
+			cfg.RemediationPolicy = &config.RemediationPolicy{
+				Actions: map[string][]string{
+					doctor.HealthcheckTypeContainerRuntime: {remediation.RestartDockerActionName},
+				},
+				FailureThreshold:  1,
+				MaxActionsPerHour: 1,
+				DryRun:            tc.dryRun,
+				AuditFile:         auditFile,
+			}
+			agent := &ecsAgent{
+				ctx:        context.TODO(),
+				cfg:        &cfg,
+				dataClient: data.NewNoopClient(),
+			}
+			doc, err := doctor.NewDoctor([]doctor.Healthcheck{dockerdoctor.NewDockerRuntimeHealthcheck(dockerClient)},
+				"cluster", "containerInstanceArn")
+			require.NoError(t, err)
+
+			agent.startRemediation(doc, nil, nil)
+			doc.RunHealthchecks()
+
+			// The guard rails can't be saved without checkpointing, so the policy is only used in dry runs
+			_, err = os.Stat(auditFile)
+			assert.Equal(t, tc.expectRecord, err == nil)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/agent/handlers"
+	"github.com/aws/amazon-ecs-agent/agent/localtasks"
+	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
+	"github.com/aws/amazon-ecs-agent/agent/stats"
+	"github.com/aws/amazon-ecs-agent/agent/version"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/eventstream"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+const (
+	// localTaskEventsFileName is the file in the data directory that the state changes of the local tasks
+	// are appended to, when ECS_LOCAL_TASK_EVENTS_FILE isn't set
+	localTaskEventsFileName = "local-task-events.log"
+	localTaskEventsFileMode = 0644
+)
+
+// startLocalTasks runs the tasks of the task definition files of the local tasks directory instead of the
+// tasks of ECS. The container instance isn't registered, and the state changes of the tasks are written to
+// the local task event log. It blocks until the agent's context is cancelled.
+func (agent *ecsAgent) startLocalTasks(
+	containerChangeEventStream *eventstream.EventStream,
+	credentialsManager credentials.Manager,
+	state dockerstate.TaskEngineState,
+	imageManager engine.ImageManager,
+	taskEngine engine.TaskEngine,
+	client ecs.ECSClient) int {
+	logger.Info("Running local tasks, the container instance won't be registered with ECS", logger.Fields{
+		"dir": agent.cfg.LocalTasksDir,
+	})
+	eventsFile := agent.cfg.LocalTaskEventsFile
+	if eventsFile == "" {
+		eventsFile = filepath.Join(agent.cfg.DataDir, localTaskEventsFileName)
+	}
+	eventLog, err := os.OpenFile(eventsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, localTaskEventsFileMode)
+	if err != nil {
+		logger.Critical("Unable to open the local task event log", logger.Fields{
+			"file":      eventsFile,
+			field.Error: err,
+		})
+		return exitcodes.ExitTerminal
+	}
+	defer eventLog.Close()
+
+	if agent.cfg.Checkpoint.Enabled() {
+		agent.saveMetadata(data.AgentVersionKey, version.Version)
+		agent.saveMetadata(data.ClusterNameKey, agent.cfg.Cluster)
+	}
+
+	// Begin listening to the docker daemon and saving changes
+	taskEngine.SetDataClient(agent.dataClient)
+	imageManager.SetDataClient(agent.dataClient)
+	taskEngine.MustInit(agent.ctx)
+
+	if !agent.cfg.ImageCleanupDisabled.Enabled() {
+		go imageManager.StartImageCleanupProcess(agent.ctx)
+	}
+	// There's no container instance to drain without the control plane
+	go handlers.ServeIntrospectionHTTPEndpoint(agent.ctx, &agent.containerInstanceARN, taskEngine, agent.cfg, nil)
+
+	// The metrics aren't published, the stats engine only serves the task metadata endpoint
+	statsEngine := stats.NewDockerStatsEngine(agent.cfg, agent.dockerClient, containerChangeEventStream,
+		nil, nil, agent.dataClient)
+	if err := statsEngine.MustInit(agent.ctx, taskEngine, agent.cfg.Cluster, agent.containerInstanceARN); err != nil {
+		logger.Warn("Error initializing metrics engine", logger.Fields{
+			field.Error: err,
+		})
+	}
+	go handlers.ServeTaskHTTPEndpoint(agent.ctx, credentialsManager, state, client, agent.containerInstanceARN,
+		agent.cfg, statsEngine, agent.availabilityZone, agent.vpc, agent.dataClient)
+
+	go localtasks.HandleEngineEvents(agent.ctx, taskEngine, agent.dataClient, eventLog)
+
+	runner := localtasks.NewRunner(agent.cfg.LocalTasksDir, agent.cfg.AWSRegion, agent.cfg.Cluster, taskEngine)
+	if err := runner.Start(agent.ctx); err != nil {
+		logger.Critical("Unable to run the local tasks", logger.Fields{
+			field.Error: err,
+		})
+		return exitcodes.ExitTerminal
+	}
+	return exitcodes.ExitSuccess
+}
@@ -58,6 +58,10 @@ func Run(arguments []string) int {
 		}
 		healthcheckUrl := fmt.Sprintf("http://%s:51678/v1/metadata", localhost)
 		return runHealthcheck(healthcheckUrl, time.Second*25)
+	} else if *parsedArgs.StateDB != "" {
+		return runStateDBTool(*parsedArgs.StateDB)
+	} else if *parsedArgs.Doctor {
+		return runDoctor()
 	}
 
 	if *parsedArgs.LogLevel != "" {
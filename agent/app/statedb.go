@@ -0,0 +1,322 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/sdkclientfactory"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/agent/sighandlers/exitcodes"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment/resource"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ec2"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+
+	"github.com/cihub/seelog"
+	"github.com/pkg/errors"
+)
+
+// Commands accepted by the state database tool (-state-db).
+const (
+	stateDBDumpCommand                     = "dump"
+	stateDBTasksCommand                    = "tasks"
+	stateDBContainersCommand               = "containers"
+	stateDBImagesCommand                   = "images"
+	stateDBENIAttachmentsCommand           = "eni-attachments"
+	stateDBResourceAttachmentsCommand      = "resource-attachments"
+	stateDBHostPortsCommand                = "host-ports"
+	stateDBMetadataCommand                 = "metadata"
+	stateDBRemoveOrphanedContainersCommand = "remove-orphaned-containers"
+	stateDBRemoveStaleImagesCommand        = "remove-stale-images"
+	stateDBCompactCommand                  = "compact"
+	stateDBBackupCommand                   = "backup"
+
+	stateDBListImagesTimeout = 2 * time.Minute
+)
+
+// stateDBDump is the JSON representation of the whole agent state database.
+type stateDBDump struct {
+	Tasks               []*apitask.Task                   `json:"tasks"`
+	Containers          []*apicontainer.DockerContainer   `json:"containers"`
+	ImageStates         []*image.ImageState               `json:"imageStates"`
+	ENIAttachments      []*networkinterface.ENIAttachment `json:"eniAttachments"`
+	ResourceAttachments []*resource.ResourceAttachment    `json:"resourceAttachments"`
+	HostPorts           []*utils.HostPortReservation      `json:"hostPorts"`
+	Metadata            map[string]string                 `json:"metadata"`
+}
+
+// stateDBRepairReport is the JSON representation of the result of a repair command.
+type stateDBRepairReport struct {
+	// Removed lists the database keys of the entries that were removed.
+	Removed []string `json:"removed"`
+	// SizeBefore and SizeAfter are the size of the database file in bytes before and after compaction.
+	SizeBefore int64 `json:"sizeBefore,omitempty"`
+	SizeAfter  int64 `json:"sizeAfter,omitempty"`
+}
+
+// stateDBBackupReport is the JSON representation of the result of the backup command.
+type stateDBBackupReport struct {
+	// Backup is the path of the snapshot that was written.
+	Backup string `json:"backup"`
+}
+
+// stateDBTool inspects and repairs the agent state database while the agent is stopped.
+type stateDBTool struct {
+	dataDir     string
+	backupDir   string
+	backupCount int
+	out         io.Writer
+	// newDataClient opens the database, read-only unless a repair command is run.
+	newDataClient func(dataDir string, readOnly bool) (data.Client, error)
+	// newDockerClient creates the docker client used to find images that no longer exist.
+	newDockerClient func() (dockerapi.DockerClient, error)
+}
+
+// runStateDBTool runs a single state database command and prints the result as JSON to stdout.
+func runStateDBTool(command string) int {
+	// The config is only used to locate the data directory and the docker endpoint, so errors
+	// about unrelated settings are not fatal here.
+	cfg, err := config.NewConfig(ec2.NewBlackholeEC2MetadataClient())
+	if cfg == nil {
+		seelog.Errorf("Unable to load configuration: %v", err)
+		return exitcodes.ExitTerminal
+	}
+	if err != nil {
+		seelog.Warnf("Configuration loaded with errors: %v", err)
+	}
+	tool := &stateDBTool{
+		dataDir:       cfg.DataDir,
+		backupDir:     cfg.StateBackupDir,
+		backupCount:   cfg.StateBackupCount,
+		out:           os.Stdout,
+		newDataClient: data.NewOffline,
+		newDockerClient: func() (dockerapi.DockerClient, error) {
+			ctx := context.Background()
+			return dockerapi.NewDockerGoClient(sdkclientfactory.NewFactory(ctx, cfg.DockerEndpoint), cfg, ctx)
+		},
+	}
+	if err := tool.run(command); err != nil {
+		seelog.Errorf("State database command %q failed: %v", command, err)
+		return exitcodes.ExitTerminal
+	}
+	return exitcodes.ExitSuccess
+}
+
+func (tool *stateDBTool) run(command string) error {
+	switch command {
+	case stateDBCompactCommand:
+		before, after, err := data.Compact(tool.dataDir)
+		if err != nil {
+			return err
+		}
+		return tool.print(&stateDBRepairReport{SizeBefore: before, SizeAfter: after})
+	case stateDBRemoveOrphanedContainersCommand, stateDBRemoveStaleImagesCommand:
+		return tool.repair(command)
+	default:
+		return tool.inspect(command)
+	}
+}
+
+// inspect opens the database read-only and prints the requested part of it. It also takes
+// point-in-time backups, which only need a read transaction.
+func (tool *stateDBTool) inspect(command string) error {
+	dataClient, err := tool.newDataClient(tool.dataDir, true)
+	if err != nil {
+		return errors.Wrap(err, "unable to open state database read-only; make sure the agent is stopped")
+	}
+	defer dataClient.Close()
+
+	var out interface{}
+	switch command {
+	case stateDBDumpCommand:
+		out, err = dumpStateDB(dataClient)
+	case stateDBTasksCommand:
+		out, err = dataClient.GetTasks()
+	case stateDBContainersCommand:
+		out, err = dataClient.GetContainers()
+	case stateDBImagesCommand:
+		out, err = dataClient.GetImageStates()
+	case stateDBENIAttachmentsCommand:
+		out, err = dataClient.GetENIAttachments()
+	case stateDBResourceAttachmentsCommand:
+		out, err = dataClient.GetResourceAttachments()
+	case stateDBHostPortsCommand:
+		out, err = dataClient.GetHostPortReservations()
+	case stateDBMetadataCommand:
+		out, err = dataClient.GetAllMetadata()
+	case stateDBBackupCommand:
+		var backupPath string
+		backupPath, err = dataClient.Backup(tool.backupDir, tool.backupCount)
+		out = &stateDBBackupReport{Backup: backupPath}
+	default:
+		return errors.Errorf("unknown command %q", command)
+	}
+	if err != nil {
+		return err
+	}
+	return tool.print(out)
+}
+
+// repair opens the database for writing and removes the entries selected by the command.
+func (tool *stateDBTool) repair(command string) error {
+	dataClient, err := tool.newDataClient(tool.dataDir, false)
+	if err != nil {
+		return errors.Wrap(err, "unable to open state database; make sure the agent is stopped")
+	}
+	defer dataClient.Close()
+
+	var removed []string
+	switch command {
+	case stateDBRemoveOrphanedContainersCommand:
+		removed, err = removeOrphanedContainers(dataClient)
+	case stateDBRemoveStaleImagesCommand:
+		var dockerClient dockerapi.DockerClient
+		dockerClient, err = tool.newDockerClient()
+		if err != nil {
+			return errors.Wrap(err, "unable to create docker client")
+		}
+		removed, err = removeStaleImageStates(dataClient, dockerClient)
+	}
+	if err != nil {
+		return err
+	}
+	return tool.print(&stateDBRepairReport{Removed: removed})
+}
+
+func (tool *stateDBTool) print(out interface{}) error {
+	encoder := json.NewEncoder(tool.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+func dumpStateDB(dataClient data.Client) (*stateDBDump, error) {
+	var (
+		dump = &stateDBDump{}
+		err  error
+	)
+	if dump.Tasks, err = dataClient.GetTasks(); err != nil {
+		return nil, errors.Wrap(err, "unable to get tasks")
+	}
+	if dump.Containers, err = dataClient.GetContainers(); err != nil {
+		return nil, errors.Wrap(err, "unable to get containers")
+	}
+	if dump.ImageStates, err = dataClient.GetImageStates(); err != nil {
+		return nil, errors.Wrap(err, "unable to get image states")
+	}
+	if dump.ENIAttachments, err = dataClient.GetENIAttachments(); err != nil {
+		return nil, errors.Wrap(err, "unable to get eni attachments")
+	}
+	if dump.ResourceAttachments, err = dataClient.GetResourceAttachments(); err != nil {
+		return nil, errors.Wrap(err, "unable to get resource attachments")
+	}
+	if dump.HostPorts, err = dataClient.GetHostPortReservations(); err != nil {
+		return nil, errors.Wrap(err, "unable to get host port reservations")
+	}
+	if dump.Metadata, err = dataClient.GetAllMetadata(); err != nil {
+		return nil, errors.Wrap(err, "unable to get metadata")
+	}
+	return dump, nil
+}
+
+// removeOrphanedContainers deletes containers whose task is not in the database anymore.
+func removeOrphanedContainers(dataClient data.Client) ([]string, error) {
+	tasks, err := dataClient.GetTasks()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get tasks")
+	}
+	containers, err := dataClient.GetContainers()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get containers")
+	}
+
+	taskIDs := make(map[string]struct{})
+	for _, task := range tasks {
+		taskID, err := utils.GetTaskID(task.Arn)
+		if err != nil {
+			seelog.Warnf("Skipping task with invalid ARN %q: %v", task.Arn, err)
+			continue
+		}
+		taskIDs[taskID] = struct{}{}
+	}
+
+	removed := []string{}
+	for _, container := range containers {
+		if container.Container == nil {
+			continue
+		}
+		taskID, err := utils.GetTaskID(container.Container.GetTaskARN())
+		if err != nil {
+			seelog.Warnf("Skipping container %s with invalid task ARN: %v", container.Container.Name, err)
+			continue
+		}
+		if _, ok := taskIDs[taskID]; ok {
+			continue
+		}
+		id, err := data.GetContainerID(container.Container)
+		if err != nil {
+			return removed, err
+		}
+		if err = dataClient.DeleteContainer(id); err != nil {
+			return removed, errors.Wrapf(err, "unable to delete container %s", id)
+		}
+		removed = append(removed, id)
+	}
+	return removed, nil
+}
+
+// removeStaleImageStates deletes image states whose image no longer exists in docker.
+func removeStaleImageStates(dataClient data.Client, dockerClient dockerapi.DockerClient) ([]string, error) {
+	imageStates, err := dataClient.GetImageStates()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get image states")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), stateDBListImagesTimeout)
+	defer cancel()
+	response := dockerClient.ListImages(ctx, stateDBListImagesTimeout)
+	if response.Error != nil {
+		// Without the list of images we can't tell which image states are stale.
+		return nil, errors.Wrap(response.Error, "unable to list images")
+	}
+	imageIDs := make(map[string]struct{})
+	for _, imageID := range response.ImageIDs {
+		imageIDs[imageID] = struct{}{}
+	}
+
+	removed := []string{}
+	for _, imageState := range imageStates {
+		id := imageState.GetImageID()
+		if id == "" {
+			continue
+		}
+		if _, ok := imageIDs[id]; ok {
+			continue
+		}
+		if err = dataClient.DeleteImageState(id); err != nil {
+			return removed, errors.Wrapf(err, "unable to delete image state %s", id)
+		}
+		removed = append(removed, id)
+	}
+	return removed, nil
+}
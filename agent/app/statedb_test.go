@@ -0,0 +1,179 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testOrphanTaskARN = "arn:aws:ecs:region:account-id:task/orphan-task-id"
+	testStaleImageID  = "sha256:stale"
+)
+
+func newTestStateDBTool(t *testing.T, dockerClient dockerapi.DockerClient) (*stateDBTool, *bytes.Buffer) {
+	dataDir := t.TempDir()
+	dataClient, err := data.NewWithSetup(dataDir)
+	require.NoError(t, err)
+	require.NoError(t, dataClient.SaveTask(&apitask.Task{Arn: testTaskARN}))
+	require.NoError(t, dataClient.SaveContainer(testContainer))
+	require.NoError(t, dataClient.SaveContainer(&apicontainer.Container{
+		Name:          testContainerName,
+		TaskARNUnsafe: testOrphanTaskARN,
+	}))
+	require.NoError(t, dataClient.SaveImageState(&image.ImageState{Image: &image.Image{ImageID: testImageId}}))
+	require.NoError(t, dataClient.SaveImageState(&image.ImageState{Image: &image.Image{ImageID: testStaleImageID}}))
+	require.NoError(t, dataClient.SaveHostPortReservation(&utils.HostPortReservation{
+		TaskARN: testTaskARN,
+		Ranges:  []utils.HostPortRange{{Protocol: "tcp", StartPort: 40000, EndPort: 40000}},
+	}))
+	require.NoError(t, dataClient.SaveMetadata(data.ClusterNameKey, testCluster))
+	require.NoError(t, dataClient.Close())
+
+	out := &bytes.Buffer{}
+	return &stateDBTool{
+		dataDir:       dataDir,
+		out:           out,
+		newDataClient: data.NewOffline,
+		newDockerClient: func() (dockerapi.DockerClient, error) {
+			return dockerClient, nil
+		},
+	}, out
+}
+
+func TestStateDBToolDump(t *testing.T) {
+	tool, out := newTestStateDBTool(t, nil)
+	require.NoError(t, tool.run(stateDBDumpCommand))
+
+	var dump struct {
+		Tasks       []json.RawMessage `json:"tasks"`
+		Containers  []json.RawMessage `json:"containers"`
+		ImageStates []json.RawMessage `json:"imageStates"`
+		HostPorts   []json.RawMessage `json:"hostPorts"`
+		Metadata    map[string]string `json:"metadata"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &dump))
+	assert.Len(t, dump.Tasks, 1)
+	assert.Len(t, dump.Containers, 2)
+	assert.Len(t, dump.ImageStates, 2)
+	assert.Len(t, dump.HostPorts, 1)
+	assert.Equal(t, map[string]string{data.ClusterNameKey: testCluster}, dump.Metadata)
+}
+
+func TestStateDBToolHostPorts(t *testing.T) {
+	tool, out := newTestStateDBTool(t, nil)
+	require.NoError(t, tool.run(stateDBHostPortsCommand))
+
+	var reservations []*utils.HostPortReservation
+	require.NoError(t, json.Unmarshal(out.Bytes(), &reservations))
+	require.Len(t, reservations, 1)
+	assert.Equal(t, testTaskARN, reservations[0].TaskARN)
+}
+
+func TestStateDBToolUnknownCommand(t *testing.T) {
+	tool, _ := newTestStateDBTool(t, nil)
+	assert.Error(t, tool.run("unknown"))
+}
+
+func TestStateDBToolRemoveOrphanedContainers(t *testing.T) {
+	tool, out := newTestStateDBTool(t, nil)
+	require.NoError(t, tool.run(stateDBRemoveOrphanedContainersCommand))
+
+	report := &stateDBRepairReport{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), report))
+	assert.Equal(t, []string{"orphan-task-id-" + testContainerName}, report.Removed)
+
+	dataClient, err := data.NewOffline(tool.dataDir, true)
+	require.NoError(t, err)
+	defer dataClient.Close()
+	containers, err := dataClient.GetContainers()
+	require.NoError(t, err)
+	require.Len(t, containers, 1)
+	assert.Equal(t, testTaskARN, containers[0].Container.GetTaskARN())
+}
+
+func TestStateDBToolRemoveStaleImages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	dockerClient := mock_dockerapi.NewMockDockerClient(ctrl)
+	dockerClient.EXPECT().ListImages(gomock.Any(), stateDBListImagesTimeout).Return(dockerapi.ListImagesResponse{
+		ImageIDs: []string{testImageId},
+	})
+
+	tool, out := newTestStateDBTool(t, dockerClient)
+	require.NoError(t, tool.run(stateDBRemoveStaleImagesCommand))
+
+	report := &stateDBRepairReport{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), report))
+	assert.Equal(t, []string{testStaleImageID}, report.Removed)
+
+	dataClient, err := data.NewOffline(tool.dataDir, true)
+	require.NoError(t, err)
+	defer dataClient.Close()
+	imageStates, err := dataClient.GetImageStates()
+	require.NoError(t, err)
+	require.Len(t, imageStates, 1)
+	assert.Equal(t, testImageId, imageStates[0].GetImageID())
+}
+
+func TestStateDBToolRemoveStaleImagesListError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	dockerClient := mock_dockerapi.NewMockDockerClient(ctrl)
+	dockerClient.EXPECT().ListImages(gomock.Any(), stateDBListImagesTimeout).Return(dockerapi.ListImagesResponse{
+		Error: errors.New("error"),
+	})
+
+	tool, _ := newTestStateDBTool(t, dockerClient)
+	assert.Error(t, tool.run(stateDBRemoveStaleImagesCommand))
+}
+
+func TestStateDBToolCompact(t *testing.T) {
+	tool, out := newTestStateDBTool(t, nil)
+	require.NoError(t, tool.run(stateDBCompactCommand))
+
+	report := &stateDBRepairReport{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), report))
+	assert.True(t, report.SizeBefore > 0)
+	assert.True(t, report.SizeAfter > 0)
+}
+
+func TestStateDBToolBackup(t *testing.T) {
+	tool, out := newTestStateDBTool(t, nil)
+	tool.backupDir = t.TempDir()
+	tool.backupCount = 1
+	require.NoError(t, tool.run(stateDBBackupCommand))
+
+	report := &stateDBBackupReport{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), report))
+	assert.FileExists(t, report.Backup)
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"time"
@@ -89,6 +90,10 @@ const (
 	//DefaultImagePullTimeout specifies the timeout for PullImage API.
 	DefaultImagePullTimeout = 2 * time.Hour
 
+	// DefaultTaskAdmissionAgingInterval specifies the default time after which a task waiting for host
+	// resources is admitted like tasks of the next higher priority class.
+	DefaultTaskAdmissionAgingInterval = 5 * time.Minute
+
 	// minimumTaskCleanupWaitDuration specifies the minimum duration to wait before cleaning up
 	// a task's container. This is used to enforce sane values for the config.TaskCleanupWaitDuration field.
 	minimumTaskCleanupWaitDuration = time.Second
@@ -108,6 +113,37 @@ const (
 	// minimumDockerStopTimeout specifies the minimum value for docker StopContainer API
 	minimumDockerStopTimeout = 1 * time.Second
 
+	// DefaultStateBackupInterval is the default time between two state database snapshots.
+	DefaultStateBackupInterval = time.Hour
+
+	// DefaultStateBackupCount is the default number of state database snapshots kept in the backup directory.
+	DefaultStateBackupCount = 5
+
+	// minimumStateBackupInterval specifies the minimum time between two state database snapshots.
+	minimumStateBackupInterval = time.Minute
+
+	// DefaultRemediationFailureThreshold is the default number of consecutive failed runs of a healthcheck
+	// before the remediation actions of the healthcheck are taken.
+	DefaultRemediationFailureThreshold = 3
+
+	// DefaultRemediationCooldown is the default minimum time between two runs of the same remediation action.
+	DefaultRemediationCooldown = 30 * time.Minute
+
+	// DefaultRemediationMaxActionsPerHour is the default maximum number of remediation actions taken in an hour.
+	DefaultRemediationMaxActionsPerHour = 2
+
+	// DefaultDrainHealthcheckFailureThreshold is the default number of consecutive failed runs of a healthcheck
+	// before the container instance is drained.
+	DefaultDrainHealthcheckFailureThreshold = 5
+
+	// DefaultDrainTaskStopTimeout is the default maximum time to wait for the tasks to stop once the container
+	// instance is draining.
+	DefaultDrainTaskStopTimeout = 10 * time.Minute
+
+	// defaultStateBackupDirName is the directory inside DataDir that state database snapshots are written
+	// to when ECS_STATE_BACKUP_DIR is not set.
+	defaultStateBackupDirName = "backups"
+
 	// minimumImageCleanupInterval specifies the minimum time for agent to wait before performing
 	// image cleanup.
 	minimumImageCleanupInterval = 10 * time.Minute
@@ -363,6 +399,8 @@ func (cfg *Config) validateAndOverrideBounds() error {
 		cfg.NumImagesToDeletePerCycle = DefaultNumImagesToDeletePerCycle
 	}
 
+	cfg.stateBackupOverrides()
+
 	if cfg.TaskMetadataSteadyStateRate <= 0 || cfg.TaskMetadataBurstRate <= 0 {
 		seelog.Warnf("Invalid values for rate limits, will be overridden with default values: %d,%d.", DefaultTaskMetadataSteadyStateRate, DefaultTaskMetadataBurstRate)
 		cfg.TaskMetadataSteadyStateRate = DefaultTaskMetadataSteadyStateRate
@@ -377,6 +415,22 @@ func (cfg *Config) validateAndOverrideBounds() error {
 	return nil
 }
 
+func (cfg *Config) stateBackupOverrides() {
+	if cfg.StateBackupDir == "" {
+		cfg.StateBackupDir = filepath.Join(cfg.DataDir, defaultStateBackupDirName)
+	}
+	if cfg.StateBackupCount < 1 {
+		seelog.Warnf("Invalid value for ECS_STATE_BACKUP_COUNT, will be overridden with the default value: %d. Parsed value: %d.",
+			DefaultStateBackupCount, cfg.StateBackupCount)
+		cfg.StateBackupCount = DefaultStateBackupCount
+	}
+	if cfg.StateBackupInterval > 0 && cfg.StateBackupInterval < minimumStateBackupInterval {
+		seelog.Warnf("ECS_STATE_BACKUP_INTERVAL parsed value (%s) is less than the minimum of %s. Setting backup interval to minimum.",
+			cfg.StateBackupInterval, minimumStateBackupInterval)
+		cfg.StateBackupInterval = minimumStateBackupInterval
+	}
+}
+
 func (cfg *Config) pollMetricsOverrides() {
 	if cfg.PollMetrics.Enabled() {
 		if cfg.PollingMetricsWaitDuration < minimumPollingMetricsWaitDuration {
@@ -527,6 +581,8 @@ func environmentConfig() (Config, error) {
 
 	additionalLocalRoutes, errs := parseAdditionalLocalRoutes(errs)
 
+	taskMemoryPolicy, errs := parseTaskMemoryPolicy(errs)
+
 	var err error
 	if len(errs) > 0 {
 		err = apierrors.NewMultiError(errs...)
@@ -540,22 +596,31 @@ func environmentConfig() (Config, error) {
 		ReservedPortsUDP:                    parseReservedPorts("ECS_RESERVED_PORTS_UDP"),
 		DataDir:                             dataDir,
 		Checkpoint:                          parseCheckpoint(dataDir),
+		StateBackupInterval:                 parseEnvVariableDuration("ECS_STATE_BACKUP_INTERVAL"),
+		StateBackupDir:                      os.Getenv("ECS_STATE_BACKUP_DIR"),
+		StateBackupCount:                    parseStateBackupCount(),
+		LocalTasksDir:                       os.Getenv("ECS_LOCAL_TASKS_DIR"),
+		LocalTaskEventsFile:                 os.Getenv("ECS_LOCAL_TASK_EVENTS_FILE"),
 		EngineAuthType:                      os.Getenv("ECS_ENGINE_AUTH_TYPE"),
 		EngineAuthData:                      NewSensitiveRawMessage([]byte(os.Getenv("ECS_ENGINE_AUTH_DATA"))),
 		UpdatesEnabled:                      parseBooleanDefaultFalseConfig("ECS_UPDATES_ENABLED"),
 		UpdateDownloadDir:                   os.Getenv("ECS_UPDATE_DOWNLOAD_DIR"),
 		DisableMetrics:                      parseBooleanDefaultFalseConfig("ECS_DISABLE_METRICS"),
 		ReservedMemory:                      parseEnvVariableUint16("ECS_RESERVED_MEMORY"),
+		TaskENISlots:                        parseEnvVariableUint16("ECS_TASK_ENI_SLOTS"),
 		AvailableLoggingDrivers:             parseAvailableLoggingDrivers(),
 		PrivilegedDisabled:                  parseBooleanDefaultFalseConfig("ECS_DISABLE_PRIVILEGED"),
 		SELinuxCapable:                      parseBooleanDefaultFalseConfig("ECS_SELINUX_CAPABLE"),
 		AppArmorCapable:                     parseBooleanDefaultFalseConfig("ECS_APPARMOR_CAPABLE"),
 		TaskCleanupWaitDuration:             parseEnvVariableDuration("ECS_ENGINE_TASK_CLEANUP_WAIT_DURATION"),
 		TaskCleanupWaitDurationJitter:       parseEnvVariableDuration("ECS_ENGINE_TASK_CLEANUP_WAIT_DURATION_JITTER"),
+		TaskAdmissionMaxWait:                parseEnvVariableDuration("ECS_TASK_ADMISSION_MAX_WAIT"),
+		TaskAdmissionAgingInterval:          parseEnvVariableDuration("ECS_TASK_ADMISSION_AGING_INTERVAL"),
 		TaskENIEnabled:                      parseBooleanDefaultFalseConfig("ECS_ENABLE_TASK_ENI"),
 		TaskIAMRoleEnabled:                  parseBooleanDefaultFalseConfig("ECS_ENABLE_TASK_IAM_ROLE"),
 		DeleteNonECSImagesEnabled:           parseBooleanDefaultFalseConfig("ECS_ENABLE_UNTRACKED_IMAGE_CLEANUP"),
 		TaskCPUMemLimit:                     parseBooleanDefaultTrueConfig("ECS_ENABLE_TASK_CPU_MEM_LIMIT"),
+		CPUPinningEnabled:                   parseBooleanDefaultFalseConfig("ECS_ENABLE_CPU_PINNING"),
 		DockerStopTimeout:                   parseDockerStopTimeout(),
 		ManifestPullTimeout:                 parseManifestPullTimeout(),
 		ContainerStartTimeout:               parseContainerStartTimeout(),
@@ -607,6 +672,9 @@ func environmentConfig() (Config, error) {
 		WarmPoolsSupport:                    parseBooleanDefaultFalseConfig("ECS_WARM_POOLS_CHECK"),
 		DynamicHostPortRange:                parseDynamicHostPortRange("ECS_DYNAMIC_HOST_PORT_RANGE"),
 		TaskPidsLimit:                       parseTaskPidsLimit(),
+		TaskMemoryPolicy:                    taskMemoryPolicy,
+		RemediationPolicy:                   parseRemediationPolicy(),
+		DrainPolicy:                         parseDrainPolicy(),
 		FirelensAsyncEnabled:                parseBooleanDefaultTrueConfig("ECS_ENABLE_FIRELENS_ASYNC"),
 	}, err
 }
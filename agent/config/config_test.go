@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -610,6 +611,33 @@ func TestTaskCleanupTimeout(t *testing.T) {
 	assert.Equal(t, 10*time.Minute, cfg.TaskCleanupWaitDuration, "Task cleanup wait duration set incorrectly")
 }
 
+func TestTaskAdmissionQueue(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Zero(t, cfg.TaskAdmissionMaxWait, "Tasks should wait for host resources indefinitely by default")
+	assert.Equal(t, DefaultTaskAdmissionAgingInterval, cfg.TaskAdmissionAgingInterval)
+
+	defer setTestEnv("ECS_TASK_ADMISSION_MAX_WAIT", "1h")()
+	defer setTestEnv("ECS_TASK_ADMISSION_AGING_INTERVAL", "2m")()
+	cfg, err = NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, cfg.TaskAdmissionMaxWait)
+	assert.Equal(t, 2*time.Minute, cfg.TaskAdmissionAgingInterval)
+}
+
+func TestTaskENISlots(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Zero(t, cfg.TaskENISlots, "ENI slots should not be accounted for by default")
+
+	defer setTestEnv("ECS_TASK_ENI_SLOTS", "15")()
+	cfg, err = NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(15), cfg.TaskENISlots)
+}
+
 func TestInvalidReservedMemoryOverridesToZero(t *testing.T) {
 	defer setTestRegion()()
 	defer setTestEnv("ECS_RESERVED_MEMORY", "-1")()
@@ -685,6 +713,34 @@ func TestImageCleanupMinimumNumImagesToDeletePerCycle(t *testing.T) {
 	assert.Equal(t, DefaultNumImagesToDeletePerCycle, cfg.NumImagesToDeletePerCycle, "Wrong value for NumImagesToDeletePerCycle")
 }
 
+func TestStateBackupDefaults(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_DATADIR", "/data")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultStateBackupInterval, cfg.StateBackupInterval, "Wrong value for StateBackupInterval")
+	assert.Equal(t, filepath.Join("/data", defaultStateBackupDirName), cfg.StateBackupDir, "Wrong value for StateBackupDir")
+	assert.Equal(t, DefaultStateBackupCount, cfg.StateBackupCount, "Wrong value for StateBackupCount")
+}
+
+func TestStateBackupDisabled(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_STATE_BACKUP_INTERVAL", "-1s")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Negative(t, cfg.StateBackupInterval, "Wrong value for StateBackupInterval")
+}
+
+func TestStateBackupMinimumInterval(t *testing.T) {
+	defer setTestRegion()()
+	defer setTestEnv("ECS_STATE_BACKUP_INTERVAL", "1s")()
+	defer setTestEnv("ECS_STATE_BACKUP_COUNT", "0")()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.Equal(t, minimumStateBackupInterval, cfg.StateBackupInterval, "Wrong value for StateBackupInterval")
+	assert.Equal(t, DefaultStateBackupCount, cfg.StateBackupCount, "Wrong value for StateBackupCount")
+}
+
 func TestInvalidImagePullBehavior(t *testing.T) {
 	defer setTestRegion()()
 	defer setTestEnv("ECS_IMAGE_PULL_BEHAVIOR", "invalid")()
@@ -760,6 +816,18 @@ func TestTaskResourceLimitsOverride(t *testing.T) {
 	assert.Equal(t, ExplicitlyDisabled, cfg.TaskCPUMemLimit.Value, "Task cpu and memory limits should be explicitly set")
 }
 
+func TestCPUPinning(t *testing.T) {
+	defer setTestRegion()()
+	cfg, err := NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.False(t, cfg.CPUPinningEnabled.Enabled(), "CPU pinning should be disabled by default")
+
+	defer setTestEnv("ECS_ENABLE_CPU_PINNING", "true")()
+	cfg, err = NewConfig(ec2testutil.FakeEC2MetadataClient{})
+	assert.NoError(t, err)
+	assert.True(t, cfg.CPUPinningEnabled.Enabled(), "CPU pinning should be enabled")
+}
+
 func TestAWSVPCBlockInstanceMetadata(t *testing.T) {
 	defer setTestEnv("ECS_AWSVPC_BLOCK_IMDS", "true")()
 	defer setTestRegion()()
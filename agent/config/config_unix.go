@@ -105,6 +105,9 @@ func DefaultConfig() Config {
 		ImagePullInactivityTimeout:          defaultImagePullInactivityTimeout,
 		ImagePullTimeout:                    DefaultImagePullTimeout,
 		NumImagesToDeletePerCycle:           DefaultNumImagesToDeletePerCycle,
+		StateBackupInterval:                 DefaultStateBackupInterval,
+		StateBackupCount:                    DefaultStateBackupCount,
+		TaskAdmissionAgingInterval:          DefaultTaskAdmissionAgingInterval,
 		NumNonECSContainersToDeletePerCycle: DefaultNumNonECSContainersToDeletePerCycle,
 		CNIPluginsPath:                      defaultCNIPluginsPath,
 		PauseContainerTarballPath:           pauseContainerTarballPath,
@@ -148,6 +148,9 @@ func DefaultConfig() Config {
 		NonECSMinimumImageDeletionAge:       DefaultNonECSImageDeletionAge,
 		ImageCleanupInterval:                DefaultImageCleanupTimeInterval,
 		NumImagesToDeletePerCycle:           DefaultNumImagesToDeletePerCycle,
+		StateBackupInterval:                 DefaultStateBackupInterval,
+		StateBackupCount:                    DefaultStateBackupCount,
+		TaskAdmissionAgingInterval:          DefaultTaskAdmissionAgingInterval,
 		NumNonECSContainersToDeletePerCycle: DefaultNumNonECSContainersToDeletePerCycle,
 		ContainerMetadataEnabled:            BooleanDefaultFalse{Value: ExplicitlyDisabled},
 		TaskCPUMemLimit:                     BooleanDefaultTrue{Value: ExplicitlyDisabled},
@@ -186,6 +186,16 @@ func parseNumImagesToDeletePerCycle() int {
 	return numImagesToDeletePerCycle
 }
 
+func parseStateBackupCount() int {
+	stateBackupCountEnvVal := os.Getenv("ECS_STATE_BACKUP_COUNT")
+	stateBackupCount, err := strconv.Atoi(stateBackupCountEnvVal)
+	if stateBackupCountEnvVal != "" && err != nil {
+		seelog.Warnf("Invalid format for \"ECS_STATE_BACKUP_COUNT\", expected an integer. err %v", err)
+	}
+
+	return stateBackupCount
+}
+
 func parseNumNonECSContainersToDeletePerCycle() int {
 	numNonEcsContainersToDeletePerCycleEnvVal := os.Getenv("NONECS_NUM_CONTAINERS_DELETE_PER_CYCLE")
 	numNonEcsContainersToDeletePerCycle, err := strconv.Atoi(numNonEcsContainersToDeletePerCycleEnvVal)
@@ -423,3 +433,88 @@ func getDefaultDynamicHostPortRange() string {
 	}
 	return fmt.Sprintf("%d-%d", startHostPortRange, endHostPortRange)
 }
+
+func parseRemediationPolicy() *RemediationPolicy {
+	policyFile := os.Getenv("ECS_REMEDIATION_POLICY_FILE")
+	if policyFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(policyFile)
+	if err != nil {
+		seelog.Warnf(`Unable to read the remediation policy file of "ECS_REMEDIATION_POLICY_FILE" [%s]: %v`, policyFile, err)
+		return nil
+	}
+	policy := &RemediationPolicy{
+		FailureThreshold:  DefaultRemediationFailureThreshold,
+		Cooldown:          DefaultRemediationCooldown,
+		MaxActionsPerHour: DefaultRemediationMaxActionsPerHour,
+	}
+	// The cooldown is a duration string such as "30m" in the file
+	raw := struct {
+		*RemediationPolicy
+		Cooldown string `json:"cooldown"`
+	}{RemediationPolicy: policy}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		seelog.Warnf(`Invalid format for the remediation policy file of "ECS_REMEDIATION_POLICY_FILE" [%s], expected a json object: %v`, policyFile, err)
+		return nil
+	}
+	if raw.Cooldown != "" {
+		if policy.Cooldown, err = time.ParseDuration(raw.Cooldown); err != nil || policy.Cooldown < 0 {
+			seelog.Warnf(`Invalid cooldown in the remediation policy file [%s], expected a non-negative duration, but got [%s]`, policyFile, raw.Cooldown)
+			return nil
+		}
+	}
+	if len(policy.Actions) == 0 {
+		seelog.Warnf(`Invalid remediation policy file [%s], no action is configured`, policyFile)
+		return nil
+	}
+	if policy.FailureThreshold < 1 {
+		seelog.Warnf(`Invalid failureThreshold in the remediation policy file [%s], expected a positive integer, but got [%d]`, policyFile, policy.FailureThreshold)
+		return nil
+	}
+	if policy.MaxActionsPerHour < 1 {
+		seelog.Warnf(`Invalid maxActionsPerHour in the remediation policy file [%s], expected a positive integer, but got [%d]`, policyFile, policy.MaxActionsPerHour)
+		return nil
+	}
+	return policy
+}
+
+func parseDrainPolicy() *DrainPolicy {
+	policyFile := os.Getenv("ECS_DRAIN_POLICY_FILE")
+	if policyFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(policyFile)
+	if err != nil {
+		seelog.Warnf(`Unable to read the drain policy file of "ECS_DRAIN_POLICY_FILE" [%s]: %v`, policyFile, err)
+		return nil
+	}
+	policy := &DrainPolicy{
+		HealthcheckFailureThreshold: DefaultDrainHealthcheckFailureThreshold,
+		TaskStopTimeout:             DefaultDrainTaskStopTimeout,
+	}
+	// The task stop timeout is a duration string such as "10m" in the file
+	raw := struct {
+		*DrainPolicy
+		TaskStopTimeout string `json:"taskStopTimeout"`
+	}{DrainPolicy: policy}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		seelog.Warnf(`Invalid format for the drain policy file of "ECS_DRAIN_POLICY_FILE" [%s], expected a json object: %v`, policyFile, err)
+		return nil
+	}
+	if raw.TaskStopTimeout != "" {
+		if policy.TaskStopTimeout, err = time.ParseDuration(raw.TaskStopTimeout); err != nil || policy.TaskStopTimeout < 0 {
+			seelog.Warnf(`Invalid taskStopTimeout in the drain policy file [%s], expected a non-negative duration, but got [%s]`, policyFile, raw.TaskStopTimeout)
+			return nil
+		}
+	}
+	if len(policy.Triggers) == 0 {
+		seelog.Warnf(`Invalid drain policy file [%s], no trigger is configured`, policyFile)
+		return nil
+	}
+	if policy.HealthcheckFailureThreshold < 1 {
+		seelog.Warnf(`Invalid healthcheckFailureThreshold in the drain policy file [%s], expected a positive integer, but got [%d]`, policyFile, policy.HealthcheckFailureThreshold)
+		return nil
+	}
+	return policy
+}
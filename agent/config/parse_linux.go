@@ -17,7 +17,9 @@
 package config
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"strconv"
@@ -135,3 +137,39 @@ func parseTaskPidsLimit() int {
 
 	return taskPidsLimit
 }
+
+// parseTaskMemoryPolicy loads the task memory policy file. A file which exists but can't be read or holds
+// an invalid policy is a configuration error, so that tasks don't run without the controls it sets.
+func parseTaskMemoryPolicy(errs []error) (*TaskMemoryPolicy, []error) {
+	policyFile := os.Getenv("ECS_TASK_MEMORY_POLICY_FILE")
+	if policyFile == "" {
+		return nil, errs
+	}
+	data, err := os.ReadFile(policyFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		seelog.Warnf(`The task memory policy file of "ECS_TASK_MEMORY_POLICY_FILE" [%s] does not exist`, policyFile)
+		return nil, errs
+	}
+	if err != nil {
+		err = fmt.Errorf(`unable to read the task memory policy file of "ECS_TASK_MEMORY_POLICY_FILE" [%s]: %v`, policyFile, err)
+		seelog.Error(err)
+		return nil, append(errs, err)
+	}
+	var policy TaskMemoryPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		err = fmt.Errorf(`invalid format for the task memory policy file of "ECS_TASK_MEMORY_POLICY_FILE" [%s], expected a json object: %v`, policyFile, err)
+		seelog.Error(err)
+		return nil, append(errs, err)
+	}
+	if policy.MemoryHighPercent != nil && (*policy.MemoryHighPercent <= 0 || *policy.MemoryHighPercent > 100) {
+		err := fmt.Errorf(`invalid memoryHighPercent in the task memory policy file [%s], expected integer greater than 0 and at most 100, but got [%d]`, policyFile, *policy.MemoryHighPercent)
+		seelog.Error(err)
+		return nil, append(errs, err)
+	}
+	if policy.SwapMaxMiB != nil && *policy.SwapMaxMiB < 0 {
+		err := fmt.Errorf(`invalid swapMaxMiB in the task memory policy file [%s], expected a non-negative integer, but got [%d]`, policyFile, *policy.SwapMaxMiB)
+		seelog.Error(err)
+		return nil, append(errs, err)
+	}
+	return &policy, errs
+}
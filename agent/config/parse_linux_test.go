@@ -17,9 +17,12 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseGMSACapabilitySupported(t *testing.T) {
@@ -109,3 +112,51 @@ func TestParseTaskPidsLimit(t *testing.T) {
 func TestParseTaskPidsLimit_Unset(t *testing.T) {
 	assert.Equal(t, 0, parseTaskPidsLimit())
 }
+
+func TestParseTaskMemoryPolicy(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "memory-policy.json")
+	t.Setenv("ECS_TASK_MEMORY_POLICY_FILE", policyFile)
+
+	writePolicy := func(policy string) {
+		require.NoError(t, os.WriteFile(policyFile, []byte(policy), 0644))
+	}
+
+	// a missing file is ignored
+	policy, errs := parseTaskMemoryPolicy(nil)
+	assert.Nil(t, policy)
+	assert.Empty(t, errs)
+
+	writePolicy(`{"memoryHighPercent": 90, "swapMaxMiB": 0, "oomGroup": true}`)
+	policy, errs = parseTaskMemoryPolicy(nil)
+	assert.Empty(t, errs)
+	require.NotNil(t, policy)
+	assert.Equal(t, int64(90), *policy.MemoryHighPercent)
+	assert.Equal(t, int64(0), *policy.SwapMaxMiB)
+	assert.True(t, *policy.OOMGroup)
+
+	writePolicy(`{"oomGroup": false}`)
+	policy, errs = parseTaskMemoryPolicy(nil)
+	assert.Empty(t, errs)
+	require.NotNil(t, policy)
+	assert.Nil(t, policy.MemoryHighPercent)
+	assert.Nil(t, policy.SwapMaxMiB)
+	assert.False(t, *policy.OOMGroup)
+
+	for _, invalid := range []string{
+		`{"memoryHighPercent": 101}`,
+		`{"memoryHighPercent": 0}`,
+		`{"swapMaxMiB": -1}`,
+		`memoryHighPercent=90`,
+	} {
+		writePolicy(invalid)
+		policy, errs = parseTaskMemoryPolicy(nil)
+		assert.Nil(t, policy, invalid)
+		assert.Len(t, errs, 1, invalid)
+	}
+}
+
+func TestParseTaskMemoryPolicy_Unset(t *testing.T) {
+	policy, errs := parseTaskMemoryPolicy(nil)
+	assert.Nil(t, policy)
+	assert.Empty(t, errs)
+}
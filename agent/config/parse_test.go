@@ -2,9 +2,13 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseReservedPorts(t *testing.T) {
@@ -137,3 +141,96 @@ func TestParseContainerInstanceTags(t *testing.T) {
 	assert.Equal(t, expectedInvalid, actual)
 	assert.Equal(t, expectedErrs, actualErrs)
 }
+
+func TestParseRemediationPolicy(t *testing.T) {
+	assert.Nil(t, parseRemediationPolicy())
+
+	policyFile := filepath.Join(t.TempDir(), "remediation-policy.json")
+	t.Setenv("ECS_REMEDIATION_POLICY_FILE", policyFile)
+	writePolicy := func(policy string) {
+		require.NoError(t, os.WriteFile(policyFile, []byte(policy), 0644))
+	}
+
+	// a missing file is ignored
+	assert.Nil(t, parseRemediationPolicy())
+
+	writePolicy(`{"actions": {"ContainerRuntime": ["restart-docker"]}}`)
+	policy := parseRemediationPolicy()
+	require.NotNil(t, policy)
+	assert.Equal(t, &RemediationPolicy{
+		Actions:           map[string][]string{"ContainerRuntime": {"restart-docker"}},
+		FailureThreshold:  DefaultRemediationFailureThreshold,
+		Cooldown:          DefaultRemediationCooldown,
+		MaxActionsPerHour: DefaultRemediationMaxActionsPerHour,
+	}, policy)
+
+	writePolicy(`{"actions": {"DiskSpace": ["clear-image-cache", "drain-instance"]}, "failureThreshold": 1,
+		"cooldown": "1h", "maxActionsPerHour": 5, "dryRun": true, "auditFile": "/log/remediation.log"}`)
+	policy = parseRemediationPolicy()
+	require.NotNil(t, policy)
+	assert.Equal(t, &RemediationPolicy{
+		Actions:           map[string][]string{"DiskSpace": {"clear-image-cache", "drain-instance"}},
+		FailureThreshold:  1,
+		Cooldown:          time.Hour,
+		MaxActionsPerHour: 5,
+		DryRun:            true,
+		AuditFile:         "/log/remediation.log",
+	}, policy)
+
+	for _, invalid := range []string{
+		`{}`,
+		`{"actions": {"ContainerRuntime": ["restart-docker"]}, "cooldown": "soon"}`,
+		`{"actions": {"ContainerRuntime": ["restart-docker"]}, "cooldown": "-1m"}`,
+		`{"actions": {"ContainerRuntime": ["restart-docker"]}, "failureThreshold": 0}`,
+		`{"actions": {"ContainerRuntime": ["restart-docker"]}, "maxActionsPerHour": 0}`,
+		`actions=restart-docker`,
+	} {
+		writePolicy(invalid)
+		assert.Nil(t, parseRemediationPolicy(), invalid)
+	}
+}
+
+func TestParseDrainPolicy(t *testing.T) {
+	assert.Nil(t, parseDrainPolicy())
+
+	policyFile := filepath.Join(t.TempDir(), "drain-policy.json")
+	t.Setenv("ECS_DRAIN_POLICY_FILE", policyFile)
+	writePolicy := func(policy string) {
+		require.NoError(t, os.WriteFile(policyFile, []byte(policy), 0644))
+	}
+
+	// a missing file is ignored
+	assert.Nil(t, parseDrainPolicy())
+
+	writePolicy(`{"triggers": ["maintenance-event"]}`)
+	policy := parseDrainPolicy()
+	require.NotNil(t, policy)
+	assert.Equal(t, &DrainPolicy{
+		Triggers:                    []string{"maintenance-event"},
+		HealthcheckFailureThreshold: DefaultDrainHealthcheckFailureThreshold,
+		TaskStopTimeout:             DefaultDrainTaskStopTimeout,
+	}, policy)
+
+	writePolicy(`{"triggers": ["healthcheck", "drain-file", "shutdown"], "healthcheckFailureThreshold": 2,
+		"drainFile": "/etc/ecs/drain", "taskStopTimeout": "90s"}`)
+	policy = parseDrainPolicy()
+	require.NotNil(t, policy)
+	assert.Equal(t, &DrainPolicy{
+		Triggers:                    []string{"healthcheck", "drain-file", "shutdown"},
+		HealthcheckFailureThreshold: 2,
+		DrainFile:                   "/etc/ecs/drain",
+		TaskStopTimeout:             90 * time.Second,
+	}, policy)
+
+	for _, invalid := range []string{
+		`{}`,
+		`{"triggers": []}`,
+		`{"triggers": ["shutdown"], "taskStopTimeout": "later"}`,
+		`{"triggers": ["shutdown"], "taskStopTimeout": "-1m"}`,
+		`{"triggers": ["healthcheck"], "healthcheckFailureThreshold": 0}`,
+		`triggers=shutdown`,
+	} {
+		writePolicy(invalid)
+		assert.Nil(t, parseDrainPolicy(), invalid)
+	}
+}
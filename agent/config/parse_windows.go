@@ -192,3 +192,10 @@ func parseTaskPidsLimit() int {
 	seelog.Warnf(`"ECS_TASK_PIDS_LIMIT" is not supported on windows`)
 	return 0
 }
+
+func parseTaskMemoryPolicy(errs []error) (*TaskMemoryPolicy, []error) {
+	if os.Getenv("ECS_TASK_MEMORY_POLICY_FILE") != "" {
+		seelog.Warnf(`"ECS_TASK_MEMORY_POLICY_FILE" is not supported on windows`)
+	}
+	return nil, errs
+}
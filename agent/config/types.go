@@ -72,6 +72,24 @@ type Config struct {
 	// file, in DataDir, such that on instance or agent restarts it will resume
 	// as the same ContainerInstance. It defaults to false.
 	Checkpoint BooleanDefaultFalse
+	// StateBackupInterval is how often a consistent snapshot of the agent state
+	// database is written to StateBackupDir while the agent is running. It defaults
+	// to an hour, and snapshots are disabled when it is negative.
+	StateBackupInterval time.Duration
+	// StateBackupDir is the directory state database snapshots are written to and
+	// restored from when the state database can't be opened at startup. It
+	// defaults to the "backups" directory inside DataDir.
+	StateBackupDir string
+	// StateBackupCount is the number of snapshots kept in StateBackupDir. Older
+	// snapshots are removed once a new one has been written. It defaults to 5.
+	StateBackupCount int
+
+	// LocalTasksDir is the directory of the task definition files of the local task runner mode. When set, the
+	// agent doesn't register with ECS and runs the tasks described by the files instead.
+	LocalTasksDir string
+	// LocalTaskEventsFile is the file the state changes of the local tasks are appended to. It defaults to
+	// the "local-task-events.log" file inside DataDir.
+	LocalTaskEventsFile string
 
 	// EngineAuthType configures what type of data is in EngineAuthData.
 	// Supported types, right now, can be found in the dockerauth package: https://godoc.org/github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerauth
@@ -109,6 +127,12 @@ type Config struct {
 	// This doesn't reserve memory usage on the instance
 	ReservedMemory uint16
 
+	// TaskENISlots is the number of awsvpc tasks the instance can host: the number of branch ENIs with ENI
+	// trunking, or the number of ENIs the instance type supports minus the primary ENI without. awsvpc tasks
+	// wait for a free slot before they start. When it is zero, which is the default, the slots are looked up
+	// from the instance type without ENI trunking and aren't accounted for with it.
+	TaskENISlots uint16
+
 	// ManifestPullTimeout is the amount of time to wait for a manifest pull
 	ManifestPullTimeout time.Duration
 
@@ -122,6 +146,15 @@ type Config struct {
 	// ContainerCreateTimeout specifies the amount of time to wait to create a container
 	ContainerCreateTimeout time.Duration
 
+	// TaskAdmissionMaxWait is the maximum time a task waits in the admission queue for host resources
+	// before it is stopped. Tasks wait until resources are available when it is zero, which is the default.
+	TaskAdmissionMaxWait time.Duration
+
+	// TaskAdmissionAgingInterval is how long a task waits in the admission queue before it is admitted
+	// like tasks of the next higher priority class, so that tasks of lower priority classes don't starve.
+	// It defaults to 5 minutes.
+	TaskAdmissionAgingInterval time.Duration
+
 	// DependentContainersPullUpfront specifies whether pulling images upfront should be applied to this agent.
 	// Default false
 	DependentContainersPullUpfront BooleanDefaultFalse
@@ -168,6 +201,11 @@ type Config struct {
 	// TaskCPUMemLimit specifies if Agent can launch a task with a hierarchical cgroup
 	TaskCPUMemLimit BooleanDefaultTrue
 
+	// CPUPinningEnabled specifies if tasks with a CPU limit of whole vCPUs get CPUs exclusively, with the memory
+	// nodes of these CPUs on NUMA hosts, through the cpuset of the task cgroup. The other tasks are kept off
+	// these CPUs. It requires TaskCPUMemLimit and is only supported on Linux.
+	CPUPinningEnabled BooleanDefaultFalse
+
 	// CredentialsAuditLogFile specifies the path/filename of the audit log.
 	CredentialsAuditLogFile string
 
@@ -369,6 +407,7 @@ type Config struct {
 	// ShouldExcludeIPv6PortBinding specifies whether agent should exclude IPv6 port bindings reported from docker. This configuration
 	// is set to true by default, and can be overridden by the ECS_EXCLUDE_IPV6_PORTBINDING environment variable. This is a workaround
 	// for docker's bug as detailed in https://github.com/aws/amazon-ecs-agent/issues/2870.
+	// IPv6 port bindings are never excluded on IPv6-only instances.
 	ShouldExcludeIPv6PortBinding BooleanDefaultTrue
 
 	// WarmPoolsSupport specifies whether the agent should poll IMDS to check the target lifecycle state for a starting
@@ -386,6 +425,18 @@ type Config struct {
 	// see https://www.kernel.org/doc/html/latest/admin-guide/cgroup-v2.html#pid
 	TaskPidsLimit int
 
+	// TaskMemoryPolicy holds the cgroup v2 memory controls of the tasks whose containers don't set them with
+	// docker labels. It's loaded from the JSON file at ECS_TASK_MEMORY_POLICY_FILE.
+	TaskMemoryPolicy *TaskMemoryPolicy
+
+	// RemediationPolicy configures the actions the agent takes when the doctor healthchecks fail. It's loaded
+	// from the JSON file at ECS_REMEDIATION_POLICY_FILE, no action is taken when it's not set.
+	RemediationPolicy *RemediationPolicy
+
+	// DrainPolicy configures the conditions on which the agent drains the container instance. It's loaded from
+	// the JSON file at ECS_DRAIN_POLICY_FILE, the instance is only drained on spot interruptions when it's not set.
+	DrainPolicy *DrainPolicy
+
 	// CSIDriverSocketPath specifies the path that the CSI driver socket file is located at.
 	// Defaults to "/var/run/ecs/ebs-csi-driver/csi-driver.sock"
 	CSIDriverSocketPath string
@@ -403,3 +454,41 @@ type Config struct {
 	// IP version compatibility for the container instance's default network
 	InstanceIPCompatibility ipcompatibility.IPCompatibility
 }
+
+// TaskMemoryPolicy holds the default cgroup v2 memory controls of task cgroups.
+type TaskMemoryPolicy struct {
+	// MemoryHighPercent sets memory.high of tasks with a memory limit to this percentage of the limit
+	MemoryHighPercent *int64 `json:"memoryHighPercent,omitempty"`
+	// SwapMaxMiB sets memory.swap.max of tasks
+	SwapMaxMiB *int64 `json:"swapMaxMiB,omitempty"`
+	// OOMGroup sets memory.oom.group of tasks, so that the OOM killer kills all their processes at once
+	OOMGroup *bool `json:"oomGroup,omitempty"`
+}
+
+// RemediationPolicy configures the remediation actions taken when the doctor healthchecks fail.
+type RemediationPolicy struct {
+	// Actions are the names of the actions to take by healthcheck type, e.g. {"ContainerRuntime": ["restart-docker"]}
+	Actions map[string][]string `json:"actions"`
+	// FailureThreshold is the number of consecutive failed runs of a healthcheck before its actions are taken
+	FailureThreshold int `json:"failureThreshold,omitempty"`
+	// Cooldown is the minimum time between two runs of the same action
+	Cooldown time.Duration `json:"-"`
+	// MaxActionsPerHour is the maximum number of actions taken in any hour
+	MaxActionsPerHour int `json:"maxActionsPerHour,omitempty"`
+	// DryRun records the actions in the audit trail without taking them
+	DryRun bool `json:"dryRun,omitempty"`
+	// AuditFile is the file the audit trail of the actions is appended to, it defaults to a file in the data directory
+	AuditFile string `json:"auditFile,omitempty"`
+}
+
+// DrainPolicy configures the conditions on which the container instance is drained.
+type DrainPolicy struct {
+	// Triggers are the names of the conditions draining the container instance, e.g. ["maintenance-event", "shutdown"]
+	Triggers []string `json:"triggers"`
+	// HealthcheckFailureThreshold is the number of consecutive failed runs of a healthcheck draining the instance
+	HealthcheckFailureThreshold int `json:"healthcheckFailureThreshold,omitempty"`
+	// DrainFile is the file whose creation drains the instance, it defaults to a file in the data directory
+	DrainFile string `json:"drainFile,omitempty"`
+	// TaskStopTimeout is the maximum time to wait for the tasks to stop once the instance is draining
+	TaskStopTimeout time.Duration `json:"-"`
+}
@@ -0,0 +1,232 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	backupFilePrefix     = "agent-"
+	backupFileSuffix     = ".db"
+	backupChecksumSuffix = ".sha256"
+	backupTmpSuffix      = ".tmp"
+	// backupTimeFormat sorts lexically in chronological order, so the newest backup is the last one by name.
+	backupTimeFormat = "20060102T150405.000000000Z"
+	backupDirMode    = 0700
+	corruptDBSuffix  = ".corrupt-"
+)
+
+// Backup writes a consistent snapshot of the database to a new file in dir together with
+// a file holding its SHA-256 checksum, and removes all but the newest keep snapshots. The
+// snapshot is taken inside a read transaction, so it doesn't block writers.
+func (c *client) Backup(dir string, keep int) (string, error) {
+	if err := os.MkdirAll(dir, backupDirMode); err != nil {
+		return "", errors.Wrapf(err, "failed to create backup directory %s", dir)
+	}
+	backupPath := filepath.Join(dir, backupFilePrefix+time.Now().UTC().Format(backupTimeFormat)+backupFileSuffix)
+	tmpPath := backupPath + backupTmpSuffix
+
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, dbMode)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create backup file")
+	}
+	hash := sha256.New()
+	err = c.DB.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(io.MultiWriter(file, hash))
+		return err
+	})
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", errors.Wrap(err, "failed to write backup file")
+	}
+	if err = os.Rename(tmpPath, backupPath); err != nil {
+		os.Remove(tmpPath)
+		return "", errors.Wrap(err, "failed to rename backup file")
+	}
+	// Use the sha256sum format so that backups can also be verified by hand.
+	checksum := fmt.Sprintf("%s  %s\n", hex.EncodeToString(hash.Sum(nil)), filepath.Base(backupPath))
+	if err = os.WriteFile(backupPath+backupChecksumSuffix, []byte(checksum), dbMode); err != nil {
+		os.Remove(backupPath)
+		return "", errors.Wrap(err, "failed to write backup checksum file")
+	}
+
+	return backupPath, rotateBackups(dir, keep)
+}
+
+// RestoreLatestBackup replaces the database in dataDir with the newest backup in backupDir
+// whose checksum matches and which passes a consistency check. The database being replaced is
+// kept next to it with a ".corrupt-<timestamp>" suffix. It returns the path of the restored backup.
+func RestoreLatestBackup(dataDir, backupDir string) (string, error) {
+	backups, err := listBackups(backupDir)
+	if err != nil {
+		return "", err
+	}
+	for i := len(backups) - 1; i >= 0; i-- {
+		backupPath := filepath.Join(backupDir, backups[i])
+		if err := verifyBackup(backupPath); err != nil {
+			logger.Warn("Skipping invalid state database backup", logger.Fields{
+				"backup":    backupPath,
+				field.Error: err,
+			})
+			continue
+		}
+		if err := restoreBackup(dataDir, backupPath); err != nil {
+			return "", err
+		}
+		return backupPath, nil
+	}
+	return "", errors.Errorf("no valid backup found in %s", backupDir)
+}
+
+// listBackups returns the file names of the backups in dir, oldest first.
+func listBackups(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupFilePrefix) || !strings.HasSuffix(name, backupFileSuffix) {
+			continue
+		}
+		backups = append(backups, name)
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// rotateBackups removes all but the newest keep backups in dir.
+func rotateBackups(dir string, keep int) error {
+	backups, err := listBackups(dir)
+	if err != nil {
+		return err
+	}
+	for len(backups) > keep {
+		backupPath := filepath.Join(dir, backups[0])
+		if err := os.Remove(backupPath); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to remove old backup %s", backupPath)
+		}
+		if err := os.Remove(backupPath + backupChecksumSuffix); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to remove checksum of old backup %s", backupPath)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// verifyBackup checks the backup against its checksum file and runs a consistency check on it.
+func verifyBackup(backupPath string) error {
+	checksum, err := os.ReadFile(backupPath + backupChecksumSuffix)
+	if err != nil {
+		return errors.Wrap(err, "failed to read checksum file")
+	}
+	fields := strings.Fields(string(checksum))
+	if len(fields) == 0 {
+		return errors.New("empty checksum file")
+	}
+
+	file, err := os.Open(backupPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return err
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != fields[0] {
+		return errors.Errorf("checksum mismatch: expected %s, got %s", fields[0], actual)
+	}
+
+	db, err := bolt.Open(backupPath, dbMode, &bolt.Options{Timeout: offlineOpenTimeout, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.View(func(tx *bolt.Tx) error {
+		var checkErr error
+		// Drain the channel so that the check is done with the transaction before it's closed.
+		for err := range tx.Check() {
+			if checkErr == nil {
+				checkErr = err
+			}
+		}
+		return checkErr
+	})
+}
+
+// restoreBackup copies the backup next to the database in dataDir, then moves the database aside and
+// the copy in its place, so that the database is left as it is if the backup can't be copied.
+func restoreBackup(dataDir, backupPath string) error {
+	dbPath := filepath.Join(dataDir, dbName)
+	src, err := os.Open(backupPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmpPath := dbPath + backupTmpSuffix
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, dbMode)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return errors.Wrap(err, "failed to copy backup")
+	}
+
+	if _, err := os.Stat(dbPath); err == nil {
+		corruptPath := dbPath + corruptDBSuffix + time.Now().UTC().Format(backupTimeFormat)
+		if err := os.Rename(dbPath, corruptPath); err != nil {
+			os.Remove(tmpPath)
+			return errors.Wrap(err, "failed to move the database aside")
+		}
+		logger.Warn("Moved unreadable state database aside", logger.Fields{
+			"path": corruptPath,
+		})
+	}
+	if err := os.Rename(tmpPath, dbPath); err != nil {
+		os.Remove(tmpPath)
+		return errors.Wrap(err, "failed to move the backup in place of the database")
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBackup(t *testing.T, dataDir, backupDir string) string {
+	testClient, err := NewWithSetup(dataDir)
+	require.NoError(t, err)
+	defer testClient.Close()
+	require.NoError(t, testClient.SaveTask(&apitask.Task{Arn: testTaskArn}))
+	backupPath, err := testClient.Backup(backupDir, 2)
+	require.NoError(t, err)
+	return backupPath
+}
+
+func TestBackup(t *testing.T) {
+	dataDir, backupDir := t.TempDir(), filepath.Join(t.TempDir(), "backups")
+	backupPath := newTestBackup(t, dataDir, backupDir)
+
+	assert.FileExists(t, backupPath)
+	assert.FileExists(t, backupPath+backupChecksumSuffix)
+	assert.NoError(t, verifyBackup(backupPath))
+}
+
+func TestBackupRotation(t *testing.T) {
+	dataDir, backupDir := t.TempDir(), t.TempDir()
+	testClient, err := NewWithSetup(dataDir)
+	require.NoError(t, err)
+	defer testClient.Close()
+
+	var backupPaths []string
+	for i := 0; i < 3; i++ {
+		backupPath, err := testClient.Backup(backupDir, 2)
+		require.NoError(t, err)
+		backupPaths = append(backupPaths, backupPath)
+	}
+
+	backups, err := listBackups(backupDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Base(backupPaths[1]), filepath.Base(backupPaths[2])}, backups)
+	assert.NoFileExists(t, backupPaths[0]+backupChecksumSuffix)
+}
+
+func TestVerifyBackupChecksumMismatch(t *testing.T) {
+	backupPath := newTestBackup(t, t.TempDir(), t.TempDir())
+	require.NoError(t, os.WriteFile(backupPath+backupChecksumSuffix, []byte("0000  agent.db\n"), dbMode))
+	assert.Error(t, verifyBackup(backupPath))
+}
+
+func TestRestoreLatestBackup(t *testing.T) {
+	dataDir, backupDir := t.TempDir(), t.TempDir()
+	backupPath := newTestBackup(t, dataDir, backupDir)
+	dbPath := filepath.Join(dataDir, dbName)
+	require.NoError(t, os.WriteFile(dbPath, []byte("corrupt"), dbMode))
+
+	restored, err := RestoreLatestBackup(dataDir, backupDir)
+	require.NoError(t, err)
+	assert.Equal(t, backupPath, restored)
+
+	corrupt, err := filepath.Glob(dbPath + corruptDBSuffix + "*")
+	require.NoError(t, err)
+	assert.Len(t, corrupt, 1)
+
+	testClient, err := NewWithSetup(dataDir)
+	require.NoError(t, err)
+	defer testClient.Close()
+	tasks, err := testClient.GetTasks()
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+}
+
+func TestRestoreLatestBackupSkipsInvalidBackups(t *testing.T) {
+	dataDir, backupDir := t.TempDir(), t.TempDir()
+	validBackup := newTestBackup(t, dataDir, backupDir)
+	invalidBackup := filepath.Join(backupDir, backupFilePrefix+"99991231T000000.000000000Z"+backupFileSuffix)
+	require.NoError(t, os.WriteFile(invalidBackup, []byte("invalid"), dbMode))
+
+	restored, err := RestoreLatestBackup(dataDir, backupDir)
+	require.NoError(t, err)
+	assert.Equal(t, validBackup, restored)
+}
+
+func TestRestoreLatestBackupNoBackups(t *testing.T) {
+	_, err := RestoreLatestBackup(t.TempDir(), t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestRestoreBackupCopyFailureKeepsDatabase(t *testing.T) {
+	dataDir := t.TempDir()
+	dbPath := filepath.Join(dataDir, dbName)
+	require.NoError(t, os.WriteFile(dbPath, []byte("corrupt"), dbMode))
+
+	// reading a directory fails once it's opened
+	assert.Error(t, restoreBackup(dataDir, t.TempDir()))
+	data, err := os.ReadFile(dbPath)
+	require.NoError(t, err)
+	assert.Equal(t, "corrupt", string(data))
+	corrupt, err := filepath.Glob(dbPath + corruptDBSuffix + "*")
+	require.NoError(t, err)
+	assert.Empty(t, corrupt)
+	assert.NoFileExists(t, dbPath+backupTmpSuffix)
+}
+
+func TestIsCorrupt(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		db      func(t *testing.T, dbPath string)
+		corrupt bool
+	}{
+		{
+			name: "truncated",
+			db: func(t *testing.T, dbPath string) {
+				require.NoError(t, os.WriteFile(dbPath, []byte("corrupt"), dbMode))
+			},
+			corrupt: true,
+		},
+		{
+			name: "invalid",
+			db: func(t *testing.T, dbPath string) {
+				require.NoError(t, os.WriteFile(dbPath, make([]byte, 64*1024), dbMode))
+			},
+			corrupt: true,
+		},
+		{
+			name: "not a file",
+			db: func(t *testing.T, dbPath string) {
+				require.NoError(t, os.Mkdir(dbPath, 0700))
+			},
+			corrupt: false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dataDir := t.TempDir()
+			tc.db(t, filepath.Join(dataDir, dbName))
+			_, err := setup(dataDir)
+			require.Error(t, err)
+			assert.Equal(t, tc.corrupt, isCorrupt(err))
+		})
+	}
+}
@@ -14,15 +14,23 @@
 package data
 
 import (
+	"errors"
+	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/api/container"
 	"github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/agent/data/transformationfunctions"
 	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment/resource"
 	generaldata "github.com/aws/amazon-ecs-agent/ecs-agent/data"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/modeltransformer"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	bolt "go.etcd.io/bbolt"
@@ -32,12 +40,23 @@ const (
 	dbName = "agent.db"
 	dbMode = 0600
 
+	// offlineOpenTimeout is how long NewOffline waits for the database lock. A running agent holds
+	// the lock for its entire lifetime, so waiting any longer than this is pointless.
+	offlineOpenTimeout = 5 * time.Second
+	// compactTxMaxSize is the maximum size of a single transaction used while compacting the database.
+	compactTxMaxSize = 64 * 1024
+	compactTmpSuffix = ".compact"
+	// boltFileTooSmallMsg is the message of the error bbolt returns, without an exported error to
+	// match it with, when the database file is truncated.
+	boltFileTooSmallMsg = "file size too small"
+
 	containersBucketName     = "containers"
 	tasksBucketName          = "tasks"
 	imagesBucketName         = "images"
 	eniAttachmentsBucketName = "eniattachments"
 	resAttachmentsBucketName = "resattachments"
 	metadataBucketName       = "metadata"
+	hostPortsBucketName      = "hostports"
 	emptyAgentVersionMsg     = "No version info available in boltDB. Either this is a fresh instance, or we were using state file to persist data. Transformer not applicable."
 )
 
@@ -45,6 +64,9 @@ var (
 	dbClient Client
 	once     sync.Once
 
+	// errCorruptDB is returned when bbolt panics while opening the database.
+	errCorruptDB = errors.New("state database is corrupt")
+
 	buckets = []string{
 		imagesBucketName,
 		containersBucketName,
@@ -52,6 +74,7 @@ var (
 		eniAttachmentsBucketName,
 		resAttachmentsBucketName,
 		metadataBucketName,
+		hostPortsBucketName,
 	}
 )
 
@@ -98,10 +121,23 @@ type Client interface {
 	// GetResourceAttachments gets the data of all the resouce attachments.
 	GetResourceAttachments() ([]*resource.ResourceAttachment, error)
 
+	// SaveHostPortReservation saves the host ports reserved for a task.
+	SaveHostPortReservation(*utils.HostPortReservation) error
+	// DeleteHostPortReservation deletes the host ports reserved for a task.
+	DeleteHostPortReservation(string) error
+	// GetHostPortReservations gets the host ports reserved for all the tasks.
+	GetHostPortReservations() ([]*utils.HostPortReservation, error)
+
 	// SaveMetadata saves a key value pair of metadata.
 	SaveMetadata(string, string) error
 	// GetMetadata gets the value of a certain kind of metadata.
 	GetMetadata(string) (string, error)
+	// GetAllMetadata gets all the metadata key value pairs.
+	GetAllMetadata() (map[string]string, error)
+
+	// Backup writes a consistent snapshot of the database to a directory, keeping only the newest
+	// given number of snapshots, and returns the path of the new snapshot.
+	Backup(string, int) (string, error)
 
 	// Close closes the connection to database.
 	Close() error
@@ -114,9 +150,34 @@ type client struct {
 
 // New returns a data client that implements the Client interface with boltdb.
 func New(dataDir string) (Client, error) {
+	return NewWithBackupFallback(dataDir, "")
+}
+
+// NewWithBackupFallback returns a data client that implements the Client interface with boltdb.
+// If the database is corrupt and backupDir is set, the newest valid backup in backupDir is restored
+// in place of the database before trying again. Other errors, such as a permission error or a full
+// disk, are returned as is, since restoring a backup would only roll back the state.
+func NewWithBackupFallback(dataDir, backupDir string) (Client, error) {
 	var err error
 	once.Do(func() {
 		dbClient, err = setup(dataDir)
+		if err == nil || backupDir == "" || !isCorrupt(err) {
+			return
+		}
+		logger.Error("Unable to open state database, restoring the latest backup", logger.Fields{
+			field.Error: err,
+		})
+		backupPath, restoreErr := RestoreLatestBackup(dataDir, backupDir)
+		if restoreErr != nil {
+			logger.Error("Unable to restore state database from backup", logger.Fields{
+				field.Error: restoreErr,
+			})
+			return
+		}
+		logger.Warn("Restored state database from backup", logger.Fields{
+			"backup": backupPath,
+		})
+		dbClient, err = setup(dataDir)
 	})
 	if err != nil {
 		return nil, err
@@ -130,10 +191,107 @@ func NewWithSetup(dataDir string) (Client, error) {
 	return setup(dataDir)
 }
 
+// NewOffline returns a data client for tools that inspect or repair the database while the agent
+// is stopped. Unlike New, opening fails after a short timeout instead of blocking forever when
+// another process (i.e. a running agent) holds the database lock. When readOnly is set, the
+// database file is opened read-only, no buckets are created and every write fails.
+func NewOffline(dataDir string, readOnly bool) (Client, error) {
+	options := &bolt.Options{
+		Timeout:  offlineOpenTimeout,
+		ReadOnly: readOnly,
+	}
+	if readOnly {
+		db, err := bolt.Open(filepath.Join(dataDir, dbName), dbMode, options)
+		if err != nil {
+			return nil, err
+		}
+		return newClient(db), nil
+	}
+	return setupWithOptions(dataDir, options)
+}
+
+// Compact rewrites the database in dataDir into a new file without free pages and atomically
+// replaces the original with it. It returns the size of the database before and after compaction.
+// The agent must not be running while the database is compacted.
+func Compact(dataDir string) (int64, int64, error) {
+	srcPath := filepath.Join(dataDir, dbName)
+	dstPath := srcPath + compactTmpSuffix
+	srcInfo, err := os.Stat(srcPath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	src, err := bolt.Open(srcPath, dbMode, &bolt.Options{Timeout: offlineOpenTimeout, ReadOnly: true})
+	if err != nil {
+		return 0, 0, err
+	}
+	defer src.Close()
+
+	// Remove any leftover from a previously interrupted compaction.
+	if err = os.Remove(dstPath); err != nil && !os.IsNotExist(err) {
+		return 0, 0, err
+	}
+	dst, err := bolt.Open(dstPath, dbMode, &bolt.Options{Timeout: offlineOpenTimeout})
+	if err != nil {
+		return 0, 0, err
+	}
+	if err = bolt.Compact(dst, src, compactTxMaxSize); err != nil {
+		dst.Close()
+		os.Remove(dstPath)
+		return 0, 0, err
+	}
+	if err = dst.Close(); err != nil {
+		os.Remove(dstPath)
+		return 0, 0, err
+	}
+	dstInfo, err := os.Stat(dstPath)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err = os.Rename(dstPath, srcPath); err != nil {
+		return 0, 0, err
+	}
+	return srcInfo.Size(), dstInfo.Size(), nil
+}
+
 // setup initiates the boltdb client and makes sure the buckets we use and transformer are created, and
 // registers transformation functions to transformer.
+// isCorrupt returns whether an error returned when opening the database means that the database file
+// is corrupt.
+func isCorrupt(err error) bool {
+	for _, corruptErr := range []error{
+		errCorruptDB,
+		bolt.ErrInvalid,
+		bolt.ErrChecksum,
+		bolt.ErrVersionMismatch,
+	} {
+		if errors.Is(err, corruptErr) {
+			return true
+		}
+	}
+	return strings.Contains(err.Error(), boltFileTooSmallMsg)
+}
+
 func setup(dataDir string) (*client, error) {
-	db, err := bolt.Open(filepath.Join(dataDir, dbName), dbMode, nil)
+	return setupWithOptions(dataDir, nil)
+}
+
+func setupWithOptions(dataDir string, options *bolt.Options) (c *client, err error) {
+	var db *bolt.DB
+	defer func() {
+		// boltdb panics instead of returning an error on some kinds of corruption. Turn it into an
+		// error so that the caller can fall back to a backup.
+		if r := recover(); r != nil {
+			if db != nil {
+				db.Close()
+			}
+			c, err = nil, fmt.Errorf("%w: %v", errCorruptDB, r)
+		}
+	}()
+	db, err = bolt.Open(filepath.Join(dataDir, dbName), dbMode, options)
+	if err != nil {
+		return nil, err
+	}
 	err = db.Update(func(tx *bolt.Tx) error {
 		for _, b := range buckets {
 			_, err = tx.CreateBucketIfNotExists([]byte(b))
@@ -144,26 +302,43 @@ func setup(dataDir string) (*client, error) {
 
 		return nil
 	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return newClient(db), nil
+}
 
+// newClient wraps an opened boltdb with a transformer that has the task transformation
+// functions registered.
+func newClient(db *bolt.DB) *client {
 	// create transformer
 	transformer := modeltransformer.NewTransformer()
 
 	// registering task transformation functions
 	transformationfunctions.RegisterTaskTransformationFunctions(transformer)
 
-	if err != nil {
-		return nil, err
-	}
 	return &client{
 		generaldata.Client{
 			Accessor:    generaldata.DBAccessor{},
 			DB:          db,
 			Transformer: transformer,
 		},
-	}, nil
+	}
 }
 
 // Close closes the boltdb connection.
 func (c *client) Close() error {
 	return c.DB.Close()
 }
+
+// walk walks the entries of a bucket. The buckets are created when the database is set up, so a bucket
+// is only absent when the database was opened read-only by NewOffline before the agent ever created it,
+// in which case it's walked as an empty bucket.
+func (c *client) walk(tx *bolt.Tx, bucketName string, callback func(id string, data []byte) error) error {
+	bucket := tx.Bucket([]byte(bucketName))
+	if bucket == nil {
+		return nil
+	}
+	return c.Accessor.Walk(bucket, callback)
+}
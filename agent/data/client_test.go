@@ -20,9 +20,11 @@ import (
 	"path/filepath"
 	"testing"
 
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	generaldata "github.com/aws/amazon-ecs-agent/ecs-agent/data"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/modeltransformer"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	bolt "go.etcd.io/bbolt"
 )
@@ -56,3 +58,79 @@ func newTestClient(t *testing.T) Client {
 	})
 	return testClient
 }
+
+func TestNewOfflineReadOnly(t *testing.T) {
+	testDir := t.TempDir()
+	setupClient, err := NewWithSetup(testDir)
+	require.NoError(t, err)
+	require.NoError(t, setupClient.SaveTask(&apitask.Task{Arn: testTaskArn}))
+	require.NoError(t, setupClient.Close())
+
+	testClient, err := NewOffline(testDir, true)
+	require.NoError(t, err)
+	defer testClient.Close()
+
+	tasks, err := testClient.GetTasks()
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.ErrorIs(t, testClient.DeleteTask("abc"), bolt.ErrDatabaseReadOnly)
+}
+
+func TestNewOfflineReadOnlyMissingDatabase(t *testing.T) {
+	_, err := NewOffline(t.TempDir(), true)
+	assert.Error(t, err)
+}
+
+func TestNewOfflineReadOnlyMissingBuckets(t *testing.T) {
+	testDir := t.TempDir()
+	db, err := bolt.Open(filepath.Join(testDir, dbName), dbMode, nil)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	testClient, err := NewOffline(testDir, true)
+	require.NoError(t, err)
+	defer testClient.Close()
+
+	tasks, err := testClient.GetTasks()
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+	containers, err := testClient.GetContainers()
+	require.NoError(t, err)
+	assert.Empty(t, containers)
+	images, err := testClient.GetImageStates()
+	require.NoError(t, err)
+	assert.Empty(t, images)
+	enis, err := testClient.GetENIAttachments()
+	require.NoError(t, err)
+	assert.Empty(t, enis)
+	resources, err := testClient.GetResourceAttachments()
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+	reservations, err := testClient.GetHostPortReservations()
+	require.NoError(t, err)
+	assert.Empty(t, reservations)
+	metadata, err := testClient.GetAllMetadata()
+	require.NoError(t, err)
+	assert.Empty(t, metadata)
+}
+
+func TestCompact(t *testing.T) {
+	testDir := t.TempDir()
+	setupClient, err := NewWithSetup(testDir)
+	require.NoError(t, err)
+	require.NoError(t, setupClient.SaveTask(&apitask.Task{Arn: testTaskArn}))
+	require.NoError(t, setupClient.Close())
+
+	before, after, err := Compact(testDir)
+	require.NoError(t, err)
+	assert.True(t, before > 0)
+	assert.True(t, after > 0)
+	assert.NoFileExists(t, filepath.Join(testDir, dbName+compactTmpSuffix))
+
+	testClient, err := NewOffline(testDir, true)
+	require.NoError(t, err)
+	defer testClient.Close()
+	tasks, err := testClient.GetTasks()
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+}
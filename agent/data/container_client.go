@@ -75,8 +75,7 @@ func (c *client) DeleteContainer(id string) error {
 func (c *client) GetContainers() ([]*apicontainer.DockerContainer, error) {
 	var containers []*apicontainer.DockerContainer
 	err := c.DB.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(containersBucketName))
-		return c.Accessor.Walk(bucket, func(id string, data []byte) error {
+		return c.walk(tx, containersBucketName, func(id string, data []byte) error {
 			container := apicontainer.DockerContainer{}
 			if err := json.Unmarshal(data, &container); err != nil {
 				return err
@@ -44,8 +44,7 @@ func (c *client) DeleteENIAttachment(id string) error {
 func (c *client) GetENIAttachments() ([]*ni.ENIAttachment, error) {
 	var eniAttachments []*ni.ENIAttachment
 	err := c.DB.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(eniAttachmentsBucketName))
-		return c.Accessor.Walk(bucket, func(id string, data []byte) error {
+		return c.walk(tx, eniAttachmentsBucketName, func(id string, data []byte) error {
 			eniAttachment := ni.ENIAttachment{}
 			if err := json.Unmarshal(data, &eniAttachment); err != nil {
 				return err
@@ -0,0 +1,63 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"encoding/json"
+
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+// SaveHostPortReservation saves the host ports reserved for a task to the host ports bucket.
+func (c *client) SaveHostPortReservation(reservation *utils.HostPortReservation) error {
+	id, err := utils.GetTaskID(reservation.TaskARN)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate database id")
+	}
+	return c.DB.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(hostPortsBucketName))
+		return c.Accessor.PutObject(b, id, reservation)
+	})
+}
+
+// DeleteHostPortReservation deletes the host ports reserved for a task from the host ports bucket.
+func (c *client) DeleteHostPortReservation(taskARN string) error {
+	id, err := utils.GetTaskID(taskARN)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate database id")
+	}
+	return c.DB.Batch(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(hostPortsBucketName))
+		return b.Delete([]byte(id))
+	})
+}
+
+// GetHostPortReservations returns all the host port reservations in the host ports bucket.
+func (c *client) GetHostPortReservations() ([]*utils.HostPortReservation, error) {
+	var reservations []*utils.HostPortReservation
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		return c.walk(tx, hostPortsBucketName, func(id string, data []byte) error {
+			reservation := utils.HostPortReservation{}
+			if err := json.Unmarshal(data, &reservation); err != nil {
+				return err
+			}
+			reservations = append(reservations, &reservation)
+			return nil
+		})
+	})
+	return reservations, err
+}
@@ -0,0 +1,55 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package data
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManageHostPortReservations(t *testing.T) {
+	testClient := newTestClient(t)
+
+	reservation := &utils.HostPortReservation{
+		TaskARN: testTaskArn,
+		Ranges: []utils.HostPortRange{
+			{Protocol: "tcp", StartPort: 40000, EndPort: 40000},
+			{Protocol: "udp", StartPort: 40001, EndPort: 40010},
+		},
+	}
+	require.NoError(t, testClient.SaveHostPortReservation(reservation))
+	res, err := testClient.GetHostPortReservations()
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, reservation, res[0])
+
+	require.NoError(t, testClient.DeleteHostPortReservation(testTaskArn))
+	res, err = testClient.GetHostPortReservations()
+	require.NoError(t, err)
+	assert.Len(t, res, 0)
+}
+
+func TestSaveHostPortReservationInvalidID(t *testing.T) {
+	testClient := newTestClient(t)
+
+	assert.Error(t, testClient.SaveHostPortReservation(&utils.HostPortReservation{TaskARN: "invalid-arn"}))
+	assert.Error(t, testClient.DeleteHostPortReservation("invalid-arn"))
+}
@@ -43,8 +43,7 @@ func (c *client) DeleteImageState(id string) error {
 func (c *client) GetImageStates() ([]*image.ImageState, error) {
 	var imageStates []*image.ImageState
 	err := c.DB.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(imagesBucketName))
-		return c.Accessor.Walk(bucket, func(id string, data []byte) error {
+		return c.walk(tx, imagesBucketName, func(id string, data []byte) error {
 			imageState := image.ImageState{}
 			if err := json.Unmarshal(data, &imageState); err != nil {
 				return err
@@ -14,6 +14,8 @@
 package data
 
 import (
+	"encoding/json"
+
 	bolt "go.etcd.io/bbolt"
 )
 
@@ -24,6 +26,8 @@ const (
 	ContainerInstanceARNKey = "container-instance-arn"
 	EC2InstanceIDKey        = "ec2-instance-id"
 	TaskManifestSeqNumKey   = "task-manifest-seq-num"
+	RemediationStateKey     = "remediation-state"
+	TaskProtectionKey       = "task-protection"
 )
 
 func (c *client) SaveMetadata(key, val string) error {
@@ -40,3 +44,19 @@ func (c *client) GetMetadata(key string) (string, error) {
 	})
 	return val, err
 }
+
+// GetAllMetadata returns every key-value pair stored in the metadata bucket.
+func (c *client) GetAllMetadata() (map[string]string, error) {
+	metadata := make(map[string]string)
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		return c.walk(tx, metadataBucketName, func(key string, data []byte) error {
+			var val string
+			if err := json.Unmarshal(data, &val); err != nil {
+				return err
+			}
+			metadata[key] = val
+			return nil
+		})
+	})
+	return metadata, err
+}
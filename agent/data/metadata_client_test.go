@@ -37,3 +37,17 @@ func TestManageMetadata(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, testVal, val)
 }
+
+func TestGetAllMetadata(t *testing.T) {
+	testClient := newTestClient(t)
+
+	require.NoError(t, testClient.SaveMetadata(testKey, testVal))
+	require.NoError(t, testClient.SaveMetadata(ClusterNameKey, "test-cluster"))
+
+	metadata, err := testClient.GetAllMetadata()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		testKey:        testVal,
+		ClusterNameKey: "test-cluster",
+	}, metadata)
+}
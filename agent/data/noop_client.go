@@ -17,6 +17,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/api/container"
 	"github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment/resource"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 )
@@ -93,6 +94,18 @@ func (c *noopClient) GetResourceAttachments() ([]*resource.ResourceAttachment, e
 	return nil, nil
 }
 
+func (c *noopClient) SaveHostPortReservation(*utils.HostPortReservation) error {
+	return nil
+}
+
+func (c *noopClient) DeleteHostPortReservation(string) error {
+	return nil
+}
+
+func (c *noopClient) GetHostPortReservations() ([]*utils.HostPortReservation, error) {
+	return nil, nil
+}
+
 func (c *noopClient) SaveMetadata(string, string) error {
 	return nil
 }
@@ -101,6 +114,14 @@ func (c *noopClient) GetMetadata(string) (string, error) {
 	return "", nil
 }
 
+func (c *noopClient) GetAllMetadata() (map[string]string, error) {
+	return nil, nil
+}
+
+func (c *noopClient) Backup(string, int) (string, error) {
+	return "", nil
+}
+
 func (c *noopClient) Close() error {
 	return nil
 }
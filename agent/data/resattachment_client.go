@@ -44,8 +44,7 @@ func (c *client) DeleteResourceAttachment(id string) error {
 func (c *client) GetResourceAttachments() ([]*resource.ResourceAttachment, error) {
 	var resAttachments []*resource.ResourceAttachment
 	err := c.DB.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(resAttachmentsBucketName))
-		return c.Accessor.Walk(bucket, func(id string, data []byte) error {
+		return c.walk(tx, resAttachmentsBucketName, func(id string, data []byte) error {
 			resAttachment := resource.ResourceAttachment{}
 			if err := json.Unmarshal(data, &resAttachment); err != nil {
 				return err
@@ -49,8 +49,7 @@ func (c *client) DeleteTask(id string) error {
 func (c *client) GetTasks() ([]*apitask.Task, error) {
 	var tasks []*apitask.Task
 	err := c.DB.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(tasksBucketName))
-		return c.Accessor.Walk(bucket, func(id string, data []byte) error {
+		return c.walk(tx, tasksBucketName, func(id string, data []byte) error {
 			task := apitask.Task{}
 			// transform the model before loading it to agent state. this is a noop for now.
 			agentVersionInDB, err := c.GetMetadata(AgentVersionKey)
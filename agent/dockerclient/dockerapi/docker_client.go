@@ -173,6 +173,10 @@ type DockerClient interface {
 	// provided for the request.
 	InspectContainer(context.Context, string, time.Duration) (*types.ContainerJSON, error)
 
+	// ContainerWritableLayerSize returns the size in bytes of the files created or changed in the writable
+	// layer of the specified container. A timeout value and a context should be provided for the request.
+	ContainerWritableLayerSize(context.Context, string, time.Duration) (int64, error)
+
 	// CreateContainerExec creates a new exec configuration to run an exec process with the provided Config. A timeout value
 	// and a context should be provided for the request.
 	CreateContainerExec(ctx context.Context, containerID string, execConfig types.ExecConfig, timeout time.Duration) (*types.IDResponse, error)
@@ -825,6 +829,27 @@ func (dg *dockerGoClient) inspectContainer(ctx context.Context, dockerID string)
 	return &containerData, err
 }
 
+func (dg *dockerGoClient) ContainerWritableLayerSize(ctx context.Context, dockerID string, timeout time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	client, err := dg.sdkDockerClient()
+	if err != nil {
+		return 0, err
+	}
+	// Computing the size walks the writable layer, which can take long on containers with many files
+	containerData, _, err := client.ContainerInspectWithRaw(ctx, dockerID, true)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return 0, &DockerTimeoutError{timeout, "inspecting the size of"}
+		}
+		return 0, &CannotInspectContainerError{err}
+	}
+	if containerData.SizeRw == nil {
+		return 0, nil
+	}
+	return *containerData.SizeRw, nil
+}
+
 func (dg *dockerGoClient) StopContainer(ctx context.Context, dockerID string, timeout time.Duration) DockerContainerMetadata {
 	ctxTimeout := timeout + stopContainerTimeoutBuffer
 	ctx, cancel := context.WithTimeout(ctx, ctxTimeout)
@@ -993,13 +1018,19 @@ func getMetadataHealthCheck(dockerContainer *types.ContainerJSON) apicontainer.H
 	logLength := len(dockerContainer.State.Health.Log)
 
 	if logLength != 0 {
-		// Only save the last log from the health check
-		output := dockerContainer.State.Health.Log[logLength-1].Output
-		size := len(output)
-		if size > maxHealthCheckOutputLength {
-			size = maxHealthCheckOutputLength
+		// Only save the last log from the health check as the output
+		health.Output = truncateHealthCheckOutput(dockerContainer.State.Health.Log[logLength-1].Output)
+	}
+	for _, result := range dockerContainer.State.Health.Log {
+		if result == nil {
+			continue
 		}
-		health.Output = output[:size]
+		health.Results = append(health.Results, apicontainer.HealthCheckResult{
+			Start:    result.Start,
+			End:      result.End,
+			ExitCode: result.ExitCode,
+			Output:   truncateHealthCheckOutput(result.Output),
+		})
 	}
 	switch dockerContainer.State.Health.Status {
 	case healthCheckHealthy:
@@ -1017,6 +1048,14 @@ func getMetadataHealthCheck(dockerContainer *types.ContainerJSON) apicontainer.H
 	return health
 }
 
+// truncateHealthCheckOutput truncates the output of a health check to the length the agent saves
+func truncateHealthCheckOutput(output string) string {
+	if len(output) > maxHealthCheckOutputLength {
+		return output[:maxHealthCheckOutputLength]
+	}
+	return output
+}
+
 // Listen to the docker event stream for container changes and pass them up
 func (dg *dockerGoClient) ContainerEvents(ctx context.Context) (<-chan DockerContainerChangeEvent, error) {
 	client, err := dg.sdkDockerClient()
@@ -951,6 +951,30 @@ func TestInspectContainer(t *testing.T) {
 	assert.True(t, reflect.DeepEqual(&containerOutput, container))
 }
 
+func TestContainerWritableLayerSize(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	sizeRw := int64(64 * 1024 * 1024)
+	mockDockerSDK.EXPECT().ContainerInspectWithRaw(gomock.Any(), "id", true).Return(types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{ID: "id", SizeRw: &sizeRw},
+	}, nil, nil)
+	size, err := client.ContainerWritableLayerSize(context.TODO(), "id", dockerclient.InspectContainerTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, sizeRw, size)
+}
+
+func TestContainerWritableLayerSizeError(t *testing.T) {
+	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
+	defer done()
+
+	mockDockerSDK.EXPECT().ContainerInspectWithRaw(gomock.Any(), "id", true).Return(types.ContainerJSON{}, nil,
+		errors.New("test error"))
+	_, err := client.ContainerWritableLayerSize(context.TODO(), "id", dockerclient.InspectContainerTimeout)
+	assert.Error(t, err)
+	assert.Equal(t, "CannotInspectContainerError", err.(apierrors.NamedError).ErrorName())
+}
+
 func TestContainerEvents(t *testing.T) {
 	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
 	defer done()
@@ -2058,6 +2082,33 @@ func TestMetadataFromContainerHealthCheckWithNoLogs(t *testing.T) {
 	assert.Equal(t, apicontainerstatus.ContainerUnhealthy, metadata.Health.Status)
 }
 
+func TestMetadataFromContainerHealthCheckResults(t *testing.T) {
+	start := time.Now()
+	longOutput := strings.Repeat("x", maxHealthCheckOutputLength+1)
+	dockerContainer := &types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			State: &types.ContainerState{
+				Health: &types.Health{
+					Status: "unhealthy",
+					Log: []*types.HealthcheckResult{
+						{Start: start, End: start.Add(time.Second), ExitCode: 0, Output: "ok"},
+						{Start: start.Add(time.Minute), End: start.Add(time.Minute + time.Second), ExitCode: 1, Output: longOutput},
+					},
+				},
+			},
+		},
+	}
+
+	metadata := MetadataFromContainer(dockerContainer)
+	assert.Equal(t, apicontainerstatus.ContainerUnhealthy, metadata.Health.Status)
+	assert.Equal(t, 1, metadata.Health.ExitCode)
+	assert.Equal(t, []apicontainer.HealthCheckResult{
+		{Start: start, End: start.Add(time.Second), ExitCode: 0, Output: "ok"},
+		{Start: start.Add(time.Minute), End: start.Add(time.Minute + time.Second), ExitCode: 1,
+			Output: longOutput[:maxHealthCheckOutputLength]},
+	}, metadata.Health.Results)
+}
+
 func TestCreateVolumeTimeout(t *testing.T) {
 	mockDockerSDK, client, _, _, _, done := dockerClientSetup(t)
 	defer done()
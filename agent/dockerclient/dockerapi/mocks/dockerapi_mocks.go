@@ -90,6 +90,21 @@ func (mr *MockDockerClientMockRecorder) ContainerEvents(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainerEvents", reflect.TypeOf((*MockDockerClient)(nil).ContainerEvents), arg0)
 }
 
+// ContainerWritableLayerSize mocks base method.
+func (m *MockDockerClient) ContainerWritableLayerSize(arg0 context.Context, arg1 string, arg2 time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContainerWritableLayerSize", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ContainerWritableLayerSize indicates an expected call of ContainerWritableLayerSize.
+func (mr *MockDockerClientMockRecorder) ContainerWritableLayerSize(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContainerWritableLayerSize", reflect.TypeOf((*MockDockerClient)(nil).ContainerWritableLayerSize), arg0, arg1, arg2)
+}
+
 // CreateContainer mocks base method.
 func (m *MockDockerClient) CreateContainer(arg0 context.Context, arg1 *container0.Config, arg2 *container0.HostConfig, arg3 string, arg4 time.Duration) dockerapi.DockerContainerMetadata {
 	m.ctrl.T.Helper()
@@ -0,0 +1,95 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package doctor
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/doctor/statustracker"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+const (
+	// Default maximum skew between the instance clock and the reference clock. Requests signed
+	// with SigV4 are rejected when the clock is skewed by more than 5 minutes.
+	DefaultMaxClockSkew = time.Minute
+	// Default request timeout for clock skew health check requests
+	DefaultClockSkewRequestTimeout = 5 * time.Second
+)
+
+// Health check for the skew of the instance clock, using the Date header of the responses of
+// a reference HTTP endpoint as reference clock.
+type clockSkewHealthcheck struct {
+	referenceURL string
+	maxSkew      time.Duration
+	client       *http.Client
+	now          func() time.Time // function that returns current time (injected for testing)
+	*statustracker.HealthCheckStatusTracker
+}
+
+// Constructor for Clock Skew Health Check
+func NewClockSkewHealthcheck(
+	referenceURL string, // URL of the endpoint whose Date header is the reference clock
+	maxSkew time.Duration, // maximum skew of the instance clock
+	requestTimeout time.Duration, // timeout for health check requests
+) doctor.Healthcheck {
+	return &clockSkewHealthcheck{
+		referenceURL:             referenceURL,
+		maxSkew:                  maxSkew,
+		client:                   &http.Client{Timeout: requestTimeout},
+		now:                      time.Now,
+		HealthCheckStatusTracker: statustracker.NewHealthCheckStatusTracker(),
+	}
+}
+
+// Performs a health check of the clock skew by comparing the Date header of a response of the
+// reference endpoint with the instance clock halfway through the request. The status isn't
+// changed when the reference clock can't be read, as that says nothing about the instance clock.
+func (c *clockSkewHealthcheck) RunCheck() doctor.HealthcheckStatus {
+	start := c.now()
+	resp, err := c.client.Head(c.referenceURL)
+	if err != nil {
+		logger.Warn("Unable to read the reference clock", logger.Fields{field.Error: err})
+		return c.GetHealthcheckStatus()
+	}
+	resp.Body.Close()
+	end := c.now()
+	referenceTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		logger.Warn("Unable to read the reference clock", logger.Fields{field.Error: err})
+		return c.GetHealthcheckStatus()
+	}
+
+	skew := start.Add(end.Sub(start) / 2).Sub(referenceTime)
+	if skew < 0 {
+		skew = -skew
+	}
+	// The Date header has a resolution of one second
+	if skew > c.maxSkew+time.Second {
+		logger.Warn("Instance clock is skewed", logger.Fields{
+			"skew":    skew.String(),
+			"maxSkew": c.maxSkew.String(),
+		})
+		c.SetHealthcheckStatus(doctor.HealthcheckStatusImpaired)
+		return c.GetHealthcheckStatus()
+	}
+	c.SetHealthcheckStatus(doctor.HealthcheckStatusOk)
+	return c.GetHealthcheckStatus()
+}
+
+func (c *clockSkewHealthcheck) GetHealthcheckType() string {
+	return doctor.HealthcheckTypeClockSkew
+}
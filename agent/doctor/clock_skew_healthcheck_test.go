@@ -0,0 +1,84 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package doctor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests that Clock Skew Health Check is of the right health check type
+func TestClockSkewGetHealthcheckType(t *testing.T) {
+	hc := NewClockSkewHealthcheck("https://ecs.us-west-2.amazonaws.com", DefaultMaxClockSkew, 0)
+
+	assert.Equal(t, doctor.HealthcheckTypeClockSkew, hc.GetHealthcheckType())
+	assert.Equal(t, doctor.HealthcheckStatusInitializing, hc.GetHealthcheckStatus())
+}
+
+// Tests RunCheck method of Clock Skew Health Check
+func TestClockSkewRunHealthCheck(t *testing.T) {
+	referenceTime := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	tcs := []struct {
+		name           string
+		date           string
+		now            time.Time
+		expectedStatus doctor.HealthcheckStatus
+	}{
+		{
+			name:           "OK when the clock is in sync",
+			date:           referenceTime.Format(http.TimeFormat),
+			now:            referenceTime.Add(500 * time.Millisecond),
+			expectedStatus: doctor.HealthcheckStatusOk,
+		},
+		{
+			name:           "IMPAIRED when the clock is ahead",
+			date:           referenceTime.Format(http.TimeFormat),
+			now:            referenceTime.Add(2 * time.Minute),
+			expectedStatus: doctor.HealthcheckStatusImpaired,
+		},
+		{
+			name:           "IMPAIRED when the clock is behind",
+			date:           referenceTime.Format(http.TimeFormat),
+			now:            referenceTime.Add(-2 * time.Minute),
+			expectedStatus: doctor.HealthcheckStatusImpaired,
+		},
+		{
+			name:           "unchanged when the reference clock can't be read",
+			date:           "yesterday",
+			now:            referenceTime,
+			expectedStatus: doctor.HealthcheckStatusInitializing,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Date", tc.date)
+			}))
+			defer server.Close()
+			hc := NewClockSkewHealthcheck(server.URL, DefaultMaxClockSkew,
+				DefaultClockSkewRequestTimeout).(*clockSkewHealthcheck)
+			hc.now = func() time.Time { return tc.now }
+
+			assert.Equal(t, tc.expectedStatus, hc.RunCheck())
+		})
+	}
+}
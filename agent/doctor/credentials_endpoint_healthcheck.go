@@ -0,0 +1,70 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package doctor
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/doctor/statustracker"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+const (
+	// Default request timeout for credentials endpoint health check requests
+	DefaultCredentialsEndpointRequestTimeout = 2 * time.Second
+)
+
+// Health check for the reachability of the task credentials endpoint.
+type credentialsEndpointHealthcheck struct {
+	url    string
+	client *http.Client
+	*statustracker.HealthCheckStatusTracker
+}
+
+// Constructor for Credentials Endpoint Health Check
+func NewCredentialsEndpointHealthcheck(
+	url string, // URL of the credentials endpoint
+	requestTimeout time.Duration, // timeout for health check requests
+) doctor.Healthcheck {
+	return &credentialsEndpointHealthcheck{
+		url:                      url,
+		client:                   &http.Client{Timeout: requestTimeout},
+		HealthCheckStatusTracker: statustracker.NewHealthCheckStatusTracker(),
+	}
+}
+
+// Performs a health check of the credentials endpoint by sending a request without credentials ID
+// to it. Any response other than a server error means that the endpoint is serving requests.
+func (c *credentialsEndpointHealthcheck) RunCheck() doctor.HealthcheckStatus {
+	resp, err := c.client.Get(c.url)
+	if err != nil {
+		logger.Error("Credentials endpoint health check failed", logger.Fields{field.Error: err})
+		c.SetHealthcheckStatus(doctor.HealthcheckStatusImpaired)
+		return c.GetHealthcheckStatus()
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		logger.Error("Credentials endpoint health check failed", logger.Fields{"statusCode": resp.StatusCode})
+		c.SetHealthcheckStatus(doctor.HealthcheckStatusImpaired)
+		return c.GetHealthcheckStatus()
+	}
+	c.SetHealthcheckStatus(doctor.HealthcheckStatusOk)
+	return c.GetHealthcheckStatus()
+}
+
+func (c *credentialsEndpointHealthcheck) GetHealthcheckType() string {
+	return doctor.HealthcheckTypeCredentials
+}
@@ -0,0 +1,74 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package doctor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests that Credentials Endpoint Health Check is of the right health check type
+func TestCredentialsEndpointGetHealthcheckType(t *testing.T) {
+	hc := NewCredentialsEndpointHealthcheck("http://127.0.0.1:51679/v2/credentials", 0)
+
+	assert.Equal(t, doctor.HealthcheckTypeCredentials, hc.GetHealthcheckType())
+	assert.Equal(t, doctor.HealthcheckStatusInitializing, hc.GetHealthcheckStatus())
+}
+
+// Tests RunCheck method of Credentials Endpoint Health Check
+func TestCredentialsEndpointRunHealthCheck(t *testing.T) {
+	tcs := []struct {
+		name           string
+		statusCode     int
+		expectedStatus doctor.HealthcheckStatus
+	}{
+		{
+			name:           "OK when the endpoint rejects the request",
+			statusCode:     http.StatusBadRequest,
+			expectedStatus: doctor.HealthcheckStatusOk,
+		},
+		{
+			name:           "IMPAIRED when the endpoint fails",
+			statusCode:     http.StatusInternalServerError,
+			expectedStatus: doctor.HealthcheckStatusImpaired,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer server.Close()
+			hc := NewCredentialsEndpointHealthcheck(server.URL, DefaultCredentialsEndpointRequestTimeout)
+
+			assert.Equal(t, tc.expectedStatus, hc.RunCheck())
+		})
+	}
+}
+
+// Tests that Credentials Endpoint Health Check is IMPAIRED when the endpoint isn't reachable
+func TestCredentialsEndpointRunHealthCheckUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	hc := NewCredentialsEndpointHealthcheck(server.URL, DefaultCredentialsEndpointRequestTimeout)
+
+	assert.Equal(t, doctor.HealthcheckStatusImpaired, hc.RunCheck())
+}
@@ -0,0 +1,57 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package doctor
+
+import (
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/doctor/statustracker"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+// objNotFoundErrMsg is part of the error returned when a key isn't in the database, which happens
+// when the agent starts with an empty database
+const objNotFoundErrMsg = "not found"
+
+// Health check for the agent state database.
+type dataStoreHealthcheck struct {
+	dataClient data.Client
+	*statustracker.HealthCheckStatusTracker
+}
+
+// Constructor for Data Store Health Check
+func NewDataStoreHealthcheck(dataClient data.Client) doctor.Healthcheck {
+	return &dataStoreHealthcheck{
+		dataClient:               dataClient,
+		HealthCheckStatusTracker: statustracker.NewHealthCheckStatusTracker(),
+	}
+}
+
+// Performs a health check of the state database by reading the agent version from it.
+func (d *dataStoreHealthcheck) RunCheck() doctor.HealthcheckStatus {
+	_, err := d.dataClient.GetMetadata(data.AgentVersionKey)
+	if err != nil && !strings.Contains(err.Error(), objNotFoundErrMsg) {
+		logger.Error("State database health check failed", logger.Fields{field.Error: err})
+		d.SetHealthcheckStatus(doctor.HealthcheckStatusImpaired)
+		return d.GetHealthcheckStatus()
+	}
+	d.SetHealthcheckStatus(doctor.HealthcheckStatusOk)
+	return d.GetHealthcheckStatus()
+}
+
+func (d *dataStoreHealthcheck) GetHealthcheckType() string {
+	return doctor.HealthcheckTypeDataStore
+}
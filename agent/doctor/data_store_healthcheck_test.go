@@ -0,0 +1,50 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package doctor
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Tests that Data Store Health Check is of the right health check type
+func TestDataStoreGetHealthcheckType(t *testing.T) {
+	hc := NewDataStoreHealthcheck(data.NewNoopClient())
+
+	assert.Equal(t, doctor.HealthcheckTypeDataStore, hc.GetHealthcheckType())
+	assert.Equal(t, doctor.HealthcheckStatusInitializing, hc.GetHealthcheckStatus())
+}
+
+// Tests RunCheck method of Data Store Health Check
+func TestDataStoreRunHealthCheck(t *testing.T) {
+	dataClient, err := data.NewWithSetup(t.TempDir())
+	require.NoError(t, err)
+	hc := NewDataStoreHealthcheck(dataClient)
+
+	// An empty database is healthy
+	assert.Equal(t, doctor.HealthcheckStatusOk, hc.RunCheck())
+
+	require.NoError(t, dataClient.SaveMetadata(data.AgentVersionKey, "1.0.0"))
+	assert.Equal(t, doctor.HealthcheckStatusOk, hc.RunCheck())
+
+	// A closed database can't be read
+	require.NoError(t, dataClient.Close())
+	assert.Equal(t, doctor.HealthcheckStatusImpaired, hc.RunCheck())
+}
@@ -0,0 +1,79 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package doctor
+
+import (
+	"github.com/aws/amazon-ecs-agent/agent/doctor/statustracker"
+	"github.com/aws/amazon-ecs-agent/agent/hostresources"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+const (
+	// Default minimum disk space that must be available on the filesystems checked by the disk space health check
+	DefaultMinAvailableDiskSpaceMiB = 1024
+)
+
+// Health check for the disk space available to the agent data directory and the docker data root.
+type diskSpaceHealthcheck struct {
+	paths           []string
+	minAvailableMiB int32
+	// availableDiskSpaceMiB returns the available space of the filesystem of a path (injected for testing)
+	availableDiskSpaceMiB func(string) (int32, error)
+	*statustracker.HealthCheckStatusTracker
+}
+
+// Constructor for Disk Space Health Check
+func NewDiskSpaceHealthcheck(
+	paths []string, // paths whose filesystems are checked
+	minAvailableMiB int32, // disk space that must be available on each filesystem
+) doctor.Healthcheck {
+	return &diskSpaceHealthcheck{
+		paths:                    paths,
+		minAvailableMiB:          minAvailableMiB,
+		availableDiskSpaceMiB:    hostresources.AvailableDiskSpaceMiB,
+		HealthCheckStatusTracker: statustracker.NewHealthCheckStatusTracker(),
+	}
+}
+
+// Performs a health check of the disk space by checking that the filesystem of every path
+// has at least the minimum space available.
+func (d *diskSpaceHealthcheck) RunCheck() doctor.HealthcheckStatus {
+	status := doctor.HealthcheckStatusOk
+	for _, path := range d.paths {
+		available, err := d.availableDiskSpaceMiB(path)
+		if err != nil {
+			logger.Error("Disk space health check failed", logger.Fields{
+				"path":      path,
+				field.Error: err,
+			})
+			status = doctor.HealthcheckStatusImpaired
+			continue
+		}
+		if available < d.minAvailableMiB {
+			logger.Warn("Disk space is running low", logger.Fields{
+				"path":            path,
+				"availableMiB":    available,
+				"minAvailableMiB": d.minAvailableMiB,
+			})
+			status = doctor.HealthcheckStatusImpaired
+		}
+	}
+	d.SetHealthcheckStatus(status)
+	return d.GetHealthcheckStatus()
+}
+
+func (d *diskSpaceHealthcheck) GetHealthcheckType() string {
+	return doctor.HealthcheckTypeDiskSpace
+}
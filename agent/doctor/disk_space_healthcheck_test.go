@@ -0,0 +1,72 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package doctor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests that Disk Space Health Check is of the right health check type
+func TestDiskSpaceGetHealthcheckType(t *testing.T) {
+	hc := NewDiskSpaceHealthcheck([]string{"/"}, DefaultMinAvailableDiskSpaceMiB)
+
+	assert.Equal(t, doctor.HealthcheckTypeDiskSpace, hc.GetHealthcheckType())
+	assert.Equal(t, doctor.HealthcheckStatusInitializing, hc.GetHealthcheckStatus())
+}
+
+// Tests RunCheck method of Disk Space Health Check
+func TestDiskSpaceRunHealthCheck(t *testing.T) {
+	tcs := []struct {
+		name           string
+		availableMiB   map[string]int32
+		expectedStatus doctor.HealthcheckStatus
+	}{
+		{
+			name:           "OK when enough space is available on every path",
+			availableMiB:   map[string]int32{"/data": 2048, "/docker": 1024},
+			expectedStatus: doctor.HealthcheckStatusOk,
+		},
+		{
+			name:           "IMPAIRED when space is running low on a path",
+			availableMiB:   map[string]int32{"/data": 2048, "/docker": 1023},
+			expectedStatus: doctor.HealthcheckStatusImpaired,
+		},
+		{
+			name:           "IMPAIRED when the space of a path can't be found",
+			availableMiB:   map[string]int32{"/data": 2048},
+			expectedStatus: doctor.HealthcheckStatusImpaired,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			hc := NewDiskSpaceHealthcheck([]string{"/data", "/docker"}, 1024).(*diskSpaceHealthcheck)
+			hc.availableDiskSpaceMiB = func(path string) (int32, error) {
+				available, ok := tc.availableMiB[path]
+				if !ok {
+					return 0, errors.New("no such file or directory")
+				}
+				return available, nil
+			}
+
+			assert.Equal(t, tc.expectedStatus, hc.RunCheck())
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package remediation
+
+import (
+	"context"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+// Names of the remediation actions, as used in the remediation policy
+const (
+	RestartDockerActionName   = "restart-docker"
+	ClearImageCacheActionName = "clear-image-cache"
+	DrainInstanceActionName   = "drain-instance"
+)
+
+// ImageCleaner removes the images which aren't used by any container.
+type ImageCleaner interface {
+	RemoveUnusedImages(ctx context.Context)
+}
+
+type clearImageCacheAction struct {
+	imageCleaner ImageCleaner
+}
+
+// NewClearImageCacheAction creates an action which removes the images that aren't used by any container,
+// as the periodic image cleanup does.
+func NewClearImageCacheAction(imageCleaner ImageCleaner) Action {
+	return &clearImageCacheAction{imageCleaner: imageCleaner}
+}
+
+func (a *clearImageCacheAction) Name() string {
+	return ClearImageCacheActionName
+}
+
+func (a *clearImageCacheAction) Run(ctx context.Context) error {
+	a.imageCleaner.RemoveUnusedImages(ctx)
+	return nil
+}
+
+type drainInstanceAction struct {
+	client               ecs.ECSClient
+	containerInstanceARN string
+}
+
+// NewDrainInstanceAction creates an action which sets the container instance to DRAINING, so that
+// ECS moves its service tasks to other instances and doesn't place new tasks on it.
+func NewDrainInstanceAction(client ecs.ECSClient, containerInstanceARN string) Action {
+	return &drainInstanceAction{
+		client:               client,
+		containerInstanceARN: containerInstanceARN,
+	}
+}
+
+func (a *drainInstanceAction) Name() string {
+	return DrainInstanceActionName
+}
+
+func (a *drainInstanceAction) Run(ctx context.Context) error {
+	return a.client.UpdateContainerInstancesState(a.containerInstanceARN, types.ContainerInstanceStatusDraining)
+}
@@ -0,0 +1,55 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package remediation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mock_engine "github.com/aws/amazon-ecs-agent/agent/engine/mocks"
+	mock_ecs "github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs/mocks"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClearImageCacheAction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	imageManager := mock_engine.NewMockImageManager(ctrl)
+	imageManager.EXPECT().RemoveUnusedImages(gomock.Any())
+
+	action := NewClearImageCacheAction(imageManager)
+	assert.Equal(t, ClearImageCacheActionName, action.Name())
+	assert.NoError(t, action.Run(context.Background()))
+}
+
+func TestDrainInstanceAction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_ecs.NewMockECSClient(ctrl)
+	gomock.InOrder(
+		client.EXPECT().UpdateContainerInstancesState("instance-arn", types.ContainerInstanceStatusDraining),
+		client.EXPECT().UpdateContainerInstancesState("instance-arn", types.ContainerInstanceStatusDraining).
+			Return(errors.New("throttled")),
+	)
+
+	action := NewDrainInstanceAction(client, "instance-arn")
+	assert.Equal(t, DrainInstanceActionName, action.Name())
+	assert.NoError(t, action.Run(context.Background()))
+	assert.EqualError(t, action.Run(context.Background()), "throttled")
+}
@@ -0,0 +1,291 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package remediation takes remediation actions, such as restarting docker, when the doctor healthchecks
+// keep failing.
+package remediation
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+
+	"github.com/pkg/errors"
+)
+
+// Outcomes of the remediation actions recorded in the audit trail
+const (
+	OutcomeSucceeded          = "succeeded"
+	OutcomeFailed             = "failed"
+	OutcomeDryRun             = "dry-run"
+	OutcomeSkippedCooldown    = "skipped-cooldown"
+	OutcomeSkippedRateLimited = "skipped-rate-limited"
+
+	auditFileMode = 0644
+	rateLimitSpan = time.Hour
+)
+
+// Action is a remediation action taken when a healthcheck keeps failing.
+type Action interface {
+	// Name returns the name of the action, as used in the remediation policy
+	Name() string
+	// Run takes the action
+	Run(ctx context.Context) error
+}
+
+// StateStore persists the guard rail state of the remediator. Restarting docker restarts the agent, so
+// the state has to outlive the agent for the guard rails to prevent restart loops. The agent data client
+// is a StateStore.
+type StateStore interface {
+	SaveMetadata(key, val string) error
+	GetAllMetadata() (map[string]string, error)
+}
+
+// guardRails is the persisted state of the guard rails
+type guardRails struct {
+	LastTaken map[string]time.Time `json:"lastTaken,omitempty"`
+	Taken     []time.Time          `json:"taken,omitempty"`
+}
+
+// AuditRecord is an entry of the audit trail of the remediation actions. Records are written as JSON lines.
+type AuditRecord struct {
+	Time        time.Time `json:"time"`
+	Healthcheck string    `json:"healthcheck"`
+	Action      string    `json:"action"`
+	Outcome     string    `json:"outcome"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// Remediator takes the remediation actions of the healthchecks which failed for a number of consecutive
+// runs of the doctor, within the guard rails of the remediation policy: an action isn't taken again during
+// its cooldown, and only a maximum number of actions is taken in any hour. The guard rails are saved to the
+// state store before an action is taken, and restored when the remediator is created. Dry runs are checked
+// against the guard rails but don't use them up.
+type Remediator struct {
+	ctx     context.Context
+	policy  config.RemediationPolicy
+	actions map[string]Action
+	audit   io.Writer
+	store   StateStore
+	now     func() time.Time // function that returns current time (injected for testing)
+
+	lock      sync.Mutex
+	failures  map[string]int       // consecutive failures by healthcheck type
+	lastTaken map[string]time.Time // time an action was last taken, by action name
+	taken     []time.Time          // times of the actions taken in the last hour
+
+	// runLock makes sure that actions are taken one at a time
+	runLock   sync.Mutex
+	auditLock sync.Mutex
+	running   sync.WaitGroup
+}
+
+// NewRemediator creates a remediator for a policy. Every action of the policy must be one of the given actions.
+func NewRemediator(ctx context.Context, policy *config.RemediationPolicy, audit io.Writer, store StateStore,
+	actions []Action) (*Remediator, error) {
+	actionsByName := make(map[string]Action)
+	for _, action := range actions {
+		actionsByName[action.Name()] = action
+	}
+	for healthcheckType, names := range policy.Actions {
+		for _, name := range names {
+			if _, ok := actionsByName[name]; !ok {
+				return nil, errors.Errorf("unknown remediation action %q for healthcheck %s", name, healthcheckType)
+			}
+		}
+	}
+	r := &Remediator{
+		ctx:       ctx,
+		policy:    *policy,
+		actions:   actionsByName,
+		audit:     audit,
+		store:     store,
+		now:       time.Now,
+		failures:  make(map[string]int),
+		lastTaken: make(map[string]time.Time),
+	}
+	if err := r.loadGuardRails(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// loadGuardRails restores the guard rails saved by a previous run of the agent.
+func (r *Remediator) loadGuardRails() error {
+	metadata, err := r.store.GetAllMetadata()
+	if err != nil {
+		return errors.Wrap(err, "unable to load the remediation guard rails")
+	}
+	val, ok := metadata[data.RemediationStateKey]
+	if !ok {
+		return nil
+	}
+	var state guardRails
+	if err := json.Unmarshal([]byte(val), &state); err != nil {
+		return errors.Wrap(err, "unable to decode the remediation guard rails")
+	}
+	for name, t := range state.LastTaken {
+		r.lastTaken[name] = t
+	}
+	r.taken = state.Taken
+	return nil
+}
+
+// saveGuardRails saves the guard rails. It must be called with the lock held.
+func (r *Remediator) saveGuardRails() error {
+	val, err := json.Marshal(guardRails{LastTaken: r.lastTaken, Taken: r.taken})
+	if err != nil {
+		return err
+	}
+	return r.store.SaveMetadata(data.RemediationStateKey, string(val))
+}
+
+// NewAuditFile returns a writer that appends the audit trail to a file. The file is reopened for every
+// record, so that it can be rotated.
+func NewAuditFile(path string) io.Writer {
+	return auditFile(path)
+}
+
+type auditFile string
+
+func (f auditFile) Write(p []byte) (int, error) {
+	file, err := os.OpenFile(string(f), os.O_APPEND|os.O_CREATE|os.O_WRONLY, auditFileMode)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return file.Write(p)
+}
+
+// HandleResults is a doctor.HealthcheckResultsHandler. It counts the consecutive failures of the
+// healthchecks and takes the actions of the ones which reached the failure threshold. The actions
+// are taken in the background so that the doctor isn't blocked.
+func (r *Remediator) HandleResults(results []doctor.HealthcheckResult) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, result := range results {
+		names, ok := r.policy.Actions[result.Type]
+		if !ok {
+			continue
+		}
+		if result.Status != doctor.HealthcheckStatusImpaired {
+			r.failures[result.Type] = 0
+			continue
+		}
+		r.failures[result.Type]++
+		if r.failures[result.Type] < r.policy.FailureThreshold {
+			continue
+		}
+		// the healthcheck has to reach the threshold again before its actions are considered again
+		r.failures[result.Type] = 0
+		for _, name := range names {
+			r.takeAction(result.Type, r.actions[name])
+		}
+	}
+}
+
+// takeAction takes an action unless a guard rail prevents it. It must be called with the lock held.
+func (r *Remediator) takeAction(healthcheckType string, action Action) {
+	now := r.now()
+	if lastTaken, ok := r.lastTaken[action.Name()]; ok && now.Sub(lastTaken) < r.policy.Cooldown {
+		r.record(healthcheckType, action.Name(), OutcomeSkippedCooldown, nil)
+		return
+	}
+	taken := r.taken[:0]
+	for _, t := range r.taken {
+		if now.Sub(t) < rateLimitSpan {
+			taken = append(taken, t)
+		}
+	}
+	r.taken = taken
+	if len(r.taken) >= r.policy.MaxActionsPerHour {
+		r.record(healthcheckType, action.Name(), OutcomeSkippedRateLimited, nil)
+		return
+	}
+	// A dry run doesn't use up the guard rails, so that it records every action the policy would take
+	if r.policy.DryRun {
+		r.record(healthcheckType, action.Name(), OutcomeDryRun, nil)
+		return
+	}
+	lastTaken, hadLastTaken := r.lastTaken[action.Name()]
+	r.lastTaken[action.Name()] = now
+	r.taken = append(r.taken, now)
+	// An action which restarts the agent is only safe to take once the guard rails outlive it
+	if err := r.saveGuardRails(); err != nil {
+		if hadLastTaken {
+			r.lastTaken[action.Name()] = lastTaken
+		} else {
+			delete(r.lastTaken, action.Name())
+		}
+		r.taken = r.taken[:len(r.taken)-1]
+		r.record(healthcheckType, action.Name(), OutcomeFailed,
+			errors.Wrap(err, "unable to save the remediation guard rails"))
+		return
+	}
+
+	r.running.Add(1)
+	go func() {
+		defer r.running.Done()
+		r.runLock.Lock()
+		defer r.runLock.Unlock()
+		logger.Warn("Taking remediation action", logger.Fields{
+			"healthcheck": healthcheckType,
+			"action":      action.Name(),
+		})
+		if err := action.Run(r.ctx); err != nil {
+			r.record(healthcheckType, action.Name(), OutcomeFailed, err)
+			return
+		}
+		r.record(healthcheckType, action.Name(), OutcomeSucceeded, nil)
+	}()
+}
+
+// record logs an action and appends it to the audit trail.
+func (r *Remediator) record(healthcheckType, action, outcome string, actionErr error) {
+	record := AuditRecord{
+		Time:        r.now(),
+		Healthcheck: healthcheckType,
+		Action:      action,
+		Outcome:     outcome,
+	}
+	fields := logger.Fields{
+		"healthcheck": healthcheckType,
+		"action":      action,
+		"outcome":     outcome,
+	}
+	if actionErr != nil {
+		record.Error = actionErr.Error()
+		fields[field.Error] = actionErr
+	}
+	logger.Info("Remediation action", fields)
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		logger.Error("Unable to encode remediation audit record", logger.Fields{field.Error: err})
+		return
+	}
+	r.auditLock.Lock()
+	defer r.auditLock.Unlock()
+	if _, err := r.audit.Write(append(data, '\n')); err != nil {
+		logger.Error("Unable to write remediation audit record", logger.Fields{field.Error: err})
+	}
+}
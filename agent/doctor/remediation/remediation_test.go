@@ -0,0 +1,293 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package remediation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAction struct {
+	name string
+	err  error
+	runs int
+	lock sync.Mutex
+}
+
+func (a *fakeAction) Name() string {
+	return a.name
+}
+
+func (a *fakeAction) Run(context.Context) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.runs++
+	return a.err
+}
+
+func (a *fakeAction) getRuns() int {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.runs
+}
+
+type fakeStateStore struct {
+	metadata map[string]string
+	err      error
+}
+
+func newFakeStateStore() *fakeStateStore {
+	return &fakeStateStore{metadata: make(map[string]string)}
+}
+
+func (s *fakeStateStore) SaveMetadata(key, val string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.metadata[key] = val
+	return nil
+}
+
+func (s *fakeStateStore) GetAllMetadata() (map[string]string, error) {
+	return s.metadata, s.err
+}
+
+func impaired(healthcheckType string) []doctor.HealthcheckResult {
+	return []doctor.HealthcheckResult{{Type: healthcheckType, Status: doctor.HealthcheckStatusImpaired}}
+}
+
+func ok(healthcheckType string) []doctor.HealthcheckResult {
+	return []doctor.HealthcheckResult{{Type: healthcheckType, Status: doctor.HealthcheckStatusOk}}
+}
+
+// newTestRemediator creates a remediator whose clock is advanced by a minute on every run of the doctor
+func newTestRemediator(t *testing.T, policy *config.RemediationPolicy, audit *bytes.Buffer,
+	actions ...Action) (*Remediator, func([]doctor.HealthcheckResult)) {
+	remediator, err := NewRemediator(context.Background(), policy, audit, newFakeStateStore(), actions)
+	require.NoError(t, err)
+	now := time.Unix(0, 0)
+	remediator.now = func() time.Time { return now }
+	return remediator, func(results []doctor.HealthcheckResult) {
+		now = now.Add(time.Minute)
+		remediator.HandleResults(results)
+		remediator.running.Wait()
+	}
+}
+
+func auditOutcomes(t *testing.T, audit *bytes.Buffer) []string {
+	var outcomes []string
+	for _, line := range strings.Split(strings.TrimSpace(audit.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record AuditRecord
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		outcomes = append(outcomes, record.Healthcheck+":"+record.Action+":"+record.Outcome)
+	}
+	return outcomes
+}
+
+func TestNewRemediatorUnknownAction(t *testing.T) {
+	_, err := NewRemediator(context.Background(), &config.RemediationPolicy{
+		Actions: map[string][]string{doctor.HealthcheckTypeContainerRuntime: {"reboot"}},
+	}, &bytes.Buffer{}, newFakeStateStore(), []Action{&fakeAction{name: RestartDockerActionName}})
+	assert.EqualError(t, err, `unknown remediation action "reboot" for healthcheck ContainerRuntime`)
+}
+
+func TestRemediatorFailureThreshold(t *testing.T) {
+	restart := &fakeAction{name: RestartDockerActionName}
+	audit := &bytes.Buffer{}
+	_, run := newTestRemediator(t, &config.RemediationPolicy{
+		Actions:           map[string][]string{doctor.HealthcheckTypeContainerRuntime: {RestartDockerActionName}},
+		FailureThreshold:  3,
+		MaxActionsPerHour: 10,
+	}, audit, restart)
+
+	// the failures have to be consecutive
+	run(impaired(doctor.HealthcheckTypeContainerRuntime))
+	run(impaired(doctor.HealthcheckTypeContainerRuntime))
+	run(ok(doctor.HealthcheckTypeContainerRuntime))
+	run(impaired(doctor.HealthcheckTypeContainerRuntime))
+	run(impaired(doctor.HealthcheckTypeContainerRuntime))
+	assert.Equal(t, 0, restart.getRuns())
+
+	run(impaired(doctor.HealthcheckTypeContainerRuntime))
+	assert.Equal(t, 1, restart.getRuns())
+
+	// healthchecks without actions are ignored
+	for i := 0; i < 5; i++ {
+		run(impaired(doctor.HealthcheckTypeDiskSpace))
+	}
+	assert.Equal(t, 1, restart.getRuns())
+	assert.Equal(t, []string{"ContainerRuntime:restart-docker:succeeded"}, auditOutcomes(t, audit))
+}
+
+func TestRemediatorGuardRails(t *testing.T) {
+	restart := &fakeAction{name: RestartDockerActionName}
+	clearCache := &fakeAction{name: ClearImageCacheActionName, err: errors.New("docker is down")}
+	audit := &bytes.Buffer{}
+	_, run := newTestRemediator(t, &config.RemediationPolicy{
+		Actions: map[string][]string{
+			doctor.HealthcheckTypeContainerRuntime: {RestartDockerActionName},
+			doctor.HealthcheckTypeDiskSpace:        {ClearImageCacheActionName},
+		},
+		FailureThreshold:  1,
+		Cooldown:          10 * time.Minute,
+		MaxActionsPerHour: 2,
+	}, audit, restart, clearCache)
+
+	// minute 1: restart, minute 2: in cooldown, minute 3: clear the cache, which fails
+	run(impaired(doctor.HealthcheckTypeContainerRuntime))
+	run(impaired(doctor.HealthcheckTypeContainerRuntime))
+	run(impaired(doctor.HealthcheckTypeDiskSpace))
+	// minute 12: out of cooldown, but 2 actions were already taken in the last hour
+	for i := 0; i < 9; i++ {
+		run(ok(doctor.HealthcheckTypeContainerRuntime))
+	}
+	run(impaired(doctor.HealthcheckTypeContainerRuntime))
+	// minute 62: an hour after the first action
+	for i := 0; i < 49; i++ {
+		run(ok(doctor.HealthcheckTypeContainerRuntime))
+	}
+	run(impaired(doctor.HealthcheckTypeContainerRuntime))
+
+	assert.Equal(t, 2, restart.getRuns())
+	assert.Equal(t, 1, clearCache.getRuns())
+	assert.Equal(t, []string{
+		"ContainerRuntime:restart-docker:succeeded",
+		"ContainerRuntime:restart-docker:skipped-cooldown",
+		"DiskSpace:clear-image-cache:failed",
+		"ContainerRuntime:restart-docker:skipped-rate-limited",
+		"ContainerRuntime:restart-docker:succeeded",
+	}, auditOutcomes(t, audit))
+}
+
+func TestRemediatorGuardRailsOutliveTheAgent(t *testing.T) {
+	policy := &config.RemediationPolicy{
+		Actions:           map[string][]string{doctor.HealthcheckTypeContainerRuntime: {RestartDockerActionName}},
+		FailureThreshold:  1,
+		Cooldown:          10 * time.Minute,
+		MaxActionsPerHour: 10,
+	}
+	store := newFakeStateStore()
+	audit := &bytes.Buffer{}
+	now := time.Unix(0, 0)
+
+	restart := &fakeAction{name: RestartDockerActionName}
+	remediator, err := NewRemediator(context.Background(), policy, audit, store, []Action{restart})
+	require.NoError(t, err)
+	remediator.now = func() time.Time { return now }
+	remediator.HandleResults(impaired(doctor.HealthcheckTypeContainerRuntime))
+	remediator.running.Wait()
+	assert.Contains(t, store.metadata, data.RemediationStateKey)
+
+	// the restart of docker restarts the agent, which is still in cooldown when it's back
+	now = now.Add(time.Minute)
+	restart = &fakeAction{name: RestartDockerActionName}
+	remediator, err = NewRemediator(context.Background(), policy, audit, store, []Action{restart})
+	require.NoError(t, err)
+	remediator.now = func() time.Time { return now }
+	remediator.HandleResults(impaired(doctor.HealthcheckTypeContainerRuntime))
+	remediator.running.Wait()
+
+	assert.Equal(t, 0, restart.getRuns())
+	assert.Equal(t, []string{
+		"ContainerRuntime:restart-docker:succeeded",
+		"ContainerRuntime:restart-docker:skipped-cooldown",
+	}, auditOutcomes(t, audit))
+}
+
+func TestRemediatorGuardRailsNotSaved(t *testing.T) {
+	restart := &fakeAction{name: RestartDockerActionName}
+	store := newFakeStateStore()
+	audit := &bytes.Buffer{}
+	remediator, err := NewRemediator(context.Background(), &config.RemediationPolicy{
+		Actions:           map[string][]string{doctor.HealthcheckTypeContainerRuntime: {RestartDockerActionName}},
+		FailureThreshold:  1,
+		MaxActionsPerHour: 1,
+	}, audit, store, []Action{restart})
+	require.NoError(t, err)
+
+	// the action isn't taken when the guard rails can't be saved, and isn't counted against them
+	store.err = errors.New("disk full")
+	remediator.HandleResults(impaired(doctor.HealthcheckTypeContainerRuntime))
+	store.err = nil
+	remediator.HandleResults(impaired(doctor.HealthcheckTypeContainerRuntime))
+	remediator.running.Wait()
+
+	assert.Equal(t, 1, restart.getRuns())
+	assert.Equal(t, []string{
+		"ContainerRuntime:restart-docker:failed",
+		"ContainerRuntime:restart-docker:succeeded",
+	}, auditOutcomes(t, audit))
+}
+
+func TestNewRemediatorInvalidGuardRails(t *testing.T) {
+	store := newFakeStateStore()
+	store.metadata[data.RemediationStateKey] = "{"
+	_, err := NewRemediator(context.Background(), &config.RemediationPolicy{}, &bytes.Buffer{}, store, nil)
+	assert.Error(t, err)
+}
+
+func TestRemediatorDryRun(t *testing.T) {
+	drain := &fakeAction{name: DrainInstanceActionName}
+	audit := &bytes.Buffer{}
+	remediator, run := newTestRemediator(t, &config.RemediationPolicy{
+		Actions:           map[string][]string{doctor.HealthcheckTypeACSConnection: {DrainInstanceActionName}},
+		FailureThreshold:  1,
+		MaxActionsPerHour: 1,
+		DryRun:            true,
+	}, audit, drain)
+
+	run(impaired(doctor.HealthcheckTypeACSConnection))
+	run(impaired(doctor.HealthcheckTypeACSConnection))
+
+	assert.Equal(t, 0, drain.getRuns())
+	// the dry run doesn't use up the guard rails
+	assert.Equal(t, []string{
+		"ACSConnection:drain-instance:dry-run",
+		"ACSConnection:drain-instance:dry-run",
+	}, auditOutcomes(t, audit))
+	assert.Empty(t, remediator.store.(*fakeStateStore).metadata)
+}
+
+func TestAuditFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "remediation-audit.log")
+	audit := NewAuditFile(path)
+
+	_, err := audit.Write([]byte("first\n"))
+	require.NoError(t, err)
+	_, err = audit.Write([]byte("second\n"))
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", string(data))
+}
@@ -0,0 +1,64 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package remediation
+
+import (
+	"context"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/pkg/errors"
+)
+
+const (
+	dockerUnitName = "docker.service"
+	// jobDone is the result of a systemd job which completed successfully
+	jobDone = "done"
+)
+
+type restartDockerAction struct{}
+
+// NewRestartDockerAction creates an action which restarts docker through the systemd D-Bus API. The
+// agent container is stopped along with docker, and started again by ecs-init.
+func NewRestartDockerAction() Action {
+	return &restartDockerAction{}
+}
+
+func (a *restartDockerAction) Name() string {
+	return RestartDockerActionName
+}
+
+func (a *restartDockerAction) Run(ctx context.Context) error {
+	conn, err := dbus.NewSystemConnectionContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "unable to connect to systemd")
+	}
+	defer conn.Close()
+
+	result := make(chan string, 1)
+	if _, err := conn.RestartUnitContext(ctx, dockerUnitName, "replace", result); err != nil {
+		return errors.Wrapf(err, "unable to restart %s", dockerUnitName)
+	}
+	select {
+	case res := <-result:
+		if res != jobDone {
+			return errors.Errorf("restart of %s finished with result %q", dockerUnitName, res)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,38 @@
+//go:build !linux
+// +build !linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package remediation
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+type restartDockerAction struct{}
+
+// NewRestartDockerAction creates an action which fails, as docker is only restarted through systemd on Linux
+func NewRestartDockerAction() Action {
+	return &restartDockerAction{}
+}
+
+func (a *restartDockerAction) Name() string {
+	return RestartDockerActionName
+}
+
+func (a *restartDockerAction) Run(context.Context) error {
+	return errors.New("restarting docker is not supported on this platform")
+}
@@ -0,0 +1,95 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package doctor
+
+import (
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/doctor/statustracker"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+)
+
+const (
+	// Default maximum time since the last connection of a session to ACS or TCS. Sessions are
+	// disconnected and reconnected every 30 to 35 minutes, so a session which hasn't connected
+	// for an hour is failing to reconnect.
+	DefaultMaxSessionConnectionAge = time.Hour
+)
+
+// ConnectedSession is a long-lived connection to an ECS backend service, such as ACS or TCS.
+type ConnectedSession interface {
+	// GetLastConnectedTime returns the timestamp that the last connection was established,
+	// or the zero time if it has never connected.
+	GetLastConnectedTime() time.Time
+}
+
+// Health check for the freshness of the connection of a session to an ECS backend service.
+type sessionHealthcheck struct {
+	healthcheckType  string
+	session          ConnectedSession
+	maxConnectionAge time.Duration
+	startTime        time.Time
+	now              func() time.Time // function that returns current time (injected for testing)
+	*statustracker.HealthCheckStatusTracker
+}
+
+// Constructor for ACS Session Health Check
+func NewACSSessionHealthcheck(session ConnectedSession, maxConnectionAge time.Duration) doctor.Healthcheck {
+	return newSessionHealthcheck(doctor.HealthcheckTypeACSConnection, session, maxConnectionAge, time.Now)
+}
+
+// Constructor for TCS Session Health Check
+func NewTCSSessionHealthcheck(session ConnectedSession, maxConnectionAge time.Duration) doctor.Healthcheck {
+	return newSessionHealthcheck(doctor.HealthcheckTypeTCSConnection, session, maxConnectionAge, time.Now)
+}
+
+func newSessionHealthcheck(
+	healthcheckType string,
+	session ConnectedSession,
+	maxConnectionAge time.Duration,
+	timeNow func() time.Time,
+) *sessionHealthcheck {
+	return &sessionHealthcheck{
+		healthcheckType:          healthcheckType,
+		session:                  session,
+		maxConnectionAge:         maxConnectionAge,
+		startTime:                timeNow(),
+		now:                      timeNow,
+		HealthCheckStatusTracker: statustracker.NewHealthCheckStatusTracker(),
+	}
+}
+
+// Performs a health check of the session by checking that it has connected recently. A session
+// which hasn't connected yet is given the maximum connection age to connect for the first time.
+func (s *sessionHealthcheck) RunCheck() doctor.HealthcheckStatus {
+	lastConnectedTime := s.session.GetLastConnectedTime()
+	if lastConnectedTime.IsZero() {
+		lastConnectedTime = s.startTime
+	}
+	if age := s.now().Sub(lastConnectedTime); age > s.maxConnectionAge {
+		logger.Warn("Session has not connected recently", logger.Fields{
+			"healthcheckType":   s.healthcheckType,
+			"lastConnectedTime": s.session.GetLastConnectedTime(),
+			"maxConnectionAge":  s.maxConnectionAge,
+		})
+		s.SetHealthcheckStatus(doctor.HealthcheckStatusImpaired)
+		return s.GetHealthcheckStatus()
+	}
+	s.SetHealthcheckStatus(doctor.HealthcheckStatusOk)
+	return s.GetHealthcheckStatus()
+}
+
+func (s *sessionHealthcheck) GetHealthcheckType() string {
+	return s.healthcheckType
+}
@@ -0,0 +1,88 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package doctor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSession struct {
+	lastConnectedTime time.Time
+}
+
+func (s *fakeSession) GetLastConnectedTime() time.Time {
+	return s.lastConnectedTime
+}
+
+// Tests that Session Health Checks are of the right health check type
+func TestSessionGetHealthcheckType(t *testing.T) {
+	acs := NewACSSessionHealthcheck(&fakeSession{}, DefaultMaxSessionConnectionAge)
+	tcs := NewTCSSessionHealthcheck(&fakeSession{}, DefaultMaxSessionConnectionAge)
+
+	assert.Equal(t, doctor.HealthcheckTypeACSConnection, acs.GetHealthcheckType())
+	assert.Equal(t, doctor.HealthcheckTypeTCSConnection, tcs.GetHealthcheckType())
+	assert.Equal(t, doctor.HealthcheckStatusInitializing, acs.GetHealthcheckStatus())
+}
+
+// Tests RunCheck method of Session Health Check
+func TestSessionRunHealthCheck(t *testing.T) {
+	startTime := time.Unix(1000, 0)
+	tcs := []struct {
+		name              string
+		lastConnectedTime time.Time
+		now               time.Time
+		expectedStatus    doctor.HealthcheckStatus
+	}{
+		{
+			name:              "OK when connected recently",
+			lastConnectedTime: startTime.Add(time.Hour),
+			now:               startTime.Add(time.Hour + 30*time.Minute),
+			expectedStatus:    doctor.HealthcheckStatusOk,
+		},
+		{
+			name:              "IMPAIRED when not connected recently",
+			lastConnectedTime: startTime.Add(time.Hour),
+			now:               startTime.Add(2*time.Hour + time.Second),
+			expectedStatus:    doctor.HealthcheckStatusImpaired,
+		},
+		{
+			name:           "OK when not connected yet since a short time",
+			now:            startTime.Add(time.Minute),
+			expectedStatus: doctor.HealthcheckStatusOk,
+		},
+		{
+			name:           "IMPAIRED when never connected",
+			now:            startTime.Add(time.Hour + time.Second),
+			expectedStatus: doctor.HealthcheckStatusImpaired,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			now := startTime
+			session := &fakeSession{lastConnectedTime: tc.lastConnectedTime}
+			hc := newSessionHealthcheck(doctor.HealthcheckTypeACSConnection, session, time.Hour,
+				func() time.Time { return now })
+			now = tc.now
+
+			assert.Equal(t, tc.expectedStatus, hc.RunCheck())
+		})
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package drain drains the container instance on configurable conditions, such as scheduled maintenance
+// events or the shutdown of the host, and waits for its tasks to stop.
+package drain
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/pkg/errors"
+)
+
+// Names of the drain triggers, as used in the drain policy
+const (
+	TriggerMaintenanceEvent = "maintenance-event"
+	TriggerASGTermination   = "asg-termination"
+	TriggerHealthcheck      = "healthcheck"
+	TriggerDrainFile        = "drain-file"
+	TriggerDrainEndpoint    = "drain-endpoint"
+	TriggerShutdown         = "shutdown"
+
+	triggerPollInterval  = 5 * time.Second
+	taskStopPollInterval = 5 * time.Second
+	shutdownCheckTimeout = 5 * time.Second
+	// shutdownTaskStopTimeout caps the wait for the tasks to stop on shutdown. ecs-init gives the agent 80
+	// seconds to stop when there's a drain policy, which also has to cover the shutdown check and the final
+	// save of the state.
+	shutdownTaskStopTimeout = time.Minute
+)
+
+// Trigger is a condition on which the container instance is drained.
+type Trigger interface {
+	// Name returns the name of the trigger, as used in the drain policy
+	Name() string
+	// Check returns whether the container instance should be drained, and why
+	Check() (bool, string)
+}
+
+// Drainer sets the container instance to DRAINING when one of the triggers of the drain policy fires, and
+// waits, for a bounded time, for the tasks of the instance to stop.
+type Drainer struct {
+	client               ecs.ECSClient
+	containerInstanceARN string
+	state                dockerstate.TaskEngineState
+	triggers             []Trigger
+	onShutdown           bool
+	taskStopTimeout      time.Duration
+	triggerPollInterval  time.Duration
+	taskStopPollInterval time.Duration
+	// shutdownTaskStopTimeout caps taskStopTimeout on shutdown
+	shutdownTaskStopTimeout time.Duration
+	// hostShuttingDown returns whether the host is shutting down (injected for testing)
+	hostShuttingDown func(ctx context.Context) (bool, error)
+
+	lock     sync.Mutex
+	draining bool
+}
+
+// NewDrainer creates a drainer for a policy. Every trigger of the policy, other than the shutdown trigger,
+// must be one of the given triggers.
+func NewDrainer(policy *config.DrainPolicy, triggers []Trigger, client ecs.ECSClient,
+	containerInstanceARN string, state dockerstate.TaskEngineState) (*Drainer, error) {
+	triggersByName := make(map[string]Trigger)
+	for _, trigger := range triggers {
+		triggersByName[trigger.Name()] = trigger
+	}
+	drainer := &Drainer{
+		client:               client,
+		containerInstanceARN: containerInstanceARN,
+		state:                state,
+		taskStopTimeout:      policy.TaskStopTimeout,
+		triggerPollInterval:  triggerPollInterval,
+		taskStopPollInterval: taskStopPollInterval,
+		hostShuttingDown:     hostShuttingDown,
+
+		shutdownTaskStopTimeout: shutdownTaskStopTimeout,
+	}
+	for _, name := range policy.Triggers {
+		// The shutdown trigger isn't polled, it's checked when the agent receives a termination signal
+		if name == TriggerShutdown {
+			drainer.onShutdown = true
+			continue
+		}
+		trigger, ok := triggersByName[name]
+		if !ok {
+			return nil, errors.Errorf("unknown drain trigger %q", name)
+		}
+		drainer.triggers = append(drainer.triggers, trigger)
+	}
+	return drainer, nil
+}
+
+// Start polls the triggers until one of them fires and the instance is drained, then waits for the tasks
+// to stop. It returns when ctx is cancelled.
+func (d *Drainer) Start(ctx context.Context) {
+	if len(d.triggers) == 0 {
+		return
+	}
+	ticker := time.NewTicker(d.triggerPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if d.checkTriggers() {
+			d.WaitForTasksToStop(ctx)
+			return
+		}
+	}
+}
+
+// checkTriggers drains the instance when one of the triggers fires, and returns whether it's draining.
+func (d *Drainer) checkTriggers() bool {
+	for _, trigger := range d.triggers {
+		if fired, reason := trigger.Check(); fired {
+			return d.Drain(trigger.Name(), reason) == nil
+		}
+	}
+	return false
+}
+
+// Drain sets the container instance to DRAINING. It does nothing once the instance has been drained.
+func (d *Drainer) Drain(trigger, reason string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.draining {
+		return nil
+	}
+	logger.Info("Draining container instance", logger.Fields{
+		field.ContainerInstanceARN: d.containerInstanceARN,
+		"trigger":                  trigger,
+		"reason":                   reason,
+	})
+	err := d.client.UpdateContainerInstancesState(d.containerInstanceARN, types.ContainerInstanceStatusDraining)
+	if err != nil {
+		logger.Error("Unable to set the container instance state to DRAINING", logger.Fields{
+			field.ContainerInstanceARN: d.containerInstanceARN,
+			field.Error:                err,
+		})
+		return err
+	}
+	d.draining = true
+	return nil
+}
+
+// WaitForTasksToStop waits until the tasks of the instance are stopped, for at most the task stop timeout
+// of the policy. It returns whether they stopped.
+func (d *Drainer) WaitForTasksToStop(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, d.taskStopTimeout)
+	defer cancel()
+	ticker := time.NewTicker(d.taskStopPollInterval)
+	defer ticker.Stop()
+	for {
+		running := d.runningTasks()
+		if running == 0 {
+			logger.Info("All the tasks of the draining container instance are stopped")
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			logger.Warn("Stopped waiting for the tasks of the draining container instance to stop", logger.Fields{
+				"runningTasks": running,
+				field.Error:    ctx.Err(),
+			})
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// runningTasks returns the number of tasks of the instance which aren't stopped. Internal tasks, such as
+// managed daemons, aren't stopped by draining and aren't counted.
+func (d *Drainer) runningTasks() int {
+	running := 0
+	for _, task := range d.state.AllTasks() {
+		if !task.IsInternal && task.GetKnownStatus() < apitaskstatus.TaskStopped {
+			running++
+		}
+	}
+	return running
+}
+
+// DrainOnShutdown drains the instance and waits for its tasks to stop when the policy has the shutdown
+// trigger and the host is shutting down. It's called when the agent receives a termination signal, so
+// that the tasks are stopped before the agent exits. The wait is capped so that the agent exits before
+// ecs-init kills it.
+func (d *Drainer) DrainOnShutdown() {
+	if !d.onShutdown {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownCheckTimeout)
+	shuttingDown, err := d.hostShuttingDown(ctx)
+	cancel()
+	if err != nil {
+		logger.Warn("Unable to determine whether the host is shutting down, not draining", logger.Fields{
+			field.Error: err,
+		})
+		return
+	}
+	if !shuttingDown {
+		return
+	}
+	if err := d.Drain(TriggerShutdown, "host is shutting down"); err != nil {
+		return
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), d.shutdownTaskStopTimeout)
+	defer cancel()
+	d.WaitForTasksToStop(ctx)
+}
@@ -0,0 +1,199 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package drain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	mock_ecs "github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs/mocks"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testContainerInstanceARN = "arn:aws:ecs:us-west-2:123456789012:container-instance/cluster/instance"
+
+type fakeTrigger struct {
+	name  string
+	fired bool
+}
+
+func (t *fakeTrigger) Name() string {
+	return t.name
+}
+
+func (t *fakeTrigger) Check() (bool, string) {
+	return t.fired, "test"
+}
+
+func newTestDrainer(t *testing.T, client *mock_ecs.MockECSClient, state dockerstate.TaskEngineState,
+	triggerNames ...string) *Drainer {
+	policy := &config.DrainPolicy{
+		Triggers:        triggerNames,
+		TaskStopTimeout: 50 * time.Millisecond,
+	}
+	drainer, err := NewDrainer(policy, []Trigger{&fakeTrigger{name: TriggerDrainFile, fired: true},
+		&fakeTrigger{name: TriggerMaintenanceEvent}}, client, testContainerInstanceARN, state)
+	require.NoError(t, err)
+	drainer.triggerPollInterval = time.Millisecond
+	drainer.taskStopPollInterval = time.Millisecond
+	return drainer
+}
+
+func TestNewDrainerUnknownTrigger(t *testing.T) {
+	_, err := NewDrainer(&config.DrainPolicy{Triggers: []string{TriggerShutdown, "reboot"}}, nil, nil, "", nil)
+	assert.Error(t, err)
+}
+
+func TestDrainerStartDrainsWhenTriggerFires(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_ecs.NewMockECSClient(ctrl)
+	gomock.InOrder(
+		client.EXPECT().UpdateContainerInstancesState(testContainerInstanceARN, types.ContainerInstanceStatusDraining).
+			Return(errors.New("throttled")),
+		client.EXPECT().UpdateContainerInstancesState(testContainerInstanceARN, types.ContainerInstanceStatusDraining).
+			Return(nil),
+	)
+	drainer := newTestDrainer(t, client, dockerstate.NewTaskEngineState(), TriggerMaintenanceEvent, TriggerDrainFile)
+
+	done := make(chan struct{})
+	go func() {
+		drainer.Start(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the instance to be drained")
+	}
+
+	// the instance is only drained once
+	assert.NoError(t, drainer.Drain(TriggerShutdown, "test"))
+}
+
+func TestDrainerStartWithoutTrigger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	drainer := newTestDrainer(t, mock_ecs.NewMockECSClient(ctrl), dockerstate.NewTaskEngineState(),
+		TriggerMaintenanceEvent)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	drainer.Start(ctx)
+}
+
+func TestWaitForTasksToStop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	state := dockerstate.NewTaskEngineState()
+	task := &apitask.Task{Arn: "task"}
+	task.SetKnownStatus(apitaskstatus.TaskRunning)
+	state.AddTask(task)
+	daemonTask := &apitask.Task{Arn: "daemon", IsInternal: true}
+	daemonTask.SetKnownStatus(apitaskstatus.TaskRunning)
+	state.AddTask(daemonTask)
+	drainer := newTestDrainer(t, mock_ecs.NewMockECSClient(ctrl), state, TriggerDrainFile)
+
+	assert.False(t, drainer.WaitForTasksToStop(context.Background()))
+
+	task.SetKnownStatus(apitaskstatus.TaskStopped)
+	assert.True(t, drainer.WaitForTasksToStop(context.Background()))
+}
+
+func TestDrainOnShutdown(t *testing.T) {
+	testCases := []struct {
+		name         string
+		triggers     []string
+		shuttingDown bool
+		err          error
+		drained      bool
+	}{
+		{
+			name:         "no shutdown trigger",
+			triggers:     []string{TriggerDrainFile},
+			shuttingDown: true,
+		},
+		{
+			name:     "host not shutting down",
+			triggers: []string{TriggerShutdown},
+		},
+		{
+			name:     "unknown host state",
+			triggers: []string{TriggerShutdown},
+			err:      errors.New("no systemd"),
+		},
+		{
+			name:         "host shutting down",
+			triggers:     []string{TriggerShutdown},
+			shuttingDown: true,
+			drained:      true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			client := mock_ecs.NewMockECSClient(ctrl)
+			if tc.drained {
+				client.EXPECT().UpdateContainerInstancesState(testContainerInstanceARN,
+					types.ContainerInstanceStatusDraining).Return(nil)
+			}
+			drainer := newTestDrainer(t, client, dockerstate.NewTaskEngineState(), tc.triggers...)
+			drainer.hostShuttingDown = func(ctx context.Context) (bool, error) {
+				return tc.shuttingDown, tc.err
+			}
+			drainer.DrainOnShutdown()
+		})
+	}
+}
+
+func TestDrainOnShutdownWaitIsCapped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_ecs.NewMockECSClient(ctrl)
+	client.EXPECT().UpdateContainerInstancesState(testContainerInstanceARN,
+		types.ContainerInstanceStatusDraining).Return(nil)
+	state := dockerstate.NewTaskEngineState()
+	task := &apitask.Task{Arn: "task"}
+	task.SetKnownStatus(apitaskstatus.TaskRunning)
+	state.AddTask(task)
+	drainer := newTestDrainer(t, client, state, TriggerShutdown)
+	drainer.taskStopTimeout = time.Hour
+	drainer.shutdownTaskStopTimeout = 20 * time.Millisecond
+	drainer.hostShuttingDown = func(ctx context.Context) (bool, error) {
+		return true, nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		drainer.DrainOnShutdown()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the wait for the tasks to stop on shutdown isn't capped")
+	}
+}
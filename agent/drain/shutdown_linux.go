@@ -0,0 +1,44 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package drain
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/pkg/errors"
+)
+
+// systemStateStopping is the state of systemd while the host is shutting down
+const systemStateStopping = "stopping"
+
+// hostShuttingDown returns whether the host is shutting down, according to the systemd D-Bus API.
+func hostShuttingDown(ctx context.Context) (bool, error) {
+	conn, err := dbus.NewSystemConnectionContext(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "unable to connect to systemd")
+	}
+	defer conn.Close()
+
+	// the property is returned as a quoted GVariant string, e.g. "running"
+	state, err := conn.GetManagerProperty("SystemState")
+	if err != nil {
+		return false, errors.Wrap(err, "unable to get the systemd state")
+	}
+	return state == strconv.Quote(systemStateStopping), nil
+}
@@ -0,0 +1,28 @@
+//go:build !linux
+// +build !linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package drain
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// hostShuttingDown is only supported on Linux.
+func hostShuttingDown(ctx context.Context) (bool, error) {
+	return false, errors.New("detecting the shutdown of the host is only supported on Linux")
+}
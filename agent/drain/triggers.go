@@ -0,0 +1,192 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package drain
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ec2"
+)
+
+const (
+	// maintenanceEventStateActive is the state of the scheduled maintenance events which haven't completed
+	// nor been canceled
+	maintenanceEventStateActive = "active"
+	// terminatedState is the target lifecycle state of instances being terminated by their auto scaling group
+	terminatedState = "Terminated"
+)
+
+// maintenanceEvent is a scheduled maintenance event of the instance, as returned by the instance metadata
+type maintenanceEvent struct {
+	Code        string
+	Description string
+	EventId     string
+	NotBefore   string
+	State       string
+}
+
+type maintenanceEventTrigger struct {
+	ec2MetadataClient ec2.EC2MetadataClient
+}
+
+// NewMaintenanceEventTrigger creates a trigger which fires when a maintenance event, such as a reboot or
+// a retirement, is scheduled for the instance.
+func NewMaintenanceEventTrigger(ec2MetadataClient ec2.EC2MetadataClient) Trigger {
+	return &maintenanceEventTrigger{ec2MetadataClient: ec2MetadataClient}
+}
+
+func (t *maintenanceEventTrigger) Name() string {
+	return TriggerMaintenanceEvent
+}
+
+func (t *maintenanceEventTrigger) Check() (bool, string) {
+	// this endpoint returns an empty list, or 404s, unless an event has been scheduled
+	resp, err := t.ec2MetadataClient.GetMetadata(ec2.ScheduledMaintenanceEventsResource)
+	if err != nil {
+		return false, ""
+	}
+	var events []maintenanceEvent
+	if err := json.Unmarshal([]byte(resp), &events); err != nil {
+		return false, ""
+	}
+	for _, event := range events {
+		if event.State == maintenanceEventStateActive {
+			return true, fmt.Sprintf("maintenance event %s (%s) scheduled not before %s",
+				event.EventId, event.Code, event.NotBefore)
+		}
+	}
+	return false, ""
+}
+
+type asgTerminationTrigger struct {
+	ec2MetadataClient ec2.EC2MetadataClient
+}
+
+// NewASGTerminationTrigger creates a trigger which fires when the auto scaling group of the instance
+// terminates it.
+func NewASGTerminationTrigger(ec2MetadataClient ec2.EC2MetadataClient) Trigger {
+	return &asgTerminationTrigger{ec2MetadataClient: ec2MetadataClient}
+}
+
+func (t *asgTerminationTrigger) Name() string {
+	return TriggerASGTermination
+}
+
+func (t *asgTerminationTrigger) Check() (bool, string) {
+	state, err := t.ec2MetadataClient.TargetLifecycleState()
+	if err != nil || state != terminatedState {
+		return false, ""
+	}
+	return true, "auto scaling group target lifecycle state is " + terminatedState
+}
+
+type drainFileTrigger struct {
+	path string
+}
+
+// NewDrainFileTrigger creates a trigger which fires when a file exists, so that the instance can be
+// drained from the host. The instance is drained again when the agent restarts while the file exists.
+func NewDrainFileTrigger(path string) Trigger {
+	return &drainFileTrigger{path: path}
+}
+
+func (t *drainFileTrigger) Name() string {
+	return TriggerDrainFile
+}
+
+func (t *drainFileTrigger) Check() (bool, string) {
+	if _, err := os.Stat(t.path); err != nil {
+		return false, ""
+	}
+	return true, fmt.Sprintf("drain file %s exists", t.path)
+}
+
+// RequestTrigger fires once the instance has been requested to drain on the drain endpoint of the
+// introspection server.
+type RequestTrigger struct {
+	lock   sync.Mutex
+	reason string // why the drain was requested, empty until it is
+}
+
+// NewRequestTrigger creates a trigger which fires once the instance has been requested to drain.
+func NewRequestTrigger() *RequestTrigger {
+	return &RequestTrigger{}
+}
+
+func (t *RequestTrigger) Name() string {
+	return TriggerDrainEndpoint
+}
+
+func (t *RequestTrigger) Check() (bool, string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.reason != "", t.reason
+}
+
+// RequestDrain requests the instance to drain. The first reason is kept.
+func (t *RequestTrigger) RequestDrain(reason string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.reason == "" {
+		t.reason = "drain requested on the drain endpoint: " + reason
+	}
+}
+
+// HealthcheckTrigger fires once a doctor healthcheck has failed for a number of consecutive runs. It's fed
+// the results of the healthchecks by the doctor.
+type HealthcheckTrigger struct {
+	threshold int
+
+	lock     sync.Mutex
+	failures map[string]int // consecutive failures by healthcheck type
+	reason   string         // why the trigger fired, empty until it does
+}
+
+// NewHealthcheckTrigger creates a trigger which fires once a healthcheck has failed threshold times in a row.
+func NewHealthcheckTrigger(threshold int) *HealthcheckTrigger {
+	return &HealthcheckTrigger{
+		threshold: threshold,
+		failures:  make(map[string]int),
+	}
+}
+
+func (t *HealthcheckTrigger) Name() string {
+	return TriggerHealthcheck
+}
+
+func (t *HealthcheckTrigger) Check() (bool, string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.reason != "", t.reason
+}
+
+// HandleResults is a doctor.HealthcheckResultsHandler counting the consecutive failures of the healthchecks.
+func (t *HealthcheckTrigger) HandleResults(results []doctor.HealthcheckResult) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, result := range results {
+		if result.Status != doctor.HealthcheckStatusImpaired {
+			t.failures[result.Type] = 0
+			continue
+		}
+		t.failures[result.Type]++
+		if t.reason == "" && t.failures[result.Type] >= t.threshold {
+			t.reason = fmt.Sprintf("healthcheck %s failed %d times in a row", result.Type, t.failures[result.Type])
+		}
+	}
+}
@@ -0,0 +1,147 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package drain
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/ec2"
+	mock_ec2 "github.com/aws/amazon-ecs-agent/ecs-agent/ec2/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceEventTrigger(t *testing.T) {
+	testCases := []struct {
+		name  string
+		resp  string
+		err   error
+		fired bool
+	}{
+		{
+			name: "no event",
+			err:  errors.New("404"),
+		},
+		{
+			name: "empty events",
+			resp: `[]`,
+		},
+		{
+			name: "completed event",
+			resp: `[{"Code": "system-reboot", "EventId": "instance-event-1", "NotBefore": "21 Jan 2026 09:00:43 GMT", "State": "completed"}]`,
+		},
+		{
+			name: "invalid events",
+			resp: `{"State": "active"}`,
+		},
+		{
+			name:  "active event",
+			resp:  `[{"Code": "system-reboot", "EventId": "instance-event-1", "NotBefore": "21 Jan 2026 09:00:43 GMT", "State": "active"}]`,
+			fired: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+			ec2MetadataClient.EXPECT().GetMetadata(ec2.ScheduledMaintenanceEventsResource).Return(tc.resp, tc.err)
+
+			trigger := NewMaintenanceEventTrigger(ec2MetadataClient)
+			assert.Equal(t, TriggerMaintenanceEvent, trigger.Name())
+			fired, reason := trigger.Check()
+			assert.Equal(t, tc.fired, fired)
+			if tc.fired {
+				assert.Contains(t, reason, "instance-event-1")
+			}
+		})
+	}
+}
+
+func TestASGTerminationTrigger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ec2MetadataClient := mock_ec2.NewMockEC2MetadataClient(ctrl)
+	gomock.InOrder(
+		ec2MetadataClient.EXPECT().TargetLifecycleState().Return("", errors.New("404")),
+		ec2MetadataClient.EXPECT().TargetLifecycleState().Return("InService", nil),
+		ec2MetadataClient.EXPECT().TargetLifecycleState().Return("Terminated", nil),
+	)
+
+	trigger := NewASGTerminationTrigger(ec2MetadataClient)
+	assert.Equal(t, TriggerASGTermination, trigger.Name())
+	fired, _ := trigger.Check()
+	assert.False(t, fired)
+	fired, _ = trigger.Check()
+	assert.False(t, fired)
+	fired, _ = trigger.Check()
+	assert.True(t, fired)
+}
+
+func TestDrainFileTrigger(t *testing.T) {
+	drainFile := filepath.Join(t.TempDir(), "drain")
+	trigger := NewDrainFileTrigger(drainFile)
+	assert.Equal(t, TriggerDrainFile, trigger.Name())
+
+	fired, _ := trigger.Check()
+	assert.False(t, fired)
+
+	require.NoError(t, os.WriteFile(drainFile, nil, 0644))
+	fired, reason := trigger.Check()
+	assert.True(t, fired)
+	assert.Contains(t, reason, drainFile)
+}
+
+func TestHealthcheckTrigger(t *testing.T) {
+	trigger := NewHealthcheckTrigger(2)
+	assert.Equal(t, TriggerHealthcheck, trigger.Name())
+	impaired := []doctor.HealthcheckResult{{Type: doctor.HealthcheckTypeContainerRuntime, Status: doctor.HealthcheckStatusImpaired}}
+	ok := []doctor.HealthcheckResult{{Type: doctor.HealthcheckTypeContainerRuntime, Status: doctor.HealthcheckStatusOk}}
+
+	trigger.HandleResults(impaired)
+	trigger.HandleResults(ok)
+	trigger.HandleResults(impaired)
+	fired, _ := trigger.Check()
+	assert.False(t, fired)
+
+	trigger.HandleResults(impaired)
+	fired, reason := trigger.Check()
+	assert.True(t, fired)
+	assert.Contains(t, reason, doctor.HealthcheckTypeContainerRuntime)
+
+	// the trigger keeps firing once the healthcheck recovers
+	trigger.HandleResults(ok)
+	fired, _ = trigger.Check()
+	assert.True(t, fired)
+}
+
+func TestRequestTrigger(t *testing.T) {
+	trigger := NewRequestTrigger()
+	assert.Equal(t, TriggerDrainEndpoint, trigger.Name())
+	fired, _ := trigger.Check()
+	assert.False(t, fired)
+
+	trigger.RequestDrain("host maintenance")
+	trigger.RequestDrain("another request")
+	fired, reason := trigger.Check()
+	assert.True(t, fired)
+	assert.Contains(t, reason, "host maintenance")
+}
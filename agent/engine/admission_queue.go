@@ -0,0 +1,170 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+// admissionQueueCheckInterval is how often the admission queue is checked for tasks which waited too
+// long or moved up a priority class
+const admissionQueueCheckInterval = 10 * time.Second
+
+// queuedTask is a task waiting in the admission queue for host resources
+type queuedTask struct {
+	*managedTask
+	enqueuedAt time.Time
+}
+
+// effectivePriorityRank returns the rank of the admission priority class of the task, raised by one for
+// each aging interval the task has waited, so that tasks of lower priority classes don't starve.
+func (queued *queuedTask) effectivePriorityRank(now time.Time, agingInterval time.Duration) int {
+	rank := apitask.AdmissionPriorityRank(queued.GetAdmissionPriority())
+	if agingInterval > 0 {
+		rank -= int(now.Sub(queued.enqueuedAt) / agingInterval)
+	}
+	return max(rank, 0)
+}
+
+// sortedWaitingTasks returns the waiting tasks in admission order: by effective priority class, then by
+// time waited. The caller must hold waitingTasksLock.
+func (engine *DockerTaskEngine) sortedWaitingTasks() []*queuedTask {
+	now := engine.time().Now()
+	agingInterval := engine.admissionAgingInterval()
+	sorted := make([]*queuedTask, len(engine.waitingTaskQueue))
+	copy(sorted, engine.waitingTaskQueue)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		iRank := sorted[i].effectivePriorityRank(now, agingInterval)
+		jRank := sorted[j].effectivePriorityRank(now, agingInterval)
+		if iRank != jRank {
+			return iRank < jRank
+		}
+		return sorted[i].enqueuedAt.Before(sorted[j].enqueuedAt)
+	})
+	return sorted
+}
+
+func (engine *DockerTaskEngine) admissionAgingInterval() time.Duration {
+	if engine.cfg == nil {
+		return 0
+	}
+	return engine.cfg.TaskAdmissionAgingInterval
+}
+
+// stopExpiredWaitingTasks stops the tasks which waited longer than the admission queue maximum wait, with
+// a stopped reason listing the host resources they waited for.
+func (engine *DockerTaskEngine) stopExpiredWaitingTasks() {
+	if engine.cfg == nil || engine.cfg.TaskAdmissionMaxWait <= 0 {
+		return
+	}
+	maxWait := engine.cfg.TaskAdmissionMaxWait
+	engine.monitorQueuedTasksLock.Lock()
+	defer engine.monitorQueuedTasksLock.Unlock()
+
+	now := engine.time().Now()
+	var expired []*queuedTask
+	engine.waitingTasksLock.RLock()
+	for _, queued := range engine.waitingTaskQueue {
+		if now.Sub(queued.enqueuedAt) > maxWait && !queued.GetDesiredStatus().Terminal() {
+			expired = append(expired, queued)
+		}
+	}
+	engine.waitingTasksLock.RUnlock()
+
+	for _, queued := range expired {
+		var unavailable []string
+		for _, resource := range engine.hostResourceManager.availability(queued.ToHostResources()) {
+			if !resource.Available {
+				unavailable = append(unavailable, resource.String())
+			}
+		}
+		reason := fmt.Sprintf("task waited more than %s for host resources: %s", maxWait,
+			strings.Join(unavailable, "; "))
+		logger.Warn("Stopping task which waited too long for host resources", logger.Fields{
+			field.TaskARN: queued.Arn,
+			field.Reason:  reason,
+		})
+		engine.dequeueTask(queued.managedTask)
+		queued.SetTerminalReason(reason)
+		queued.SetDesiredStatus(apitaskstatus.TaskStopped)
+		queued.UpdateDesiredStatus()
+		engine.saveTaskData(queued.Task)
+		queued.consumedHostResourceEvent <- struct{}{}
+	}
+}
+
+// AdmissionQueueStatus describes the tasks waiting in the admission queue for host resources.
+type AdmissionQueueStatus struct {
+	// MaxWait is the time after which waiting tasks are stopped, it's empty if tasks wait indefinitely
+	MaxWait string `json:",omitempty"`
+	// AgingInterval is the time after which waiting tasks move up a priority class
+	AgingInterval string `json:",omitempty"`
+	// Tasks are the waiting tasks in admission order
+	Tasks []QueuedTask
+}
+
+// QueuedTask describes a task waiting in the admission queue.
+type QueuedTask struct {
+	TaskARN string
+	Family  string
+	Version string
+	// PriorityClass is the admission priority class of the task, and EffectivePriorityClass the class the
+	// task is admitted with after aging
+	PriorityClass          string
+	EffectivePriorityClass string
+	EnqueuedAt             time.Time
+	Waited                 string
+	// Resources compares the host resources requested by the task with the free host resources
+	Resources []ResourceAvailability
+}
+
+// GetAdmissionQueueStatus returns the tasks waiting in the admission queue for host resources, with the
+// resources each task is waiting for.
+func (engine *DockerTaskEngine) GetAdmissionQueueStatus() *AdmissionQueueStatus {
+	status := &AdmissionQueueStatus{Tasks: []QueuedTask{}}
+	if engine.cfg != nil && engine.cfg.TaskAdmissionMaxWait > 0 {
+		status.MaxWait = engine.cfg.TaskAdmissionMaxWait.String()
+	}
+	agingInterval := engine.admissionAgingInterval()
+	if agingInterval > 0 {
+		status.AgingInterval = agingInterval.String()
+	}
+
+	engine.waitingTasksLock.RLock()
+	sorted := engine.sortedWaitingTasks()
+	engine.waitingTasksLock.RUnlock()
+
+	now := engine.time().Now()
+	for _, queued := range sorted {
+		status.Tasks = append(status.Tasks, QueuedTask{
+			TaskARN:                queued.Arn,
+			Family:                 queued.Family,
+			Version:                queued.Version,
+			PriorityClass:          queued.GetAdmissionPriority(),
+			EffectivePriorityClass: apitask.AdmissionPriorityForRank(queued.effectivePriorityRank(now, agingInterval)),
+			EnqueuedAt:             queued.enqueuedAt,
+			Waited:                 now.Sub(queued.enqueuedAt).Round(time.Second).String(),
+			Resources:              engine.hostResourceManager.availability(queued.ToHostResources()),
+		})
+	}
+	return status
+}
@@ -0,0 +1,215 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/engine/testdata"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	mock_ttime "github.com/aws/amazon-ecs-agent/ecs-agent/utils/ttime/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAdmissionQueueTestEngine returns an engine with a 1 vCPU host and tasks requesting 0.5 vCPU each,
+// queued in the given order at the given times.
+func newAdmissionQueueTestEngine(t *testing.T, cfg *config.Config, now time.Time,
+	queued map[string]time.Time, priorities map[string]string, order ...string) *DockerTaskEngine {
+	ctrl := gomock.NewController(t)
+	mockTime := mock_ttime.NewMockTime(ctrl)
+	mockTime.EXPECT().Now().Return(now).AnyTimes()
+	hostResourceManager := NewHostResourceManager(getTestHostResources())
+	taskEngine := &DockerTaskEngine{
+		cfg:                    cfg,
+		managedTasks:           make(map[string]*managedTask),
+		monitorQueuedTaskEvent: make(chan struct{}, 1),
+		hostResourceManager:    &hostResourceManager,
+		dataClient:             data.NewNoopClient(),
+		_time:                  mockTime,
+	}
+	for _, arn := range order {
+		task := testdata.LoadTask("sleep5")
+		task.Arn = arn
+		task.CPU = float64(0.5)
+		task.AdmissionPriority = priorities[arn]
+		mtask := &managedTask{
+			Task:                      task,
+			engine:                    taskEngine,
+			consumedHostResourceEvent: make(chan struct{}, 1),
+		}
+		taskEngine.managedTasks[arn] = mtask
+		taskEngine.waitingTaskQueue = append(taskEngine.waitingTaskQueue, &queuedTask{
+			managedTask: mtask,
+			enqueuedAt:  queued[arn],
+		})
+	}
+	return taskEngine
+}
+
+func TestAdmissionQueueOrder(t *testing.T) {
+	now := time.Now()
+	queued := map[string]time.Time{
+		"batch":    now.Add(-3 * time.Minute),
+		"service1": now.Add(-2 * time.Minute),
+		"daemon":   now.Add(-time.Minute),
+		"service2": now.Add(-4 * time.Minute),
+	}
+	priorities := map[string]string{
+		"batch":  apitask.AdmissionPriorityBatch,
+		"daemon": apitask.AdmissionPriorityDaemon,
+	}
+	order := []string{"batch", "service1", "daemon", "service2"}
+
+	testCases := []struct {
+		name          string
+		agingInterval time.Duration
+		expected      []string
+	}{
+		{
+			name:     "by priority class then time waited",
+			expected: []string{"daemon", "service2", "service1", "batch"},
+		},
+		{
+			// Service tasks which waited 2 minutes or more are admitted like daemon tasks, the batch task
+			// which waited 3 minutes is admitted like service tasks
+			name:          "with aging",
+			agingInterval: 2 * time.Minute,
+			expected:      []string{"service2", "service1", "daemon", "batch"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			taskEngine := newAdmissionQueueTestEngine(t, &config.Config{TaskAdmissionAgingInterval: tc.agingInterval},
+				now, queued, priorities, order...)
+			var arns []string
+			for _, queuedTask := range taskEngine.sortedWaitingTasks() {
+				arns = append(arns, queuedTask.Arn)
+			}
+			assert.Equal(t, tc.expected, arns)
+
+			top, err := taskEngine.topTask()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected[0], top.Arn)
+		})
+	}
+}
+
+func TestAdmissionQueueStartsTasksByPriority(t *testing.T) {
+	now := time.Now()
+	taskEngine := newAdmissionQueueTestEngine(t, &config.Config{}, now,
+		map[string]time.Time{"arn0": now.Add(-2 * time.Minute), "arn1": now.Add(-time.Minute), "arn2": now},
+		map[string]string{"arn0": apitask.AdmissionPriorityBatch}, "arn0", "arn1", "arn2")
+
+	// Only 2 of the 3 tasks fit on the host, the batch task keeps waiting
+	taskEngine.startWaitingTasks()
+	for _, arn := range []string{"arn1", "arn2"} {
+		select {
+		case <-taskEngine.managedTasks[arn].consumedHostResourceEvent:
+		default:
+			t.Fatalf("task %s was not started", arn)
+		}
+	}
+	top, err := taskEngine.topTask()
+	require.NoError(t, err)
+	assert.Equal(t, "arn0", top.Arn)
+}
+
+func TestAdmissionQueueStopsExpiredTasks(t *testing.T) {
+	now := time.Now()
+	taskEngine := newAdmissionQueueTestEngine(t, &config.Config{TaskAdmissionMaxWait: 10 * time.Minute}, now,
+		map[string]time.Time{"arn0": now.Add(-time.Hour), "arn1": now.Add(-time.Minute)}, nil, "arn0", "arn1")
+	consumed, err := taskEngine.hostResourceManager.consume("running", getTestTaskResourceMap(int32(768),
+		int32(256), []string{}, []string{}, []string{}))
+	require.NoError(t, err)
+	require.True(t, consumed)
+
+	taskEngine.stopExpiredWaitingTasks()
+
+	expired := taskEngine.managedTasks["arn0"]
+	select {
+	case <-expired.consumedHostResourceEvent:
+	default:
+		t.Fatal("expired task was not woken up")
+	}
+	assert.Equal(t, apitaskstatus.TaskStopped, expired.GetDesiredStatus())
+	assert.Equal(t, "Task waited more than 10m0s for host resources: CPU: requested 512, 256 free",
+		expired.GetTerminalReason())
+
+	status := taskEngine.GetAdmissionQueueStatus()
+	assert.Equal(t, "10m0s", status.MaxWait)
+	require.Len(t, status.Tasks, 1)
+	assert.Equal(t, QueuedTask{
+		TaskARN:                "arn1",
+		Family:                 expired.Family,
+		Version:                expired.Version,
+		PriorityClass:          apitask.AdmissionPriorityService,
+		EffectivePriorityClass: apitask.AdmissionPriorityService,
+		EnqueuedAt:             now.Add(-time.Minute),
+		Waited:                 "1m0s",
+		Resources:              taskEngine.hostResourceManager.availability(taskEngine.managedTasks["arn1"].ToHostResources()),
+	}, status.Tasks[0])
+	assert.False(t, status.Tasks[0].Resources[0].Available)
+}
+
+func TestMonitorQueuedTasksWithPriorities(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	hostResourceManager := NewHostResourceManager(getTestHostResources())
+	taskEngine := &DockerTaskEngine{
+		managedTasks:           make(map[string]*managedTask),
+		monitorQueuedTaskEvent: make(chan struct{}, 1),
+		hostResourceManager:    &hostResourceManager,
+	}
+	// Fill the host, then queue a batch task followed by a service task
+	for i, priority := range []string{"", apitask.AdmissionPriorityBatch, apitask.AdmissionPriorityService} {
+		task := testdata.LoadTask("sleep5")
+		task.Arn = fmt.Sprintf("arn%d", i)
+		task.CPU = float64(1)
+		task.AdmissionPriority = priority
+		taskEngine.managedTasks[task.Arn] = &managedTask{
+			Task:                      task,
+			engine:                    taskEngine,
+			consumedHostResourceEvent: make(chan struct{}, 1),
+		}
+	}
+	go taskEngine.monitorQueuedTasks(ctx)
+	consumed, err := taskEngine.hostResourceManager.consume("arn0", taskEngine.managedTasks["arn0"].ToHostResources())
+	require.NoError(t, err)
+	require.True(t, consumed)
+	taskEngine.enqueueTask(taskEngine.managedTasks["arn1"])
+	taskEngine.enqueueTask(taskEngine.managedTasks["arn2"])
+
+	taskEngine.hostResourceManager.release("arn0", taskEngine.managedTasks["arn0"].ToHostResources())
+	taskEngine.wakeUpTaskQueueMonitor()
+	select {
+	case <-taskEngine.managedTasks["arn2"].consumedHostResourceEvent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("service task was not started before the batch task")
+	}
+	top, err := taskEngine.topTask()
+	require.NoError(t, err)
+	assert.Equal(t, "arn1", top.Arn)
+}
@@ -0,0 +1,48 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package bandwidth limits the network bandwidth of tasks and containers with traffic control (tc).
+package bandwidth
+
+import (
+	"context"
+	"net"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper"
+)
+
+// Shaper applies network bandwidth limits. The traffic control configuration lives in the kernel, it
+// survives agent restarts and is removed along with the network interface it applies to.
+type Shaper interface {
+	// ApplyToNetNS limits the traffic of the interface inside the network namespace. This is used for
+	// tasks in awsvpc network mode, whose containers share the task network interface.
+	ApplyToNetNS(ctx context.Context, netNSPath, ifName string, limits *apicontainer.BandwidthLimits) error
+	// ApplyToContainer limits the traffic of the container whose process is given, by shaping the host
+	// side of its virtual interface. This is used for containers in bridge network mode. The interface
+	// is recreated every time the container starts, so the limits have to be applied on every start.
+	ApplyToContainer(ctx context.Context, pid int, limits *apicontainer.BandwidthLimits) error
+}
+
+type shaper struct {
+	exec             execwrapper.Exec
+	interfaceByIndex func(index int) (*net.Interface, error)
+}
+
+// NewShaper returns a new Shaper.
+func NewShaper() Shaper {
+	return &shaper{
+		exec:             execwrapper.NewExec(),
+		interfaceByIndex: net.InterfaceByIndex,
+	}
+}
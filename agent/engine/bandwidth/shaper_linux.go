@@ -0,0 +1,168 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package bandwidth
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	tcCmd      = "tc"
+	ipCmd      = "ip"
+	nsenterCmd = "nsenter"
+
+	// containerIfName is the name of the interface of a bridge mode container.
+	containerIfName = "eth0"
+	// minBurstBytes is the lowest burst size, it must be larger than the MTU of the interface.
+	minBurstBytes  = 32 * 1024
+	commandTimeout = 30 * time.Second
+)
+
+// peerIfIndexRegex matches the index of the peer of a veth in the output of `ip -o link show`, e.g.
+// "3: eth0@if42: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 ..."
+var peerIfIndexRegex = regexp.MustCompile(`@if([0-9]+):`)
+
+// ApplyToNetNS limits the traffic of the interface inside the network namespace. The traffic sent
+// by the task is policed at the interface egress, the traffic received by the task at its ingress.
+func (s *shaper) ApplyToNetNS(
+	ctx context.Context,
+	netNSPath, ifName string,
+	limits *apicontainer.BandwidthLimits,
+) error {
+	logger.Info("Applying bandwidth limits to network namespace", logger.Fields{
+		"NetNSPath":            netNSPath,
+		"Interface":            ifName,
+		"IngressBitsPerSecond": limits.IngressBitsPerSecond,
+		"EgressBitsPerSecond":  limits.EgressBitsPerSecond,
+	})
+	run := func(args ...string) error {
+		_, err := s.run(ctx, nsenterCmd, append([]string{"--net=" + netNSPath, tcCmd}, args...)...)
+		return err
+	}
+	return applyLimits(run, ifName, limits.EgressBitsPerSecond, limits.IngressBitsPerSecond)
+}
+
+// ApplyToContainer limits the traffic of the container by shaping the host side of its veth. The
+// directions are reversed on the host side: the traffic sent by the container is received by the veth.
+func (s *shaper) ApplyToContainer(ctx context.Context, pid int, limits *apicontainer.BandwidthLimits) error {
+	hostIfName, err := s.hostVethName(ctx, pid)
+	if err != nil {
+		return err
+	}
+	logger.Info("Applying bandwidth limits to container veth", logger.Fields{
+		"PID":                  pid,
+		"Interface":            hostIfName,
+		"IngressBitsPerSecond": limits.IngressBitsPerSecond,
+		"EgressBitsPerSecond":  limits.EgressBitsPerSecond,
+	})
+	run := func(args ...string) error {
+		_, err := s.run(ctx, tcCmd, args...)
+		return err
+	}
+	return applyLimits(run, hostIfName, limits.IngressBitsPerSecond, limits.EgressBitsPerSecond)
+}
+
+// hostVethName returns the name of the host side of the veth of the container's network namespace.
+func (s *shaper) hostVethName(ctx context.Context, pid int) (string, error) {
+	out, err := s.run(ctx, nsenterCmd, fmt.Sprintf("--net=/proc/%d/ns/net", pid),
+		ipCmd, "-o", "link", "show", containerIfName)
+	if err != nil {
+		return "", err
+	}
+	matches := peerIfIndexRegex.FindStringSubmatch(string(out))
+	if matches == nil {
+		return "", errors.Errorf("%s of process %d is not a veth: %s", containerIfName, pid,
+			strings.TrimSpace(string(out)))
+	}
+	index, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid interface index %q", matches[1])
+	}
+	iface, err := s.interfaceByIndex(index)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to find host veth of process %d", pid)
+	}
+	return iface.Name, nil
+}
+
+// applyLimits polices the traffic leaving and entering the device with filters on its clsact qdisc. A
+// rate of 0 leaves the direction unlimited. The root qdisc is left alone because fault injection
+// attaches its own prio qdisc there, and the clsact filters keep working alongside it.
+func applyLimits(run func(args ...string) error, device string, outgoingRate, incomingRate uint64) error {
+	if outgoingRate == 0 && incomingRate == 0 {
+		return nil
+	}
+	// The clsact qdisc can't be replaced, the one left by a previous start of the container is
+	// deleted first together with its filters.
+	_ = run("qdisc", "del", "dev", device, "clsact")
+	if err := run("qdisc", "add", "dev", device, "clsact"); err != nil {
+		return errors.Wrap(err, "failed to add clsact qdisc")
+	}
+	if outgoingRate > 0 {
+		if err := run(policeArgs(device, "egress", outgoingRate)...); err != nil {
+			return errors.Wrap(err, "failed to limit outgoing bandwidth")
+		}
+	}
+	if incomingRate > 0 {
+		if err := run(policeArgs(device, "ingress", incomingRate)...); err != nil {
+			return errors.Wrap(err, "failed to limit incoming bandwidth")
+		}
+	}
+	return nil
+}
+
+// policeArgs returns the tc arguments of a filter that drops the traffic above the rate in the direction.
+func policeArgs(device, direction string, bitsPerSecond uint64) []string {
+	return []string{"filter", "add", "dev", device, direction, "protocol", "all", "prio", "1", "matchall",
+		"action", "police", "rate", rateArg(bitsPerSecond), "burst", burstArg(bitsPerSecond), "drop"}
+}
+
+// run runs the command and returns its output.
+func (s *shaper) run(ctx context.Context, command string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	out, err := s.exec.CommandContext(ctx, command, args...).CombinedOutput()
+	if err != nil {
+		return out, errors.Wrapf(err, "%s %s failed: %s", command, strings.Join(args, " "),
+			strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+func rateArg(bitsPerSecond uint64) string {
+	return strconv.FormatUint(bitsPerSecond, 10) + "bit"
+}
+
+// burstArg returns the burst size for the rate, which is the traffic of 10ms at that rate.
+func burstArg(bitsPerSecond uint64) string {
+	burst := bitsPerSecond / 8 / 100
+	if burst < minBurstBytes {
+		burst = minBurstBytes
+	}
+	return strconv.FormatUint(burst, 10)
+}
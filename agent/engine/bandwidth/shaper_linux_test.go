@@ -0,0 +1,140 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package bandwidth
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	mock_execwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testNetNSPath = "/var/run/netns/test-netns"
+
+func TestApplyToNetNS(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := mock_execwrapper.NewMockExec(ctrl)
+	cmd := mock_execwrapper.NewMockCmd(ctrl)
+	s := &shaper{exec: exec}
+
+	expectTC := func(args ...interface{}) *gomock.Call {
+		return exec.EXPECT().CommandContext(gomock.Any(), "nsenter",
+			append([]interface{}{"--net=" + testNetNSPath, "tc"}, args...)...)
+	}
+	gomock.InOrder(
+		expectTC("qdisc", "del", "dev", "eth0", "clsact").Return(cmd),
+		cmd.EXPECT().CombinedOutput().Return([]byte("Cannot find specified qdisc"), errors.New("exit status 2")),
+		expectTC("qdisc", "add", "dev", "eth0", "clsact").Return(cmd),
+		cmd.EXPECT().CombinedOutput().Return(nil, nil),
+		expectTC("filter", "add", "dev", "eth0", "egress", "protocol", "all", "prio", "1", "matchall",
+			"action", "police", "rate", "20000000bit", "burst", "32768", "drop").Return(cmd),
+		cmd.EXPECT().CombinedOutput().Return(nil, nil),
+		expectTC("filter", "add", "dev", "eth0", "ingress", "protocol", "all", "prio", "1", "matchall",
+			"action", "police", "rate", "100000000bit", "burst", "125000", "drop").Return(cmd),
+		cmd.EXPECT().CombinedOutput().Return(nil, nil),
+	)
+
+	err := s.ApplyToNetNS(context.TODO(), testNetNSPath, "eth0", &apicontainer.BandwidthLimits{
+		IngressBitsPerSecond: 100000000,
+		EgressBitsPerSecond:  20000000,
+	})
+	require.NoError(t, err)
+}
+
+func TestApplyToNetNSError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := mock_execwrapper.NewMockExec(ctrl)
+	cmd := mock_execwrapper.NewMockCmd(ctrl)
+	s := &shaper{exec: exec}
+
+	gomock.InOrder(
+		exec.EXPECT().CommandContext(gomock.Any(), "nsenter", gomock.Any()).Return(cmd),
+		cmd.EXPECT().CombinedOutput().Return(nil, nil),
+		exec.EXPECT().CommandContext(gomock.Any(), "nsenter", gomock.Any()).Return(cmd),
+		cmd.EXPECT().CombinedOutput().Return([]byte("Operation not permitted"), errors.New("exit status 2")),
+	)
+
+	err := s.ApplyToNetNS(context.TODO(), testNetNSPath, "eth0",
+		&apicontainer.BandwidthLimits{EgressBitsPerSecond: 20000000})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Operation not permitted")
+}
+
+func TestApplyToContainer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := mock_execwrapper.NewMockExec(ctrl)
+	cmd := mock_execwrapper.NewMockCmd(ctrl)
+	s := &shaper{
+		exec: exec,
+		interfaceByIndex: func(index int) (*net.Interface, error) {
+			assert.Equal(t, 42, index)
+			return &net.Interface{Index: 42, Name: "veth1234"}, nil
+		},
+	}
+
+	linkOutput := "3: eth0@if42: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc noqueue state UP\n"
+	gomock.InOrder(
+		exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net=/proc/1234/ns/net",
+			"ip", "-o", "link", "show", "eth0").Return(cmd),
+		cmd.EXPECT().CombinedOutput().Return([]byte(linkOutput), nil),
+		exec.EXPECT().CommandContext(gomock.Any(), "tc", "qdisc", "del", "dev", "veth1234", "clsact").Return(cmd),
+		cmd.EXPECT().CombinedOutput().Return(nil, nil),
+		exec.EXPECT().CommandContext(gomock.Any(), "tc", "qdisc", "add", "dev", "veth1234", "clsact").Return(cmd),
+		cmd.EXPECT().CombinedOutput().Return(nil, nil),
+		// The container ingress is policed at the egress of the host veth.
+		exec.EXPECT().CommandContext(gomock.Any(), "tc", "filter", "add", "dev", "veth1234", "egress",
+			"protocol", "all", "prio", "1", "matchall",
+			"action", "police", "rate", "8000000bit", "burst", "32768", "drop").Return(cmd),
+		cmd.EXPECT().CombinedOutput().Return(nil, nil),
+	)
+
+	err := s.ApplyToContainer(context.TODO(), 1234, &apicontainer.BandwidthLimits{IngressBitsPerSecond: 8000000})
+	require.NoError(t, err)
+}
+
+func TestApplyToContainerNotVeth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := mock_execwrapper.NewMockExec(ctrl)
+	cmd := mock_execwrapper.NewMockCmd(ctrl)
+	s := &shaper{exec: exec}
+
+	exec.EXPECT().CommandContext(gomock.Any(), "nsenter", gomock.Any()).Return(cmd)
+	cmd.EXPECT().CombinedOutput().Return([]byte("2: eth0: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500\n"), nil)
+
+	err := s.ApplyToContainer(context.TODO(), 1234, &apicontainer.BandwidthLimits{IngressBitsPerSecond: 8000000})
+	assert.Error(t, err)
+}
+
+func TestBurstArg(t *testing.T) {
+	assert.Equal(t, "32768", burstArg(8000))
+	assert.Equal(t, "1250000", burstArg(1000000000))
+}
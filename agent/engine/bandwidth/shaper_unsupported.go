@@ -0,0 +1,35 @@
+//go:build !linux
+// +build !linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package bandwidth
+
+import (
+	"context"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+
+	"github.com/pkg/errors"
+)
+
+// ApplyToNetNS fails, as bandwidth limits are only supported on Linux
+func (s *shaper) ApplyToNetNS(context.Context, string, string, *apicontainer.BandwidthLimits) error {
+	return errors.New("bandwidth limits are not supported on this platform")
+}
+
+// ApplyToContainer fails, as bandwidth limits are only supported on Linux
+func (s *shaper) ApplyToContainer(context.Context, int, *apicontainer.BandwidthLimits) error {
+	return errors.New("bandwidth limits are not supported on this platform")
+}
@@ -44,7 +44,11 @@ func (engine *DockerTaskEngine) LoadState() error {
 		return err
 	}
 
-	return engine.loadENIAttachments()
+	if err := engine.loadENIAttachments(); err != nil {
+		return err
+	}
+
+	return engine.loadHostPortReservations()
 }
 
 func (engine *DockerTaskEngine) loadTasks() error {
@@ -152,6 +156,30 @@ func (engine *DockerTaskEngine) loadENIAttachments() error {
 	return nil
 }
 
+// loadHostPortReservations restores the host ports reserved for the tasks, so that they are not assigned to
+// other tasks after the agent restarts. Reservations of tasks that are no longer in the state are removed.
+func (engine *DockerTaskEngine) loadHostPortReservations() error {
+	reservations, err := engine.dataClient.GetHostPortReservations()
+	if err != nil {
+		return err
+	}
+
+	var restored []*utils.HostPortReservation
+	for _, reservation := range reservations {
+		if _, ok := engine.state.TaskByArn(reservation.TaskARN); ok {
+			restored = append(restored, reservation)
+			continue
+		}
+		seelog.Infof("Removing host port reservation of task %s that is no longer in the state", reservation.TaskARN)
+		if err := engine.dataClient.DeleteHostPortReservation(reservation.TaskARN); err != nil {
+			seelog.Errorf("Failed to remove host port reservation of task %s: %v", reservation.TaskARN, err)
+		}
+	}
+	utils.RestoreHostPortReservations(restored)
+	utils.SetHostPortReservationStore(engine.dataClient)
+	return nil
+}
+
 // SaveState saves all the data in task engine state to db.
 func (engine *DockerTaskEngine) SaveState() error {
 	state := engine.state
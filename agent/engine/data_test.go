@@ -24,6 +24,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/data"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/engine/image"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/attachment"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
@@ -161,6 +162,44 @@ func TestLoadState(t *testing.T) {
 	assert.Equal(t, testTaskARN, arn)
 }
 
+func TestLoadStateHostPortReservations(t *testing.T) {
+	dataClient := newTestDataClient(t)
+	defer func() {
+		utils.RestoreHostPortReservations(nil)
+		utils.SetHostPortReservationStore(nil)
+	}()
+
+	engine := &DockerTaskEngine{
+		state:      dockerstate.NewTaskEngineState(),
+		dataClient: dataClient,
+	}
+	staleTaskARN := "arn:aws:ecs:us-west-2:1234567890:task/test-cluster/stale"
+	reservation := &utils.HostPortReservation{
+		TaskARN: testTaskARN,
+		Ranges:  []utils.HostPortRange{{Protocol: "tcp", StartPort: 40000, EndPort: 40002}},
+	}
+	require.NoError(t, dataClient.SaveTask(testTask))
+	require.NoError(t, dataClient.SaveHostPortReservation(reservation))
+	require.NoError(t, dataClient.SaveHostPortReservation(&utils.HostPortReservation{
+		TaskARN: staleTaskARN,
+		Ranges:  []utils.HostPortRange{{Protocol: "udp", StartPort: 40003, EndPort: 40003}},
+	}))
+
+	require.NoError(t, engine.LoadState())
+	// Only the reservation of the task in the state is restored, the stale one is removed from the db.
+	assert.Equal(t, []*utils.HostPortReservation{reservation}, utils.GetHostPortReservations())
+	reservations, err := dataClient.GetHostPortReservations()
+	require.NoError(t, err)
+	assert.Equal(t, []*utils.HostPortReservation{reservation}, reservations)
+
+	// Releasing the host ports of the task removes its reservation from the db.
+	utils.ReleaseHostPorts(testTaskARN)
+	assert.Empty(t, utils.GetHostPortReservations())
+	reservations, err = dataClient.GetHostPortReservations()
+	require.NoError(t, err)
+	assert.Empty(t, reservations)
+}
+
 func TestLoadStateWithManagedDaemon(t *testing.T) {
 	dataClient := newTestDataClient(t)
 
@@ -15,6 +15,7 @@ package dependencygraph
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -40,6 +41,9 @@ const (
 	completeCondition = "COMPLETE"
 	// HealthyCondition ensures that a container progresses to next state only when dependency container is healthy
 	healthyCondition = "HEALTHY"
+	// Readiness conditions, "PORT:<port>" and "FILE:<path>", ensure that a container progresses to next state
+	// only when dependency container listens on the TCP port or has created the path. See
+	// apicontainer.ParseReadinessCondition.
 	// 0 is the standard exit code for success.
 	successExitCode = 0
 )
@@ -90,6 +94,16 @@ func (de *dependencyError) IsTerminal() bool {
 // containers within it to reach the desired status by proceeding in some
 // order.
 func ValidDependencies(task *apitask.Task, cfg *config.Config) bool {
+	if err := ValidateDependencies(task, cfg); err != nil {
+		log.Warnf("Could not resolve dependencies of task %s: %v", task.Arn, err)
+		return false
+	}
+	return true
+}
+
+// ValidateDependencies is like ValidDependencies, but it returns an error explaining why the
+// dependencies of the task can't be resolved.
+func ValidateDependencies(task *apitask.Task, cfg *config.Config) error {
 	unresolved := make([]*apicontainer.Container, len(task.Containers))
 	resolved := make([]*apicontainer.Container, 0, len(task.Containers))
 
@@ -106,11 +120,40 @@ OuterLoop:
 				continue OuterLoop
 			}
 		}
-		log.Warnf("Could not resolve some containers: [%v] for task %v", unresolved, task)
-		return false
+		return unresolvableDependenciesError(task, unresolved)
 	}
 
-	return true
+	return nil
+}
+
+// unresolvableDependenciesError explains why the dependencies of the unresolved containers can't be
+// resolved: they are either part of a cycle, depend on a container which doesn't exist, or have an
+// invalid readiness condition.
+func unresolvableDependenciesError(task *apitask.Task, unresolved []*apicontainer.Container) error {
+	if cycles := findCycles(task.Containers); len(cycles) > 0 {
+		paths := make([]string, len(cycles))
+		for i, cycle := range cycles {
+			paths[i] = strings.Join(cycle, " -> ")
+		}
+		return errors.Errorf("dependency graph: dependency cycle between containers: [%s]",
+			strings.Join(paths, "], ["))
+	}
+
+	names := make([]string, len(unresolved))
+	for i, container := range unresolved {
+		names[i] = container.Name
+		for _, dependency := range container.GetDependsOn() {
+			if _, ok := task.ContainerByName(dependency.ContainerName); !ok {
+				return errors.Errorf("dependency graph: container %s depends on container %s which does not exist",
+					container.Name, dependency.ContainerName)
+			}
+			if _, err := apicontainer.ParseReadinessCondition(dependency.Condition); err != nil {
+				return errors.Wrapf(err, "dependency graph: container %s", container.Name)
+			}
+		}
+	}
+	return errors.Errorf("dependency graph: dependencies of containers [%s] cannot be resolved",
+		strings.Join(names, ", "))
 }
 
 // DependenciesCanBeResolved verifies that it's possible to transition a `target`
@@ -130,7 +173,7 @@ func dependenciesCanBeResolved(target *apicontainer.Container, by []*apicontaine
 	if _, err := verifyContainerOrderingStatusResolvable(target, nameMap, cfg, containerOrderingDependenciesCanResolve); err != nil {
 		return false
 	}
-	return verifyStatusResolvable(target, nameMap, target.SteadyStateDependencies, onSteadyStateCanResolve)
+	return verifyStatusResolvable(target, nameMap, target.GetSteadyStateDependencies(), onSteadyStateCanResolve)
 }
 
 // DependenciesAreResolved validates that the `target` container can be
@@ -164,11 +207,20 @@ func DependenciesAreResolved(target *apicontainer.Container,
 		resourcesMap[resource.GetName()] = resource
 	}
 
+	return dependenciesAreResolved(target, nameMap, resourcesMap, cfg)
+}
+
+// dependenciesAreResolved validates that the dependencies of `target` on other containers and on task
+// resources allow it to transition to its next known status.
+func dependenciesAreResolved(target *apicontainer.Container,
+	nameMap map[string]*apicontainer.Container,
+	resourcesMap map[string]taskresource.TaskResource,
+	cfg *config.Config) (*apicontainer.DependsOn, DependencyError) {
 	if blocked, err := verifyContainerOrderingStatusResolvable(target, nameMap, cfg, containerOrderingDependenciesIsResolved); err != nil {
 		return blocked, err
 	}
 
-	if !verifyStatusResolvable(target, nameMap, target.SteadyStateDependencies, onSteadyStateIsResolved) {
+	if !verifyStatusResolvable(target, nameMap, target.GetSteadyStateDependencies(), onSteadyStateIsResolved) {
 		return nil, DependentContainerNotResolvedErr
 	}
 	if err := verifyTransitionDependenciesResolved(target, nameMap, resourcesMap); err != nil {
@@ -288,7 +340,13 @@ func verifyContainerOrderingStatusResolvable(target *apicontainer.Container, exi
 		// If the target is already created, then everything is normal and dependency can be and is resolved.
 		// However, if dependency container has already stopped, then it cannot time out.
 		if targetKnown < apicontainerstatus.ContainerCreated && dependencyContainer.GetKnownStatus() != apicontainerstatus.ContainerStopped {
-			if hasDependencyTimedOut(dependencyContainer, dependency.Condition) {
+			readiness, _ := apicontainer.ParseReadinessCondition(dependency.Condition)
+			if readiness != nil && !dependencyContainer.IsReadinessConditionMet(dependency.Condition) &&
+				hasDependencyTimedOut(dependencyContainer, dependency.Condition) {
+				return nil, &dependencyError{err: fmt.Errorf("dependency graph: container %s timed out after %s waiting for container %s to %s",
+					target.Name, dependencyContainer.GetStartTimeout(), dependencyContainer.Name, readiness), isTerminal: true}
+			}
+			if readiness == nil && hasDependencyTimedOut(dependencyContainer, dependency.Condition) {
 				return nil, &dependencyError{err: fmt.Errorf("dependency graph: container ordering dependency [%v] for target [%v] has timed out.", dependencyContainer, target), isTerminal: true}
 			}
 		}
@@ -300,6 +358,14 @@ func verifyContainerOrderingStatusResolvable(target *apicontainer.Container, exi
 			return nil, &dependencyError{err: fmt.Errorf("dependency graph: failed to resolve container ordering dependency [%v] for target [%v] as dependency did not exit successfully.", dependencyContainer, target), isTerminal: true}
 		}
 
+		// A readiness condition can't be met anymore once the dependency container has stopped.
+		if readiness, _ := apicontainer.ParseReadinessCondition(dependency.Condition); readiness != nil &&
+			dependencyContainer.GetKnownStatus() == apicontainerstatus.ContainerStopped &&
+			!dependencyContainer.IsReadinessConditionMet(dependency.Condition) {
+			return nil, &dependencyError{err: fmt.Errorf("dependency graph: container %s stopped before it could %s, which container %s depends on",
+				dependencyContainer.Name, readiness, target.Name), isTerminal: true}
+		}
+
 		// For any of the dependency conditions - START/COMPLETE/SUCCESS/HEALTHY, if the dependency container has
 		// not started and will not start in the future, this dependency can never be resolved.
 		if dependencyContainer.HasNotAndWillNotStart() {
@@ -331,34 +397,42 @@ func verifyTransitionDependenciesResolved(target *apicontainer.Container,
 
 func verifyContainerDependenciesResolved(target *apicontainer.Container, existingContainers map[string]*apicontainer.Container) bool {
 	targetNext := target.GetNextKnownStateProgression()
-	containerDependencies := target.TransitionDependenciesMap[targetNext].ContainerDependencies
+	containerDependencies := target.GetTransitionDependencies()[targetNext].ContainerDependencies
 	for _, containerDependency := range containerDependencies {
-		dep, exists := existingContainers[containerDependency.ContainerName]
-		if !exists {
-			return false
-		}
-		if dep.GetKnownStatus() < containerDependency.SatisfiedStatus {
+		if !containerDependencyResolved(containerDependency, existingContainers) {
 			return false
 		}
 	}
 	return true
 }
 
+// containerDependencyResolved returns whether the container of the transition dependency exists and has
+// reached the status which satisfies the dependency.
+func containerDependencyResolved(dependency apicontainer.ContainerDependency,
+	existingContainers map[string]*apicontainer.Container) bool {
+	dep, exists := existingContainers[dependency.ContainerName]
+	return exists && dep.GetKnownStatus() >= dependency.SatisfiedStatus
+}
+
 func verifyResourceDependenciesResolved(target *apicontainer.Container, existingResources map[string]taskresource.TaskResource) bool {
 	targetNext := target.GetNextKnownStateProgression()
-	resourceDependencies := target.TransitionDependenciesMap[targetNext].ResourceDependencies
+	resourceDependencies := target.GetTransitionDependencies()[targetNext].ResourceDependencies
 	for _, resourceDependency := range resourceDependencies {
-		dep, exists := existingResources[resourceDependency.Name]
-		if !exists {
-			return false
-		}
-		if dep.GetKnownStatus() < resourceDependency.GetRequiredStatus() {
+		if !resourceDependencyResolved(resourceDependency, existingResources) {
 			return false
 		}
 	}
 	return true
 }
 
+// resourceDependencyResolved returns whether the task resource of the transition dependency exists and
+// has reached the status required by the dependency.
+func resourceDependencyResolved(dependency apicontainer.ResourceDependency,
+	existingResources map[string]taskresource.TaskResource) bool {
+	dep, exists := existingResources[dependency.Name]
+	return exists && dep.GetKnownStatus() >= dependency.GetRequiredStatus()
+}
+
 func containerOrderingDependenciesCanResolve(target *apicontainer.Container,
 	dependsOnContainer *apicontainer.Container,
 	dependsOnStatus string,
@@ -402,6 +476,10 @@ func containerOrderingDependenciesCanResolve(target *apicontainer.Container,
 		return verifyContainerOrderingStatus(dependsOnContainer) && dependsOnContainer.HealthStatusShouldBeReported()
 
 	default:
+		// Readiness conditions are checked by the agent once the dependency container is running
+		if readiness, err := apicontainer.ParseReadinessCondition(dependsOnStatus); readiness != nil && err == nil {
+			return verifyContainerOrderingStatus(dependsOnContainer)
+		}
 		return false
 	}
 }
@@ -473,19 +551,50 @@ func containerOrderingDependenciesIsResolved(target *apicontainer.Container,
 			dependsOnContainer.GetHealthStatus().Status == apicontainerstatus.ContainerHealthy
 
 	default:
-		return false
+		// The agent records the readiness conditions met by the dependency container
+		return dependsOnContainer.IsReadinessConditionMet(dependsOnStatus)
 	}
 }
 
+// PendingReadinessConditions returns the readiness conditions which the containers of the task wait
+// for the container to meet, and which it hasn't met yet.
+func PendingReadinessConditions(task *apitask.Task, container *apicontainer.Container) []string {
+	var pending []string
+	for _, dependent := range task.Containers {
+		for _, dependency := range dependent.GetDependsOn() {
+			if dependency.ContainerName != container.Name || container.IsReadinessConditionMet(dependency.Condition) {
+				continue
+			}
+			if readiness, err := apicontainer.ParseReadinessCondition(dependency.Condition); readiness == nil || err != nil {
+				continue
+			}
+			if !slices.Contains(pending, dependency.Condition) {
+				pending = append(pending, dependency.Condition)
+			}
+		}
+	}
+	return pending
+}
+
 func hasDependencyTimedOut(dependOnContainer *apicontainer.Container, dependencyCondition string) bool {
 	if dependOnContainer.GetStartedAt().IsZero() || dependOnContainer.GetStartTimeout() <= 0 {
 		return false
 	}
+	if !conditionCanTimeOut(dependencyCondition) {
+		return false
+	}
+	return time.Now().After(dependOnContainer.GetStartedAt().Add(dependOnContainer.GetStartTimeout()))
+}
+
+// conditionCanTimeOut returns whether the dependency condition has to be met within the start
+// timeout of the dependency container.
+func conditionCanTimeOut(dependencyCondition string) bool {
 	switch dependencyCondition {
 	case successCondition, completeCondition, healthyCondition:
-		return time.Now().After(dependOnContainer.GetStartedAt().Add(dependOnContainer.GetStartTimeout()))
+		return true
 	default:
-		return false
+		readiness, _ := apicontainer.ParseReadinessCondition(dependencyCondition)
+		return readiness != nil
 	}
 }
 
@@ -1603,6 +1603,18 @@ func TestStartTimeoutForContainerOrdering(t *testing.T) {
 			DependencyCondition:    successCondition,
 			ExpectedTimedOut:       false,
 		},
+		{
+			DependencyStartedAt:    time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+			DependencyStartTimeout: 10,
+			DependencyCondition:    "PORT:8080",
+			ExpectedTimedOut:       true,
+		},
+		{
+			DependencyStartedAt:    time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+			DependencyStartTimeout: 10,
+			DependencyCondition:    startCondition,
+			ExpectedTimedOut:       false,
+		},
 	}
 
 	for _, tc := range testcases {
@@ -1654,3 +1666,66 @@ func TestVerifyContainerOrderingStatusResolvableFailOnDependencyWontStart(t *tes
 	_, err := verifyContainerOrderingStatusResolvable(target, contMap, &config.Config{}, dummyResolves)
 	assert.Error(t, err)
 }
+
+func TestReadinessConditionDependencies(t *testing.T) {
+	const portCondition = "PORT:8080"
+	newContainers := func() (*apicontainer.Container, *apicontainer.Container) {
+		dep := steadyStateContainer("sidecar", nil, apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerRunning)
+		dep.KnownStatusUnsafe = apicontainerstatus.ContainerRunning
+		dep.StartTimeout = 60
+		target := steadyStateContainer("app", dependsOnCondition(portCondition, "sidecar"),
+			apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerRunning)
+		target.KnownStatusUnsafe = apicontainerstatus.ContainerPulled
+		return dep, target
+	}
+	cfg := &config.Config{}
+
+	t.Run("waits until the condition is met", func(t *testing.T) {
+		dep, target := newContainers()
+		dep.SetStartedAt(time.Now())
+		task := &apitask.Task{Containers: []*apicontainer.Container{dep, target}}
+		assert.NoError(t, ValidateDependencies(task, cfg))
+		assert.Equal(t, []string{portCondition}, PendingReadinessConditions(task, dep))
+
+		blocked, err := DependenciesAreResolved(target, task.Containers, "", nil, nil, cfg)
+		require.Error(t, err)
+		assert.False(t, err.IsTerminal())
+		assert.Equal(t, &apicontainer.DependsOn{ContainerName: "sidecar", Condition: portCondition}, blocked)
+
+		dep.SetReadinessConditionMet(portCondition)
+		assert.Empty(t, PendingReadinessConditions(task, dep))
+		_, err = DependenciesAreResolved(target, task.Containers, "", nil, nil, cfg)
+		assert.NoError(t, err)
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		dep, target := newContainers()
+		dep.SetStartedAt(time.Now().Add(-time.Hour))
+		_, err := DependenciesAreResolved(target, []*apicontainer.Container{dep, target}, "", nil, nil, cfg)
+		require.Error(t, err)
+		assert.True(t, err.IsTerminal())
+		assert.Equal(t, "dependency graph: container app timed out after 1m0s waiting for container sidecar to listen on TCP port 8080",
+			err.Error())
+
+		dep.SetReadinessConditionMet(portCondition)
+		_, err = DependenciesAreResolved(target, []*apicontainer.Container{dep, target}, "", nil, nil, cfg)
+		assert.NoError(t, err)
+	})
+
+	t.Run("dependency stopped before it was ready", func(t *testing.T) {
+		dep, target := newContainers()
+		dep.KnownStatusUnsafe = apicontainerstatus.ContainerStopped
+		_, err := DependenciesAreResolved(target, []*apicontainer.Container{dep, target}, "", nil, nil, cfg)
+		require.Error(t, err)
+		assert.True(t, err.IsTerminal())
+		assert.Contains(t, err.Error(), "container sidecar stopped before it could listen on TCP port 8080")
+	})
+
+	t.Run("invalid condition", func(t *testing.T) {
+		dep, target := newContainers()
+		target.SetDependsOn(dependsOnCondition("FILE:relative/path", "sidecar"))
+		err := ValidateDependencies(&apitask.Task{Containers: []*apicontainer.Container{dep, target}}, cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `container app: invalid dependency condition "FILE:relative/path"`)
+	})
+}
@@ -0,0 +1,449 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dependencygraph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+)
+
+const (
+	// NodeTypeContainer is the type of the container nodes of the report.
+	NodeTypeContainer = "container"
+	// NodeTypeResource is the type of the task resource nodes of the report.
+	NodeTypeResource = "resource"
+
+	// EdgeTypeDependsOn is a container ordering dependency of the task definition. Links and volumes
+	// from other containers are container ordering dependencies too.
+	EdgeTypeDependsOn = "dependsOn"
+	// EdgeTypeSteadyState is a dependency on another container reaching its steady state.
+	EdgeTypeSteadyState = "steadyState"
+	// EdgeTypeTransition is a dependency of a container transition on another container or on a
+	// task resource, which the agent adds for its own containers and resources.
+	EdgeTypeTransition = "transition"
+)
+
+// Report is the dependency graph of a task, along with the state of each dependency. It explains
+// why a container is blocked, and why the dependencies of a task can't be resolved.
+type Report struct {
+	TaskARN string
+	Nodes   []ReportNode
+	Edges   []ReportEdge
+	// Cycles lists the dependency cycles between containers. Each cycle is the path of container
+	// names from a container back to itself, e.g. [a b a].
+	Cycles [][]string `json:",omitempty"`
+}
+
+// ReportNode is a container or a task resource of the task.
+type ReportNode struct {
+	Name          string
+	Type          string
+	KnownStatus   string
+	DesiredStatus string
+	// Blocked is set if the node has an unsatisfied dependency.
+	Blocked bool
+}
+
+// ReportEdge is a dependency of the From node on the To node.
+type ReportEdge struct {
+	From string
+	To   string
+	Type string
+	// Condition is the condition of a container ordering dependency, e.g. HEALTHY, or the status
+	// which satisfies the other dependencies, e.g. RUNNING.
+	Condition string
+	Satisfied bool
+	// BlockingReason explains why the dependency isn't satisfied.
+	BlockingReason string `json:",omitempty"`
+	// Terminal is set if the dependency can never be satisfied.
+	Terminal bool `json:",omitempty"`
+	// TimeoutRemaining is the time left before the dependency times out, for the dependencies
+	// on containers with a start timeout.
+	TimeoutRemaining string `json:",omitempty"`
+}
+
+// NewReport returns the dependency report of the task.
+func NewReport(task *apitask.Task, cfg *config.Config) *Report {
+	report := &Report{TaskARN: task.Arn}
+	containers := make(map[string]*apicontainer.Container)
+	for _, container := range task.Containers {
+		containers[container.Name] = container
+	}
+	resources := make(map[string]taskresource.TaskResource)
+	for _, resource := range task.GetResources() {
+		resources[resource.GetName()] = resource
+		report.Nodes = append(report.Nodes, ReportNode{
+			Name:          resource.GetName(),
+			Type:          NodeTypeResource,
+			KnownStatus:   resource.StatusString(resource.GetKnownStatus()),
+			DesiredStatus: resource.StatusString(resource.GetDesiredStatus()),
+		})
+	}
+
+	for _, container := range task.Containers {
+		edges := containerReportEdges(container, containers, resources, cfg)
+		_, err := dependenciesAreResolved(container, containers, resources, cfg)
+		report.Nodes = append(report.Nodes, ReportNode{
+			Name:          container.Name,
+			Type:          NodeTypeContainer,
+			KnownStatus:   container.GetKnownStatus().String(),
+			DesiredStatus: container.GetDesiredStatus().String(),
+			Blocked:       err != nil,
+		})
+		report.Edges = append(report.Edges, edges...)
+	}
+	report.Cycles = findCycles(task.Containers)
+	return report
+}
+
+// containerReportEdges returns the dependencies of the container.
+func containerReportEdges(
+	target *apicontainer.Container,
+	containers map[string]*apicontainer.Container,
+	resources map[string]taskresource.TaskResource,
+	cfg *config.Config,
+) []ReportEdge {
+	var edges []ReportEdge
+	for _, dependency := range target.GetDependsOn() {
+		edge := ReportEdge{
+			From:      target.Name,
+			To:        dependency.ContainerName,
+			Type:      EdgeTypeDependsOn,
+			Condition: dependency.Condition,
+		}
+		edge.Satisfied, edge.Terminal, edge.BlockingReason = dependsOnState(target,
+			containers[dependency.ContainerName], dependency.Condition, cfg)
+		edge.TimeoutRemaining = dependencyTimeoutRemaining(containers[dependency.ContainerName], dependency.Condition)
+		edges = append(edges, edge)
+	}
+
+	for _, name := range target.GetSteadyStateDependencies() {
+		edge := ReportEdge{
+			From:      target.Name,
+			To:        name,
+			Type:      EdgeTypeSteadyState,
+			Condition: "steady state",
+			Satisfied: true,
+		}
+		dependency, ok := containers[name]
+		switch {
+		case !ok:
+			edge.Satisfied, edge.Terminal, edge.BlockingReason = false, true, "container does not exist"
+		case target.GetKnownStatus() < apicontainerstatus.ContainerCreated &&
+			!verifyStatusResolvable(target, containers, []string{name}, onSteadyStateIsResolved):
+			edge.Satisfied = false
+			edge.BlockingReason = fmt.Sprintf("waiting for %s to reach %s", name,
+				dependency.GetSteadyStateStatus().String())
+		}
+		edges = append(edges, edge)
+	}
+
+	transitionDependencies := target.GetTransitionDependencies()
+	dependentStatuses := make([]apicontainerstatus.ContainerStatus, 0, len(transitionDependencies))
+	for status := range transitionDependencies {
+		dependentStatuses = append(dependentStatuses, status)
+	}
+	sort.Slice(dependentStatuses, func(i, j int) bool { return dependentStatuses[i] < dependentStatuses[j] })
+	for _, dependentStatus := range dependentStatuses {
+		// The transition dependencies of the statuses the container has reached are satisfied.
+		passed := target.GetKnownStatus() >= dependentStatus
+		set := transitionDependencies[dependentStatus]
+		for _, dependency := range set.ContainerDependencies {
+			edge := ReportEdge{
+				From:      target.Name,
+				To:        dependency.ContainerName,
+				Type:      EdgeTypeTransition,
+				Condition: dependency.SatisfiedStatus.String(),
+				Satisfied: true,
+			}
+			_, ok := containers[dependency.ContainerName]
+			switch {
+			case passed:
+			case !ok:
+				edge.Satisfied, edge.Terminal, edge.BlockingReason = false, true, "container does not exist"
+			case !containerDependencyResolved(dependency, containers):
+				edge.Satisfied = false
+				edge.BlockingReason = fmt.Sprintf("waiting for %s to reach %s before moving to %s",
+					dependency.ContainerName, dependency.SatisfiedStatus.String(), dependentStatus.String())
+			}
+			edges = append(edges, edge)
+		}
+		for _, dependency := range set.ResourceDependencies {
+			edge := ReportEdge{
+				From:      target.Name,
+				To:        dependency.Name,
+				Type:      EdgeTypeTransition,
+				Satisfied: true,
+			}
+			resource, ok := resources[dependency.Name]
+			if ok {
+				edge.Condition = resource.StatusString(dependency.GetRequiredStatus())
+			}
+			switch {
+			case passed:
+			case !ok:
+				edge.Satisfied, edge.Terminal, edge.BlockingReason = false, true, "resource does not exist"
+			case !resourceDependencyResolved(dependency, resources):
+				edge.Satisfied = false
+				edge.BlockingReason = fmt.Sprintf("waiting for %s to reach %s before moving to %s",
+					dependency.Name, edge.Condition, dependentStatus.String())
+			}
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// dependsOnState returns whether the container ordering dependency is satisfied, whether it can never
+// be satisfied, and why it isn't. It follows the checks of verifyContainerOrderingStatusResolvable.
+func dependsOnState(
+	target *apicontainer.Container,
+	dependency *apicontainer.Container,
+	condition string,
+	cfg *config.Config,
+) (bool, bool, string) {
+	if dependency == nil {
+		return false, true, "container does not exist"
+	}
+	targetGoal := target.GetDesiredStatus()
+	if targetGoal != target.GetSteadyStateStatus() && targetGoal != apicontainerstatus.ContainerCreated {
+		// A container can always stop regardless of its dependencies
+		return true, false, ""
+	}
+	if target.GetKnownStatus() < apicontainerstatus.ContainerCreated &&
+		dependency.GetKnownStatus() != apicontainerstatus.ContainerStopped &&
+		!dependency.IsReadinessConditionMet(condition) && hasDependencyTimedOut(dependency, condition) {
+		return false, true, fmt.Sprintf("%s did not reach %s within its start timeout of %s", dependency.Name,
+			condition, dependency.GetStartTimeout())
+	}
+	if condition == successCondition && dependency.GetKnownStatus() == apicontainerstatus.ContainerStopped &&
+		!hasDependencyStoppedSuccessfully(dependency) {
+		return false, true, fmt.Sprintf("%s did not exit successfully", dependency.Name)
+	}
+	if readiness, _ := apicontainer.ParseReadinessCondition(condition); readiness != nil &&
+		dependency.GetKnownStatus() == apicontainerstatus.ContainerStopped &&
+		!dependency.IsReadinessConditionMet(condition) {
+		return false, true, fmt.Sprintf("%s stopped before it could %s", dependency.Name, readiness)
+	}
+	if dependency.HasNotAndWillNotStart() {
+		return false, true, fmt.Sprintf("%s will never start", dependency.Name)
+	}
+	if containerOrderingDependenciesIsResolved(target, dependency, condition, cfg) {
+		return true, false, ""
+	}
+	return false, false, fmt.Sprintf("waiting for %s to be %s, it is %s", dependency.Name,
+		conditionDescription(condition), dependency.GetKnownStatus().String())
+}
+
+// conditionDescription describes the state of the dependency which satisfies the condition.
+func conditionDescription(condition string) string {
+	switch condition {
+	case createCondition:
+		return "created"
+	case startCondition:
+		return "running"
+	case successCondition:
+		return "stopped with exit code 0"
+	case completeCondition:
+		return "stopped"
+	case healthyCondition:
+		return "healthy"
+	default:
+		if readiness, _ := apicontainer.ParseReadinessCondition(condition); readiness != nil {
+			return "ready to " + readiness.String()
+		}
+		return condition
+	}
+}
+
+// dependencyTimeoutRemaining returns the time left before the dependency times out, or "" if it can't
+// time out.
+func dependencyTimeoutRemaining(dependency *apicontainer.Container, condition string) string {
+	if dependency == nil || dependency.GetStartTimeout() <= 0 ||
+		dependency.GetKnownStatus() == apicontainerstatus.ContainerStopped ||
+		dependency.IsReadinessConditionMet(condition) {
+		return ""
+	}
+	if !conditionCanTimeOut(condition) {
+		return ""
+	}
+	if dependency.GetStartedAt().IsZero() {
+		// The timeout starts when the container starts.
+		return dependency.GetStartTimeout().String()
+	}
+	remaining := time.Until(dependency.GetStartedAt().Add(dependency.GetStartTimeout()))
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining.Round(time.Second).String()
+}
+
+// findCycles returns the dependency cycles between the containers. Each cycle is reported once,
+// starting from its container which comes first in the task definition.
+func findCycles(containers []*apicontainer.Container) [][]string {
+	order := make(map[string]int)
+	for i, container := range containers {
+		order[container.Name] = i
+	}
+	dependencies := make(map[string][]string)
+	for _, container := range containers {
+		seen := make(map[string]struct{})
+		add := func(name string) {
+			if _, ok := order[name]; !ok {
+				return
+			}
+			if _, ok := seen[name]; ok {
+				return
+			}
+			seen[name] = struct{}{}
+			dependencies[container.Name] = append(dependencies[container.Name], name)
+		}
+		for _, dependency := range container.GetDependsOn() {
+			add(dependency.ContainerName)
+		}
+		for _, name := range container.GetSteadyStateDependencies() {
+			add(name)
+		}
+		for _, set := range container.GetTransitionDependencies() {
+			for _, dependency := range set.ContainerDependencies {
+				add(dependency.ContainerName)
+			}
+		}
+		sort.Slice(dependencies[container.Name], func(i, j int) bool {
+			return order[dependencies[container.Name][i]] < order[dependencies[container.Name][j]]
+		})
+	}
+
+	var cycles [][]string
+	reported := make(map[string]struct{})
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		path = append(path, name)
+		for _, next := range dependencies[name] {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case visiting:
+				cycle := rotateCycle(path[indexOf(path, next):], order)
+				key := strings.Join(cycle, " -> ")
+				if _, ok := reported[key]; !ok {
+					reported[key] = struct{}{}
+					cycles = append(cycles, cycle)
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+	}
+	for _, container := range containers {
+		if state[container.Name] == unvisited {
+			visit(container.Name)
+		}
+	}
+	return cycles
+}
+
+// rotateCycle rotates the cycle to start from its container which comes first in the task
+// definition, and closes it with that container.
+func rotateCycle(cycle []string, order map[string]int) []string {
+	start := 0
+	for i, name := range cycle {
+		if order[name] < order[cycle[start]] {
+			start = i
+		}
+	}
+	rotated := make([]string, 0, len(cycle)+1)
+	rotated = append(rotated, cycle[start:]...)
+	rotated = append(rotated, cycle[:start]...)
+	return append(rotated, rotated[0])
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// DOT renders the report in the Graphviz DOT language. Unsatisfied dependencies are red, and the
+// dependencies which are part of a cycle are bold.
+func (r *Report) DOT() string {
+	cycleEdges := make(map[[2]string]struct{})
+	for _, cycle := range r.Cycles {
+		for i := 0; i+1 < len(cycle); i++ {
+			cycleEdges[[2]string{cycle[i], cycle[i+1]}] = struct{}{}
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(r.TaskARN))
+	b.WriteString("  rankdir=LR;\n")
+	for _, node := range r.Nodes {
+		attrs := []string{
+			"label=" + dotQuote(fmt.Sprintf("%s\n%s -> %s", node.Name, node.KnownStatus, node.DesiredStatus)),
+		}
+		if node.Type == NodeTypeResource {
+			attrs = append(attrs, "shape=box")
+		}
+		if node.Blocked {
+			attrs = append(attrs, "color=red")
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(node.Name), strings.Join(attrs, ", "))
+	}
+	for _, edge := range r.Edges {
+		label := edge.Condition
+		if edge.TimeoutRemaining != "" {
+			label += " (timeout in " + edge.TimeoutRemaining + ")"
+		}
+		attrs := []string{"label=" + dotQuote(label)}
+		if edge.Type != EdgeTypeDependsOn {
+			attrs = append(attrs, "style=dashed")
+		}
+		if !edge.Satisfied {
+			attrs = append(attrs, "color=red", "tooltip="+dotQuote(edge.BlockingReason))
+		}
+		if _, ok := cycleEdges[[2]string{edge.From, edge.To}]; ok {
+			attrs = append(attrs, "penwidth=3")
+		}
+		fmt.Fprintf(&b, "  %s -> %s [%s];\n", dotQuote(edge.From), dotQuote(edge.To), strings.Join(attrs, ", "))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotQuote quotes an identifier of the DOT language.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,185 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package dependencygraph
+
+import (
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDependenciesCycle(t *testing.T) {
+	task := &apitask.Task{
+		Containers: []*apicontainer.Container{
+			steadyStateContainer("a", dependsOnCondition(createCondition, "b"),
+				apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerRunning),
+			steadyStateContainer("b", dependsOnCondition(startCondition, "c"),
+				apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerRunning),
+			steadyStateContainer("c", dependsOnCondition(createCondition, "a"),
+				apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerRunning),
+		},
+	}
+	err := ValidateDependencies(task, &config.Config{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "[a -> b -> c -> a]")
+}
+
+func TestValidateDependenciesMissingContainer(t *testing.T) {
+	task := &apitask.Task{
+		Containers: []*apicontainer.Container{
+			steadyStateContainer("php", dependsOnCondition(createCondition, "db"),
+				apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerRunning),
+		},
+	}
+	err := ValidateDependencies(task, &config.Config{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "container php depends on container db which does not exist")
+}
+
+func TestFindCycles(t *testing.T) {
+	containers := []*apicontainer.Container{
+		{Name: "a", DependsOnUnsafe: dependsOnCondition(startCondition, "b", "d")},
+		{Name: "b", DependsOnUnsafe: dependsOnCondition(startCondition, "c")},
+		{Name: "c", DependsOnUnsafe: dependsOnCondition(startCondition, "a")},
+		{Name: "d", SteadyStateDependencies: []string{"d"}},
+		{Name: "e", TransitionDependenciesMap: apicontainer.TransitionDependenciesMap{
+			apicontainerstatus.ContainerCreated: {
+				ContainerDependencies: []apicontainer.ContainerDependency{
+					{ContainerName: "a", SatisfiedStatus: apicontainerstatus.ContainerRunning},
+				},
+			},
+		}},
+	}
+	assert.Equal(t, [][]string{{"a", "b", "c", "a"}, {"d", "d"}}, findCycles(containers))
+	assert.Empty(t, findCycles(containers[3:3]))
+}
+
+func TestNewReport(t *testing.T) {
+	db := steadyStateContainer("db", nil, apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerRunning)
+	db.KnownStatusUnsafe = apicontainerstatus.ContainerRunning
+	db.HealthCheckType = apicontainer.DockerHealthCheckType
+	db.StartTimeout = 60
+	db.SetStartedAt(time.Now().Add(-10 * time.Second))
+
+	app := steadyStateContainer("app", dependsOnCondition(healthyCondition, "db"),
+		apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerRunning)
+	app.KnownStatusUnsafe = apicontainerstatus.ContainerPulled
+	app.TransitionDependenciesMap = apicontainer.TransitionDependenciesMap{
+		apicontainerstatus.ContainerCreated: {
+			ContainerDependencies: []apicontainer.ContainerDependency{
+				{ContainerName: "db", SatisfiedStatus: apicontainerstatus.ContainerRunning},
+				{ContainerName: "proxy", SatisfiedStatus: apicontainerstatus.ContainerRunning},
+			},
+		},
+	}
+
+	task := &apitask.Task{
+		Arn:        "arn:aws:ecs:us-west-2:1234567890:task/test-cluster/abc",
+		Containers: []*apicontainer.Container{db, app},
+	}
+	report := NewReport(task, &config.Config{})
+
+	assert.Equal(t, task.Arn, report.TaskARN)
+	assert.Equal(t, []ReportNode{
+		{Name: "db", Type: NodeTypeContainer, KnownStatus: "RUNNING", DesiredStatus: "RUNNING"},
+		{Name: "app", Type: NodeTypeContainer, KnownStatus: "PULLED", DesiredStatus: "RUNNING", Blocked: true},
+	}, report.Nodes)
+	require.Len(t, report.Edges, 3)
+
+	healthy := report.Edges[0]
+	assert.Equal(t, "app", healthy.From)
+	assert.Equal(t, "db", healthy.To)
+	assert.Equal(t, EdgeTypeDependsOn, healthy.Type)
+	assert.Equal(t, healthyCondition, healthy.Condition)
+	assert.False(t, healthy.Satisfied)
+	assert.False(t, healthy.Terminal)
+	assert.Equal(t, "waiting for db to be healthy, it is RUNNING", healthy.BlockingReason)
+	assert.Equal(t, "50s", healthy.TimeoutRemaining)
+
+	assert.Equal(t, ReportEdge{From: "app", To: "db", Type: EdgeTypeTransition, Condition: "RUNNING",
+		Satisfied: true}, report.Edges[1])
+	assert.Equal(t, ReportEdge{From: "app", To: "proxy", Type: EdgeTypeTransition, Condition: "RUNNING",
+		Terminal: true, BlockingReason: "container does not exist"}, report.Edges[2])
+	assert.Empty(t, report.Cycles)
+}
+
+func TestNewReportTerminalDependency(t *testing.T) {
+	exitCode := 1
+	setup := steadyStateContainer("setup", nil, apicontainerstatus.ContainerStopped, apicontainerstatus.ContainerRunning)
+	setup.KnownStatusUnsafe = apicontainerstatus.ContainerStopped
+	setup.KnownExitCodeUnsafe = &exitCode
+	app := steadyStateContainer("app", dependsOnCondition(successCondition, "setup"),
+		apicontainerstatus.ContainerRunning, apicontainerstatus.ContainerRunning)
+	app.KnownStatusUnsafe = apicontainerstatus.ContainerPulled
+
+	report := NewReport(&apitask.Task{Containers: []*apicontainer.Container{setup, app}}, &config.Config{})
+	require.Len(t, report.Edges, 1)
+	assert.False(t, report.Edges[0].Satisfied)
+	assert.True(t, report.Edges[0].Terminal)
+	assert.Equal(t, "setup did not exit successfully", report.Edges[0].BlockingReason)
+}
+
+func TestReportDOT(t *testing.T) {
+	report := &Report{
+		TaskARN: "task",
+		Nodes: []ReportNode{
+			{Name: "a", Type: NodeTypeContainer, KnownStatus: "PULLED", DesiredStatus: "RUNNING", Blocked: true},
+			{Name: "b", Type: NodeTypeContainer, KnownStatus: "PULLED", DesiredStatus: "RUNNING"},
+			{Name: "volume", Type: NodeTypeResource, KnownStatus: "CREATED", DesiredStatus: "CREATED"},
+		},
+		Edges: []ReportEdge{
+			{From: "a", To: "b", Type: EdgeTypeDependsOn, Condition: "START", BlockingReason: `waiting for "b"`},
+			{From: "b", To: "a", Type: EdgeTypeDependsOn, Condition: "HEALTHY", Satisfied: true,
+				TimeoutRemaining: "30s"},
+			{From: "b", To: "volume", Type: EdgeTypeTransition, Condition: "CREATED", Satisfied: true},
+		},
+		Cycles: [][]string{{"a", "b", "a"}},
+	}
+
+	expected := `digraph "task" {
+  rankdir=LR;
+  "a" [label="a\nPULLED -> RUNNING", color=red];
+  "b" [label="b\nPULLED -> RUNNING"];
+  "volume" [label="volume\nCREATED -> CREATED", shape=box];
+  "a" -> "b" [label="START", color=red, tooltip="waiting for \"b\"", penwidth=3];
+  "b" -> "a" [label="HEALTHY (timeout in 30s)", penwidth=3];
+  "b" -> "volume" [label="CREATED", style=dashed];
+}
+`
+	assert.Equal(t, expected, report.DOT())
+}
+
+func dependsOnCondition(condition string, names ...string) []apicontainer.DependsOn {
+	d := make([]apicontainer.DependsOn, len(names))
+	for i, name := range names {
+		d[i] = apicontainer.DependsOn{ContainerName: name, Condition: condition}
+	}
+	return d
+}
+
+func TestConditionDescription(t *testing.T) {
+	assert.Equal(t, "healthy", conditionDescription(healthyCondition))
+	assert.Equal(t, "ready to listen on TCP port 8080", conditionDescription("PORT:8080"))
+	assert.Equal(t, "ready to create /shared/ready", conditionDescription("FILE:/shared/ready"))
+}
@@ -46,6 +46,7 @@ type ImageManager interface {
 	AddAllImageStates(imageStates []*image.ImageState)
 	GetImageStateFromImageName(containerImageName string) (*image.ImageState, bool)
 	StartImageCleanupProcess(ctx context.Context)
+	RemoveUnusedImages(ctx context.Context)
 	SetDataClient(dataClient data.Client)
 	AddImageToCleanUpExclusionList(image string)
 }
@@ -372,6 +373,12 @@ func (imageManager *dockerImageManager) performPeriodicImageCleanup(ctx context.
 	}
 }
 
+// RemoveUnusedImages removes the images which aren't used by any container, as a cycle of the periodic
+// image cleanup does, without waiting for the next cycle.
+func (imageManager *dockerImageManager) RemoveUnusedImages(ctx context.Context) {
+	imageManager.removeUnusedImages(ctx)
+}
+
 func (imageManager *dockerImageManager) removeUnusedImages(ctx context.Context) {
 	logger.Debug("Attempting to obtain ImagePullDeleteLock for removing images")
 	ImagePullDeleteLock.Lock()
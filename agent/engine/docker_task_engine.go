@@ -17,9 +17,11 @@ package engine
 import (
 	"context"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -35,11 +37,15 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	"github.com/aws/amazon-ecs-agent/agent/ecscni"
+	"github.com/aws/amazon-ecs-agent/agent/engine/bandwidth"
 	dm "github.com/aws/amazon-ecs-agent/agent/engine/daemonmanager"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dependencygraph"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/engine/execcmd"
+	"github.com/aws/amazon-ecs-agent/agent/engine/healthprobe"
+	"github.com/aws/amazon-ecs-agent/agent/engine/readiness"
 	"github.com/aws/amazon-ecs-agent/agent/engine/serviceconnect"
+	"github.com/aws/amazon-ecs-agent/agent/hostresources"
 	"github.com/aws/amazon-ecs-agent/agent/statechange"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/credentialspec"
@@ -118,6 +124,9 @@ const (
 
 	defaultMonitorExecAgentsInterval = 15 * time.Minute
 
+	defaultEphemeralStorageRefreshInterval = time.Minute
+	bytesPerMiB                            = 1024 * 1024
+
 	defaultStopContainerBackoffMin = time.Second
 	defaultStopContainerBackoffMax = time.Second * 5
 	stopContainerBackoffJitter     = 0.2
@@ -132,6 +141,7 @@ const (
 
 var (
 	newExponentialBackoff = retry.NewExponentialBackoff
+	ephemeralStorageMiB   = hostresources.EphemeralStorageMiB
 
 	// List of isolated regions where AWS SDK Go V1 cannot resolve the endpoints for.
 	// This is a short term solution only for specific regions and ideally we should not be keeping a list of hardcoded regions.
@@ -168,8 +178,9 @@ type DockerTaskEngine struct {
 	state        dockerstate.TaskEngineState
 	managedTasks map[string]*managedTask
 
-	// waitingTasksQueue is a FIFO queue of tasks waiting to acquire host resources
-	waitingTaskQueue []*managedTask
+	// waitingTasksQueue is the admission queue of tasks waiting to acquire host resources. Tasks are
+	// admitted by priority class, then in FIFO order. See topTask.
+	waitingTaskQueue []*queuedTask
 
 	events                 <-chan dockerapi.DockerContainerChangeEvent
 	monitorQueuedTaskEvent chan struct{}
@@ -233,6 +244,18 @@ type DockerTaskEngine struct {
 	stopContainerBackoffMin   time.Duration
 	stopContainerBackoffMax   time.Duration
 	namespaceHelper           ecscni.NamespaceHelper
+	// ephemeralStorageRefreshInterval is the interval at which the ephemeral storage capacity is refreshed
+	// from the space available on the filesystem of the docker data root.
+	ephemeralStorageRefreshInterval time.Duration
+	// dockerRootDir is the docker data root, looked up on the first ephemeral storage capacity refresh.
+	dockerRootDir string
+	// bandwidthShaper applies the network bandwidth limits of tasks and containers.
+	bandwidthShaper bandwidth.Shaper
+	// readinessChecker checks the readiness conditions which containers wait for before they start.
+	readinessChecker readiness.Checker
+	// healthProbeManager runs the health probes of containers whose health is checked by the agent.
+	// It's set when the engine is initialized.
+	healthProbeManager *healthprobe.Manager
 }
 
 // NewDockerTaskEngine returns a created, but uninitialized, DockerTaskEngine.
@@ -278,9 +301,12 @@ func NewDockerTaskEngine(cfg *config.Config,
 		handleDelay:                       time.Sleep,
 		execCmdMgr:                        execCmdMgr,
 		monitorExecAgentsInterval:         defaultMonitorExecAgentsInterval,
+		ephemeralStorageRefreshInterval:   defaultEphemeralStorageRefreshInterval,
 		stopContainerBackoffMin:           defaultStopContainerBackoffMin,
 		stopContainerBackoffMax:           defaultStopContainerBackoffMax,
 		namespaceHelper:                   ecscni.NewNamespaceHelper(client),
+		bandwidthShaper:                   bandwidth.NewShaper(),
+		readinessChecker:                  readiness.NewChecker(),
 		daemonTasks:                       make(map[string]*apitask.Task),
 	}
 
@@ -312,12 +338,100 @@ func (engine *DockerTaskEngine) reconcileHostResources() {
 		// Call to consume here should always succeed
 		// Idempotent consume call
 		if !task.IsInternal && task.HasActiveContainers() {
+			// The ephemeral storage capacity is the space which was available when the agent started, so the
+			// tasks which were already running may not fit in it. Their requests are added to it until the
+			// capacity is refreshed below, once all of them consumed their resources.
+			if !engine.hostResourceManager.checkTaskConsumed(task.Arn) {
+				engine.hostResourceManager.addIntCapacity(EPHEMERALSTORAGE, resources[EPHEMERALSTORAGE].IntegerValue)
+			}
 			consumed, err := engine.hostResourceManager.consume(task.Arn, resources)
 			if err != nil || !consumed {
 				logger.Critical("Failed to consume resources for created/running tasks during reconciliation", logger.Fields{field.TaskARN: task.Arn})
 			}
 		}
 	}
+	engine.refreshEphemeralStorageCapacity()
+}
+
+// startPeriodicEphemeralStorageRefresh refreshes the ephemeral storage capacity periodically, as image pulls,
+// container logs and the containers of the tasks use up the space of the docker data root and the cleanup
+// of images and containers frees it. Tasks waiting for host resources are checked after each refresh.
+func (engine *DockerTaskEngine) startPeriodicEphemeralStorageRefresh(ctx context.Context) {
+	if !engine.hostResourceManager.hasResource(EPHEMERALSTORAGE) {
+		return
+	}
+	ticker := time.NewTicker(engine.ephemeralStorageRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			engine.refreshEphemeralStorageCapacity()
+			engine.wakeUpTaskQueueMonitor()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// refreshEphemeralStorageCapacity sets the ephemeral storage capacity to the space available on the filesystem
+// of the docker data root plus the space the tasks which consumed ephemeral storage already use, up to their
+// request. What the tasks already use is part of their consumption, and only the rest of their request is
+// still to be taken from the available space.
+func (engine *DockerTaskEngine) refreshEphemeralStorageCapacity() {
+	if !engine.hostResourceManager.hasResource(EPHEMERALSTORAGE) {
+		return
+	}
+	if engine.dockerRootDir == "" {
+		info, err := engine.client.Info(engine.ctx, dockerclient.InfoTimeout)
+		if err != nil {
+			logger.Warn("Unable to refresh the ephemeral storage capacity", logger.Fields{field.Error: err})
+			return
+		}
+		engine.dockerRootDir = info.DockerRootDir
+	}
+
+	taskUsedMiB := make(map[string]int32)
+	for _, task := range engine.state.AllTasks() {
+		if !engine.hostResourceManager.checkTaskConsumed(task.Arn) {
+			continue
+		}
+		request := task.ToHostResources()[EPHEMERALSTORAGE].IntegerValue
+		if request > 0 {
+			taskUsedMiB[task.Arn] = min(engine.taskEphemeralStorageUsedMiB(task), request)
+		}
+	}
+	// The available space is read after the usage of the tasks, so that what they write in between isn't
+	// counted twice
+	available, err := ephemeralStorageMiB(engine.dockerRootDir)
+	if err != nil {
+		logger.Warn("Unable to refresh the ephemeral storage capacity", logger.Fields{field.Error: err})
+		return
+	}
+	engine.hostResourceManager.refreshIntCapacity(EPHEMERALSTORAGE, available, taskUsedMiB)
+}
+
+// taskEphemeralStorageUsedMiB returns the space the writable layers of the containers of a task use. The
+// containers whose size can't be read, and the logs and local volumes of the task, count as unused, so that
+// the space they use is only taken into account through the available space.
+func (engine *DockerTaskEngine) taskEphemeralStorageUsedMiB(task *apitask.Task) int32 {
+	var usedBytes int64
+	for _, container := range task.Containers {
+		dockerID := container.GetRuntimeID()
+		if dockerID == "" {
+			continue
+		}
+		size, err := engine.client.ContainerWritableLayerSize(engine.ctx, dockerID, dockerclient.InspectContainerTimeout)
+		if err != nil {
+			logger.Warn("Unable to get the size of the writable layer of container", logger.Fields{
+				field.TaskARN:   task.Arn,
+				field.Container: container.Name,
+				field.Error:     err,
+			})
+			continue
+		}
+		usedBytes += size
+	}
+	return int32(min(usedBytes/bytesPerMiB, math.MaxInt32))
 }
 
 func (engine *DockerTaskEngine) initializeContainerStatusToTransitionFunction() {
@@ -354,6 +468,10 @@ func (engine *DockerTaskEngine) Init(ctx context.Context) error {
 	derivedCtx, cancel := context.WithCancel(ctx)
 	engine.stopEngine = cancel
 	engine.ctx = derivedCtx
+	engine.healthProbeManager = healthprobe.NewManager(derivedCtx, engine.client,
+		func(task *apitask.Task, container *apicontainer.Container, health apicontainer.HealthStatus) {
+			setContainerHealthStatus(task, container, container.GetRuntimeID(), health, engine.containerChangeEventStream)
+		})
 
 	// Open the event stream before we sync state so that e.g. if a container
 	// goes from running to stopped after we sync with it as "running" we still
@@ -368,6 +486,7 @@ func (engine *DockerTaskEngine) Init(ctx context.Context) error {
 	go engine.handleDockerEvents(derivedCtx)
 	engine.initialized = true
 	go engine.startPeriodicExecAgentsMonitoring(derivedCtx)
+	go engine.startPeriodicEphemeralStorageRefresh(derivedCtx)
 	go engine.watchAppNetImage(derivedCtx)
 	return nil
 }
@@ -391,64 +510,83 @@ func (engine *DockerTaskEngine) wakeUpTaskQueueMonitor() {
 	}
 }
 
+// topTask returns the next task to admit: the task of the highest effective priority class which has
+// waited the longest. See queuedTask.effectivePriorityRank.
 func (engine *DockerTaskEngine) topTask() (*managedTask, error) {
 	engine.waitingTasksLock.Lock()
 	defer engine.waitingTasksLock.Unlock()
-	if len(engine.waitingTaskQueue) > 0 {
-		return engine.waitingTaskQueue[0], nil
+	if len(engine.waitingTaskQueue) == 0 {
+		return nil, fmt.Errorf("no tasks in waiting queue")
 	}
-	return nil, fmt.Errorf("no tasks in waiting queue")
+	return engine.sortedWaitingTasks()[0].managedTask, nil
 }
 
 func (engine *DockerTaskEngine) enqueueTask(task *managedTask) {
 	engine.waitingTasksLock.Lock()
-	engine.waitingTaskQueue = append(engine.waitingTaskQueue, task)
+	engine.waitingTaskQueue = append(engine.waitingTaskQueue, &queuedTask{
+		managedTask: task,
+		enqueuedAt:  engine.time().Now(),
+	})
 	engine.waitingTasksLock.Unlock()
-	logger.Debug("Enqueued task in Waiting Task Queue", logger.Fields{field.TaskARN: task.Arn})
+	logger.Debug("Enqueued task in Waiting Task Queue", logger.Fields{
+		field.TaskARN:       task.Arn,
+		"admissionPriority": task.GetAdmissionPriority(),
+	})
 	engine.wakeUpTaskQueueMonitor()
 }
 
-func (engine *DockerTaskEngine) dequeueTask() (*managedTask, error) {
+func (engine *DockerTaskEngine) dequeueTask(task *managedTask) {
 	engine.waitingTasksLock.Lock()
 	defer engine.waitingTasksLock.Unlock()
-	if len(engine.waitingTaskQueue) > 0 {
-		task := engine.waitingTaskQueue[0]
-		engine.waitingTaskQueue = engine.waitingTaskQueue[1:]
-		logger.Debug("Dequeued task from Waiting Task Queue", logger.Fields{field.TaskARN: task.Arn})
-		return task, nil
-	}
-
-	return nil, fmt.Errorf("no tasks in waiting queue")
+	engine.waitingTaskQueue = slices.DeleteFunc(engine.waitingTaskQueue, func(queued *queuedTask) bool {
+		return queued.managedTask == task
+	})
+	logger.Debug("Dequeued task from Waiting Task Queue", logger.Fields{field.TaskARN: task.Arn})
 }
 
-// monitorQueuedTasks starts as many tasks as possible based on FIFO order of waitingTaskQueue
+// monitorQueuedTasks starts as many tasks as possible based on the order of waitingTaskQueue
 // and availability of host resources. When no more tasks can be started, it will wait on
 // monitorQueuedTaskEvent channel. This channel receives (best effort) messages when
 // - a task stops
 // - a new task is queued up
 // It does not need to receive all messages, as if the routine is going through the queue, it
-// may schedule more than one task for a single 'event' received
+// may schedule more than one task for a single 'event' received.
+// When the engine is configured with an admission queue maximum wait or aging interval, the queue is
+// also checked periodically, as tasks expire or move up priority classes over time.
 func (engine *DockerTaskEngine) monitorQueuedTasks(ctx context.Context) {
 	logger.Info("Monitoring Task Queue started")
+	var tick <-chan time.Time
+	if engine.cfg != nil && (engine.cfg.TaskAdmissionMaxWait > 0 || engine.cfg.TaskAdmissionAgingInterval > 0) {
+		ticker := time.NewTicker(admissionQueueCheckInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-tick:
+			engine.stopExpiredWaitingTasks()
+			engine.startWaitingTasks()
 		case <-engine.monitorQueuedTaskEvent:
-			// Dequeue as many tasks as possible and start wake up their goroutines
-			for {
-				task, err := engine.topTask()
-				if err != nil {
-					break
-				}
-				dequeuedTask := engine.tryDequeueWaitingTasks(task)
-				if !dequeuedTask {
-					break
-				}
-			}
-			logger.Debug("No more tasks could be started at this moment, waiting")
+			engine.startWaitingTasks()
+		}
+	}
+}
+
+// startWaitingTasks dequeues as many tasks as possible and wakes up their goroutines
+func (engine *DockerTaskEngine) startWaitingTasks() {
+	for {
+		task, err := engine.topTask()
+		if err != nil {
+			break
+		}
+		dequeuedTask := engine.tryDequeueWaitingTasks(task)
+		if !dequeuedTask {
+			break
 		}
 	}
+	logger.Debug("No more tasks could be started at this moment, waiting")
 }
 
 func (engine *DockerTaskEngine) tryDequeueWaitingTasks(task *managedTask) bool {
@@ -461,17 +599,17 @@ func (engine *DockerTaskEngine) tryDequeueWaitingTasks(task *managedTask) bool {
 	taskDesiredStatus := task.GetDesiredStatus()
 	if taskDesiredStatus.Terminal() {
 		logger.Info("Task desired status changed to STOPPED while waiting for host resources, progressing without consuming resources", logger.Fields{field.TaskARN: task.Arn})
-		engine.returnWaitingTask()
+		engine.returnWaitingTask(task)
 		return true
 	}
 	taskHostResources := task.ToHostResources()
 	consumed, err := task.engine.hostResourceManager.consume(task.Arn, taskHostResources)
 	if err != nil {
-		engine.failWaitingTask(err)
+		engine.failWaitingTask(task, err)
 		return true
 	}
 	if consumed {
-		engine.startWaitingTask()
+		engine.startWaitingTask(task)
 		return true
 	}
 	return false
@@ -479,20 +617,20 @@ func (engine *DockerTaskEngine) tryDequeueWaitingTasks(task *managedTask) bool {
 }
 
 // To be called when resources are not to be consumed by host resource manager, just dequeues and returns
-func (engine *DockerTaskEngine) returnWaitingTask() {
-	task, _ := engine.dequeueTask()
+func (engine *DockerTaskEngine) returnWaitingTask(task *managedTask) {
+	engine.dequeueTask(task)
 	task.consumedHostResourceEvent <- struct{}{}
 }
 
-func (engine *DockerTaskEngine) failWaitingTask(err error) {
-	task, _ := engine.dequeueTask()
+func (engine *DockerTaskEngine) failWaitingTask(task *managedTask, err error) {
+	engine.dequeueTask(task)
 	logger.Error(fmt.Sprintf("Error consuming resources due to invalid task config : %s", err.Error()), logger.Fields{field.TaskARN: task.Arn})
 	task.SetDesiredStatus(apitaskstatus.TaskStopped)
 	task.consumedHostResourceEvent <- struct{}{}
 }
 
-func (engine *DockerTaskEngine) startWaitingTask() {
-	task, _ := engine.dequeueTask()
+func (engine *DockerTaskEngine) startWaitingTask(task *managedTask) {
+	engine.dequeueTask(task)
 	logger.Info("Host resources consumed, progressing task", logger.Fields{field.TaskARN: task.Arn})
 	task.consumedHostResourceEvent <- struct{}{}
 }
@@ -707,6 +845,7 @@ func (engine *DockerTaskEngine) filterTasksToStartUnsafe(tasks []*apitask.Task)
 		for _, cont := range conts {
 			engine.synchronizeContainerStatus(cont, task)
 			engine.saveDockerContainerData(cont) // persist the container with the updated information.
+			engine.updateHealthProbe(task, cont.Container, cont.DockerID)
 		}
 
 		tasksToStart = append(tasksToStart, task)
@@ -717,7 +856,8 @@ func (engine *DockerTaskEngine) filterTasksToStartUnsafe(tasks []*apitask.Task)
 
 // updateContainerMetadata sets the container metadata from the docker inspect,
 // and update port mappings for bridge mode containers with service connect enabled
-func updateContainerMetadata(metadata *dockerapi.DockerContainerMetadata, container *apicontainer.Container, task *apitask.Task) {
+func updateContainerMetadata(metadata *dockerapi.DockerContainerMetadata, container *apicontainer.Container,
+	task *apitask.Task, containerChangeEventStream *eventstream.EventStream) {
 	container.SetCreatedAt(metadata.CreatedAt)
 	container.SetStartedAt(metadata.StartedAt)
 	container.SetFinishedAt(metadata.FinishedAt)
@@ -765,13 +905,65 @@ func updateContainerMetadata(metadata *dockerapi.DockerContainerMetadata, contai
 	}
 
 	// update the container health information
-	if container.HealthStatusShouldBeReported() {
-		container.SetHealthStatus(metadata.Health)
+	if container.HealthCheckType == apicontainer.DockerHealthCheckType {
+		setContainerHealthStatus(task, container, metadata.DockerID, metadata.Health, containerChangeEventStream)
 	}
 	container.SetNetworkMode(metadata.NetworkMode)
 	container.SetNetworkSettings(metadata.NetworkSettings)
 }
 
+// setContainerHealthStatus updates the health status and health history of the container. When the
+// health status changes, the transition is logged and written to the container change event stream
+// as a container health event.
+func setContainerHealthStatus(task *apitask.Task, container *apicontainer.Container, dockerID string,
+	health apicontainer.HealthStatus, containerChangeEventStream *eventstream.EventStream) {
+	previous := container.GetHealthStatus()
+	if !container.SetHealthStatus(health) {
+		return
+	}
+	transitionFields := logger.Fields{
+		field.TaskID:    task.GetID(),
+		field.Container: container.Name,
+		field.DockerId:  dockerID,
+		"from":          previous.Status.String(),
+		"to":            health.Status.String(),
+		"exitCode":      health.ExitCode,
+		"output":        health.Output,
+	}
+	logger.Info("Container health status transition", transitionFields)
+	if containerChangeEventStream == nil {
+		return
+	}
+	err := containerChangeEventStream.WriteToEventStream(dockerapi.DockerContainerChangeEvent{
+		Type: apicontainer.ContainerHealthEvent,
+		DockerContainerMetadata: dockerapi.DockerContainerMetadata{
+			DockerID: dockerID,
+			Health:   health,
+		},
+	})
+	if err != nil {
+		logger.Warn("Failed to write container health event to the event stream", transitionFields,
+			logger.Fields{
+				field.Error: err,
+			})
+	}
+}
+
+// updateHealthProbe starts the agent health probe of a running container and stops the probe
+// of a stopped container
+func (engine *DockerTaskEngine) updateHealthProbe(task *apitask.Task, container *apicontainer.Container,
+	dockerID string) {
+	if engine.healthProbeManager == nil || !container.HasAgentHealthProbe() {
+		return
+	}
+	switch knownStatus := container.GetKnownStatus(); {
+	case knownStatus == apicontainerstatus.ContainerRunning:
+		engine.healthProbeManager.Start(task, container, dockerID)
+	case knownStatus.Terminal():
+		engine.healthProbeManager.Stop(dockerID)
+	}
+}
+
 // synchronizeContainerStatus checks and updates the container status with docker
 func (engine *DockerTaskEngine) synchronizeContainerStatus(container *apicontainer.DockerContainer, task *apitask.Task) {
 	if container.DockerID == "" {
@@ -791,7 +983,7 @@ func (engine *DockerTaskEngine) synchronizeContainerStatus(container *apicontain
 		} else {
 			// update the container metadata in case the container was created during agent restart
 			metadata := dockerapi.MetadataFromContainer(describedContainer)
-			updateContainerMetadata(&metadata, container.Container, task)
+			updateContainerMetadata(&metadata, container.Container, task, engine.containerChangeEventStream)
 			container.DockerID = describedContainer.ID
 
 			container.Container.SetKnownStatus(dockerapi.DockerStateToState(describedContainer.State))
@@ -834,12 +1026,12 @@ func (engine *DockerTaskEngine) synchronizeContainerStatus(container *apicontain
 			}
 		} else {
 			// If this is a container state error
-			updateContainerMetadata(&metadata, container.Container, task)
+			updateContainerMetadata(&metadata, container.Container, task, engine.containerChangeEventStream)
 			container.Container.ApplyingError = apierrors.NewNamedError(metadata.Error)
 		}
 	} else {
 		// update the container metadata in case the container status/metadata changed during agent restart
-		updateContainerMetadata(&metadata, container.Container, task)
+		updateContainerMetadata(&metadata, container.Container, task, engine.containerChangeEventStream)
 		err := engine.imageManager.RecordContainerReference(container.Container)
 		if err != nil {
 			logger.Warn("Unable to add container reference to image state", logger.Fields{
@@ -869,6 +1061,9 @@ func (engine *DockerTaskEngine) checkTaskState(task *apitask.Task) {
 			continue
 		}
 		status, metadata := engine.client.DescribeContainer(engine.ctx, dockerID)
+		if status == apicontainerstatus.ContainerRunning {
+			engine.checkReadinessConditions(task, container, dockerID)
+		}
 		engine.tasksLock.RLock()
 		managedTask, ok := engine.managedTasks[task.Arn]
 		engine.tasksLock.RUnlock()
@@ -885,6 +1080,54 @@ func (engine *DockerTaskEngine) checkTaskState(task *apitask.Task) {
 	}
 }
 
+// checkReadinessConditions records the readiness conditions which the dependent containers of the
+// running container wait for, and which the container now meets.
+func (engine *DockerTaskEngine) checkReadinessConditions(task *apitask.Task, container *apicontainer.Container,
+	dockerID string) {
+	pending := dependencygraph.PendingReadinessConditions(task, container)
+	if len(pending) == 0 {
+		return
+	}
+	inspectOutput, err := engine.client.InspectContainer(engine.ctx, dockerID, dockerclient.InspectContainerTimeout)
+	if err == nil && (inspectOutput.State == nil || inspectOutput.State.Pid == 0) {
+		err = errors.New("container is not running")
+	}
+	if err != nil {
+		logger.Warn("Unable to check container readiness conditions", logger.Fields{
+			field.TaskID:    task.GetID(),
+			field.Container: container.Name,
+			field.Error:     err,
+		})
+		return
+	}
+	for _, condition := range pending {
+		readiness, err := apicontainer.ParseReadinessCondition(condition)
+		if err != nil {
+			continue
+		}
+		ready, err := engine.readinessChecker.Check(inspectOutput.State.Pid, readiness)
+		if err != nil {
+			logger.Warn("Unable to check container readiness condition", logger.Fields{
+				field.TaskID:    task.GetID(),
+				field.Container: container.Name,
+				"condition":     condition,
+				field.Error:     err,
+			})
+			continue
+		}
+		if !ready {
+			continue
+		}
+		logger.Info("Container met readiness condition", logger.Fields{
+			field.TaskID:    task.GetID(),
+			field.Container: container.Name,
+			"condition":     condition,
+		})
+		container.SetReadinessConditionMet(condition)
+		engine.saveContainerData(container)
+	}
+}
+
 // sweepTask deletes all the containers associated with a task
 func (engine *DockerTaskEngine) sweepTask(task *apitask.Task) {
 	for _, cont := range task.Containers {
@@ -987,6 +1230,7 @@ func (engine *DockerTaskEngine) deleteTask(task *apitask.Task) {
 
 	// Remove task and container data from database.
 	engine.removeTaskData(task)
+	utils.ReleaseHostPorts(task.Arn)
 
 	logger.Info("Finished removing task data, removing task from managed tasks", logger.Fields{
 		field.TaskID: task.GetID(),
@@ -1127,7 +1371,7 @@ func (engine *DockerTaskEngine) handleDockerEvent(event dockerapi.DockerContaine
 	// Container health status change does not affect the container status
 	// no need to process this in task manager
 	if event.Type == apicontainer.ContainerHealthEvent {
-		if cont.Container.HealthStatusShouldBeReported() {
+		if cont.Container.HealthCheckType == apicontainer.DockerHealthCheckType {
 			logger.Debug("Updating container health status", logger.Fields{
 				field.TaskID:    task.GetID(),
 				field.Container: cont.Container.Name,
@@ -1136,7 +1380,8 @@ func (engine *DockerTaskEngine) handleDockerEvent(event dockerapi.DockerContaine
 				"exitCode":      event.DockerContainerMetadata.Health.ExitCode,
 				"output":        event.DockerContainerMetadata.Health.Output,
 			})
-			cont.Container.SetHealthStatus(event.DockerContainerMetadata.Health)
+			setContainerHealthStatus(task, cont.Container, event.DockerID, event.DockerContainerMetadata.Health,
+				engine.containerChangeEventStream)
 		}
 		return
 	}
@@ -1224,11 +1469,12 @@ func (engine *DockerTaskEngine) UpsertTask(task *apitask.Task) {
 		engine.updateTaskENIDependencies(task)
 
 		engine.state.AddTask(task)
-		if dependencygraph.ValidDependencies(task, engine.cfg) {
+		if err := dependencygraph.ValidateDependencies(task, engine.cfg); err == nil {
 			engine.startTask(task)
 		} else {
-			logger.Error("Task has circular dependencies; unable to start", logger.Fields{
+			logger.Error("Task has unresolvable dependencies; unable to start", logger.Fields{
 				field.TaskID: task.GetID(),
+				field.Error:  err,
 			})
 			task.SetKnownStatus(apitaskstatus.TaskStopped)
 			task.SetDesiredStatus(apitaskstatus.TaskStopped)
@@ -1250,6 +1496,12 @@ func (engine *DockerTaskEngine) GetTaskByArn(arn string) (*apitask.Task, bool) {
 	return engine.state.TaskByArn(arn)
 }
 
+// GetHostResourceStatus returns the capacity of the host resources accounted for tasks, and how much of
+// them tasks consume
+func (engine *DockerTaskEngine) GetHostResourceStatus() []HostResourceStatus {
+	return engine.hostResourceManager.status()
+}
+
 func (engine *DockerTaskEngine) GetDaemonTask(daemonName string) *apitask.Task {
 	engine.daemonTasksLock.RLock()
 	defer engine.daemonTasksLock.RUnlock()
@@ -2372,6 +2624,28 @@ func (engine *DockerTaskEngine) startContainer(task *apitask.Task, container *ap
 		}
 	}
 
+	// This also runs when the restart policy restarts the container, which gets a new veth every time.
+	if task.IsNetworkModeBridge() && container.BandwidthLimits != nil {
+		if err := engine.applyContainerBandwidthLimits(task, container, dockerID); err != nil {
+			return dockerapi.DockerContainerMetadata{
+				DockerID: dockerID,
+				Error: ContainerNetworkingError{
+					fromError: fmt.Errorf("startContainer: failed to apply bandwidth limits: %+v", err),
+				},
+			}
+		}
+	}
+
+	// Like the bandwidth limits, memory.high is set again when the restart policy restarts the container.
+	if err := engine.applyContainerMemoryHigh(task, container, dockerID); err != nil {
+		return dockerapi.DockerContainerMetadata{
+			DockerID: dockerID,
+			Error: dockerapi.CannotStartContainerError{
+				FromError: fmt.Errorf("startContainer: failed to set memory.high: %+v", err),
+			},
+		}
+	}
+
 	if task.IsServiceConnectEnabled() && task.IsNetworkModeBridge() && task.IsContainerServiceConnectPause(container.Name) {
 		ipv4Addr, ipv6Addr := getBridgeModeContainerIP(dockerContainerMD.NetworkSettings)
 		if ipv4Addr == "" && ipv6Addr == "" {
@@ -2387,6 +2661,27 @@ func (engine *DockerTaskEngine) startContainer(task *apitask.Task, container *ap
 	return dockerContainerMD
 }
 
+// applyContainerBandwidthLimits applies the network bandwidth limits of a bridge mode container to the host
+// side of its veth.
+func (engine *DockerTaskEngine) applyContainerBandwidthLimits(
+	task *apitask.Task,
+	container *apicontainer.Container,
+	dockerID string,
+) error {
+	inspectOutput, err := engine.client.InspectContainer(engine.ctx, dockerID, dockerclient.InspectContainerTimeout)
+	if err != nil {
+		return err
+	}
+	if inspectOutput.State == nil || inspectOutput.State.Pid == 0 {
+		return errors.New("container is not running")
+	}
+	logger.Info("Applying container bandwidth limits", logger.Fields{
+		field.TaskID:    task.GetID(),
+		field.Container: container.Name,
+	})
+	return engine.bandwidthShaper.ApplyToContainer(engine.ctx, inspectOutput.State.Pid, container.BandwidthLimits)
+}
+
 func (engine *DockerTaskEngine) provisionContainerResources(task *apitask.Task, container *apicontainer.Container) dockerapi.DockerContainerMetadata {
 	logger.Info("Setting up container resources for container", logger.Fields{
 		field.TaskID:    task.GetID(),
@@ -2487,6 +2782,23 @@ func (engine *DockerTaskEngine) provisionContainerResourcesAwsvpc(task *apitask.
 		}
 	}
 
+	// The limits are applied with tc inside the task network namespace. They live as long as the namespace,
+	// hence they don't have to be applied again when the agent restarts.
+	if limits := task.GetBandwidthLimits(); limits != nil {
+		err = engine.bandwidthShaper.ApplyToNetNS(engine.ctx, cniConfig.ContainerNetNS, task.GetDefaultIfname(), limits)
+		if err != nil {
+			logger.Error("Unable to apply task bandwidth limits", logger.Fields{
+				field.TaskID: task.GetID(),
+				field.Error:  err,
+			})
+			return dockerapi.DockerContainerMetadata{
+				DockerID: cniConfig.ContainerID,
+				Error: ContainerNetworkingError{fmt.Errorf(
+					"container resource provisioning: failed to apply bandwidth limits: %+v", err)},
+			}
+		}
+	}
+
 	return dockerapi.MetadataFromContainer(containerInspectOutput)
 }
 
@@ -2908,12 +3220,16 @@ func getContainerHostIP(networkSettings *types.NetworkSettings) (string, bool) {
 		return "", false
 	} else if networkSettings.IPAddress != "" {
 		return networkSettings.IPAddress, true
-	} else if len(networkSettings.Networks) > 0 {
-		for mode, network := range networkSettings.Networks {
-			if mode == apitask.BridgeNetworkMode && network.IPAddress != "" {
-				return network.IPAddress, true
-			}
-		}
+	} else if network, ok := networkSettings.Networks[apitask.BridgeNetworkMode]; ok && network != nil &&
+		network.IPAddress != "" {
+		return network.IPAddress, true
+	}
+	// Containers only have an IPv6 address on the bridge of IPv6-only instances
+	if networkSettings.GlobalIPv6Address != "" {
+		return networkSettings.GlobalIPv6Address, true
+	} else if network, ok := networkSettings.Networks[apitask.BridgeNetworkMode]; ok && network != nil &&
+		network.GlobalIPv6Address != "" {
+		return network.GlobalIPv6Address, true
 	}
 	return "", false
 }
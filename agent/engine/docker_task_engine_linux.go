@@ -18,6 +18,7 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -26,6 +27,8 @@ import (
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
@@ -53,6 +56,35 @@ func (engine *DockerTaskEngine) invokePluginsForContainer(task *apitask.Task, co
 	return nil
 }
 
+// applyContainerMemoryHigh sets the memory.high configured with a docker label on the cgroup of a started
+// container. Docker has no option for memory.high, so it's written once the container cgroup exists.
+func (engine *DockerTaskEngine) applyContainerMemoryHigh(
+	task *apitask.Task,
+	container *apicontainer.Container,
+	dockerID string,
+) error {
+	if !config.CgroupV2 || container.MemoryControls == nil || container.MemoryControls.HighMiB == nil {
+		return nil
+	}
+	if engine.resourceFields == nil || engine.resourceFields.Control == nil {
+		return errors.New("cgroup control is not available")
+	}
+	inspectOutput, err := engine.client.InspectContainer(engine.ctx, dockerID, dockerclient.InspectContainerTimeout)
+	if err != nil {
+		return err
+	}
+	if inspectOutput.State == nil || inspectOutput.State.Pid == 0 {
+		return errors.New("container is not running")
+	}
+	logger.Info("Setting container memory.high", logger.Fields{
+		field.TaskID:    task.GetID(),
+		field.Container: container.Name,
+		"memoryHighMiB": *container.MemoryControls.HighMiB,
+	})
+	return engine.resourceFields.Control.SetMemoryHigh(inspectOutput.State.Pid,
+		*container.MemoryControls.HighMiB*1024*1024)
+}
+
 func (engine *DockerTaskEngine) watchAppNetImage(ctx context.Context) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -1624,3 +1624,32 @@ func TestCredentialSpecResourceTaskFile(t *testing.T) {
 	ret := taskEngine.(*DockerTaskEngine).createContainer(testTask, testTask.Containers[0])
 	assert.Nil(t, ret.Error)
 }
+
+func TestApplyContainerMemoryHigh(t *testing.T) {
+	defer func(cgroupV2 bool) { config.CgroupV2 = cgroupV2 }(config.CgroupV2)
+	config.CgroupV2 = true
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, client, _, taskEngine, _, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+	mockControl := mock_control.NewMockControl(ctrl)
+	taskEngine.(*DockerTaskEngine).resourceFields = &taskresource.ResourceFields{Control: mockControl}
+
+	testTask := testdata.LoadTask("sleep5")
+	container := testTask.Containers[0]
+	container.MemoryControls = &apicontainer.MemoryControls{HighMiB: aws.Int64(256)}
+
+	client.EXPECT().InspectContainer(gomock.Any(), containerID, gomock.Any()).Return(&types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:    containerID,
+			State: &types.ContainerState{Pid: containerPid},
+		},
+	}, nil)
+	mockControl.EXPECT().SetMemoryHigh(containerPid, int64(256*1024*1024)).Return(nil)
+	require.NoError(t, taskEngine.(*DockerTaskEngine).applyContainerMemoryHigh(testTask, container, containerID))
+
+	// memory.high is left alone in cgroup v1
+	config.CgroupV2 = false
+	require.NoError(t, taskEngine.(*DockerTaskEngine).applyContainerMemoryHigh(testTask, container, containerID))
+}
@@ -43,13 +43,16 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
 	mock_ecscni "github.com/aws/amazon-ecs-agent/agent/ecscni/mocks"
+	mock_bandwidth "github.com/aws/amazon-ecs-agent/agent/engine/bandwidth/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/engine/execcmd"
 	mock_execcmdagent "github.com/aws/amazon-ecs-agent/agent/engine/execcmd/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/engine/image"
 	mock_engine "github.com/aws/amazon-ecs-agent/agent/engine/mocks"
+	mock_readiness "github.com/aws/amazon-ecs-agent/agent/engine/readiness/mocks"
 	mock_engineserviceconnect "github.com/aws/amazon-ecs-agent/agent/engine/serviceconnect/mock"
 	"github.com/aws/amazon-ecs-agent/agent/engine/testdata"
+	"github.com/aws/amazon-ecs-agent/agent/hostresources"
 	mock_ssm_factory "github.com/aws/amazon-ecs-agent/agent/ssm/factory/mocks"
 	mock_ssmiface "github.com/aws/amazon-ecs-agent/agent/ssm/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
@@ -1109,6 +1112,99 @@ func TestProvisionContainerResourcesAwsvpcSetPausePIDInVolumeResources(t *testin
 	assert.Len(t, savedTasks, 1)
 }
 
+func TestCheckTaskStateReadinessConditions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, dockerClient, _, taskEngine, _, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+	mockChecker := mock_readiness.NewMockChecker(ctrl)
+	taskEngine.(*DockerTaskEngine).readinessChecker = mockChecker
+
+	sidecar := &apicontainer.Container{Name: "sidecar"}
+	app := &apicontainer.Container{
+		Name: "app",
+		DependsOnUnsafe: []apicontainer.DependsOn{
+			{ContainerName: "sidecar", Condition: "PORT:8080"},
+			{ContainerName: "sidecar", Condition: "FILE:/shared/ready"},
+		},
+	}
+	testTask := &apitask.Task{Arn: "arn:aws:ecs:us-west-2:1234567890:task/test-cluster/abc",
+		Containers: []*apicontainer.Container{sidecar, app}}
+	taskEngine.(*DockerTaskEngine).State().AddTask(testTask)
+	taskEngine.(*DockerTaskEngine).State().AddContainer(&apicontainer.DockerContainer{
+		DockerID:   containerID,
+		DockerName: dockerContainerName,
+		Container:  sidecar,
+	}, testTask)
+
+	dockerClient.EXPECT().DescribeContainer(gomock.Any(), containerID).
+		Return(apicontainerstatus.ContainerRunning, dockerapi.DockerContainerMetadata{})
+	dockerClient.EXPECT().InspectContainer(gomock.Any(), containerID, gomock.Any()).Return(&types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:    containerID,
+			State: &types.ContainerState{Pid: containerPid},
+		},
+	}, nil)
+	mockChecker.EXPECT().Check(containerPid, &apicontainer.ReadinessCondition{Port: 8080}).Return(true, nil)
+	mockChecker.EXPECT().Check(containerPid, &apicontainer.ReadinessCondition{Path: "/shared/ready"}).
+		Return(false, nil)
+
+	taskEngine.(*DockerTaskEngine).checkTaskState(testTask)
+	assert.True(t, sidecar.IsReadinessConditionMet("PORT:8080"))
+	assert.False(t, sidecar.IsReadinessConditionMet("FILE:/shared/ready"))
+}
+
+func TestProvisionContainerResourcesAwsvpcBandwidthLimits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctrl, dockerClient, _, taskEngine, _, _, _, _ := mocks(t, ctx, &defaultConfig)
+	defer ctrl.Finish()
+
+	mockNamespaceHelper := mock_ecscni.NewMockNamespaceHelper(ctrl)
+	taskEngine.(*DockerTaskEngine).namespaceHelper = mockNamespaceHelper
+	mockCNIClient := mock_ecscni.NewMockCNIClient(ctrl)
+	taskEngine.(*DockerTaskEngine).cniClient = mockCNIClient
+	mockShaper := mock_bandwidth.NewMockShaper(ctrl)
+	taskEngine.(*DockerTaskEngine).bandwidthShaper = mockShaper
+
+	limits := &apicontainer.BandwidthLimits{IngressBitsPerSecond: 100000000, EgressBitsPerSecond: 20000000}
+	testTask := testdata.LoadTask("sleep5")
+	testTask.Containers[0].BandwidthLimits = limits
+	pauseContainer := &apicontainer.Container{
+		Name: "pausecontainer",
+		Type: apicontainer.ContainerCNIPause,
+	}
+	testTask.Containers = append(testTask.Containers, pauseContainer)
+	testTask.AddTaskENI(mockENI)
+	testTask.NetworkMode = apitask.AWSVPCNetworkMode
+	taskEngine.(*DockerTaskEngine).State().AddTask(testTask)
+	taskEngine.(*DockerTaskEngine).State().AddContainer(&apicontainer.DockerContainer{
+		DockerID:   containerID,
+		DockerName: dockerContainerName,
+		Container:  pauseContainer,
+	}, testTask)
+
+	gomock.InOrder(
+		dockerClient.EXPECT().InspectContainer(gomock.Any(), containerID, gomock.Any()).Return(&types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				ID:    containerID,
+				State: &types.ContainerState{Pid: containerPid},
+				HostConfig: &dockercontainer.HostConfig{
+					NetworkMode: containerNetworkMode,
+				},
+			},
+		}, nil),
+		mockCNIClient.EXPECT().SetupNS(gomock.Any(), gomock.Any(), gomock.Any()).Return(nsResult, nil),
+		mockNamespaceHelper.EXPECT().ConfigureTaskNamespaceRouting(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
+		mockShaper.EXPECT().ApplyToNetNS(gomock.Any(), ExpectedNetworkNamespace, defaultIfname, limits).
+			Return(errors.New("tc failed")),
+	)
+
+	actualErr := taskEngine.(*DockerTaskEngine).provisionContainerResources(testTask, pauseContainer).Error
+	require.NotNil(t, actualErr)
+	assert.Contains(t, actualErr.Error(), "failed to apply bandwidth limits")
+}
+
 func TestProvisionContainerResourcesAwsvpcInspectError(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.TODO())
 	defer cancel()
@@ -2318,6 +2414,14 @@ func TestHandleDockerHealthEvent(t *testing.T) {
 		DockerName: "container_name",
 		Container:  testContainer,
 	}, testTask)
+	healthEvents := make(chan dockerapi.DockerContainerChangeEvent, 1)
+	require.NoError(t, taskEngine.(*DockerTaskEngine).containerChangeEventStream.Subscribe("health",
+		func(events ...interface{}) error {
+			for _, event := range events {
+				healthEvents <- event.(dockerapi.DockerContainerChangeEvent)
+			}
+			return nil
+		}))
 
 	taskEngine.(*DockerTaskEngine).handleDockerEvent(dockerapi.DockerContainerChangeEvent{
 		Status: apicontainerstatus.ContainerRunning,
@@ -2330,6 +2434,15 @@ func TestHandleDockerHealthEvent(t *testing.T) {
 		},
 	})
 	assert.Equal(t, testContainer.Health.Status, apicontainerstatus.ContainerHealthy)
+
+	select {
+	case event := <-healthEvents:
+		assert.Equal(t, apicontainer.ContainerHealthEvent, event.Type)
+		assert.Equal(t, "id", event.DockerID)
+		assert.Equal(t, apicontainerstatus.ContainerHealthy, event.Health.Status)
+	case <-time.After(time.Second):
+		t.Fatal("health transition was not written to the container change event stream")
+	}
 }
 
 func TestContainerMetadataUpdatedOnRestart(t *testing.T) {
@@ -2644,6 +2757,75 @@ func TestSynchronizeResource(t *testing.T) {
 	dockerTaskEngine.synchronizeState()
 }
 
+func TestReconcileHostResourcesEphemeralStorage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+
+	defer func() { ephemeralStorageMiB = hostresources.EphemeralStorageMiB }()
+	ephemeralStorageMiB = func(dockerRootDir string) (int32, error) {
+		assert.Equal(t, "/var/lib/docker", dockerRootDir)
+		return 2048, nil
+	}
+
+	// The capacity is the space which was available when the agent started
+	hostResources := getTestHostResources()
+	addTestIntegerResource(hostResources, EPHEMERALSTORAGE, 2048)
+	hostResourceManager := NewHostResourceManager(hostResources)
+	state := dockerstate.NewTaskEngineState()
+	taskEngine := &DockerTaskEngine{
+		ctx:                 context.TODO(),
+		client:              client,
+		state:               state,
+		hostResourceManager: &hostResourceManager,
+	}
+
+	// The restored task requested 1024 MiB and already used 512 MiB of it
+	task := &apitask.Task{
+		Arn: "arn1",
+		Containers: []*apicontainer.Container{
+			{
+				Name:                 "c1",
+				RuntimeID:            "id1",
+				KnownStatusUnsafe:    apicontainerstatus.ContainerRunning,
+				HostResourceRequests: apicontainer.HostResourceRequests{EphemeralStorageMiB: 768},
+			},
+			{
+				Name:                 "c2",
+				RuntimeID:            "id2",
+				KnownStatusUnsafe:    apicontainerstatus.ContainerRunning,
+				HostResourceRequests: apicontainer.HostResourceRequests{EphemeralStorageMiB: 256},
+			},
+		},
+	}
+	state.AddTask(task)
+	client.EXPECT().Info(gomock.Any(), gomock.Any()).Return(types.Info{DockerRootDir: "/var/lib/docker"}, nil)
+	client.EXPECT().ContainerWritableLayerSize(gomock.Any(), "id1", gomock.Any()).Return(int64(384*bytesPerMiB), nil)
+	client.EXPECT().ContainerWritableLayerSize(gomock.Any(), "id2", gomock.Any()).Return(int64(128*bytesPerMiB), nil)
+
+	taskEngine.reconcileHostResources()
+	assert.True(t, hostResourceManager.checkTaskConsumed("arn1"))
+	// Only the 512 MiB the task hasn't used yet are taken from the available space
+	assert.Contains(t, hostResourceManager.status(), HostResourceStatus{
+		Name:     EPHEMERALSTORAGE,
+		Capacity: aws.Int32(2560),
+		Consumed: aws.Int32(1024),
+		Free:     aws.Int32(1536),
+	})
+
+	// Image pulls used up the space in the meantime, and the task used up its request
+	ephemeralStorageMiB = func(string) (int32, error) { return 512, nil }
+	client.EXPECT().ContainerWritableLayerSize(gomock.Any(), "id1", gomock.Any()).Return(int64(1024*bytesPerMiB), nil)
+	client.EXPECT().ContainerWritableLayerSize(gomock.Any(), "id2", gomock.Any()).Return(int64(256*bytesPerMiB), nil)
+	taskEngine.refreshEphemeralStorageCapacity()
+	assert.Contains(t, hostResourceManager.status(), HostResourceStatus{
+		Name:     EPHEMERALSTORAGE,
+		Capacity: aws.Int32(1536),
+		Consumed: aws.Int32(1024),
+		Free:     aws.Int32(512),
+	})
+}
+
 func TestSynchronizeENIAttachment(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.TODO())
 	defer cancel()
@@ -3578,6 +3760,66 @@ func TestGetBridgeIP(t *testing.T) {
 	}
 }
 
+func TestGetBridgeIPv6(t *testing.T) {
+	const (
+		defaultIPv6 = "2001:db8:1::242:ac11:2"
+		bridgeIPv6  = "2001:db8:1::242:ac11:3"
+	)
+	testCases := []struct {
+		name              string
+		networkSettings   *types.NetworkSettings
+		expectedOk        bool
+		expectedIPAddress string
+	}{
+		{
+			name: "IPv4 address is preferred",
+			networkSettings: &types.NetworkSettings{
+				Networks: map[string]*network.EndpointSettings{
+					networkModeBridge: {IPAddress: networkBridgeIP, GlobalIPv6Address: bridgeIPv6},
+				},
+			},
+			expectedOk:        true,
+			expectedIPAddress: networkBridgeIP,
+		},
+		{
+			name: "default IPv6 address",
+			networkSettings: &types.NetworkSettings{
+				DefaultNetworkSettings: types.DefaultNetworkSettings{GlobalIPv6Address: defaultIPv6},
+			},
+			expectedOk:        true,
+			expectedIPAddress: defaultIPv6,
+		},
+		{
+			name: "bridge IPv6 address",
+			networkSettings: &types.NetworkSettings{
+				Networks: map[string]*network.EndpointSettings{
+					networkModeBridge: {GlobalIPv6Address: bridgeIPv6},
+				},
+			},
+			expectedOk:        true,
+			expectedIPAddress: bridgeIPv6,
+		},
+		{
+			name: "IPv6 address of another network",
+			networkSettings: &types.NetworkSettings{
+				Networks: map[string]*network.EndpointSettings{
+					networkModeAWSVPC: {GlobalIPv6Address: bridgeIPv6},
+				},
+			},
+			expectedOk:        false,
+			expectedIPAddress: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			IPAddress, ok := getContainerHostIP(tc.networkSettings)
+			assert.Equal(t, tc.expectedOk, ok)
+			assert.Equal(t, tc.expectedIPAddress, IPAddress)
+		})
+	}
+}
+
 func TestStartFirelensContainerRetryForContainerIP(t *testing.T) {
 	applicationContainerName := "logSenderTask"
 	firelensContainerName := "test-firelens"
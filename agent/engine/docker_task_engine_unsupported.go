@@ -41,6 +41,15 @@ func (engine *DockerTaskEngine) invokePluginsForContainer(task *apitask.Task, co
 	return nil
 }
 
+// applyContainerMemoryHigh is a noop, memory.high is only supported on linux
+func (engine *DockerTaskEngine) applyContainerMemoryHigh(
+	task *apitask.Task,
+	container *apicontainer.Container,
+	dockerID string,
+) error {
+	return nil
+}
+
 // watchAppNetImage is a file watcher, if there is any change/update to AppNet image
 // we reload the image and restart the relay instance task with updated AppNet image.
 func (engine *DockerTaskEngine) watchAppNetImage(ctx context.Context) {
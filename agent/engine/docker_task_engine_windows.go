@@ -69,6 +69,15 @@ func (engine *DockerTaskEngine) invokePluginsForContainer(task *apitask.Task, co
 	return nil
 }
 
+// applyContainerMemoryHigh is a noop, memory.high is only supported on linux
+func (engine *DockerTaskEngine) applyContainerMemoryHigh(
+	task *apitask.Task,
+	container *apicontainer.Container,
+	dockerID string,
+) error {
+	return nil
+}
+
 func (engine *DockerTaskEngine) watchAppNetImage(ctx context.Context) {
 }
 
@@ -0,0 +1,46 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package healthprobe
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecscni"
+	"github.com/aws/amazon-ecs-agent/agent/utils/nswrapper"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+)
+
+// netNSDialer returns a DialContextFunc that opens connections from the network namespace of
+// the process. The socket is created while the thread is in the namespace, so the connection
+// stays in the namespace after the thread switches back.
+func netNSDialer(pid int) DialContextFunc {
+	nsPath := fmt.Sprintf(ecscni.NetnsFormat, strconv.Itoa(pid))
+	nsWrapper := nswrapper.NewNS()
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		var conn net.Conn
+		err := nsWrapper.WithNetNSPath(nsPath, func(ns.NetNS) error {
+			var err error
+			conn, err = (&net.Dialer{}).DialContext(ctx, network, address)
+			return err
+		})
+		return conn, err
+	}
+}
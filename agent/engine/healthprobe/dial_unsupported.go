@@ -0,0 +1,32 @@
+//go:build !linux
+// +build !linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package healthprobe
+
+import (
+	"context"
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// netNSDialer returns a DialContextFunc that fails, as probing containers from the agent
+// is only supported on Linux
+func netNSDialer(pid int) DialContextFunc {
+	return func(context.Context, string, string) (net.Conn, error) {
+		return nil, errors.New("agent health probes are not supported on this platform")
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package healthprobe runs the health probes of containers whose health is checked by the
+// agent instead of by docker.
+package healthprobe
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient"
+	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+// maxOutputLength is the maximum length of the output of a probe that is kept, which matches
+// the length of the docker health check output kept by the agent
+const maxOutputLength = 1024
+
+// HealthFunc receives the health status of a container after each probe. The status holds
+// the result of the probe.
+type HealthFunc func(task *apitask.Task, container *apicontainer.Container, health apicontainer.HealthStatus)
+
+// Manager schedules the health probes of containers
+type Manager struct {
+	ctx      context.Context
+	client   dockerapi.DockerClient
+	onHealth HealthFunc
+	// newDialer returns the dialer used to probe the container with the given pid. It can be
+	// replaced by tests.
+	newDialer func(pid int) DialContextFunc
+
+	lock   sync.Mutex
+	probes map[string]context.CancelFunc
+}
+
+// NewManager creates a Manager that runs probes until ctx is cancelled
+func NewManager(ctx context.Context, client dockerapi.DockerClient, onHealth HealthFunc) *Manager {
+	return &Manager{
+		ctx:       ctx,
+		client:    client,
+		onHealth:  onHealth,
+		newDialer: netNSDialer,
+		probes:    make(map[string]context.CancelFunc),
+	}
+}
+
+// Start starts probing the container if it has an agent health probe and isn't probed already
+func (m *Manager) Start(task *apitask.Task, container *apicontainer.Container, dockerID string) {
+	if !container.HasAgentHealthProbe() || dockerID == "" {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.probes[dockerID]; ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.probes[dockerID] = cancel
+	go m.run(ctx, task, container, dockerID)
+}
+
+// Stop stops probing the container
+func (m *Manager) Stop(dockerID string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if cancel, ok := m.probes[dockerID]; ok {
+		cancel()
+		delete(m.probes, dockerID)
+	}
+}
+
+// run probes the container every interval until ctx is cancelled. Like docker health checks,
+// the container becomes healthy after a successful probe and unhealthy after Retries
+// consecutive failed probes. Failed probes during the start period are not counted.
+func (m *Manager) run(ctx context.Context, task *apitask.Task, container *apicontainer.Container, dockerID string) {
+	probe := container.HealthProbe
+	fields := logger.Fields{
+		field.TaskID:    task.GetID(),
+		field.Container: container.Name,
+		field.DockerId:  dockerID,
+		"probeType":     probe.Type,
+	}
+	dial, err := m.containerDialer(ctx, dockerID)
+	if err != nil {
+		logger.Error("Unable to start container health probe", fields, logger.Fields{field.Error: err})
+		m.Stop(dockerID)
+		return
+	}
+	logger.Info("Starting container health probe", fields)
+
+	startedAt := container.GetStartedAt()
+	if startedAt.IsZero() {
+		startedAt = time.Now()
+	}
+	status := container.GetHealthStatus().Status
+	failures := 0
+	ticker := time.NewTicker(probe.IntervalDuration())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		result := m.probeOnce(ctx, dial, probe)
+		if ctx.Err() != nil {
+			return
+		}
+		if result.ExitCode == 0 {
+			failures = 0
+			status = apicontainerstatus.ContainerHealthy
+		} else if result.Start.Sub(startedAt) >= probe.StartPeriodDuration() {
+			failures++
+			if failures >= probe.Retries {
+				status = apicontainerstatus.ContainerUnhealthy
+			}
+		}
+		m.onHealth(task, container, apicontainer.HealthStatus{
+			Status:   status,
+			ExitCode: result.ExitCode,
+			Output:   result.Output,
+			Results:  []apicontainer.HealthCheckResult{result},
+		})
+	}
+}
+
+// containerDialer returns a dialer for the network namespace of the container
+func (m *Manager) containerDialer(ctx context.Context, dockerID string) (DialContextFunc, error) {
+	inspect, err := m.client.InspectContainer(ctx, dockerID, dockerclient.InspectContainerTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return m.newDialer(inspect.State.Pid), nil
+}
+
+// probeOnce runs the probe and returns its result. Following the docker convention, the exit
+// code is 0 if the container is healthy and 1 otherwise.
+func (m *Manager) probeOnce(ctx context.Context, dial DialContextFunc,
+	probe *apicontainer.HealthProbe) apicontainer.HealthCheckResult {
+	probeCtx, cancel := context.WithTimeout(ctx, probe.TimeoutDuration())
+	defer cancel()
+
+	result := apicontainer.HealthCheckResult{Start: time.Now()}
+	output, err := runProbe(probeCtx, dial, probe)
+	result.End = time.Now()
+	if err != nil {
+		result.ExitCode = 1
+		output = err.Error()
+	}
+	if len(output) > maxOutputLength {
+		output = output[:maxOutputLength]
+	}
+	result.Output = output
+	return result
+}
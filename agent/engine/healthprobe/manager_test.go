@@ -0,0 +1,172 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package healthprobe
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+
+	"github.com/docker/docker/api/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const testDockerID = "dockerID"
+
+// dialerTo returns a dialer that connects to the listener instead of the probed address.
+func dialerTo(listener net.Listener) DialContextFunc {
+	return func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, listener.Addr().String())
+	}
+}
+
+type testHealthServer struct {
+	healthpb.UnimplementedHealthServer
+	serving atomic.Bool
+}
+
+func (s *testHealthServer) Check(context.Context, *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if s.serving.Load() {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	return &healthpb.HealthCheckResponse{Status: status}, nil
+}
+
+func TestProbeHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	dial := dialerTo(server.Listener)
+
+	_, err := runProbe(context.Background(), dial, &apicontainer.HealthProbe{
+		Type: apicontainer.HealthProbeTypeHTTP, Port: 80, Path: "/health",
+	})
+	assert.NoError(t, err)
+	_, err = runProbe(context.Background(), dial, &apicontainer.HealthProbe{
+		Type: apicontainer.HealthProbeTypeHTTP, Port: 80, Path: "/missing",
+	})
+	assert.Error(t, err)
+}
+
+func TestProbeTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	dial := dialerTo(listener)
+	probe := &apicontainer.HealthProbe{Type: apicontainer.HealthProbeTypeTCP, Port: 80}
+
+	_, err = runProbe(context.Background(), dial, probe)
+	assert.NoError(t, err)
+	listener.Close()
+	_, err = runProbe(context.Background(), dial, probe)
+	assert.Error(t, err)
+}
+
+func TestProbeGRPC(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	healthServer := &testHealthServer{}
+	healthServer.serving.Store(true)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	defer server.Stop()
+	dial := dialerTo(listener)
+	probe := &apicontainer.HealthProbe{Type: apicontainer.HealthProbeTypeGRPC, Port: 80}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = runProbe(ctx, dial, probe)
+	assert.NoError(t, err)
+	healthServer.serving.Store(false)
+	_, err = runProbe(ctx, dial, probe)
+	assert.Error(t, err)
+}
+
+func TestManagerReportsHealth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock_dockerapi.NewMockDockerClient(ctrl)
+	client.EXPECT().InspectContainer(gomock.Any(), testDockerID, gomock.Any()).Return(&types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{State: &types.ContainerState{Pid: 1}},
+	}, nil)
+
+	var healthy atomic.Bool
+	healthy.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	healthChan := make(chan apicontainer.HealthStatus)
+	manager := NewManager(ctx, client, func(_ *apitask.Task, _ *apicontainer.Container, health apicontainer.HealthStatus) {
+		healthChan <- health
+	})
+	manager.newDialer = func(pid int) DialContextFunc {
+		assert.Equal(t, 1, pid)
+		return dialerTo(server.Listener)
+	}
+
+	container := &apicontainer.Container{
+		Name:            "container",
+		HealthCheckType: apicontainer.AgentHealthCheckType,
+		HealthProbe: &apicontainer.HealthProbe{
+			Type: apicontainer.HealthProbeTypeHTTP, Port: 80, Interval: 1, Timeout: 1, Retries: 2,
+		},
+	}
+	manager.Start(&apitask.Task{Arn: "arn:aws:ecs:region:account-id:task/task-id"}, container, testDockerID)
+	// Starting the probe of a container that is already probed does nothing
+	manager.Start(&apitask.Task{Arn: "arn:aws:ecs:region:account-id:task/task-id"}, container, testDockerID)
+
+	health := <-healthChan
+	assert.Equal(t, apicontainerstatus.ContainerHealthy, health.Status)
+	require.Len(t, health.Results, 1)
+	assert.Equal(t, 0, health.Results[0].ExitCode)
+
+	// The container stays healthy until the number of failed probes reaches Retries
+	healthy.Store(false)
+	health = <-healthChan
+	assert.Equal(t, apicontainerstatus.ContainerHealthy, health.Status)
+	assert.Equal(t, 1, health.ExitCode)
+	health = <-healthChan
+	assert.Equal(t, apicontainerstatus.ContainerUnhealthy, health.Status)
+
+	manager.Stop(testDockerID)
+	manager.lock.Lock()
+	assert.Empty(t, manager.probes)
+	manager.lock.Unlock()
+}
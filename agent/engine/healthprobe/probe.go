@@ -0,0 +1,114 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package healthprobe
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// probeHost is the address probes connect to in the network namespace of the container
+	probeHost = "127.0.0.1"
+	// probeUserAgent is the user agent of HTTP probes
+	probeUserAgent = "Amazon ECS Agent health probe"
+)
+
+// DialContextFunc opens a connection to the address in the network namespace of a container
+type DialContextFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// runProbe runs the probe once and returns nil if the container is healthy, or an error
+// describing why the probe failed
+func runProbe(ctx context.Context, dial DialContextFunc, probe *apicontainer.HealthProbe) (string, error) {
+	address := net.JoinHostPort(probeHost, strconv.Itoa(int(probe.Port)))
+	switch probe.Type {
+	case apicontainer.HealthProbeTypeHTTP:
+		return probeHTTP(ctx, dial, address, probe)
+	case apicontainer.HealthProbeTypeTCP:
+		return probeTCP(ctx, dial, address)
+	case apicontainer.HealthProbeTypeGRPC:
+		return probeGRPC(ctx, dial, address, probe)
+	}
+	return "", fmt.Errorf("unsupported probe type %q", probe.Type)
+}
+
+func probeHTTP(ctx context.Context, dial DialContextFunc, address string,
+	probe *apicontainer.HealthProbe) (string, error) {
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext:       dial,
+			DisableKeepAlives: true,
+		},
+		// Report redirects as they are, like other HTTP health checkers do
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+address+probe.Path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", probeUserAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	output := fmt.Sprintf("HTTP GET %s: %s", probe.Path, resp.Status)
+	if !probe.IsExpectedStatus(resp.StatusCode) {
+		return "", fmt.Errorf("%s: unexpected status", output)
+	}
+	return output, nil
+}
+
+func probeTCP(ctx context.Context, dial DialContextFunc, address string) (string, error) {
+	conn, err := dial(ctx, "tcp", address)
+	if err != nil {
+		return "", err
+	}
+	conn.Close()
+	return fmt.Sprintf("TCP connection to %s succeeded", address), nil
+}
+
+func probeGRPC(ctx context.Context, dial DialContextFunc, address string,
+	probe *apicontainer.HealthProbe) (string, error) {
+	conn, err := grpc.NewClient("passthrough:///"+address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return dial(ctx, "tcp", addr)
+		}),
+		grpc.WithUserAgent(probeUserAgent))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: probe.Service})
+	if err != nil {
+		return "", err
+	}
+	output := fmt.Sprintf("gRPC health check of service %q: %s", probe.Service, resp.GetStatus())
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return "", fmt.Errorf("%s: service is not serving", output)
+	}
+	return output, nil
+}
@@ -17,11 +17,16 @@ package engine
 
 import (
 	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 )
 
@@ -31,8 +36,18 @@ const (
 	MEMORY   = "MEMORY"
 	PORTSTCP = "PORTS_TCP"
 	PORTSUDP = "PORTS_UDP"
+	// EPHEMERALSTORAGE is the space in MiB available on the filesystem of the docker data root
+	EPHEMERALSTORAGE = "EPHEMERAL_STORAGE"
+	// ENI is the number of network interfaces for awsvpc tasks, branch ENIs with ENI trunking
+	ENI = "ENI"
+	// HUGEPAGES is the memory in MiB reserved for huge pages
+	HUGEPAGES = "HUGEPAGES"
 )
 
+// optionalHostResources are the host resources whose capacity may not be known. Tasks don't consume
+// them when the host resources don't have them.
+var optionalHostResources = []string{EPHEMERALSTORAGE, ENI, HUGEPAGES}
+
 // HostResourceManager keeps account of host resources allocated for tasks set to be created/running tasks
 type HostResourceManager struct {
 	initialHostResource       map[string]types.Resource
@@ -41,6 +56,9 @@ type HostResourceManager struct {
 
 	//task.arn to boolean whether host resources consumed or not
 	taskConsumed map[string]bool
+	// taskUsedCapacity maps the integer resources tasks use up gradually to the amounts each task already
+	// uses, which are part of the capacity of the resource until the task releases its resources
+	taskUsedCapacity map[string]map[string]int32
 }
 
 type InvalidHostResource struct {
@@ -53,15 +71,30 @@ func (e *InvalidHostResource) Error() string {
 
 func (h *HostResourceManager) logResources(msg string, taskArn string) {
 	logger.Debug(msg, logger.Fields{
-		"taskArn":   taskArn,
-		"CPU":       h.consumedResource[CPU].IntegerValue,
-		"MEMORY":    h.consumedResource[MEMORY].IntegerValue,
-		"PORTS_TCP": h.consumedResource[PORTSTCP].StringSetValue,
-		"PORTS_UDP": h.consumedResource[PORTSUDP].StringSetValue,
-		"GPU":       h.consumedResource[GPU].StringSetValue,
+		"taskArn":           taskArn,
+		"CPU":               h.consumedResource[CPU].IntegerValue,
+		"MEMORY":            h.consumedResource[MEMORY].IntegerValue,
+		"PORTS_TCP":         h.consumedResource[PORTSTCP].StringSetValue,
+		"PORTS_UDP":         h.consumedResource[PORTSUDP].StringSetValue,
+		"GPU":               h.consumedResource[GPU].StringSetValue,
+		"EPHEMERAL_STORAGE": h.consumedResource[EPHEMERALSTORAGE].IntegerValue,
+		"ENI":               h.consumedResource[ENI].IntegerValue,
+		"HUGEPAGES":         h.consumedResource[HUGEPAGES].IntegerValue,
 	})
 }
 
+// trackedResources returns the task resources without the optional resources the host doesn't have
+func (h *HostResourceManager) trackedResources(resources map[string]types.Resource) map[string]types.Resource {
+	tracked := make(map[string]types.Resource, len(resources))
+	for resourceKey, resource := range resources {
+		if _, ok := h.initialHostResource[resourceKey]; !ok && slices.Contains(optionalHostResources, resourceKey) {
+			continue
+		}
+		tracked[resourceKey] = resource
+	}
+	return tracked
+}
+
 func (h *HostResourceManager) consumeIntType(resourceType string, resources map[string]types.Resource) {
 	consumedResource := h.consumedResource[resourceType]
 	consumedResource.IntegerValue += resources[resourceType].IntegerValue
@@ -77,6 +110,68 @@ func (h *HostResourceManager) consumeStringSetType(resourceType string, resource
 	}
 }
 
+// addIntCapacity adds to the capacity of an integer resource of the host. Resources the host doesn't
+// have are left out.
+func (h *HostResourceManager) addIntCapacity(resourceType string, value int32) {
+	h.hostResourceManagerRWLock.Lock()
+	defer h.hostResourceManagerRWLock.Unlock()
+	resource, ok := h.initialHostResource[resourceType]
+	if !ok {
+		return
+	}
+	resource.IntegerValue += value
+	h.initialHostResource[resourceType] = resource
+}
+
+// refreshIntCapacity sets the capacity of an integer resource of the host which tasks use up gradually: the
+// amount still available on the host plus the amounts the tasks already use out of their consumption. The
+// amounts of tasks released in the meantime are left out. Resources the host doesn't have are left out.
+func (h *HostResourceManager) refreshIntCapacity(resourceType string, available int32, taskUsed map[string]int32) {
+	h.hostResourceManagerRWLock.Lock()
+	defer h.hostResourceManagerRWLock.Unlock()
+	resource, ok := h.initialHostResource[resourceType]
+	if !ok {
+		return
+	}
+	capacity := int64(available)
+	usedCapacity := make(map[string]int32)
+	for taskArn, used := range taskUsed {
+		if h.taskConsumed[taskArn] {
+			capacity += int64(used)
+			usedCapacity[taskArn] = used
+		}
+	}
+	resource.IntegerValue = int32(min(capacity, math.MaxInt32))
+	h.initialHostResource[resourceType] = resource
+	if h.taskUsedCapacity == nil {
+		h.taskUsedCapacity = make(map[string]map[string]int32)
+	}
+	h.taskUsedCapacity[resourceType] = usedCapacity
+}
+
+// releaseUsedCapacity removes the amounts a task uses from the capacity of the resources it uses up gradually,
+// as they are no longer part of its consumption
+func (h *HostResourceManager) releaseUsedCapacity(taskArn string) {
+	for resourceType, usedCapacity := range h.taskUsedCapacity {
+		used, ok := usedCapacity[taskArn]
+		if !ok {
+			continue
+		}
+		resource := h.initialHostResource[resourceType]
+		resource.IntegerValue -= used
+		h.initialHostResource[resourceType] = resource
+		delete(usedCapacity, taskArn)
+	}
+}
+
+// hasResource returns whether the host has a resource
+func (h *HostResourceManager) hasResource(resourceType string) bool {
+	h.hostResourceManagerRWLock.Lock()
+	defer h.hostResourceManagerRWLock.Unlock()
+	_, ok := h.initialHostResource[resourceType]
+	return ok
+}
+
 func (h *HostResourceManager) checkTaskConsumed(taskArn string) bool {
 	h.hostResourceManagerRWLock.Lock()
 	defer h.hostResourceManagerRWLock.Unlock()
@@ -101,6 +196,7 @@ func (h *HostResourceManager) consume(taskArn string, resources map[string]types
 		return true, nil
 	}
 
+	resources = h.trackedResources(resources)
 	ok, failedResourceKeys, err := h.consumable(resources)
 	if err != nil {
 		logger.Error("Resources failing to consume, error in task resources", logger.Fields{
@@ -227,6 +323,122 @@ func (h *HostResourceManager) consumable(resources map[string]types.Resource) (b
 	}
 }
 
+// ResourceAvailability compares a host resource requested by a task with what is free on the host.
+type ResourceAvailability struct {
+	Name string
+	// Requested and Free are the amounts of integer resources, e.g. CPU and MEMORY
+	Requested int32 `json:",omitempty"`
+	Free      int32 `json:",omitempty"`
+	// RequestedValues are the requested values of string set resources, ports and GPUs, and InUse the
+	// requested values which are consumed by other tasks
+	RequestedValues []string `json:",omitempty"`
+	InUse           []string `json:",omitempty"`
+	// Available is whether the requested resource can be consumed
+	Available bool
+}
+
+// String describes the availability of the resource, e.g. "CPU: requested 1024, 512 free"
+func (r ResourceAvailability) String() string {
+	if r.RequestedValues != nil {
+		if len(r.InUse) > 0 {
+			return fmt.Sprintf("%s: %s in use", r.Name, strings.Join(r.InUse, ","))
+		}
+		return fmt.Sprintf("%s: %s available", r.Name, strings.Join(r.RequestedValues, ","))
+	}
+	return fmt.Sprintf("%s: requested %d, %d free", r.Name, r.Requested, r.Free)
+}
+
+// availability compares the requested resources with the free host resources, sorted by resource name
+func (h *HostResourceManager) availability(resources map[string]types.Resource) []ResourceAvailability {
+	h.hostResourceManagerRWLock.Lock()
+	defer h.hostResourceManagerRWLock.Unlock()
+
+	resources = h.trackedResources(resources)
+	var availability []ResourceAvailability
+	for resourceKey, resource := range resources {
+		switch aws.ToString(resource.Type) {
+		case "INTEGER":
+			availability = append(availability, ResourceAvailability{
+				Name:      resourceKey,
+				Requested: resource.IntegerValue,
+				Free:      h.initialHostResource[resourceKey].IntegerValue - h.consumedResource[resourceKey].IntegerValue,
+				Available: h.checkConsumableIntType(resourceKey, resources),
+			})
+		case "STRINGSET":
+			if len(resource.StringSetValue) == 0 {
+				continue
+			}
+			inUse := []string{}
+			for _, value := range resource.StringSetValue {
+				if slices.Contains(h.consumedResource[resourceKey].StringSetValue, value) {
+					inUse = append(inUse, value)
+				}
+			}
+			availability = append(availability, ResourceAvailability{
+				Name:            resourceKey,
+				RequestedValues: resource.StringSetValue,
+				InUse:           inUse,
+				Available:       len(inUse) == 0,
+			})
+		}
+	}
+	sort.Slice(availability, func(i, j int) bool {
+		return availability[i].Name < availability[j].Name
+	})
+	return availability
+}
+
+// HostResourceStatus describes the capacity of a host resource and how much of it tasks consume.
+type HostResourceStatus struct {
+	Name string
+	// Capacity, Consumed and Free are the amounts of integer resources, e.g. CPU and MEMORY
+	Capacity *int32 `json:",omitempty"`
+	Consumed *int32 `json:",omitempty"`
+	Free     *int32 `json:",omitempty"`
+	// ConsumedValues are the values of string set resources which are consumed, either by tasks or, for
+	// ports, reserved on the host. FreeValues are the GPUs not consumed by tasks.
+	ConsumedValues []string `json:",omitempty"`
+	FreeValues     []string `json:",omitempty"`
+}
+
+// status returns the capacity and consumption of the host resources, sorted by resource name
+func (h *HostResourceManager) status() []HostResourceStatus {
+	h.hostResourceManagerRWLock.Lock()
+	defer h.hostResourceManagerRWLock.Unlock()
+
+	var status []HostResourceStatus
+	for resourceKey, resource := range h.initialHostResource {
+		consumed := h.consumedResource[resourceKey]
+		switch aws.ToString(resource.Type) {
+		case "INTEGER":
+			status = append(status, HostResourceStatus{
+				Name:     resourceKey,
+				Capacity: aws.Int32(resource.IntegerValue),
+				Consumed: aws.Int32(consumed.IntegerValue),
+				Free:     aws.Int32(resource.IntegerValue - consumed.IntegerValue),
+			})
+		case "STRINGSET":
+			resourceStatus := HostResourceStatus{
+				Name:           resourceKey,
+				ConsumedValues: slices.Clone(consumed.StringSetValue),
+			}
+			if resourceKey == GPU {
+				resourceStatus.FreeValues = []string{}
+				for _, value := range resource.StringSetValue {
+					if !slices.Contains(consumed.StringSetValue, value) {
+						resourceStatus.FreeValues = append(resourceStatus.FreeValues, value)
+					}
+				}
+			}
+			status = append(status, resourceStatus)
+		}
+	}
+	sort.Slice(status, func(i, j int) bool {
+		return status[i].Name < status[j].Name
+	})
+	return status
+}
+
 // Utility function to manage release of ports
 // s2 is contiguous sub slice of s1, each is unique (ports)
 // returns a slice after removing s2 from s1, if found
@@ -274,6 +486,7 @@ func (h *HostResourceManager) release(taskArn string, resources map[string]types
 	defer h.logResources("Consumed resources after task release call", taskArn)
 
 	if h.taskConsumed[taskArn] {
+		resources = h.trackedResources(resources)
 		err := h.checkResourcesHealth(resources)
 		if err != nil {
 			return err
@@ -288,6 +501,7 @@ func (h *HostResourceManager) release(taskArn string, resources map[string]types
 			}
 		}
 
+		h.releaseUsedCapacity(taskArn)
 		// Set consumed status
 		delete(h.taskConsumed, taskArn)
 	}
@@ -350,6 +564,15 @@ func NewHostResourceManager(resourceMap map[string]types.Resource) HostResourceM
 		StringSetValue: gpuIDs,
 	}
 
+	// EPHEMERAL_STORAGE, ENI, HUGEPAGES
+	for _, resourceKey := range optionalHostResources {
+		consumedResourceMap[resourceKey] = types.Resource{
+			Name:         utils.Strptr(resourceKey),
+			Type:         utils.Strptr("INTEGER"),
+			IntegerValue: int32(0),
+		}
+	}
+
 	logger.Info("Initializing host resource manager, initialHostResource", logger.Fields{"initialHostResource": resourceMap})
 	logger.Info("Initializing host resource manager, consumed resource", logger.Fields{"consumedResource": consumedResourceMap})
 	return HostResourceManager{
@@ -21,6 +21,7 @@ import (
 
 	"github.com/aws/amazon-ecs-agent/agent/utils"
 	commonutils "github.com/aws/amazon-ecs-agent/ecs-agent/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 
 	"github.com/stretchr/testify/assert"
@@ -290,3 +291,129 @@ func TestResourceHealthGPUFalse(t *testing.T) {
 	err := h.checkResourcesHealth(resources)
 	assert.Error(t, err, "Error in checking unhealthy resource map status")
 }
+
+func TestHostResourceAvailability(t *testing.T) {
+	h := getTestHostResourceManager(int32(2048), int32(2048), []string{"22"}, []string{}, []string{"gpu1", "gpu2"})
+	consumed, err := h.consume("arn1", getTestTaskResourceMap(int32(1536), int32(512), []string{"80"}, []string{},
+		[]string{"gpu1"}))
+	assert.NoError(t, err)
+	assert.True(t, consumed)
+
+	availability := h.availability(getTestTaskResourceMap(int32(1024), int32(1024), []string{"80", "443"},
+		[]string{}, []string{"gpu2"}))
+	assert.Equal(t, []ResourceAvailability{
+		{Name: "CPU", Requested: 1024, Free: 512},
+		{Name: "GPU", RequestedValues: []string{"gpu2"}, InUse: []string{}, Available: true},
+		{Name: "MEMORY", Requested: 1024, Free: 1536, Available: true},
+		{Name: "PORTS_TCP", RequestedValues: []string{"80", "443"}, InUse: []string{"80"}},
+	}, availability)
+	assert.Equal(t, "CPU: requested 1024, 512 free", availability[0].String())
+	assert.Equal(t, "GPU: gpu2 available", availability[1].String())
+	assert.Equal(t, "PORTS_TCP: 80 in use", availability[3].String())
+}
+
+func addTestIntegerResource(resources map[string]types.Resource, name string, value int32) {
+	resources[name] = types.Resource{
+		Name:         utils.Strptr(name),
+		Type:         utils.Strptr("INTEGER"),
+		IntegerValue: value,
+	}
+}
+
+func TestHostResourceConsumeAgentAccountedResources(t *testing.T) {
+	h := getTestHostResourceManager(int32(4096), int32(4096), []string{}, []string{}, []string{})
+	// The host has no huge pages, so HUGEPAGES isn't accounted for
+	addTestIntegerResource(h.initialHostResource, EPHEMERALSTORAGE, 2048)
+	addTestIntegerResource(h.initialHostResource, ENI, 2)
+
+	taskResources := func(storage, enis, hugePages int32) map[string]types.Resource {
+		resources := getTestTaskResourceMap(int32(512), int32(512), []string{}, []string{}, []string{})
+		for name, value := range map[string]int32{EPHEMERALSTORAGE: storage, ENI: enis, HUGEPAGES: hugePages} {
+			if value > 0 {
+				addTestIntegerResource(resources, name, value)
+			}
+		}
+		return resources
+	}
+
+	consumed, err := h.consume("arn1", taskResources(1536, 1, 64))
+	assert.NoError(t, err)
+	assert.True(t, consumed, "Task requesting resources the host doesn't account for should be consumed")
+
+	consumed, err = h.consume("arn2", taskResources(1024, 0, 0))
+	assert.NoError(t, err)
+	assert.False(t, consumed, "Ephemeral storage should not be overcommitted")
+
+	consumed, err = h.consume("arn3", taskResources(0, 1, 0))
+	assert.NoError(t, err)
+	assert.True(t, consumed)
+
+	consumed, err = h.consume("arn4", taskResources(0, 1, 0))
+	assert.NoError(t, err)
+	assert.False(t, consumed, "ENI slots should not be overcommitted")
+
+	assert.NoError(t, h.release("arn1", taskResources(1536, 1, 64)))
+	assert.Equal(t, int32(0), h.consumedResource[EPHEMERALSTORAGE].IntegerValue)
+	assert.Equal(t, int32(1), h.consumedResource[ENI].IntegerValue)
+	assert.Equal(t, int32(0), h.consumedResource[HUGEPAGES].IntegerValue)
+
+	consumed, err = h.consume("arn2", taskResources(1024, 0, 0))
+	assert.NoError(t, err)
+	assert.True(t, consumed)
+}
+
+func TestHostResourceAddIntCapacity(t *testing.T) {
+	h := getTestHostResourceManager(int32(4096), int32(4096), []string{}, []string{}, []string{})
+	addTestIntegerResource(h.initialHostResource, EPHEMERALSTORAGE, 1024)
+
+	h.addIntCapacity(EPHEMERALSTORAGE, 512)
+	assert.Equal(t, int32(1536), h.initialHostResource[EPHEMERALSTORAGE].IntegerValue)
+
+	// The host has no huge pages, so it doesn't get any
+	h.addIntCapacity(HUGEPAGES, 64)
+	_, ok := h.initialHostResource[HUGEPAGES]
+	assert.False(t, ok)
+}
+
+func TestHostResourceRefreshIntCapacity(t *testing.T) {
+	h := getTestHostResourceManager(int32(4096), int32(4096), []string{}, []string{}, []string{})
+	addTestIntegerResource(h.initialHostResource, EPHEMERALSTORAGE, 4096)
+	taskResources := getTestTaskResourceMap(int32(512), int32(512), []string{}, []string{}, []string{})
+	addTestIntegerResource(taskResources, EPHEMERALSTORAGE, 2048)
+	consumed, err := h.consume("arn1", taskResources)
+	assert.NoError(t, err)
+	assert.True(t, consumed)
+
+	// arn1 used 512 MiB out of its request, which is no longer available on the host; arn2 isn't consumed
+	h.refreshIntCapacity(EPHEMERALSTORAGE, 3072, map[string]int32{"arn1": 512, "arn2": 1024})
+	assert.Equal(t, int32(3584), h.initialHostResource[EPHEMERALSTORAGE].IntegerValue)
+	assert.Equal(t, int32(2048), h.consumedResource[EPHEMERALSTORAGE].IntegerValue)
+
+	// The space arn1 used is no longer part of the capacity once it releases its resources
+	assert.NoError(t, h.release("arn1", taskResources))
+	assert.Equal(t, int32(3072), h.initialHostResource[EPHEMERALSTORAGE].IntegerValue)
+	assert.Equal(t, int32(0), h.consumedResource[EPHEMERALSTORAGE].IntegerValue)
+
+	// The host has no huge pages, so it doesn't get any
+	h.refreshIntCapacity(HUGEPAGES, 64, nil)
+	assert.False(t, h.hasResource(HUGEPAGES))
+}
+
+func TestHostResourceStatus(t *testing.T) {
+	h := getTestHostResourceManager(int32(2048), int32(4096), []string{"22"}, []string{}, []string{"gpu1", "gpu2"})
+	addTestIntegerResource(h.initialHostResource, EPHEMERALSTORAGE, 10240)
+	taskResources := getTestTaskResourceMap(int32(1024), int32(512), []string{"80"}, []string{}, []string{"gpu2"})
+	addTestIntegerResource(taskResources, EPHEMERALSTORAGE, 1024)
+	consumed, err := h.consume("arn1", taskResources)
+	assert.NoError(t, err)
+	assert.True(t, consumed)
+
+	assert.Equal(t, []HostResourceStatus{
+		{Name: "CPU", Capacity: aws.Int32(2048), Consumed: aws.Int32(1024), Free: aws.Int32(1024)},
+		{Name: "EPHEMERAL_STORAGE", Capacity: aws.Int32(10240), Consumed: aws.Int32(1024), Free: aws.Int32(9216)},
+		{Name: "GPU", ConsumedValues: []string{"gpu2"}, FreeValues: []string{"gpu1"}},
+		{Name: "MEMORY", Capacity: aws.Int32(4096), Consumed: aws.Int32(512), Free: aws.Int32(3584)},
+		{Name: "PORTS_TCP", ConsumedValues: []string{"22", "80"}},
+		{Name: "PORTS_UDP", ConsumedValues: []string{}},
+	}, h.status())
+}
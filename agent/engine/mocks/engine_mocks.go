@@ -374,6 +374,18 @@ func (mr *MockImageManagerMockRecorder) RemoveContainerReferenceFromImageState(a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveContainerReferenceFromImageState", reflect.TypeOf((*MockImageManager)(nil).RemoveContainerReferenceFromImageState), arg0)
 }
 
+// RemoveUnusedImages mocks base method.
+func (m *MockImageManager) RemoveUnusedImages(arg0 context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RemoveUnusedImages", arg0)
+}
+
+// RemoveUnusedImages indicates an expected call of RemoveUnusedImages.
+func (mr *MockImageManagerMockRecorder) RemoveUnusedImages(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveUnusedImages", reflect.TypeOf((*MockImageManager)(nil).RemoveUnusedImages), arg0)
+}
+
 // SetDataClient mocks base method.
 func (m *MockImageManager) SetDataClient(arg0 data.Client) {
 	m.ctrl.T.Helper()
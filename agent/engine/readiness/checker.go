@@ -0,0 +1,36 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package readiness checks the readiness conditions of containers, which their dependent containers
+// wait for before they start.
+package readiness
+
+import (
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+)
+
+// Checker checks the readiness conditions of containers.
+type Checker interface {
+	// Check returns whether the container whose process is given meets the readiness condition.
+	Check(pid int, condition *apicontainer.ReadinessCondition) (bool, error)
+}
+
+type checker struct {
+	// procRoot is the mount point of the host procfs
+	procRoot string
+}
+
+// NewChecker returns a new Checker.
+func NewChecker() Checker {
+	return &checker{procRoot: hostProcRoot}
+}
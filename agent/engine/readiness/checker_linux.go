@@ -0,0 +1,144 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package readiness
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	hostProcRoot = "/host/proc"
+	// tcpListenState is the state of listening sockets in /proc/net/tcp, see include/net/tcp_states.h
+	tcpListenState = "0A"
+	// maxSymlinks is the largest number of symlinks followed when resolving a path, as in the kernel.
+	maxSymlinks = 40
+)
+
+// Check returns whether the container meets the readiness condition. Ports are looked up in the
+// socket tables of the network namespace of the process, so a container listening on any address of
+// the namespace meets the condition. Paths are looked up in the root filesystem of the process, which
+// includes the volumes mounted in the container, and symlinks are resolved within that filesystem.
+func (c *checker) Check(pid int, condition *apicontainer.ReadinessCondition) (bool, error) {
+	processRoot := filepath.Join(c.procRoot, strconv.Itoa(pid))
+	if condition.Path != "" {
+		_, err := resolveInRoot(filepath.Join(processRoot, "root"), condition.Path)
+		if os.IsNotExist(err) || errors.Is(err, syscall.ENOTDIR) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	for _, table := range []string{"tcp", "tcp6"} {
+		listening, err := isListening(filepath.Join(processRoot, "net", table), condition.Port)
+		if err != nil || listening {
+			return listening, err
+		}
+	}
+	return false, nil
+}
+
+// resolveInRoot returns the host path of the path inside the root filesystem, following symlinks as
+// the process would. Going through <root>/<path> directly would resolve absolute symlinks against the
+// host filesystem, since only the root itself is a link to the filesystem of the process.
+func resolveInRoot(root, path string) (string, error) {
+	current := "/"
+	remaining := path
+	links := 0
+	for remaining != "" {
+		var part string
+		if i := strings.IndexByte(remaining, '/'); i >= 0 {
+			part, remaining = remaining[:i], remaining[i+1:]
+		} else {
+			part, remaining = remaining, ""
+		}
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			// The parent of the root is the root itself.
+			current = filepath.Dir(current)
+			continue
+		}
+
+		next := filepath.Join(current, part)
+		info, err := os.Lstat(filepath.Join(root, next))
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			current = next
+			continue
+		}
+		links++
+		if links > maxSymlinks {
+			return "", errors.Errorf("too many levels of symbolic links in %s", path)
+		}
+		target, err := os.Readlink(filepath.Join(root, next))
+		if err != nil {
+			return "", err
+		}
+		if filepath.IsAbs(target) {
+			current = "/"
+		}
+		remaining = target + "/" + remaining
+	}
+	return filepath.Join(root, current), nil
+}
+
+// isListening returns whether a socket of the /proc/net/tcp socket table listens on the port. A
+// missing table, such as tcp6 when IPv6 is disabled, has no listening socket.
+func isListening(table string, port uint16) (bool, error) {
+	file, err := os.Open(table)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	// Skip the header
+	scanner.Scan()
+	for scanner.Scan() {
+		// Each line is "sl local_address rem_address st ...", where addresses are "<hex ip>:<hex port>"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[3] != tcpListenState {
+			continue
+		}
+		i := strings.LastIndex(fields[1], ":")
+		if i < 0 {
+			continue
+		}
+		localPort, err := strconv.ParseUint(fields[1][i+1:], 16, 16)
+		if err == nil && uint16(localPort) == port {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, errors.Wrapf(err, "unable to read %s", table)
+	}
+	return false, nil
+}
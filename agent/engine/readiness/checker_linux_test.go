@@ -0,0 +1,99 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package readiness
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	tcpTable = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1F91 0100007F:A2C4 01 00000000:00000000 00:00000000 00000000     0        0 2 1 0000000000000000 20 4 30 10 -1
+`
+	tcp6Table = `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:0050 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 3 1 0000000000000000 100 0 0 10 0
+`
+)
+
+func TestCheck(t *testing.T) {
+	procRoot := t.TempDir()
+	netDir := filepath.Join(procRoot, "1234", "net")
+	require.NoError(t, os.MkdirAll(netDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(netDir, "tcp"), []byte(tcpTable), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(netDir, "tcp6"), []byte(tcp6Table), 0644))
+	sharedDir := filepath.Join(procRoot, "1234", "root", "shared")
+	require.NoError(t, os.MkdirAll(sharedDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sharedDir, "ready"), nil, 0644))
+	rootDir := filepath.Join(procRoot, "1234", "root")
+	require.NoError(t, os.Symlink("/shared", filepath.Join(rootDir, "link")))
+	// The target of the symlink exists on the host, but not in the container.
+	hostDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(hostDir, "ready"), nil, 0644))
+	require.NoError(t, os.Symlink(hostDir, filepath.Join(rootDir, "hostlink")))
+
+	c := &checker{procRoot: procRoot}
+	testCases := []struct {
+		name      string
+		condition *apicontainer.ReadinessCondition
+		expected  bool
+	}{
+		{name: "listening on IPv4", condition: &apicontainer.ReadinessCondition{Port: 8080}, expected: true},
+		{name: "listening on IPv6", condition: &apicontainer.ReadinessCondition{Port: 80}, expected: true},
+		{name: "connected but not listening", condition: &apicontainer.ReadinessCondition{Port: 8081}},
+		{name: "not listening", condition: &apicontainer.ReadinessCondition{Port: 443}},
+		{name: "file exists", condition: &apicontainer.ReadinessCondition{Path: "/shared/ready"}, expected: true},
+		{name: "file doesn't exist", condition: &apicontainer.ReadinessCondition{Path: "/shared/done"}},
+		{name: "file behind symlink", condition: &apicontainer.ReadinessCondition{Path: "/link/ready"}, expected: true},
+		{name: "symlink to host path", condition: &apicontainer.ReadinessCondition{Path: "/hostlink/ready"}},
+		{name: "parent of root", condition: &apicontainer.ReadinessCondition{Path: "/../../shared/ready"}, expected: true},
+		{name: "file is not a directory", condition: &apicontainer.ReadinessCondition{Path: "/shared/ready/done"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ready, err := c.Check(1234, tc.condition)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ready)
+		})
+	}
+}
+
+func TestCheckWithoutIPv6(t *testing.T) {
+	procRoot := t.TempDir()
+	netDir := filepath.Join(procRoot, "1234", "net")
+	require.NoError(t, os.MkdirAll(netDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(netDir, "tcp"), []byte(tcpTable), 0644))
+
+	ready, err := (&checker{procRoot: procRoot}).Check(1234, &apicontainer.ReadinessCondition{Port: 80})
+	require.NoError(t, err)
+	assert.False(t, ready)
+}
+
+func TestResolveInRootSymlinkLoop(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.Symlink("/loop", filepath.Join(root, "loop")))
+
+	_, err := resolveInRoot(root, "/loop")
+	assert.Error(t, err)
+}
@@ -0,0 +1,30 @@
+//go:build !linux
+// +build !linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package readiness
+
+import (
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+
+	"github.com/pkg/errors"
+)
+
+const hostProcRoot = ""
+
+// Check fails, as readiness conditions are only supported on Linux
+func (c *checker) Check(int, *apicontainer.ReadinessCondition) (bool, error) {
+	return false, errors.New("readiness conditions are not supported on this platform")
+}
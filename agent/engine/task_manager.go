@@ -35,6 +35,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 	taskresourcevolume "github.com/aws/amazon-ecs-agent/agent/taskresource/volume"
+	"github.com/aws/amazon-ecs-agent/agent/utils"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
@@ -256,6 +257,8 @@ func (mtask *managedTask) overseeTask() {
 	mtask.engine.checkTearDownPauseContainer(mtask.Task)
 	// TODO [SC]: We need to also tear down pause containets in bridge mode for SC-enabled tasks
 	mtask.cleanupCredentials()
+	// The host ports of a stopped task can be assigned to other tasks
+	utils.ReleaseHostPorts(mtask.Arn)
 	// Send event to monitor queue task routine to check for any pending tasks to progress
 	mtask.engine.wakeUpTaskQueueMonitor()
 	// TODO: make this idempotent on agent restart
@@ -473,7 +476,8 @@ func (mtask *managedTask) handleContainerChange(containerChange dockerContainerC
 
 		// Only update container metadata when status stays RUNNING
 		if event.Status == containerKnownStatus && event.Status == apicontainerstatus.ContainerRunning {
-			updateContainerMetadata(&event.DockerContainerMetadata, container, mtask.Task)
+			updateContainerMetadata(&event.DockerContainerMetadata, container, mtask.Task,
+				mtask.containerChangeEventStream)
 		}
 		return
 	}
@@ -519,7 +523,8 @@ func (mtask *managedTask) handleContainerChange(containerChange dockerContainerC
 	// Update the container to be known
 	currentKnownStatus := containerKnownStatus
 	container.SetKnownStatus(event.Status)
-	updateContainerMetadata(&event.DockerContainerMetadata, container, mtask.Task)
+	updateContainerMetadata(&event.DockerContainerMetadata, container, mtask.Task, mtask.containerChangeEventStream)
+	mtask.engine.updateHealthProbe(mtask.Task, container, runtimeID)
 
 	if event.Error != nil {
 		proceedAnyway := mtask.handleEventError(containerChange, currentKnownStatus)
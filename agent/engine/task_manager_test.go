@@ -32,6 +32,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/data"
 	"github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi"
 	mock_dockerapi "github.com/aws/amazon-ecs-agent/agent/dockerclient/dockerapi/mocks"
+	mock_bandwidth "github.com/aws/amazon-ecs-agent/agent/engine/bandwidth/mocks"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dependencygraph"
 	mock_dockerstate "github.com/aws/amazon-ecs-agent/agent/engine/dockerstate/mocks"
 	mock_engine "github.com/aws/amazon-ecs-agent/agent/engine/mocks"
@@ -56,6 +57,7 @@ import (
 	mock_ttime "github.com/aws/amazon-ecs-agent/ecs-agent/utils/ttime/mocks"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/docker/docker/api/types"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -1964,6 +1966,71 @@ func TestHandleContainerChangeStopped_WithRestartPolicy(t *testing.T) {
 	assert.Equal(t, apitaskstatus.TaskRunning.String(), mTask.GetDesiredStatus().String(), "Expected task to be RUNNING since exited container should have restarted and task should be running")
 }
 
+// TestHandleContainerChangeStopped_WithRestartPolicyBandwidthLimits verifies that the bandwidth limits of a
+// bridge mode container are applied again when it is restarted, since the restart gives it a new veth.
+func TestHandleContainerChangeStopped_WithRestartPolicyBandwidthLimits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	containerChangeEventStream := eventstream.NewEventStream(t.Name(), ctx)
+	containerChangeEventStream.StartListening()
+
+	ctrl := gomock.NewController(t)
+	mockClient := mock_dockerapi.NewMockDockerClient(ctrl)
+	mockShaper := mock_bandwidth.NewMockShaper(ctrl)
+	defer ctrl.Finish()
+
+	cfg := getTestConfig()
+	hostResourceManager := NewHostResourceManager(getTestHostResources())
+	mTask := &managedTask{
+		Task:                       testdata.LoadTask("sleep5RestartPolicy"),
+		containerChangeEventStream: containerChangeEventStream,
+		stateChangeEvents:          make(chan statechange.Event),
+		ctx:                        context.TODO(),
+		engine: &DockerTaskEngine{
+			ctx:                 context.TODO(),
+			cfg:                 &cfg,
+			dataClient:          data.NewNoopClient(),
+			hostResourceManager: &hostResourceManager,
+			client:              mockClient,
+			bandwidthShaper:     mockShaper,
+		},
+	}
+	// Discard all the statechange events
+	defer discardEvents(mTask.stateChangeEvents)()
+
+	mTask.NetworkMode = apitask.BridgeNetworkMode
+	mTask.SetKnownStatus(apitaskstatus.TaskRunning)
+	mTask.SetSentStatus(apitaskstatus.TaskRunning)
+	container := mTask.Containers[0]
+	container.RestartTracker = restart.NewRestartTracker(*container.RestartPolicy)
+	limits := &apicontainer.BandwidthLimits{IngressBitsPerSecond: 8000000}
+	container.BandwidthLimits = limits
+
+	exitCode := int(100)
+	containerChange := dockerContainerChange{
+		container: container,
+		event: dockerapi.DockerContainerChangeEvent{
+			Status: apicontainerstatus.ContainerStopped,
+			DockerContainerMetadata: dockerapi.DockerContainerMetadata{
+				ExitCode: &exitCode,
+			},
+		},
+	}
+
+	gomock.InOrder(
+		mockClient.EXPECT().StartContainer(gomock.Any(), container.RuntimeID, gomock.Any()).
+			Return(dockerapi.DockerContainerMetadata{}),
+		mockClient.EXPECT().InspectContainer(gomock.Any(), container.RuntimeID, gomock.Any()).
+			Return(&types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{State: &types.ContainerState{Pid: 1234}},
+			}, nil),
+		mockShaper.EXPECT().ApplyToContainer(gomock.Any(), 1234, limits).Return(nil),
+	)
+	mTask.handleContainerChange(containerChange)
+	waitForRestartCount(container, 1)
+	assert.Equal(t, 1, container.RestartTracker.GetRestartCount(), "After stop event, container should have been restarted")
+}
+
 func TestHandleContainerChangeStopped_WithRestartPolicy_RestartFails(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
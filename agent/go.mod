@@ -26,6 +26,7 @@ require (
 	github.com/containerd/cgroups/v3 v3.0.4
 	github.com/containernetworking/cni v1.2.3
 	github.com/containernetworking/plugins v1.4.1
+	github.com/coreos/go-systemd/v22 v22.5.0
 	github.com/deniswernert/udev v0.0.0-20170418162847-a12666f7b5a1
 	github.com/didip/tollbooth v4.0.2+incompatible
 	github.com/docker/distribution v2.8.2+incompatible
@@ -47,6 +48,7 @@ require (
 	go.etcd.io/bbolt v1.3.10
 	golang.org/x/sys v0.30.0
 	golang.org/x/tools v0.27.0
+	google.golang.org/grpc v1.67.1
 	k8s.io/api v0.28.1
 )
 
@@ -68,7 +70,6 @@ require (
 	github.com/cilium/ebpf v0.16.0 // indirect
 	github.com/containerd/containerd v1.7.27 // indirect
 	github.com/containerd/log v0.1.0 // indirect
-	github.com/coreos/go-systemd/v22 v22.5.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/distribution/reference v0.6.0 // indirect
 	github.com/felixge/httpsnoop v1.0.4 // indirect
@@ -106,7 +107,6 @@ require (
 	golang.org/x/text v0.22.0 // indirect
 	golang.org/x/time v0.3.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20241104194629-dd2ea8efbc28 // indirect
-	google.golang.org/grpc v1.67.1 // indirect
 	google.golang.org/protobuf v1.35.2 // indirect
 	gopkg.in/inf.v0 v0.9.1 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
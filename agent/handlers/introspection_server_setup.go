@@ -22,6 +22,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/engine"
 	v1 "github.com/aws/amazon-ecs-agent/agent/handlers/v1"
+	"github.com/aws/amazon-ecs-agent/agent/netnsdiag"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/introspection"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
@@ -30,7 +31,9 @@ import (
 )
 
 // ServeIntrospectionHTTPEndpoint serves information about this agent/containerInstance and tasks running on it.
-func ServeIntrospectionHTTPEndpoint(ctx context.Context, containerInstanceArn *string, taskEngine engine.TaskEngine, cfg *config.Config) {
+// The drain endpoint is only served when drainRequester isn't nil.
+func ServeIntrospectionHTTPEndpoint(ctx context.Context, containerInstanceArn *string, taskEngine engine.TaskEngine,
+	cfg *config.Config, drainRequester v1.DrainRequester) {
 	// Is this the right level to type assert, assuming we'd abstract multiple taskengines here?
 	// Revisit if we ever add another type..
 	dockerTaskEngine := taskEngine.(*engine.DockerTaskEngine)
@@ -40,13 +43,21 @@ func ServeIntrospectionHTTPEndpoint(ctx context.Context, containerInstanceArn *s
 		TaskEngine:           dockerTaskEngine,
 	}
 
-	server, err := introspection.NewServer(
-		agentState,
-		metrics.NewNopEntryFactory(),
+	options := []introspection.ConfigOpt{
 		introspection.WithReadTimeout(readTimeout),
 		introspection.WithWriteTimeout(writeTimeout),
 		introspection.WithRuntimeStats(cfg.EnableRuntimeStats.Enabled()),
-	)
+		introspection.WithHandler(v1.HostPortsPath, v1.HostPortsHandler(cfg.DynamicHostPortRange)),
+		introspection.WithHandler(v1.HostResourcesPath, v1.HostResourcesHandler(dockerTaskEngine)),
+		introspection.WithHandler(v1.AdmissionQueuePath, v1.AdmissionQueueHandler(dockerTaskEngine)),
+		introspection.WithHandler(v1.DependencyGraphPath, v1.DependencyGraphHandler(dockerTaskEngine, cfg)),
+		introspection.WithHandler(v1.NetNSDiagnosticsPath,
+			v1.NetNSDiagnosticsHandler(dockerTaskEngine, netnsdiag.NewCollector())),
+	}
+	if drainRequester != nil {
+		options = append(options, introspection.WithHandler(v1.DrainPath, v1.DrainHandler(drainRequester)))
+	}
+	server, err := introspection.NewServer(agentState, metrics.NewNopEntryFactory(), options...)
 
 	if err != nil {
 		seelog.Criticalf("Failed to set up Introspection Server: %v", err)
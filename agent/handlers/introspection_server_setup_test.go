@@ -49,7 +49,7 @@ func TestServeIntrospectionHTTPEndpoint(t *testing.T) {
 		return fmt.Errorf("timed out waiting for server %s to come up: %w", serverAddress, err)
 	}
 
-	go ServeIntrospectionHTTPEndpoint(context.Background(), aws.String("test_container_instance_arn"), &engine.DockerTaskEngine{}, &config.Config{Cluster: clusterName})
+	go ServeIntrospectionHTTPEndpoint(context.Background(), aws.String("test_container_instance_arn"), &engine.DockerTaskEngine{}, &config.Config{Cluster: clusterName}, nil)
 
 	client := http.DefaultClient
 	err := waitForServer(client, serverAddress)
@@ -0,0 +1,53 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"encoding/json"
+
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	tp "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/taskprotection/v1/handlers"
+
+	"github.com/pkg/errors"
+)
+
+// taskProtectionStore saves the task protection records in the metadata of the agent data, so that the
+// renewals of task protection resume after the agent restarts.
+type taskProtectionStore struct {
+	dataClient data.Client
+}
+
+func (s taskProtectionStore) SaveTaskProtectionRecords(records []tp.PersistedTaskProtectionRecord) error {
+	val, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	return s.dataClient.SaveMetadata(data.TaskProtectionKey, string(val))
+}
+
+func (s taskProtectionStore) GetTaskProtectionRecords() ([]tp.PersistedTaskProtectionRecord, error) {
+	metadata, err := s.dataClient.GetAllMetadata()
+	if err != nil {
+		return nil, err
+	}
+	var records []tp.PersistedTaskProtectionRecord
+	val, ok := metadata[data.TaskProtectionKey]
+	if !ok {
+		return records, nil
+	}
+	if err := json.Unmarshal([]byte(val), &records); err != nil {
+		return nil, errors.Wrap(err, "unable to decode the task protection records")
+	}
+	return records, nil
+}
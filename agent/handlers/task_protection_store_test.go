@@ -0,0 +1,56 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	tp "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/taskprotection/v1/handlers"
+	tptypes "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/taskprotection/v1/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskProtectionStore(t *testing.T) {
+	dataClient, err := data.NewWithSetup(t.TempDir())
+	require.NoError(t, err)
+	defer dataClient.Close()
+	store := taskProtectionStore{dataClient: dataClient}
+
+	records, err := store.GetTaskProtectionRecords()
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	saved := []tp.PersistedTaskProtectionRecord{{
+		TaskProtectionRecord: tptypes.TaskProtectionRecord{
+			TaskARN:       taskARN,
+			RenewalPolicy: &tptypes.RenewalPolicy{MaxRenewals: aws.Int64(3)},
+		},
+		EndpointContainerID: endpointId,
+	}}
+	require.NoError(t, store.SaveTaskProtectionRecords(saved))
+	records, err = store.GetTaskProtectionRecords()
+	require.NoError(t, err)
+	assert.Equal(t, saved, records)
+
+	require.NoError(t, dataClient.SaveMetadata(data.TaskProtectionKey, "invalid"))
+	_, err = store.GetTaskProtectionRecords()
+	assert.Error(t, err)
+}
@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/data"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	tpfactory "github.com/aws/amazon-ecs-agent/agent/handlers/agentapi/taskprotection"
 	v2 "github.com/aws/amazon-ecs-agent/agent/handlers/v2"
@@ -57,6 +58,9 @@ const (
 
 	// Timeout for ECS calls. Must be lower than server write timeout defined above.
 	ecsCallTimeout = 4 * time.Second
+
+	// Interval at which task protection requested with a renewal policy is checked for renewal.
+	taskProtectionRenewalInterval = 30 * time.Second
 )
 
 func taskServerSetup(
@@ -72,7 +76,8 @@ func taskServerSetup(
 	vpcID string,
 	containerInstanceArn string,
 	taskProtectionClientFactory tp.TaskProtectionClientFactoryInterface,
-) (*http.Server, error) {
+	taskProtectionRecorder *tp.TaskProtectionRecorder,
+) (*http.Server, *v4.TMDSAgentState, error) {
 	muxRouter := mux.NewRouter()
 
 	// Set this to false so that for request like "//v3//metadata/task"
@@ -93,18 +98,19 @@ func taskServerSetup(
 		tmdsAgentState, metricsFactory)
 
 	agentAPIV1HandlersSetup(muxRouter, state, credentialsManager, cluster, tmdsAgentState,
-		taskProtectionClientFactory, metricsFactory)
+		taskProtectionClientFactory, taskProtectionRecorder, metricsFactory)
 
 	execWrapper := execwrapper.NewExec()
 	registerFaultHandlers(muxRouter, tmdsAgentState, metricsFactory, execWrapper)
 
-	return tmds.NewServer(auditLogger,
+	server, err := tmds.NewServer(auditLogger,
 		tmds.WithHandler(muxRouter),
 		tmds.WithListenAddress(tmds.AddressIPv4()),
 		tmds.WithReadTimeout(readTimeout),
 		tmds.WithWriteTimeout(writeTimeout),
 		tmds.WithSteadyStateRate(float64(steadyStateRate)),
 		tmds.WithBurstRate(burstRate))
+	return server, tmdsAgentState, err
 }
 
 // v2HandlersSetup adds all handlers in v2 package to the mux router.
@@ -178,20 +184,28 @@ func agentAPIV1HandlersSetup(
 	cluster string,
 	agentState *v4.TMDSAgentState,
 	factory tp.TaskProtectionClientFactoryInterface,
+	recorder *tp.TaskProtectionRecorder,
 	metricsFactory metrics.EntryFactory,
 ) {
 	muxRouter.
 		HandleFunc(
 			tp.TaskProtectionPath(),
 			tp.UpdateTaskProtectionHandler(agentState, credentialsManager,
-				factory, cluster, metricsFactory, ecsCallTimeout)).
+				factory, cluster, metricsFactory, ecsCallTimeout, recorder)).
 		Methods("PUT")
 	muxRouter.
 		HandleFunc(
 			tp.TaskProtectionPath(),
 			tp.GetTaskProtectionHandler(agentState, credentialsManager,
-				factory, cluster, metricsFactory, ecsCallTimeout)).
+				factory, cluster, metricsFactory, ecsCallTimeout, recorder)).
 		Methods("GET")
+	if recorder != nil {
+		muxRouter.
+			HandleFunc(
+				tp.TaskProtectionHistoryPath(),
+				tp.TaskProtectionHistoryHandler(agentState, recorder)).
+			Methods("GET")
+	}
 }
 
 // registerFaultHandlers adds handlers for fault endpoints
@@ -344,6 +358,7 @@ func ServeTaskHTTPEndpoint(
 	statsEngine stats.Engine,
 	availabilityZone string,
 	vpcID string,
+	dataClient data.Client,
 ) {
 	// Create and initialize the audit log
 	logger, err := seelog.LoggerFromConfigAsString(audit.AuditLoggerConfig(cfg))
@@ -358,14 +373,24 @@ func ServeTaskHTTPEndpoint(
 	taskProtectionClientFactory := tpfactory.TaskProtectionClientFactory{
 		Region: cfg.AWSRegion, Endpoint: cfg.APIEndpoint, AcceptInsecureCert: cfg.AcceptInsecureCert, IPCompatibility: cfg.InstanceIPCompatibility,
 	}
-	server, err := taskServerSetup(credentialsManager, auditLogger, state, ecsClient, cfg.Cluster,
+	taskProtectionRecorder, err := tp.NewPersistentTaskProtectionRecorder(taskProtectionStore{dataClient: dataClient})
+	if err != nil {
+		// The renewals of the protection requested before the agent restarted are lost, but task protection
+		// can still be updated.
+		seelog.Errorf("Unable to load the task protection records: %v", err)
+		taskProtectionRecorder = tp.NewTaskProtectionRecorder()
+	}
+	server, tmdsAgentState, err := taskServerSetup(credentialsManager, auditLogger, state, ecsClient, cfg.Cluster,
 		statsEngine, cfg.TaskMetadataSteadyStateRate, cfg.TaskMetadataBurstRate,
-		availabilityZone, vpcID, containerInstanceArn, taskProtectionClientFactory)
+		availabilityZone, vpcID, containerInstanceArn, taskProtectionClientFactory, taskProtectionRecorder)
 	if err != nil {
 		seelog.Criticalf("Failed to set up Task Metadata Server: %v", err)
 		return
 	}
 
+	go tp.StartTaskProtectionRenewer(ctx, taskProtectionRecorder, tmdsAgentState, credentialsManager,
+		taskProtectionClientFactory, cfg.Cluster, ecsCallTimeout, taskProtectionRenewalInterval)
+
 	go func() {
 		<-ctx.Done()
 		if err := server.Shutdown(context.Background()); err != nil {
@@ -1011,9 +1011,9 @@ func testErrorResponsesFromServer(t *testing.T, path string, expectedErrorMessag
 	credentialsManager := mock_credentials.NewMockManager(ctrl)
 	auditLog := mock_audit.NewMockAuditLogger(ctrl)
 	ecsClient := mock_ecs.NewMockECSClient(ctrl)
-	server, err := taskServerSetup(credentialsManager, auditLog, nil, ecsClient, "", nil,
+	server, _, err := taskServerSetup(credentialsManager, auditLog, nil, ecsClient, "", nil,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), tp.NewTaskProtectionRecorder())
 	require.NoError(t, err)
 
 	recorder := httptest.NewRecorder()
@@ -1048,9 +1048,9 @@ func getResponseForCredentialsRequest(t *testing.T, expectedStatus int,
 	credentialsManager := mock_credentials.NewMockManager(ctrl)
 	auditLog := mock_audit.NewMockAuditLogger(ctrl)
 	ecsClient := mock_ecs.NewMockECSClient(ctrl)
-	server, err := taskServerSetup(credentialsManager, auditLog, nil, ecsClient, "", nil,
+	server, _, err := taskServerSetup(credentialsManager, auditLog, nil, ecsClient, "", nil,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), tp.NewTaskProtectionRecorder())
 	require.NoError(t, err)
 
 	recorder := httptest.NewRecorder()
@@ -1107,9 +1107,9 @@ func TestV3ContainerAssociations(t *testing.T) {
 		state.EXPECT().ContainerByID(containerID).Return(dockerContainer, true),
 		state.EXPECT().TaskByArn(taskARN).Return(standardTask(), true),
 	)
-	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
+	server, _, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), tp.NewTaskProtectionRecorder())
 	require.NoError(t, err)
 	recorder := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", v3BasePath+v3EndpointID+"/associations/"+associationType, nil)
@@ -1139,9 +1139,9 @@ func TestV3ContainerAssociation(t *testing.T) {
 		state.EXPECT().TaskARNByV3EndpointID(v3EndpointID).Return(taskARN, true),
 		state.EXPECT().TaskByArn(taskARN).Return(task, true),
 	)
-	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
+	server, _, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), tp.NewTaskProtectionRecorder())
 	require.NoError(t, err)
 	recorder := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", v3BasePath+v3EndpointID+"/associations/"+associationType+"/"+associationName, nil)
@@ -1170,9 +1170,9 @@ func TestV4ContainerAssociations(t *testing.T) {
 		state.EXPECT().ContainerByID(containerID).Return(dockerContainer, true),
 		state.EXPECT().TaskByArn(taskARN).Return(task, true),
 	)
-	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
+	server, _, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), tp.NewTaskProtectionRecorder())
 	require.NoError(t, err)
 	recorder := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", v4BasePath+v3EndpointID+"/associations/"+associationType, nil)
@@ -1202,9 +1202,9 @@ func TestV4ContainerAssociation(t *testing.T) {
 		state.EXPECT().TaskARNByV3EndpointID(v3EndpointID).Return(taskARN, true),
 		state.EXPECT().TaskByArn(taskARN).Return(task, true),
 	)
-	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
+	server, _, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), tp.NewTaskProtectionRecorder())
 	require.NoError(t, err)
 	recorder := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", v4BasePath+v3EndpointID+"/associations/"+associationType+"/"+associationName, nil)
@@ -1229,9 +1229,9 @@ func TestTaskHTTPEndpoint301Redirect(t *testing.T) {
 	statsEngine := mock_stats.NewMockEngine(ctrl)
 	ecsClient := mock_ecs.NewMockECSClient(ctrl)
 
-	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
+	server, _, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), tp.NewTaskProtectionRecorder())
 	require.NoError(t, err)
 
 	for testPath, expectedPath := range testPathsMap {
@@ -1272,9 +1272,9 @@ func TestTaskHTTPEndpointErrorCode404(t *testing.T) {
 	statsEngine := mock_stats.NewMockEngine(ctrl)
 	ecsClient := mock_ecs.NewMockECSClient(ctrl)
 
-	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
+	server, _, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), tp.NewTaskProtectionRecorder())
 	require.NoError(t, err)
 
 	for _, testPath := range testPaths {
@@ -1312,9 +1312,9 @@ func TestTaskHTTPEndpointErrorCode400(t *testing.T) {
 	statsEngine := mock_stats.NewMockEngine(ctrl)
 	ecsClient := mock_ecs.NewMockECSClient(ctrl)
 
-	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
+	server, _, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), tp.NewTaskProtectionRecorder())
 	require.NoError(t, err)
 
 	for _, testPath := range testPaths {
@@ -1351,9 +1351,9 @@ func TestTaskHTTPEndpointErrorCode500(t *testing.T) {
 	statsEngine := mock_stats.NewMockEngine(ctrl)
 	ecsClient := mock_ecs.NewMockECSClient(ctrl)
 
-	server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
+	server, _, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+		containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), tp.NewTaskProtectionRecorder())
 	require.NoError(t, err)
 
 	for _, testPath := range testPaths {
@@ -1421,9 +1421,9 @@ func TestV4TaskNotFoundError404(t *testing.T) {
 			statsEngine := mock_stats.NewMockEngine(ctrl)
 			ecsClient := mock_ecs.NewMockECSClient(ctrl)
 
-			server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
+			server, _, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 				config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-				containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+				containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), tp.NewTaskProtectionRecorder())
 			require.NoError(t, err)
 
 			state.EXPECT().TaskARNByV3EndpointID(gomock.Any()).Return("", tc.taskFound).AnyTimes()
@@ -1477,9 +1477,9 @@ func TestV4Unexpected500Error(t *testing.T) {
 			statsEngine := mock_stats.NewMockEngine(ctrl)
 			ecsClient := mock_ecs.NewMockECSClient(ctrl)
 
-			server, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
+			server, _, err := taskServerSetup(credentials.NewManager(), auditLog, state, ecsClient, clusterName, statsEngine,
 				config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, "", vpcID,
-				containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl))
+				containerInstanceArn, tp.NewMockTaskProtectionClientFactoryInterface(ctrl), tp.NewTaskProtectionRecorder())
 			require.NoError(t, err)
 
 			// Initial lookups succeed
@@ -1584,10 +1584,10 @@ func testTMDSRequest[R TMDSResponse](t *testing.T, tc TMDSTestCase[R]) {
 	}
 
 	// Initialize server
-	server, err := taskServerSetup(credsManager, auditLog, state, ecsClient,
+	server, _, err := taskServerSetup(credsManager, auditLog, state, ecsClient,
 		clusterName, statsEngine,
 		config.DefaultTaskMetadataSteadyStateRate, config.DefaultTaskMetadataBurstRate, availabilityzone, vpcID,
-		containerInstanceArn, taskProtectionClientFactory)
+		containerInstanceArn, taskProtectionClientFactory, tp.NewTaskProtectionRecorder())
 	require.NoError(t, err)
 
 	// Create the request
@@ -3201,6 +3201,7 @@ func TestV4ContainerStats(t *testing.T) {
 				gomock.InOrder(
 					state.EXPECT().TaskARNByV3EndpointID(v3EndpointID).Return(taskARN, true),
 					state.EXPECT().DockerIDByV3EndpointID(v3EndpointID).Return(containerID, true),
+					state.EXPECT().ContainerByID(containerID).Return(nil, false),
 				)
 			},
 			setStatsEngineExpectations: func(engine *mock_stats.MockEngine) {
@@ -3214,6 +3215,41 @@ func TestV4ContainerStats(t *testing.T) {
 			},
 		})
 	})
+	t.Run("bandwidth limits", func(t *testing.T) {
+		dockerStats := types.StatsJSON{Stats: types.Stats{NumProcs: 2}}
+		dockerContainer := &apicontainer.DockerContainer{
+			DockerID: containerID,
+			Container: &apicontainer.Container{
+				Name: containerName,
+				BandwidthLimits: &apicontainer.BandwidthLimits{
+					IngressBitsPerSecond: 100000000,
+					EgressBitsPerSecond:  20000000,
+				},
+			},
+		}
+		testTMDSRequest(t, TMDSTestCase[v4.StatsResponse]{
+			path: path,
+			setStateExpectations: func(state *mock_dockerstate.MockTaskEngineState) {
+				gomock.InOrder(
+					state.EXPECT().TaskARNByV3EndpointID(v3EndpointID).Return(taskARN, true),
+					state.EXPECT().DockerIDByV3EndpointID(v3EndpointID).Return(containerID, true),
+					state.EXPECT().ContainerByID(containerID).Return(dockerContainer, true),
+				)
+			},
+			setStatsEngineExpectations: func(engine *mock_stats.MockEngine) {
+				engine.EXPECT().ContainerDockerStats(taskARN, containerID).
+					Return(&dockerStats, nil, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: v4.StatsResponse{
+				StatsJSON: &dockerStats,
+				Network_bandwidth_limits: &stats.NetworkBandwidthLimits{
+					IngressBitsPerSecond: 100000000,
+					EgressBitsPerSecond:  20000000,
+				},
+			},
+		})
+	})
 }
 
 func TestV4TaskStats(t *testing.T) {
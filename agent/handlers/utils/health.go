@@ -0,0 +1,34 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package utils
+
+import (
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	tmdsresponse "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/response"
+)
+
+// NewHealthHistoryResponse converts the health history of the given container to HealthCheckResultResponses.
+func NewHealthHistoryResponse(container *apicontainer.Container) []tmdsresponse.HealthCheckResultResponse {
+	var resp []tmdsresponse.HealthCheckResultResponse
+	for _, result := range container.GetHealthHistory() {
+		start, end := result.Start.UTC(), result.End.UTC()
+		resp = append(resp, tmdsresponse.HealthCheckResultResponse{
+			Start:    &start,
+			End:      &end,
+			ExitCode: result.ExitCode,
+			Output:   result.Output,
+		})
+	}
+	return resp
+}
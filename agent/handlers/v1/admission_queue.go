@@ -0,0 +1,40 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+const (
+	// AdmissionQueuePath is the introspection path of the tasks waiting for host resources.
+	AdmissionQueuePath        = "/v1/admission"
+	requestTypeAdmissionQueue = "introspection/admission"
+)
+
+// AdmissionQueueResolver returns the tasks waiting in the admission queue of the engine.
+type AdmissionQueueResolver interface {
+	GetAdmissionQueueStatus() *engine.AdmissionQueueStatus
+}
+
+// AdmissionQueueHandler creates the response for the '/v1/admission' API. It lists the tasks waiting for host
+// resources in the order they will be admitted, with the resources each task requested versus what is free.
+func AdmissionQueueHandler(resolver AdmissionQueueResolver) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tmdsutils.WriteJSONResponse(w, http.StatusOK, resolver.GetAdmissionQueueStatus(), requestTypeAdmissionQueue)
+	}
+}
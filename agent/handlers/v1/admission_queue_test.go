@@ -0,0 +1,64 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAdmissionQueueResolver struct {
+	status *engine.AdmissionQueueStatus
+}
+
+func (r *fakeAdmissionQueueResolver) GetAdmissionQueueStatus() *engine.AdmissionQueueStatus {
+	return r.status
+}
+
+func TestAdmissionQueueHandler(t *testing.T) {
+	status := &engine.AdmissionQueueStatus{
+		MaxWait: "1h0m0s",
+		Tasks: []engine.QueuedTask{
+			{
+				TaskARN:                taskARN,
+				PriorityClass:          "batch",
+				EffectivePriorityClass: "service",
+				Waited:                 "10m0s",
+				Resources: []engine.ResourceAvailability{
+					{Name: "CPU", Requested: 1024, Free: 512},
+				},
+			},
+		},
+	}
+
+	req, err := http.NewRequest("GET", AdmissionQueuePath, nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	AdmissionQueueHandler(&fakeAdmissionQueueResolver{status: status})(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var actual engine.AdmissionQueueStatus
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &actual))
+	assert.Equal(t, *status, actual)
+}
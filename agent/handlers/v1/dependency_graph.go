@@ -0,0 +1,82 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dependencygraph"
+	handlerutils "github.com/aws/amazon-ecs-agent/agent/handlers/utils"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+const (
+	// DependencyGraphPath is the introspection path of the dependency graph of a task.
+	DependencyGraphPath        = "/v1/dependencies"
+	requestTypeDependencyGraph = "introspection/dependencies"
+
+	formatQueryField = "format"
+	formatJSON       = "json"
+	formatDOT        = "dot"
+	dotContentType   = "text/vnd.graphviz"
+)
+
+// DependencyGraphHandler creates the response for the '/v1/dependencies?taskarn=<arn>' API. It returns the
+// dependency graph of the task: its containers and resources, the dependencies between them with their
+// state and blocking reason, and the dependency cycles. The graph is returned in JSON, or in the Graphviz
+// DOT language with 'format=dot'.
+func DependencyGraphHandler(
+	taskEngine handlerutils.DockerStateResolver,
+	cfg *config.Config,
+) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskARN, ok := tmdsutils.ValueFromRequest(r, taskARNQueryField)
+		if !ok {
+			logger.Error("Bad request for dependency graph: task ARN is required")
+			tmdsutils.WriteJSONResponse(w, http.StatusBadRequest, dependencygraph.Report{},
+				requestTypeDependencyGraph)
+			return
+		}
+		format := formatJSON
+		if value, ok := tmdsutils.ValueFromRequest(r, formatQueryField); ok {
+			format = value
+		}
+		if format != formatJSON && format != formatDOT {
+			logger.Error("Bad request for dependency graph: unknown format", logger.Fields{
+				"format": format,
+			})
+			tmdsutils.WriteJSONResponse(w, http.StatusBadRequest, dependencygraph.Report{},
+				requestTypeDependencyGraph)
+			return
+		}
+
+		task, found := taskEngine.State().TaskByArn(taskARN)
+		if !found {
+			tmdsutils.WriteJSONResponse(w, http.StatusNotFound, dependencygraph.Report{},
+				requestTypeDependencyGraph)
+			return
+		}
+
+		report := dependencygraph.NewReport(task, cfg)
+		if format == formatDOT {
+			w.Header().Set("Content-Type", dotContentType)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(report.DOT()))
+			return
+		}
+		tmdsutils.WriteJSONResponse(w, http.StatusOK, report, requestTypeDependencyGraph)
+	}
+}
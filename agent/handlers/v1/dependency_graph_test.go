@@ -0,0 +1,118 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/engine/dependencygraph"
+	mock_dockerstate "github.com/aws/amazon-ecs-agent/agent/engine/dockerstate/mocks"
+	mock_utils "github.com/aws/amazon-ecs-agent/agent/handlers/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependencyGraphHandler(t *testing.T) {
+	task := &apitask.Task{
+		Arn: taskARN,
+		Containers: []*apicontainer.Container{
+			{Name: "db"},
+			{Name: "app", DependsOnUnsafe: []apicontainer.DependsOn{{ContainerName: "db", Condition: "START"}}},
+		},
+	}
+
+	testCases := []struct {
+		name                string
+		query               string
+		task                *apitask.Task
+		expectLookup        bool
+		expectedStatus      int
+		expectedContentType string
+	}{
+		{
+			name:                "json",
+			query:               "?taskarn=" + taskARN,
+			task:                task,
+			expectLookup:        true,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+		},
+		{
+			name:                "dot",
+			query:               "?taskarn=" + taskARN + "&format=dot",
+			task:                task,
+			expectLookup:        true,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: dotContentType,
+		},
+		{
+			name:           "missing task arn",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown format",
+			query:          "?taskarn=" + taskARN + "&format=svg",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "task not found",
+			query:          "?taskarn=" + taskARN,
+			expectLookup:   true,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			taskEngine := mock_utils.NewMockDockerStateResolver(ctrl)
+			state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+			if tc.expectLookup {
+				taskEngine.EXPECT().State().Return(state)
+				state.EXPECT().TaskByArn(taskARN).Return(tc.task, tc.task != nil)
+			}
+
+			req, err := http.NewRequest("GET", DependencyGraphPath+tc.query, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			DependencyGraphHandler(taskEngine, &config.Config{})(recorder, req)
+			require.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tc.expectedContentType, recorder.Header().Get("Content-Type"))
+			expected := dependencygraph.NewReport(task, &config.Config{})
+			if tc.expectedContentType == dotContentType {
+				assert.Equal(t, expected.DOT(), recorder.Body.String())
+				return
+			}
+			var report dependencygraph.Report
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+			assert.Equal(t, *expected, report)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+const (
+	// DrainPath is the introspection path requesting the container instance to drain.
+	DrainPath        = "/v1/drain"
+	requestTypeDrain = "introspection/drain"
+
+	reasonQueryField = "reason"
+	defaultReason    = "no reason given"
+)
+
+// DrainRequester requests the container instance to drain.
+type DrainRequester interface {
+	RequestDrain(reason string)
+}
+
+// DrainResponse is the response of the drain API.
+type DrainResponse struct {
+	Requested bool   `json:"Requested"`
+	Reason    string `json:"Reason,omitempty"`
+}
+
+// DrainHandler creates the response for the 'PUT /v1/drain?reason=<reason>' API. It requests the container
+// instance to drain, which the drain-endpoint trigger of the drain policy does within a few seconds. The
+// introspection server listens on all interfaces, so only requests from the host itself are accepted.
+func DrainHandler(requester DrainRequester) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			tmdsutils.WriteJSONResponse(w, http.StatusMethodNotAllowed, DrainResponse{}, requestTypeDrain)
+			return
+		}
+		if !isLoopbackRequest(r) {
+			logger.Warn("Rejected drain request from a remote address", logger.Fields{
+				"remoteAddr": r.RemoteAddr,
+			})
+			tmdsutils.WriteJSONResponse(w, http.StatusForbidden, DrainResponse{}, requestTypeDrain)
+			return
+		}
+		reason, _ := tmdsutils.ValueFromRequest(r, reasonQueryField)
+		if reason == "" {
+			reason = defaultReason
+		}
+		requester.RequestDrain(reason)
+		tmdsutils.WriteJSONResponse(w, http.StatusAccepted, DrainResponse{Requested: true, Reason: reason},
+			requestTypeDrain)
+	}
+}
+
+// isLoopbackRequest returns whether the request comes from the host itself. The introspection server listens on
+// all interfaces, so the handlers acting on the host or on the network namespaces of tasks only serve local
+// requests.
+func isLoopbackRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	ip := net.ParseIP(host)
+	return err == nil && ip != nil && ip.IsLoopback()
+}
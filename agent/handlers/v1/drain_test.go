@@ -0,0 +1,97 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDrainRequester struct {
+	reasons []string
+}
+
+func (r *fakeDrainRequester) RequestDrain(reason string) {
+	r.reasons = append(r.reasons, reason)
+}
+
+func TestDrainHandler(t *testing.T) {
+	testCases := []struct {
+		name           string
+		method         string
+		url            string
+		remoteAddr     string
+		expectedStatus int
+		expectedReason string
+	}{
+		{
+			name:           "drain requested",
+			method:         http.MethodPut,
+			url:            DrainPath + "?reason=maintenance",
+			remoteAddr:     "127.0.0.1:40000",
+			expectedStatus: http.StatusAccepted,
+			expectedReason: "maintenance",
+		},
+		{
+			name:           "drain requested without reason",
+			method:         http.MethodPut,
+			url:            DrainPath,
+			remoteAddr:     "[::1]:40000",
+			expectedStatus: http.StatusAccepted,
+			expectedReason: defaultReason,
+		},
+		{
+			name:           "wrong method",
+			method:         http.MethodGet,
+			url:            DrainPath,
+			remoteAddr:     "127.0.0.1:40000",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "remote address",
+			method:         http.MethodPut,
+			url:            DrainPath,
+			remoteAddr:     "10.0.0.1:40000",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, tc.url, nil)
+			require.NoError(t, err)
+			req.RemoteAddr = tc.remoteAddr
+			recorder := httptest.NewRecorder()
+			requester := &fakeDrainRequester{}
+			DrainHandler(requester)(recorder, req)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedReason == "" {
+				assert.Empty(t, requester.reasons)
+				return
+			}
+			assert.Equal(t, []string{tc.expectedReason}, requester.reasons)
+			var resp DrainResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+			assert.Equal(t, DrainResponse{Requested: true, Reason: tc.expectedReason}, resp)
+		})
+	}
+}
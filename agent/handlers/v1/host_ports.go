@@ -0,0 +1,56 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+const (
+	// HostPortsPath is the introspection path of the host ports reserved for tasks.
+	HostPortsPath        = "/v1/hostports"
+	requestTypeHostPorts = "introspection/hostports"
+)
+
+// HostPortsResponse is the schema for the host ports introspection response.
+type HostPortsResponse struct {
+	DynamicHostPortRange string
+	Usage                []utils.HostPortUsage
+	Reservations         []*utils.HostPortReservation
+}
+
+// HostPortsHandler creates the response for the '/v1/hostports' API. It lists the host ports reserved for
+// each task and how many ports of the dynamic host port range are left, so that port exhaustion can be seen
+// before tasks fail to start.
+func HostPortsHandler(dynamicHostPortRange string) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		usage, err := utils.GetHostPortUsage(dynamicHostPortRange)
+		if err != nil {
+			// The reservations are still listed, only the usage summary is left out
+			logger.Warn("Unable to get host port usage", logger.Fields{
+				field.Error: err,
+			})
+		}
+		tmdsutils.WriteJSONResponse(w, http.StatusOK, &HostPortsResponse{
+			DynamicHostPortRange: dynamicHostPortRange,
+			Usage:                usage,
+			Reservations:         utils.GetHostPortReservations(),
+		}, requestTypeHostPorts)
+	}
+}
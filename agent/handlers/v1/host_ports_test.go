@@ -0,0 +1,57 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostPortsHandler(t *testing.T) {
+	reservation := &utils.HostPortReservation{
+		TaskARN: "arn:aws:ecs:us-west-2:1234567890:task/test-cluster/abc",
+		Ranges: []utils.HostPortRange{
+			{Protocol: "tcp", StartPort: 40000, EndPort: 40002},
+		},
+	}
+	utils.RestoreHostPortReservations([]*utils.HostPortReservation{reservation})
+	defer utils.RestoreHostPortReservations(nil)
+
+	req, err := http.NewRequest("GET", HostPortsPath, nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	HostPortsHandler("40000-40009")(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response HostPortsResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, HostPortsResponse{
+		DynamicHostPortRange: "40000-40009",
+		Usage: []utils.HostPortUsage{
+			{Protocol: "tcp", Total: 10, Reserved: 3, Available: 7},
+			{Protocol: "udp", Total: 10, Reserved: 0, Available: 10},
+		},
+		Reservations: []*utils.HostPortReservation{reservation},
+	}, response)
+}
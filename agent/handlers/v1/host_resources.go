@@ -0,0 +1,47 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"net/http"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+const (
+	// HostResourcesPath is the introspection path of the host resources accounted for tasks.
+	HostResourcesPath        = "/v1/hostresources"
+	requestTypeHostResources = "introspection/hostresources"
+)
+
+// HostResourcesResolver returns the host resources accounted for tasks by the engine.
+type HostResourcesResolver interface {
+	GetHostResourceStatus() []engine.HostResourceStatus
+}
+
+// HostResourcesResponse is the schema for the host resources introspection response.
+type HostResourcesResponse struct {
+	Resources []engine.HostResourceStatus
+}
+
+// HostResourcesHandler creates the response for the '/v1/hostresources' API. It lists the capacity of each
+// host resource the agent accounts for when admitting tasks, how much of it tasks consume and what is left.
+func HostResourcesHandler(resolver HostResourcesResolver) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tmdsutils.WriteJSONResponse(w, http.StatusOK, &HostResourcesResponse{
+			Resources: resolver.GetHostResourceStatus(),
+		}, requestTypeHostResources)
+	}
+}
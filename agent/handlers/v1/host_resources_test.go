@@ -0,0 +1,57 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHostResourcesResolver struct {
+	status []engine.HostResourceStatus
+}
+
+func (r *fakeHostResourcesResolver) GetHostResourceStatus() []engine.HostResourceStatus {
+	return r.status
+}
+
+func TestHostResourcesHandler(t *testing.T) {
+	status := []engine.HostResourceStatus{
+		{Name: "CPU", Capacity: aws.Int32(2048), Consumed: aws.Int32(2048), Free: aws.Int32(0)},
+		{Name: "EPHEMERAL_STORAGE", Capacity: aws.Int32(10240), Consumed: aws.Int32(1024), Free: aws.Int32(9216)},
+		{Name: "PORTS_TCP", ConsumedValues: []string{"22"}},
+	}
+
+	req, err := http.NewRequest("GET", HostResourcesPath, nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	HostResourcesHandler(&fakeHostResourcesResolver{status: status})(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"Free":0`, "Exhausted resources should report no free capacity")
+	var actual HostResourcesResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &actual))
+	assert.Equal(t, status, actual.Resources)
+}
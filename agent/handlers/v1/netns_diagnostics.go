@@ -0,0 +1,100 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"net/http"
+
+	handlerutils "github.com/aws/amazon-ecs-agent/agent/handlers/utils"
+	"github.com/aws/amazon-ecs-agent/agent/netnsdiag"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	tmdsutils "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/utils"
+)
+
+const (
+	// NetNSDiagnosticsPath is the introspection path of the network namespace diagnostics of awsvpc tasks.
+	NetNSDiagnosticsPath        = "/v1/netns"
+	requestTypeNetNSDiagnostics = "introspection/netns"
+
+	taskARNQueryField = "taskarn"
+)
+
+// NetNSDiagnosticsHandler creates the response for the '/v1/netns?taskarn=<arn>' API. It returns the
+// interfaces, addresses, routes, neighbors, resolv.conf, hosts, iptables and tc configuration of the
+// network namespace of an awsvpc task. The optional 'dns=<name>' and 'tcp=<host:port>' query parameters,
+// which can be repeated, run connectivity checks from inside the network namespace. Only requests from the host
+// itself are served, as the introspection server listens on all interfaces.
+func NetNSDiagnosticsHandler(
+	taskEngine handlerutils.DockerStateResolver,
+	collector netnsdiag.Collector,
+) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopbackRequest(r) {
+			logger.Warn("Rejected network namespace diagnostics request from a remote address", logger.Fields{
+				"remoteAddr": r.RemoteAddr,
+			})
+			tmdsutils.WriteJSONResponse(w, http.StatusForbidden, netnsdiag.Diagnostics{},
+				requestTypeNetNSDiagnostics)
+			return
+		}
+
+		taskARN, ok := tmdsutils.ValueFromRequest(r, taskARNQueryField)
+		if !ok {
+			logger.Error("Bad request for network namespace diagnostics: task ARN is required")
+			tmdsutils.WriteJSONResponse(w, http.StatusBadRequest, netnsdiag.Diagnostics{},
+				requestTypeNetNSDiagnostics)
+			return
+		}
+
+		var checks []netnsdiag.ConnectivityCheck
+		for _, checkType := range []string{netnsdiag.CheckTypeDNS, netnsdiag.CheckTypeTCP} {
+			for _, target := range r.URL.Query()[checkType] {
+				check := netnsdiag.ConnectivityCheck{Type: checkType, Target: target}
+				if err := check.Validate(); err != nil {
+					logger.Error("Bad request for network namespace diagnostics", logger.Fields{
+						field.TaskARN: taskARN,
+						field.Error:   err,
+					})
+					tmdsutils.WriteJSONResponse(w, http.StatusBadRequest, netnsdiag.Diagnostics{},
+						requestTypeNetNSDiagnostics)
+					return
+				}
+				checks = append(checks, check)
+			}
+		}
+
+		task, found := taskEngine.State().TaskByArn(taskARN)
+		if !found {
+			tmdsutils.WriteJSONResponse(w, http.StatusNotFound, netnsdiag.Diagnostics{},
+				requestTypeNetNSDiagnostics)
+			return
+		}
+		netNSPath := task.GetNetworkNamespace()
+		if !task.IsNetworkModeAWSVPC() || netNSPath == "" {
+			// Only the network namespaces of awsvpc tasks are known to the agent, and only once the
+			// pause container is running.
+			logger.Warn("Network namespace of task not available for diagnostics", logger.Fields{
+				field.TaskARN:     taskARN,
+				field.NetworkMode: task.GetNetworkMode(),
+			})
+			tmdsutils.WriteJSONResponse(w, http.StatusConflict, netnsdiag.Diagnostics{},
+				requestTypeNetNSDiagnostics)
+			return
+		}
+
+		tmdsutils.WriteJSONResponse(w, http.StatusOK, collector.Collect(r.Context(), netNSPath, checks),
+			requestTypeNetNSDiagnostics)
+	}
+}
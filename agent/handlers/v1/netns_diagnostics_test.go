@@ -0,0 +1,142 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	mock_dockerstate "github.com/aws/amazon-ecs-agent/agent/engine/dockerstate/mocks"
+	mock_utils "github.com/aws/amazon-ecs-agent/agent/handlers/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/netnsdiag"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCollector records the diagnostics requests and returns empty diagnostics.
+type fakeCollector struct {
+	netNSPath string
+	checks    []netnsdiag.ConnectivityCheck
+}
+
+func (c *fakeCollector) Collect(
+	_ context.Context,
+	netNSPath string,
+	checks []netnsdiag.ConnectivityCheck,
+) *netnsdiag.Diagnostics {
+	c.netNSPath = netNSPath
+	c.checks = checks
+	return &netnsdiag.Diagnostics{NetNSPath: netNSPath}
+}
+
+func TestNetNSDiagnosticsHandler(t *testing.T) {
+	const netNSPath = "/host/proc/1234/ns/net"
+	awsvpcTask := &apitask.Task{Arn: taskARN, NetworkMode: apitask.AWSVPCNetworkMode}
+	awsvpcTask.SetNetworkNamespace(netNSPath)
+
+	testCases := []struct {
+		name           string
+		query          string
+		remoteAddr     string
+		task           *apitask.Task
+		expectLookup   bool
+		expectedStatus int
+		expectedChecks []netnsdiag.ConnectivityCheck
+	}{
+		{
+			name:           "happy case",
+			query:          "?taskarn=" + taskARN + "&dns=example.com&tcp=10.0.0.1:443&tcp=10.0.0.2:80",
+			task:           awsvpcTask,
+			expectLookup:   true,
+			expectedStatus: http.StatusOK,
+			expectedChecks: []netnsdiag.ConnectivityCheck{
+				{Type: netnsdiag.CheckTypeDNS, Target: "example.com"},
+				{Type: netnsdiag.CheckTypeTCP, Target: "10.0.0.1:443"},
+				{Type: netnsdiag.CheckTypeTCP, Target: "10.0.0.2:80"},
+			},
+		},
+		{
+			name:           "missing task arn",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "empty check target",
+			query:          "?taskarn=" + taskARN + "&tcp=",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "task not found",
+			query:          "?taskarn=" + taskARN,
+			expectLookup:   true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "bridge task",
+			query:          "?taskarn=" + taskARN,
+			task:           &apitask.Task{Arn: taskARN, NetworkMode: apitask.BridgeNetworkMode},
+			expectLookup:   true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "remote address",
+			query:          "?taskarn=" + taskARN + "&tcp=10.0.0.1:443",
+			remoteAddr:     "10.0.0.1:40000",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			taskEngine := mock_utils.NewMockDockerStateResolver(ctrl)
+			state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+			if tc.expectLookup {
+				taskEngine.EXPECT().State().Return(state)
+				state.EXPECT().TaskByArn(taskARN).Return(tc.task, tc.task != nil)
+			}
+			collector := &fakeCollector{}
+
+			req, err := http.NewRequest("GET", NetNSDiagnosticsPath+tc.query, nil)
+			require.NoError(t, err)
+			req.RemoteAddr = "127.0.0.1:40000"
+			if tc.remoteAddr != "" {
+				req.RemoteAddr = tc.remoteAddr
+			}
+			recorder := httptest.NewRecorder()
+			NetNSDiagnosticsHandler(taskEngine, collector)(recorder, req)
+			require.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedStatus != http.StatusOK {
+				assert.Empty(t, collector.netNSPath)
+				return
+			}
+
+			var response netnsdiag.Diagnostics
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			assert.Equal(t, netNSPath, response.NetNSPath)
+			assert.Equal(t, netNSPath, collector.netNSPath)
+			assert.Equal(t, tc.expectedChecks, collector.checks)
+		})
+	}
+}
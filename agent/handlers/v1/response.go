@@ -16,6 +16,7 @@ package v1
 import (
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	handlerutils "github.com/aws/amazon-ecs-agent/agent/handlers/utils"
 	v1 "github.com/aws/amazon-ecs-agent/ecs-agent/introspection/v1"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
 	tmdsresponse "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/response"
@@ -87,6 +88,9 @@ func NewContainerResponse(dockerContainer *apicontainer.DockerContainer, eni *ni
 		restartCount := container.RestartTracker.GetRestartCount()
 		resp.RestartCount = &restartCount
 	}
+	if container.HealthStatusShouldBeReported() {
+		resp.HealthHistory = handlerutils.NewHealthHistoryResponse(container)
+	}
 	return resp
 }
 
@@ -18,10 +18,13 @@ package v1
 
 import (
 	"testing"
+	"time"
 
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/container/restart"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	tmdsresponse "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/response"
 
 	"github.com/docker/docker/api/types"
 	"github.com/stretchr/testify/assert"
@@ -82,6 +85,25 @@ func TestContainerResponseWithRestartPolicy(t *testing.T) {
 	assert.Equal(t, expectedContainerResponseWithRestartPolicy, containerResponse)
 }
 
+func TestContainerResponseWithHealthHistory(t *testing.T) {
+	container := testContainer()
+	container.HealthCheckType = apicontainer.DockerHealthCheckType
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Second)
+	container.SetHealthStatus(apicontainer.HealthStatus{
+		Status: apicontainerstatus.ContainerUnhealthy,
+		Results: []apicontainer.HealthCheckResult{
+			{Start: start, End: end, ExitCode: 1, Output: "connection refused"},
+		},
+	})
+
+	containerResponse := NewContainerResponse(testDockerContainer(container), nil)
+
+	assert.Equal(t, []tmdsresponse.HealthCheckResultResponse{
+		{Start: &start, End: &end, ExitCode: 1, Output: "connection refused"},
+	}, containerResponse.HealthHistory)
+}
+
 func TestPortBindingsResponse(t *testing.T) {
 	container := &apicontainer.Container{
 		Name: containerName,
@@ -46,34 +46,33 @@ func GetContainerNetworkMetadata(containerID string, state dockerstate.TaskEngin
 	if len(settings.Networks) > 0 {
 		for modeFromSettings, containerNetwork := range settings.Networks {
 			networkMode := modeFromSettings
-			ipv4Addresses := []string{containerNetwork.IPAddress}
-			var ipv6Addresses []string
-			if containerNetwork.GlobalIPv6Address != "" {
-				ipv6Addresses = []string{containerNetwork.GlobalIPv6Address}
-			}
 			network := tmdsv4.Network{
 				Network: tmdsresponse.Network{
 					NetworkMode:   networkMode,
-					IPv4Addresses: ipv4Addresses,
-					IPv6Addresses: ipv6Addresses,
+					IPv4Addresses: addressList(containerNetwork.IPAddress),
+					IPv6Addresses: addressList(containerNetwork.GlobalIPv6Address),
 				},
 			}
 			networks = append(networks, network)
 		}
 	} else {
-		ipv4Addresses := []string{ipv4AddressFromSettings}
-		var ipv6Addresses []string
-		if ipv6AddressFromSettings != "" {
-			ipv6Addresses = []string{ipv6AddressFromSettings}
-		}
 		network := tmdsv4.Network{
 			Network: tmdsresponse.Network{
 				NetworkMode:   networkModeFromHostConfig,
-				IPv4Addresses: ipv4Addresses,
-				IPv6Addresses: ipv6Addresses,
+				IPv4Addresses: addressList(ipv4AddressFromSettings),
+				IPv6Addresses: addressList(ipv6AddressFromSettings),
 			},
 		}
 		networks = append(networks, network)
 	}
 	return networks, nil
 }
+
+// addressList returns the address as a list, or nil if the container has no address of the IP family, such as
+// the IPv4 address of a bridge mode container on an IPv6-only instance
+func addressList(address string) []string {
+	if address == "" {
+		return nil
+	}
+	return []string{address}
+}
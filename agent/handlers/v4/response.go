@@ -17,6 +17,7 @@ import (
 	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
+	handlerutils "github.com/aws/amazon-ecs-agent/agent/handlers/utils"
 	v2 "github.com/aws/amazon-ecs-agent/agent/handlers/v2"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
@@ -147,9 +148,26 @@ func augmentContainerResponse(
 		restartCount := dockerContainer.Container.RestartTracker.GetRestartCount()
 		v4Response.RestartCount = &restartCount
 	}
+	if dockerContainer.Container.HealthStatusShouldBeReported() {
+		v4Response.HealthHistory = handlerutils.NewHealthHistoryResponse(dockerContainer.Container)
+	}
+	v4Response.MemoryControls = newMemoryControlsResponse(dockerContainer.Container.MemoryControls)
 	return v4Response
 }
 
+// newMemoryControlsResponse creates the MemoryControls object for the memory controls of a task or a
+// container. It returns nil if there are no memory controls.
+func newMemoryControlsResponse(controls *apicontainer.MemoryControls) *tmdsv4.MemoryControls {
+	if controls == nil {
+		return nil
+	}
+	return &tmdsv4.MemoryControls{
+		MemoryHighMiBs:    controls.HighMiB,
+		MemorySwapMaxMiBs: controls.SwapMaxMiB,
+		OOMGroup:          controls.OOMGroup,
+	}
+}
+
 // newNetworkInterfaceProperties creates the NetworkInterfaceProperties object for a given
 // task.
 func newNetworkInterfaceProperties(task *apitask.Task) (tmdsv4.NetworkInterfaceProperties, error) {
@@ -28,8 +28,10 @@ import (
 	mock_ecs "github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs/mocks"
 	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	ni "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	tmdsv4 "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v4/state"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -159,3 +161,105 @@ func TestNewTaskContainerResponses(t *testing.T) {
 	assert.Equal(t, "192.168.0.0/24", containerResponse.Networks[0].IPV4SubnetCIDRBlock)
 	assert.Equal(t, subnetGatewayIPV4Address, containerResponse.Networks[0].SubnetGatewayIPV4Address)
 }
+
+func TestGetContainerNetworkMetadataBridgeMode(t *testing.T) {
+	const (
+		bridgeIPv4Address = "172.17.0.2"
+		bridgeIPv6Address = "2001:db8:1::242:ac11:2"
+	)
+	tcs := []struct {
+		name         string
+		ipv4Address  string
+		ipv6Address  string
+		expectedIPv4 []string
+		expectedIPv6 []string
+	}{
+		{
+			name:         "IPv4-only",
+			ipv4Address:  bridgeIPv4Address,
+			expectedIPv4: []string{bridgeIPv4Address},
+		},
+		{
+			name:         "dual stack",
+			ipv4Address:  bridgeIPv4Address,
+			ipv6Address:  bridgeIPv6Address,
+			expectedIPv4: []string{bridgeIPv4Address},
+			expectedIPv6: []string{bridgeIPv6Address},
+		},
+		{
+			name:         "IPv6-only",
+			ipv6Address:  bridgeIPv6Address,
+			expectedIPv6: []string{bridgeIPv6Address},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			for _, withNetworks := range []bool{true, false} {
+				container := &apicontainer.Container{Name: containerName}
+				container.SetNetworkMode(apitask.BridgeNetworkMode)
+				settings := &types.NetworkSettings{
+					DefaultNetworkSettings: types.DefaultNetworkSettings{
+						IPAddress:         tc.ipv4Address,
+						GlobalIPv6Address: tc.ipv6Address,
+					},
+				}
+				if withNetworks {
+					settings = &types.NetworkSettings{
+						Networks: map[string]*network.EndpointSettings{
+							apitask.BridgeNetworkMode: {
+								IPAddress:         tc.ipv4Address,
+								GlobalIPv6Address: tc.ipv6Address,
+							},
+						},
+					}
+				}
+				container.SetNetworkSettings(settings)
+				state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+				state.EXPECT().ContainerByID(containerID).Return(&apicontainer.DockerContainer{
+					DockerID:  containerID,
+					Container: container,
+				}, true)
+
+				networks, err := GetContainerNetworkMetadata(containerID, state)
+				require.NoError(t, err)
+				require.Len(t, networks, 1)
+				assert.Equal(t, apitask.BridgeNetworkMode, networks[0].NetworkMode)
+				assert.Equal(t, tc.expectedIPv4, networks[0].IPv4Addresses)
+				assert.Equal(t, tc.expectedIPv6, networks[0].IPv6Addresses)
+			}
+		})
+	}
+}
+
+func TestContainerResponseWithMemoryControls(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	state := mock_dockerstate.NewMockTaskEngineState(ctrl)
+	task := &apitask.Task{Arn: taskARN}
+	swapMax := int64(256)
+	oomGroup := true
+	dockerContainer := &apicontainer.DockerContainer{
+		DockerID:   containerID,
+		DockerName: containerName,
+		Container: &apicontainer.Container{
+			Name:           containerName,
+			MemoryControls: &apicontainer.MemoryControls{SwapMaxMiB: &swapMax, OOMGroup: &oomGroup},
+		},
+	}
+	state.EXPECT().ContainerByID(containerID).Return(dockerContainer, true).AnyTimes()
+	state.EXPECT().TaskByID(containerID).Return(task, true).AnyTimes()
+
+	containerResponse, err := NewContainerResponse(containerID, state)
+	require.NoError(t, err)
+	assert.Equal(t, &tmdsv4.MemoryControls{MemorySwapMaxMiBs: &swapMax, OOMGroup: &oomGroup},
+		containerResponse.MemoryControls)
+	data, err := json.Marshal(containerResponse.MemoryControls)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"MemorySwapMax":256,"OOMGroup":true}`, string(data))
+
+	assert.Nil(t, newMemoryControlsResponse(nil))
+}
@@ -14,8 +14,10 @@
 package v4
 
 import (
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
 	"github.com/aws/amazon-ecs-agent/agent/engine/dockerstate"
 	"github.com/aws/amazon-ecs-agent/agent/stats"
+	ecsstats "github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 	response "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v4/state"
 
 	"github.com/cihub/seelog"
@@ -46,8 +48,9 @@ func NewV4TaskStatsResponse(taskARN string,
 		}
 
 		statsResponse := response.StatsResponse{
-			StatsJSON:          dockerStats,
-			Network_rate_stats: network_rate_stats,
+			StatsJSON:                dockerStats,
+			Network_rate_stats:       network_rate_stats,
+			Network_bandwidth_limits: newNetworkBandwidthLimits(dockerContainer.Container),
 		}
 
 		resp[containerID] = &statsResponse
@@ -55,3 +58,14 @@ func NewV4TaskStatsResponse(taskARN string,
 
 	return resp, nil
 }
+
+// newNetworkBandwidthLimits returns the network bandwidth limits which apply to the container, if any.
+func newNetworkBandwidthLimits(container *apicontainer.Container) *ecsstats.NetworkBandwidthLimits {
+	if container == nil || container.BandwidthLimits == nil {
+		return nil
+	}
+	return &ecsstats.NetworkBandwidthLimits{
+		IngressBitsPerSecond: container.BandwidthLimits.IngressBitsPerSecond,
+		EgressBitsPerSecond:  container.BandwidthLimits.EgressBitsPerSecond,
+	}
+}
@@ -156,6 +156,7 @@ func (s *TMDSAgentState) getTaskMetadata(v3EndpointID string, includeTags bool,
 	}
 
 	taskResponse.FaultInjectionEnabled = task.IsFaultInjectionEnabled()
+	taskResponse.MemoryControls = newMemoryControlsResponse(task.GetMemoryControls())
 	if includeTaskNetworkConfig {
 		var taskNetworkConfig *tmdsv4.TaskNetworkConfig
 		if task.IsNetworkModeHost() {
@@ -193,10 +194,14 @@ func (s *TMDSAgentState) GetContainerStats(v3EndpointID string) (tmdsv4.StatsRes
 			err)
 	}
 
-	return tmdsv4.StatsResponse{
+	statsResponse := tmdsv4.StatsResponse{
 		StatsJSON:          dockerStats,
 		Network_rate_stats: network_rate_stats,
-	}, nil
+	}
+	if dockerContainer, ok := s.state.ContainerByID(containerID); ok {
+		statsResponse.Network_bandwidth_limits = newNetworkBandwidthLimits(dockerContainer.Container)
+	}
+	return statsResponse, nil
 }
 
 func (s *TMDSAgentState) GetTaskStats(v3EndpointID string) (map[string]*tmdsv4.StatsResponse, error) {
@@ -0,0 +1,22 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+// Package hostresources discovers the capacity of the host resources which the ECS backend doesn't report,
+// so that the agent can account for them when admitting tasks.
+package hostresources
+
+const (
+	// hostProcRoot is where the proc filesystem of the host is mounted in the agent container
+	hostProcRoot = "/host/proc"
+	// bytesPerMiB converts sizes in bytes to MiB
+	bytesPerMiB = 1024 * 1024
+)
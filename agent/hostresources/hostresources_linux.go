@@ -0,0 +1,94 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package hostresources
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// HostPath returns the path through which a path of the host filesystem is reached from the agent container,
+// i.e. through the root of the host init process, as the agent container doesn't mount the whole host filesystem.
+func HostPath(path string) string {
+	return filepath.Join(hostProcRoot, "1", "root", path)
+}
+
+// EphemeralStorageMiB returns the space in MiB available on the filesystem of the docker data root. The
+// space already used by images and containers, and the blocks reserved for root, are not available.
+func EphemeralStorageMiB(dockerRootDir string) (int32, error) {
+	return AvailableDiskSpaceMiB(HostPath(dockerRootDir))
+}
+
+// AvailableDiskSpaceMiB returns the space in MiB available to unprivileged users on the filesystem of a path.
+func AvailableDiskSpaceMiB(path string) (int32, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return 0, errors.Wrapf(err, "unable to get the available space of %s", path)
+	}
+	return toMiB(stat.Bavail * uint64(stat.Bsize)), nil
+}
+
+// HugePagesMiB returns the memory in MiB of the huge pages reserved on the host.
+func HugePagesMiB() (int32, error) {
+	file, err := os.Open(filepath.Join(hostProcRoot, "meminfo"))
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to read meminfo")
+	}
+	defer file.Close()
+	return parseHugePagesMiB(file)
+}
+
+// parseHugePagesMiB parses the number and the size of the huge pages from meminfo, e.g.
+//
+//	HugePages_Total:      16
+//	Hugepagesize:       2048 kB
+func parseHugePagesMiB(meminfo io.Reader) (int32, error) {
+	var pages, pageSizeKiB uint64
+	scanner := bufio.NewScanner(meminfo)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		var err error
+		switch fields[0] {
+		case "HugePages_Total:":
+			pages, err = strconv.ParseUint(fields[1], 10, 64)
+		case "Hugepagesize:":
+			pageSizeKiB, err = strconv.ParseUint(fields[1], 10, 64)
+		}
+		if err != nil {
+			return 0, errors.Wrapf(err, "unable to parse meminfo line %q", scanner.Text())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, errors.Wrap(err, "unable to read meminfo")
+	}
+	return toMiB(pages * pageSizeKiB * 1024), nil
+}
+
+// toMiB converts a size in bytes to MiB, capped to the largest host resource value
+func toMiB(bytes uint64) int32 {
+	return int32(min(bytes/bytesPerMiB, math.MaxInt32))
+}
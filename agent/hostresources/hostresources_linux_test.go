@@ -0,0 +1,79 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package hostresources
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHugePagesMiB(t *testing.T) {
+	testCases := []struct {
+		name     string
+		meminfo  string
+		expected int32
+	}{
+		{
+			name: "huge pages reserved",
+			meminfo: `MemTotal:       16101452 kB
+HugePages_Total:      16
+HugePages_Free:       16
+Hugepagesize:       2048 kB
+Hugetlb:           32768 kB`,
+			expected: 32,
+		},
+		{
+			name: "no huge pages",
+			meminfo: `MemTotal:       16101452 kB
+HugePages_Total:       0
+Hugepagesize:       2048 kB`,
+			expected: 0,
+		},
+		{
+			name:     "huge pages not supported",
+			meminfo:  "MemTotal:       16101452 kB",
+			expected: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hugePages, err := parseHugePagesMiB(strings.NewReader(tc.meminfo))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, hugePages)
+		})
+	}
+}
+
+func TestParseHugePagesMiBInvalid(t *testing.T) {
+	_, err := parseHugePagesMiB(strings.NewReader("HugePages_Total:      lots"))
+	assert.Error(t, err)
+}
+
+func TestAvailableDiskSpaceMiB(t *testing.T) {
+	_, err := AvailableDiskSpaceMiB(t.TempDir())
+	assert.NoError(t, err)
+
+	_, err = AvailableDiskSpaceMiB(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestHostPath(t *testing.T) {
+	assert.Equal(t, "/host/proc/1/root/var/lib/docker", HostPath("/var/lib/docker"))
+}
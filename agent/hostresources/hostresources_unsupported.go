@@ -0,0 +1,40 @@
+//go:build !linux
+// +build !linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package hostresources
+
+import (
+	"github.com/pkg/errors"
+)
+
+// HostPath returns the path unchanged, as the agent isn't run in a container on this platform
+func HostPath(path string) string {
+	return path
+}
+
+// EphemeralStorageMiB fails, as ephemeral storage is only accounted for on Linux
+func EphemeralStorageMiB(string) (int32, error) {
+	return 0, errors.New("ephemeral storage is not accounted for on this platform")
+}
+
+// HugePagesMiB fails, as huge pages are only accounted for on Linux
+func HugePagesMiB() (int32, error) {
+	return 0, errors.New("huge pages are not accounted for on this platform")
+}
+
+// AvailableDiskSpaceMiB fails, as the available disk space is only checked on Linux
+func AvailableDiskSpaceMiB(string) (int32, error) {
+	return 0, errors.New("available disk space is not checked on this platform")
+}
@@ -0,0 +1,116 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localtasks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/api"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/amazon-ecs-agent/agent/statechange"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+)
+
+// Event is a state change of a task or a container. Events are written to the event log as JSON lines.
+type Event struct {
+	Time      time.Time `json:"time"`
+	TaskARN   string    `json:"taskArn"`
+	Container string    `json:"container,omitempty"`
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason,omitempty"`
+	ExitCode  *int      `json:"exitCode,omitempty"`
+}
+
+// HandleEngineEvents writes the state changes of the task engine to the event log until ctx is cancelled.
+// It takes the place of the submission of the state changes to ECS, and marks them as sent.
+func HandleEngineEvents(ctx context.Context, taskEngine engine.TaskEngine, dataClient data.Client,
+	eventLog io.Writer) {
+	encoder := json.NewEncoder(eventLog)
+	stateChangeEvents := taskEngine.StateChangeEvents()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case change := <-stateChangeEvents:
+			event, ok := handleEngineEvent(change, dataClient)
+			if !ok {
+				continue
+			}
+			if err := encoder.Encode(event); err != nil {
+				logger.Error("Unable to write to the local task event log", logger.Fields{
+					field.Error: err,
+				})
+			}
+		}
+	}
+}
+
+// handleEngineEvent marks a state change as sent and returns its event. It returns false for the state
+// changes which aren't logged.
+func handleEngineEvent(change statechange.Event, dataClient data.Client) (Event, bool) {
+	switch change := change.(type) {
+	case api.TaskStateChange:
+		if change.Task != nil {
+			change.Task.SetSentStatus(change.Status)
+			if err := dataClient.SaveTask(change.Task); err != nil {
+				logger.Error("Failed to save data for task", logger.Fields{
+					field.TaskARN: change.TaskARN,
+					field.Error:   err,
+				})
+			}
+		}
+		for _, containerChange := range change.Containers {
+			setContainerSent(containerChange, dataClient)
+		}
+		logger.Info("Local task state change", change.ToFields())
+		return Event{
+			Time:    time.Now(),
+			TaskARN: change.TaskARN,
+			Status:  change.Status.String(),
+			Reason:  change.Reason,
+		}, true
+	case api.ContainerStateChange:
+		setContainerSent(change, dataClient)
+		logger.Info("Local container state change", change.ToFields())
+		return Event{
+			Time:      time.Now(),
+			TaskARN:   change.TaskArn,
+			Container: change.ContainerName,
+			Status:    change.Status.String(),
+			Reason:    change.Reason,
+			ExitCode:  change.ExitCode,
+		}, true
+	default:
+		return Event{}, false
+	}
+}
+
+func setContainerSent(change api.ContainerStateChange, dataClient data.Client) {
+	if change.Container == nil || change.Container.GetSentStatus() >= change.Status {
+		return
+	}
+	change.Container.SetSentStatus(change.Status)
+	if err := dataClient.SaveContainer(change.Container); err != nil {
+		logger.Error("Failed to save data for container", logger.Fields{
+			field.TaskARN:   change.TaskArn,
+			field.Container: change.ContainerName,
+			field.Error:     err,
+		})
+	}
+}
@@ -0,0 +1,92 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package localtasks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/api"
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/data"
+	mock_engine "github.com/aws/amazon-ecs-agent/agent/engine/mocks"
+	"github.com/aws/amazon-ecs-agent/agent/statechange"
+	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleEngineEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	taskEngine := mock_engine.NewMockTaskEngine(ctrl)
+	stateChangeEvents := make(chan statechange.Event)
+	taskEngine.EXPECT().StateChangeEvents().Return(stateChangeEvents)
+
+	container := &apicontainer.Container{Name: "app"}
+	task := &apitask.Task{Arn: "arn:aws:ecs:us-west-2:000000000000:task/edge/id", Containers: []*apicontainer.Container{container}}
+	exitCode := 1
+
+	var eventLog bytes.Buffer
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		HandleEngineEvents(ctx, taskEngine, data.NewNoopClient(), &eventLog)
+		close(done)
+	}()
+	stateChangeEvents <- api.ContainerStateChange{
+		TaskArn:       task.Arn,
+		ContainerName: container.Name,
+		Status:        apicontainerstatus.ContainerStopped,
+		ExitCode:      &exitCode,
+		Container:     container,
+	}
+	stateChangeEvents <- api.TaskStateChange{
+		TaskARN: task.Arn,
+		Status:  apitaskstatus.TaskStopped,
+		Reason:  "Essential container in task exited",
+		Task:    task,
+	}
+	// an event is handled once the next one is received
+	stateChangeEvents <- api.AttachmentStateChange{}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the event handler to exit")
+	}
+
+	assert.Equal(t, apicontainerstatus.ContainerStopped, container.GetSentStatus())
+	assert.Equal(t, apitaskstatus.TaskStopped, task.GetSentStatus())
+	decoder := json.NewDecoder(&eventLog)
+	var containerEvent, taskEvent Event
+	require.NoError(t, decoder.Decode(&containerEvent))
+	assert.Equal(t, "app", containerEvent.Container)
+	assert.Equal(t, "STOPPED", containerEvent.Status)
+	assert.Equal(t, &exitCode, containerEvent.ExitCode)
+	require.NoError(t, decoder.Decode(&taskEvent))
+	assert.Equal(t, task.Arn, taskEvent.TaskARN)
+	assert.Equal(t, "STOPPED", taskEvent.Status)
+	assert.Equal(t, "Essential container in task exited", taskEvent.Reason)
+	assert.False(t, decoder.More())
+}
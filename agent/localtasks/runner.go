@@ -0,0 +1,304 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package localtasks runs the tasks described by the task definition files of a local directory, without
+// the ECS control plane: the files are the desired state of the tasks, and the state changes of the tasks
+// are written to a local event log.
+package localtasks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/agent/engine"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/fsnotify/fsnotify"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	taskDefinitionFileExt = ".json"
+	// localTaskAccountID is the account ID of the ARNs of the local tasks, which tells them apart from the
+	// tasks received from ECS
+	localTaskAccountID = "000000000000"
+	// versionLength is the length of the versions of the local tasks, which are prefixes of the SHA-256 hash
+	// of their task definition files
+	versionLength         = 12
+	defaultResyncInterval = time.Minute
+
+	// The tasks which stop are started again after a backoff, which is reset once a task has run for
+	// restartBackoffMax. The runner checks for tasks to start every resync interval.
+	restartBackoffMin      = time.Minute
+	restartBackoffMax      = 30 * time.Minute
+	restartBackoffJitter   = 0.2
+	restartBackoffMultiple = 2
+)
+
+// taskDefinition is a parsed task definition file. The task is nil when the file is invalid.
+type taskDefinition struct {
+	version string
+	task    *ecsacs.Task
+}
+
+// restartState tracks the restarts of the tasks of a family.
+type restartState struct {
+	version   string        // version of the task definition file of the last task started
+	backoff   retry.Backoff // backoff between restarts of the tasks of the version
+	startedAt time.Time     // when the last task was started
+	nextStart time.Time     // when the next task may be started, zero until the last task has stopped
+}
+
+// Runner keeps the local tasks in sync with the task definition files of a directory. Each file runs one
+// task, whose family is the name of the file without the .json extension:
+//   - the task is started when the file is created, and started again, after a backoff, when it stops
+//   - the task is replaced when the file changes
+//   - the task is stopped when the file is removed
+//
+// The running task is left as is while its file is invalid.
+type Runner struct {
+	dir            string
+	region         string
+	cluster        string
+	taskEngine     engine.TaskEngine
+	resyncInterval time.Duration
+	newTaskID      func() string    // function that returns a new task ID (injected for testing)
+	now            func() time.Time // function that returns current time (injected for testing)
+	restarts       map[string]*restartState
+}
+
+// NewRunner creates a runner for the task definition files of a directory.
+func NewRunner(dir, region, cluster string, taskEngine engine.TaskEngine) *Runner {
+	return &Runner{
+		dir:            dir,
+		region:         region,
+		cluster:        cluster,
+		taskEngine:     taskEngine,
+		resyncInterval: defaultResyncInterval,
+		newTaskID: func() string {
+			return strings.Replace(uuid.New(), "-", "", -1)
+		},
+		now:      time.Now,
+		restarts: make(map[string]*restartState),
+	}
+}
+
+// Start syncs the local tasks with the task definition files whenever the directory changes, and
+// periodically, until ctx is cancelled.
+func (r *Runner) Start(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "unable to create the local tasks directory watcher")
+	}
+	defer watcher.Close()
+	if err := watcher.Add(r.dir); err != nil {
+		return errors.Wrapf(err, "unable to watch the local tasks directory %s", r.dir)
+	}
+	ticker := time.NewTicker(r.resyncInterval)
+	defer ticker.Stop()
+	for {
+		r.sync()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-watcher.Events:
+		case err := <-watcher.Errors:
+			logger.Warn("Error watching the local tasks directory", logger.Fields{
+				"dir":       r.dir,
+				field.Error: err,
+			})
+		}
+	}
+}
+
+// sync starts, replaces and stops the local tasks according to the task definition files.
+func (r *Runner) sync() {
+	taskDefs, err := r.readTaskDefinitions()
+	if err != nil {
+		logger.Error("Unable to read the local tasks directory", logger.Fields{
+			"dir":       r.dir,
+			field.Error: err,
+		})
+		return
+	}
+	active, stopping := r.localTasks()
+	for family, task := range active {
+		taskDef, ok := taskDefs[family]
+		if ok && (taskDef.task == nil || taskDef.version == task.Version) {
+			continue
+		}
+		logger.Info("Stopping local task", logger.Fields{
+			field.TaskARN:     task.Arn,
+			"family":          family,
+			field.TaskVersion: task.Version,
+		})
+		// Only the task ARN and desired status are required to stop the task
+		r.taskEngine.UpsertTask(&apitask.Task{
+			Arn:                 task.Arn,
+			DesiredStatusUnsafe: apitaskstatus.TaskStopped,
+		})
+		stopping[family] = true
+	}
+	for family := range r.restarts {
+		if _, ok := taskDefs[family]; !ok {
+			delete(r.restarts, family)
+		}
+	}
+	for family, taskDef := range taskDefs {
+		// The new task is started once the one it replaces has stopped, so that they don't conflict
+		if _, ok := active[family]; ok || stopping[family] || taskDef.task == nil {
+			continue
+		}
+		if !r.mayStart(family, taskDef.version) {
+			continue
+		}
+		r.startTask(family, taskDef)
+	}
+}
+
+// mayStart returns whether a task of a family may be started. The first task of a version of the task
+// definition file is started right away, and the next ones once the restart backoff has elapsed since
+// the last one stopped. The stopped tasks are kept by the task engine until they're cleaned up, the
+// backoff also keeps a task which stops right away from piling them up.
+func (r *Runner) mayStart(family, version string) bool {
+	now := r.now()
+	state, ok := r.restarts[family]
+	if !ok || state.version != version {
+		r.restarts[family] = &restartState{
+			version: version,
+			backoff: retry.NewExponentialBackoff(restartBackoffMin, restartBackoffMax,
+				restartBackoffJitter, restartBackoffMultiple),
+			startedAt: now,
+		}
+		return true
+	}
+	if state.nextStart.IsZero() {
+		if now.Sub(state.startedAt) >= restartBackoffMax {
+			state.backoff.Reset()
+		}
+		state.nextStart = now.Add(state.backoff.Duration())
+		logger.Info("Local task stopped, restarting it after a backoff", logger.Fields{
+			"family":  family,
+			"restart": state.nextStart.Format(time.RFC3339),
+		})
+	}
+	if now.Before(state.nextStart) {
+		return false
+	}
+	state.startedAt = now
+	state.nextStart = time.Time{}
+	return true
+}
+
+// startTask adds a new task of a task definition file to the task engine.
+func (r *Runner) startTask(family string, taskDef taskDefinition) {
+	acsTask := taskDef.task
+	acsTask.Arn = aws.String(r.taskARN(r.newTaskID()))
+	acsTask.Family = aws.String(family)
+	acsTask.Version = aws.String(taskDef.version)
+	acsTask.DesiredStatus = aws.String(apitaskstatus.TaskRunning.String())
+	task, err := apitask.TaskFromACS(acsTask, nil)
+	if err != nil {
+		logger.Error("Invalid local task", logger.Fields{
+			"family":    family,
+			field.Error: err,
+		})
+		return
+	}
+	logger.Info("Starting local task", logger.Fields{
+		field.TaskARN:     task.Arn,
+		"family":          family,
+		field.TaskVersion: task.Version,
+	})
+	r.taskEngine.AddTask(task)
+}
+
+// readTaskDefinitions reads the task definition files of the directory, by task family.
+func (r *Runner) readTaskDefinitions() (map[string]taskDefinition, error) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return nil, err
+	}
+	taskDefs := make(map[string]taskDefinition)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != taskDefinitionFileExt {
+			continue
+		}
+		path := filepath.Join(r.dir, name)
+		family := strings.TrimSuffix(name, taskDefinitionFileExt)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.Error("Unable to read local task definition file", logger.Fields{
+				"file":      path,
+				field.Error: err,
+			})
+			taskDefs[family] = taskDefinition{}
+			continue
+		}
+		hash := sha256.Sum256(data)
+		taskDef := taskDefinition{version: hex.EncodeToString(hash[:])[:versionLength]}
+		if taskDef.task, err = parseTaskDefinition(data); err != nil {
+			logger.Error("Invalid local task definition file", logger.Fields{
+				"file":      path,
+				field.Error: err,
+			})
+		}
+		taskDefs[family] = taskDef
+	}
+	return taskDefs, nil
+}
+
+// localTasks returns the local tasks of the task engine by family: the active tasks, which are meant to
+// run, and the families of the tasks which are stopping.
+func (r *Runner) localTasks() (map[string]*apitask.Task, map[string]bool) {
+	active := make(map[string]*apitask.Task)
+	stopping := make(map[string]bool)
+	tasks, _ := r.taskEngine.ListTasks()
+	for _, task := range tasks {
+		taskARN, err := arn.Parse(task.Arn)
+		if err != nil || taskARN.AccountID != localTaskAccountID {
+			continue
+		}
+		if task.GetDesiredStatus() != apitaskstatus.TaskStopped {
+			active[task.Family] = task
+		} else if task.GetKnownStatus() != apitaskstatus.TaskStopped {
+			stopping[task.Family] = true
+		}
+	}
+	return active, stopping
+}
+
+func (r *Runner) taskARN(taskID string) string {
+	return arn.ARN{
+		Partition: "aws",
+		Service:   "ecs",
+		Region:    r.region,
+		AccountID: localTaskAccountID,
+		Resource:  "task/" + r.cluster + "/" + taskID,
+	}.String()
+}
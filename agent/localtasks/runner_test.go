@@ -0,0 +1,196 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package localtasks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	mock_engine "github.com/aws/amazon-ecs-agent/agent/engine/mocks"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRegion     = "us-west-2"
+	testCluster    = "edge"
+	testTaskID     = "0123456789abcdef0123456789abcdef"
+	testTaskDefApp = `{"containerDefinitions": [{"name": "app", "image": "app:1"}]}`
+)
+
+func newTestRunner(t *testing.T, taskEngine *mock_engine.MockTaskEngine) (*Runner, string) {
+	dir := t.TempDir()
+	runner := NewRunner(dir, testRegion, testCluster, taskEngine)
+	runner.newTaskID = func() string {
+		return testTaskID
+	}
+	return runner, dir
+}
+
+func writeTaskDefinition(t *testing.T, dir, name, taskDef string) {
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(taskDef), 0644))
+}
+
+func newLocalTask(runner *Runner, family, version string, desired, known apitaskstatus.TaskStatus) *apitask.Task {
+	task := &apitask.Task{
+		Arn:     runner.taskARN(family),
+		Family:  family,
+		Version: version,
+	}
+	task.SetDesiredStatus(desired)
+	task.SetKnownStatus(known)
+	return task
+}
+
+func TestRunnerStartsNewTasks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	taskEngine := mock_engine.NewMockTaskEngine(ctrl)
+	runner, dir := newTestRunner(t, taskEngine)
+	writeTaskDefinition(t, dir, "app.json", testTaskDefApp)
+	writeTaskDefinition(t, dir, "README.md", "not a task definition")
+	writeTaskDefinition(t, dir, ".app.json.swp", testTaskDefApp)
+
+	// tasks received from ECS are left as is
+	ecsTask := &apitask.Task{Arn: "arn:aws:ecs:us-west-2:123456789012:task/edge/ecs", Family: "app"}
+	taskEngine.EXPECT().ListTasks().Return([]*apitask.Task{ecsTask}, nil)
+	taskEngine.EXPECT().AddTask(gomock.Any()).Do(func(task *apitask.Task) {
+		assert.Equal(t, "arn:aws:ecs:us-west-2:000000000000:task/edge/"+testTaskID, task.Arn)
+		assert.Equal(t, "app", task.Family)
+		assert.Len(t, task.Version, versionLength)
+		assert.Equal(t, apitaskstatus.TaskRunning, task.GetDesiredStatus())
+		require.Len(t, task.Containers, 1)
+		assert.Equal(t, "app:1", task.Containers[0].Image)
+	})
+
+	runner.sync()
+}
+
+func TestRunnerKeepsTasksInSync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	taskEngine := mock_engine.NewMockTaskEngine(ctrl)
+	runner, dir := newTestRunner(t, taskEngine)
+	writeTaskDefinition(t, dir, "unchanged.json", testTaskDefApp)
+	writeTaskDefinition(t, dir, "changed.json", testTaskDefApp)
+	writeTaskDefinition(t, dir, "invalid.json", `{"containerDefinitions": []}`)
+	writeTaskDefinition(t, dir, "stopping.json", testTaskDefApp)
+	taskDefs, err := runner.readTaskDefinitions()
+	require.NoError(t, err)
+	version := taskDefs["unchanged"].version
+
+	unchanged := newLocalTask(runner, "unchanged", version, apitaskstatus.TaskRunning, apitaskstatus.TaskRunning)
+	changed := newLocalTask(runner, "changed", "0123456789ab", apitaskstatus.TaskRunning, apitaskstatus.TaskRunning)
+	invalid := newLocalTask(runner, "invalid", "0123456789ab", apitaskstatus.TaskRunning, apitaskstatus.TaskRunning)
+	removed := newLocalTask(runner, "removed", version, apitaskstatus.TaskRunning, apitaskstatus.TaskRunning)
+	stopping := newLocalTask(runner, "stopping", version, apitaskstatus.TaskStopped, apitaskstatus.TaskRunning)
+	taskEngine.EXPECT().ListTasks().Return([]*apitask.Task{unchanged, changed, invalid, removed, stopping}, nil)
+
+	// the changed and removed tasks are stopped, and the changed one is replaced once it has stopped
+	stopped := make(map[string]bool)
+	taskEngine.EXPECT().UpsertTask(gomock.Any()).Times(2).Do(func(task *apitask.Task) {
+		assert.Equal(t, apitaskstatus.TaskStopped, task.GetDesiredStatus())
+		stopped[task.Arn] = true
+	})
+	runner.sync()
+	assert.Equal(t, map[string]bool{changed.Arn: true, removed.Arn: true}, stopped)
+
+	changed.SetDesiredStatus(apitaskstatus.TaskStopped)
+	changed.SetKnownStatus(apitaskstatus.TaskStopped)
+	stopping.SetKnownStatus(apitaskstatus.TaskStopped)
+	taskEngine.EXPECT().ListTasks().Return([]*apitask.Task{unchanged, changed, invalid, stopping}, nil)
+	started := make(map[string]bool)
+	taskEngine.EXPECT().AddTask(gomock.Any()).Times(2).Do(func(task *apitask.Task) {
+		assert.Equal(t, version, task.Version)
+		started[task.Family] = true
+	})
+	runner.sync()
+	assert.Equal(t, map[string]bool{"changed": true, "stopping": true}, started)
+}
+
+func TestRunnerBacksOffRestarts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	taskEngine := mock_engine.NewMockTaskEngine(ctrl)
+	runner, dir := newTestRunner(t, taskEngine)
+	now := time.Now()
+	runner.now = func() time.Time {
+		return now
+	}
+	writeTaskDefinition(t, dir, "app.json", testTaskDefApp)
+
+	var task *apitask.Task
+	taskEngine.EXPECT().AddTask(gomock.Any()).Times(5).Do(func(added *apitask.Task) {
+		task = added
+	})
+	// sync returns the task started last, after it has stopped
+	taskEngine.EXPECT().ListTasks().AnyTimes().DoAndReturn(func() ([]*apitask.Task, error) {
+		if task == nil {
+			return nil, nil
+		}
+		task.SetDesiredStatus(apitaskstatus.TaskStopped)
+		task.SetKnownStatus(apitaskstatus.TaskStopped)
+		return []*apitask.Task{task}, nil
+	})
+	runner.sync()
+
+	// the first restart is delayed by restartBackoffMin, plus up to 20% of jitter
+	started := task
+	runner.sync()
+	now = now.Add(50 * time.Second)
+	runner.sync()
+	assert.Same(t, started, task)
+	now = now.Add(restartBackoffMin)
+	runner.sync()
+	assert.NotSame(t, started, task)
+
+	// the next one twice as long
+	started = task
+	runner.sync()
+	now = now.Add(restartBackoffMin + 15*time.Second)
+	runner.sync()
+	assert.Same(t, started, task)
+	now = now.Add(restartBackoffMin + 15*time.Second)
+	runner.sync()
+	assert.NotSame(t, started, task)
+
+	// and a changed file is started right away, with a new backoff
+	writeTaskDefinition(t, dir, "app.json", `{"containerDefinitions": [{"name": "app", "image": "app:2"}]}`)
+	runner.sync()
+	assert.Equal(t, "app:2", task.Containers[0].Image)
+	started = task
+	runner.sync()
+	now = now.Add(restartBackoffMin + 15*time.Second)
+	runner.sync()
+	assert.NotSame(t, started, task)
+}
+
+func TestRunnerMissingDirectory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	taskEngine := mock_engine.NewMockTaskEngine(ctrl)
+	runner := NewRunner(filepath.Join(t.TempDir(), "missing"), testRegion, testCluster, taskEngine)
+
+	runner.sync()
+	assert.Error(t, runner.Start(context.Background()))
+}
@@ -0,0 +1,264 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package localtasks
+
+import (
+	"encoding/json"
+	"strconv"
+
+	apicontainer "github.com/aws/amazon-ecs-agent/agent/api/container"
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// cpuUnitsPerVCPU is the number of CPU units of a vCPU, the unit of the CPU of the task payloads
+	cpuUnitsPerVCPU = 1024
+	bytesPerMiB     = 1024 * 1024
+	mibPerGiB       = 1024
+)
+
+// parseTaskDefinition converts a task definition file, in the format of the input of the RegisterTaskDefinition
+// API, to a task payload, as received from ECS. The settings which require the ECS control plane, such as
+// task IAM roles or secrets, aren't supported.
+func parseTaskDefinition(data []byte) (*ecsacs.Task, error) {
+	var taskDef ecs.RegisterTaskDefinitionInput
+	if err := json.Unmarshal(data, &taskDef); err != nil {
+		return nil, errors.Wrap(err, "invalid task definition")
+	}
+	if len(taskDef.ContainerDefinitions) == 0 {
+		return nil, errors.New("the task definition has no container definition")
+	}
+	if taskDef.TaskRoleArn != nil || taskDef.ExecutionRoleArn != nil {
+		return nil, errors.New("task and execution roles aren't supported by local tasks")
+	}
+	if taskDef.ProxyConfiguration != nil {
+		return nil, errors.New("proxy configurations aren't supported by local tasks")
+	}
+
+	task := &ecsacs.Task{
+		NetworkMode: aws.String(apitask.BridgeNetworkMode),
+	}
+	switch taskDef.NetworkMode {
+	case "":
+	case types.NetworkModeBridge, types.NetworkModeHost, types.NetworkModeNone:
+		task.NetworkMode = aws.String(string(taskDef.NetworkMode))
+	default:
+		return nil, errors.Errorf("network mode %s isn't supported by local tasks", taskDef.NetworkMode)
+	}
+	if taskDef.PidMode != "" {
+		task.PidMode = aws.String(string(taskDef.PidMode))
+	}
+	if taskDef.IpcMode != "" {
+		task.IpcMode = aws.String(string(taskDef.IpcMode))
+	}
+	if taskDef.Cpu != nil {
+		cpu, err := strconv.ParseFloat(*taskDef.Cpu, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid task cpu %q, expected a number of CPU units", *taskDef.Cpu)
+		}
+		task.Cpu = aws.Float64(cpu / cpuUnitsPerVCPU)
+	}
+	if taskDef.Memory != nil {
+		memory, err := strconv.ParseInt(*taskDef.Memory, 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid task memory %q, expected a number of MiB", *taskDef.Memory)
+		}
+		task.Memory = aws.Int64(memory)
+	}
+
+	for _, volume := range taskDef.Volumes {
+		if volume.DockerVolumeConfiguration != nil || volume.EfsVolumeConfiguration != nil ||
+			volume.FsxWindowsFileServerVolumeConfiguration != nil {
+			return nil, errors.Errorf("volume %s: only host volumes are supported by local tasks",
+				aws.ToString(volume.Name))
+		}
+		acsVolume := &ecsacs.Volume{
+			Name: volume.Name,
+			Type: aws.String(apitask.HostVolumeType),
+			Host: &ecsacs.HostVolumeProperties{},
+		}
+		if volume.Host != nil {
+			acsVolume.Host.SourcePath = volume.Host.SourcePath
+		}
+		task.Volumes = append(task.Volumes, acsVolume)
+	}
+
+	if taskDef.EphemeralStorage != nil {
+		requestEphemeralStorage(taskDef.ContainerDefinitions, taskDef.EphemeralStorage.SizeInGiB)
+	}
+	for _, containerDef := range taskDef.ContainerDefinitions {
+		container, err := containerFromDefinition(containerDef)
+		if err != nil {
+			return nil, err
+		}
+		task.Containers = append(task.Containers, container)
+	}
+	return task, nil
+}
+
+// requestEphemeralStorage requests the ephemeral storage of the task definition, which task payloads have no
+// field for, with the ephemeral storage label of the first container, unless a container sets the label.
+func requestEphemeralStorage(defs []types.ContainerDefinition, sizeInGiB int32) {
+	for _, def := range defs {
+		if _, ok := def.DockerLabels[apicontainer.EphemeralStorageLabel]; ok {
+			return
+		}
+	}
+	labels := map[string]string{apicontainer.EphemeralStorageLabel: strconv.Itoa(int(sizeInGiB) * mibPerGiB)}
+	for key, value := range defs[0].DockerLabels {
+		labels[key] = value
+	}
+	defs[0].DockerLabels = labels
+}
+
+// containerFromDefinition converts a container definition to a container of a task payload. The settings
+// which ECS passes to the agent as docker configuration are converted to docker configuration.
+func containerFromDefinition(def types.ContainerDefinition) (*ecsacs.Container, error) {
+	name := aws.ToString(def.Name)
+	if name == "" || aws.ToString(def.Image) == "" {
+		return nil, errors.New("container definitions must have a name and an image")
+	}
+	if len(def.Secrets) > 0 || len(def.EnvironmentFiles) > 0 || def.RepositoryCredentials != nil ||
+		def.FirelensConfiguration != nil {
+		return nil, errors.Errorf("container %s: secrets, environment files, repository credentials and "+
+			"FireLens aren't supported by local tasks", name)
+	}
+
+	container := &ecsacs.Container{
+		Name:         def.Name,
+		Image:        def.Image,
+		Cpu:          aws.Int64(int64(def.Cpu)),
+		Command:      aws.StringSlice(def.Command),
+		EntryPoint:   aws.StringSlice(def.EntryPoint),
+		Links:        aws.StringSlice(def.Links),
+		Essential:    aws.Bool(def.Essential == nil || *def.Essential),
+		Environment:  make(map[string]*string),
+		StartTimeout: int64Ptr(def.StartTimeout),
+		StopTimeout:  int64Ptr(def.StopTimeout),
+	}
+	if def.Memory != nil {
+		container.Memory = aws.Int64(int64(*def.Memory))
+	}
+	for _, env := range def.Environment {
+		container.Environment[aws.ToString(env.Name)] = env.Value
+	}
+	for _, portMapping := range def.PortMappings {
+		acsPortMapping := &ecsacs.PortMapping{
+			ContainerPort:      int64Ptr(portMapping.ContainerPort),
+			ContainerPortRange: portMapping.ContainerPortRange,
+			HostPort:           int64Ptr(portMapping.HostPort),
+		}
+		if portMapping.Protocol != "" {
+			acsPortMapping.Protocol = aws.String(string(portMapping.Protocol))
+		}
+		container.PortMappings = append(container.PortMappings, acsPortMapping)
+	}
+	for _, mountPoint := range def.MountPoints {
+		container.MountPoints = append(container.MountPoints, &ecsacs.MountPoint{
+			ContainerPath: mountPoint.ContainerPath,
+			ReadOnly:      mountPoint.ReadOnly,
+			SourceVolume:  mountPoint.SourceVolume,
+		})
+	}
+	for _, volumeFrom := range def.VolumesFrom {
+		container.VolumesFrom = append(container.VolumesFrom, &ecsacs.VolumeFrom{
+			ReadOnly:        volumeFrom.ReadOnly,
+			SourceContainer: volumeFrom.SourceContainer,
+		})
+	}
+	for _, dependency := range def.DependsOn {
+		container.DependsOn = append(container.DependsOn, &ecsacs.ContainerDependency{
+			Condition:     aws.String(string(dependency.Condition)),
+			ContainerName: dependency.ContainerName,
+		})
+	}
+
+	dockerConfig, err := dockerConfigFromDefinition(def)
+	if err != nil {
+		return nil, errors.Wrapf(err, "container %s", name)
+	}
+	container.DockerConfig = dockerConfig
+	return container, nil
+}
+
+// dockerConfigFromDefinition returns the docker configuration of a container definition. Only the set fields
+// are included, as the configuration is applied over the one the agent creates.
+func dockerConfigFromDefinition(def types.ContainerDefinition) (*ecsacs.DockerConfig, error) {
+	config := make(map[string]interface{})
+	if len(def.DockerLabels) > 0 {
+		config["Labels"] = def.DockerLabels
+	}
+	if def.User != nil {
+		config["User"] = *def.User
+	}
+	if def.WorkingDirectory != nil {
+		config["WorkingDir"] = *def.WorkingDirectory
+	}
+	if def.Hostname != nil {
+		config["Hostname"] = *def.Hostname
+	}
+
+	hostConfig := make(map[string]interface{})
+	if def.LogConfiguration != nil {
+		hostConfig["LogConfig"] = map[string]interface{}{
+			"Type":   string(def.LogConfiguration.LogDriver),
+			"Config": def.LogConfiguration.Options,
+		}
+	}
+	if def.MemoryReservation != nil {
+		hostConfig["MemoryReservation"] = int64(*def.MemoryReservation) * bytesPerMiB
+	}
+	if def.Privileged != nil {
+		hostConfig["Privileged"] = *def.Privileged
+	}
+	if def.ReadonlyRootFilesystem != nil {
+		hostConfig["ReadonlyRootfs"] = *def.ReadonlyRootFilesystem
+	}
+	if len(def.DnsServers) > 0 {
+		hostConfig["Dns"] = def.DnsServers
+	}
+	if len(def.DnsSearchDomains) > 0 {
+		hostConfig["DnsSearch"] = def.DnsSearchDomains
+	}
+
+	dockerConfig := &ecsacs.DockerConfig{}
+	if len(config) > 0 {
+		data, err := json.Marshal(config)
+		if err != nil {
+			return nil, err
+		}
+		dockerConfig.Config = aws.String(string(data))
+	}
+	if len(hostConfig) > 0 {
+		data, err := json.Marshal(hostConfig)
+		if err != nil {
+			return nil, err
+		}
+		dockerConfig.HostConfig = aws.String(string(data))
+	}
+	return dockerConfig, nil
+}
+
+func int64Ptr(value *int32) *int64 {
+	if value == nil {
+		return nil
+	}
+	return aws.Int64(int64(*value))
+}
@@ -0,0 +1,146 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+package localtasks
+
+import (
+	"encoding/json"
+	"testing"
+
+	apitask "github.com/aws/amazon-ecs-agent/agent/api/task"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTaskDefinition = `{
+	"family": "ignored",
+	"networkMode": "bridge",
+	"cpu": "512",
+	"memory": "1024",
+	"volumes": [{"name": "data", "host": {"sourcePath": "/var/data"}}, {"name": "scratch"}],
+	"containerDefinitions": [
+		{
+			"name": "web",
+			"image": "nginx:latest",
+			"cpu": 256,
+			"memoryReservation": 128,
+			"portMappings": [{"containerPort": 80, "hostPort": 8080, "protocol": "tcp"}],
+			"mountPoints": [{"sourceVolume": "data", "containerPath": "/data", "readOnly": true}],
+			"environment": [{"name": "MODE", "value": "edge"}],
+			"dockerLabels": {"site": "store-1"},
+			"logConfiguration": {"logDriver": "json-file", "options": {"max-size": "10m"}},
+			"dependsOn": [{"containerName": "init", "condition": "SUCCESS"}]
+		},
+		{
+			"name": "init",
+			"image": "busybox",
+			"essential": false,
+			"command": ["sh", "-c", "echo ready"]
+		}
+	]
+}`
+
+func TestParseTaskDefinition(t *testing.T) {
+	acsTask, err := parseTaskDefinition([]byte(testTaskDefinition))
+	require.NoError(t, err)
+	assert.Equal(t, "bridge", aws.ToString(acsTask.NetworkMode))
+	assert.Equal(t, 0.5, aws.ToFloat64(acsTask.Cpu))
+	assert.Equal(t, int64(1024), aws.ToInt64(acsTask.Memory))
+	require.Len(t, acsTask.Volumes, 2)
+	assert.Equal(t, "/var/data", aws.ToString(acsTask.Volumes[0].Host.SourcePath))
+	assert.Nil(t, acsTask.Volumes[1].Host.SourcePath)
+	require.Len(t, acsTask.Containers, 2)
+
+	web := acsTask.Containers[0]
+	assert.Equal(t, "nginx:latest", aws.ToString(web.Image))
+	assert.Equal(t, int64(256), aws.ToInt64(web.Cpu))
+	assert.Nil(t, web.Memory)
+	assert.True(t, aws.ToBool(web.Essential))
+	assert.Equal(t, "edge", aws.ToString(web.Environment["MODE"]))
+	require.Len(t, web.PortMappings, 1)
+	assert.Equal(t, int64(8080), aws.ToInt64(web.PortMappings[0].HostPort))
+	assert.Equal(t, "tcp", aws.ToString(web.PortMappings[0].Protocol))
+	require.Len(t, web.MountPoints, 1)
+	assert.True(t, aws.ToBool(web.MountPoints[0].ReadOnly))
+	require.Len(t, web.DependsOn, 1)
+	assert.Equal(t, "SUCCESS", aws.ToString(web.DependsOn[0].Condition))
+
+	var config, hostConfig map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(aws.ToString(web.DockerConfig.Config)), &config))
+	assert.Equal(t, map[string]interface{}{"Labels": map[string]interface{}{"site": "store-1"}}, config)
+	require.NoError(t, json.Unmarshal([]byte(aws.ToString(web.DockerConfig.HostConfig)), &hostConfig))
+	assert.Equal(t, map[string]interface{}{
+		"LogConfig":         map[string]interface{}{"Type": "json-file", "Config": map[string]interface{}{"max-size": "10m"}},
+		"MemoryReservation": float64(128 * 1024 * 1024),
+	}, hostConfig)
+
+	initContainer := acsTask.Containers[1]
+	assert.False(t, aws.ToBool(initContainer.Essential))
+	assert.Equal(t, []string{"sh", "-c", "echo ready"}, aws.ToStringSlice(initContainer.Command))
+	assert.Nil(t, initContainer.DockerConfig.Config)
+	assert.Nil(t, initContainer.DockerConfig.HostConfig)
+
+	acsTask.Arn = aws.String("arn:aws:ecs:us-west-2:000000000000:task/default/id")
+	task, err := apitask.TaskFromACS(acsTask, nil)
+	require.NoError(t, err)
+	assert.Equal(t, apitask.BridgeNetworkMode, task.NetworkMode)
+	assert.Len(t, task.Containers, 2)
+}
+
+func TestParseTaskDefinitionDefaultNetworkMode(t *testing.T) {
+	acsTask, err := parseTaskDefinition([]byte(`{"containerDefinitions": [{"name": "app", "image": "app"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, apitask.BridgeNetworkMode, aws.ToString(acsTask.NetworkMode))
+	assert.Nil(t, acsTask.Cpu)
+}
+
+func TestParseTaskDefinitionEphemeralStorage(t *testing.T) {
+	acsTask, err := parseTaskDefinition([]byte(`{"ephemeralStorage": {"sizeInGiB": 2}, "containerDefinitions": [
+		{"name": "app", "image": "app", "dockerLabels": {"site": "store-1"}},
+		{"name": "sidecar", "image": "sidecar"}]}`))
+	require.NoError(t, err)
+	var config map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(aws.ToString(acsTask.Containers[0].DockerConfig.Config)), &config))
+	assert.Equal(t, map[string]interface{}{"Labels": map[string]interface{}{
+		"site":                                "store-1",
+		"com.amazonaws.ecs.ephemeral-storage": "2048",
+	}}, config)
+	assert.Nil(t, acsTask.Containers[1].DockerConfig.Config)
+
+	// The containers which set the label take precedence over the task definition
+	acsTask, err = parseTaskDefinition([]byte(`{"ephemeralStorage": {"sizeInGiB": 2}, "containerDefinitions": [
+		{"name": "app", "image": "app"},
+		{"name": "sidecar", "image": "sidecar", "dockerLabels": {"com.amazonaws.ecs.ephemeral-storage": "512"}}]}`))
+	require.NoError(t, err)
+	assert.Nil(t, acsTask.Containers[0].DockerConfig.Config)
+}
+
+func TestParseTaskDefinitionUnsupported(t *testing.T) {
+	for _, invalid := range []string{
+		`containerDefinitions`,
+		`{}`,
+		`{"containerDefinitions": [{"name": "app"}]}`,
+		`{"networkMode": "awsvpc", "containerDefinitions": [{"name": "app", "image": "app"}]}`,
+		`{"taskRoleArn": "arn:aws:iam::123456789012:role/app", "containerDefinitions": [{"name": "app", "image": "app"}]}`,
+		`{"cpu": "1 vCPU", "containerDefinitions": [{"name": "app", "image": "app"}]}`,
+		`{"volumes": [{"name": "efs", "efsVolumeConfiguration": {"fileSystemId": "fs-1"}}], "containerDefinitions": [{"name": "app", "image": "app"}]}`,
+		`{"containerDefinitions": [{"name": "app", "image": "app", "secrets": [{"name": "KEY", "valueFrom": "arn"}]}]}`,
+	} {
+		_, err := parseTaskDefinition([]byte(invalid))
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,309 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package netnsdiag
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/utils/netlinkwrapper"
+	"github.com/aws/amazon-ecs-agent/agent/utils/nswrapper"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/pkg/errors"
+	"github.com/vishvananda/netlink"
+)
+
+const (
+	nsenterCmd     = "nsenter"
+	commandTimeout = 10 * time.Second
+)
+
+type collector struct {
+	ns       nswrapper.NS
+	netlink  netlinkwrapper.NetLink
+	exec     execwrapper.Exec
+	readFile func(name string) ([]byte, error)
+}
+
+// NewCollector returns a new Collector.
+func NewCollector() Collector {
+	return &collector{
+		ns:       nswrapper.NewNS(),
+		netlink:  netlinkwrapper.New(),
+		exec:     execwrapper.NewExec(),
+		readFile: os.ReadFile,
+	}
+}
+
+// Collect returns the network configuration of the network namespace, and the results of the
+// connectivity checks run from inside it.
+func (c *collector) Collect(ctx context.Context, netNSPath string, checks []ConnectivityCheck) *Diagnostics {
+	diagnostics := &Diagnostics{NetNSPath: netNSPath}
+
+	err := c.ns.WithNetNSPath(netNSPath, func(ns.NetNS) error {
+		return c.collectLinks(diagnostics)
+	})
+	if err != nil {
+		diagnostics.addError("netlink", err)
+	}
+
+	if root := procRoot(netNSPath); root != "" {
+		diagnostics.ResolvConf = c.readNetNSFile(diagnostics, filepath.Join(root, "etc", "resolv.conf"))
+		diagnostics.Hosts = c.readNetNSFile(diagnostics, filepath.Join(root, "etc", "hosts"))
+	}
+
+	diagnostics.IPTables = c.runInNetNS(ctx, diagnostics, netNSPath, "iptables-save")
+	diagnostics.IP6Tables = c.runInNetNS(ctx, diagnostics, netNSPath, "ip6tables-save")
+	diagnostics.TrafficControl = c.collectTrafficControl(ctx, diagnostics, netNSPath)
+
+	nameServers := parseNameServers(diagnostics.ResolvConf)
+	for _, check := range checks {
+		diagnostics.Connectivity = append(diagnostics.Connectivity,
+			c.checkConnectivity(ctx, netNSPath, nameServers, check))
+	}
+	return diagnostics
+}
+
+// collectLinks reads the interfaces, routes and neighbors of the network namespace. It must run
+// inside the network namespace.
+func (c *collector) collectLinks(diagnostics *Diagnostics) error {
+	links, err := c.netlink.LinkList()
+	if err != nil {
+		return errors.Wrap(err, "unable to list links")
+	}
+	linkNames := make(map[int]string)
+	for _, link := range links {
+		attrs := link.Attrs()
+		linkNames[attrs.Index] = attrs.Name
+		iface := Interface{
+			Name:         attrs.Name,
+			Type:         link.Type(),
+			HardwareAddr: attrs.HardwareAddr.String(),
+			MTU:          attrs.MTU,
+			State:        attrs.OperState.String(),
+		}
+		addrs, err := c.netlink.AddrList(link, netlink.FAMILY_ALL)
+		if err != nil {
+			diagnostics.addError("addresses of "+attrs.Name, err)
+		}
+		for _, addr := range addrs {
+			iface.Addresses = append(iface.Addresses, addr.IPNet.String())
+		}
+		diagnostics.Interfaces = append(diagnostics.Interfaces, iface)
+
+		neighs, err := c.netlink.NeighList(attrs.Index, netlink.FAMILY_ALL)
+		if err != nil {
+			diagnostics.addError("neighbors of "+attrs.Name, err)
+		}
+		for _, neigh := range neighs {
+			diagnostics.Neighbors = append(diagnostics.Neighbors, Neighbor{
+				IP:           neigh.IP.String(),
+				HardwareAddr: neigh.HardwareAddr.String(),
+				Interface:    attrs.Name,
+				State:        neighStateString(neigh.State),
+			})
+		}
+	}
+
+	routes, err := c.netlink.RouteList(nil, netlink.FAMILY_ALL)
+	if err != nil {
+		return errors.Wrap(err, "unable to list routes")
+	}
+	for _, route := range routes {
+		r := Route{
+			Destination: "default",
+			Interface:   linkNames[route.LinkIndex],
+			Priority:    route.Priority,
+		}
+		if route.Dst != nil {
+			r.Destination = route.Dst.String()
+		}
+		if route.Gw != nil {
+			r.Gateway = route.Gw.String()
+		}
+		if route.Src != nil {
+			r.Source = route.Src.String()
+		}
+		diagnostics.Routes = append(diagnostics.Routes, r)
+	}
+	return nil
+}
+
+// collectTrafficControl returns the queueing disciplines of the network namespace, along with the
+// ingress and egress filters of the clsact queueing disciplines which police the bandwidth.
+func (c *collector) collectTrafficControl(ctx context.Context, diagnostics *Diagnostics, netNSPath string) string {
+	var b strings.Builder
+	b.WriteString(c.runInNetNS(ctx, diagnostics, netNSPath, "tc", "-s", "qdisc", "show"))
+	for _, iface := range diagnostics.Interfaces {
+		if iface.Type == "loopback" || iface.Name == "lo" {
+			continue
+		}
+		for _, direction := range []string{"ingress", "egress"} {
+			if filters := c.runInNetNS(ctx, diagnostics, netNSPath,
+				"tc", "-s", "filter", "show", "dev", iface.Name, direction); filters != "" {
+				b.WriteString(filters)
+			}
+		}
+	}
+	return b.String()
+}
+
+// checkConnectivity runs the check from inside the network namespace.
+func (c *collector) checkConnectivity(
+	ctx context.Context,
+	netNSPath string,
+	nameServers []string,
+	check ConnectivityCheck,
+) ConnectivityResult {
+	result := ConnectivityResult{Type: check.Type, Target: check.Target}
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	var err error
+	switch check.Type {
+	case CheckTypeDNS:
+		result.Addresses, err = c.resolve(ctx, netNSPath, nameServers, check.Target)
+	case CheckTypeTCP:
+		var conn net.Conn
+		conn, err = c.dialTCP(ctx, netNSPath, nameServers, check.Target)
+		if err == nil {
+			result.Addresses = []string{conn.RemoteAddr().String()}
+			conn.Close()
+		}
+	default:
+		err = check.Validate()
+	}
+	result.Duration = time.Since(start).String()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Success = true
+	return result
+}
+
+// resolve resolves the name with the first name server of the task, queried from inside the network
+// namespace.
+func (c *collector) resolve(ctx context.Context, netNSPath string, nameServers []string, name string) ([]string, error) {
+	if len(nameServers) == 0 {
+		return nil, errors.New("no name server found in resolv.conf")
+	}
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return c.dial(ctx, netNSPath, network, net.JoinHostPort(nameServers[0], "53"))
+		},
+	}
+	return resolver.LookupHost(ctx, name)
+}
+
+// dialTCP connects to the host:port address from inside the network namespace. A host name is resolved
+// with the name servers of the task first, the dialer would otherwise resolve it in the agent namespace.
+func (c *collector) dialTCP(ctx context.Context, netNSPath string, nameServers []string, target string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return c.dial(ctx, netNSPath, "tcp", target)
+	}
+	addresses, err := c.resolve(ctx, netNSPath, nameServers, host)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to resolve %s", host)
+	}
+	for _, address := range addresses {
+		var conn net.Conn
+		conn, err = c.dial(ctx, netNSPath, "tcp", net.JoinHostPort(address, port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// dial opens a connection from inside the network namespace. The address must be an IP address, so
+// that the dialer doesn't need a resolver. The socket is created while the thread
+// is in the namespace, so the connection stays in the namespace after the thread switches back.
+func (c *collector) dial(ctx context.Context, netNSPath, network, address string) (net.Conn, error) {
+	var conn net.Conn
+	err := c.ns.WithNetNSPath(netNSPath, func(ns.NetNS) error {
+		var err error
+		conn, err = (&net.Dialer{}).DialContext(ctx, network, address)
+		return err
+	})
+	return conn, err
+}
+
+func (c *collector) readNetNSFile(diagnostics *Diagnostics, path string) string {
+	content, err := c.readFile(path)
+	if err != nil {
+		diagnostics.addError(filepath.Base(path), err)
+		return ""
+	}
+	return string(content)
+}
+
+// runInNetNS runs the command inside the network namespace and returns its output.
+func (c *collector) runInNetNS(
+	ctx context.Context,
+	diagnostics *Diagnostics,
+	netNSPath string,
+	command string,
+	args ...string,
+) string {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	out, err := c.exec.CommandContext(ctx, nsenterCmd,
+		append([]string{"--net=" + netNSPath, command}, args...)...).CombinedOutput()
+	if err != nil {
+		diagnostics.addError(command, errors.Wrap(err, strings.TrimSpace(string(out))))
+		return ""
+	}
+	return string(out)
+}
+
+// neighStateString returns the name of the neighbor state, as printed by `ip neigh`.
+func neighStateString(state int) string {
+	switch state {
+	case netlink.NUD_INCOMPLETE:
+		return "INCOMPLETE"
+	case netlink.NUD_REACHABLE:
+		return "REACHABLE"
+	case netlink.NUD_STALE:
+		return "STALE"
+	case netlink.NUD_DELAY:
+		return "DELAY"
+	case netlink.NUD_PROBE:
+		return "PROBE"
+	case netlink.NUD_FAILED:
+		return "FAILED"
+	case netlink.NUD_NOARP:
+		return "NOARP"
+	case netlink.NUD_PERMANENT:
+		return "PERMANENT"
+	default:
+		return "NONE"
+	}
+}
@@ -0,0 +1,168 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package netnsdiag
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+
+	mock_netlink "github.com/aws/amazon-ecs-agent/agent/utils/netlinkwrapper/mocks"
+	mock_nswrapper "github.com/aws/amazon-ecs-agent/agent/utils/nswrapper/mocks"
+	mock_execwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper/mocks"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vishvananda/netlink"
+)
+
+const testNetNSPath = "/host/proc/1234/ns/net"
+
+func TestCollect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockNS := mock_nswrapper.NewMockNS(ctrl)
+	mockNetLink := mock_netlink.NewMockNetLink(ctrl)
+	exec := mock_execwrapper.NewMockExec(ctrl)
+	cmd := mock_execwrapper.NewMockCmd(ctrl)
+	files := map[string]string{
+		"/host/proc/1234/root/etc/resolv.conf": "search ec2.internal\nnameserver 10.0.0.2\n",
+		"/host/proc/1234/root/etc/hosts":       "127.0.0.1 localhost\n",
+	}
+	c := &collector{
+		ns:      mockNS,
+		netlink: mockNetLink,
+		exec:    exec,
+		readFile: func(name string) ([]byte, error) {
+			content, ok := files[name]
+			if !ok {
+				return nil, os.ErrNotExist
+			}
+			return []byte(content), nil
+		},
+	}
+
+	mockNS.EXPECT().WithNetNSPath(testNetNSPath, gomock.Any()).DoAndReturn(
+		func(_ string, toRun func(ns.NetNS) error) error {
+			return toRun(nil)
+		}).AnyTimes()
+
+	_, dst, _ := net.ParseCIDR("10.0.0.0/24")
+	eth0 := &netlink.Device{LinkAttrs: netlink.LinkAttrs{
+		Index:        2,
+		Name:         "eth0",
+		MTU:          9001,
+		HardwareAddr: net.HardwareAddr{0x02, 0, 0, 0, 0, 0x01},
+		OperState:    netlink.OperUp,
+	}}
+	mockNetLink.EXPECT().LinkList().Return([]netlink.Link{eth0}, nil)
+	mockNetLink.EXPECT().AddrList(eth0, netlink.FAMILY_ALL).Return([]netlink.Addr{
+		{IPNet: &net.IPNet{IP: net.ParseIP("10.0.0.10"), Mask: net.CIDRMask(24, 32)}},
+	}, nil)
+	mockNetLink.EXPECT().NeighList(2, netlink.FAMILY_ALL).Return([]netlink.Neigh{
+		{IP: net.ParseIP("10.0.0.1"), HardwareAddr: net.HardwareAddr{0x02, 0, 0, 0, 0, 0x02},
+			State: netlink.NUD_REACHABLE},
+	}, nil)
+	mockNetLink.EXPECT().RouteList(nil, netlink.FAMILY_ALL).Return([]netlink.Route{
+		{LinkIndex: 2, Gw: net.ParseIP("10.0.0.1")},
+		{LinkIndex: 2, Dst: dst, Src: net.ParseIP("10.0.0.10")},
+	}, nil)
+
+	exec.EXPECT().CommandContext(gomock.Any(), "nsenter", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, args ...string) *mock_execwrapper.MockCmd {
+			assert.Equal(t, "--net="+testNetNSPath, args[0])
+			return cmd
+		}).Times(5)
+	gomock.InOrder(
+		cmd.EXPECT().CombinedOutput().Return([]byte("*filter\nCOMMIT\n"), nil),
+		cmd.EXPECT().CombinedOutput().Return([]byte("ip6tables-save: not found"), errors.New("exit status 127")),
+		cmd.EXPECT().CombinedOutput().Return([]byte("qdisc noqueue 0: dev eth0 root\n"), nil),
+		cmd.EXPECT().CombinedOutput().Return(nil, nil),
+		cmd.EXPECT().CombinedOutput().Return(nil, nil),
+	)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	diagnostics := c.Collect(context.TODO(), testNetNSPath, []ConnectivityCheck{
+		{Type: CheckTypeTCP, Target: listener.Addr().String()},
+	})
+
+	assert.Equal(t, []Interface{{
+		Name:         "eth0",
+		Type:         "device",
+		HardwareAddr: "02:00:00:00:00:01",
+		MTU:          9001,
+		State:        "up",
+		Addresses:    []string{"10.0.0.10/24"},
+	}}, diagnostics.Interfaces)
+	assert.Equal(t, []Route{
+		{Destination: "default", Gateway: "10.0.0.1", Interface: "eth0"},
+		{Destination: "10.0.0.0/24", Source: "10.0.0.10", Interface: "eth0"},
+	}, diagnostics.Routes)
+	assert.Equal(t, []Neighbor{
+		{IP: "10.0.0.1", HardwareAddr: "02:00:00:00:00:02", Interface: "eth0", State: "REACHABLE"},
+	}, diagnostics.Neighbors)
+	assert.Equal(t, files["/host/proc/1234/root/etc/resolv.conf"], diagnostics.ResolvConf)
+	assert.Equal(t, files["/host/proc/1234/root/etc/hosts"], diagnostics.Hosts)
+	assert.Equal(t, "*filter\nCOMMIT\n", diagnostics.IPTables)
+	assert.Empty(t, diagnostics.IP6Tables)
+	assert.Equal(t, "qdisc noqueue 0: dev eth0 root\n", diagnostics.TrafficControl)
+	require.Len(t, diagnostics.Errors, 1)
+	assert.Contains(t, diagnostics.Errors[0], "ip6tables-save")
+
+	require.Len(t, diagnostics.Connectivity, 1)
+	assert.True(t, diagnostics.Connectivity[0].Success)
+	assert.Equal(t, []string{listener.Addr().String()}, diagnostics.Connectivity[0].Addresses)
+}
+
+func TestCheckConnectivityDNSWithoutNameServer(t *testing.T) {
+	c := &collector{}
+	result := c.checkConnectivity(context.TODO(), testNetNSPath, nil,
+		ConnectivityCheck{Type: CheckTypeDNS, Target: "example.com"})
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Error, "no name server")
+}
+
+func TestCheckConnectivityTCPError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockNS := mock_nswrapper.NewMockNS(ctrl)
+	mockNS.EXPECT().WithNetNSPath(testNetNSPath, gomock.Any()).Return(errors.New("no such netns"))
+	c := &collector{ns: mockNS}
+
+	result := c.checkConnectivity(context.TODO(), testNetNSPath, nil,
+		ConnectivityCheck{Type: CheckTypeTCP, Target: "10.0.0.1:443"})
+	assert.False(t, result.Success)
+	assert.Equal(t, "no such netns", result.Error)
+}
+
+func TestCheckConnectivityTCPHostNameWithoutNameServer(t *testing.T) {
+	// The host name must be resolved by the name servers of the task, never by the agent resolver.
+	c := &collector{}
+	result := c.checkConnectivity(context.TODO(), testNetNSPath, nil,
+		ConnectivityCheck{Type: CheckTypeTCP, Target: "localhost:443"})
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Error, "no name server")
+}
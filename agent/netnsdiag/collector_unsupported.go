@@ -0,0 +1,37 @@
+//go:build !linux
+// +build !linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package netnsdiag
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+type collector struct{}
+
+// NewCollector returns a new Collector.
+func NewCollector() Collector {
+	return &collector{}
+}
+
+// Collect reports an error, as network namespaces are only supported on Linux.
+func (c *collector) Collect(_ context.Context, netNSPath string, _ []ConnectivityCheck) *Diagnostics {
+	diagnostics := &Diagnostics{NetNSPath: netNSPath}
+	diagnostics.addError("netns", errors.New("network namespace diagnostics are not supported on this platform"))
+	return diagnostics
+}
@@ -0,0 +1,140 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package netnsdiag collects the network configuration of a task network namespace and runs
+// connectivity checks from inside it, to help debugging the connectivity of awsvpc tasks.
+package netnsdiag
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// CheckTypeDNS resolves a DNS name with the name servers of the task.
+	CheckTypeDNS = "dns"
+	// CheckTypeTCP opens a TCP connection to a host:port address.
+	CheckTypeTCP = "tcp"
+
+	// checkTimeout is the timeout of a single connectivity check.
+	checkTimeout = 5 * time.Second
+)
+
+// Collector collects the diagnostics of network namespaces.
+type Collector interface {
+	// Collect returns the network configuration of the network namespace, and the results of the
+	// connectivity checks run from inside it. Collection is best effort, the parts which can't be
+	// collected are reported in the Errors of the diagnostics.
+	Collect(ctx context.Context, netNSPath string, checks []ConnectivityCheck) *Diagnostics
+}
+
+// Diagnostics is the network configuration of a network namespace.
+type Diagnostics struct {
+	NetNSPath      string
+	Interfaces     []Interface
+	Routes         []Route
+	Neighbors      []Neighbor
+	ResolvConf     string
+	Hosts          string
+	IPTables       string
+	IP6Tables      string
+	TrafficControl string
+	Connectivity   []ConnectivityResult `json:",omitempty"`
+	Errors         []string             `json:",omitempty"`
+}
+
+// Interface is a network interface and its addresses.
+type Interface struct {
+	Name         string
+	Type         string
+	HardwareAddr string
+	MTU          int
+	State        string
+	Addresses    []string
+}
+
+// Route is an entry of the main routing table.
+type Route struct {
+	Destination string
+	Gateway     string `json:",omitempty"`
+	Source      string `json:",omitempty"`
+	Interface   string
+	Priority    int
+}
+
+// Neighbor is an entry of the neighbor (ARP and NDP) table.
+type Neighbor struct {
+	IP           string
+	HardwareAddr string
+	Interface    string
+	State        string
+}
+
+// ConnectivityCheck is a check run from inside the network namespace.
+type ConnectivityCheck struct {
+	// Type is either dns or tcp.
+	Type string
+	// Target is the DNS name to resolve, or the host:port address to connect to.
+	Target string
+}
+
+// ConnectivityResult is the result of a connectivity check.
+type ConnectivityResult struct {
+	Type      string
+	Target    string
+	Success   bool
+	Addresses []string `json:",omitempty"`
+	Duration  string
+	Error     string `json:",omitempty"`
+}
+
+// Validate returns an error if the type of the check is unknown.
+func (c ConnectivityCheck) Validate() error {
+	switch c.Type {
+	case CheckTypeDNS, CheckTypeTCP:
+	default:
+		return fmt.Errorf("unknown connectivity check type %q", c.Type)
+	}
+	if c.Target == "" {
+		return fmt.Errorf("%s connectivity check requires a target", c.Type)
+	}
+	return nil
+}
+
+func (d *Diagnostics) addError(section string, err error) {
+	d.Errors = append(d.Errors, fmt.Sprintf("%s: %v", section, err))
+}
+
+// procRoot returns the root directory of the process which holds the network namespace, e.g.
+// /host/proc/1234/root for /host/proc/1234/ns/net. It returns "" for other network namespace paths.
+func procRoot(netNSPath string) string {
+	const netNSSuffix = "/ns/net"
+	if !strings.HasSuffix(netNSPath, netNSSuffix) {
+		return ""
+	}
+	return strings.TrimSuffix(netNSPath, netNSSuffix) + "/root"
+}
+
+// parseNameServers returns the name servers of a resolv.conf file.
+func parseNameServers(resolvConf string) []string {
+	var servers []string
+	for _, line := range strings.Split(resolvConf, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			servers = append(servers, fields[1])
+		}
+	}
+	return servers
+}
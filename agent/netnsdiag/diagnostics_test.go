@@ -0,0 +1,40 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package netnsdiag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcRoot(t *testing.T) {
+	assert.Equal(t, "/host/proc/1234/root", procRoot("/host/proc/1234/ns/net"))
+	assert.Empty(t, procRoot("/var/run/netns/task"))
+}
+
+func TestParseNameServers(t *testing.T) {
+	resolvConf := "# generated\nsearch ec2.internal\nnameserver 10.0.0.2\nnameserver  fd00:ec2::253\noptions ndots:2\n"
+	assert.Equal(t, []string{"10.0.0.2", "fd00:ec2::253"}, parseNameServers(resolvConf))
+}
+
+func TestConnectivityCheckValidate(t *testing.T) {
+	assert.NoError(t, ConnectivityCheck{Type: CheckTypeDNS, Target: "example.com"}.Validate())
+	assert.NoError(t, ConnectivityCheck{Type: CheckTypeTCP, Target: "10.0.0.1:443"}.Validate())
+	assert.Error(t, ConnectivityCheck{Type: "icmp", Target: "10.0.0.1"}.Validate())
+	assert.Error(t, ConnectivityCheck{Type: CheckTypeTCP}.Validate())
+}
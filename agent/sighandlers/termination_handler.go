@@ -49,21 +49,32 @@ type TerminationHandler func(state dockerstate.TaskEngineState, dataClient data.
 
 // StartDefaultTerminationHandler defines a default termination handler suitable for running in a process
 func StartDefaultTerminationHandler(state dockerstate.TaskEngineState, dataClient data.Client, taskEngine engine.TaskEngine, cancel context.CancelFunc) {
-	// when we receive a termination signal, first save the state, then
-	// cancel the agent's context so other goroutines can exit cleanly.
-	signalC := make(chan os.Signal, 2)
-	signal.Notify(signalC, os.Interrupt, syscall.SIGTERM)
-
-	sig := <-signalC
-	seelog.Infof("Agent received termination signal: %s", sig.String())
-
-	err := FinalSave(state, dataClient, taskEngine)
-	if err != nil {
-		seelog.Criticalf("Error saving state before final shutdown: %v", err)
-		// Terminal because it's a sigterm; the user doesn't want it to restart
-		os.Exit(exitcodes.ExitTerminal)
+	NewTerminationHandler(nil)(state, dataClient, taskEngine, cancel)
+}
+
+// NewTerminationHandler returns a termination handler suitable for running in a process, which calls
+// beforeSave, if set, once it receives a termination signal and before saving the state
+func NewTerminationHandler(beforeSave func()) TerminationHandler {
+	return func(state dockerstate.TaskEngineState, dataClient data.Client, taskEngine engine.TaskEngine, cancel context.CancelFunc) {
+		// when we receive a termination signal, first save the state, then
+		// cancel the agent's context so other goroutines can exit cleanly.
+		signalC := make(chan os.Signal, 2)
+		signal.Notify(signalC, os.Interrupt, syscall.SIGTERM)
+
+		sig := <-signalC
+		seelog.Infof("Agent received termination signal: %s", sig.String())
+
+		if beforeSave != nil {
+			beforeSave()
+		}
+		err := FinalSave(state, dataClient, taskEngine)
+		if err != nil {
+			seelog.Criticalf("Error saving state before final shutdown: %v", err)
+			// Terminal because it's a sigterm; the user doesn't want it to restart
+			os.Exit(exitcodes.ExitTerminal)
+		}
+		cancel()
 	}
-	cancel()
 }
 
 // FinalSave should be called immediately before exiting, and only before
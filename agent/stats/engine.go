@@ -748,6 +748,10 @@ func (engine *DockerStatsEngine) handleDockerEvents(events ...interface{}) error
 		if !ok {
 			return fmt.Errorf("Unexpected event received, expected docker container change event")
 		}
+		if dockerContainerChangeEvent.Type == apicontainer.ContainerHealthEvent {
+			// Health transitions don't change which containers are being watched
+			continue
+		}
 
 		switch dockerContainerChangeEvent.Status {
 		case apicontainerstatus.ContainerRunning:
@@ -101,6 +101,11 @@ func (session *DockerTelemetrySession) Start(ctx context.Context) error {
 	return session.s.Start(ctx)
 }
 
+// GetLastConnectedTime returns the timestamp that the last connection was established to TCS.
+func (session *DockerTelemetrySession) GetLastConnectedTime() time.Time {
+	return session.s.GetLastConnectedTime()
+}
+
 // generateVersionInfo generates the agentVersion, agentHash and containerRuntimeVersion from dockerTaskEngine state
 func generateVersionInfo(taskEngine engine.TaskEngine) (string, string, string) {
 	agentVersion := version.Version
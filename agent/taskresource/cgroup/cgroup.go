@@ -30,6 +30,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/cgroup/control"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/cgroup/cpuset"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 	"github.com/aws/amazon-ecs-agent/agent/utils/ioutilwrapper"
 	apicontainerstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/container/status"
@@ -68,6 +69,8 @@ type CgroupResource struct {
 	// used while progressing resource states in progressTask() of task manager
 	appliedStatus       resourcestatus.ResourceStatus
 	statusToTransitions map[resourcestatus.ResourceStatus]func() error
+	// cpusetLedger assigns exclusive CPUs to the task, it's nil when CPU pinning is disabled
+	cpusetLedger *cpuset.Ledger
 	// lock is used for fields that are accessed and updated concurrently
 	lock sync.RWMutex
 }
@@ -112,6 +115,13 @@ func (cgroup *CgroupResource) SetIOUtil(ioutil ioutilwrapper.IOUtil) {
 	cgroup.ioutil = ioutil
 }
 
+// SetCPUSetLedger sets the ledger assigning exclusive CPUs to the task
+func (cgroup *CgroupResource) SetCPUSetLedger(ledger *cpuset.Ledger) {
+	cgroup.lock.Lock()
+	defer cgroup.lock.Unlock()
+	cgroup.cpusetLedger = ledger
+}
+
 // SetDesiredStatus safely sets the desired status of the resource
 func (cgroup *CgroupResource) SetDesiredStatus(status resourcestatus.ResourceStatus) {
 	cgroup.lock.Lock()
@@ -274,6 +284,10 @@ func (cgroup *CgroupResource) setupTaskCgroup() error {
 		return nil
 	}
 
+	if err := cgroup.setupCPUSet(); err != nil {
+		return fmt.Errorf("cgroup resource: setup cgroup: unable to assign CPUs taskARN=%s cgroupPath=%s err=%s", cgroup.taskARN, cgroupRoot, err)
+	}
+
 	cgroupSpec := control.Spec{
 		Root:  cgroupRoot,
 		Specs: &cgroup.resourceSpec,
@@ -297,8 +311,80 @@ func (cgroup *CgroupResource) setupTaskCgroup() error {
 	return nil
 }
 
+// setupCPUSet sets the cpuset of the task cgroup when CPU pinning is enabled. Tasks with a CPU limit of whole
+// vCPUs get CPUs exclusively, in the memory nodes of the CPUs, the other tasks share the CPUs left.
+func (cgroup *CgroupResource) setupCPUSet() error {
+	cgroup.lock.Lock()
+	defer cgroup.lock.Unlock()
+
+	if cgroup.cpusetLedger == nil {
+		return nil
+	}
+	if cgroup.resourceSpec.CPU == nil {
+		cgroup.resourceSpec.CPU = &specs.LinuxCPU{}
+	}
+	count := cgroup.exclusiveCPUsUnsafe()
+	if count == 0 {
+		cgroup.resourceSpec.CPU.Cpus = cgroup.cpusetLedger.AddShared(cgroup.taskARN, cgroup.cgroupRoot)
+		return nil
+	}
+	allocation, err := cgroup.cpusetLedger.Allocate(cgroup.taskARN, count)
+	if err != nil {
+		return err
+	}
+	cgroup.resourceSpec.CPU.Cpus = cpuset.FormatList(allocation.CPUs)
+	cgroup.resourceSpec.CPU.Mems = cpuset.FormatList(allocation.Mems)
+	return nil
+}
+
+// exclusiveCPUsUnsafe returns the number of CPUs the task gets exclusively, which is its CPU limit when it's
+// a whole number of vCPUs, and 0 otherwise
+func (cgroup *CgroupResource) exclusiveCPUsUnsafe() int {
+	cpu := cgroup.resourceSpec.CPU
+	if cpu == nil || cpu.Quota == nil || cpu.Period == nil || *cpu.Quota <= 0 || *cpu.Period == 0 {
+		return 0
+	}
+	if uint64(*cpu.Quota)%*cpu.Period != 0 {
+		return 0
+	}
+	return int(uint64(*cpu.Quota) / *cpu.Period)
+}
+
+// restoreCPUSetUnsafe records the cpuset of a task cgroup restored from the agent state in the ledger
+func (cgroup *CgroupResource) restoreCPUSetUnsafe() {
+	if cgroup.cpusetLedger == nil || cgroup.resourceSpec.CPU == nil || cgroup.resourceSpec.CPU.Cpus == "" {
+		return
+	}
+	if cgroup.exclusiveCPUsUnsafe() == 0 {
+		cgroup.cpusetLedger.AddShared(cgroup.taskARN, cgroup.cgroupRoot)
+		return
+	}
+	cpus, cpusErr := cpuset.ParseList(cgroup.resourceSpec.CPU.Cpus)
+	mems, memsErr := cpuset.ParseList(cgroup.resourceSpec.CPU.Mems)
+	if cpusErr != nil || memsErr != nil {
+		seelog.Warnf("Unable to restore exclusive CPUs of task taskARN=%s cpus=%s mems=%s", cgroup.taskARN,
+			cgroup.resourceSpec.CPU.Cpus, cgroup.resourceSpec.CPU.Mems)
+		return
+	}
+	cgroup.cpusetLedger.Restore(cgroup.taskARN, cpuset.Allocation{CPUs: cpus, Mems: mems})
+}
+
+// releaseCPUSet gives the exclusive CPUs of the task back to the ledger
+func (cgroup *CgroupResource) releaseCPUSet() {
+	cgroup.lock.RLock()
+	defer cgroup.lock.RUnlock()
+
+	if cgroup.cpusetLedger == nil {
+		return
+	}
+	cgroup.cpusetLedger.RemoveShared(cgroup.taskARN)
+	cgroup.cpusetLedger.Release(cgroup.taskARN)
+}
+
 // Cleanup removes the cgroup root created for the task
 func (cgroup *CgroupResource) Cleanup() error {
+	// The processes of the task are gone, its CPUs can be given to other tasks even if the cgroup removal fails
+	defer cgroup.releaseCPUSet()
 	err := cgroup.control.Remove(cgroup.cgroupRoot)
 	// Explicitly handle cgroup deleted error
 	if err != nil {
@@ -390,6 +476,10 @@ func (cgroup *CgroupResource) Initialize(
 	cgroup.initializeResourceStatusToTransitionFunction()
 	cgroup.ioutil = resourceFields.IOUtil
 	cgroup.control = resourceFields.Control
+	cgroup.cpusetLedger = resourceFields.CPUSetLedger
+	if cgroup.knownStatusUnsafe == resourcestatus.ResourceStatus(CgroupCreated) {
+		cgroup.restoreCPUSetUnsafe()
+	}
 }
 
 func (cgroup *CgroupResource) DependOnTaskNetwork() bool {
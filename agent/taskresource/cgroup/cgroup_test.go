@@ -23,13 +23,17 @@ import (
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource"
 	cgroup "github.com/aws/amazon-ecs-agent/agent/taskresource/cgroup/control"
 	"github.com/aws/amazon-ecs-agent/agent/taskresource/cgroup/control/mock_control"
+	"github.com/aws/amazon-ecs-agent/agent/taskresource/cgroup/cpuset"
 	resourcestatus "github.com/aws/amazon-ecs-agent/agent/taskresource/status"
 	mock_ioutilwrapper "github.com/aws/amazon-ecs-agent/agent/utils/ioutilwrapper/mocks"
+	apitaskstatus "github.com/aws/amazon-ecs-agent/ecs-agent/api/task/status"
 	cgroups "github.com/containerd/cgroups/v3/cgroup1"
 	specs "github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/golang/mock/gomock"
 )
@@ -202,3 +206,101 @@ func TestUnmarshal(t *testing.T) {
 	assert.Equal(t, resourcestatus.ResourceStatus(CgroupCreated), unmarshalledCgroup.GetDesiredStatus())
 	assert.Equal(t, resourcestatus.ResourceStatus(CgroupStatusNone), unmarshalledCgroup.GetKnownStatus())
 }
+
+func TestCreateWithExclusiveCPUs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockControl := mock_control.NewMockControl(ctrl)
+	mockIO := mock_ioutilwrapper.NewMockIOUtil(ctrl)
+	ledger := cpuset.NewLedgerWithTopology(mockControl, map[int][]int{0: {0, 1, 2, 3}, 1: {4, 5, 6, 7}})
+
+	quota, period := int64(400000), uint64(100000)
+	cgroupRoot := fmt.Sprintf("/ecs/%s", taskID)
+	mockControl.EXPECT().Exists(gomock.Any()).Return(false)
+	mockControl.EXPECT().Create(gomock.Any()).Do(func(spec *cgroup.Spec) {
+		assert.Equal(t, "4-7", spec.Specs.CPU.Cpus)
+		assert.Equal(t, "1", spec.Specs.CPU.Mems)
+	}).Return(nil)
+	mockIO.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	cgroupResource := NewCgroupResource(validTaskArn, mockControl, mockIO, cgroupRoot, cgroupMountPath,
+		specs.LinuxResources{CPU: &specs.LinuxCPU{Quota: &quota, Period: &period}})
+	cgroupResource.SetCPUSetLedger(ledger)
+	assert.NoError(t, cgroupResource.Create())
+
+	// the CPUs are given back to the shared tasks on cleanup
+	mockControl.EXPECT().Remove(cgroupRoot).Return(nil)
+	assert.NoError(t, cgroupResource.Cleanup())
+	assert.Equal(t, "0-7", ledger.AddShared("otherTaskArn", "/ecs/otherTaskID"))
+}
+
+func TestCreateWithExclusiveCPUsNotAvailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockControl := mock_control.NewMockControl(ctrl)
+	ledger := cpuset.NewLedgerWithTopology(mockControl, map[int][]int{0: {0, 1}})
+
+	quota, period := int64(200000), uint64(100000)
+	mockControl.EXPECT().Exists(gomock.Any()).Return(false)
+
+	cgroupResource := NewCgroupResource(validTaskArn, mockControl, nil, fmt.Sprintf("/ecs/%s", taskID),
+		cgroupMountPath, specs.LinuxResources{CPU: &specs.LinuxCPU{Quota: &quota, Period: &period}})
+	cgroupResource.SetCPUSetLedger(ledger)
+	assert.Error(t, cgroupResource.Create())
+}
+
+func TestCreateWithSharedCPUs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockControl := mock_control.NewMockControl(ctrl)
+	mockIO := mock_ioutilwrapper.NewMockIOUtil(ctrl)
+	ledger := cpuset.NewLedgerWithTopology(mockControl, map[int][]int{0: {0, 1, 2, 3}})
+
+	// a fractional vCPU limit shares the CPUs which aren't assigned exclusively
+	quota, period := int64(150000), uint64(100000)
+	cgroupRoot := fmt.Sprintf("/ecs/%s", taskID)
+	mockControl.EXPECT().Exists(gomock.Any()).Return(false)
+	mockControl.EXPECT().Create(gomock.Any()).Do(func(spec *cgroup.Spec) {
+		assert.Equal(t, "0-3", spec.Specs.CPU.Cpus)
+		assert.Empty(t, spec.Specs.CPU.Mems)
+	}).Return(nil)
+	mockIO.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	cgroupResource := NewCgroupResource(validTaskArn, mockControl, mockIO, cgroupRoot, cgroupMountPath,
+		specs.LinuxResources{CPU: &specs.LinuxCPU{Quota: &quota, Period: &period}})
+	cgroupResource.SetCPUSetLedger(ledger)
+	require.NoError(t, cgroupResource.Create())
+
+	// the shared task cgroup is narrowed when CPUs are assigned exclusively
+	mockControl.EXPECT().SetCPUSet(cgroupRoot, "0,3", "").Return(nil)
+	_, err := ledger.Allocate("otherTaskArn", 2)
+	require.NoError(t, err)
+
+	// the cgroup is no longer updated once it's cleaned up
+	mockControl.EXPECT().Remove(cgroupRoot).Return(nil)
+	require.NoError(t, cgroupResource.Cleanup())
+	ledger.Release("otherTaskArn")
+}
+
+func TestInitializeRestoresExclusiveCPUs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockControl := mock_control.NewMockControl(ctrl)
+	ledger := cpuset.NewLedgerWithTopology(mockControl, map[int][]int{0: {0, 1, 2, 3}})
+
+	quota, period := int64(200000), uint64(100000)
+	cgroupResource := NewCgroupResource(validTaskArn, mockControl, nil, fmt.Sprintf("/ecs/%s", taskID),
+		cgroupMountPath, specs.LinuxResources{CPU: &specs.LinuxCPU{Quota: &quota, Period: &period, Cpus: "1-2", Mems: "0"}})
+	cgroupResource.SetKnownStatus(resourcestatus.ResourceStatus(CgroupCreated))
+	cgroupResource.Initialize(&config.Config{}, &taskresource.ResourceFields{
+		Control:              mockControl,
+		ResourceFieldsCommon: &taskresource.ResourceFieldsCommon{},
+		CPUSetLedger:         ledger,
+	}, apitaskstatus.TaskRunning, apitaskstatus.TaskRunning)
+
+	assert.Equal(t, "0,3", ledger.AddShared("otherTaskArn", "/ecs/otherTaskID"))
+}
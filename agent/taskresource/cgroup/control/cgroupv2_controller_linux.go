@@ -19,6 +19,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/aws/amazon-ecs-agent/agent/config"
 	"github.com/cihub/seelog"
@@ -33,6 +35,8 @@ const (
 	// containers, then we use a dummy PID of -1.
 	// see https://github.com/containerd/cgroups/blob/1df78138f1e1e6ee593db155c6b369466f577651/v2/manager.go#L732-L735
 	generalSlicePID int = -1
+	// cgroupv2FilePerm is the mode the cgroup v2 files are written with
+	cgroupv2FilePerm = os.FileMode(0644)
 )
 
 // controlv2 is used to implement the cgroup Control interface
@@ -58,6 +62,18 @@ func (c *controlv2) Create(cgroupSpec *Spec) error {
 		return fmt.Errorf("cgroupv2 create: unable initialize cgroup controllers: %w", err)
 	}
 
+	// systemd doesn't set the cpuset of the slice, it's set once the slice is created
+	if cpu := cgroupSpec.Specs.CPU; cpu != nil && (cpu.Cpus != "" || cpu.Mems != "") {
+		if err := setCPUSetV2(m, cpu.Cpus, cpu.Mems); err != nil {
+			return fmt.Errorf("cgroupv2 create: %w", err)
+		}
+	}
+
+	// the cgroup v2 controls without a systemd property, such as memory.high and memory.oom.group
+	if err := setUnifiedV2(cgroupPath, cgroupSpec.Specs.Unified); err != nil {
+		return fmt.Errorf("cgroupv2 create: %w", err)
+	}
+
 	return nil
 }
 
@@ -138,6 +154,25 @@ func validateController(controller string, controllers []string) error {
 	return fmt.Errorf("unable to validate cgroup controllers, did not find %s controller in list of controllers=%v", controller, controllers)
 }
 
+// setUnifiedV2 writes the cgroup v2 files of the unified resources of the spec in the cgroup
+func setUnifiedV2(cgroupPath string, unified map[string]string) error {
+	files := make([]string, 0, len(unified))
+	for file := range unified {
+		if strings.ContainsRune(file, filepath.Separator) {
+			return fmt.Errorf("invalid cgroup file %s", file)
+		}
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		path := filepath.Join(fullCgroupPath(cgroupPath), file)
+		if err := os.WriteFile(path, []byte(unified[file]), cgroupv2FilePerm); err != nil {
+			return fmt.Errorf("unable to set %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
 // fullCgroupPath returns the full path on disk to a task cgroup slice.
 // example: /sys/fs/cgroup/ecstasks.slice/ecstasks-529630467358463ab6bbba4e73afe704.slice
 func fullCgroupPath(cgroupPath string) string {
@@ -0,0 +1,105 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package control
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/cihub/seelog"
+	cgroupsv2 "github.com/containerd/cgroups/v3/cgroup2"
+)
+
+const (
+	cpusetCPUsFile = "cpuset.cpus"
+	cpusetMemsFile = "cpuset.mems"
+	cpusetFilePerm = os.FileMode(0644)
+)
+
+// cgroupV1CPUSetPath is the mount point of the cgroup v1 cpuset hierarchy
+var cgroupV1CPUSetPath = "/sys/fs/cgroup/cpuset"
+
+// SetCPUSet restricts the cgroup and its children to the CPUs and memory nodes. In cgroup v1, the cpuset of a
+// cgroup must include the cpusets of its children, the containers of the task, so the cgroups are first widened
+// to the cpuset of the parent cgroup top-down, then narrowed to the new cpuset bottom-up.
+func (c *control) SetCPUSet(cgroupPath, cpus, mems string) error {
+	seelog.Debugf("Setting cgroup cpuset cgroupPath=%s cpus=%s mems=%s", cgroupPath, cpus, mems)
+	root := filepath.Join(cgroupV1CPUSetPath, cgroupPath)
+	var cgroups []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			cgroups = append(cgroups, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("cgroup set cpuset: unable to list cgroups: %w", err)
+	}
+
+	for _, file := range []struct {
+		name  string
+		value string
+	}{
+		{name: cpusetCPUsFile, value: cpus},
+		{name: cpusetMemsFile, value: mems},
+	} {
+		if file.value == "" {
+			continue
+		}
+		parentValue, err := os.ReadFile(filepath.Join(filepath.Dir(root), file.name))
+		if err != nil {
+			return fmt.Errorf("cgroup set cpuset: unable to read parent %s: %w", file.name, err)
+		}
+		for _, cgroup := range cgroups {
+			if err := os.WriteFile(filepath.Join(cgroup, file.name), parentValue, cpusetFilePerm); err != nil {
+				return fmt.Errorf("cgroup set cpuset: unable to widen %s of %s: %w", file.name, cgroup, err)
+			}
+		}
+		for i := len(cgroups) - 1; i >= 0; i-- {
+			if err := os.WriteFile(filepath.Join(cgroups[i], file.name), []byte(file.value), cpusetFilePerm); err != nil {
+				return fmt.Errorf("cgroup set cpuset: unable to set %s of %s: %w", file.name, cgroups[i], err)
+			}
+		}
+	}
+	return nil
+}
+
+// SetCPUSet restricts the cgroup to the CPUs and memory nodes. The effective cpusets of the children of the
+// cgroup, the containers of the task, follow the cpuset of the cgroup in cgroup v2.
+func (c *controlv2) SetCPUSet(cgroupPath, cpus, mems string) error {
+	seelog.Debugf("Setting cgroup cpuset cgroupPath=%s cpus=%s mems=%s", cgroupPath, cpus, mems)
+	m, err := cgroupsv2.LoadSystemd(parentCgroupSlice, cgroupPath)
+	if err != nil {
+		return fmt.Errorf("cgroupv2 set cpuset: error loading systemd cgroup: %w", err)
+	}
+	return setCPUSetV2(m, cpus, mems)
+}
+
+func setCPUSetV2(m *cgroupsv2.Manager, cpus, mems string) error {
+	if err := m.ToggleControllers([]string{"cpuset"}, cgroupsv2.Enable); err != nil {
+		return fmt.Errorf("cgroupv2 set cpuset: error enabling cpuset controller: %w", err)
+	}
+	if err := m.Update(&cgroupsv2.Resources{CPU: &cgroupsv2.CPU{Cpus: cpus, Mems: mems}}); err != nil {
+		return fmt.Errorf("cgroupv2 set cpuset: unable to set cpuset: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package control
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetCPUSetV1(t *testing.T) {
+	defer func(path string) { cgroupV1CPUSetPath = path }(cgroupV1CPUSetPath)
+	cgroupV1CPUSetPath = t.TempDir()
+
+	parent := filepath.Join(cgroupV1CPUSetPath, "ecs")
+	task := filepath.Join(parent, "task1")
+	container := filepath.Join(task, "container1")
+	require.NoError(t, os.MkdirAll(container, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(parent, cpusetCPUsFile), []byte("0-7\n"), cpusetFilePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(parent, cpusetMemsFile), []byte("0-1\n"), cpusetFilePerm))
+
+	c := &control{}
+	require.NoError(t, c.SetCPUSet("/ecs/task1", "2-3", "0"))
+	for _, cgroup := range []string{task, container} {
+		cpus, err := os.ReadFile(filepath.Join(cgroup, cpusetCPUsFile))
+		require.NoError(t, err)
+		assert.Equal(t, "2-3", string(cpus))
+		mems, err := os.ReadFile(filepath.Join(cgroup, cpusetMemsFile))
+		require.NoError(t, err)
+		assert.Equal(t, "0", string(mems))
+	}
+
+	// memory nodes are left as they are when not specified
+	require.NoError(t, c.SetCPUSet("/ecs/task1", "0-1,4-7", ""))
+	cpus, err := os.ReadFile(filepath.Join(container, cpusetCPUsFile))
+	require.NoError(t, err)
+	assert.Equal(t, "0-1,4-7", string(cpus))
+	mems, err := os.ReadFile(filepath.Join(container, cpusetMemsFile))
+	require.NoError(t, err)
+	assert.Equal(t, "0", string(mems))
+}
+
+func TestSetCPUSetV1CgroupNotFound(t *testing.T) {
+	defer func(path string) { cgroupV1CPUSetPath = path }(cgroupV1CPUSetPath)
+	cgroupV1CPUSetPath = t.TempDir()
+
+	c := &control{}
+	assert.Error(t, c.SetCPUSet("/ecs/task1", "2-3", "0"))
+}
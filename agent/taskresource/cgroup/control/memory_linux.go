@@ -0,0 +1,80 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package control
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/cihub/seelog"
+)
+
+const (
+	memoryHighFile = "memory.high"
+	// cgroupV2Prefix prefixes the entry of the cgroup v2 hierarchy in /proc/<pid>/cgroup
+	cgroupV2Prefix = "0::"
+)
+
+var (
+	// hostProcPath is where the proc filesystem of the host is mounted in the agent container
+	hostProcPath = "/host/proc"
+	// cgroupV2MountPath is the mount point of the cgroup v2 hierarchy
+	cgroupV2MountPath = defaultCgroupv2Path
+)
+
+// SetMemoryHigh is not supported in cgroup v1, which has no memory.high.
+func (c *control) SetMemoryHigh(pid int, memoryHighBytes int64) error {
+	return errors.New("cgroup set memory.high: memory.high requires cgroup v2")
+}
+
+// SetMemoryHigh sets the memory.high of the cgroup the process belongs to, which is the container cgroup
+// for the init process of a container.
+func (c *controlv2) SetMemoryHigh(pid int, memoryHighBytes int64) error {
+	seelog.Debugf("Setting cgroup memory.high pid=%d memoryHighBytes=%d", pid, memoryHighBytes)
+	cgroupPath, err := processCgroupV2Path(pid)
+	if err != nil {
+		return fmt.Errorf("cgroupv2 set memory.high: %w", err)
+	}
+	path := filepath.Join(cgroupV2MountPath, cgroupPath, memoryHighFile)
+	if err := os.WriteFile(path, []byte(strconv.FormatInt(memoryHighBytes, 10)), cgroupv2FilePerm); err != nil {
+		return fmt.Errorf("cgroupv2 set memory.high: unable to set %s: %w", path, err)
+	}
+	return nil
+}
+
+// processCgroupV2Path returns the path of the cgroup v2 the process belongs to, relative to the mount point
+// of the hierarchy.
+func processCgroupV2Path(pid int) (string, error) {
+	procCgroup := filepath.Join(hostProcPath, strconv.Itoa(pid), "cgroup")
+	data, err := os.ReadFile(procCgroup)
+	if err != nil {
+		return "", fmt.Errorf("unable to read %s: %w", procCgroup, err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if cgroupPath, ok := strings.CutPrefix(scanner.Text(), cgroupV2Prefix); ok {
+			return cgroupPath, nil
+		}
+	}
+	return "", fmt.Errorf("no cgroup v2 entry in %s", procCgroup)
+}
@@ -0,0 +1,64 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package control
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetMemoryHighV2(t *testing.T) {
+	defer func(proc, mount string) { hostProcPath, cgroupV2MountPath = proc, mount }(hostProcPath, cgroupV2MountPath)
+	hostProcPath = t.TempDir()
+	cgroupV2MountPath = t.TempDir()
+
+	const pid = 1234
+	containerCgroup := "/ecstasks.slice/ecstasks-task1.slice/docker-container1.scope"
+	require.NoError(t, os.MkdirAll(filepath.Join(hostProcPath, strconv.Itoa(pid)), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(hostProcPath, strconv.Itoa(pid), "cgroup"),
+		[]byte("0::"+containerCgroup+"\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(cgroupV2MountPath, containerCgroup), 0755))
+
+	c := &controlv2{}
+	require.NoError(t, c.SetMemoryHigh(pid, 256*1024*1024))
+	memoryHigh, err := os.ReadFile(filepath.Join(cgroupV2MountPath, containerCgroup, memoryHighFile))
+	require.NoError(t, err)
+	assert.Equal(t, "268435456", string(memoryHigh))
+}
+
+func TestSetMemoryHighV2NoCgroupV2Entry(t *testing.T) {
+	defer func(proc string) { hostProcPath = proc }(hostProcPath)
+	hostProcPath = t.TempDir()
+
+	const pid = 1234
+	require.NoError(t, os.MkdirAll(filepath.Join(hostProcPath, strconv.Itoa(pid)), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(hostProcPath, strconv.Itoa(pid), "cgroup"),
+		[]byte("4:memory:/ecs/task1/container1\n"), 0644))
+
+	c := &controlv2{}
+	assert.Error(t, c.SetMemoryHigh(pid, 256*1024*1024))
+}
+
+func TestSetMemoryHighV1(t *testing.T) {
+	c := &control{}
+	assert.Error(t, c.SetMemoryHigh(1234, 256*1024*1024))
+}
@@ -103,3 +103,31 @@ func (mr *MockControlMockRecorder) Remove(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockControl)(nil).Remove), arg0)
 }
+
+// SetCPUSet mocks base method.
+func (m *MockControl) SetCPUSet(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCPUSet", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCPUSet indicates an expected call of SetCPUSet.
+func (mr *MockControlMockRecorder) SetCPUSet(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCPUSet", reflect.TypeOf((*MockControl)(nil).SetCPUSet), arg0, arg1, arg2)
+}
+
+// SetMemoryHigh mocks base method.
+func (m *MockControl) SetMemoryHigh(arg0 int, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMemoryHigh", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMemoryHigh indicates an expected call of SetMemoryHigh.
+func (mr *MockControlMockRecorder) SetMemoryHigh(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMemoryHigh", reflect.TypeOf((*MockControl)(nil).SetMemoryHigh), arg0, arg1)
+}
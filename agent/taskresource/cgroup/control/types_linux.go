@@ -34,4 +34,10 @@ type Control interface {
 	Remove(cgroupPath string) error
 	Exists(cgroupPath string) bool
 	Init() error
+	// SetCPUSet restricts the tasks of the cgroup to the CPUs and memory nodes given as cpuset lists, e.g.
+	// "2-3,6". An empty list leaves the current setting unchanged.
+	SetCPUSet(cgroupPath, cpus, mems string) error
+	// SetMemoryHigh sets the memory.high of the cgroup of the process, above which the processes of the cgroup
+	// are throttled and have their memory reclaimed. It requires cgroup v2.
+	SetMemoryHigh(pid int, memoryHighBytes int64) error
 }
@@ -0,0 +1,225 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package cpuset assigns exclusive CPUs, and the memory nodes of their NUMA nodes, to tasks requesting whole
+// vCPUs, and keeps the other tasks on the CPUs left.
+package cpuset
+
+import (
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+
+	"github.com/pkg/errors"
+)
+
+// CgroupUpdater updates the cpuset of task cgroups.
+type CgroupUpdater interface {
+	SetCPUSet(cgroupPath, cpus, mems string) error
+}
+
+// Allocation is the CPUs assigned exclusively to a task, and the memory nodes of their NUMA nodes.
+type Allocation struct {
+	CPUs []int
+	Mems []int
+}
+
+// Ledger accounts for the CPUs assigned exclusively to tasks. The CPUs not assigned to any task are shared
+// by the other tasks, whose cgroups are updated as exclusive CPUs are assigned and released. The lowest CPU
+// of the host is never assigned, so that shared tasks and host processes always have a CPU to run on.
+//
+// The ledger isn't persisted itself: the allocations are part of the cgroup resources of tasks, which are
+// saved with the agent state and restored into the ledger when the agent restarts.
+type Ledger struct {
+	lock    sync.Mutex
+	cgroups CgroupUpdater
+	// topology holds the online CPUs of each NUMA node
+	topology    map[int][]int
+	reservedCPU int
+	// exclusive holds the allocation of each task with exclusive CPUs, by task ARN
+	exclusive map[string]Allocation
+	// shared holds the cgroup root of each task sharing the CPUs left, by task ARN
+	shared map[string]string
+}
+
+// NewLedger returns a ledger for the CPUs of the host.
+func NewLedger(cgroups CgroupUpdater) (*Ledger, error) {
+	topology, err := discoverTopology()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to discover CPU topology")
+	}
+	return NewLedgerWithTopology(cgroups, topology), nil
+}
+
+// NewLedgerWithTopology returns a ledger for the given online CPUs of each NUMA node.
+func NewLedgerWithTopology(cgroups CgroupUpdater, topology map[int][]int) *Ledger {
+	reservedCPU := -1
+	for _, cpus := range topology {
+		if reservedCPU < 0 || cpus[0] < reservedCPU {
+			reservedCPU = cpus[0]
+		}
+	}
+	return &Ledger{
+		cgroups:     cgroups,
+		topology:    topology,
+		reservedCPU: reservedCPU,
+		exclusive:   make(map[string]Allocation),
+		shared:      make(map[string]string),
+	}
+}
+
+// Allocate assigns CPUs exclusively to the task. The CPUs are taken from a single NUMA node when one has
+// enough free CPUs, the one with the fewest, so that larger tasks can still fit on a single node later.
+// Otherwise they are spread over the NUMA nodes with the most free CPUs.
+func (l *Ledger) Allocate(taskARN string, count int) (Allocation, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if allocation, ok := l.exclusive[taskARN]; ok {
+		return allocation, nil
+	}
+
+	free := l.freeCPUsUnsafe()
+	nodes := make([]int, 0, len(free))
+	total := 0
+	for node, cpus := range free {
+		nodes = append(nodes, node)
+		total += len(cpus)
+	}
+	if count > total {
+		return Allocation{}, errors.Errorf("not enough CPUs to assign %d exclusive CPUs, %d free", count, total)
+	}
+
+	// Best fit on a single node, ties broken by the lowest node
+	sort.Slice(nodes, func(i, j int) bool {
+		if len(free[nodes[i]]) != len(free[nodes[j]]) {
+			return len(free[nodes[i]]) < len(free[nodes[j]])
+		}
+		return nodes[i] < nodes[j]
+	})
+	allocation := Allocation{}
+	for _, node := range nodes {
+		if len(free[node]) >= count {
+			allocation.CPUs = free[node][:count]
+			allocation.Mems = []int{node}
+			break
+		}
+	}
+	if allocation.CPUs == nil {
+		// Spread over the nodes with the most free CPUs
+		slices.Reverse(nodes)
+		for _, node := range nodes {
+			take := min(count-len(allocation.CPUs), len(free[node]))
+			if take == 0 {
+				break
+			}
+			allocation.CPUs = append(allocation.CPUs, free[node][:take]...)
+			allocation.Mems = append(allocation.Mems, node)
+		}
+		sort.Ints(allocation.CPUs)
+		sort.Ints(allocation.Mems)
+	}
+
+	l.exclusive[taskARN] = allocation
+	logger.Info("Assigned exclusive CPUs to task", logger.Fields{
+		field.TaskARN: taskARN,
+		"cpus":        FormatList(allocation.CPUs),
+		"mems":        FormatList(allocation.Mems),
+	})
+	l.updateSharedCgroupsUnsafe()
+	return allocation, nil
+}
+
+// Restore records the allocation of a task restored from the agent state.
+func (l *Ledger) Restore(taskARN string, allocation Allocation) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.exclusive[taskARN] = allocation
+}
+
+// Release frees the exclusive CPUs of the task, which are given back to the shared tasks.
+func (l *Ledger) Release(taskARN string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if _, ok := l.exclusive[taskARN]; !ok {
+		return
+	}
+	delete(l.exclusive, taskARN)
+	logger.Info("Released exclusive CPUs of task", logger.Fields{field.TaskARN: taskARN})
+	l.updateSharedCgroupsUnsafe()
+}
+
+// AddShared records a task sharing the CPUs which aren't assigned exclusively, and returns these CPUs as a
+// cpuset list. The cgroup of the task is updated when exclusive CPUs are assigned or released.
+func (l *Ledger) AddShared(taskARN, cgroupRoot string) string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.shared[taskARN] = cgroupRoot
+	return l.sharedCPUsUnsafe()
+}
+
+// RemoveShared stops updating the cgroup of a task sharing CPUs.
+func (l *Ledger) RemoveShared(taskARN string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	delete(l.shared, taskARN)
+}
+
+// freeCPUsUnsafe returns the CPUs of each NUMA node which can be assigned exclusively
+func (l *Ledger) freeCPUsUnsafe() map[int][]int {
+	assigned := make(map[int]bool)
+	for _, allocation := range l.exclusive {
+		for _, cpu := range allocation.CPUs {
+			assigned[cpu] = true
+		}
+	}
+	free := make(map[int][]int)
+	for node, cpus := range l.topology {
+		free[node] = []int{}
+		for _, cpu := range cpus {
+			if cpu != l.reservedCPU && !assigned[cpu] {
+				free[node] = append(free[node], cpu)
+			}
+		}
+	}
+	return free
+}
+
+// sharedCPUsUnsafe returns the CPUs which aren't assigned exclusively as a cpuset list
+func (l *Ledger) sharedCPUsUnsafe() string {
+	shared := []int{l.reservedCPU}
+	for _, cpus := range l.freeCPUsUnsafe() {
+		shared = append(shared, cpus...)
+	}
+	return FormatList(shared)
+}
+
+func (l *Ledger) updateSharedCgroupsUnsafe() {
+	sharedCPUs := l.sharedCPUsUnsafe()
+	for taskARN, cgroupRoot := range l.shared {
+		if err := l.cgroups.SetCPUSet(cgroupRoot, sharedCPUs, ""); err != nil {
+			logger.Warn("Unable to update the CPUs of task sharing CPUs", logger.Fields{
+				field.TaskARN: taskARN,
+				"cpus":        sharedCPUs,
+				field.Error:   err,
+			})
+		}
+	}
+}
@@ -0,0 +1,175 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cpuset
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	taskARN1 = "arn:aws:ecs:us-west-2:123456789012:task/cluster/task1"
+	taskARN2 = "arn:aws:ecs:us-west-2:123456789012:task/cluster/task2"
+	taskARN3 = "arn:aws:ecs:us-west-2:123456789012:task/cluster/task3"
+)
+
+type cpusetUpdate struct {
+	cgroupPath string
+	cpus       string
+}
+
+type fakeCgroupUpdater struct {
+	updates []cpusetUpdate
+	err     error
+}
+
+func (f *fakeCgroupUpdater) SetCPUSet(cgroupPath, cpus, mems string) error {
+	f.updates = append(f.updates, cpusetUpdate{cgroupPath: cgroupPath, cpus: cpus})
+	return f.err
+}
+
+func twoNodeTopology() map[int][]int {
+	return map[int][]int{
+		0: {0, 1, 2, 3},
+		1: {4, 5, 6, 7},
+	}
+}
+
+func TestLedgerAllocateBestFit(t *testing.T) {
+	ledger := NewLedgerWithTopology(&fakeCgroupUpdater{}, twoNodeTopology())
+
+	// node 0 has 3 free CPUs as CPU 0 is reserved, which fits best
+	allocation, err := ledger.Allocate(taskARN1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, Allocation{CPUs: []int{1, 2}, Mems: []int{0}}, allocation)
+
+	allocation, err = ledger.Allocate(taskARN2, 4)
+	require.NoError(t, err)
+	assert.Equal(t, Allocation{CPUs: []int{4, 5, 6, 7}, Mems: []int{1}}, allocation)
+
+	_, err = ledger.Allocate(taskARN3, 2)
+	assert.Error(t, err)
+}
+
+func TestLedgerAllocateIsIdempotent(t *testing.T) {
+	ledger := NewLedgerWithTopology(&fakeCgroupUpdater{}, twoNodeTopology())
+
+	allocation, err := ledger.Allocate(taskARN1, 2)
+	require.NoError(t, err)
+	again, err := ledger.Allocate(taskARN1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, allocation, again)
+}
+
+func TestLedgerAllocateSpreadsOverNodes(t *testing.T) {
+	ledger := NewLedgerWithTopology(&fakeCgroupUpdater{}, twoNodeTopology())
+
+	allocation, err := ledger.Allocate(taskARN1, 5)
+	require.NoError(t, err)
+	assert.Equal(t, Allocation{CPUs: []int{1, 4, 5, 6, 7}, Mems: []int{0, 1}}, allocation)
+}
+
+func TestLedgerNeverAssignsReservedCPU(t *testing.T) {
+	ledger := NewLedgerWithTopology(&fakeCgroupUpdater{}, twoNodeTopology())
+
+	_, err := ledger.Allocate(taskARN1, 8)
+	assert.Error(t, err)
+	allocation, err := ledger.Allocate(taskARN1, 7)
+	require.NoError(t, err)
+	assert.NotContains(t, allocation.CPUs, 0)
+}
+
+func TestLedgerSharedCgroups(t *testing.T) {
+	updater := &fakeCgroupUpdater{}
+	ledger := NewLedgerWithTopology(updater, twoNodeTopology())
+
+	assert.Equal(t, "0-7", ledger.AddShared(taskARN1, "/ecs/task1"))
+
+	_, err := ledger.Allocate(taskARN2, 4)
+	require.NoError(t, err)
+	assert.Equal(t, []cpusetUpdate{{cgroupPath: "/ecs/task1", cpus: "0-3"}}, updater.updates)
+
+	ledger.Release(taskARN2)
+	assert.Equal(t, cpusetUpdate{cgroupPath: "/ecs/task1", cpus: "0-7"}, updater.updates[1])
+
+	// releasing an unknown task doesn't update the shared cgroups
+	ledger.Release(taskARN2)
+	assert.Len(t, updater.updates, 2)
+
+	ledger.RemoveShared(taskARN1)
+	_, err = ledger.Allocate(taskARN2, 4)
+	require.NoError(t, err)
+	assert.Len(t, updater.updates, 2)
+}
+
+func TestLedgerSharedCgroupUpdateError(t *testing.T) {
+	updater := &fakeCgroupUpdater{err: errors.New("cpuset error")}
+	ledger := NewLedgerWithTopology(updater, twoNodeTopology())
+	ledger.AddShared(taskARN1, "/ecs/task1")
+
+	// failing to update a shared cgroup doesn't fail the allocation
+	_, err := ledger.Allocate(taskARN2, 2)
+	assert.NoError(t, err)
+	assert.Len(t, updater.updates, 1)
+}
+
+func TestLedgerRestore(t *testing.T) {
+	ledger := NewLedgerWithTopology(&fakeCgroupUpdater{}, twoNodeTopology())
+	ledger.Restore(taskARN1, Allocation{CPUs: []int{4, 5, 6, 7}, Mems: []int{1}})
+
+	assert.Equal(t, "0-3", ledger.AddShared(taskARN2, "/ecs/task2"))
+	allocation, err := ledger.Allocate(taskARN3, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, allocation.CPUs)
+}
+
+func TestDiscoverTopology(t *testing.T) {
+	defer func(path string) { sysDevicesSystemPath = path }(sysDevicesSystemPath)
+	sysDevicesSystemPath = t.TempDir()
+
+	writeFile := func(path, content string) {
+		path = filepath.Join(sysDevicesSystemPath, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	// hosts without NUMA nodes fall back to the online CPUs
+	writeFile("cpu/online", "0-3\n")
+	topology, err := discoverTopology()
+	require.NoError(t, err)
+	assert.Equal(t, map[int][]int{0: {0, 1, 2, 3}}, topology)
+
+	writeFile("node/node0/cpulist", "0-1\n")
+	writeFile("node/node1/cpulist", "2-3\n")
+	writeFile("node/node2/cpulist", "\n")
+	topology, err = discoverTopology()
+	require.NoError(t, err)
+	assert.Equal(t, map[int][]int{0: {0, 1}, 1: {2, 3}}, topology)
+}
+
+func TestDiscoverTopologyError(t *testing.T) {
+	defer func(path string) { sysDevicesSystemPath = path }(sysDevicesSystemPath)
+	sysDevicesSystemPath = t.TempDir()
+
+	_, err := discoverTopology()
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+//go:build linux && unit
+// +build linux,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cpuset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseList(t *testing.T) {
+	testCases := []struct {
+		list        string
+		expectedIDs []int
+		expectError bool
+	}{
+		{list: "0", expectedIDs: []int{0}},
+		{list: "0-3\n", expectedIDs: []int{0, 1, 2, 3}},
+		{list: "8,0-2,5", expectedIDs: []int{0, 1, 2, 5, 8}},
+		{list: "", expectedIDs: nil},
+		{list: "3-1", expectError: true},
+		{list: "a-b", expectError: true},
+		{list: "-1", expectError: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.list, func(t *testing.T) {
+			ids, err := ParseList(tc.list)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedIDs, ids)
+		})
+	}
+}
+
+func TestFormatList(t *testing.T) {
+	assert.Equal(t, "", FormatList(nil))
+	assert.Equal(t, "3", FormatList([]int{3}))
+	assert.Equal(t, "0-3,8", FormatList([]int{8, 0, 1, 2, 3}))
+	assert.Equal(t, "1,3,5-6", FormatList([]int{1, 3, 5, 6}))
+}
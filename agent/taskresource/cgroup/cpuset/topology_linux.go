@@ -0,0 +1,72 @@
+//go:build linux
+// +build linux
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cpuset
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// sysDevicesSystemPath is where the kernel exposes the CPUs and the NUMA nodes of the host
+var sysDevicesSystemPath = "/sys/devices/system"
+
+// discoverTopology returns the online CPUs of each NUMA node of the host. Hosts without NUMA support have all
+// their CPUs in node 0.
+func discoverTopology() (map[int][]int, error) {
+	nodeDirs, err := filepath.Glob(filepath.Join(sysDevicesSystemPath, "node", "node[0-9]*"))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to list NUMA nodes")
+	}
+	topology := make(map[int][]int)
+	for _, nodeDir := range nodeDirs {
+		node, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(nodeDir), "node"))
+		if err != nil {
+			continue
+		}
+		cpus, err := readList(filepath.Join(nodeDir, "cpulist"))
+		if err != nil {
+			return nil, err
+		}
+		if len(cpus) > 0 {
+			topology[node] = cpus
+		}
+	}
+	if len(topology) > 0 {
+		return topology, nil
+	}
+
+	cpus, err := readList(filepath.Join(sysDevicesSystemPath, "cpu", "online"))
+	if err != nil {
+		return nil, err
+	}
+	if len(cpus) == 0 {
+		return nil, errors.New("no online CPUs found")
+	}
+	return map[int][]int{0: cpus}, nil
+}
+
+func readList(path string) ([]int, error) {
+	list, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read %s", path)
+	}
+	return ParseList(string(list))
+}
@@ -15,6 +15,7 @@ package utils
 
 import (
 	"fmt"
+	"io"
 	"math/rand"
 	"net"
 	"strconv"
@@ -42,6 +43,7 @@ var (
 	// Injection point for UTs
 	randIntFunc         = rand.Intn
 	isPortAvailableFunc = isPortAvailable
+	ipv6SupportedFunc   = isIPv6Supported
 	// portLock is a mutex lock used to prevent two concurrent tasks to get the same host ports.
 	portLock sync.Mutex
 )
@@ -110,6 +112,10 @@ func ResetTracker() {
 func GetHostPortRange(numberOfPorts int, protocol string, dynamicHostPortRange string) (string, error) {
 	portLock.Lock()
 	defer portLock.Unlock()
+	return getHostPortRangeWithinRange(numberOfPorts, protocol, dynamicHostPortRange)
+}
+
+func getHostPortRangeWithinRange(numberOfPorts int, protocol string, dynamicHostPortRange string) (string, error) {
 	result, err := getNumOfHostPorts(numberOfPorts, protocol, dynamicHostPortRange)
 	if err == nil {
 		// Verify the found host port range is within the given dynamic host port range
@@ -127,6 +133,10 @@ func GetHostPortRange(numberOfPorts int, protocol string, dynamicHostPortRange s
 func GetHostPort(protocol string, dynamicHostPortRange string) (string, error) {
 	portLock.Lock()
 	defer portLock.Unlock()
+	return getHostPortWithinRange(protocol, dynamicHostPortRange)
+}
+
+func getHostPortWithinRange(protocol string, dynamicHostPortRange string) (string, error) {
 	numberOfPorts := 1
 	result, err := getNumOfHostPorts(numberOfPorts, protocol, dynamicHostPortRange)
 	foundHostPort := strings.Split(result, "-")[0]
@@ -178,6 +188,10 @@ func getNumOfHostPorts(numberOfPorts int, protocol, dynamicHostPortRange string)
 func getHostPortRange(numberOfPorts, start, end int, protocol string) (string, int, error) {
 	var resultStartPort, resultEndPort, n int
 	for port := start; port <= end; port++ {
+		if ledger.isReserved(port, protocol) {
+			// the port is reserved by a task, even if nothing is listening on it right now
+			continue
+		}
 		isAvailable, err := isPortAvailableFunc(port, protocol)
 		if !isAvailable || err != nil {
 			// either port is unavailable or some error occurred while listening or closing the listener,
@@ -211,39 +225,54 @@ func getHostPortRange(numberOfPorts, start, end int, protocol string) (string, i
 	return fmt.Sprintf("%d-%d", resultStartPort, resultEndPort), resultEndPort, nil
 }
 
-// isPortAvailable checks if a port is available
+// isPortAvailable checks if a port is available on the IPv4 and the IPv6 wildcard addresses. Docker binds
+// the host ports of bridge mode containers on both address families, so a port in use on either of them can't
+// be assigned. The IPv6 address family is only checked if it is supported by the host.
 func isPortAvailable(port int, protocol string) (bool, error) {
+	if protocol != "tcp" && protocol != "udp" {
+		return false, errors.New("invalid protocol")
+	}
 	portStr := strconv.Itoa(port)
-	switch protocol {
-	case "tcp":
+	available, err := isPortAvailableOnNetwork(protocol+"4", net.JoinHostPort(net.IPv4zero.String(), portStr))
+	if !available || err != nil || !ipv6SupportedFunc() {
+		return available, err
+	}
+	return isPortAvailableOnNetwork(protocol+"6", net.JoinHostPort(net.IPv6unspecified.String(), portStr))
+}
+
+// isPortAvailableOnNetwork checks if an address is available on the given tcp or udp network
+func isPortAvailableOnNetwork(network, address string) (bool, error) {
+	var ln io.Closer
+	var err error
+	if strings.HasPrefix(network, "tcp") {
 		// net.Listen announces on the local tcp network
-		ln, err := net.Listen(protocol, ":"+portStr)
-		if err != nil {
-			return false, err
-		}
-		// let's close the listener first
-		err = ln.Close()
-		if err != nil {
-			return false, err
-		}
-		return true, nil
-	case "udp":
+		ln, err = net.Listen(network, address)
+	} else {
 		// net.ListenPacket announces on the local udp network
-		ln, err := net.ListenPacket(protocol, ":"+portStr)
-		if err != nil {
-			return false, err
-		}
-		// let's close the listener first
-		err = ln.Close()
-		if err != nil {
-			return false, err
-		}
-		return true, nil
-	default:
-		return false, errors.New("invalid protocol")
+		ln, err = net.ListenPacket(network, address)
+	}
+	if err != nil {
+		return false, err
+	}
+	// let's close the listener first
+	err = ln.Close()
+	if err != nil {
+		return false, err
 	}
+	return true, nil
 }
 
+// isIPv6Supported returns whether the host supports the IPv6 address family, by listening on an ephemeral
+// port of the IPv6 wildcard address. The result is cached for the lifetime of the process.
+var isIPv6Supported = sync.OnceValue(func() bool {
+	ln, err := net.Listen("tcp6", net.JoinHostPort(net.IPv6unspecified.String(), "0"))
+	if err != nil {
+		return false
+	}
+	ln.Close()
+	return true
+})
+
 // PortIsInRange returns true if the given port is within the start-end port range;
 // otherwise, returns false.
 func portIsInRange(port, start, end int) bool {
@@ -18,13 +18,17 @@ package utils
 
 import (
 	"errors"
+	"io"
 	"math/rand"
+	"net"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/docker/go-connections/nat"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -313,3 +317,96 @@ func getPortRangeLength(portRange string) (int, error) {
 	}
 	return endPort - startPort + 1, nil
 }
+
+func TestIsPortAvailable(t *testing.T) {
+	tcs := []struct {
+		name     string
+		network  string
+		address  string
+		protocol string
+	}{
+		{
+			name:     "tcp port in use on IPv4",
+			network:  "tcp4",
+			address:  "0.0.0.0:0",
+			protocol: testTCPProtocol,
+		},
+		{
+			name:     "tcp port in use on IPv6",
+			network:  "tcp6",
+			address:  "[::]:0",
+			protocol: testTCPProtocol,
+		},
+		{
+			name:     "udp port in use on IPv4",
+			network:  "udp4",
+			address:  "0.0.0.0:0",
+			protocol: testUDPProtocol,
+		},
+		{
+			name:     "udp port in use on IPv6",
+			network:  "udp6",
+			address:  "[::]:0",
+			protocol: testUDPProtocol,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if strings.HasSuffix(tc.network, "6") && !ipv6SupportedFunc() {
+				t.Skip("IPv6 is not supported on this host")
+			}
+			var ln io.Closer
+			var port int
+			var err error
+			if tc.protocol == testTCPProtocol {
+				var l net.Listener
+				l, err = net.Listen(tc.network, tc.address)
+				if err == nil {
+					ln, port = l, l.Addr().(*net.TCPAddr).Port
+				}
+			} else {
+				var l net.PacketConn
+				l, err = net.ListenPacket(tc.network, tc.address)
+				if err == nil {
+					ln, port = l, l.LocalAddr().(*net.UDPAddr).Port
+				}
+			}
+			require.NoError(t, err)
+
+			available, err := isPortAvailable(port, tc.protocol)
+			assert.False(t, available)
+			assert.Error(t, err)
+
+			require.NoError(t, ln.Close())
+			available, err = isPortAvailable(port, tc.protocol)
+			assert.True(t, available)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestIsPortAvailableIPv6NotSupported(t *testing.T) {
+	ipv6SupportedFuncTmp := ipv6SupportedFunc
+	defer func() {
+		ipv6SupportedFunc = ipv6SupportedFuncTmp
+	}()
+	if !ipv6SupportedFunc() {
+		t.Skip("IPv6 is not supported on this host")
+	}
+	ln, err := net.Listen("tcp6", "[::]:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	// A port in use on IPv6 is available if the host doesn't support IPv6
+	ipv6SupportedFunc = func() bool { return false }
+	available, err := isPortAvailable(port, testTCPProtocol)
+	assert.True(t, available)
+	assert.NoError(t, err)
+}
+
+func TestIsPortAvailableInvalidProtocol(t *testing.T) {
+	available, err := isPortAvailable(EphemeralPortMin, "sctp")
+	assert.False(t, available)
+	assert.Error(t, err)
+}
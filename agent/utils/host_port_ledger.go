@@ -0,0 +1,264 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+
+	"github.com/docker/go-connections/nat"
+)
+
+// HostPortReservation records the host ports assigned by ECS Agent to the containers of a task.
+type HostPortReservation struct {
+	TaskARN string
+	Ranges  []HostPortRange
+}
+
+// HostPortRange is a contiguous range of host ports of a protocol assigned to a container.
+type HostPortRange struct {
+	ContainerName string
+	Protocol      string
+	StartPort     int
+	EndPort       int
+}
+
+// HostPortUsage summarizes the reservations of a protocol within the dynamic host port range.
+type HostPortUsage struct {
+	Protocol  string
+	Total     int
+	Reserved  int
+	Available int
+}
+
+// HostPortReservationStore persists the host port reservations, so that they survive agent restarts.
+type HostPortReservationStore interface {
+	// SaveHostPortReservation saves the host port reservation of a task.
+	SaveHostPortReservation(*HostPortReservation) error
+	// DeleteHostPortReservation deletes the host port reservation of a task.
+	DeleteHostPortReservation(taskARN string) error
+}
+
+// hostPortLedger records which task holds which host ports. Reserved ports are never assigned to another
+// task, even if nothing is listening on them, e.g. while the containers of the task are restarted.
+type hostPortLedger struct {
+	mu           sync.Mutex
+	reservations map[string]*HostPortReservation
+	store        HostPortReservationStore
+}
+
+var ledger = &hostPortLedger{reservations: make(map[string]*HostPortReservation)}
+
+// SetHostPortReservationStore sets the store the host port reservations are persisted to.
+func SetHostPortReservationStore(store HostPortReservationStore) {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+	ledger.store = store
+}
+
+// RestoreHostPortReservations replaces the host port reservations with the ones loaded from the store.
+func RestoreHostPortReservations(reservations []*HostPortReservation) {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+	ledger.reservations = make(map[string]*HostPortReservation)
+	for _, reservation := range reservations {
+		ledger.reservations[reservation.TaskARN] = reservation.copy()
+	}
+}
+
+// ReserveHostPortRange gets N contiguous host ports from the dynamic host port range, like GetHostPortRange,
+// and reserves them for the container of the task.
+func ReserveHostPortRange(taskARN, containerName string, numberOfPorts int, protocol string,
+	dynamicHostPortRange string) (string, error) {
+	portLock.Lock()
+	defer portLock.Unlock()
+	result, err := getHostPortRangeWithinRange(numberOfPorts, protocol, dynamicHostPortRange)
+	if err != nil {
+		return "", err
+	}
+	ledger.reserve(taskARN, containerName, protocol, result)
+	return result, nil
+}
+
+// ReserveHostPort gets 1 host port from the dynamic host port range, like GetHostPort, and reserves it for
+// the container of the task.
+func ReserveHostPort(taskARN, containerName, protocol, dynamicHostPortRange string) (string, error) {
+	portLock.Lock()
+	defer portLock.Unlock()
+	result, err := getHostPortWithinRange(protocol, dynamicHostPortRange)
+	if err != nil {
+		return "", err
+	}
+	ledger.reserve(taskARN, containerName, protocol, result)
+	return result, nil
+}
+
+// ReleaseContainerHostPorts releases the host ports reserved for the container of the task. The host ports of a
+// container are released before they are assigned again, so that retries of the container creation don't
+// accumulate reservations.
+func ReleaseContainerHostPorts(taskARN, containerName string) {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+	reservation, ok := ledger.reservations[taskARN]
+	if !ok {
+		return
+	}
+	var ranges []HostPortRange
+	for _, r := range reservation.Ranges {
+		if r.ContainerName != containerName {
+			ranges = append(ranges, r)
+		}
+	}
+	if len(ranges) == len(reservation.Ranges) {
+		return
+	}
+	if len(ranges) == 0 {
+		ledger.deleteLocked(taskARN)
+		return
+	}
+	reservation.Ranges = ranges
+	ledger.saveLocked(reservation)
+}
+
+// ReleaseHostPorts releases the host ports reserved for the task.
+func ReleaseHostPorts(taskARN string) {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+	if _, ok := ledger.reservations[taskARN]; !ok {
+		return
+	}
+	ledger.deleteLocked(taskARN)
+}
+
+// GetHostPortReservations returns the host port reservations sorted by task ARN.
+func GetHostPortReservations() []*HostPortReservation {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+	reservations := make([]*HostPortReservation, 0, len(ledger.reservations))
+	for _, reservation := range ledger.reservations {
+		reservations = append(reservations, reservation.copy())
+	}
+	sort.Slice(reservations, func(i, j int) bool {
+		return reservations[i].TaskARN < reservations[j].TaskARN
+	})
+	return reservations
+}
+
+// GetHostPortUsage returns the number of reserved and available ports of each protocol within the dynamic
+// host port range. Ports in use by processes other than tasks are counted as available.
+func GetHostPortUsage(dynamicHostPortRange string) ([]HostPortUsage, error) {
+	start, end, err := nat.ParsePortRangeToInt(dynamicHostPortRange)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dynamic host port range %q: %w", dynamicHostPortRange, err)
+	}
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+	var usage []HostPortUsage
+	for _, protocol := range []string{"tcp", "udp"} {
+		total := end - start + 1
+		reserved := 0
+		for port := start; port <= end; port++ {
+			if ledger.isReservedLocked(port, protocol) {
+				reserved++
+			}
+		}
+		usage = append(usage, HostPortUsage{
+			Protocol:  protocol,
+			Total:     total,
+			Reserved:  reserved,
+			Available: total - reserved,
+		})
+	}
+	return usage, nil
+}
+
+// reserve records the host port or host port range for the container of the task and persists the reservation.
+func (l *hostPortLedger) reserve(taskARN, containerName, protocol, portRange string) {
+	start, end, err := nat.ParsePortRangeToInt(portRange)
+	if err != nil || start == 0 {
+		// port 0 lets the kernel pick the port, there is nothing to reserve
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	reservation, ok := l.reservations[taskARN]
+	if !ok {
+		reservation = &HostPortReservation{TaskARN: taskARN}
+		l.reservations[taskARN] = reservation
+	}
+	reservation.Ranges = append(reservation.Ranges, HostPortRange{
+		ContainerName: containerName,
+		Protocol:      protocol,
+		StartPort:     start,
+		EndPort:       end,
+	})
+	l.saveLocked(reservation)
+}
+
+// saveLocked persists the reservation. The reservation is kept in memory if it can't be saved, it is only lost
+// if the agent restarts.
+func (l *hostPortLedger) saveLocked(reservation *HostPortReservation) {
+	if l.store == nil {
+		return
+	}
+	if err := l.store.SaveHostPortReservation(reservation.copy()); err != nil {
+		logger.Warn("Failed to save host port reservation", logger.Fields{
+			field.TaskARN: reservation.TaskARN,
+			field.Error:   err,
+		})
+	}
+}
+
+// deleteLocked removes the reservation of the task from memory and from the store.
+func (l *hostPortLedger) deleteLocked(taskARN string) {
+	delete(l.reservations, taskARN)
+	if l.store == nil {
+		return
+	}
+	if err := l.store.DeleteHostPortReservation(taskARN); err != nil {
+		logger.Warn("Failed to delete host port reservation", logger.Fields{
+			field.TaskARN: taskARN,
+			field.Error:   err,
+		})
+	}
+}
+
+// isReserved returns whether the port of the protocol is reserved by any task.
+func (l *hostPortLedger) isReserved(port int, protocol string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.isReservedLocked(port, protocol)
+}
+
+func (l *hostPortLedger) isReservedLocked(port int, protocol string) bool {
+	for _, reservation := range l.reservations {
+		for _, r := range reservation.Ranges {
+			if r.Protocol == protocol && port >= r.StartPort && port <= r.EndPort {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *HostPortReservation) copy() *HostPortReservation {
+	return &HostPortReservation{
+		TaskARN: r.TaskARN,
+		Ranges:  append([]HostPortRange(nil), r.Ranges...),
+	}
+}
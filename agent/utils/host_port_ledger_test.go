@@ -0,0 +1,212 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testLedgerTaskARN1   = "arn:aws:ecs:us-west-2:1234567890:task/test-cluster/task1"
+	testLedgerTaskARN2   = "arn:aws:ecs:us-west-2:1234567890:task/test-cluster/task2"
+	testLedgerContainer1 = "container1"
+	testLedgerContainer2 = "container2"
+)
+
+// fakeHostPortReservationStore records the reservations saved to it
+type fakeHostPortReservationStore struct {
+	saved map[string]*HostPortReservation
+	err   error
+}
+
+func (s *fakeHostPortReservationStore) SaveHostPortReservation(r *HostPortReservation) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.saved[r.TaskARN] = r
+	return nil
+}
+
+func (s *fakeHostPortReservationStore) DeleteHostPortReservation(taskARN string) error {
+	if s.err != nil {
+		return s.err
+	}
+	delete(s.saved, taskARN)
+	return nil
+}
+
+// setupLedger resets the ledger and the port tracker, and makes every port available
+func setupLedger(t *testing.T) *fakeHostPortReservationStore {
+	store := &fakeHostPortReservationStore{saved: make(map[string]*HostPortReservation)}
+	isPortAvailableFuncTmp := isPortAvailableFunc
+	isPortAvailableFunc = func(port int, protocol string) (bool, error) { return true, nil }
+	RestoreHostPortReservations(nil)
+	SetHostPortReservationStore(store)
+	ResetTracker()
+	t.Cleanup(func() {
+		isPortAvailableFunc = isPortAvailableFuncTmp
+		RestoreHostPortReservations(nil)
+		SetHostPortReservationStore(nil)
+		ResetTracker()
+	})
+	return store
+}
+
+func TestReserveHostPorts(t *testing.T) {
+	store := setupLedger(t)
+
+	port, err := ReserveHostPort(testLedgerTaskARN1, testLedgerContainer1, testTCPProtocol, "40000-40010")
+	require.NoError(t, err)
+	assert.Equal(t, "40000", port)
+	portRange, err := ReserveHostPortRange(testLedgerTaskARN1, testLedgerContainer2, 3, testUDPProtocol, "40000-40010")
+	require.NoError(t, err)
+	assert.Equal(t, "40001-40003", portRange)
+
+	expected := &HostPortReservation{
+		TaskARN: testLedgerTaskARN1,
+		Ranges: []HostPortRange{
+			{ContainerName: testLedgerContainer1, Protocol: testTCPProtocol, StartPort: 40000, EndPort: 40000},
+			{ContainerName: testLedgerContainer2, Protocol: testUDPProtocol, StartPort: 40001, EndPort: 40003},
+		},
+	}
+	assert.Equal(t, []*HostPortReservation{expected}, GetHostPortReservations())
+	assert.Equal(t, expected, store.saved[testLedgerTaskARN1])
+
+	ReleaseHostPorts(testLedgerTaskARN1)
+	assert.Empty(t, GetHostPortReservations())
+	assert.Empty(t, store.saved)
+}
+
+func TestReleaseContainerHostPorts(t *testing.T) {
+	store := setupLedger(t)
+
+	_, err := ReserveHostPort(testLedgerTaskARN1, testLedgerContainer1, testTCPProtocol, "40000-40010")
+	require.NoError(t, err)
+	_, err = ReserveHostPort(testLedgerTaskARN1, testLedgerContainer2, testTCPProtocol, "40000-40010")
+	require.NoError(t, err)
+
+	// Assigning the ports of a container again replaces its reservation instead of adding to it
+	ReleaseContainerHostPorts(testLedgerTaskARN1, testLedgerContainer1)
+	ResetTracker()
+	port, err := ReserveHostPort(testLedgerTaskARN1, testLedgerContainer1, testTCPProtocol, "40000-40010")
+	require.NoError(t, err)
+	assert.Equal(t, "40000", port)
+
+	expected := &HostPortReservation{
+		TaskARN: testLedgerTaskARN1,
+		Ranges: []HostPortRange{
+			{ContainerName: testLedgerContainer2, Protocol: testTCPProtocol, StartPort: 40001, EndPort: 40001},
+			{ContainerName: testLedgerContainer1, Protocol: testTCPProtocol, StartPort: 40000, EndPort: 40000},
+		},
+	}
+	assert.Equal(t, []*HostPortReservation{expected}, GetHostPortReservations())
+	assert.Equal(t, expected, store.saved[testLedgerTaskARN1])
+
+	// The reservation of the task is deleted with the ports of its last container
+	ReleaseContainerHostPorts(testLedgerTaskARN1, testLedgerContainer1)
+	ReleaseContainerHostPorts(testLedgerTaskARN1, testLedgerContainer2)
+	assert.Empty(t, GetHostPortReservations())
+	assert.Empty(t, store.saved)
+}
+
+func TestReservedHostPortsAreNotAssigned(t *testing.T) {
+	setupLedger(t)
+	RestoreHostPortReservations([]*HostPortReservation{
+		{
+			TaskARN: testLedgerTaskARN1,
+			Ranges:  []HostPortRange{{Protocol: testTCPProtocol, StartPort: 40000, EndPort: 40001}},
+		},
+	})
+
+	// Reserved ports are skipped even though nothing is listening on them
+	port, err := ReserveHostPort(testLedgerTaskARN2, testLedgerContainer1, testTCPProtocol, "40000-40003")
+	require.NoError(t, err)
+	assert.Equal(t, "40002", port)
+	port, err = GetHostPort(testTCPProtocol, "40000-40003")
+	require.NoError(t, err)
+	assert.Equal(t, "40003", port)
+	_, err = ReserveHostPortRange(testLedgerTaskARN2, testLedgerContainer1, 2, testTCPProtocol, "40000-40003")
+	assert.Error(t, err)
+
+	// Reservations are per protocol
+	port, err = ReserveHostPort(testLedgerTaskARN2, testLedgerContainer1, testUDPProtocol, "40000-40000")
+	require.NoError(t, err)
+	assert.Equal(t, "40000", port)
+
+	// Released ports can be assigned again
+	ReleaseHostPorts(testLedgerTaskARN1)
+	ResetTracker()
+	portRange, err := ReserveHostPortRange(testLedgerTaskARN2, testLedgerContainer1, 2, testTCPProtocol, "40000-40003")
+	require.NoError(t, err)
+	assert.Equal(t, "40000-40001", portRange)
+}
+
+func TestReserveHostPortStoreError(t *testing.T) {
+	store := setupLedger(t)
+	store.err = errors.New("store error")
+
+	// The reservation is kept in memory when it can't be persisted
+	port, err := ReserveHostPort(testLedgerTaskARN1, testLedgerContainer1, testTCPProtocol, "40000-40010")
+	require.NoError(t, err)
+	assert.Equal(t, "40000", port)
+	assert.Len(t, GetHostPortReservations(), 1)
+	assert.Empty(t, store.saved)
+}
+
+func TestReserveHostPortNotFound(t *testing.T) {
+	setupLedger(t)
+	isPortAvailableFunc = func(port int, protocol string) (bool, error) { return false, nil }
+
+	_, err := ReserveHostPort(testLedgerTaskARN1, testLedgerContainer1, testTCPProtocol, "40000-40010")
+	assert.Error(t, err)
+	assert.Empty(t, GetHostPortReservations())
+}
+
+func TestGetHostPortUsage(t *testing.T) {
+	setupLedger(t)
+	RestoreHostPortReservations([]*HostPortReservation{
+		{
+			TaskARN: testLedgerTaskARN1,
+			Ranges: []HostPortRange{
+				{Protocol: testTCPProtocol, StartPort: 40000, EndPort: 40004},
+				// Ports outside of the dynamic host port range are not counted
+				{Protocol: testTCPProtocol, StartPort: 50000, EndPort: 50000},
+			},
+		},
+		{
+			TaskARN: testLedgerTaskARN2,
+			Ranges:  []HostPortRange{{Protocol: testUDPProtocol, StartPort: 40009, EndPort: 40009}},
+		},
+	})
+
+	usage, err := GetHostPortUsage("40000-40009")
+	require.NoError(t, err)
+	assert.Equal(t, []HostPortUsage{
+		{Protocol: testTCPProtocol, Total: 10, Reserved: 5, Available: 5},
+		{Protocol: testUDPProtocol, Total: 10, Reserved: 1, Available: 9},
+	}, usage)
+}
+
+func TestGetHostPortUsageInvalidRange(t *testing.T) {
+	setupLedger(t)
+	_, err := GetHostPortUsage("invalid")
+	assert.Error(t, err)
+}
@@ -20,8 +20,10 @@ import "github.com/vishvananda/netlink"
 
 // NetLink Wrapper methods used from the vishvananda/netlink package
 type NetLink interface {
+	AddrList(link netlink.Link, family int) ([]netlink.Addr, error)
 	LinkByName(name string) (netlink.Link, error)
 	LinkList() ([]netlink.Link, error)
+	NeighList(linkIndex, family int) ([]netlink.Neigh, error)
 	RouteList(link netlink.Link, family int) ([]netlink.Route, error)
 }
 
@@ -33,6 +35,11 @@ func New() NetLink {
 	return NetLinkClient{}
 }
 
+// AddrList gets a list of IP addresses of the link. Equivalent to: `ip addr show`
+func (NetLinkClient) AddrList(link netlink.Link, family int) ([]netlink.Addr, error) {
+	return netlink.AddrList(link, family)
+}
+
 // LinkByName finds a link by name and returns a pointer to the object
 func (NetLinkClient) LinkByName(name string) (netlink.Link, error) {
 	return netlink.LinkByName(name)
@@ -43,6 +50,11 @@ func (NetLinkClient) LinkList() ([]netlink.Link, error) {
 	return netlink.LinkList()
 }
 
+// NeighList gets a list of neighbor entries of the link. Equivalent to: `ip neigh show`
+func (NetLinkClient) NeighList(linkIndex, family int) ([]netlink.Neigh, error) {
+	return netlink.NeighList(linkIndex, family)
+}
+
 func (NetLinkClient) RouteList(link netlink.Link, family int) ([]netlink.Route, error) {
 	return netlink.RouteList(link, family)
 }
@@ -21,6 +21,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
@@ -100,6 +101,7 @@ type session struct {
 	disconnectJitter               time.Duration
 	inactiveInstanceReconnectDelay time.Duration
 	lastConnectedTime              time.Time
+	lastConnectedTimeLock          sync.RWMutex
 	firstACSConnectionTime         time.Time
 }
 
@@ -277,14 +279,14 @@ func (s *session) startSessionOnce(ctx context.Context) error {
 	}
 
 	// Record the timestamp of the last connection to ACS.
-	s.lastConnectedTime = time.Now()
+	s.setLastConnectedTime(time.Now())
 
 	// Connection to ACS was successful. Moving forward, rely on ACS to send credentials to Agent at its own cadence
 	// and make sure Agent does not force ACS to send credentials for any subsequent reconnects to ACS.
 	logger.Info("Connected to ACS endpoint",
 		logger.Fields{
 			"containerInstanceARN": s.containerInstanceARN,
-			"lastConnectedTime":    s.lastConnectedTime,
+			"lastConnectedTime":    s.GetLastConnectedTime(),
 		})
 	s.sendCredentials = false
 
@@ -485,9 +487,17 @@ func formatDockerVersion(dockerVersionValue string) string {
 
 // GetLastConnectedTime returns the timestamp that the last connection was established to ACS.
 func (s *session) GetLastConnectedTime() time.Time {
+	s.lastConnectedTimeLock.RLock()
+	defer s.lastConnectedTimeLock.RUnlock()
 	return s.lastConnectedTime
 }
 
+func (s *session) setLastConnectedTime(lastConnectedTime time.Time) {
+	s.lastConnectedTimeLock.Lock()
+	defer s.lastConnectedTimeLock.Unlock()
+	s.lastConnectedTime = lastConnectedTime
+}
+
 func (s *session) GetFirstACSConnectionTime() time.Time {
 	return s.firstACSConnectionTime
 }
@@ -26,8 +26,18 @@ var (
 	EmptyHealthcheckError = errors.New("No instance healthcheck status metrics to report")
 )
 
+// HealthcheckResult is the status a healthcheck returned when the doctor ran it
+type HealthcheckResult struct {
+	Type   string
+	Status HealthcheckStatus
+}
+
+// HealthcheckResultsHandler is called with the results of the healthchecks every time the doctor runs them
+type HealthcheckResultsHandler func(results []HealthcheckResult)
+
 type Doctor struct {
 	healthchecks         []Healthcheck
+	resultsHandlers      []HealthcheckResultsHandler
 	lock                 sync.RWMutex
 	cluster              string
 	containerInstanceArn string
@@ -91,12 +101,35 @@ func (doc *Doctor) AddHealthcheck(healthcheck Healthcheck) {
 	doc.healthchecks = append(doc.healthchecks, healthcheck)
 }
 
+// AddResultsHandler adds a handler that the doctor calls with the results of the
+// healthchecks every time doctor.RunHealthchecks() is called, e.g. to act on the
+// healthchecks which failed
+func (doc *Doctor) AddResultsHandler(handler HealthcheckResultsHandler) {
+	doc.lock.Lock()
+	defer doc.lock.Unlock()
+	doc.resultsHandlers = append(doc.resultsHandlers, handler)
+}
+
 // RunHealthchecks runs every healthcheck that the doctor knows about and
 // returns a cumulative result; true if they all pass, false otherwise
 func (doc *Doctor) RunHealthchecks() bool {
+	results, handlers := doc.runHealthchecks()
+	// the handlers are called without holding the lock, so that they can use the doctor
+	for _, handler := range handlers {
+		handler(results)
+	}
+
+	allChecksResult := []HealthcheckStatus{}
+	for _, result := range results {
+		allChecksResult = append(allChecksResult, result.Status)
+	}
+	return doc.allRight(allChecksResult)
+}
+
+func (doc *Doctor) runHealthchecks() ([]HealthcheckResult, []HealthcheckResultsHandler) {
 	doc.lock.Lock()
 	defer doc.lock.Unlock()
-	allChecksResult := []HealthcheckStatus{}
+	results := []HealthcheckResult{}
 
 	for _, healthcheck := range doc.healthchecks {
 		res := healthcheck.RunCheck()
@@ -104,11 +137,16 @@ func (doc *Doctor) RunHealthchecks() bool {
 			"instanceHealthcheckType":   healthcheck.GetHealthcheckType(),
 			"instanceHealthCheckResult": res,
 		})
-		allChecksResult = append(allChecksResult, res)
+		results = append(results, HealthcheckResult{
+			Type:   healthcheck.GetHealthcheckType(),
+			Status: res,
+		})
 	}
 
 	doc.statusReported = false
-	return doc.allRight(allChecksResult)
+	handlers := make([]HealthcheckResultsHandler, len(doc.resultsHandlers))
+	copy(handlers, doc.resultsHandlers)
+	return results, handlers
 }
 
 // GetHealthchecks returns a copy of list of healthchecks that the
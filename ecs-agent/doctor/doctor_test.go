@@ -145,6 +145,22 @@ func TestRunHealthchecks(t *testing.T) {
 	}
 }
 
+func TestRunHealthchecksResultsHandler(t *testing.T) {
+	newDoctor, _ := NewDoctor([]Healthcheck{&trueHealthcheck{}, &falseHealthcheck{}}, TEST_CLUSTER, TEST_INSTANCE_ARN)
+	var handledResults []HealthcheckResult
+	newDoctor.AddResultsHandler(func(results []HealthcheckResult) {
+		// the handler can use the doctor
+		assert.Len(t, *newDoctor.GetHealthchecks(), 2)
+		handledResults = results
+	})
+
+	assert.False(t, newDoctor.RunHealthchecks())
+	assert.Equal(t, []HealthcheckResult{
+		{Type: HealthcheckTypeAgent, Status: HealthcheckStatusOk},
+		{Type: HealthcheckTypeAgent, Status: HealthcheckStatusImpaired},
+	}, handledResults)
+}
+
 func TestGetHealthchecks(t *testing.T) {
 	trueCheck := &trueHealthcheck{}
 	falseCheck := &falseHealthcheck{}
@@ -21,6 +21,12 @@ const (
 	HealthcheckTypeContainerRuntime = "ContainerRuntime"
 	HealthcheckTypeAgent            = "Agent"
 	HealthcheckTypeEBSDaemon        = "EBSDaemon"
+	HealthcheckTypeDiskSpace        = "DiskSpace"
+	HealthcheckTypeDataStore        = "DataStore"
+	HealthcheckTypeACSConnection    = "ACSConnection"
+	HealthcheckTypeTCSConnection    = "TCSConnection"
+	HealthcheckTypeCredentials      = "CredentialsEndpoint"
+	HealthcheckTypeClockSkew        = "ClockSkew"
 )
 
 type Healthcheck interface {
@@ -43,11 +43,13 @@ const (
 type Client interface {
 	CreateTags(input *ec2sdk.CreateTagsInput) (*ec2sdk.CreateTagsOutput, error)
 	DescribeECSTagsForInstance(instanceID string) ([]ecstypes.Tag, error)
+	DescribeMaximumNetworkInterfaces(instanceType string) (int32, error)
 }
 
 type ClientSDK interface {
 	CreateTags(ctx context.Context, input *ec2sdk.CreateTagsInput, optsFns ...func(*ec2sdk.Options)) (*ec2sdk.CreateTagsOutput, error)
 	DescribeTags(ctx context.Context, input *ec2sdk.DescribeTagsInput, optsFns ...func(*ec2sdk.Options)) (*ec2sdk.DescribeTagsOutput, error)
+	DescribeInstanceTypes(ctx context.Context, input *ec2sdk.DescribeInstanceTypesInput, optsFns ...func(*ec2sdk.Options)) (*ec2sdk.DescribeInstanceTypesOutput, error)
 }
 
 type ClientImpl struct {
@@ -119,6 +121,22 @@ func (c *ClientImpl) DescribeECSTagsForInstance(instanceID string) ([]ecstypes.T
 	return tags, nil
 }
 
+// DescribeMaximumNetworkInterfaces calls DescribeInstanceTypes API to get the maximum number of network
+// interfaces which can be attached to an instance of the instance type
+func (c *ClientImpl) DescribeMaximumNetworkInterfaces(instanceType string) (int32, error) {
+	res, err := c.client.DescribeInstanceTypes(context.TODO(), &ec2sdk.DescribeInstanceTypesInput{
+		InstanceTypes: []types.InstanceType{types.InstanceType(instanceType)},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error calling DescribeInstanceTypes API: %w", err)
+	}
+	if len(res.InstanceTypes) == 0 || res.InstanceTypes[0].NetworkInfo == nil ||
+		res.InstanceTypes[0].NetworkInfo.MaximumNetworkInterfaces == nil {
+		return 0, fmt.Errorf("no network information for instance type %s", instanceType)
+	}
+	return aws.ToInt32(res.InstanceTypes[0].NetworkInfo.MaximumNetworkInterfaces), nil
+}
+
 func (c *ClientImpl) CreateTags(input *ec2sdk.CreateTagsInput) (*ec2sdk.CreateTagsOutput, error) {
 	return c.client.CreateTags(context.TODO(), input)
 }
@@ -92,3 +92,46 @@ func TestDescribeECSTagsForInstance(t *testing.T) {
 	assert.Equal(t, aws.ToString(tags[0].Key), "key")
 	assert.Equal(t, aws.ToString(tags[0].Value), "value")
 }
+
+func TestDescribeMaximumNetworkInterfaces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClientSDK := mock_ec2.NewMockClientSDK(ctrl)
+	testClient, err := ec2.NewClientImpl("us-west-2")
+	assert.NoError(t, err)
+	testClient.(*ec2.ClientImpl).SetClientSDK(mockClientSDK)
+
+	mockClientSDK.EXPECT().DescribeInstanceTypes(
+		gomock.Any(), gomock.Any(),
+	).Do(func(_ context.Context, input *ec2sdk.DescribeInstanceTypesInput, _ ...func(*ec2sdk.Options)) {
+		assert.Equal(t, []types.InstanceType{types.InstanceTypeM5Large}, input.InstanceTypes)
+	}).Return(&ec2sdk.DescribeInstanceTypesOutput{
+		InstanceTypes: []types.InstanceTypeInfo{
+			{
+				InstanceType: types.InstanceTypeM5Large,
+				NetworkInfo:  &types.NetworkInfo{MaximumNetworkInterfaces: aws.Int32(3)},
+			},
+		},
+	}, nil)
+
+	maxENIs, err := testClient.DescribeMaximumNetworkInterfaces("m5.large")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), maxENIs)
+}
+
+func TestDescribeMaximumNetworkInterfacesNoNetworkInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClientSDK := mock_ec2.NewMockClientSDK(ctrl)
+	testClient, err := ec2.NewClientImpl("us-west-2")
+	assert.NoError(t, err)
+	testClient.(*ec2.ClientImpl).SetClientSDK(mockClientSDK)
+
+	mockClientSDK.EXPECT().DescribeInstanceTypes(gomock.Any(), gomock.Any()).
+		Return(&ec2sdk.DescribeInstanceTypesOutput{}, nil)
+
+	_, err = testClient.DescribeMaximumNetworkInterfaces("m5.large")
+	assert.Error(t, err)
+}
@@ -44,6 +44,7 @@ const (
 	OutpostARN                                = "outpost-arn"
 	PrimaryIPV4VPCCIDRResourceFormat          = "network/interfaces/macs/%s/vpc-ipv4-cidr-block"
 	TargetLifecycleState                      = "autoscaling/target-lifecycle-state"
+	ScheduledMaintenanceEventsResource        = "events/maintenance/scheduled"
 )
 
 const (
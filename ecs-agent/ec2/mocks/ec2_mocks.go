@@ -483,6 +483,21 @@ func (mr *MockClientMockRecorder) DescribeECSTagsForInstance(arg0 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeECSTagsForInstance", reflect.TypeOf((*MockClient)(nil).DescribeECSTagsForInstance), arg0)
 }
 
+// DescribeMaximumNetworkInterfaces mocks base method.
+func (m *MockClient) DescribeMaximumNetworkInterfaces(arg0 string) (int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeMaximumNetworkInterfaces", arg0)
+	ret0, _ := ret[0].(int32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeMaximumNetworkInterfaces indicates an expected call of DescribeMaximumNetworkInterfaces.
+func (mr *MockClientMockRecorder) DescribeMaximumNetworkInterfaces(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeMaximumNetworkInterfaces", reflect.TypeOf((*MockClient)(nil).DescribeMaximumNetworkInterfaces), arg0)
+}
+
 // MockClientSDK is a mock of ClientSDK interface.
 type MockClientSDK struct {
 	ctrl     *gomock.Controller
@@ -526,6 +541,26 @@ func (mr *MockClientSDKMockRecorder) CreateTags(arg0, arg1 interface{}, arg2 ...
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTags", reflect.TypeOf((*MockClientSDK)(nil).CreateTags), varargs...)
 }
 
+// DescribeInstanceTypes mocks base method.
+func (m *MockClientSDK) DescribeInstanceTypes(arg0 context.Context, arg1 *ec20.DescribeInstanceTypesInput, arg2 ...func(*ec20.Options)) (*ec20.DescribeInstanceTypesOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DescribeInstanceTypes", varargs...)
+	ret0, _ := ret[0].(*ec20.DescribeInstanceTypesOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeInstanceTypes indicates an expected call of DescribeInstanceTypes.
+func (mr *MockClientSDKMockRecorder) DescribeInstanceTypes(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeInstanceTypes", reflect.TypeOf((*MockClientSDK)(nil).DescribeInstanceTypes), varargs...)
+}
+
 // DescribeTags mocks base method.
 func (m *MockClientSDK) DescribeTags(arg0 context.Context, arg1 *ec20.DescribeTagsInput, arg2 ...func(*ec20.Options)) (*ec20.DescribeTagsOutput, error) {
 	m.ctrl.T.Helper()
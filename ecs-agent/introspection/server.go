@@ -60,6 +60,13 @@ type Config struct {
 	writeTimeout       time.Duration // http server write timeout
 	enableRuntimeStats bool          // enable profiling handlers
 	hideAgentVersion   bool          // if true, do not show Version in metadata
+	handlers           []pathHandler // additional handlers registered by the agent
+}
+
+// pathHandler is an additional handler of the Introspection Server
+type pathHandler struct {
+	path    string
+	handler http.HandlerFunc
 }
 
 // Function type for updating Introspection Server config
@@ -94,6 +101,14 @@ func HideAgentVersion(isHidden bool) ConfigOpt {
 	}
 }
 
+// Register an additional handler for the given path. The path is listed
+// with the available commands of the Introspection Server.
+func WithHandler(path string, handler http.HandlerFunc) ConfigOpt {
+	return func(c *Config) {
+		c.handlers = append(c.handlers, pathHandler{path: path, handler: handler})
+	}
+}
+
 // Create a new HTTP Introspection Server
 func NewServer(agentState v1.AgentState, metricsFactory metrics.EntryFactory, options ...ConfigOpt) (*http.Server, error) {
 	config := new(Config)
@@ -133,6 +148,9 @@ func setup(
 	}
 
 	paths := []string{handlers.V1AgentMetadataPath, handlers.V1TasksMetadataPath, licensePath}
+	for _, h := range config.handlers {
+		paths = append(paths, h.path)
+	}
 
 	if config.enableRuntimeStats {
 		paths = append(paths, pprofBasePath, pprofCMDLinePath, pprofProfilePath, pprofSymbolPath, pprofTracePath)
@@ -153,6 +171,9 @@ func setup(
 	serveMux.HandleFunc("/", defaultHandler)
 
 	v1HandlersSetup(serveMux, agentState, metricsFactory, config.hideAgentVersion)
+	for _, h := range config.handlers {
+		serveMux.HandleFunc(h.path, h.handler)
+	}
 	wTimeout := config.writeTimeout
 	if config.enableRuntimeStats {
 		pprofHandlerSetup(serveMux)
@@ -203,3 +203,32 @@ func TestPProfHandlerSetup(t *testing.T) {
 		})
 	}
 }
+
+func TestNewServerWithHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	agentState := mock_v1.NewMockAgentState(ctrl)
+	metricsFactory := mock_metrics.NewMockEntryFactory(ctrl)
+	server, err := NewServer(agentState, metricsFactory, WithHandler("/v1/custom",
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("custom"))
+		}))
+	require.NoError(t, err)
+
+	t.Run("handler is served", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/v1/custom", nil)
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		server.Handler.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "custom", recorder.Body.String())
+	})
+
+	t.Run("handler is listed", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/", nil)
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		server.Handler.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, `{"AvailableCommands":["/v1/metadata","/v1/tasks","/license","/v1/custom"]}`, recorder.Body.String())
+	})
+}
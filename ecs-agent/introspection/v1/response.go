@@ -63,6 +63,8 @@ type ContainerResponse struct {
 	Networks     []response.Network        `json:"Networks,omitempty"`
 	Volumes      []response.VolumeResponse `json:"Volumes,omitempty"`
 	RestartCount *int                      `json:"RestartCount,omitempty"`
+	// HealthHistory lists the results of the latest health check probes, oldest first
+	HealthHistory []response.HealthCheckResultResponse `json:"HealthHistory,omitempty"`
 }
 
 // ErrorMultipleTasksFound should be returned when a task cannot be uniquely identified for a given request.
@@ -24,6 +24,7 @@ import (
 
 	ecsacs "github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
 	tasknetworkconfig "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	stats "github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 	types "github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -66,6 +67,21 @@ func (mr *MockNetworkBuilderMockRecorder) BuildTaskNetworkConfiguration(arg0, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuildTaskNetworkConfiguration", reflect.TypeOf((*MockNetworkBuilder)(nil).BuildTaskNetworkConfiguration), arg0, arg1)
 }
 
+// GetNetworkPolicyStats mocks base method.
+func (m *MockNetworkBuilder) GetNetworkPolicyStats(arg0 context.Context, arg1 *tasknetworkconfig.NetworkNamespace) (*stats.NetworkPolicyStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetworkPolicyStats", arg0, arg1)
+	ret0, _ := ret[0].(*stats.NetworkPolicyStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNetworkPolicyStats indicates an expected call of GetNetworkPolicyStats.
+func (mr *MockNetworkBuilderMockRecorder) GetNetworkPolicyStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetworkPolicyStats", reflect.TypeOf((*MockNetworkBuilder)(nil).GetNetworkPolicyStats), arg0, arg1)
+}
+
 // Start mocks base method.
 func (m *MockNetworkBuilder) Start(arg0 context.Context, arg1 types.NetworkMode, arg2 string, arg3 *tasknetworkconfig.NetworkNamespace) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,229 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package networkpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/pkg/errors"
+)
+
+const (
+	// NetworkPolicyLabel is the docker label of a task container which holds the network policy of the task.
+	NetworkPolicyLabel = "com.amazonaws.ecs.network-policy"
+
+	// DefaultActionDeny drops the egress traffic which doesn't match any rule of the policy.
+	DefaultActionDeny = "DENY"
+	// DefaultActionAllow allows the egress traffic which doesn't match any rule of the policy. The traffic
+	// is still counted as a violation, which allows auditing a policy before enforcing it.
+	DefaultActionAllow = "ALLOW"
+
+	ProtocolTCP = "tcp"
+	ProtocolUDP = "udp"
+	ProtocolAll = "all"
+
+	// DefaultDNSRefreshInterval is the interval at which the DNS names of a policy are resolved again
+	// if the policy doesn't specify one.
+	DefaultDNSRefreshInterval = time.Minute
+	// minDNSRefreshInterval is the shortest DNS refresh interval accepted in a policy.
+	minDNSRefreshInterval = 10 * time.Second
+)
+
+// NetworkPolicy is the egress policy of a task. The egress rules form an allow-list, the traffic
+// which doesn't match any of them is handled as per the default action.
+type NetworkPolicy struct {
+	// DefaultAction is either DENY or ALLOW.
+	DefaultAction string `json:"defaultAction,omitempty"`
+	// Egress is the list of the allowed egress destinations.
+	Egress []EgressRule `json:"egress,omitempty"`
+	// DNSRefreshIntervalSeconds is the interval at which the DNS names of the egress rules are resolved again.
+	DNSRefreshIntervalSeconds int `json:"dnsRefreshIntervalSeconds,omitempty"`
+}
+
+// EgressRule allows the egress traffic to a set of destinations.
+type EgressRule struct {
+	// CIDRs is the list of the IPv4 and IPv6 destination CIDR blocks.
+	CIDRs []string `json:"cidrs,omitempty"`
+	// DNSNames is the list of the destination DNS names. They are resolved periodically, and the rule
+	// allows the addresses they resolve to.
+	DNSNames []string `json:"dnsNames,omitempty"`
+	// Protocol is one of tcp, udp or all. It defaults to all.
+	Protocol string `json:"protocol,omitempty"`
+	// Ports is the list of the destination ports or port ranges, e.g. "443" or "8000-8080". Every port
+	// is allowed if the list is empty. Ports can only be specified for tcp and udp rules.
+	Ports []string `json:"ports,omitempty"`
+}
+
+// NetworkPolicyFromACS returns the network policy of the task, which is read from the network policy
+// label of the task containers. Nil is returned if no container has the label. Every container which has
+// the label must specify the same policy, as the policy applies to the whole task.
+func NetworkPolicyFromACS(taskPayload *ecsacs.Task) (*NetworkPolicy, error) {
+	var policy *NetworkPolicy
+	for _, container := range taskPayload.Containers {
+		containerPolicy, err := networkPolicyFromContainer(container)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid network policy of container %s",
+				aws.ToString(container.Name))
+		}
+		if containerPolicy == nil {
+			continue
+		}
+		if policy != nil && !reflect.DeepEqual(policy, containerPolicy) {
+			return nil, errors.New("containers of the task specify different network policies")
+		}
+		policy = containerPolicy
+	}
+	return policy, nil
+}
+
+// networkPolicyFromContainer parses the network policy label in the docker config of the container.
+func networkPolicyFromContainer(container *ecsacs.Container) (*NetworkPolicy, error) {
+	if container.DockerConfig == nil || aws.ToString(container.DockerConfig.Config) == "" {
+		return nil, nil
+	}
+	var config struct {
+		Labels map[string]string `json:"Labels"`
+	}
+	if err := json.Unmarshal([]byte(aws.ToString(container.DockerConfig.Config)), &config); err != nil {
+		// The labels can't be read, so whether the task has a network policy is unknown. The task is not
+		// started without its policy.
+		return nil, errors.Wrap(err, "failed to parse docker config")
+	}
+	value, ok := config.Labels[NetworkPolicyLabel]
+	if !ok {
+		return nil, nil
+	}
+
+	policy := &NetworkPolicy{}
+	if err := json.Unmarshal([]byte(value), policy); err != nil {
+		return nil, errors.Wrap(err, "failed to parse network policy")
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Validate validates the policy and fills in the defaults.
+func (p *NetworkPolicy) Validate() error {
+	switch strings.ToUpper(p.DefaultAction) {
+	case "", DefaultActionDeny:
+		p.DefaultAction = DefaultActionDeny
+	case DefaultActionAllow:
+		p.DefaultAction = DefaultActionAllow
+	default:
+		return fmt.Errorf("invalid default action %q", p.DefaultAction)
+	}
+
+	if p.DNSRefreshIntervalSeconds != 0 &&
+		time.Duration(p.DNSRefreshIntervalSeconds)*time.Second < minDNSRefreshInterval {
+		return fmt.Errorf("DNS refresh interval must be at least %s", minDNSRefreshInterval)
+	}
+
+	for i := range p.Egress {
+		if err := p.Egress[i].validate(); err != nil {
+			return errors.Wrapf(err, "invalid egress rule %d", i)
+		}
+	}
+	return nil
+}
+
+// Deny returns whether the egress traffic which doesn't match any rule is dropped.
+func (p *NetworkPolicy) Deny() bool {
+	return p.DefaultAction == DefaultActionDeny
+}
+
+// DNSNames returns the DNS names used in the egress rules of the policy.
+func (p *NetworkPolicy) DNSNames() []string {
+	var names []string
+	seen := make(map[string]struct{})
+	for _, rule := range p.Egress {
+		for _, name := range rule.DNSNames {
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// DNSRefreshInterval returns the interval at which the DNS names of the policy are resolved again.
+func (p *NetworkPolicy) DNSRefreshInterval() time.Duration {
+	if p.DNSRefreshIntervalSeconds == 0 {
+		return DefaultDNSRefreshInterval
+	}
+	return time.Duration(p.DNSRefreshIntervalSeconds) * time.Second
+}
+
+func (r *EgressRule) validate() error {
+	if len(r.CIDRs) == 0 && len(r.DNSNames) == 0 {
+		return errors.New("at least one CIDR or DNS name is required")
+	}
+	for _, cidr := range r.CIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid CIDR %q", cidr)
+		}
+	}
+	for _, name := range r.DNSNames {
+		if name == "" || strings.ContainsAny(name, " /") {
+			return fmt.Errorf("invalid DNS name %q", name)
+		}
+	}
+
+	r.Protocol = strings.ToLower(r.Protocol)
+	switch r.Protocol {
+	case "":
+		r.Protocol = ProtocolAll
+	case ProtocolTCP, ProtocolUDP, ProtocolAll:
+	default:
+		return fmt.Errorf("invalid protocol %q", r.Protocol)
+	}
+	if r.Protocol == ProtocolAll && len(r.Ports) > 0 {
+		return errors.New("ports require the tcp or udp protocol")
+	}
+	for _, port := range r.Ports {
+		if err := validatePortRange(port); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePortRange validates a port, e.g. "443", or a port range, e.g. "8000-8080".
+func validatePortRange(portRange string) error {
+	bounds := strings.SplitN(portRange, "-", 2)
+	var ports []int
+	for _, bound := range bounds {
+		port, err := strconv.Atoi(bound)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("invalid port %q", portRange)
+		}
+		ports = append(ports, port)
+	}
+	if len(ports) == 2 && ports[0] > ports[1] {
+		return fmt.Errorf("invalid port range %q", portRange)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package networkpolicy
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `{"egress":[{"cidrs":["10.0.0.0/8","2001:db8::/32"],"protocol":"TCP","ports":["443","8000-8080"]},` +
+	`{"dnsNames":["example.com"]}]}`
+
+// containerWithLabels returns a task container whose docker config has the given labels.
+func containerWithLabels(t *testing.T, name string, labels map[string]string) *ecsacs.Container {
+	config, err := json.Marshal(map[string]interface{}{"Labels": labels})
+	require.NoError(t, err)
+	return &ecsacs.Container{
+		Name:         aws.String(name),
+		DockerConfig: &ecsacs.DockerConfig{Config: aws.String(string(config))},
+	}
+}
+
+func TestNetworkPolicyFromACS(t *testing.T) {
+	task := &ecsacs.Task{
+		Containers: []*ecsacs.Container{
+			{Name: aws.String("no-config")},
+			containerWithLabels(t, "app", map[string]string{NetworkPolicyLabel: testPolicy}),
+			containerWithLabels(t, "sidecar", map[string]string{NetworkPolicyLabel: testPolicy}),
+			containerWithLabels(t, "other", map[string]string{"foo": "bar"}),
+		},
+	}
+
+	policy, err := NetworkPolicyFromACS(task)
+	require.NoError(t, err)
+	require.NotNil(t, policy)
+	assert.Equal(t, DefaultActionDeny, policy.DefaultAction)
+	assert.True(t, policy.Deny())
+	require.Len(t, policy.Egress, 2)
+	assert.Equal(t, ProtocolTCP, policy.Egress[0].Protocol)
+	assert.Equal(t, []string{"443", "8000-8080"}, policy.Egress[0].Ports)
+	assert.Equal(t, ProtocolAll, policy.Egress[1].Protocol)
+	assert.Equal(t, []string{"example.com"}, policy.DNSNames())
+	assert.Equal(t, DefaultDNSRefreshInterval, policy.DNSRefreshInterval())
+}
+
+func TestNetworkPolicyFromACSNoPolicy(t *testing.T) {
+	task := &ecsacs.Task{
+		Containers: []*ecsacs.Container{
+			{Name: aws.String("no-config")},
+			containerWithLabels(t, "app", map[string]string{"foo": "bar"}),
+		},
+	}
+
+	policy, err := NetworkPolicyFromACS(task)
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+}
+
+func TestNetworkPolicyFromACSDifferentPolicies(t *testing.T) {
+	task := &ecsacs.Task{
+		Containers: []*ecsacs.Container{
+			containerWithLabels(t, "app", map[string]string{NetworkPolicyLabel: testPolicy}),
+			containerWithLabels(t, "sidecar", map[string]string{NetworkPolicyLabel: `{"defaultAction":"ALLOW"}`}),
+		},
+	}
+
+	_, err := NetworkPolicyFromACS(task)
+	assert.Error(t, err)
+}
+
+func TestNetworkPolicyFromACSInvalidLabels(t *testing.T) {
+	for name, task := range map[string]*ecsacs.Task{
+		"invalid docker config": {
+			Containers: []*ecsacs.Container{
+				{
+					Name:         aws.String("app"),
+					DockerConfig: &ecsacs.DockerConfig{Config: aws.String(`{"Labels":`)},
+				},
+			},
+		},
+		"invalid policy": {
+			Containers: []*ecsacs.Container{
+				containerWithLabels(t, "app", map[string]string{NetworkPolicyLabel: `{"egress":`}),
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// The task must not start without its policy
+			_, err := NetworkPolicyFromACS(task)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNetworkPolicyValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		policy      string
+		expectError bool
+	}{
+		{name: "empty policy", policy: `{}`},
+		{name: "allow", policy: `{"defaultAction":"allow","egress":[{"cidrs":["0.0.0.0/0"]}]}`},
+		{name: "udp port", policy: `{"egress":[{"cidrs":["10.0.0.1/32"],"protocol":"udp","ports":["53"]}]}`},
+		{name: "refresh interval", policy: `{"egress":[{"dnsNames":["a.b"]}],"dnsRefreshIntervalSeconds":30}`},
+		{name: "invalid json", policy: `{`, expectError: true},
+		{name: "invalid default action", policy: `{"defaultAction":"REJECT"}`, expectError: true},
+		{name: "no destination", policy: `{"egress":[{"protocol":"tcp"}]}`, expectError: true},
+		{name: "invalid cidr", policy: `{"egress":[{"cidrs":["10.0.0.1"]}]}`, expectError: true},
+		{name: "invalid dns name", policy: `{"egress":[{"dnsNames":["a b"]}]}`, expectError: true},
+		{name: "invalid protocol", policy: `{"egress":[{"cidrs":["10.0.0.0/8"],"protocol":"icmp"}]}`,
+			expectError: true},
+		{name: "ports without protocol", policy: `{"egress":[{"cidrs":["10.0.0.0/8"],"ports":["443"]}]}`,
+			expectError: true},
+		{name: "invalid port", policy: `{"egress":[{"cidrs":["10.0.0.0/8"],"protocol":"tcp","ports":["0"]}]}`,
+			expectError: true},
+		{name: "invalid port range",
+			policy:      `{"egress":[{"cidrs":["10.0.0.0/8"],"protocol":"tcp","ports":["90-80"]}]}`,
+			expectError: true},
+		{name: "short refresh interval", policy: `{"dnsRefreshIntervalSeconds":1}`, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := &ecsacs.Task{
+				Containers: []*ecsacs.Container{
+					containerWithLabels(t, "app", map[string]string{NetworkPolicyLabel: tc.policy}),
+				},
+			}
+			policy, err := NetworkPolicyFromACS(task)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, policy)
+		})
+	}
+}
+
+func TestNetworkPolicyDNSRefreshInterval(t *testing.T) {
+	policy := &NetworkPolicy{DNSRefreshIntervalSeconds: 30}
+	assert.Equal(t, 30*time.Second, policy.DNSRefreshInterval())
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkpolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
 )
@@ -39,6 +40,9 @@ type NetworkNamespace struct {
 	// ServiceConnectConfig holds ServiceConnect related parameters for the particular netns.
 	ServiceConnectConfig *serviceconnect.ServiceConnectConfig
 
+	// NetworkPolicy holds the egress policy enforced in the particular netns.
+	NetworkPolicy *networkpolicy.NetworkPolicy
+
 	KnownState   status.NetworkStatus
 	DesiredState status.NetworkStatus
 
@@ -16,15 +16,19 @@ package netlib
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data"
 	netlibdata "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/platform"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/netwrapper"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/volume"
 
@@ -39,12 +43,19 @@ type NetworkBuilder interface {
 	Start(ctx context.Context, mode types.NetworkMode, taskID string, netNS *tasknetworkconfig.NetworkNamespace) error
 
 	Stop(ctx context.Context, mode types.NetworkMode, taskID string, netNS *tasknetworkconfig.NetworkNamespace) error
+
+	// GetNetworkPolicyStats returns the counters of the egress traffic which violates the network policy
+	// of the network namespace. Nil is returned if the network namespace has no network policy.
+	GetNetworkPolicyStats(ctx context.Context, netNS *tasknetworkconfig.NetworkNamespace) (*stats.NetworkPolicyStats, error)
 }
 
 type networkBuilder struct {
 	platformAPI    platform.API
 	metricsFactory metrics.EntryFactory
 	networkDAO     netlibdata.NetworkDataClient
+	// networkPolicyRefreshers holds the function stopping the DNS refresh of the network policy
+	// of each network namespace, keyed by the netns name.
+	networkPolicyRefreshers sync.Map
 }
 
 func NewNetworkBuilder(
@@ -88,6 +99,7 @@ func (nb *networkBuilder) Start(
 		"NetNSPath":             netNS.Path,
 		"AppMeshEnabled":        netNS.AppMeshConfig != nil,
 		"ServiceConnectEnabled": netNS.ServiceConnectConfig != nil,
+		"NetworkPolicyEnabled":  netNS.NetworkPolicy != nil,
 	}
 	metricEntry := nb.metricsFactory.New(metrics.BuildNetworkNamespaceMetricName).WithFields(logFields)
 
@@ -118,6 +130,7 @@ func (nb *networkBuilder) Stop(ctx context.Context, mode types.NetworkMode, task
 		"NetNSPath":             netNS.Path,
 		"AppMeshEnabled":        netNS.AppMeshConfig != nil,
 		"ServiceConnectEnabled": netNS.ServiceConnectConfig != nil,
+		"NetworkPolicyEnabled":  netNS.NetworkPolicy != nil,
 	}
 	metricEntry := nb.metricsFactory.New(metrics.DeleteNetworkNamespaceMetricName).WithFields(logFields)
 
@@ -200,11 +213,82 @@ func (nb *networkBuilder) startAWSVPC(ctx context.Context, taskID string, netNS
 				return errors.Wrapf(err, "failed to configure ServiceConnect in netns %s", netNS.Name)
 			}
 		}
+
+		// The network policy is enforced before the task containers start, i.e. before the netns is ready.
+		if netNS.NetworkPolicy != nil {
+			logger.Debug("Configuring network policy", logger.Fields{
+				"NetworkPolicy": netNS.NetworkPolicy,
+			})
+
+			err = nb.platformAPI.ConfigureNetworkPolicy(
+				ctx, netNS.Path, netNS.GetPrimaryInterface(), netNS.NetworkPolicy)
+			if err != nil {
+				return errors.Wrapf(err, "failed to configure network policy in netns %s", netNS.Name)
+			}
+			nb.startNetworkPolicyRefresh(netNS)
+		}
 	}
 
 	return err
 }
 
+// startNetworkPolicyRefresh periodically resolves the DNS names of the network policy again, and updates
+// the rules of the policy with the addresses they resolve to.
+func (nb *networkBuilder) startNetworkPolicyRefresh(netNS *tasknetworkconfig.NetworkNamespace) {
+	policy := netNS.NetworkPolicy
+	if len(policy.DNSNames()) == 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if previous, loaded := nb.networkPolicyRefreshers.Swap(netNS.Name, cancel); loaded {
+		previous.(context.CancelFunc)()
+	}
+
+	netNSPath := netNS.Path
+	primaryIf := netNS.GetPrimaryInterface()
+	go func() {
+		ticker := time.NewTicker(policy.DNSRefreshInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := nb.platformAPI.ConfigureNetworkPolicy(ctx, netNSPath, primaryIf, policy); err != nil {
+					logger.Warn("Failed to refresh network policy", logger.Fields{
+						"NetNSPath": netNSPath,
+						field.Error: err,
+					})
+				}
+			}
+		}
+	}()
+}
+
+// stopNetworkPolicyRefresh stops the DNS refresh of the network policy of the network namespace.
+func (nb *networkBuilder) stopNetworkPolicyRefresh(netNS *tasknetworkconfig.NetworkNamespace) {
+	if cancel, loaded := nb.networkPolicyRefreshers.LoadAndDelete(netNS.Name); loaded {
+		cancel.(context.CancelFunc)()
+	}
+}
+
+// GetNetworkPolicyStats returns the counters of the egress traffic which violates the network policy
+// of the network namespace.
+func (nb *networkBuilder) GetNetworkPolicyStats(
+	ctx context.Context,
+	netNS *tasknetworkconfig.NetworkNamespace,
+) (*stats.NetworkPolicyStats, error) {
+	if netNS.NetworkPolicy == nil {
+		return nil, nil
+	}
+	policyStats, err := nb.platformAPI.GetNetworkPolicyStats(ctx, netNS.Path, netNS.GetPrimaryInterface())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get network policy stats of netns %s", netNS.Name)
+	}
+	policyStats.DefaultAction = netNS.NetworkPolicy.DefaultAction
+	return policyStats, nil
+}
+
 // configureNetNSInterfaces executes the platform API to configure every interface inside a network namespace.
 func (nb *networkBuilder) configureNetNSInterfaces(ctx context.Context, netNS *tasknetworkconfig.NetworkNamespace) error {
 	var errs error
@@ -258,6 +342,9 @@ func (nb *networkBuilder) stopAWSVPC(ctx context.Context, netNS *tasknetworkconf
 	logFields := logger.Fields{
 		"NetNSName": netNS.Name,
 	}
+	// The network policy rules live in the netns, they are removed along with it.
+	nb.stopNetworkPolicyRefresh(netNS)
+
 	err := nb.configureNetNSInterfaces(ctx, netNS)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to cleanup interfaces in netns: %v", err), logFields)
@@ -28,11 +28,13 @@ import (
 	mock_metrics "github.com/aws/amazon-ecs-agent/ecs-agent/metrics/mocks"
 	mock_data "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data/mocks"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkpolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
 	platform "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/platform"
 	mock_platform "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/platform/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 	mock_netwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/netwrapper/mocks"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -72,6 +74,37 @@ func TestNetworkBuilder_Start(t *testing.T) {
 	t.Run("awsvpc", testNetworkBuilder_StartAWSVPC)
 }
 
+// TestNetworkBuilder_GetNetworkPolicyStats verifies that the network policy stats of a netns
+// are read from the platform API.
+func TestNetworkBuilder_GetNetworkPolicyStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.TODO()
+	platformAPI := mock_platform.NewMockAPI(ctrl)
+	netBuilder := &networkBuilder{
+		platformAPI: platformAPI,
+	}
+	_, taskNetConfig := getSingleNetNSAWSVPCTestData(taskID)
+	netNS := taskNetConfig.GetPrimaryNetNS()
+
+	// No stats are returned for a netns without network policy.
+	policyStats, err := netBuilder.GetNetworkPolicyStats(ctx, netNS)
+	require.NoError(t, err)
+	require.Nil(t, policyStats)
+
+	netNS.NetworkPolicy = &networkpolicy.NetworkPolicy{DefaultAction: networkpolicy.DefaultActionAllow}
+	platformAPI.EXPECT().GetNetworkPolicyStats(ctx, netNS.Path, netNS.GetPrimaryInterface()).
+		Return(&stats.NetworkPolicyStats{ViolationPackets: 2, ViolationBytes: 120}, nil)
+	policyStats, err = netBuilder.GetNetworkPolicyStats(ctx, netNS)
+	require.NoError(t, err)
+	require.Equal(t, &stats.NetworkPolicyStats{
+		DefaultAction:    networkpolicy.DefaultActionAllow,
+		ViolationPackets: 2,
+		ViolationBytes:   120,
+	}, policyStats)
+}
+
 // TestNetworkBuilder_Stop verifies stop workflow for AWSVPC mode.
 func TestNetworkBuilder_Stop(t *testing.T) {
 	t.Run("awsvpc", testNetworkBuilder_StopAWSVPC)
@@ -229,6 +262,28 @@ func testNetworkBuilder_StartAWSVPC(t *testing.T) {
 		netBuilder.Start(ctx, types.NetworkModeAwsvpc, taskID, netNS)
 	})
 
+	// Single ENI with a network policy and desired state = READY.
+	// The network policy should get enforced, and its DNS names refreshed until the netns is deleted.
+	netNS.ServiceConnectConfig = nil
+	netNS.NetworkPolicy = &networkpolicy.NetworkPolicy{
+		DefaultAction: networkpolicy.DefaultActionDeny,
+		Egress: []networkpolicy.EgressRule{
+			{DNSNames: []string{"example.com"}, Protocol: networkpolicy.ProtocolAll},
+		},
+	}
+	mockEntry = mock_metrics.NewMockEntry(ctrl)
+	t.Run("single-eni-networkpolicy-ready", func(t *testing.T) {
+		gomock.InOrder(
+			getExpectedCalls_StartAWSVPC(ctx, platformAPI, metricsFactory, mockEntry, netDao, netNS)...,
+		)
+		netBuilder.Start(ctx, types.NetworkModeAwsvpc, taskID, netNS)
+		_, ok := netBuilder.networkPolicyRefreshers.Load(netNS.Name)
+		require.True(t, ok)
+		netBuilder.stopNetworkPolicyRefresh(netNS)
+		_, ok = netBuilder.networkPolicyRefreshers.Load(netNS.Name)
+		require.False(t, ok)
+	})
+
 	// Single netns with multi interface case.
 	_, taskNetConfig = getSingleNetNSMultiIfaceAWSVPCTestData(taskID)
 	netNS = taskNetConfig.GetPrimaryNetNS()
@@ -310,6 +365,10 @@ func getExpectedCalls_StartAWSVPC(
 			calls = append(calls, platformAPI.EXPECT().ConfigureServiceConnect(ctx, netNS.Path,
 				netNS.GetPrimaryInterface(), netNS.ServiceConnectConfig).Return(nil).Times(1))
 		}
+		if netNS.NetworkPolicy != nil {
+			calls = append(calls, platformAPI.EXPECT().ConfigureNetworkPolicy(ctx, netNS.Path,
+				netNS.GetPrimaryInterface(), netNS.NetworkPolicy).Return(nil).Times(1))
+		}
 	}
 
 	calls = append(calls, mockEntry.EXPECT().Done(nil).Times(1))
@@ -21,8 +21,10 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkpolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 )
 
 // API declares a set of methods that requires platform specific implementations.
@@ -78,6 +80,23 @@ type API interface {
 		primaryIf *networkinterface.NetworkInterface,
 		scConfig *serviceconnect.ServiceConnectConfig,
 	) error
+
+	// ConfigureNetworkPolicy enforces the egress network policy of the task inside the task network
+	// namespace. It is called again to refresh the addresses the DNS names of the policy resolve to.
+	ConfigureNetworkPolicy(
+		ctx context.Context,
+		netNSPath string,
+		primaryIf *networkinterface.NetworkInterface,
+		policy *networkpolicy.NetworkPolicy,
+	) error
+
+	// GetNetworkPolicyStats returns the counters of the egress traffic which violates the network
+	// policy of the task network namespace.
+	GetNetworkPolicyStats(
+		ctx context.Context,
+		netNSPath string,
+		primaryIf *networkinterface.NetworkInterface,
+	) (*stats.NetworkPolicyStats, error)
 }
 
 // Config contains platform-specific data.
@@ -31,9 +31,11 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkpolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/ioutilwrapper"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/netlinkwrapper"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/netwrapper"
@@ -80,6 +82,8 @@ type common struct {
 	cniClient         ecscni.CNI
 	net               netwrapper.Net
 	resolvConfPath    string
+	exec              execwrapper.Exec
+	resolver          netNSResolver
 }
 
 // NewPlatform creates an implementation of the platform API depending on the
@@ -90,8 +94,9 @@ func NewPlatform(
 	stateDBDirectory string,
 	netWrapper netwrapper.Net,
 ) (API, error) {
+	nsUtil := ecscni.NewNetNSUtil()
 	commonPlatform := common{
-		nsUtil:            ecscni.NewNetNSUtil(),
+		nsUtil:            nsUtil,
 		dnsVolumeAccessor: volumeAccessor,
 		os:                oswrapper.NewOS(),
 		ioutil:            ioutilwrapper.NewIOUtil(),
@@ -100,6 +105,8 @@ func NewPlatform(
 		cniClient:         ecscni.NewCNIClient([]string{CNIPluginPathDefault}),
 		net:               netWrapper,
 		resolvConfPath:    platformConfig.ResolvConfPath,
+		exec:              execwrapper.NewExec(),
+		resolver:          &nsResolver{nsUtil: nsUtil},
 	}
 
 	switch platformConfig.Name {
@@ -196,6 +203,12 @@ func (c *common) buildAWSVPCNetworkNamespaces(
 		return nil, err
 	}
 
+	// The network policy applies to the whole task, i.e. to every network namespace of the task.
+	networkPolicy, err := networkpolicy.NetworkPolicyFromACS(taskPayload)
+	if err != nil {
+		return nil, err
+	}
+
 	logger.Info("Building network configuration for awsvpc task", map[string]interface{}{
 		"SingleNetNS":            singleNetNS,
 		"ENICount":               len(taskPayload.ElasticNetworkInterfaces),
@@ -216,6 +229,7 @@ func (c *common) buildAWSVPCNetworkNamespaces(
 		if err != nil {
 			return nil, err
 		}
+		primaryNetNS.NetworkPolicy = networkPolicy
 
 		return []*tasknetworkconfig.NetworkNamespace{primaryNetNS}, nil
 	}
@@ -274,6 +288,7 @@ func (c *common) buildAWSVPCNetworkNamespaces(
 		if err != nil {
 			return nil, err
 		}
+		netNS.NetworkPolicy = networkPolicy
 		netNSs = append(netNSs, netNS)
 		nsIndex += 1
 	}
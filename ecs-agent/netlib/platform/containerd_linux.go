@@ -21,8 +21,10 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/acs/model/ecsacs"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkpolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 )
 
 // containerd implements platform API methods for non-firecrakcer infrastructure.
@@ -62,3 +64,20 @@ func (c *containerd) ConfigureServiceConnect(
 ) error {
 	return c.common.configureServiceConnect(ctx, netNSPath, primaryIf, scConfig)
 }
+
+func (c *containerd) ConfigureNetworkPolicy(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+	policy *networkpolicy.NetworkPolicy,
+) error {
+	return c.common.configureNetworkPolicy(ctx, netNSPath, primaryIf, policy)
+}
+
+func (c *containerd) GetNetworkPolicyStats(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+) (*stats.NetworkPolicyStats, error) {
+	return c.common.getNetworkPolicyStats(ctx, netNSPath, primaryIf)
+}
@@ -25,9 +25,11 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkpolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/netwrapper"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/oswrapper"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
@@ -241,6 +243,23 @@ func (c *containerd) ConfigureServiceConnect(
 	return errors.New("not implemented")
 }
 
+func (c *containerd) ConfigureNetworkPolicy(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+	policy *networkpolicy.NetworkPolicy,
+) error {
+	return errors.New("not implemented")
+}
+
+func (c *containerd) GetNetworkPolicyStats(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+) (*stats.NetworkPolicyStats, error) {
+	return nil, errors.New("not implemented")
+}
+
 // configureRegularENI configures a network interface for an ENI.
 func (c *containerd) configureRegularENI(ctx context.Context, netNSID string, iface *networkinterface.NetworkInterface) error {
 	var cniNetConf []ecscni.PluginConfig
@@ -24,9 +24,11 @@ import (
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkpolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/pkg/errors"
@@ -100,6 +102,25 @@ func (f *firecraker) ConfigureServiceConnect(
 	return errors.New("not implemented")
 }
 
+// ConfigureNetworkPolicy is not supported, as the task egress traffic is forwarded from the
+// microVM rather than sent from the task network namespace.
+func (f *firecraker) ConfigureNetworkPolicy(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+	policy *networkpolicy.NetworkPolicy,
+) error {
+	return errors.New("not implemented")
+}
+
+func (f *firecraker) GetNetworkPolicyStats(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+) (*stats.NetworkPolicyStats, error) {
+	return nil, errors.New("not implemented")
+}
+
 // configureSecondaryDNSConfig creates DNS config files for secondary interfaces. This is required because
 // on FoF, secondary interfaces reside in their own network namespace inside the microVM. The DNS config
 // inside the namespace will need to be the secondary interface DNS config.
@@ -12,9 +12,11 @@ import (
 	netlibdata "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkpolicy"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/status"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/net"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -98,6 +100,23 @@ func (m *managedLinux) ConfigureServiceConnect(
 	return m.common.configureServiceConnect(ctx, netNSPath, primaryIf, scConfig)
 }
 
+func (m *managedLinux) ConfigureNetworkPolicy(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+	policy *networkpolicy.NetworkPolicy,
+) error {
+	return m.common.configureNetworkPolicy(ctx, netNSPath, primaryIf, policy)
+}
+
+func (m *managedLinux) GetNetworkPolicyStats(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+) (*stats.NetworkPolicyStats, error) {
+	return m.common.getNetworkPolicyStats(ctx, netNSPath, primaryIf)
+}
+
 // buildDefaultNetworkNamespace return default network namespace of host ENI for host mode.
 func (m *managedLinux) buildDefaultNetworkNamespace(taskID string) ([]*tasknetworkconfig.NetworkNamespace, error) {
 	macAddress, err1 := m.client.GetMetadata(MacResource)
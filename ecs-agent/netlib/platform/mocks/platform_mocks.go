@@ -26,8 +26,10 @@ import (
 	data "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/data"
 	appmesh "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/appmesh"
 	networkinterface "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	networkpolicy "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkpolicy"
 	serviceconnect "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/serviceconnect"
 	tasknetworkconfig "github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/tasknetworkconfig"
+	stats "github.com/aws/amazon-ecs-agent/ecs-agent/stats"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -97,6 +99,20 @@ func (mr *MockAPIMockRecorder) ConfigureInterface(arg0, arg1, arg2, arg3 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureInterface", reflect.TypeOf((*MockAPI)(nil).ConfigureInterface), arg0, arg1, arg2, arg3)
 }
 
+// ConfigureNetworkPolicy mocks base method.
+func (m *MockAPI) ConfigureNetworkPolicy(arg0 context.Context, arg1 string, arg2 *networkinterface.NetworkInterface, arg3 *networkpolicy.NetworkPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfigureNetworkPolicy", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfigureNetworkPolicy indicates an expected call of ConfigureNetworkPolicy.
+func (mr *MockAPIMockRecorder) ConfigureNetworkPolicy(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigureNetworkPolicy", reflect.TypeOf((*MockAPI)(nil).ConfigureNetworkPolicy), arg0, arg1, arg2, arg3)
+}
+
 // ConfigureServiceConnect mocks base method.
 func (m *MockAPI) ConfigureServiceConnect(arg0 context.Context, arg1 string, arg2 *networkinterface.NetworkInterface, arg3 *serviceconnect.ServiceConnectConfig) error {
 	m.ctrl.T.Helper()
@@ -181,6 +197,21 @@ func (mr *MockAPIMockRecorder) GetNetNSPath(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetNSPath", reflect.TypeOf((*MockAPI)(nil).GetNetNSPath), arg0)
 }
 
+// GetNetworkPolicyStats mocks base method.
+func (m *MockAPI) GetNetworkPolicyStats(arg0 context.Context, arg1 string, arg2 *networkinterface.NetworkInterface) (*stats.NetworkPolicyStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetworkPolicyStats", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*stats.NetworkPolicyStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNetworkPolicyStats indicates an expected call of GetNetworkPolicyStats.
+func (mr *MockAPIMockRecorder) GetNetworkPolicyStats(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetworkPolicyStats", reflect.TypeOf((*MockAPI)(nil).GetNetworkPolicyStats), arg0, arg1, arg2)
+}
+
 // HandleHostMode mocks base method.
 func (m *MockAPI) HandleHostMode() error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,375 @@
+//go:build !windows
+// +build !windows
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package platform
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/ecscni"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkpolicy"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
+
+	cnins "github.com/containernetworking/plugins/pkg/ns"
+	"github.com/pkg/errors"
+)
+
+const (
+	// networkPolicyChain is the filter table chain the egress traffic goes through. It jumps to the
+	// rules chain, and counts, and drops, the traffic which isn't accepted there. It is created once,
+	// so that its counters are kept when the rules are refreshed.
+	networkPolicyChain = "ECS-EGRESS-POLICY"
+	// networkPolicyRulesChain is the filter table chain holding the egress rules of the network policy,
+	// which accept the allowed traffic. It is replaced whenever the rules are refreshed.
+	networkPolicyRulesChain = "ECS-EGRESS-POLICY-RULES"
+	// networkPolicyViolationComment marks the rule which counts, and drops, the egress traffic
+	// that doesn't match any rule of the network policy.
+	networkPolicyViolationComment = "ecs-network-policy-violation"
+	networkPolicyCommandTimeout   = 30 * time.Second
+	iptablesWaitSeconds           = "10"
+
+	iptablesCmd         = "iptables"
+	ip6tablesCmd        = "ip6tables"
+	iptablesRestoreCmd  = "iptables-restore"
+	ip6tablesRestoreCmd = "ip6tables-restore"
+	nsenterCmd          = "nsenter"
+
+	dnsPort = "53"
+
+	// The task metadata endpoint and the Amazon provided DNS server are always reachable from the task,
+	// as the task can't work without them.
+	networkPolicyTaskMetadataEndpointIPv4 = "169.254.170.2"
+	networkPolicyDNSServerIPv4            = "169.254.169.253"
+	networkPolicyDNSServerIPv6            = "fd00:ec2::253"
+)
+
+// netNSResolver resolves DNS names into IP addresses from inside a network namespace.
+type netNSResolver interface {
+	LookupHost(ctx context.Context, netNSPath string, dnsServers []string, host string) ([]string, error)
+}
+
+// nsResolver queries the DNS servers over sockets opened inside the network namespace, so that names
+// resolve as they do for the task, e.g. the private hosted zones of the task VPC.
+type nsResolver struct {
+	nsUtil ecscni.NetNSUtil
+}
+
+func (r *nsResolver) LookupHost(
+	ctx context.Context,
+	netNSPath string,
+	dnsServers []string,
+	host string,
+) ([]string, error) {
+	resolver := &net.Resolver{
+		PreferGo: true,
+		// The servers of the host resolv.conf are ignored, the queries go to the DNS servers of the task.
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var conn net.Conn
+			err := r.nsUtil.ExecInNSPath(netNSPath, func(cnins.NetNS) error {
+				var err error
+				conn, err = (&net.Dialer{}).DialContext(ctx, network, net.JoinHostPort(dnsServers[0], dnsPort))
+				return err
+			})
+			return conn, err
+		},
+	}
+	return resolver.LookupHost(ctx, host)
+}
+
+// configureNetworkPolicy enforces the network policy inside the network namespace. The rules of the
+// policy are replaced atomically, hence this is also used to refresh the addresses of the DNS names
+// of the policy. The rules live in the network namespace, and are removed along with it.
+func (c *common) configureNetworkPolicy(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+	policy *networkpolicy.NetworkPolicy,
+) error {
+	logger.Info("Configuring network policy", logger.Fields{
+		"NetNSPath":     netNSPath,
+		"DefaultAction": policy.DefaultAction,
+	})
+
+	resolved := c.resolveNetworkPolicyDNSNames(ctx, netNSPath, primaryIf, policy)
+	if err := c.applyNetworkPolicyRules(ctx, netNSPath, iptablesRestoreCmd, iptablesCmd,
+		policy, resolved, primaryIf, false); err != nil {
+		return err
+	}
+	if !hasIPv6(primaryIf) {
+		return nil
+	}
+	return c.applyNetworkPolicyRules(ctx, netNSPath, ip6tablesRestoreCmd, ip6tablesCmd,
+		policy, resolved, primaryIf, true)
+}
+
+// getNetworkPolicyStats returns the counters of the egress traffic which doesn't match any rule of the
+// network policy.
+func (c *common) getNetworkPolicyStats(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+) (*stats.NetworkPolicyStats, error) {
+	policyStats := &stats.NetworkPolicyStats{}
+	commands := []string{iptablesCmd}
+	if hasIPv6(primaryIf) {
+		commands = append(commands, ip6tablesCmd)
+	}
+	for _, command := range commands {
+		out, err := c.runInNetNS(ctx, netNSPath, nil, command,
+			"-w", iptablesWaitSeconds, "-t", "filter", "-L", networkPolicyChain, "-v", "-x", "-n")
+		if err != nil {
+			return nil, err
+		}
+		packets, bytes, err := parseNetworkPolicyViolations(out)
+		if err != nil {
+			return nil, err
+		}
+		policyStats.ViolationPackets += packets
+		policyStats.ViolationBytes += bytes
+	}
+	return policyStats, nil
+}
+
+// resolveNetworkPolicyDNSNames resolves the DNS names of the policy inside the network namespace, with
+// the DNS servers of the primary interface, or the Amazon provided DNS server. A name which can't be
+// resolved allows no address until it is resolved by a later refresh.
+func (c *common) resolveNetworkPolicyDNSNames(
+	ctx context.Context,
+	netNSPath string,
+	primaryIf *networkinterface.NetworkInterface,
+	policy *networkpolicy.NetworkPolicy,
+) map[string][]string {
+	dnsServers := []string{networkPolicyDNSServerIPv4}
+	if primaryIf != nil && len(primaryIf.DomainNameServers) > 0 {
+		dnsServers = primaryIf.DomainNameServers
+	}
+	resolved := make(map[string][]string)
+	for _, name := range policy.DNSNames() {
+		addrs, err := c.resolver.LookupHost(ctx, netNSPath, dnsServers, name)
+		if err != nil {
+			logger.Warn("Unable to resolve DNS name of network policy", logger.Fields{
+				"DNSName": name,
+				"Error":   err,
+			})
+			continue
+		}
+		sort.Strings(addrs)
+		resolved[name] = addrs
+	}
+	return resolved
+}
+
+// applyNetworkPolicyRules replaces the rules of the network policy rules chain and makes sure that the
+// egress traffic goes through the network policy chain. The network policy chain is only created along
+// with the first rules, and isn't touched afterwards, so that the violation counters are kept.
+func (c *common) applyNetworkPolicyRules(
+	ctx context.Context,
+	netNSPath string,
+	restoreCommand string,
+	command string,
+	policy *networkpolicy.NetworkPolicy,
+	resolved map[string][]string,
+	primaryIf *networkinterface.NetworkInterface,
+	ipv6 bool,
+) error {
+	_, err := c.runInNetNS(ctx, netNSPath, nil, command,
+		"-w", iptablesWaitSeconds, "-t", "filter", "-S", networkPolicyChain)
+	createPolicyChain := err != nil
+
+	// --noflush leaves the other chains alone, only the chains declared in the input are flushed.
+	rules := buildNetworkPolicyRules(policy, resolved, primaryIf, ipv6, createPolicyChain)
+	_, err = c.runInNetNS(ctx, netNSPath, strings.NewReader(rules), restoreCommand,
+		"-w", iptablesWaitSeconds, "--noflush")
+	if err != nil {
+		return errors.Wrap(err, "failed to apply network policy rules")
+	}
+
+	_, err = c.runInNetNS(ctx, netNSPath, nil, command,
+		"-w", iptablesWaitSeconds, "-C", "OUTPUT", "-j", networkPolicyChain)
+	if err == nil {
+		return nil
+	}
+	_, err = c.runInNetNS(ctx, netNSPath, nil, command,
+		"-w", iptablesWaitSeconds, "-I", "OUTPUT", "1", "-j", networkPolicyChain)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert network policy chain")
+	}
+	return nil
+}
+
+// runInNetNS runs the command inside the network namespace.
+func (c *common) runInNetNS(
+	ctx context.Context,
+	netNSPath string,
+	stdin io.Reader,
+	command string,
+	args ...string,
+) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, networkPolicyCommandTimeout)
+	defer cancel()
+
+	cmd := c.exec.CommandContext(ctx, nsenterCmd, append([]string{"--net=" + netNSPath, command}, args...)...)
+	if stdin != nil {
+		cmd.SetIOStreams(stdin, nil, nil)
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return out, errors.Wrapf(err, "%s failed: %s", command, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+// buildNetworkPolicyRules renders the iptables-restore input of the network policy rules chain, for
+// either IPv4 or IPv6. The network policy chain, which counts the violations, is only declared when it is
+// created, since declaring a chain flushes it and resets its counters.
+func buildNetworkPolicyRules(
+	policy *networkpolicy.NetworkPolicy,
+	resolved map[string][]string,
+	primaryIf *networkinterface.NetworkInterface,
+	ipv6 bool,
+	createPolicyChain bool,
+) string {
+	var b strings.Builder
+	appendRule := func(rule string) {
+		fmt.Fprintf(&b, "-A %s %s -j ACCEPT\n", networkPolicyRulesChain, rule)
+	}
+
+	b.WriteString("*filter\n")
+	fmt.Fprintf(&b, ":%s - [0:0]\n", networkPolicyRulesChain)
+	if createPolicyChain {
+		fmt.Fprintf(&b, ":%s - [0:0]\n", networkPolicyChain)
+	}
+	appendRule("-o lo")
+	appendRule("-m conntrack --ctstate ESTABLISHED,RELATED")
+
+	dnsServers := []string{networkPolicyDNSServerIPv4}
+	if ipv6 {
+		dnsServers = []string{networkPolicyDNSServerIPv6}
+	} else {
+		appendRule(fmt.Sprintf("-d %s", hostCIDR(networkPolicyTaskMetadataEndpointIPv4)))
+	}
+	if primaryIf != nil {
+		dnsServers = append(dnsServers, primaryIf.DomainNameServers...)
+	}
+	for _, server := range dnsServers {
+		if isIPv6(server) != ipv6 {
+			continue
+		}
+		for _, protocol := range []string{networkpolicy.ProtocolUDP, networkpolicy.ProtocolTCP} {
+			appendRule(fmt.Sprintf("-d %s -p %s --dport %s", hostCIDR(server), protocol, dnsPort))
+		}
+	}
+
+	for _, rule := range policy.Egress {
+		var destinations []string
+		for _, cidr := range rule.CIDRs {
+			if isIPv6(strings.Split(cidr, "/")[0]) == ipv6 {
+				destinations = append(destinations, cidr)
+			}
+		}
+		for _, name := range rule.DNSNames {
+			for _, addr := range resolved[name] {
+				if isIPv6(addr) == ipv6 {
+					destinations = append(destinations, hostCIDR(addr))
+				}
+			}
+		}
+
+		for _, destination := range destinations {
+			if len(rule.Ports) == 0 {
+				protocol := ""
+				if rule.Protocol != networkpolicy.ProtocolAll {
+					protocol = " -p " + rule.Protocol
+				}
+				appendRule(fmt.Sprintf("-d %s%s", destination, protocol))
+				continue
+			}
+			for _, port := range rule.Ports {
+				appendRule(fmt.Sprintf("-d %s -p %s --dport %s",
+					destination, rule.Protocol, strings.Replace(port, "-", ":", 1)))
+			}
+		}
+	}
+
+	if createPolicyChain {
+		// Traffic which isn't accepted by the rules chain violates the policy. The rule without a target
+		// only counts it.
+		fmt.Fprintf(&b, "-A %s -j %s\n", networkPolicyChain, networkPolicyRulesChain)
+		violationRule := fmt.Sprintf("-m comment --comment %s", networkPolicyViolationComment)
+		if policy.Deny() {
+			violationRule += " -j DROP"
+		}
+		fmt.Fprintf(&b, "-A %s %s\n", networkPolicyChain, violationRule)
+	}
+	b.WriteString("COMMIT\n")
+	return b.String()
+}
+
+// parseNetworkPolicyViolations reads the packet and byte counters of the violation rule from the
+// output of `iptables -L <chain> -v -x -n`.
+func parseNetworkPolicyViolations(out []byte) (uint64, uint64, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.Contains(line, networkPolicyViolationComment) {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			break
+		}
+		packets, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "invalid packet counter in %q", line)
+		}
+		bytes, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, 0, errors.Wrapf(err, "invalid byte counter in %q", line)
+		}
+		return packets, bytes, nil
+	}
+	return 0, 0, errors.New("network policy violation rule not found")
+}
+
+// hasIPv6 returns whether the interface has IPv6 connectivity, in which case IPv6 egress traffic
+// is filtered too.
+func hasIPv6(iface *networkinterface.NetworkInterface) bool {
+	return iface != nil && len(iface.IPV6Addresses) > 0
+}
+
+func isIPv6(addr string) bool {
+	ip := net.ParseIP(addr)
+	return ip != nil && ip.To4() == nil
+}
+
+// hostCIDR returns the CIDR block of a single address.
+func hostCIDR(addr string) string {
+	if isIPv6(addr) {
+		return addr + "/128"
+	}
+	return addr + "/32"
+}
@@ -0,0 +1,270 @@
+//go:build !windows && unit
+// +build !windows,unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package platform
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkinterface"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/netlib/model/networkpolicy"
+	mock_execwrapper "github.com/aws/amazon-ecs-agent/ecs-agent/utils/execwrapper/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicyNetNSPath = "/var/run/netns/test-netns"
+
+// fakeResolver resolves DNS names from a map.
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupHost(_ context.Context, _ string, _ []string, host string) ([]string, error) {
+	addrs, ok := r[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return addrs, nil
+}
+
+func testNetworkPolicy() *networkpolicy.NetworkPolicy {
+	return &networkpolicy.NetworkPolicy{
+		DefaultAction: networkpolicy.DefaultActionDeny,
+		Egress: []networkpolicy.EgressRule{
+			{
+				CIDRs:    []string{"10.0.0.0/8", "2001:db8::/32"},
+				Protocol: networkpolicy.ProtocolTCP,
+				Ports:    []string{"443", "8000-8080"},
+			},
+			{
+				DNSNames: []string{"example.com", "unknown.example.com"},
+				Protocol: networkpolicy.ProtocolAll,
+			},
+		},
+	}
+}
+
+func TestBuildNetworkPolicyRules(t *testing.T) {
+	iface := &networkinterface.NetworkInterface{
+		DomainNameServers: []string{"10.0.0.2", "fd00::2"},
+		IPV6Addresses:     []*networkinterface.IPV6Address{{Address: "2001:db8::10"}},
+	}
+	resolved := map[string][]string{"example.com": {"2001:db8::1", "93.184.216.34"}}
+
+	expectedIPv4 := `*filter
+:ECS-EGRESS-POLICY-RULES - [0:0]
+:ECS-EGRESS-POLICY - [0:0]
+-A ECS-EGRESS-POLICY-RULES -o lo -j ACCEPT
+-A ECS-EGRESS-POLICY-RULES -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT
+-A ECS-EGRESS-POLICY-RULES -d 169.254.170.2/32 -j ACCEPT
+-A ECS-EGRESS-POLICY-RULES -d 169.254.169.253/32 -p udp --dport 53 -j ACCEPT
+-A ECS-EGRESS-POLICY-RULES -d 169.254.169.253/32 -p tcp --dport 53 -j ACCEPT
+-A ECS-EGRESS-POLICY-RULES -d 10.0.0.2/32 -p udp --dport 53 -j ACCEPT
+-A ECS-EGRESS-POLICY-RULES -d 10.0.0.2/32 -p tcp --dport 53 -j ACCEPT
+-A ECS-EGRESS-POLICY-RULES -d 10.0.0.0/8 -p tcp --dport 443 -j ACCEPT
+-A ECS-EGRESS-POLICY-RULES -d 10.0.0.0/8 -p tcp --dport 8000:8080 -j ACCEPT
+-A ECS-EGRESS-POLICY-RULES -d 93.184.216.34/32 -j ACCEPT
+-A ECS-EGRESS-POLICY -j ECS-EGRESS-POLICY-RULES
+-A ECS-EGRESS-POLICY -m comment --comment ecs-network-policy-violation -j DROP
+COMMIT
+`
+	assert.Equal(t, expectedIPv4, buildNetworkPolicyRules(testNetworkPolicy(), resolved, iface, false, true))
+
+	expectedIPv6 := `*filter
+:ECS-EGRESS-POLICY-RULES - [0:0]
+:ECS-EGRESS-POLICY - [0:0]
+-A ECS-EGRESS-POLICY-RULES -o lo -j ACCEPT
+-A ECS-EGRESS-POLICY-RULES -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT
+-A ECS-EGRESS-POLICY-RULES -d fd00:ec2::253/128 -p udp --dport 53 -j ACCEPT
+-A ECS-EGRESS-POLICY-RULES -d fd00:ec2::253/128 -p tcp --dport 53 -j ACCEPT
+-A ECS-EGRESS-POLICY-RULES -d fd00::2/128 -p udp --dport 53 -j ACCEPT
+-A ECS-EGRESS-POLICY-RULES -d fd00::2/128 -p tcp --dport 53 -j ACCEPT
+-A ECS-EGRESS-POLICY-RULES -d 2001:db8::/32 -p tcp --dport 443 -j ACCEPT
+-A ECS-EGRESS-POLICY-RULES -d 2001:db8::/32 -p tcp --dport 8000:8080 -j ACCEPT
+-A ECS-EGRESS-POLICY-RULES -d 2001:db8::1/128 -j ACCEPT
+-A ECS-EGRESS-POLICY -j ECS-EGRESS-POLICY-RULES
+-A ECS-EGRESS-POLICY -m comment --comment ecs-network-policy-violation -j DROP
+COMMIT
+`
+	assert.Equal(t, expectedIPv6, buildNetworkPolicyRules(testNetworkPolicy(), resolved, iface, true, true))
+}
+
+func TestBuildNetworkPolicyRulesRefresh(t *testing.T) {
+	// Refreshing the rules must not declare the network policy chain, which would reset its counters
+	rules := buildNetworkPolicyRules(testNetworkPolicy(), nil, nil, false, false)
+	assert.Contains(t, rules, ":ECS-EGRESS-POLICY-RULES - [0:0]\n")
+	assert.NotContains(t, rules, ":ECS-EGRESS-POLICY -")
+	assert.NotContains(t, rules, "-A ECS-EGRESS-POLICY ")
+}
+
+func TestBuildNetworkPolicyRulesAllow(t *testing.T) {
+	policy := &networkpolicy.NetworkPolicy{DefaultAction: networkpolicy.DefaultActionAllow}
+	rules := buildNetworkPolicyRules(policy, nil, nil, false, true)
+	assert.Contains(t, rules, "-A ECS-EGRESS-POLICY -m comment --comment ecs-network-policy-violation\n")
+	assert.NotContains(t, rules, "DROP")
+}
+
+func TestConfigureNetworkPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := mock_execwrapper.NewMockExec(ctrl)
+	listCmd := mock_execwrapper.NewMockCmd(ctrl)
+	restoreCmd := mock_execwrapper.NewMockCmd(ctrl)
+	checkCmd := mock_execwrapper.NewMockCmd(ctrl)
+	insertCmd := mock_execwrapper.NewMockCmd(ctrl)
+	commonPlatform := &common{
+		exec:     exec,
+		resolver: fakeResolver{"example.com": {"93.184.216.34"}},
+	}
+
+	gomock.InOrder(
+		exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net="+testPolicyNetNSPath,
+			"iptables", "-w", "10", "-t", "filter", "-S", "ECS-EGRESS-POLICY").Return(listCmd),
+		listCmd.EXPECT().CombinedOutput().Return([]byte("No chain/target/match by that name."),
+			errors.New("exit status 1")),
+		exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net="+testPolicyNetNSPath,
+			"iptables-restore", "-w", "10", "--noflush").Return(restoreCmd),
+		restoreCmd.EXPECT().SetIOStreams(gomock.Any(), nil, nil).Do(func(stdin io.Reader, _, _ io.Writer) {
+			rules, err := io.ReadAll(stdin)
+			require.NoError(t, err)
+			// The network policy chain is created along with the first rules
+			assert.Contains(t, string(rules), ":ECS-EGRESS-POLICY - [0:0]\n")
+			assert.Contains(t, string(rules), "-A ECS-EGRESS-POLICY-RULES -d 93.184.216.34/32 -j ACCEPT\n")
+		}),
+		restoreCmd.EXPECT().CombinedOutput().Return(nil, nil),
+		exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net="+testPolicyNetNSPath,
+			"iptables", "-w", "10", "-C", "OUTPUT", "-j", "ECS-EGRESS-POLICY").Return(checkCmd),
+		checkCmd.EXPECT().CombinedOutput().Return([]byte("Bad rule"), errors.New("exit status 1")),
+		exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net="+testPolicyNetNSPath,
+			"iptables", "-w", "10", "-I", "OUTPUT", "1", "-j", "ECS-EGRESS-POLICY").Return(insertCmd),
+		insertCmd.EXPECT().CombinedOutput().Return(nil, nil),
+	)
+
+	// The interface has no IPv6 address, hence only the IPv4 rules are applied.
+	err := commonPlatform.configureNetworkPolicy(context.TODO(), testPolicyNetNSPath,
+		&networkinterface.NetworkInterface{}, testNetworkPolicy())
+	require.NoError(t, err)
+}
+
+func TestConfigureNetworkPolicyRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := mock_execwrapper.NewMockExec(ctrl)
+	listCmd := mock_execwrapper.NewMockCmd(ctrl)
+	restoreCmd := mock_execwrapper.NewMockCmd(ctrl)
+	checkCmd := mock_execwrapper.NewMockCmd(ctrl)
+	commonPlatform := &common{
+		exec:     exec,
+		resolver: fakeResolver{"example.com": {"93.184.216.35"}},
+	}
+
+	gomock.InOrder(
+		exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net="+testPolicyNetNSPath,
+			"iptables", "-w", "10", "-t", "filter", "-S", "ECS-EGRESS-POLICY").Return(listCmd),
+		listCmd.EXPECT().CombinedOutput().Return([]byte("-N ECS-EGRESS-POLICY\n"), nil),
+		exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net="+testPolicyNetNSPath,
+			"iptables-restore", "-w", "10", "--noflush").Return(restoreCmd),
+		restoreCmd.EXPECT().SetIOStreams(gomock.Any(), nil, nil).Do(func(stdin io.Reader, _, _ io.Writer) {
+			rules, err := io.ReadAll(stdin)
+			require.NoError(t, err)
+			// Only the rules chain is replaced, the violation counters are kept
+			assert.NotContains(t, string(rules), ":ECS-EGRESS-POLICY -")
+			assert.Contains(t, string(rules), "-A ECS-EGRESS-POLICY-RULES -d 93.184.216.35/32 -j ACCEPT\n")
+		}),
+		restoreCmd.EXPECT().CombinedOutput().Return(nil, nil),
+		exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net="+testPolicyNetNSPath,
+			"iptables", "-w", "10", "-C", "OUTPUT", "-j", "ECS-EGRESS-POLICY").Return(checkCmd),
+		checkCmd.EXPECT().CombinedOutput().Return(nil, nil),
+	)
+
+	err := commonPlatform.configureNetworkPolicy(context.TODO(), testPolicyNetNSPath,
+		&networkinterface.NetworkInterface{}, testNetworkPolicy())
+	require.NoError(t, err)
+}
+
+func TestConfigureNetworkPolicyRestoreError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := mock_execwrapper.NewMockExec(ctrl)
+	listCmd := mock_execwrapper.NewMockCmd(ctrl)
+	restoreCmd := mock_execwrapper.NewMockCmd(ctrl)
+	commonPlatform := &common{
+		exec:     exec,
+		resolver: fakeResolver{},
+	}
+
+	gomock.InOrder(
+		exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net="+testPolicyNetNSPath,
+			"iptables", "-w", "10", "-t", "filter", "-S", "ECS-EGRESS-POLICY").Return(listCmd),
+		listCmd.EXPECT().CombinedOutput().Return(nil, errors.New("exit status 1")),
+		exec.EXPECT().CommandContext(gomock.Any(), "nsenter", gomock.Any()).Return(restoreCmd),
+		restoreCmd.EXPECT().SetIOStreams(gomock.Any(), nil, nil),
+		restoreCmd.EXPECT().CombinedOutput().Return([]byte("line 3 failed"), errors.New("exit status 1")),
+	)
+
+	err := commonPlatform.configureNetworkPolicy(context.TODO(), testPolicyNetNSPath, nil, testNetworkPolicy())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 3 failed")
+}
+
+func TestGetNetworkPolicyStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exec := mock_execwrapper.NewMockExec(ctrl)
+	ipv4Cmd := mock_execwrapper.NewMockCmd(ctrl)
+	ipv6Cmd := mock_execwrapper.NewMockCmd(ctrl)
+	commonPlatform := &common{exec: exec}
+
+	ipv4Output := `Chain ECS-EGRESS-POLICY (1 references)
+    pkts      bytes target     prot opt in     out     source               destination
+       0        0 RETURN     all  --  *      lo      0.0.0.0/0            0.0.0.0/0
+     120     9600 RETURN     all  --  *      *       0.0.0.0/0            0.0.0.0/0            ctstate RELATED,ESTABLISHED
+      12      720 DROP       all  --  *      *       0.0.0.0/0            0.0.0.0/0            /* ecs-network-policy-violation */
+`
+	ipv6Output := `Chain ECS-EGRESS-POLICY (1 references)
+    pkts      bytes target     prot opt in     out     source               destination
+       3      240 DROP       all      *      *       ::/0                 ::/0                 /* ecs-network-policy-violation */
+`
+	gomock.InOrder(
+		exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net="+testPolicyNetNSPath,
+			"iptables", "-w", "10", "-t", "filter", "-L", "ECS-EGRESS-POLICY", "-v", "-x", "-n").Return(ipv4Cmd),
+		ipv4Cmd.EXPECT().CombinedOutput().Return([]byte(ipv4Output), nil),
+		exec.EXPECT().CommandContext(gomock.Any(), "nsenter", "--net="+testPolicyNetNSPath,
+			"ip6tables", "-w", "10", "-t", "filter", "-L", "ECS-EGRESS-POLICY", "-v", "-x", "-n").Return(ipv6Cmd),
+		ipv6Cmd.EXPECT().CombinedOutput().Return([]byte(ipv6Output), nil),
+	)
+
+	iface := &networkinterface.NetworkInterface{
+		IPV6Addresses: []*networkinterface.IPV6Address{{Address: "2001:db8::10"}},
+	}
+	policyStats, err := commonPlatform.getNetworkPolicyStats(context.TODO(), testPolicyNetNSPath, iface)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(15), policyStats.ViolationPackets)
+	assert.Equal(t, uint64(960), policyStats.ViolationBytes)
+}
+
+func TestParseNetworkPolicyViolationsNotFound(t *testing.T) {
+	_, _, err := parseNetworkPolicyViolations([]byte("Chain ECS-EGRESS-POLICY (1 references)\n"))
+	assert.Error(t, err)
+}
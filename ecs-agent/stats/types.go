@@ -16,3 +16,17 @@ type NetworkStatsPerSec struct {
 	RxBytesPerSecond float64 `json:"rx_bytes_per_sec"`
 	TxBytesPerSecond float64 `json:"tx_bytes_per_sec"`
 }
+
+// NetworkPolicyStats holds the counters of the egress traffic of a task which violates the task network policy.
+type NetworkPolicyStats struct {
+	DefaultAction    string `json:"default_action"`
+	ViolationPackets uint64 `json:"violation_packets"`
+	ViolationBytes   uint64 `json:"violation_bytes"`
+}
+
+// NetworkBandwidthLimits holds the network bandwidth limits applied to a container, a limit is 0 if the
+// traffic isn't limited.
+type NetworkBandwidthLimits struct {
+	IngressBitsPerSecond uint64 `json:"ingress_bits_per_sec"`
+	EgressBitsPerSecond  uint64 `json:"egress_bits_per_sec"`
+}
@@ -19,6 +19,7 @@ import (
 	"io"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/amazon-ecs-agent/ecs-agent/doctor"
@@ -52,6 +53,7 @@ type TcsEcsClient interface {
 type TelemetrySession interface {
 	StartTelemetrySession(context.Context) error
 	Start(context.Context) error
+	GetLastConnectedTime() time.Time
 }
 
 // telemetrySession is the base session params type which contains all the parameters required to start a tcs session
@@ -74,6 +76,8 @@ type telemetrySession struct {
 	healthChannel                 <-chan ecstcs.HealthMessage
 	doctor                        *doctor.Doctor
 	ecsClient                     TcsEcsClient
+	lastConnectedTime             time.Time
+	lock                          sync.RWMutex
 }
 
 func NewTelemetrySession(
@@ -180,6 +184,7 @@ func (session *telemetrySession) StartTelemetrySession(ctx context.Context) erro
 		return err
 	}
 	defer disconnectTimer.Stop()
+	session.setLastConnectedTime(time.Now())
 	logger.Info("Connected to TCS endpoint")
 	// start a timer and listens for tcs heartbeats/acks. The timer is reset when
 	// we receive a heartbeat from the server or when a published metrics message
@@ -196,6 +201,19 @@ func (session *telemetrySession) StartTelemetrySession(ctx context.Context) erro
 	return client.Serve(ctx)
 }
 
+// GetLastConnectedTime returns the timestamp that the last connection was established to TCS.
+func (session *telemetrySession) GetLastConnectedTime() time.Time {
+	session.lock.RLock()
+	defer session.lock.RUnlock()
+	return session.lastConnectedTime
+}
+
+func (session *telemetrySession) setLastConnectedTime(lastConnectedTime time.Time) {
+	session.lock.Lock()
+	defer session.lock.Unlock()
+	session.lastConnectedTime = lastConnectedTime
+}
+
 func (session *telemetrySession) getTelemetryEndpoint() (string, error) {
 	containerInstanceARN := session.containerInstanceArn
 	tcsEndpoint, err := session.ecsClient.DiscoverTelemetryEndpoint(containerInstanceARN)
@@ -211,6 +211,8 @@ func TestStartTelemetrySession(t *testing.T) {
 		testecsclient,
 	)
 
+	assert.True(t, session.GetLastConnectedTime().IsZero())
+
 	// Start a session with the test server.
 	go session.StartTelemetrySession(ctx)
 
@@ -223,6 +225,7 @@ func TestStartTelemetrySession(t *testing.T) {
 
 	// Read request channel to get the metric data published to the server.
 	request := <-requestChan
+	assert.False(t, session.GetLastConnectedTime().IsZero())
 	cancel()
 	wait.Wait()
 	go func() {
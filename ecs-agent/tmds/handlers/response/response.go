@@ -14,6 +14,8 @@
 // This package defines some common types used by all task metadata functions
 package response
 
+import "time"
+
 // VolumeResponse is the schema for the volume response JSON object
 type VolumeResponse struct {
 	DockerName  string `json:"DockerName,omitempty"`
@@ -36,3 +38,12 @@ type Network struct {
 	IPv4Addresses []string `json:"IPv4Addresses,omitempty"`
 	IPv6Addresses []string `json:"IPv6Addresses,omitempty"`
 }
+
+// HealthCheckResultResponse is the schema for the result of a single container
+// health check probe
+type HealthCheckResultResponse struct {
+	Start    *time.Time `json:"Start,omitempty"`
+	End      *time.Time `json:"End,omitempty"`
+	ExitCode int        `json:"ExitCode"`
+	Output   string     `json:"Output,omitempty"`
+}
@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"time"
 
+	ecsapi "github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs"
 	apierrors "github.com/aws/amazon-ecs-agent/ecs-agent/api/errors"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
@@ -31,6 +32,7 @@ import (
 	v4 "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v4"
 	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v4/state"
 	commonutils "github.com/aws/amazon-ecs-agent/ecs-agent/utils"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
@@ -46,8 +48,17 @@ const (
 	expectedProtectionResponseLength = 1
 	ecsCallTimedOutError             = "Timed out calling ECS Task Protection API"
 	taskMetadataFetchFailureMsg      = "Failed to find a task for the request"
+	// throttlingRetryAttempts is the number of times a throttled ECS Task Protection API call is attempted
+	throttlingRetryAttempts = 3
+	throttlingErrorCode     = "ThrottlingException"
 )
 
+// throttlingBackoff is the backoff between attempts of a throttled ECS Task Protection API call.
+// It's a variable so that tests can shorten it.
+var throttlingBackoff = func() retry.Backoff {
+	return retry.NewExponentialBackoff(200*time.Millisecond, 2*time.Second, 0.2, 2)
+}
+
 // TaskProtectionPath Returns endpoint path for UpdateTaskProtection API
 func TaskProtectionPath() string {
 	return fmt.Sprintf(
@@ -55,10 +66,23 @@ func TaskProtectionPath() string {
 		utils.ConstructMuxVar(v4.EndpointContainerIDMuxName, utils.AnythingButSlashRegEx))
 }
 
+// TaskProtectionHistoryPath Returns endpoint path for the task protection history API
+func TaskProtectionHistoryPath() string {
+	return fmt.Sprintf(
+		"/api/%s/task-protection/v1/history",
+		utils.ConstructMuxVar(v4.EndpointContainerIDMuxName, utils.AnythingButSlashRegEx))
+}
+
 // TaskProtectionRequest is the Task protection request received from customers pending validation
 type TaskProtectionRequest struct {
 	ProtectionEnabled *bool
 	ExpiresInMinutes  *int64
+	// Reason is an optional description of why protection is changed, kept in the task protection history
+	Reason *string
+	// RequestedBy optionally identifies who changed protection, kept in the task protection history
+	RequestedBy *string
+	// RenewalPolicy optionally asks the agent to keep renewing the protection before it expires
+	RenewalPolicy *types.RenewalPolicy
 }
 
 // CanceledError is an interface that defines a method to check if an error is a cancellation error.
@@ -75,6 +99,7 @@ func GetTaskProtectionHandler(
 	cluster string,
 	metricsFactory metrics.EntryFactory,
 	ecsCallTimeout time.Duration,
+	recorder *TaskProtectionRecorder,
 ) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		requestType := "api/GetTaskProtection/v1"
@@ -115,11 +140,29 @@ func GetTaskProtectionHandler(
 
 		ctx, cancel := context.WithTimeout(r.Context(), ecsCallTimeout)
 		defer cancel()
-		responseBody, err := ecsClient.GetTaskProtection(ctx, &ecs.GetTaskProtectionInput{
-			Cluster: aws.String(cluster),
-			Tasks:   []string{task.TaskARN},
+		var responseBody *ecs.GetTaskProtectionOutput
+		err = callWithThrottlingRetries(ctx, func() error {
+			var err error
+			responseBody, err = ecsClient.GetTaskProtection(ctx, &ecs.GetTaskProtectionInput{
+				Cluster: aws.String(cluster),
+				Tasks:   []string{task.TaskARN},
+			})
+			return err
 		})
 		if err != nil {
+			if isThrottlingError(err) && recorder != nil {
+				if protection, ok := recorder.cachedProtection(task.TaskARN); ok {
+					logger.Warn("ECS Task Protection API is throttled, returning cached task protection", logger.Fields{
+						field.TaskARN:     task.TaskARN,
+						field.Error:       err,
+						field.RequestType: requestType,
+					})
+					utils.WriteJSONResponse(w, http.StatusOK,
+						types.NewTaskProtectionResponseCachedProtection(protection), requestType)
+					successMetric.WithCount(0).Done(nil)
+					return
+				}
+			}
 			errResponseCode, errResponseBody := logAndHandleECSError(err, *task, requestType)
 			utils.WriteJSONResponse(w, errResponseCode, errResponseBody, requestType)
 			successMetric.WithCount(0).Done(nil)
@@ -136,6 +179,10 @@ func GetTaskProtectionHandler(
 		}
 
 		// ECS call was successful
+		if recorder != nil && len(responseBody.ProtectedTasks) > 0 {
+			endpointContainerID := mux.Vars(r)[v4.EndpointContainerIDMuxName]
+			recorder.recordProtection(task.TaskARN, endpointContainerID, &responseBody.ProtectedTasks[0])
+		}
 		utils.WriteJSONResponse(w, http.StatusOK,
 			types.NewTaskProtectionResponseProtection(&responseBody.ProtectedTasks[0]), requestType)
 		successMetric.WithCount(1).Done(nil)
@@ -150,6 +197,7 @@ func UpdateTaskProtectionHandler(
 	cluster string,
 	metricsFactory metrics.EntryFactory,
 	ecsCallTimeout time.Duration,
+	recorder *TaskProtectionRecorder,
 ) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		requestType := "api/UpdateTaskProtection/v1"
@@ -197,6 +245,12 @@ func UpdateTaskProtectionHandler(
 			utils.WriteJSONResponse(w, http.StatusBadRequest, response, requestType)
 			return
 		}
+		if errMsg := validateRenewalPolicy(request); errMsg != "" {
+			responseErr := types.NewErrorResponsePtr(task.TaskARN, apierrors.ErrCodeInvalidParameterException, errMsg)
+			response := types.NewTaskProtectionResponseError(responseErr, nil)
+			utils.WriteJSONResponse(w, http.StatusBadRequest, response, requestType)
+			return
+		}
 
 		// Prepare ECS request body
 		taskProtection := types.NewTaskProtection(*request.ProtectionEnabled, request.ExpiresInMinutes)
@@ -227,14 +281,20 @@ func UpdateTaskProtectionHandler(
 		ctx, cancel := context.WithTimeout(r.Context(), ecsCallTimeout)
 		defer cancel()
 
-		response, err := ecsClient.UpdateTaskProtection(ctx, &ecs.UpdateTaskProtectionInput{
-			Cluster:           aws.String(cluster),
-			ExpiresInMinutes:  commonutils.Int64PtrToInt32Ptr(taskProtection.GetExpiresInMinutes()),
-			ProtectionEnabled: taskProtection.GetProtectionEnabled(),
-			Tasks:             []string{task.TaskARN},
-		})
+		historyEntry := newHistoryEntry(types.TaskProtectionSourceRequest,
+			taskProtection.GetProtectionEnabled(), taskProtection.GetExpiresInMinutes())
+		historyEntry.Reason = aws.ToString(request.Reason)
+		historyEntry.RequestedBy = aws.ToString(request.RequestedBy)
+		endpointContainerID := mux.Vars(r)[v4.EndpointContainerIDMuxName]
+
+		response, err := updateTaskProtection(ctx, ecsClient, cluster, task.TaskARN,
+			taskProtection.GetProtectionEnabled(), taskProtection.GetExpiresInMinutes())
 		if err != nil {
 			errResponseCode, errResponseBody := logAndHandleECSError(err, *task, requestType)
+			if recorder != nil {
+				historyEntry.Error = errResponseBody.Error
+				recorder.recordUpdate(task.TaskARN, endpointContainerID, request, historyEntry)
+			}
 			utils.WriteJSONResponse(w, errResponseCode, errResponseBody, requestType)
 			successMetric.WithCount(0).Done(nil)
 			return
@@ -244,18 +304,106 @@ func UpdateTaskProtectionHandler(
 		errResponseCode, errResponseBody = logAndValidateECSResponse(
 			response.ProtectedTasks, response.Failures, *task, requestType)
 		if errResponseBody != nil {
+			if recorder != nil {
+				historyEntry.Failure = errResponseBody.Failure
+				historyEntry.Error = errResponseBody.Error
+				recorder.recordUpdate(task.TaskARN, endpointContainerID, request, historyEntry)
+			}
 			utils.WriteJSONResponse(w, errResponseCode, errResponseBody, requestType)
 			successMetric.WithCount(0).Done(nil)
 			return
 		}
 
 		// ECS call was successful
+		if recorder != nil {
+			historyEntry.Protection = &response.ProtectedTasks[0]
+			recorder.recordUpdate(task.TaskARN, endpointContainerID, request, historyEntry)
+		}
 		utils.WriteJSONResponse(w, http.StatusOK,
 			types.NewTaskProtectionResponseProtection(&response.ProtectedTasks[0]), requestType)
 		successMetric.WithCount(1).Done(nil)
 	}
 }
 
+// TaskProtectionHistoryHandler returns an HTTP request handler function that returns the local record
+// of task protection changes made through the agent for the task
+func TaskProtectionHistoryHandler(
+	agentState state.AgentState,
+	recorder *TaskProtectionRecorder,
+) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestType := "api/TaskProtectionHistory/v1"
+
+		task, errResponseCode, errResponseBody := getTaskMetadata(r, agentState, requestType)
+		if errResponseBody != nil {
+			utils.WriteJSONResponse(w, errResponseCode, errResponseBody, requestType)
+			return
+		}
+
+		record, ok := recorder.Get(task.TaskARN)
+		if !ok {
+			record = types.TaskProtectionRecord{TaskARN: task.TaskARN}
+		}
+		utils.WriteJSONResponse(w, http.StatusOK, record, requestType)
+	}
+}
+
+// Helper function for calling the ECS UpdateTaskProtection API
+func updateTaskProtection(
+	ctx context.Context,
+	ecsClient ecsapi.ECSTaskProtectionSDK,
+	cluster string,
+	taskARN string,
+	protectionEnabled bool,
+	expiresInMinutes *int64,
+) (*ecs.UpdateTaskProtectionOutput, error) {
+	var response *ecs.UpdateTaskProtectionOutput
+	err := callWithThrottlingRetries(ctx, func() error {
+		var err error
+		response, err = ecsClient.UpdateTaskProtection(ctx, &ecs.UpdateTaskProtectionInput{
+			Cluster:           aws.String(cluster),
+			ExpiresInMinutes:  commonutils.Int64PtrToInt32Ptr(expiresInMinutes),
+			ProtectionEnabled: protectionEnabled,
+			Tasks:             []string{taskARN},
+		})
+		return err
+	})
+	return response, err
+}
+
+// Helper function for validating the renewal policy of a request. Returns an error message if the
+// policy is invalid.
+func validateRenewalPolicy(request TaskProtectionRequest) string {
+	policy := request.RenewalPolicy
+	if policy == nil {
+		return ""
+	}
+	if !aws.ToBool(request.ProtectionEnabled) {
+		return "Invalid request: 'RenewalPolicy' requires 'ProtectionEnabled' to be true"
+	}
+	if policy.MaxRenewals == nil || *policy.MaxRenewals <= 0 {
+		return "Invalid request: 'RenewalPolicy.MaxRenewals' must be greater than 0"
+	}
+	if policy.RenewBeforeExpiryMinutes != nil && *policy.RenewBeforeExpiryMinutes <= 0 {
+		return "Invalid request: 'RenewalPolicy.RenewBeforeExpiryMinutes' must be greater than 0"
+	}
+	return ""
+}
+
+// Helper function for creating the task protection history entry of a protection change
+func newHistoryEntry(
+	source string,
+	protectionEnabled bool,
+	expiresInMinutes *int64,
+) types.TaskProtectionHistoryEntry {
+	return types.TaskProtectionHistoryEntry{
+		Time:              time.Now(),
+		Source:            source,
+		ProtectionEnabled: protectionEnabled,
+		ExpiresInMinutes:  expiresInMinutes,
+	}
+}
+
 // Helper function for retrieving task metadata for the request
 func getTaskMetadata(
 	r *http.Request,
@@ -403,6 +551,33 @@ func getTaskMetadataErrorResponse(
 	return http.StatusInternalServerError, types.NewTaskProtectionResponseError(responseErr, nil)
 }
 
+// Helper function for calling the ECS Task Protection API. Calls that are throttled by ECS are retried
+// with backoff, other errors are returned right away.
+func callWithThrottlingRetries(ctx context.Context, fn func() error) error {
+	var err error
+	retry.RetryNWithBackoffCtx(ctx, throttlingBackoff(), throttlingRetryAttempts, func() error {
+		err = fn()
+		if err != nil && !isThrottlingError(err) {
+			return apierrors.NewRetriableError(apierrors.NewRetriable(false), err)
+		}
+		return err
+	})
+	return err
+}
+
+// Helper function for checking whether an error returned by the ECS Task Protection API is a throttling error
+func isThrottlingError(err error) bool {
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code() == throttlingErrorCode
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == throttlingErrorCode {
+		return true
+	}
+	var re *awshttp.ResponseError
+	return errors.As(err, &re) && re.HTTPStatusCode() == http.StatusTooManyRequests
+}
+
 // Helper function to parse error to get ErrorCode, ExceptionMessage, HttpStatusCode, RequestID.
 // RequestID will be empty if the request is not able to reach AWS
 func getErrorCodeAndStatusCode(err error) (string, string, int, *string) {
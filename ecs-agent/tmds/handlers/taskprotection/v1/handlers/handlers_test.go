@@ -114,11 +114,11 @@ func testTaskProtectionRequest(t *testing.T, tc TestCase) {
 	router := mux.NewRouter()
 	router.HandleFunc(
 		TaskProtectionPath(),
-		GetTaskProtectionHandler(agentState, credsManager, factory, cluster, metricsFactory, ecsCallTimeout),
+		GetTaskProtectionHandler(agentState, credsManager, factory, cluster, metricsFactory, ecsCallTimeout, nil),
 	).Methods("GET")
 	router.HandleFunc(
 		TaskProtectionPath(),
-		UpdateTaskProtectionHandler(agentState, credsManager, factory, cluster, metricsFactory, ecsCallTimeout),
+		UpdateTaskProtectionHandler(agentState, credsManager, factory, cluster, metricsFactory, ecsCallTimeout, nil),
 	).Methods("PUT")
 
 	// Create the request
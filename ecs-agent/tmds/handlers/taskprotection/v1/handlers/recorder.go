@@ -0,0 +1,238 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/taskprotection/v1/types"
+
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+)
+
+const (
+	// maxTaskProtectionHistory is the number of protection changes kept per task
+	maxTaskProtectionHistory = 20
+)
+
+// TaskProtectionStore persists the records of a TaskProtectionRecorder, so that the renewals of task
+// protection resume after the agent restarts.
+type TaskProtectionStore interface {
+	// SaveTaskProtectionRecords replaces the saved records with the given ones
+	SaveTaskProtectionRecords([]PersistedTaskProtectionRecord) error
+	// GetTaskProtectionRecords returns the saved records
+	GetTaskProtectionRecords() ([]PersistedTaskProtectionRecord, error)
+}
+
+// PersistedTaskProtectionRecord is a record saved by a TaskProtectionStore. It includes the ID of the
+// endpoint container, which isn't part of the records returned by the history API.
+type PersistedTaskProtectionRecord struct {
+	types.TaskProtectionRecord
+	EndpointContainerID string
+}
+
+// TaskProtectionRecorder keeps a local record of the task protection requests made through TMDS
+// and of the last protection state returned by ECS for each task. The records are saved in the
+// store of the recorder, if any, every time they change.
+type TaskProtectionRecorder struct {
+	lock    sync.RWMutex
+	records map[string]*types.TaskProtectionRecord
+	store   TaskProtectionStore
+}
+
+// NewTaskProtectionRecorder creates an empty TaskProtectionRecorder, whose records are kept in memory only
+func NewTaskProtectionRecorder() *TaskProtectionRecorder {
+	return &TaskProtectionRecorder{
+		records: make(map[string]*types.TaskProtectionRecord),
+	}
+}
+
+// NewPersistentTaskProtectionRecorder creates a TaskProtectionRecorder with the records saved in the store
+func NewPersistentTaskProtectionRecorder(store TaskProtectionStore) (*TaskProtectionRecorder, error) {
+	persisted, err := store.GetTaskProtectionRecords()
+	if err != nil {
+		return nil, err
+	}
+	recorder := NewTaskProtectionRecorder()
+	recorder.store = store
+	for _, persistedRecord := range persisted {
+		record := persistedRecord.TaskProtectionRecord
+		record.EndpointContainerID = persistedRecord.EndpointContainerID
+		recorder.records[record.TaskARN] = &record
+	}
+	return recorder, nil
+}
+
+// Get returns a copy of the record of the task
+func (r *TaskProtectionRecorder) Get(taskARN string) (types.TaskProtectionRecord, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	record, ok := r.records[taskARN]
+	if !ok {
+		return types.TaskProtectionRecord{}, false
+	}
+	return copyRecord(record), true
+}
+
+// GetAll returns a copy of the records of all tasks
+func (r *TaskProtectionRecorder) GetAll() []types.TaskProtectionRecord {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	records := make([]types.TaskProtectionRecord, 0, len(r.records))
+	for _, record := range r.records {
+		records = append(records, copyRecord(record))
+	}
+	return records
+}
+
+// Remove forgets the record of the task
+func (r *TaskProtectionRecorder) Remove(taskARN string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.records[taskARN]; !ok {
+		return
+	}
+	delete(r.records, taskARN)
+	r.save()
+}
+
+// recordProtection stores the protection state returned by ECS for the task, along with the ID of
+// the container whose endpoint was called so that the renewer can look the task up.
+func (r *TaskProtectionRecorder) recordProtection(
+	taskARN string,
+	endpointContainerID string,
+	protection *ecstypes.ProtectedTask,
+) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	record := r.getOrCreate(taskARN)
+	if endpointContainerID != "" {
+		record.EndpointContainerID = endpointContainerID
+	}
+	setLastProtection(record, protection)
+	r.save()
+}
+
+// recordUpdate stores a protection change and the ECS response to it. Changes requested through
+// TMDS replace the renewal policy of the task, while renewals increment its renewal count.
+func (r *TaskProtectionRecorder) recordUpdate(
+	taskARN string,
+	endpointContainerID string,
+	request TaskProtectionRequest,
+	entry types.TaskProtectionHistoryEntry,
+) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	record := r.getOrCreate(taskARN)
+	switch entry.Source {
+	case types.TaskProtectionSourceRequest:
+		record.EndpointContainerID = endpointContainerID
+		record.ExpiresInMinutes = request.ExpiresInMinutes
+		record.Renewals = 0
+		record.RenewalPolicy = nil
+		if entry.Protection != nil && entry.Protection.ProtectionEnabled {
+			record.RenewalPolicy = request.RenewalPolicy
+		}
+	case types.TaskProtectionSourceRenewal:
+		record.Renewals++
+	}
+	if entry.Protection != nil {
+		setLastProtection(record, entry.Protection)
+	}
+
+	record.History = append(record.History, entry)
+	if len(record.History) > maxTaskProtectionHistory {
+		record.History = record.History[len(record.History)-maxTaskProtectionHistory:]
+	}
+	r.save()
+}
+
+// stopRenewals clears the renewal policy of the task
+func (r *TaskProtectionRecorder) stopRenewals(taskARN string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if record, ok := r.records[taskARN]; ok {
+		record.RenewalPolicy = nil
+		r.save()
+	}
+}
+
+// save saves the records in the store of the recorder, if any. It must be called with the lock held.
+// Failing to save the records doesn't fail the protection change, which has already been made in ECS:
+// only the renewals after the agent restarts are lost.
+func (r *TaskProtectionRecorder) save() {
+	if r.store == nil {
+		return
+	}
+	persisted := make([]PersistedTaskProtectionRecord, 0, len(r.records))
+	for _, record := range r.records {
+		persisted = append(persisted, PersistedTaskProtectionRecord{
+			TaskProtectionRecord: copyRecord(record),
+			EndpointContainerID:  record.EndpointContainerID,
+		})
+	}
+	if err := r.store.SaveTaskProtectionRecords(persisted); err != nil {
+		logger.Warn("Unable to save task protection records", logger.Fields{
+			field.Error: err,
+		})
+	}
+}
+
+func (r *TaskProtectionRecorder) getOrCreate(taskARN string) *types.TaskProtectionRecord {
+	record, ok := r.records[taskARN]
+	if !ok {
+		record = &types.TaskProtectionRecord{TaskARN: taskARN}
+		r.records[taskARN] = record
+	}
+	return record
+}
+
+// cachedProtection returns the last protection state returned by ECS for the task. Protection
+// that has expired since then is reported as disabled.
+func (r *TaskProtectionRecorder) cachedProtection(taskARN string) (*ecstypes.ProtectedTask, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	record, ok := r.records[taskARN]
+	if !ok || record.LastProtection == nil {
+		return nil, false
+	}
+	protection := *record.LastProtection
+	if protection.ExpirationDate != nil && protection.ExpirationDate.Before(time.Now()) {
+		protection.ProtectionEnabled = false
+		protection.ExpirationDate = nil
+	}
+	return &protection, true
+}
+
+func copyRecord(record *types.TaskProtectionRecord) types.TaskProtectionRecord {
+	recordCopy := *record
+	recordCopy.History = append([]types.TaskProtectionHistoryEntry(nil), record.History...)
+	return recordCopy
+}
+
+func setLastProtection(record *types.TaskProtectionRecord, protection *ecstypes.ProtectedTask) {
+	now := time.Now()
+	record.LastProtection = protection
+	record.LastProtectionUpdatedAt = &now
+}
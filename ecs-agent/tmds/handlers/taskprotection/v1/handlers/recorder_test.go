@@ -0,0 +1,335 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mock_api "github.com/aws/amazon-ecs-agent/ecs-agent/api/ecs/mocks"
+	mock_credentials "github.com/aws/amazon-ecs-agent/ecs-agent/credentials/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/metrics"
+	mock_metrics "github.com/aws/amazon-ecs-agent/ecs-agent/metrics/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/taskprotection/v1/types"
+	mock_state "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v4/state/mocks"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/utils/retry"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	throttlingBackoff = func() retry.Backoff {
+		return retry.NewExponentialBackoff(time.Millisecond, time.Millisecond, 0, 1)
+	}
+}
+
+// Returns an error like the one returned by ECS when a call is throttled.
+func throttlingError() error {
+	return &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{
+				Response: &http.Response{StatusCode: http.StatusBadRequest},
+			},
+			Err: &smithy.GenericAPIError{Code: throttlingErrorCode, Message: "Rate exceeded"},
+		},
+		RequestID: "reqID",
+	}
+}
+
+type recorderTestMocks struct {
+	agentState     *mock_state.MockAgentState
+	credsManager   *mock_credentials.MockManager
+	factory        *MockTaskProtectionClientFactoryInterface
+	metricsFactory *mock_metrics.MockEntryFactory
+	client         *mock_api.MockECSTaskProtectionSDK
+}
+
+func newRecorderTestMocks(ctrl *gomock.Controller) recorderTestMocks {
+	return recorderTestMocks{
+		agentState:     mock_state.NewMockAgentState(ctrl),
+		credsManager:   mock_credentials.NewMockManager(ctrl),
+		factory:        NewMockTaskProtectionClientFactoryInterface(ctrl),
+		metricsFactory: mock_metrics.NewMockEntryFactory(ctrl),
+		client:         mock_api.NewMockECSTaskProtectionSDK(ctrl),
+	}
+}
+
+// Sends a request to the task protection handlers set up with the recorder.
+func serveWithRecorder(
+	t *testing.T,
+	mocks recorderTestMocks,
+	recorder *TaskProtectionRecorder,
+	method, path string,
+	body interface{},
+) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.HandleFunc(TaskProtectionPath(), GetTaskProtectionHandler(mocks.agentState, mocks.credsManager,
+		mocks.factory, cluster, mocks.metricsFactory, ecsCallTimeout, recorder)).Methods("GET")
+	router.HandleFunc(TaskProtectionPath(), UpdateTaskProtectionHandler(mocks.agentState, mocks.credsManager,
+		mocks.factory, cluster, mocks.metricsFactory, ecsCallTimeout, recorder)).Methods("PUT")
+	router.HandleFunc(TaskProtectionHistoryPath(),
+		TaskProtectionHistoryHandler(mocks.agentState, recorder)).Methods("GET")
+
+	var reqBody bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(body))
+	}
+	req, err := http.NewRequest(method, fmt.Sprintf(path, endpointId), &reqBody)
+	require.NoError(t, err)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, req)
+	return response
+}
+
+func TestTaskProtectionHistoryPath(t *testing.T) {
+	assert.Equal(t, "/api/{endpointContainerIDMuxName:[^/]*}/task-protection/v1/history", TaskProtectionHistoryPath())
+}
+
+func TestUpdateTaskProtectionRecordsHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mocks := newRecorderTestMocks(ctrl)
+	recorder := NewTaskProtectionRecorder()
+
+	happyStateExpectations(mocks.agentState)
+	happyCredsManagerExpectations(mocks.credsManager)
+	metricsExpectations(metrics.UpdateTaskProtectionMetricName, 1)(ctrl, mocks.metricsFactory)
+	mocks.factory.EXPECT().NewTaskProtectionClient(taskRoleCreds()).Return(mocks.client, nil)
+	protectedTask := ecsProtectedTask()
+	gomock.InOrder(
+		mocks.client.EXPECT().UpdateTaskProtection(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, throttlingError()),
+		mocks.client.EXPECT().UpdateTaskProtection(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&ecs.UpdateTaskProtectionOutput{ProtectedTasks: []ecstypes.ProtectedTask{protectedTask}}, nil),
+	)
+
+	response := serveWithRecorder(t, mocks, recorder, "PUT", "/api/%s/task-protection/v1/state",
+		map[string]interface{}{
+			"ProtectionEnabled": true,
+			"ExpiresInMinutes":  10,
+			"Reason":            "processing job",
+			"RequestedBy":       "worker",
+			"RenewalPolicy":     map[string]interface{}{"MaxRenewals": 2},
+		})
+	require.Equal(t, http.StatusOK, response.Code)
+
+	happyStateExpectations(mocks.agentState)
+	response = serveWithRecorder(t, mocks, recorder, "GET", "/api/%s/task-protection/v1/history", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	var record types.TaskProtectionRecord
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &record))
+	assert.Equal(t, taskARN, record.TaskARN)
+	assert.Equal(t, aws.Int64(2), record.RenewalPolicy.MaxRenewals)
+	assert.Equal(t, aws.Int64(10), record.ExpiresInMinutes)
+	require.Len(t, record.History, 1)
+	assert.Equal(t, types.TaskProtectionSourceRequest, record.History[0].Source)
+	assert.Equal(t, "processing job", record.History[0].Reason)
+	assert.Equal(t, "worker", record.History[0].RequestedBy)
+	assert.Equal(t, &protectedTask, record.History[0].Protection)
+}
+
+func TestUpdateTaskProtectionInvalidRenewalPolicy(t *testing.T) {
+	testCases := map[string]map[string]interface{}{
+		"protection disabled": {
+			"ProtectionEnabled": false,
+			"RenewalPolicy":     map[string]interface{}{"MaxRenewals": 2},
+		},
+		"missing max renewals": {
+			"ProtectionEnabled": true,
+			"RenewalPolicy":     map[string]interface{}{},
+		},
+		"invalid renew before expiry": {
+			"ProtectionEnabled": true,
+			"RenewalPolicy":     map[string]interface{}{"MaxRenewals": 2, "RenewBeforeExpiryMinutes": 0},
+		},
+	}
+	for name, body := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mocks := newRecorderTestMocks(ctrl)
+			happyStateExpectations(mocks.agentState)
+			mocks.metricsFactory.EXPECT().New(metrics.UpdateTaskProtectionMetricName).
+				Return(mock_metrics.NewMockEntry(ctrl))
+
+			response := serveWithRecorder(t, mocks, NewTaskProtectionRecorder(), "PUT",
+				"/api/%s/task-protection/v1/state", body)
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+		})
+	}
+}
+
+func TestGetTaskProtectionThrottledReturnsCachedProtection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mocks := newRecorderTestMocks(ctrl)
+	recorder := NewTaskProtectionRecorder()
+	protectedTask := ecsProtectedTask()
+	recorder.recordProtection(taskARN, endpointId, &protectedTask)
+
+	happyStateExpectations(mocks.agentState)
+	happyCredsManagerExpectations(mocks.credsManager)
+	metricsExpectations(metrics.GetTaskProtectionMetricName, 0)(ctrl, mocks.metricsFactory)
+	mocks.factory.EXPECT().NewTaskProtectionClient(taskRoleCreds()).Return(mocks.client, nil)
+	mocks.client.EXPECT().GetTaskProtection(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, throttlingError()).Times(throttlingRetryAttempts)
+
+	response := serveWithRecorder(t, mocks, recorder, "GET", "/api/%s/task-protection/v1/state", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var body types.TaskProtectionResponse
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+	assert.True(t, body.Cached)
+	assert.Equal(t, &protectedTask, body.Protection)
+}
+
+func TestGetTaskProtectionRecordsEndpointContainerID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mocks := newRecorderTestMocks(ctrl)
+	recorder := NewTaskProtectionRecorder()
+	protectedTask := ecsProtectedTask()
+
+	happyStateExpectations(mocks.agentState)
+	happyCredsManagerExpectations(mocks.credsManager)
+	metricsExpectations(metrics.GetTaskProtectionMetricName, 1)(ctrl, mocks.metricsFactory)
+	mocks.factory.EXPECT().NewTaskProtectionClient(taskRoleCreds()).Return(mocks.client, nil)
+	mocks.client.EXPECT().GetTaskProtection(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&ecs.GetTaskProtectionOutput{ProtectedTasks: []ecstypes.ProtectedTask{protectedTask}}, nil)
+
+	response := serveWithRecorder(t, mocks, recorder, "GET", "/api/%s/task-protection/v1/state", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	record, ok := recorder.Get(taskARN)
+	require.True(t, ok)
+	assert.Equal(t, endpointId, record.EndpointContainerID)
+	assert.Equal(t, &protectedTask, record.LastProtection)
+}
+
+func TestCachedProtectionExpired(t *testing.T) {
+	recorder := NewTaskProtectionRecorder()
+	expired := time.Now().Add(-time.Minute)
+	recorder.recordProtection(taskARN, endpointId, &ecstypes.ProtectedTask{
+		ProtectionEnabled: true,
+		ExpirationDate:    &expired,
+		TaskArn:           aws.String(taskARN),
+	})
+
+	protection, ok := recorder.cachedProtection(taskARN)
+	require.True(t, ok)
+	assert.False(t, protection.ProtectionEnabled)
+	assert.Nil(t, protection.ExpirationDate)
+}
+
+func TestRecorderHistoryIsBounded(t *testing.T) {
+	recorder := NewTaskProtectionRecorder()
+	for i := 0; i < maxTaskProtectionHistory+5; i++ {
+		recorder.recordUpdate(taskARN, endpointId, TaskProtectionRequest{},
+			newHistoryEntry(types.TaskProtectionSourceRequest, false, nil))
+	}
+	record, ok := recorder.Get(taskARN)
+	require.True(t, ok)
+	assert.Len(t, record.History, maxTaskProtectionHistory)
+}
+
+// fakeTaskProtectionStore saves the records as JSON, like the agent does
+type fakeTaskProtectionStore struct {
+	data    []byte
+	saveErr error
+}
+
+func (s *fakeTaskProtectionStore) SaveTaskProtectionRecords(records []PersistedTaskProtectionRecord) error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	data, err := json.Marshal(records)
+	s.data = data
+	return err
+}
+
+func (s *fakeTaskProtectionStore) GetTaskProtectionRecords() ([]PersistedTaskProtectionRecord, error) {
+	var records []PersistedTaskProtectionRecord
+	if s.data == nil {
+		return records, nil
+	}
+	return records, json.Unmarshal(s.data, &records)
+}
+
+func TestPersistentRecorderOutlivesTheAgent(t *testing.T) {
+	store := &fakeTaskProtectionStore{}
+	recorder, err := NewPersistentTaskProtectionRecorder(store)
+	require.NoError(t, err)
+	expiration := time.Now().Add(time.Hour).UTC()
+	entry := newHistoryEntry(types.TaskProtectionSourceRequest, true, aws.Int64(60))
+	entry.Protection = &ecstypes.ProtectedTask{
+		ProtectionEnabled: true,
+		ExpirationDate:    &expiration,
+		TaskArn:           aws.String(taskARN),
+	}
+	policy := &types.RenewalPolicy{MaxRenewals: aws.Int64(3)}
+	recorder.recordUpdate(taskARN, endpointId, TaskProtectionRequest{
+		ProtectionEnabled: aws.Bool(true),
+		ExpiresInMinutes:  aws.Int64(60),
+		RenewalPolicy:     policy,
+	}, entry)
+
+	restarted, err := NewPersistentTaskProtectionRecorder(store)
+	require.NoError(t, err)
+	record, ok := restarted.Get(taskARN)
+	require.True(t, ok)
+	assert.Equal(t, endpointId, record.EndpointContainerID)
+	assert.Equal(t, policy, record.RenewalPolicy)
+	assert.Equal(t, aws.Int64(60), record.ExpiresInMinutes)
+	require.NotNil(t, record.LastProtection)
+	assert.True(t, expiration.Equal(aws.ToTime(record.LastProtection.ExpirationDate)))
+	assert.Len(t, record.History, 1)
+
+	// the renewals resume with the saved records
+	assert.True(t, renewalDue(record, expiration))
+
+	restarted.Remove(taskARN)
+	restarted, err = NewPersistentTaskProtectionRecorder(store)
+	require.NoError(t, err)
+	assert.Empty(t, restarted.GetAll())
+}
+
+func TestPersistentRecorderSaveError(t *testing.T) {
+	store := &fakeTaskProtectionStore{saveErr: errors.New("disk full")}
+	recorder, err := NewPersistentTaskProtectionRecorder(store)
+	require.NoError(t, err)
+
+	// the record is kept in memory
+	recorder.recordUpdate(taskARN, endpointId, TaskProtectionRequest{},
+		newHistoryEntry(types.TaskProtectionSourceRequest, false, nil))
+	_, ok := recorder.Get(taskARN)
+	assert.True(t, ok)
+}
+
+func TestNewPersistentRecorderInvalidRecords(t *testing.T) {
+	_, err := NewPersistentTaskProtectionRecorder(&fakeTaskProtectionStore{data: []byte("invalid")})
+	assert.Error(t, err)
+}
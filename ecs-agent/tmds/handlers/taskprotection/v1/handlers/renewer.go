@@ -0,0 +1,168 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/credentials"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/taskprotection/v1/types"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v4/state"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+const (
+	// defaultRenewBeforeExpiryMinutes is used when a renewal policy doesn't set RenewBeforeExpiryMinutes
+	defaultRenewBeforeExpiryMinutes = 1
+	taskStoppedStatus               = "STOPPED"
+)
+
+// StartTaskProtectionRenewer renews task protection that was requested with a renewal policy until
+// the context is cancelled. Every interval, protection that expires within the RenewBeforeExpiryMinutes
+// of its policy is renewed with the expiry of the original request. Records of tasks that have
+// stopped are forgotten.
+func StartTaskProtectionRenewer(
+	ctx context.Context,
+	recorder *TaskProtectionRecorder,
+	agentState state.AgentState,
+	credentialsManager credentials.Manager,
+	factory TaskProtectionClientFactoryInterface,
+	cluster string,
+	ecsCallTimeout time.Duration,
+	interval time.Duration,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			renewTaskProtection(ctx, recorder, agentState, credentialsManager, factory, cluster, ecsCallTimeout)
+		}
+	}
+}
+
+// renewTaskProtection makes one pass over the records of the recorder
+func renewTaskProtection(
+	ctx context.Context,
+	recorder *TaskProtectionRecorder,
+	agentState state.AgentState,
+	credentialsManager credentials.Manager,
+	factory TaskProtectionClientFactoryInterface,
+	cluster string,
+	ecsCallTimeout time.Duration,
+) {
+	for _, record := range recorder.GetAll() {
+		task, err := agentState.GetTaskMetadata(record.EndpointContainerID)
+		if err != nil {
+			var errLookupFailure *state.ErrorLookupFailure
+			if record.EndpointContainerID == "" || errors.As(err, &errLookupFailure) {
+				recorder.Remove(record.TaskARN)
+			}
+			continue
+		}
+		if task.DesiredStatus == taskStoppedStatus || task.KnownStatus == taskStoppedStatus {
+			recorder.Remove(record.TaskARN)
+			continue
+		}
+		if !renewalDue(record, time.Now()) {
+			continue
+		}
+		renewTaskProtectionRecord(ctx, recorder, task, record, credentialsManager, factory, cluster, ecsCallTimeout)
+	}
+}
+
+// renewalDue returns whether the protection in the record should be renewed now. It stops renewals
+// of records whose protection has been disabled or has already expired.
+func renewalDue(record types.TaskProtectionRecord, now time.Time) bool {
+	policy := record.RenewalPolicy
+	if policy == nil || record.LastProtection == nil || record.LastProtection.ExpirationDate == nil {
+		return false
+	}
+	if record.Renewals >= aws.ToInt64(policy.MaxRenewals) {
+		return false
+	}
+	renewBefore := int64(defaultRenewBeforeExpiryMinutes)
+	if policy.RenewBeforeExpiryMinutes != nil {
+		renewBefore = *policy.RenewBeforeExpiryMinutes
+	}
+	renewAt := record.LastProtection.ExpirationDate.Add(-time.Duration(renewBefore) * time.Minute)
+	return !now.Before(renewAt)
+}
+
+// renewTaskProtectionRecord calls ECS to extend the protection of the task and records the result
+func renewTaskProtectionRecord(
+	ctx context.Context,
+	recorder *TaskProtectionRecorder,
+	task state.TaskResponse,
+	record types.TaskProtectionRecord,
+	credentialsManager credentials.Manager,
+	factory TaskProtectionClientFactoryInterface,
+	cluster string,
+	ecsCallTimeout time.Duration,
+) {
+	requestType := "renewal/UpdateTaskProtection/v1"
+	if !record.LastProtection.ProtectionEnabled || record.LastProtection.ExpirationDate.Before(time.Now()) {
+		logger.Warn("Task protection expired before it could be renewed, stopping renewals", logger.Fields{
+			field.TaskARN: record.TaskARN,
+		})
+		recorder.stopRenewals(record.TaskARN)
+		return
+	}
+
+	historyEntry := newHistoryEntry(types.TaskProtectionSourceRenewal, true, record.ExpiresInMinutes)
+	taskCreds, _, errResponseBody := getTaskCredentials(credentialsManager, task)
+	if errResponseBody != nil {
+		historyEntry.Error = errResponseBody.Error
+		recorder.recordUpdate(record.TaskARN, record.EndpointContainerID, TaskProtectionRequest{}, historyEntry)
+		return
+	}
+	ecsClient, err := factory.NewTaskProtectionClient(*taskCreds)
+	if err != nil {
+		_, errResponseBody := logAndHandleECSError(err, task, requestType)
+		historyEntry.Error = errResponseBody.Error
+		recorder.recordUpdate(record.TaskARN, record.EndpointContainerID, TaskProtectionRequest{}, historyEntry)
+		return
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, ecsCallTimeout)
+	defer cancel()
+	response, err := updateTaskProtection(callCtx, ecsClient, cluster, task.TaskARN, true, record.ExpiresInMinutes)
+	if err != nil {
+		_, errResponseBody := logAndHandleECSError(err, task, requestType)
+		historyEntry.Error = errResponseBody.Error
+		recorder.recordUpdate(record.TaskARN, record.EndpointContainerID, TaskProtectionRequest{}, historyEntry)
+		return
+	}
+	_, validationErrResponse := logAndValidateECSResponse(response.ProtectedTasks, response.Failures, task, requestType)
+	if validationErrResponse != nil {
+		historyEntry.Failure = validationErrResponse.Failure
+		historyEntry.Error = validationErrResponse.Error
+		recorder.recordUpdate(record.TaskARN, record.EndpointContainerID, TaskProtectionRequest{}, historyEntry)
+		return
+	}
+
+	historyEntry.Protection = &response.ProtectedTasks[0]
+	recorder.recordUpdate(record.TaskARN, record.EndpointContainerID, TaskProtectionRequest{}, historyEntry)
+	logger.Info("Renewed task protection", logger.Fields{
+		field.TaskARN:        record.TaskARN,
+		field.TaskProtection: response.ProtectedTasks[0],
+	})
+}
@@ -0,0 +1,123 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/taskprotection/v1/types"
+	v2 "github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v2"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/tmds/handlers/v4/state"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns a recorder holding a protection of the test task that expires at the given time
+// and was requested with the given renewal policy.
+func recorderWithProtection(expiresAt time.Time, policy *types.RenewalPolicy) *TaskProtectionRecorder {
+	recorder := NewTaskProtectionRecorder()
+	entry := newHistoryEntry(types.TaskProtectionSourceRequest, true, aws.Int64(10))
+	entry.Protection = &ecstypes.ProtectedTask{
+		ProtectionEnabled: true,
+		ExpirationDate:    &expiresAt,
+		TaskArn:           aws.String(taskARN),
+	}
+	recorder.recordUpdate(taskARN, endpointId, TaskProtectionRequest{
+		ProtectionEnabled: aws.Bool(true),
+		ExpiresInMinutes:  aws.Int64(10),
+		RenewalPolicy:     policy,
+	}, entry)
+	return recorder
+}
+
+func TestRenewalDue(t *testing.T) {
+	now := time.Now()
+	policy := &types.RenewalPolicy{MaxRenewals: aws.Int64(1), RenewBeforeExpiryMinutes: aws.Int64(5)}
+
+	record, _ := recorderWithProtection(now.Add(4*time.Minute), policy).Get(taskARN)
+	assert.True(t, renewalDue(record, now))
+
+	record, _ = recorderWithProtection(now.Add(6*time.Minute), policy).Get(taskARN)
+	assert.False(t, renewalDue(record, now))
+
+	record, _ = recorderWithProtection(now.Add(4*time.Minute), nil).Get(taskARN)
+	assert.False(t, renewalDue(record, now))
+
+	record, _ = recorderWithProtection(now.Add(4*time.Minute), policy).Get(taskARN)
+	record.Renewals = 1
+	assert.False(t, renewalDue(record, now))
+}
+
+func TestRenewTaskProtection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mocks := newRecorderTestMocks(ctrl)
+	recorder := recorderWithProtection(time.Now().Add(30*time.Second),
+		&types.RenewalPolicy{MaxRenewals: aws.Int64(1)})
+
+	renewed := time.Now().Add(10 * time.Minute)
+	happyStateExpectations(mocks.agentState)
+	happyCredsManagerExpectations(mocks.credsManager)
+	mocks.factory.EXPECT().NewTaskProtectionClient(taskRoleCreds()).Return(mocks.client, nil)
+	mocks.client.EXPECT().UpdateTaskProtection(gomock.Any(), &ecs.UpdateTaskProtectionInput{
+		Cluster:           aws.String(cluster),
+		ExpiresInMinutes:  aws.Int32(10),
+		ProtectionEnabled: true,
+		Tasks:             []string{taskARN},
+	}, gomock.Any()).Return(&ecs.UpdateTaskProtectionOutput{
+		ProtectedTasks: []ecstypes.ProtectedTask{{
+			ProtectionEnabled: true,
+			ExpirationDate:    &renewed,
+			TaskArn:           aws.String(taskARN),
+		}},
+	}, nil)
+
+	renewTaskProtection(context.Background(), recorder, mocks.agentState, mocks.credsManager,
+		mocks.factory, cluster, ecsCallTimeout)
+
+	record, ok := recorder.Get(taskARN)
+	require.True(t, ok)
+	assert.Equal(t, int64(1), record.Renewals)
+	assert.Equal(t, &renewed, record.LastProtection.ExpirationDate)
+	require.Len(t, record.History, 2)
+	assert.Equal(t, types.TaskProtectionSourceRenewal, record.History[1].Source)
+
+	// The maximum number of renewals has been reached, so the next pass doesn't call ECS.
+	happyStateExpectations(mocks.agentState)
+	renewTaskProtection(context.Background(), recorder, mocks.agentState, mocks.credsManager,
+		mocks.factory, cluster, ecsCallTimeout)
+}
+
+func TestRenewTaskProtectionRemovesStoppedTasks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mocks := newRecorderTestMocks(ctrl)
+	recorder := recorderWithProtection(time.Now().Add(time.Hour), nil)
+
+	mocks.agentState.EXPECT().GetTaskMetadata(endpointId).Return(state.TaskResponse{
+		TaskResponse: &v2.TaskResponse{TaskARN: taskARN, KnownStatus: taskStoppedStatus},
+	}, nil)
+	renewTaskProtection(context.Background(), recorder, mocks.agentState, mocks.credsManager,
+		mocks.factory, cluster, ecsCallTimeout)
+
+	_, ok := recorder.Get(taskARN)
+	assert.False(t, ok)
+}
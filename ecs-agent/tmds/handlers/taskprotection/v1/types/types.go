@@ -16,10 +16,18 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 )
 
+const (
+	// TaskProtectionSourceRequest marks protection changes requested through the TMDS endpoint.
+	TaskProtectionSourceRequest = "request"
+	// TaskProtectionSourceRenewal marks protection changes made by the agent to renew protection.
+	TaskProtectionSourceRenewal = "renewal"
+)
+
 // taskProtection is type of Protection for a Task
 type taskProtection struct {
 	protectionEnabled bool
@@ -73,6 +81,8 @@ type TaskProtectionResponse struct {
 	Protection *types.ProtectedTask `json:"protection,omitempty"`
 	Failure    *types.Failure       `json:"failure,omitempty"`
 	Error      *ErrorResponse       `json:"error,omitempty"`
+	// Cached is set when ECS could not be reached and Protection is the last state returned by ECS.
+	Cached bool `json:"cached,omitempty"`
 }
 
 // NewTaskProtectionResponseProtection creates a TaskProtectionResponse when it is a successful response (has protection)
@@ -80,6 +90,11 @@ func NewTaskProtectionResponseProtection(protection *types.ProtectedTask) TaskPr
 	return TaskProtectionResponse{Protection: protection}
 }
 
+// NewTaskProtectionResponseCachedProtection creates a TaskProtectionResponse from protection cached by the agent
+func NewTaskProtectionResponseCachedProtection(protection *types.ProtectedTask) TaskProtectionResponse {
+	return TaskProtectionResponse{Protection: protection, Cached: true}
+}
+
 // NewTaskProtectionResponseFailure creates a TaskProtectionResponse when there is a failed response with failure
 func NewTaskProtectionResponseFailure(failure *types.Failure) TaskProtectionResponse {
 	return TaskProtectionResponse{Failure: failure}
@@ -105,3 +120,44 @@ func NewErrorResponsePtr(arn string, code string, message string) *ErrorResponse
 		Message: message,
 	}
 }
+
+// RenewalPolicy asks the agent to renew task protection before it expires
+type RenewalPolicy struct {
+	// RenewBeforeExpiryMinutes is how many minutes before the protection expires it is renewed
+	RenewBeforeExpiryMinutes *int64
+	// MaxRenewals is the maximum number of times the agent renews the protection
+	MaxRenewals *int64
+}
+
+// TaskProtectionRecord is the local record the agent keeps of the task protection of a task
+type TaskProtectionRecord struct {
+	TaskARN string
+	// EndpointContainerID identifies the TMDS endpoint protection was last requested through
+	EndpointContainerID string `json:"-"`
+	// RenewalPolicy is the renewal policy of the latest request, if any
+	RenewalPolicy *RenewalPolicy `json:",omitempty"`
+	// Renewals is the number of times the agent renewed the protection under RenewalPolicy
+	Renewals int64
+	// ExpiresInMinutes is the expiry requested by the latest request, reused for renewals
+	ExpiresInMinutes *int64 `json:",omitempty"`
+	// LastProtection is the last protection state returned by ECS
+	LastProtection *types.ProtectedTask `json:",omitempty"`
+	// LastProtectionUpdatedAt is when LastProtection was received from ECS
+	LastProtectionUpdatedAt *time.Time `json:",omitempty"`
+	// History lists the latest protection changes, oldest first
+	History []TaskProtectionHistoryEntry
+}
+
+// TaskProtectionHistoryEntry records a single protection change and the ECS response to it
+type TaskProtectionHistoryEntry struct {
+	Time time.Time
+	// Source is either TaskProtectionSourceRequest or TaskProtectionSourceRenewal
+	Source            string
+	RequestedBy       string `json:",omitempty"`
+	Reason            string `json:",omitempty"`
+	ProtectionEnabled bool
+	ExpiresInMinutes  *int64               `json:",omitempty"`
+	Protection        *types.ProtectedTask `json:",omitempty"`
+	Failure           *types.Failure       `json:",omitempty"`
+	Error             *ErrorResponse       `json:",omitempty"`
+}
@@ -0,0 +1,79 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package state
+
+import (
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/logger/field"
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
+)
+
+// NetworkPolicyStatsGetter returns the network policy stats of the task of the container identified by
+// the provided endpointContainerID, e.g. with NetworkBuilder.GetNetworkPolicyStats of the task network
+// namespace. It returns nil if the task has no network policy.
+type NetworkPolicyStatsGetter func(endpointContainerID string) (*stats.NetworkPolicyStats, error)
+
+// agentStateWithNetworkPolicyStats adds the network policy stats of the task to the stats returned by
+// the wrapped AgentState.
+type agentStateWithNetworkPolicyStats struct {
+	AgentState
+	getNetworkPolicyStats NetworkPolicyStatsGetter
+}
+
+// NewAgentStateWithNetworkPolicyStats returns an AgentState which reports the network policy stats of
+// the task along with the container and task stats of agentState.
+func NewAgentStateWithNetworkPolicyStats(agentState AgentState, getter NetworkPolicyStatsGetter) AgentState {
+	return &agentStateWithNetworkPolicyStats{
+		AgentState:            agentState,
+		getNetworkPolicyStats: getter,
+	}
+}
+
+func (s *agentStateWithNetworkPolicyStats) GetContainerStats(endpointContainerID string) (StatsResponse, error) {
+	statsResponse, err := s.AgentState.GetContainerStats(endpointContainerID)
+	if err != nil {
+		return statsResponse, err
+	}
+	statsResponse.Network_policy_stats = s.networkPolicyStats(endpointContainerID)
+	return statsResponse, nil
+}
+
+func (s *agentStateWithNetworkPolicyStats) GetTaskStats(endpointContainerID string) (map[string]*StatsResponse, error) {
+	taskStats, err := s.AgentState.GetTaskStats(endpointContainerID)
+	if err != nil {
+		return taskStats, err
+	}
+	// The policy applies to the whole task, every container reports the same counters.
+	policyStats := s.networkPolicyStats(endpointContainerID)
+	for _, statsResponse := range taskStats {
+		if statsResponse != nil {
+			statsResponse.Network_policy_stats = policyStats
+		}
+	}
+	return taskStats, nil
+}
+
+// networkPolicyStats returns the network policy stats of the task. The stats are left out of the response
+// if they can't be read, rather than failing the whole request.
+func (s *agentStateWithNetworkPolicyStats) networkPolicyStats(endpointContainerID string) *stats.NetworkPolicyStats {
+	policyStats, err := s.getNetworkPolicyStats(endpointContainerID)
+	if err != nil {
+		logger.Warn("Unable to get network policy stats", logger.Fields{
+			"endpointContainerID": endpointContainerID,
+			field.Error:           err,
+		})
+		return nil
+	}
+	return policyStats
+}
@@ -0,0 +1,74 @@
+//go:build unit
+// +build unit
+
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package state
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-agent/stats"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testEndpointContainerID = "endpoint-container-id"
+
+// fakeStatsAgentState returns empty stats for a task with two containers.
+type fakeStatsAgentState struct {
+	AgentState
+}
+
+func (fakeStatsAgentState) GetContainerStats(string) (StatsResponse, error) {
+	return StatsResponse{}, nil
+}
+
+func (fakeStatsAgentState) GetTaskStats(string) (map[string]*StatsResponse, error) {
+	return map[string]*StatsResponse{"container1": {}, "container2": {}}, nil
+}
+
+func TestAgentStateWithNetworkPolicyStats(t *testing.T) {
+	policyStats := &stats.NetworkPolicyStats{DefaultAction: "DENY", ViolationPackets: 3, ViolationBytes: 180}
+	agentState := NewAgentStateWithNetworkPolicyStats(fakeStatsAgentState{},
+		func(endpointContainerID string) (*stats.NetworkPolicyStats, error) {
+			assert.Equal(t, testEndpointContainerID, endpointContainerID)
+			return policyStats, nil
+		})
+
+	containerStats, err := agentState.GetContainerStats(testEndpointContainerID)
+	require.NoError(t, err)
+	assert.Equal(t, policyStats, containerStats.Network_policy_stats)
+
+	taskStats, err := agentState.GetTaskStats(testEndpointContainerID)
+	require.NoError(t, err)
+	require.Len(t, taskStats, 2)
+	for _, containerStats := range taskStats {
+		assert.Equal(t, policyStats, containerStats.Network_policy_stats)
+	}
+}
+
+func TestAgentStateWithNetworkPolicyStatsError(t *testing.T) {
+	agentState := NewAgentStateWithNetworkPolicyStats(fakeStatsAgentState{},
+		func(string) (*stats.NetworkPolicyStats, error) {
+			return nil, errors.New("iptables failed")
+		})
+
+	// The stats are still returned, without the network policy stats
+	containerStats, err := agentState.GetContainerStats(testEndpointContainerID)
+	require.NoError(t, err)
+	assert.Nil(t, containerStats.Network_policy_stats)
+}
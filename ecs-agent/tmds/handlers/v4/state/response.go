@@ -36,6 +36,7 @@ type TaskResponse struct {
 	ServiceName             string                   `json:"ServiceName,omitempty"`
 	ClockDrift              *ClockDrift              `json:"ClockDrift,omitempty"`
 	EphemeralStorageMetrics *EphemeralStorageMetrics `json:"EphemeralStorageMetrics,omitempty"`
+	MemoryControls          *MemoryControls          `json:"MemoryControls,omitempty"`
 	CredentialsID           string                   `json:"-"`
 	TaskNetworkConfig       *TaskNetworkConfig       `json:"-"`
 	FaultInjectionEnabled   bool                     `json:"FaultInjectionEnabled"`
@@ -88,6 +89,15 @@ type EphemeralStorageMetrics struct {
 	ReservedMiBs int64 `json:"Reserved"`
 }
 
+// MemoryControls struct that is specific to the TMDS response. This struct will show customers the cgroup v2
+// memory controls in MiBs to match the units used in other fields in TMDS. A control which isn't set is left
+// to the default of the kernel.
+type MemoryControls struct {
+	MemoryHighMiBs    *int64 `json:"MemoryHigh,omitempty"`
+	MemorySwapMaxMiBs *int64 `json:"MemorySwapMax,omitempty"`
+	OOMGroup          *bool  `json:"OOMGroup,omitempty"`
+}
+
 // ContainerResponse is the v4 Container response. It augments the v4 Network response
 // with the v2 container response object.
 type ContainerResponse struct {
@@ -95,6 +105,10 @@ type ContainerResponse struct {
 	Networks     []Network `json:"Networks,omitempty"`
 	Snapshotter  string    `json:"Snapshotter,omitempty"`
 	RestartCount *int      `json:"RestartCount,omitempty"`
+	// HealthHistory lists the results of the latest health check probes, oldest first
+	HealthHistory []response.HealthCheckResultResponse `json:"HealthHistory,omitempty"`
+	// MemoryControls are the cgroup v2 memory controls the container sets with docker labels
+	MemoryControls *MemoryControls `json:"MemoryControls,omitempty"`
 }
 
 // Network is the v4 Network response. It adds a bunch of information about network
@@ -136,4 +150,8 @@ type NetworkInterfaceProperties struct {
 type StatsResponse struct {
 	*types.StatsJSON
 	Network_rate_stats *stats.NetworkStatsPerSec `json:"network_rate_stats,omitempty"`
+	// Network_policy_stats is only set for the containers of tasks with a network policy.
+	Network_policy_stats *stats.NetworkPolicyStats `json:"network_policy_stats,omitempty"`
+	// Network_bandwidth_limits is only set for the containers with network bandwidth limits.
+	Network_bandwidth_limits *stats.NetworkBandwidthLimits `json:"network_bandwidth_limits,omitempty"`
 }
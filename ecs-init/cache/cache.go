@@ -19,6 +19,8 @@ import (
 	"bufio"
 	"context"
 	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
@@ -58,6 +60,12 @@ const (
 	// distributed cached image on package installation, upgrades, or
 	// downgrades.
 	StatusReloadNeeded CacheStatus = 2
+	// StatusVerificationFailed indicates that there is an agent
+	// downloaded and cached but its signature could not be verified
+	// against the signing key, or that it has no signature while
+	// signature verification is required. The cached image must not be
+	// loaded and should be downloaded again.
+	StatusVerificationFailed CacheStatus = 3
 )
 
 // Downloader is responsible for cache operations relating to downloading the agent
@@ -65,13 +73,26 @@ type Downloader struct {
 	s3Downloader s3DownloaderAPI
 	fs           fileSystem
 	metadata     instanceMetadata
+	verifier     agentVerifier
 	region       string
+	// requireSignature rejects agents that are not signed. When it's not set, the
+	// signature is only verified if both the signing key and the signature are present.
+	requireSignature bool
+	// cachedAgentVerified is set once the cached agent has been verified, so that
+	// the tarball is hashed at most once
+	cachedAgentVerified bool
 }
 
 // NewDownloader returns a Downloader with default dependencies
 func NewDownloader() (*Downloader, error) {
+	fs := &standardFS{}
 	downloader := &Downloader{
-		fs: &standardFS{},
+		fs: fs,
+		verifier: &publicKeyVerifier{
+			keyFile: config.AgentSigningKeyFile(),
+			fs:      fs,
+		},
+		requireSignature: config.AgentSignatureRequired(),
 	}
 
 	if config.RunningInExternal() {
@@ -143,15 +164,21 @@ func (d *Downloader) AgentCacheStatus() CacheStatus {
 	if err != nil {
 		return StatusUncached
 	}
+	if status != StatusUncached {
+		if err := d.verifyCachedAgent(); err != nil {
+			log.Errorf("Cached agent failed verification: %v", err)
+			return StatusVerificationFailed
+		}
+	}
 	return status
 }
 
 // IsAgentCached returns true if there is a cached copy of the Agent present
-// and a cache state file is not empty (no validation is performed on the
-// tarball or cache state file contents)
+// that passed verification and a cache state file is not empty (no
+// validation is performed on the cache state file contents)
 func (d *Downloader) IsAgentCached() bool {
 	switch d.AgentCacheStatus() {
-	case StatusUncached:
+	case StatusUncached, StatusVerificationFailed:
 		return false
 	}
 	return true
@@ -207,13 +234,37 @@ func (d *Downloader) getPartitionBucketRegion() string {
 }
 
 // DownloadAgent downloads a copy of the Agent and performs an
-// integrity check of the downloaded image
+// integrity check of the downloaded image. When a signature is required, the
+// image is checked against the published SHA-256 digest and detached signature,
+// and the signature is cached together with the image so that the image can be
+// verified again before it's loaded. The download fails if no signature is
+// published. Otherwise the image is checked against the published MD5 checksum.
 func (d *Downloader) DownloadAgent() error {
 	err := d.fs.MkdirAll(config.CacheDirectory(), os.ModeDir|orwPerm)
 	if err != nil {
 		return err
 	}
 
+	if d.signatureRequired() {
+		tempSignatureFileName, err := d.getPublishedSignature()
+		if err != nil {
+			return err
+		}
+		defer d.removeFile(tempSignatureFileName)
+		return d.downloadSignedAgent(tempSignatureFileName)
+	}
+	return d.downloadAgent()
+}
+
+// signatureRequired returns whether the Agent must be signed, which is the case when signatures are
+// required or when a signing key is configured. Falling back to the MD5 checksum when a key is
+// configured would let an Agent whose signature has been stripped through.
+func (d *Downloader) signatureRequired() bool {
+	return d.requireSignature || d.fileNotEmpty(config.AgentSigningKeyFile())
+}
+
+// downloadAgent downloads the Agent and verifies it against the published MD5 checksum
+func (d *Downloader) downloadAgent() error {
 	publishedMd5Sum, err := d.getPublishedMd5Sum()
 	if err != nil {
 		return err
@@ -223,13 +274,7 @@ func (d *Downloader) DownloadAgent() error {
 	if err != nil {
 		return err
 	}
-
-	defer func() { // clean up temp file
-		if _, err := d.fs.Stat(tempFileName); err == nil { // if temp file exists, remove it
-			log.Debugf("Removing temp file %s", tempFileName)
-			d.fs.Remove(tempFileName)
-		}
-	}()
+	defer d.removeFile(tempFileName)
 
 	publishedTarballReader, err := d.fs.Open(tempFileName)
 	if err != nil {
@@ -255,10 +300,105 @@ func (d *Downloader) DownloadAgent() error {
 		return errors.Errorf("downloaded agent %q does not match expected checksum", agentTarballName)
 	}
 
+	// The signature of a previously cached agent doesn't apply to this one
+	d.removeFile(config.AgentTarballSignature())
+	d.cachedAgentVerified = false
 	log.Debugf("Attempting to rename %s to %s", tempFileName, config.AgentTarball())
 	return d.fs.Rename(tempFileName, config.AgentTarball())
 }
 
+// downloadSignedAgent downloads the Agent and verifies it against the published
+// SHA-256 digest and the given detached signature
+func (d *Downloader) downloadSignedAgent(tempSignatureFileName string) error {
+	publishedSHA256Sum, err := d.getPublishedSHA256Sum()
+	if err != nil {
+		return err
+	}
+
+	tempFileName, err := d.getPublishedTarball()
+	if err != nil {
+		return err
+	}
+	defer d.removeFile(tempFileName)
+
+	calculatedSHA256Sum, err := d.fileDigest(tempFileName)
+	if err != nil {
+		return err
+	}
+
+	calculatedSHA256SumString := hex.EncodeToString(calculatedSHA256Sum)
+	log.Debugf("Expected SHA-256 %q", publishedSHA256Sum)
+	log.Debugf("Calculated SHA-256 %q", calculatedSHA256SumString)
+	agentTarballName, err := config.AgentRemoteTarballKey()
+	if err != nil {
+		return err
+	}
+	if publishedSHA256Sum != calculatedSHA256SumString {
+		return errors.Errorf("downloaded agent %q does not match expected checksum", agentTarballName)
+	}
+
+	err = d.verifier.verify(calculatedSHA256Sum, tempSignatureFileName)
+	if err != nil {
+		return errors.Wrapf(err, "downloaded agent %q failed signature verification", agentTarballName)
+	}
+
+	log.Debugf("Attempting to rename %s to %s", tempFileName, config.AgentTarball())
+	err = d.fs.Rename(tempFileName, config.AgentTarball())
+	if err != nil {
+		return err
+	}
+	log.Debugf("Attempting to rename %s to %s", tempSignatureFileName, config.AgentTarballSignature())
+	err = d.fs.Rename(tempSignatureFileName, config.AgentTarballSignature())
+	if err != nil {
+		return err
+	}
+	d.cachedAgentVerified = true
+	return nil
+}
+
+// removeFile removes the file if it exists
+func (d *Downloader) removeFile(fileName string) {
+	if _, err := d.fs.Stat(fileName); err == nil {
+		log.Debugf("Removing file %s", fileName)
+		d.fs.Remove(fileName)
+	}
+}
+
+// fileDigest returns the SHA-256 digest of the file
+func (d *Downloader) fileDigest(fileName string) ([]byte, error) {
+	file, err := d.fs.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = d.fs.Copy(hash, file)
+	if err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+// verifyCachedAgent verifies the signature of the cached Agent when a signature
+// is required. An Agent cached without a signature, such as the one installed
+// by the package, then fails verification.
+func (d *Downloader) verifyCachedAgent() error {
+	if d.cachedAgentVerified || !d.signatureRequired() {
+		return nil
+	}
+	digest, err := d.fileDigest(config.AgentTarball())
+	if err != nil {
+		return errors.Wrap(err, "failed to compute digest of cached agent")
+	}
+	err = d.verifier.verify(digest, config.AgentTarballSignature())
+	if err != nil {
+		return err
+	}
+	d.cachedAgentVerified = true
+	return nil
+}
+
 func (d *Downloader) getPublishedMd5Sum() (string, error) {
 	objectKey, err := config.AgentRemoteTarballMD5Key()
 	if err != nil {
@@ -286,6 +426,51 @@ func (d *Downloader) getPublishedMd5Sum() (string, error) {
 	return strings.TrimSpace(string(body)), nil
 }
 
+func (d *Downloader) getPublishedSHA256Sum() (string, error) {
+	objectKey, err := config.AgentRemoteTarballSHA256Key()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to determine sha256 file for download")
+	}
+	tempSHA256FileName, err := d.s3Downloader.downloadFile(objectKey)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to download sha256 file for published tarball")
+	}
+
+	tempSHA256File, err := d.fs.Open(tempSHA256FileName)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open temporary sha256 file")
+	}
+	defer func() { // clean up temp file
+		log.Debugf("Removing temp file %s", tempSHA256FileName)
+		d.fs.Remove(tempSHA256FileName)
+	}()
+
+	body, err := d.fs.ReadAll(tempSHA256File)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read from temporary sha256 file")
+	}
+
+	// The file may be in the format of sha256sum, with the name of the tarball following the digest
+	fields := strings.Fields(string(body))
+	if len(fields) == 0 {
+		return "", errors.New("published sha256 file is empty")
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+func (d *Downloader) getPublishedSignature() (string, error) {
+	objectKey, err := config.AgentRemoteTarballSignatureKey()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to determine signature file for download")
+	}
+	tempSignatureFileName, err := d.s3Downloader.downloadFile(objectKey)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to download signature file for published tarball")
+	}
+
+	return tempSignatureFileName, nil
+}
+
 func (d *Downloader) getPublishedTarball() (string, error) {
 	objectKey, err := config.AgentRemoteTarballKey()
 	if err != nil {
@@ -299,8 +484,13 @@ func (d *Downloader) getPublishedTarball() (string, error) {
 	return tempAgentFileName, nil
 }
 
-// LoadCachedAgent returns an io.ReadCloser of the Agent from the cache
+// LoadCachedAgent returns an io.ReadCloser of the Agent from the cache. The
+// Agent is not loaded if its signature cannot be verified.
 func (d *Downloader) LoadCachedAgent() (io.ReadCloser, error) {
+	err := d.verifyCachedAgent()
+	if err != nil {
+		return nil, errors.Wrap(err, "cached agent failed verification")
+	}
 	return d.fs.Open(config.AgentTarball())
 }
 
@@ -19,6 +19,7 @@ package cache
 import (
 	"bytes"
 	"crypto/md5"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
@@ -32,8 +33,10 @@ import (
 )
 
 var (
-	remoteTarballKey    string
-	remoteTarballMD5Key string
+	remoteTarballKey          string
+	remoteTarballMD5Key       string
+	remoteTarballSHA256Key    string
+	remoteTarballSignatureKey string
 )
 
 func init() {
@@ -44,11 +47,25 @@ func init() {
 	if err == nil {
 		remoteTarballKey = agentS3Key
 		remoteTarballMD5Key, _ = config.AgentRemoteTarballMD5Key()
+		remoteTarballSHA256Key, _ = config.AgentRemoteTarballSHA256Key()
+		remoteTarballSignatureKey, _ = config.AgentRemoteTarballSignatureKey()
 	} else {
 		log.Println("Warning: this architecture does not support downloading of agent")
 	}
 }
 
+// expectCachedAgentVerification sets up the expectations of the verification
+// of the cached agent tarball
+func expectCachedAgentVerification(mockFS *MockfileSystem, mockVerifier *MockagentVerifier, verifyErr error) {
+	tarballContents := "tarball contents"
+	tarballReader := io.NopCloser(bytes.NewBufferString(tarballContents))
+	digest := sha256.Sum256([]byte(tarballContents))
+
+	mockFS.EXPECT().Open(config.AgentTarball()).Return(tarballReader, nil)
+	mockFS.EXPECT().Copy(gomock.Any(), tarballReader).DoAndReturn(io.Copy)
+	mockVerifier.EXPECT().verify(digest[:], config.AgentTarballSignature()).Return(verifyErr)
+}
+
 func TestIsAgentCachedFalseMissingState(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -104,49 +121,84 @@ func TestIsAgentCachedTrue(t *testing.T) {
 	file := io.NopCloser(bytes.NewBufferString(fmt.Sprintf("%d", StatusCached)))
 	mockFS := NewMockfileSystem(mockCtrl)
 	mockFSInfo := NewMockfileSizeInfo(mockCtrl)
+	mockVerifier := NewMockagentVerifier(mockCtrl)
 	mockFS.EXPECT().Stat(config.CacheState()).Return(mockFSInfo, nil)
 	mockFS.EXPECT().Stat(config.AgentTarball()).Return(mockFSInfo, nil)
 	mockFSInfo.EXPECT().Size().Return(int64(1)).Times(2)
 	mockFS.EXPECT().Open(config.CacheState()).Return(file, nil)
+	expectCachedAgentVerification(mockFS, mockVerifier, nil)
 
 	d := &Downloader{
-		fs: mockFS,
+		fs:               mockFS,
+		verifier:         mockVerifier,
+		requireSignature: true,
 	}
 
 	assert.True(t, d.IsAgentCached(), "expect d.IsAgentCached() to be true")
 }
 
+func TestIsAgentCachedFalseVerificationFailed(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	file := io.NopCloser(bytes.NewBufferString(fmt.Sprintf("%d", StatusCached)))
+	mockFS := NewMockfileSystem(mockCtrl)
+	mockFSInfo := NewMockfileSizeInfo(mockCtrl)
+	mockVerifier := NewMockagentVerifier(mockCtrl)
+	mockFS.EXPECT().Stat(config.CacheState()).Return(mockFSInfo, nil)
+	mockFS.EXPECT().Stat(config.AgentTarball()).Return(mockFSInfo, nil)
+	mockFSInfo.EXPECT().Size().Return(int64(1)).Times(2)
+	mockFS.EXPECT().Open(config.CacheState()).Return(file, nil)
+	expectCachedAgentVerification(mockFS, mockVerifier, errors.New("test error"))
+
+	d := &Downloader{
+		fs:               mockFS,
+		verifier:         mockVerifier,
+		requireSignature: true,
+	}
+
+	assert.False(t, d.IsAgentCached(), "expect d.IsAgentCached() to be false")
+}
+
 func TestAgentCacheStatus(t *testing.T) {
 	var cases = []struct {
-		data     string
-		expected CacheStatus
+		data      string
+		verifyErr error
+		expected  CacheStatus
 	}{
 		// Expected states:
-		{"0", StatusUncached},
-		{"1", StatusCached},
-		{"2", StatusReloadNeeded},
-		{"1\n", StatusCached},
+		{"0", nil, StatusUncached},
+		{"1", nil, StatusCached},
+		{"2", nil, StatusReloadNeeded},
+		{"1\n", nil, StatusCached},
+		// Verification failures:
+		{"1", errors.New("test error"), StatusVerificationFailed},
+		{"2", errors.New("test error"), StatusVerificationFailed},
 		// Invalid states:
-		{"spurious", StatusUncached},
-		{" ", StatusUncached},
-		{"256", StatusUncached},
+		{"spurious", nil, StatusUncached},
+		{" ", nil, StatusUncached},
+		{"256", nil, StatusUncached},
 	}
 
 	for _, testcase := range cases {
-		t.Run(string(testcase.data), func(t *testing.T) {
+		t.Run(fmt.Sprintf("%s %v", testcase.data, testcase.verifyErr), func(t *testing.T) {
 			mockCtrl := gomock.NewController(t)
 			defer mockCtrl.Finish()
 
 			file := io.NopCloser(bytes.NewBufferString(testcase.data))
 			mockFS := NewMockfileSystem(mockCtrl)
 			mockFSInfo := NewMockfileSizeInfo(mockCtrl)
+			mockVerifier := NewMockagentVerifier(mockCtrl)
 
 			mockFS.EXPECT().Stat(config.CacheState()).Return(mockFSInfo, nil)
 			mockFS.EXPECT().Stat(config.AgentTarball()).Return(mockFSInfo, nil)
 			mockFSInfo.EXPECT().Size().Return(int64(1)).Times(2)
 			mockFS.EXPECT().Open(config.CacheState()).Return(file, nil)
+			if testcase.expected != StatusUncached {
+				expectCachedAgentVerification(mockFS, mockVerifier, testcase.verifyErr)
+			}
 
-			d := &Downloader{fs: mockFS}
+			d := &Downloader{fs: mockFS, verifier: mockVerifier, requireSignature: true}
 
 			actual := d.AgentCacheStatus()
 			assert.Equal(t, testcase.expected, actual, "expected output %d to match %d for input %s", actual, testcase.expected, testcase.data)
@@ -154,6 +206,71 @@ func TestAgentCacheStatus(t *testing.T) {
 	}
 }
 
+func TestAgentCacheStatusUnsignedAgent(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	file := io.NopCloser(bytes.NewBufferString(fmt.Sprintf("%d", StatusCached)))
+	mockFS := NewMockfileSystem(mockCtrl)
+	mockFSInfo := NewMockfileSizeInfo(mockCtrl)
+	mockVerifier := NewMockagentVerifier(mockCtrl)
+	mockFS.EXPECT().Stat(config.CacheState()).Return(mockFSInfo, nil)
+	mockFS.EXPECT().Stat(config.AgentTarball()).Return(mockFSInfo, nil)
+	mockFSInfo.EXPECT().Size().Return(int64(1)).Times(2)
+	mockFS.EXPECT().Open(config.CacheState()).Return(file, nil)
+	// The agent installed by the package has no signature, and no signing key is configured
+	mockFS.EXPECT().Stat(config.AgentSigningKeyFile()).Return(nil, errors.New("test error"))
+
+	d := &Downloader{fs: mockFS, verifier: mockVerifier}
+
+	assert.Equal(t, StatusCached, d.AgentCacheStatus())
+}
+
+func TestAgentCacheStatusUnsignedAgentSigningKeyConfigured(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	file := io.NopCloser(bytes.NewBufferString(fmt.Sprintf("%d", StatusCached)))
+	mockFS := NewMockfileSystem(mockCtrl)
+	mockFSInfo := NewMockfileSizeInfo(mockCtrl)
+	mockVerifier := NewMockagentVerifier(mockCtrl)
+	mockFS.EXPECT().Stat(config.CacheState()).Return(mockFSInfo, nil)
+	mockFS.EXPECT().Stat(config.AgentTarball()).Return(mockFSInfo, nil)
+	mockFS.EXPECT().Stat(config.AgentSigningKeyFile()).Return(mockFSInfo, nil)
+	mockFSInfo.EXPECT().Size().Return(int64(1)).Times(3)
+	mockFS.EXPECT().Open(config.CacheState()).Return(file, nil)
+	// The signature of the cached agent is missing, so it can't be verified
+	expectCachedAgentVerification(mockFS, mockVerifier, errors.New("signature not found"))
+
+	d := &Downloader{fs: mockFS, verifier: mockVerifier}
+
+	assert.Equal(t, StatusVerificationFailed, d.AgentCacheStatus())
+}
+
+func TestAgentCacheStatusVerifiesOnce(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	file := io.NopCloser(bytes.NewBufferString(fmt.Sprintf("%d", StatusCached)))
+	mockFS := NewMockfileSystem(mockCtrl)
+	mockFSInfo := NewMockfileSizeInfo(mockCtrl)
+	mockVerifier := NewMockagentVerifier(mockCtrl)
+	cachedAgent := io.NopCloser(&bytes.Buffer{})
+	mockFS.EXPECT().Stat(config.CacheState()).Return(mockFSInfo, nil)
+	mockFS.EXPECT().Stat(config.AgentTarball()).Return(mockFSInfo, nil)
+	mockFSInfo.EXPECT().Size().Return(int64(1)).Times(2)
+	mockFS.EXPECT().Open(config.CacheState()).Return(file, nil)
+	expectCachedAgentVerification(mockFS, mockVerifier, nil)
+	mockFS.EXPECT().Open(config.AgentTarball()).Return(cachedAgent, nil)
+
+	d := &Downloader{fs: mockFS, verifier: mockVerifier, requireSignature: true}
+
+	assert.Equal(t, StatusCached, d.AgentCacheStatus())
+	agent, err := d.LoadCachedAgent()
+	assert.NoError(t, err)
+	assert.Equal(t, cachedAgent, agent)
+}
+
 func TestGetPartitionBucketRegion(t *testing.T) {
 	d := &Downloader{}
 
@@ -205,6 +322,7 @@ func TestDownloadAgentDownloadMD5Failure(t *testing.T) {
 
 	gomock.InOrder(
 		mockFS.EXPECT().MkdirAll(config.CacheDirectory(), os.ModeDir|0700),
+		mockFS.EXPECT().Stat(config.AgentSigningKeyFile()).Return(nil, errors.New("test error")),
 		mockS3Downloader.EXPECT().downloadFile(remoteTarballMD5Key).Return("", errors.New("test error")),
 	)
 
@@ -232,6 +350,7 @@ func TestDownloadAgentReadPublishedMd5Failure(t *testing.T) {
 
 	gomock.InOrder(
 		mockFS.EXPECT().MkdirAll(config.CacheDirectory(), os.ModeDir|0700),
+		mockFS.EXPECT().Stat(config.AgentSigningKeyFile()).Return(nil, errors.New("test error")),
 		mockS3Downloader.EXPECT().downloadFile(remoteTarballMD5Key).Return(tempMD5File.Name(), nil),
 		mockFS.EXPECT().Open(tempMD5File.Name()).Return(tempMD5File, nil),
 		mockFS.EXPECT().ReadAll(tempMD5File).Return(nil, errors.New("test error")),
@@ -268,6 +387,7 @@ func TestDownloadAgentDownloadTarballFailure(t *testing.T) {
 
 	gomock.InOrder(
 		mockFS.EXPECT().MkdirAll(config.CacheDirectory(), os.ModeDir|0700),
+		mockFS.EXPECT().Stat(config.AgentSigningKeyFile()).Return(nil, errors.New("test error")),
 		mockS3Downloader.EXPECT().downloadFile(remoteTarballMD5Key).Return(tempMD5File.Name(), nil),
 		mockFS.EXPECT().Open(tempMD5File.Name()).Return(tempMD5File, nil),
 		mockFS.EXPECT().ReadAll(tempMD5File).Return([]byte(md5sum), nil),
@@ -307,6 +427,7 @@ func TestDownloadAgentCopyFailure(t *testing.T) {
 
 	gomock.InOrder(
 		mockFS.EXPECT().MkdirAll(config.CacheDirectory(), os.ModeDir|0700),
+		mockFS.EXPECT().Stat(config.AgentSigningKeyFile()).Return(nil, errors.New("test error")),
 		mockS3Downloader.EXPECT().downloadFile(remoteTarballMD5Key).Return(tempMD5File.Name(), nil),
 		mockFS.EXPECT().Open(tempMD5File.Name()).Return(tempMD5File, nil),
 		mockFS.EXPECT().ReadAll(tempMD5File).Return([]byte(md5sum), nil),
@@ -350,6 +471,7 @@ func TestDownloadAgentMD5Mismatch(t *testing.T) {
 
 	gomock.InOrder(
 		mockFS.EXPECT().MkdirAll(config.CacheDirectory(), os.ModeDir|0700),
+		mockFS.EXPECT().Stat(config.AgentSigningKeyFile()).Return(nil, errors.New("test error")),
 		mockS3Downloader.EXPECT().downloadFile(remoteTarballMD5Key).Return(tempMD5File.Name(), nil),
 		mockFS.EXPECT().Open(tempMD5File.Name()).Return(tempMD5File, nil),
 		mockFS.EXPECT().ReadAll(tempMD5File).Return([]byte(md5sum), nil),
@@ -393,6 +515,7 @@ func TestDownloadAgentSuccess(t *testing.T) {
 
 	gomock.InOrder(
 		mockFS.EXPECT().MkdirAll(config.CacheDirectory(), os.ModeDir|0700),
+		mockFS.EXPECT().Stat(config.AgentSigningKeyFile()).Return(nil, errors.New("test error")),
 		mockS3Downloader.EXPECT().downloadFile(remoteTarballMD5Key).Return(tempMD5File.Name(), nil),
 		mockFS.EXPECT().Open(tempMD5File.Name()).Return(tempMD5File, nil),
 		mockFS.EXPECT().ReadAll(tempMD5File).Return([]byte(expectedMd5Sum), nil),
@@ -403,6 +526,9 @@ func TestDownloadAgentSuccess(t *testing.T) {
 			_, err = io.Copy(writer, reader)
 			assert.NoError(t, err, "Expect to successfully write to file")
 		}),
+		// The signature of the previously cached agent is removed
+		mockFS.EXPECT().Stat(config.AgentTarballSignature()).Return(nil, nil),
+		mockFS.EXPECT().Remove(config.AgentTarballSignature()),
 		mockFS.EXPECT().Rename(tempAgentFile.Name(), config.AgentTarball()),
 		mockFS.EXPECT().Stat(tempAgentFile.Name()).Return(nil, errors.New("temp file has been renamed")),
 	)
@@ -414,7 +540,303 @@ func TestDownloadAgentSuccess(t *testing.T) {
 		region:       config.DefaultRegionName,
 	}
 
-	d.DownloadAgent()
+	assert.NoError(t, d.DownloadAgent())
+}
+
+func TestDownloadAgentSigningKeyConfiguredSignatureNotPublished(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFS := NewMockfileSystem(mockCtrl)
+	mockFSInfo := NewMockfileSizeInfo(mockCtrl)
+	mockS3Downloader := NewMocks3DownloaderAPI(mockCtrl)
+	mockMetadata := NewMockinstanceMetadata(mockCtrl)
+
+	gomock.InOrder(
+		mockFS.EXPECT().MkdirAll(config.CacheDirectory(), os.ModeDir|0700),
+		mockFS.EXPECT().Stat(config.AgentSigningKeyFile()).Return(mockFSInfo, nil),
+		mockFSInfo.EXPECT().Size().Return(int64(1)),
+		// The agent isn't verified against its MD5 checksum instead
+		mockS3Downloader.EXPECT().downloadFile(remoteTarballSignatureKey).Return("", errors.New("test error")),
+	)
+
+	d := &Downloader{
+		s3Downloader: mockS3Downloader,
+		fs:           mockFS,
+		metadata:     mockMetadata,
+		region:       config.DefaultRegionName,
+	}
+
+	assert.Error(t, d.DownloadAgent(), "Expect an error when a signing key is configured and no signature is published")
+}
+
+func TestDownloadAgentSignatureRequiredNotPublished(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFS := NewMockfileSystem(mockCtrl)
+	mockS3Downloader := NewMocks3DownloaderAPI(mockCtrl)
+	mockMetadata := NewMockinstanceMetadata(mockCtrl)
+
+	gomock.InOrder(
+		mockFS.EXPECT().MkdirAll(config.CacheDirectory(), os.ModeDir|0700),
+		mockS3Downloader.EXPECT().downloadFile(remoteTarballSignatureKey).Return("", errors.New("test error")),
+	)
+
+	d := &Downloader{
+		s3Downloader:     mockS3Downloader,
+		fs:               mockFS,
+		metadata:         mockMetadata,
+		region:           config.DefaultRegionName,
+		requireSignature: true,
+	}
+
+	assert.Error(t, d.DownloadAgent(), "Expect an error when a required signature is not published")
+}
+
+// expectPublishedSignatureDownload sets up the expectations of the download of
+// the published signature, when signatures are required
+func expectPublishedSignatureDownload(mockFS *MockfileSystem, mockS3Downloader *Mocks3DownloaderAPI,
+	tempSignatureFile *os.File) {
+	gomock.InOrder(
+		mockFS.EXPECT().MkdirAll(config.CacheDirectory(), os.ModeDir|0700),
+		mockS3Downloader.EXPECT().downloadFile(remoteTarballSignatureKey).Return(tempSignatureFile.Name(), nil),
+	)
+}
+
+func TestDownloadAgentDownloadSHA256Failure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFS := NewMockfileSystem(mockCtrl)
+	mockS3Downloader := NewMocks3DownloaderAPI(mockCtrl)
+	mockMetadata := NewMockinstanceMetadata(mockCtrl)
+
+	tempSignatureFile, err := os.CreateTemp("", "signature-test")
+	assert.NoError(t, err, "Expect to successfully create a temporary file")
+	defer tempSignatureFile.Close()
+
+	expectPublishedSignatureDownload(mockFS, mockS3Downloader, tempSignatureFile)
+	gomock.InOrder(
+		mockS3Downloader.EXPECT().downloadFile(remoteTarballSHA256Key).Return("", errors.New("test error")),
+		mockFS.EXPECT().Stat(tempSignatureFile.Name()).Return(nil, nil),
+		mockFS.EXPECT().Remove(tempSignatureFile.Name()),
+	)
+
+	d := &Downloader{
+		s3Downloader:     mockS3Downloader,
+		fs:               mockFS,
+		metadata:         mockMetadata,
+		region:           config.DefaultRegionName,
+		requireSignature: true,
+	}
+
+	assert.Error(t, d.DownloadAgent())
+}
+
+func TestDownloadAgentSHA256Mismatch(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sha256sum := "sha256sum"
+
+	mockFS := NewMockfileSystem(mockCtrl)
+	mockS3Downloader := NewMocks3DownloaderAPI(mockCtrl)
+	mockMetadata := NewMockinstanceMetadata(mockCtrl)
+
+	tempSignatureFile, err := os.CreateTemp("", "signature-test")
+	assert.NoError(t, err, "Expect to successfully create a temporary file")
+	defer tempSignatureFile.Close()
+
+	tempSHA256File, err := os.CreateTemp("", "sha256-test")
+	assert.NoError(t, err, "Expect to successfully create a temporary file")
+	defer tempSHA256File.Close()
+
+	tempAgentFile, err := os.CreateTemp("", "agent-test")
+	assert.NoError(t, err, "Expect to successfully create a temporary file")
+	defer tempAgentFile.Close()
+
+	tempReader := io.NopCloser(&bytes.Buffer{})
+
+	expectPublishedSignatureDownload(mockFS, mockS3Downloader, tempSignatureFile)
+	gomock.InOrder(
+		mockS3Downloader.EXPECT().downloadFile(remoteTarballSHA256Key).Return(tempSHA256File.Name(), nil),
+		mockFS.EXPECT().Open(tempSHA256File.Name()).Return(tempSHA256File, nil),
+		mockFS.EXPECT().ReadAll(tempSHA256File).Return([]byte(sha256sum), nil),
+		mockFS.EXPECT().Remove(tempSHA256File.Name()),
+		mockS3Downloader.EXPECT().downloadFile(remoteTarballKey).Return(tempAgentFile.Name(), nil),
+		mockFS.EXPECT().Open(tempAgentFile.Name()).Return(tempReader, nil),
+		mockFS.EXPECT().Copy(gomock.Any(), tempReader).Return(int64(0), nil),
+		mockFS.EXPECT().Stat(tempAgentFile.Name()).Return(nil, nil),
+		mockFS.EXPECT().Remove(tempAgentFile.Name()),
+		mockFS.EXPECT().Stat(tempSignatureFile.Name()).Return(nil, nil),
+		mockFS.EXPECT().Remove(tempSignatureFile.Name()),
+	)
+
+	d := &Downloader{
+		s3Downloader:     mockS3Downloader,
+		fs:               mockFS,
+		metadata:         mockMetadata,
+		region:           config.DefaultRegionName,
+		requireSignature: true,
+	}
+
+	assert.Error(t, d.DownloadAgent(), "Expect an error when the digest does not match")
+}
+
+// expectPublishedTarballDownload sets up the expectations of the download of
+// the published signature, sha256 file and tarball, and returns the digest of
+// the tarball
+func expectPublishedTarballDownload(t *testing.T, mockFS *MockfileSystem, mockS3Downloader *Mocks3DownloaderAPI,
+	tempSignatureFile, tempSHA256File, tempAgentFile *os.File) []byte {
+	tarballContents := "tarball contents"
+	tarballReader := io.NopCloser(bytes.NewBufferString(tarballContents))
+	digest := sha256.Sum256([]byte(tarballContents))
+	publishedSHA256Sum := fmt.Sprintf("%x  %s\n", digest, remoteTarballKey)
+
+	expectPublishedSignatureDownload(mockFS, mockS3Downloader, tempSignatureFile)
+	gomock.InOrder(
+		mockS3Downloader.EXPECT().downloadFile(remoteTarballSHA256Key).Return(tempSHA256File.Name(), nil),
+		mockFS.EXPECT().Open(tempSHA256File.Name()).Return(tempSHA256File, nil),
+		mockFS.EXPECT().ReadAll(tempSHA256File).Return([]byte(publishedSHA256Sum), nil),
+		mockFS.EXPECT().Remove(tempSHA256File.Name()),
+		mockS3Downloader.EXPECT().downloadFile(remoteTarballKey).Return(tempAgentFile.Name(), nil),
+		mockFS.EXPECT().Open(tempAgentFile.Name()).Return(tarballReader, nil),
+		mockFS.EXPECT().Copy(gomock.Any(), tarballReader).Do(func(writer io.Writer, reader io.Reader) {
+			_, err := io.Copy(writer, reader)
+			assert.NoError(t, err, "Expect to successfully write to file")
+		}),
+	)
+	return digest[:]
+}
+
+func TestDownloadAgentSignatureVerificationFailure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	tempSHA256File, err := os.CreateTemp("", "sha256-test")
+	assert.NoError(t, err, "Expect to successfully create a temporary file")
+	defer tempSHA256File.Close()
+
+	tempAgentFile, err := os.CreateTemp("", "agent-test")
+	assert.NoError(t, err, "Expect to successfully create a temporary file")
+	defer tempAgentFile.Close()
+
+	tempSignatureFile, err := os.CreateTemp("", "signature-test")
+	assert.NoError(t, err, "Expect to successfully create a temporary file")
+	defer tempSignatureFile.Close()
+
+	mockFS := NewMockfileSystem(mockCtrl)
+	mockS3Downloader := NewMocks3DownloaderAPI(mockCtrl)
+	mockMetadata := NewMockinstanceMetadata(mockCtrl)
+	mockVerifier := NewMockagentVerifier(mockCtrl)
+
+	digest := expectPublishedTarballDownload(t, mockFS, mockS3Downloader, tempSignatureFile, tempSHA256File,
+		tempAgentFile)
+	gomock.InOrder(
+		mockVerifier.EXPECT().verify(digest, tempSignatureFile.Name()).Return(errors.New("test error")),
+		mockFS.EXPECT().Stat(tempAgentFile.Name()).Return(nil, nil),
+		mockFS.EXPECT().Remove(tempAgentFile.Name()),
+		mockFS.EXPECT().Stat(tempSignatureFile.Name()).Return(nil, nil),
+		mockFS.EXPECT().Remove(tempSignatureFile.Name()),
+	)
+
+	d := &Downloader{
+		s3Downloader:     mockS3Downloader,
+		fs:               mockFS,
+		metadata:         mockMetadata,
+		verifier:         mockVerifier,
+		region:           config.DefaultRegionName,
+		requireSignature: true,
+	}
+
+	assert.Error(t, d.DownloadAgent(), "Expect an error when the signature cannot be verified")
+}
+
+func TestDownloadSignedAgentSuccess(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	tempSHA256File, err := os.CreateTemp("", "sha256-test")
+	assert.NoError(t, err, "Expect to successfully create a temporary file")
+	defer tempSHA256File.Close()
+
+	tempAgentFile, err := os.CreateTemp("", "agent-test")
+	assert.NoError(t, err, "Expect to successfully create a temporary file")
+	defer tempAgentFile.Close()
+
+	tempSignatureFile, err := os.CreateTemp("", "signature-test")
+	assert.NoError(t, err, "Expect to successfully create a temporary file")
+	defer tempSignatureFile.Close()
+
+	mockFS := NewMockfileSystem(mockCtrl)
+	mockS3Downloader := NewMocks3DownloaderAPI(mockCtrl)
+	mockMetadata := NewMockinstanceMetadata(mockCtrl)
+	mockVerifier := NewMockagentVerifier(mockCtrl)
+
+	digest := expectPublishedTarballDownload(t, mockFS, mockS3Downloader, tempSignatureFile, tempSHA256File,
+		tempAgentFile)
+	gomock.InOrder(
+		mockVerifier.EXPECT().verify(digest, tempSignatureFile.Name()).Return(nil),
+		mockFS.EXPECT().Rename(tempAgentFile.Name(), config.AgentTarball()),
+		mockFS.EXPECT().Rename(tempSignatureFile.Name(), config.AgentTarballSignature()),
+		mockFS.EXPECT().Stat(tempAgentFile.Name()).Return(nil, errors.New("temp file has been renamed")),
+		mockFS.EXPECT().Stat(tempSignatureFile.Name()).Return(nil, errors.New("temp file has been renamed")),
+	)
+
+	d := &Downloader{
+		s3Downloader:     mockS3Downloader,
+		fs:               mockFS,
+		metadata:         mockMetadata,
+		verifier:         mockVerifier,
+		region:           config.DefaultRegionName,
+		requireSignature: true,
+	}
+
+	assert.NoError(t, d.DownloadAgent())
+	// The downloaded agent has been verified and is not hashed again
+	assert.NoError(t, d.verifyCachedAgent())
+}
+
+func TestLoadCachedAgent(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFS := NewMockfileSystem(mockCtrl)
+	mockVerifier := NewMockagentVerifier(mockCtrl)
+	cachedAgent := io.NopCloser(&bytes.Buffer{})
+
+	expectCachedAgentVerification(mockFS, mockVerifier, nil)
+	mockFS.EXPECT().Open(config.AgentTarball()).Return(cachedAgent, nil)
+
+	d := &Downloader{
+		fs:               mockFS,
+		verifier:         mockVerifier,
+		requireSignature: true,
+	}
+
+	agent, err := d.LoadCachedAgent()
+	assert.NoError(t, err)
+	assert.Equal(t, cachedAgent, agent)
+}
+
+func TestLoadCachedAgentVerificationFailure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFS := NewMockfileSystem(mockCtrl)
+	mockVerifier := NewMockagentVerifier(mockCtrl)
+
+	expectCachedAgentVerification(mockFS, mockVerifier, errors.New("test error"))
+
+	d := &Downloader{
+		fs:               mockFS,
+		verifier:         mockVerifier,
+		requireSignature: true,
+	}
+
+	_, err := d.LoadCachedAgent()
+	assert.Error(t, err, "Expect the cached agent not to be loaded when it fails verification")
 }
 
 func TestLoadDesiredAgentFailOpenDesired(t *testing.T) {
@@ -144,6 +144,11 @@ type instanceMetadata interface {
 	GetRegion(ctx context.Context, input *imds.GetRegionInput, opts ...func(*imds.Options)) (*imds.GetRegionOutput, error)
 }
 
+// agentVerifier verifies the detached signature of the agent tarball
+type agentVerifier interface {
+	verify(digest []byte, signatureFile string) error
+}
+
 type blackholeInstanceMetadata struct {
 }
 
@@ -381,3 +381,40 @@ func (mr *MockinstanceMetadataMockRecorder) GetRegion(ctx, input interface{}, op
 	varargs := append([]interface{}{ctx, input}, opts...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRegion", reflect.TypeOf((*MockinstanceMetadata)(nil).GetRegion), varargs...)
 }
+
+// MockagentVerifier is a mock of agentVerifier interface.
+type MockagentVerifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockagentVerifierMockRecorder
+}
+
+// MockagentVerifierMockRecorder is the mock recorder for MockagentVerifier.
+type MockagentVerifierMockRecorder struct {
+	mock *MockagentVerifier
+}
+
+// NewMockagentVerifier creates a new mock instance.
+func NewMockagentVerifier(ctrl *gomock.Controller) *MockagentVerifier {
+	mock := &MockagentVerifier{ctrl: ctrl}
+	mock.recorder = &MockagentVerifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockagentVerifier) EXPECT() *MockagentVerifierMockRecorder {
+	return m.recorder
+}
+
+// verify mocks base method.
+func (m *MockagentVerifier) verify(digest []byte, signatureFile string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "verify", digest, signatureFile)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// verify indicates an expected call of verify.
+func (mr *MockagentVerifierMockRecorder) verify(digest, signatureFile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "verify", reflect.TypeOf((*MockagentVerifier)(nil).verify), digest, signatureFile)
+}
@@ -0,0 +1,101 @@
+// Copyright 2015 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cache
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// publicKeyVerifier verifies detached signatures of the agent tarball with a
+// PEM encoded public key. The signature file holds the base64 encoded
+// signature of the SHA-256 digest of the tarball: an RSA PKCS #1 v1.5
+// signature, an ASN.1 encoded ECDSA signature or an Ed25519 signature of the
+// digest.
+type publicKeyVerifier struct {
+	keyFile string
+	fs      fileSystem
+}
+
+func (v *publicKeyVerifier) verify(digest []byte, signatureFile string) error {
+	publicKey, err := v.readPublicKey()
+	if err != nil {
+		return err
+	}
+	signature, err := v.readSignature(signatureFile)
+	if err != nil {
+		return err
+	}
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, signature)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest, signature) {
+			err = errors.New("ecdsa: verification error")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, digest, signature) {
+			err = errors.New("ed25519: verification error")
+		}
+	default:
+		return errors.Errorf("unsupported signing key type %T in %s", publicKey, v.keyFile)
+	}
+	return errors.Wrapf(err, "signature %s does not match signing key %s", signatureFile, v.keyFile)
+}
+
+func (v *publicKeyVerifier) readPublicKey() (crypto.PublicKey, error) {
+	data, err := v.readFile(v.keyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read signing key")
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.Errorf("signing key %s is not PEM encoded", v.keyFile)
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse signing key %s", v.keyFile)
+	}
+	return publicKey, nil
+}
+
+func (v *publicKeyVerifier) readSignature(signatureFile string) ([]byte, error) {
+	data, err := v.readFile(signatureFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read signature")
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode signature %s", signatureFile)
+	}
+	return signature, nil
+}
+
+func (v *publicKeyVerifier) readFile(name string) ([]byte, error) {
+	file, err := v.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return v.fs.ReadAll(file)
+}
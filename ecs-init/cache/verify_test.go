@@ -0,0 +1,128 @@
+//go:build test
+// +build test
+
+// Copyright 2015 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cache
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSigningKey writes the PEM encoded public key to a file and returns a
+// verifier using it
+func writeSigningKey(t *testing.T, publicKey crypto.PublicKey) *publicKeyVerifier {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "signing-key.pem")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+	return &publicKeyVerifier{keyFile: keyFile, fs: &standardFS{}}
+}
+
+func writeSignature(t *testing.T, signature []byte) string {
+	signatureFile := filepath.Join(t.TempDir(), "ecs-agent.tar.sig")
+	require.NoError(t, os.WriteFile(signatureFile, []byte(base64.StdEncoding.EncodeToString(signature)+"\n"), 0600))
+	return signatureFile
+}
+
+func TestPublicKeyVerifier(t *testing.T) {
+	digest := sha256.Sum256([]byte("tarball contents"))
+	otherDigest := sha256.Sum256([]byte("other tarball contents"))
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaSignature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecdsaSignature, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, digest[:])
+	require.NoError(t, err)
+
+	ed25519PublicKey, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ed25519Signature := ed25519.Sign(ed25519Key, digest[:])
+
+	var cases = []struct {
+		name      string
+		publicKey crypto.PublicKey
+		signature []byte
+	}{
+		{"rsa", &rsaKey.PublicKey, rsaSignature},
+		{"ecdsa", &ecdsaKey.PublicKey, ecdsaSignature},
+		{"ed25519", ed25519PublicKey, ed25519Signature},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			verifier := writeSigningKey(t, c.publicKey)
+			signatureFile := writeSignature(t, c.signature)
+
+			assert.NoError(t, verifier.verify(digest[:], signatureFile))
+			assert.Error(t, verifier.verify(otherDigest[:], signatureFile),
+				"Expect an error when the signature doesn't match the digest")
+		})
+	}
+}
+
+func TestPublicKeyVerifierWrongKey(t *testing.T) {
+	digest := sha256.Sum256([]byte("tarball contents"))
+	_, signingKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	verifier := writeSigningKey(t, otherPublicKey)
+	signatureFile := writeSignature(t, ed25519.Sign(signingKey, digest[:]))
+
+	assert.Error(t, verifier.verify(digest[:], signatureFile))
+}
+
+func TestPublicKeyVerifierMissingFiles(t *testing.T) {
+	digest := sha256.Sum256([]byte("tarball contents"))
+	publicKey, signingKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	verifier := &publicKeyVerifier{keyFile: filepath.Join(t.TempDir(), "missing.pem"), fs: &standardFS{}}
+	signatureFile := writeSignature(t, ed25519.Sign(signingKey, digest[:]))
+	assert.Error(t, verifier.verify(digest[:], signatureFile), "Expect an error when the signing key is missing")
+
+	verifier = writeSigningKey(t, publicKey)
+	assert.Error(t, verifier.verify(digest[:], filepath.Join(t.TempDir(), "missing.sig")),
+		"Expect an error when the signature is missing")
+}
+
+func TestPublicKeyVerifierInvalidSigningKey(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "signing-key.pem")
+	require.NoError(t, os.WriteFile(keyFile, []byte("not a key"), 0600))
+	verifier := &publicKeyVerifier{keyFile: keyFile, fs: &standardFS{}}
+
+	digest := sha256.Sum256([]byte("tarball contents"))
+	assert.Error(t, verifier.verify(digest[:], writeSignature(t, []byte("signature"))))
+}
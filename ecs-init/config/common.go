@@ -31,12 +31,19 @@ const (
 	// AgentImageName is the name of the Docker image containing the Agent
 	AgentImageName = "amazon/amazon-ecs-agent:latest"
 
+	// AgentPreviousImageName is the name of the Docker image containing the Agent
+	// replaced by the last Agent upgrade, kept to roll back a failed upgrade
+	AgentPreviousImageName = "amazon/amazon-ecs-agent:previous"
+
 	// AgentContainerName is the name of the Agent container started by this program
 	AgentContainerName = "ecs-agent"
 
 	// AgentLogFile is the name of the log file used by the Agent
 	AgentLogFile = "ecs-agent.log"
 
+	// VolumePluginExecutable is the path of the Amazon ECS volume plugin installed by the package
+	VolumePluginExecutable = "/usr/libexec/amazon-ecs-volume-plugin"
+
 	UnixSocketPrefix = "unix://"
 
 	// Used to mount /proc for agent container
@@ -99,6 +106,20 @@ const (
 	// CredentialsFetcherHostEnvVar is the environment variable that specifies the location of the credentials-fetcher daemon socket.
 	CredentialsFetcherHostEnvVar = "CREDENTIALS_FETCHER_HOST"
 
+	// RemediationPolicyFileEnvVar is the environment variable for the remediation policy file of the Agent, whose
+	// restart-docker action restarts docker through the D-Bus system bus.
+	RemediationPolicyFileEnvVar = "ECS_REMEDIATION_POLICY_FILE"
+
+	// DrainPolicyFileEnvVar is the environment variable for the drain policy file of the Agent, whose shutdown
+	// trigger checks whether the host is shutting down through the D-Bus system bus, and drains the instance
+	// before the Agent stops.
+	DrainPolicyFileEnvVar = "ECS_DRAIN_POLICY_FILE"
+
+	// StateBackupDirEnvVar is the environment variable for the directory the Agent writes the snapshots of its
+	// state database to. A directory of the host outside the Agent data directory is mounted at the same path,
+	// so that the snapshots can be kept on another volume than the state database.
+	StateBackupDirEnvVar = "ECS_STATE_BACKUP_DIR"
+
 	// this socket is exposed by credentials-fetcher (daemon for gMSA support on Linux)
 	// defaultCredentialsFetcherSocketPath is set to /var/credentials-fetcher/socket/credentials_fetcher.sock
 	// in case path is not passed in the env variable
@@ -108,6 +129,14 @@ const (
 	// on AppArmor-enabled platforms (such as Ubuntu and Debian).
 	ECSAgentAppArmorProfileNameEnvVar  = "ECS_AGENT_APPARMOR_PROFILE"
 	ECSAgentAppArmorDefaultProfileName = "ecs-agent-default"
+
+	// AgentSigningKeyFileEnvVar is the environment variable that may be used to specify the public
+	// key used to verify the signature of the Agent image
+	AgentSigningKeyFileEnvVar = "ECS_AGENT_SIGNING_KEY_FILE"
+
+	// AgentSignatureRequiredEnvVar is the environment variable that may be used to reject Agent
+	// images that are not signed, including the image installed by the package
+	AgentSignatureRequiredEnvVar = "ECS_AGENT_SIGNATURE_REQUIRED"
 )
 
 // OsStat is useful for mocking in unit tests
@@ -217,6 +246,45 @@ func AgentRemoteTarballMD5Key() (string, error) {
 	return tarballKey + ".md5", nil
 }
 
+// AgentTarballSignature returns the location on disk of the detached signature of the cached Agent image
+func AgentTarballSignature() string {
+	return AgentTarball() + ".sig"
+}
+
+// AgentRemoteTarballSHA256Key is the remote file of a sha256sum used to verify the integrity of the AgentRemoteTarball
+func AgentRemoteTarballSHA256Key() (string, error) {
+	tarballKey, err := AgentRemoteTarballKey()
+	if err != nil {
+		return "", err
+	}
+	return tarballKey + ".sha256", nil
+}
+
+// AgentRemoteTarballSignatureKey is the remote file of the detached signature of the AgentRemoteTarball
+func AgentRemoteTarballSignatureKey() (string, error) {
+	tarballKey, err := AgentRemoteTarballKey()
+	if err != nil {
+		return "", err
+	}
+	return tarballKey + ".sig", nil
+}
+
+// AgentSigningKeyFile returns the location on disk of the PEM encoded public key used to verify the
+// signature of the Agent image. No key is shipped with ecs-init: the public key matching the private
+// key the Agent images are signed with is installed by the operator, at this location or at the one
+// set with AgentSigningKeyFileEnvVar. Signatures are required once the key is present.
+func AgentSigningKeyFile() string {
+	if keyFile := os.Getenv(AgentSigningKeyFileEnvVar); keyFile != "" {
+		return keyFile
+	}
+	return AgentConfigDirectory() + "/ecs-agent-signing-key.pem"
+}
+
+// AgentUpgradeStateFile returns the location on disk of the state of the last Agent upgrade
+func AgentUpgradeStateFile() string {
+	return CacheDirectory() + "/upgrade-state.json"
+}
+
 // DesiredImageLocatorFile returns the location on disk of a well-known file describing an Agent image to load
 func DesiredImageLocatorFile() string {
 	return CacheDirectory() + "/desired-image"
@@ -337,6 +405,14 @@ func RunPrivileged() bool {
 	return envVar == "true"
 }
 
+// AgentSignatureRequired returns whether Agent images must be signed even when no signing key is
+// present, which makes ecs-init refuse every Agent image until the key is installed. When it's not set,
+// Agent images must be signed if the signing key is present.
+func AgentSignatureRequired() bool {
+	envVar := os.Getenv(AgentSignatureRequiredEnvVar)
+	return envVar == "true"
+}
+
 // RunningInExternal returns whether we are running in external (non-EC2) environment.
 func RunningInExternal() bool {
 	envVar := os.Getenv(ExternalEnvVar)
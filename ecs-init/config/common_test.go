@@ -60,6 +60,22 @@ func TestECSAgentAppArmorProfileName(t *testing.T) {
 	assert.Equal(t, profile, "docker-default")
 }
 
+func TestAgentSigningKeyFile(t *testing.T) {
+	assert.Equal(t, AgentConfigDirectory()+"/ecs-agent-signing-key.pem", AgentSigningKeyFile())
+
+	os.Setenv(AgentSigningKeyFileEnvVar, "/etc/pki/ecs-agent.pem")
+	defer os.Unsetenv(AgentSigningKeyFileEnvVar)
+	assert.Equal(t, "/etc/pki/ecs-agent.pem", AgentSigningKeyFile())
+}
+
+func TestAgentSignatureRequired(t *testing.T) {
+	assert.False(t, AgentSignatureRequired())
+
+	os.Setenv(AgentSignatureRequiredEnvVar, "true")
+	defer os.Unsetenv(AgentSignatureRequiredEnvVar)
+	assert.True(t, AgentSignatureRequired())
+}
+
 func TestGetAgentPartitionBucketRegion(t *testing.T) {
 	testCases := []struct {
 		region      string
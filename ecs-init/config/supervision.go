@@ -0,0 +1,172 @@
+// Copyright 2015 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cihub/seelog"
+)
+
+const (
+	// agentMaxRestartsEnvVar is the environment variable that may be used to
+	// limit the number of restarts of the Agent within the restart window.
+	// The Agent is restarted without limit if it's unset or 0.
+	agentMaxRestartsEnvVar = "ECS_INIT_AGENT_MAX_RESTARTS"
+	// agentRestartWindowEnvVar is the environment variable that may be used
+	// to specify the window over which restarts of the Agent are counted
+	agentRestartWindowEnvVar = "ECS_INIT_AGENT_RESTART_WINDOW"
+	// agentRestartMinBackoffEnvVar and agentRestartMaxBackoffEnvVar are the
+	// environment variables that may be used to specify the range of the
+	// delay before the Agent is restarted
+	agentRestartMinBackoffEnvVar = "ECS_INIT_AGENT_RESTART_MIN_BACKOFF"
+	agentRestartMaxBackoffEnvVar = "ECS_INIT_AGENT_RESTART_MAX_BACKOFF"
+	// agentRestartBackoffMultiplierEnvVar is the environment variable that
+	// may be used to specify the factor by which the delay before the Agent
+	// is restarted increases after each restart
+	agentRestartBackoffMultiplierEnvVar = "ECS_INIT_AGENT_RESTART_BACKOFF_MULTIPLIER"
+	// agentTerminalExitCodesEnvVar is the environment variable that may be
+	// used to specify a comma separated list of Agent exit codes after which
+	// the Agent is not restarted, in addition to the terminal failure exit
+	// code of the Agent
+	agentTerminalExitCodesEnvVar = "ECS_INIT_AGENT_TERMINAL_EXIT_CODES"
+	// crashBundleCountEnvVar is the environment variable that may be used to
+	// specify the number of crash bundles kept. Crash bundles are not written
+	// if it's 0.
+	crashBundleCountEnvVar = "ECS_INIT_CRASH_BUNDLE_COUNT"
+
+	defaultAgentRestartWindow            = time.Hour
+	defaultAgentRestartMinBackoff        = 500 * time.Millisecond
+	defaultAgentRestartMaxBackoff        = 15 * time.Second
+	defaultAgentRestartBackoffMultiplier = 2.0
+	defaultCrashBundleCount              = 10
+)
+
+// SupervisionPolicy configures how the Agent is restarted when it exits
+type SupervisionPolicy struct {
+	// MaxRestarts is the number of restarts of the Agent within
+	// RestartWindow after which the Agent is not restarted anymore. The
+	// Agent is restarted without limit if it's 0.
+	MaxRestarts   int
+	RestartWindow time.Duration
+	// MinBackoff, MaxBackoff and BackoffMultiplier define the delay before
+	// the Agent is restarted, which starts at MinBackoff and is multiplied
+	// by BackoffMultiplier after each restart up to MaxBackoff
+	MinBackoff        time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+	// TerminalExitCodes are Agent exit codes after which the Agent is not
+	// restarted, in addition to the terminal failure exit code of the Agent
+	TerminalExitCodes []int
+}
+
+// IsTerminalExitCode returns whether the Agent must not be restarted after
+// exiting with the exit code
+func (p SupervisionPolicy) IsTerminalExitCode(exitCode int) bool {
+	for _, terminalExitCode := range p.TerminalExitCodes {
+		if exitCode == terminalExitCode {
+			return true
+		}
+	}
+	return false
+}
+
+// AgentSupervisionPolicy returns the supervision policy of the Agent.
+// Invalid values are logged and replaced by their default.
+func AgentSupervisionPolicy() SupervisionPolicy {
+	policy := SupervisionPolicy{
+		MaxRestarts:       parseEnvInt(agentMaxRestartsEnvVar, 0),
+		RestartWindow:     parseEnvDuration(agentRestartWindowEnvVar, defaultAgentRestartWindow),
+		MinBackoff:        parseEnvDuration(agentRestartMinBackoffEnvVar, defaultAgentRestartMinBackoff),
+		MaxBackoff:        parseEnvDuration(agentRestartMaxBackoffEnvVar, defaultAgentRestartMaxBackoff),
+		BackoffMultiplier: defaultAgentRestartBackoffMultiplier,
+	}
+	if value := os.Getenv(agentRestartBackoffMultiplierEnvVar); value != "" {
+		multiplier, err := strconv.ParseFloat(value, 64)
+		if err != nil || multiplier < 1 {
+			seelog.Warnf("Invalid value %q for %s, using default %v", value, agentRestartBackoffMultiplierEnvVar,
+				defaultAgentRestartBackoffMultiplier)
+		} else {
+			policy.BackoffMultiplier = multiplier
+		}
+	}
+	if policy.MaxBackoff < policy.MinBackoff {
+		seelog.Warnf("%s is less than %s, using %s as maximum backoff", agentRestartMaxBackoffEnvVar,
+			agentRestartMinBackoffEnvVar, policy.MinBackoff)
+		policy.MaxBackoff = policy.MinBackoff
+	}
+	for _, value := range strings.Split(os.Getenv(agentTerminalExitCodesEnvVar), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		exitCode, err := strconv.Atoi(value)
+		if err != nil {
+			seelog.Warnf("Ignoring invalid exit code %q in %s", value, agentTerminalExitCodesEnvVar)
+			continue
+		}
+		policy.TerminalExitCodes = append(policy.TerminalExitCodes, exitCode)
+	}
+	return policy
+}
+
+// CrashBundleCount returns the number of crash bundles of the Agent kept in
+// CrashBundleDirectory
+func CrashBundleCount() int {
+	return parseEnvInt(crashBundleCountEnvVar, defaultCrashBundleCount)
+}
+
+// CrashBundleDirectory returns the location on disk where crash bundles of
+// the Agent are written
+func CrashBundleDirectory() string {
+	return LogDirectory() + "/crash-bundles"
+}
+
+// AgentStateDBFile returns the location on disk of the state database of the Agent
+func AgentStateDBFile() string {
+	return AgentDataDirectory() + "/agent.db"
+}
+
+// parseEnvInt returns the non-negative integer value of the environment
+// variable, or the default value if it's unset or invalid
+func parseEnvInt(envVar string, defaultValue int) int {
+	value := os.Getenv(envVar)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		seelog.Warnf("Invalid value %q for %s, using default %d", value, envVar, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
+
+// parseEnvDuration returns the positive duration value of the environment
+// variable, or the default value if it's unset or invalid
+func parseEnvDuration(envVar string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(envVar)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		seelog.Warnf("Invalid value %q for %s, using default %s", value, envVar, defaultValue)
+		return defaultValue
+	}
+	return parsed
+}
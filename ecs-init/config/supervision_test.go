@@ -0,0 +1,73 @@
+// Copyright 2015 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgentSupervisionPolicyDefaults(t *testing.T) {
+	policy := AgentSupervisionPolicy()
+	assert.Equal(t, SupervisionPolicy{
+		RestartWindow:     time.Hour,
+		MinBackoff:        500 * time.Millisecond,
+		MaxBackoff:        15 * time.Second,
+		BackoffMultiplier: 2,
+	}, policy)
+	assert.False(t, policy.IsTerminalExitCode(1))
+	assert.Equal(t, 10, CrashBundleCount())
+}
+
+func TestAgentSupervisionPolicyFromEnv(t *testing.T) {
+	t.Setenv(agentMaxRestartsEnvVar, "5")
+	t.Setenv(agentRestartWindowEnvVar, "10m")
+	t.Setenv(agentRestartMinBackoffEnvVar, "1s")
+	t.Setenv(agentRestartMaxBackoffEnvVar, "1m")
+	t.Setenv(agentRestartBackoffMultiplierEnvVar, "1.5")
+	t.Setenv(agentTerminalExitCodesEnvVar, "3, 4,invalid,")
+	t.Setenv(crashBundleCountEnvVar, "0")
+
+	policy := AgentSupervisionPolicy()
+	assert.Equal(t, SupervisionPolicy{
+		MaxRestarts:       5,
+		RestartWindow:     10 * time.Minute,
+		MinBackoff:        time.Second,
+		MaxBackoff:        time.Minute,
+		BackoffMultiplier: 1.5,
+		TerminalExitCodes: []int{3, 4},
+	}, policy)
+	assert.True(t, policy.IsTerminalExitCode(4))
+	assert.False(t, policy.IsTerminalExitCode(1))
+	assert.Zero(t, CrashBundleCount())
+}
+
+func TestAgentSupervisionPolicyInvalidValues(t *testing.T) {
+	t.Setenv(agentMaxRestartsEnvVar, "-1")
+	t.Setenv(agentRestartWindowEnvVar, "forever")
+	t.Setenv(agentRestartMinBackoffEnvVar, "1m")
+	t.Setenv(agentRestartMaxBackoffEnvVar, "1s")
+	t.Setenv(agentRestartBackoffMultiplierEnvVar, "0.5")
+	t.Setenv(crashBundleCountEnvVar, "many")
+
+	policy := AgentSupervisionPolicy()
+	assert.Zero(t, policy.MaxRestarts)
+	assert.Equal(t, time.Hour, policy.RestartWindow)
+	assert.Equal(t, time.Minute, policy.MinBackoff)
+	assert.Equal(t, time.Minute, policy.MaxBackoff, "Expect the maximum backoff to be raised to the minimum backoff")
+	assert.Equal(t, 2.0, policy.BackoffMultiplier)
+	assert.Equal(t, 10, CrashBundleCount())
+}
@@ -39,11 +39,14 @@ var dockerAPIVersion string
 
 type dockerclient interface {
 	ListImages(opts godocker.ListImagesOptions) ([]godocker.APIImages, error)
+	InspectImage(name string) (*godocker.Image, error)
+	TagImage(name string, opts godocker.TagImageOptions) error
 	LoadImage(opts godocker.LoadImageOptions) error
 	Logs(opts godocker.LogsOptions) error
 	ListContainers(opts godocker.ListContainersOptions) ([]godocker.APIContainers, error)
 	RemoveContainer(opts godocker.RemoveContainerOptions) error
 	CreateContainer(opts godocker.CreateContainerOptions) (*godocker.Container, error)
+	InspectContainer(id string) (*godocker.Container, error)
 	StartContainer(id string, hostConfig *godocker.HostConfig) error
 	WaitContainer(id string) (int, error)
 	StopContainer(id string, timeout uint) error
@@ -181,6 +184,14 @@ func (d *_dockerclient) ListImages(opts godocker.ListImagesOptions) ([]godocker.
 	return d.docker.ListImages(opts)
 }
 
+func (d *_dockerclient) InspectImage(name string) (*godocker.Image, error) {
+	return d.docker.InspectImage(name)
+}
+
+func (d *_dockerclient) TagImage(name string, opts godocker.TagImageOptions) error {
+	return d.docker.TagImage(name, opts)
+}
+
 func (d *_dockerclient) LoadImage(opts godocker.LoadImageOptions) error {
 	return d.docker.LoadImage(opts)
 }
@@ -201,6 +212,10 @@ func (d *_dockerclient) CreateContainer(opts godocker.CreateContainerOptions) (*
 	return d.docker.CreateContainer(opts)
 }
 
+func (d *_dockerclient) InspectContainer(id string) (*godocker.Container, error) {
+	return d.docker.InspectContainer(id)
+}
+
 func (d *_dockerclient) StartContainer(id string, hostConfig *godocker.HostConfig) error {
 	return d.docker.StartContainer(id, hostConfig)
 }
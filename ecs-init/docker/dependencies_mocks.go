@@ -62,6 +62,36 @@ func (mr *MockdockerclientMockRecorder) CreateContainer(opts interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateContainer", reflect.TypeOf((*Mockdockerclient)(nil).CreateContainer), opts)
 }
 
+// InspectContainer mocks base method.
+func (m *Mockdockerclient) InspectContainer(id string) (*docker.Container, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InspectContainer", id)
+	ret0, _ := ret[0].(*docker.Container)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InspectContainer indicates an expected call of InspectContainer.
+func (mr *MockdockerclientMockRecorder) InspectContainer(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InspectContainer", reflect.TypeOf((*Mockdockerclient)(nil).InspectContainer), id)
+}
+
+// InspectImage mocks base method.
+func (m *Mockdockerclient) InspectImage(name string) (*docker.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InspectImage", name)
+	ret0, _ := ret[0].(*docker.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InspectImage indicates an expected call of InspectImage.
+func (mr *MockdockerclientMockRecorder) InspectImage(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InspectImage", reflect.TypeOf((*Mockdockerclient)(nil).InspectImage), name)
+}
+
 // ListContainers mocks base method.
 func (m *Mockdockerclient) ListContainers(opts docker.ListContainersOptions) ([]docker.APIContainers, error) {
 	m.ctrl.T.Helper()
@@ -176,6 +206,20 @@ func (mr *MockdockerclientMockRecorder) StopContainer(id, timeout interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopContainer", reflect.TypeOf((*Mockdockerclient)(nil).StopContainer), id, timeout)
 }
 
+// TagImage mocks base method.
+func (m *Mockdockerclient) TagImage(name string, opts docker.TagImageOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagImage", name, opts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TagImage indicates an expected call of TagImage.
+func (mr *MockdockerclientMockRecorder) TagImage(name, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagImage", reflect.TypeOf((*Mockdockerclient)(nil).TagImage), name, opts)
+}
+
 // WaitContainer mocks base method.
 func (m *Mockdockerclient) WaitContainer(id string) (int, error) {
 	m.ctrl.T.Helper()
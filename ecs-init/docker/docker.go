@@ -130,6 +130,19 @@ const (
 	// fault inject functionality. Ref: https://man7.org/linux/man-pages/man8/modinfo.8.html
 	modInfoSbinDir    = "/sbin/modinfo"
 	modInfoUsrSbinDir = "/usr/sbin/modinfo"
+
+	// dbusSystemBusDir is the directory of the socket of the D-Bus system bus. The directory is bind mounted
+	// rather than the socket so that the mount survives restarts of D-Bus.
+	dbusSystemBusDir = "/run/dbus"
+	// dbusSystemBusAddressEnvVar points the Agent to the D-Bus system bus socket in dbusSystemBusDir.
+	dbusSystemBusAddressEnvVar = "DBUS_SYSTEM_BUS_ADDRESS"
+	dbusSystemBusAddress       = "unix:path=" + dbusSystemBusDir + "/system_bus_socket"
+
+	// stopAgentTimeoutSeconds is the time the Agent is given to stop before it's killed
+	stopAgentTimeoutSeconds = uint(10)
+	// drainStopAgentTimeoutSeconds is the time the Agent is given to stop when it drains the instance on
+	// shutdown. It stays below the default stop timeout of systemd units, 90 seconds.
+	drainStopAgentTimeoutSeconds = uint(80)
 )
 
 // Do NOT include "CAP_" in capability string
@@ -212,6 +225,37 @@ func (c *client) LoadImage(image io.Reader) error {
 	return c.docker.LoadImage(godocker.LoadImageOptions{InputStream: image})
 }
 
+// AgentImageID returns the ID of the Agent image loaded in Docker
+func (c *client) AgentImageID() (string, error) {
+	image, err := c.docker.InspectImage(config.AgentImageName)
+	if err != nil {
+		return "", err
+	}
+	return image.ID, nil
+}
+
+// SaveAgentImage tags the Agent image loaded in Docker as the previous Agent
+// image, so that it can be restored if the Agent image is upgraded and the
+// upgraded Agent fails
+func (c *client) SaveAgentImage() error {
+	return c.tagImage(config.AgentImageName, config.AgentPreviousImageName)
+}
+
+// RestorePreviousAgentImage tags the previous Agent image saved by
+// SaveAgentImage as the Agent image
+func (c *client) RestorePreviousAgentImage() error {
+	return c.tagImage(config.AgentPreviousImageName, config.AgentImageName)
+}
+
+func (c *client) tagImage(source, target string) error {
+	repository, tag, _ := strings.Cut(target, ":")
+	return c.docker.TagImage(source, godocker.TagImageOptions{
+		Repo:  repository,
+		Tag:   tag,
+		Force: true,
+	})
+}
+
 // RemoveExistingAgentContainer removes any existing container named
 // "ecs-agent" or returns without error if none is found
 func (c *client) RemoveExistingAgentContainer() error {
@@ -254,6 +298,16 @@ func (c *client) findAgentContainer() (string, error) {
 	return "", nil
 }
 
+// InspectAgentContainer returns the details of the existing container named
+// "ecs-agent", or nil if none is found
+func (c *client) InspectAgentContainer() (*godocker.Container, error) {
+	containerID, err := c.findAgentContainer()
+	if err != nil || containerID == "" {
+		return nil, err
+	}
+	return c.docker.InspectContainer(containerID)
+}
+
 // StartAgent starts the Agent in Docker and returns the exit code from the container
 func (c *client) StartAgent() (int, error) {
 	envVarsFromFiles := c.LoadEnvVars()
@@ -341,6 +395,10 @@ func (c *client) getContainerConfig(envVarsFromFiles map[string]string) *godocke
 		}
 	}
 
+	if needsSystemBus(envVarsFromFiles) {
+		envVariables[dbusSystemBusAddressEnvVar] = dbusSystemBusAddress
+	}
+
 	for key, val := range envVarsFromFiles {
 		envVariables[key] = val
 	}
@@ -495,6 +553,14 @@ func (c *client) getHostConfig(envVarsFromFiles map[string]string) *godocker.Hos
 		}
 	}
 
+	if needsSystemBus(envVarsFromFiles) && isPathValid(dbusSystemBusDir, true) {
+		binds = append(binds, dbusSystemBusDir+":"+dbusSystemBusDir)
+	}
+
+	if stateBackupBind, ok := getStateBackupDirBind(envVarsFromFiles); ok {
+		binds = append(binds, stateBackupBind)
+	}
+
 	binds = append(binds, getDockerPluginDirBinds()...)
 
 	// only add bind mounts when the src file/directory exists on host; otherwise docker API create an empty directory on host
@@ -520,6 +586,26 @@ func getCredentialsFetcherSocketBind() (string, bool) {
 	return "", false
 }
 
+// getStateBackupDirBind returns the bind for the state backup directory of the Agent, when it's a directory of the
+// host outside the data directory of the Agent.
+func getStateBackupDirBind(envVarsFromFiles map[string]string) (string, bool) {
+	backupDir := filepath.Clean(envVarsFromFiles[config.StateBackupDirEnvVar])
+	if !filepath.IsAbs(backupDir) || backupDir == dataDir || strings.HasPrefix(backupDir, dataDir+"/") {
+		return "", false
+	}
+	if !isPathValid(backupDir, true) {
+		return "", false
+	}
+	return backupDir + ":" + backupDir, true
+}
+
+// needsSystemBus returns whether the Agent is configured with a feature that talks to systemd over the
+// D-Bus system bus.
+func needsSystemBus(envVarsFromFiles map[string]string) bool {
+	return envVarsFromFiles[config.RemediationPolicyFileEnvVar] != "" ||
+		envVarsFromFiles[config.DrainPolicyFileEnvVar] != ""
+}
+
 // getDockerSocketBind returns the bind for Docker socket.
 // Value for the bind is as follows:
 //  1. DOCKER_HOST (as in os.Getenv) not set: source /var/run, dest /var/run
@@ -630,8 +716,7 @@ func (c *client) StopAgent() error {
 		log.Info("No running Agent to stop")
 		return nil
 	}
-	stopContainerTimeoutSeconds := uint(10)
-	err = c.docker.StopContainer(id, stopContainerTimeoutSeconds)
+	err = c.docker.StopContainer(id, c.stopAgentTimeout())
 	if _, ok := err.(*godocker.ContainerNotRunning); ok {
 		log.Info("Agent is already stopped")
 		return nil
@@ -639,6 +724,18 @@ func (c *client) StopAgent() error {
 	return err
 }
 
+// stopAgentTimeout returns the time the Agent is given to stop, which is longer when it may drain the
+// instance on shutdown. The config files are read directly rather than with LoadEnvVars, which waits for
+// GPU devices.
+func (c *client) stopAgentTimeout() uint {
+	for _, envVars := range []map[string]string{c.loadCustomInstanceEnvVars(), c.loadUsrEnvVars()} {
+		if envVars[config.DrainPolicyFileEnvVar] != "" {
+			return drainStopAgentTimeoutSeconds
+		}
+	}
+	return stopAgentTimeoutSeconds
+}
+
 // isDomainJoined is used to validate if container instance is part of a valid active directory.
 func isDomainJoined() bool {
 	realmPath, err := execLookPath("realm")
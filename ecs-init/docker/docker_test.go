@@ -97,6 +97,74 @@ func TestLoadImage(t *testing.T) {
 	assert.NoError(t, err, "no errors should be returned on load image with nil image")
 }
 
+func TestAgentImageID(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerclient(mockCtrl)
+
+	mockDocker.EXPECT().InspectImage(config.AgentImageName).Return(&godocker.Image{ID: "sha256:agent"}, nil)
+
+	client := &client{
+		docker: mockDocker,
+	}
+	imageID, err := client.AgentImageID()
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:agent", imageID)
+}
+
+func TestSaveAndRestorePreviousAgentImage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerclient(mockCtrl)
+
+	gomock.InOrder(
+		mockDocker.EXPECT().TagImage(config.AgentImageName, godocker.TagImageOptions{
+			Repo:  "amazon/amazon-ecs-agent",
+			Tag:   "previous",
+			Force: true,
+		}),
+		mockDocker.EXPECT().TagImage(config.AgentPreviousImageName, godocker.TagImageOptions{
+			Repo:  "amazon/amazon-ecs-agent",
+			Tag:   "latest",
+			Force: true,
+		}),
+	)
+
+	client := &client{
+		docker: mockDocker,
+	}
+	assert.NoError(t, client.SaveAgentImage())
+	assert.NoError(t, client.RestorePreviousAgentImage())
+}
+
+func TestInspectAgentContainer(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerclient(mockCtrl)
+
+	gomock.InOrder(
+		mockDocker.EXPECT().ListContainers(gomock.Any()).Return([]godocker.APIContainers{
+			{Names: []string{"/ecs-agent"}, ID: "id"},
+		}, nil),
+		mockDocker.EXPECT().InspectContainer("id").Return(&godocker.Container{ID: "id"}, nil),
+		mockDocker.EXPECT().ListContainers(gomock.Any()),
+	)
+
+	client := &client{
+		docker: mockDocker,
+	}
+	container, err := client.InspectAgentContainer()
+	assert.NoError(t, err)
+	assert.Equal(t, "id", container.ID)
+
+	container, err = client.InspectAgentContainer()
+	assert.NoError(t, err)
+	assert.Nil(t, container, "Expect no container when the agent container doesn't exist")
+}
+
 func TestRemoveExistingAgentContainerListContainersFailure(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -670,6 +738,7 @@ func TestStopAgent(t *testing.T) {
 		listEmpty            bool
 		stopFailedNotRunning bool
 		stopFailedOther      bool
+		drainPolicy          bool
 		expectedError        bool
 	}{
 		{
@@ -691,6 +760,10 @@ func TestStopAgent(t *testing.T) {
 			name:            "List containers succeeded, stop agent failed on error other than not running",
 			stopFailedOther: true,
 		},
+		{
+			name:        "List containers succeeded, stop agent draining on shutdown succeeded",
+			drainPolicy: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -699,9 +772,17 @@ func TestStopAgent(t *testing.T) {
 			defer mockCtrl.Finish()
 
 			mockDocker := NewMockdockerclient(mockCtrl)
+			mockFS := NewMockfileSystem(mockCtrl)
 			client := &client{
 				docker: mockDocker,
+				fs:     mockFS,
 			}
+			agentConfig := ""
+			if tc.drainPolicy {
+				agentConfig = config.DrainPolicyFileEnvVar + "=/etc/ecs/drain-policy.json\n"
+			}
+			mockFS.EXPECT().ReadFile(config.InstanceConfigFile()).Return(nil, errors.New("not found")).AnyTimes()
+			mockFS.EXPECT().ReadFile(config.AgentConfigFile()).Return([]byte(agentConfig), nil).AnyTimes()
 
 			var listInput godocker.ListContainersOptions
 			var listOutput []godocker.APIContainers
@@ -737,7 +818,11 @@ func TestStopAgent(t *testing.T) {
 			}
 
 			if !tc.listEmpty && !tc.listFailed {
-				mockDocker.EXPECT().StopContainer("id", uint(10)).Return(stopErr)
+				stopTimeout := uint(10)
+				if tc.drainPolicy {
+					stopTimeout = 80
+				}
+				mockDocker.EXPECT().StopContainer("id", stopTimeout).Return(stopErr)
 			}
 
 			if tc.listFailed || tc.stopFailedOther {
@@ -880,6 +965,61 @@ func TestGetHostConfigExternal(t *testing.T) {
 	assert.NotEmpty(t, hostConfig.CapAdd)
 }
 
+func TestSystemBusWithPolicies(t *testing.T) {
+	isPathValid = func(path string, isDir bool) bool {
+		return true
+	}
+	defer func() {
+		isPathValid = defaultIsPathValid
+	}()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFS := NewMockfileSystem(mockCtrl)
+	mockFS.EXPECT().ReadFile(config.InstanceConfigFile()).Return(nil, errors.New("not found")).AnyTimes()
+	mockFS.EXPECT().ReadFile(config.AgentConfigFile()).Return(nil, errors.New("not found")).AnyTimes()
+	client := &client{
+		fs: mockFS,
+	}
+	dbusBind := dbusSystemBusDir + ":" + dbusSystemBusDir
+	dbusEnv := dbusSystemBusAddressEnvVar + "=" + dbusSystemBusAddress
+
+	for _, envVarsFromFiles := range []map[string]string{
+		{config.RemediationPolicyFileEnvVar: "/etc/ecs/remediation-policy.json"},
+		{config.DrainPolicyFileEnvVar: "/etc/ecs/drain-policy.json"},
+	} {
+		assert.Contains(t, client.getHostConfig(envVarsFromFiles).Binds, dbusBind)
+		assert.Contains(t, client.getContainerConfig(envVarsFromFiles).Env, dbusEnv)
+	}
+
+	assert.NotContains(t, client.getHostConfig(map[string]string{}).Binds, dbusBind)
+	assert.NotContains(t, client.getContainerConfig(map[string]string{}).Env, dbusEnv)
+}
+
+func TestStateBackupDirBind(t *testing.T) {
+	isPathValid = func(path string, isDir bool) bool {
+		return path != "/missing"
+	}
+	defer func() {
+		isPathValid = defaultIsPathValid
+	}()
+
+	for _, tc := range []struct {
+		backupDir string
+		bind      string
+	}{
+		{backupDir: "/mnt/backup/ecs/", bind: "/mnt/backup/ecs:/mnt/backup/ecs"},
+		{backupDir: ""},
+		{backupDir: "backups"},
+		{backupDir: dataDir + "/backups"},
+		{backupDir: "/missing"},
+	} {
+		bind, ok := getStateBackupDirBind(map[string]string{config.StateBackupDirEnvVar: tc.backupDir})
+		assert.Equal(t, tc.bind != "", ok, tc.backupDir)
+		assert.Equal(t, tc.bind, bind, tc.backupDir)
+	}
+}
+
 func TestStartAgentWithExecBinds(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -0,0 +1,295 @@
+// Copyright 2015-2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-init/config"
+	"github.com/aws/amazon-ecs-agent/ecs-init/version"
+
+	log "github.com/cihub/seelog"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	crashBundlePrefix = "ecs-agent-crash-"
+	crashBundleSuffix = ".tar.gz"
+	// crashBundleLogWindowSize is the number of lines of the Agent logs
+	// included in crash bundles, as string for log config
+	crashBundleLogWindowSize      = "1000"
+	crashBundleDirPermission      = 0700
+	crashBundleEntryPermission    = 0600
+	crashBundleTimestampFormat    = "20060102T150405Z"
+	crashBundleErrorsEntryName    = "errors.txt"
+	crashBundleExitEntryName      = "exit.json"
+	crashBundleLogEntryName       = "agent-log-tail.txt"
+	crashBundleContainerEntryName = "agent-container.json"
+	crashBundleStateDBEntryName   = "agent-state-db.json"
+	crashBundleHostEntryName      = "host-info.json"
+	// stateDBMetadataBucketName is the bucket of the Agent state database
+	// holding the Agent metadata, such as the cluster and container instance
+	stateDBMetadataBucketName = "metadata"
+	// stateDBOpenTimeout is how long to wait for the lock on the Agent state
+	// database, in case another process still holds it
+	stateDBOpenTimeout = 5 * time.Second
+)
+
+// hostInfoFiles are the files describing the host that are included in crash
+// bundles
+var hostInfoFiles = []string{
+	"/etc/os-release",
+	"/proc/version",
+	"/proc/uptime",
+	"/proc/loadavg",
+	"/proc/meminfo",
+}
+
+// crashBundles writes crash bundles of the Agent to a directory, keeping the
+// newest maxBundles of them. A crash bundle is a gzipped tarball holding the
+// tail of the Agent logs, the details of the Agent container, the metadata of
+// the Agent state database and information about the host.
+type crashBundles struct {
+	dir        string
+	maxBundles int
+	stateDB    string
+}
+
+// newCrashBundleWriter returns the crash bundle writer configured for the
+// Agent, or nil if crash bundles are disabled
+func newCrashBundleWriter() crashBundleWriter {
+	maxBundles := config.CrashBundleCount()
+	if maxBundles == 0 {
+		return nil
+	}
+	return &crashBundles{
+		dir:        config.CrashBundleDirectory(),
+		maxBundles: maxBundles,
+		stateDB:    config.AgentStateDBFile(),
+	}
+}
+
+type crashBundleExit struct {
+	ExitCode int       `json:"exitCode"`
+	Time     time.Time `json:"time"`
+	Version  string    `json:"ecsInitVersion"`
+}
+
+type crashBundleHostInfo struct {
+	Hostname   string            `json:"hostname,omitempty"`
+	Files      map[string]string `json:"files"`
+	DataVolume *diskUsage        `json:"dataVolume,omitempty"`
+}
+
+type stateDBInfo struct {
+	Path      string            `json:"path"`
+	SizeBytes int64             `json:"sizeBytes"`
+	ModTime   time.Time         `json:"modTime"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+type diskUsage struct {
+	Path       string `json:"path"`
+	TotalBytes uint64 `json:"totalBytes"`
+	FreeBytes  uint64 `json:"freeBytes"`
+	TotalFiles uint64 `json:"totalFiles"`
+	FreeFiles  uint64 `json:"freeFiles"`
+}
+
+// Write writes a crash bundle for the Agent that exited with the exit code and
+// returns its path. Information that can't be collected is listed in the
+// errors entry of the bundle rather than failing the bundle.
+func (b *crashBundles) Write(docker dockerClient, agentExitCode int) (string, error) {
+	if err := os.MkdirAll(b.dir, crashBundleDirPermission); err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	file, err := os.CreateTemp(b.dir, crashBundlePrefix+now.Format(crashBundleTimestampFormat)+"-*"+crashBundleSuffix)
+	if err != nil {
+		return "", err
+	}
+	err = b.writeBundle(file, docker, agentExitCode, now)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	b.rotate()
+	return file.Name(), nil
+}
+
+func (b *crashBundles) writeBundle(file *os.File, docker dockerClient, agentExitCode int, now time.Time) error {
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	bundle := &crashBundle{writer: tarWriter, modTime: now}
+
+	bundle.addJSON(crashBundleExitEntryName, crashBundleExit{
+		ExitCode: agentExitCode,
+		Time:     now,
+		Version:  version.Version,
+	}, nil)
+	bundle.add(crashBundleLogEntryName, []byte(docker.GetContainerLogTail(crashBundleLogWindowSize)), nil)
+	container, err := docker.InspectAgentContainer()
+	if err == nil && container == nil {
+		err = fmt.Errorf("no %s container found", config.AgentContainerName)
+	}
+	bundle.addJSON(crashBundleContainerEntryName, container, err)
+	stateDB, err := readStateDBInfo(b.stateDB)
+	bundle.addJSON(crashBundleStateDBEntryName, stateDB, err)
+	bundle.addJSON(crashBundleHostEntryName, readHostInfo(), nil)
+	if len(bundle.errors) > 0 {
+		bundle.add(crashBundleErrorsEntryName, []byte(strings.Join(bundle.errors, "\n")+"\n"), nil)
+	}
+
+	if bundle.err != nil {
+		return bundle.err
+	}
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// rotate removes the oldest crash bundles so that at most maxBundles are kept
+func (b *crashBundles) rotate() {
+	bundles, err := filepath.Glob(filepath.Join(b.dir, crashBundlePrefix+"*"+crashBundleSuffix))
+	if err != nil {
+		log.Warnf("Could not list crash bundles: %v", err)
+		return
+	}
+	if len(bundles) <= b.maxBundles {
+		return
+	}
+	// Bundle names start with their creation time, so that they sort from
+	// oldest to newest
+	sort.Strings(bundles)
+	for _, bundle := range bundles[:len(bundles)-b.maxBundles] {
+		if err := os.Remove(bundle); err != nil {
+			log.Warnf("Could not remove crash bundle %s: %v", bundle, err)
+		}
+	}
+}
+
+// crashBundle adds entries to a crash bundle, recording why information
+// couldn't be collected
+type crashBundle struct {
+	writer  *tar.Writer
+	modTime time.Time
+	errors  []string
+	// err is the first error writing the bundle
+	err error
+}
+
+// add adds an entry to the bundle, or records collectErr if the entry couldn't
+// be collected
+func (b *crashBundle) add(name string, data []byte, collectErr error) {
+	if collectErr != nil {
+		b.errors = append(b.errors, fmt.Sprintf("%s: %v", name, collectErr))
+		return
+	}
+	if b.err != nil {
+		return
+	}
+	b.err = b.writer.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    crashBundleEntryPermission,
+		Size:    int64(len(data)),
+		ModTime: b.modTime,
+	})
+	if b.err == nil {
+		_, b.err = b.writer.Write(data)
+	}
+}
+
+func (b *crashBundle) addJSON(name string, value interface{}, collectErr error) {
+	if collectErr != nil {
+		b.add(name, nil, collectErr)
+		return
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	b.add(name, data, err)
+}
+
+// readStateDBInfo returns the size and modification time of the Agent state
+// database and a copy of its metadata bucket. The database is opened read-only
+// once the Agent has exited, with a timeout in case its lock is still held.
+func readStateDBInfo(stateDB string) (*stateDBInfo, error) {
+	info, err := os.Stat(stateDB)
+	if err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(stateDB, crashBundleEntryPermission, &bolt.Options{
+		ReadOnly: true,
+		Timeout:  stateDBOpenTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not open %s: %w", stateDB, err)
+	}
+	defer db.Close()
+	metadata := make(map[string]string)
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(stateDBMetadataBucketName))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(key, value []byte) error {
+			metadata[string(key)] = string(value)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", stateDB, err)
+	}
+	return &stateDBInfo{
+		Path:      stateDB,
+		SizeBytes: info.Size(),
+		ModTime:   info.ModTime().UTC(),
+		Metadata:  metadata,
+	}, nil
+}
+
+func readHostInfo() crashBundleHostInfo {
+	info := crashBundleHostInfo{Files: make(map[string]string)}
+	info.Hostname, _ = os.Hostname()
+	for _, name := range hostInfoFiles {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		info.Files[name] = string(data)
+	}
+	var stat syscall.Statfs_t
+	dataDir := config.AgentDataDirectory()
+	if err := syscall.Statfs(dataDir, &stat); err == nil {
+		info.DataVolume = &diskUsage{
+			Path:       dataDir,
+			TotalBytes: stat.Blocks * uint64(stat.Bsize),
+			FreeBytes:  stat.Bavail * uint64(stat.Bsize),
+			TotalFiles: stat.Files,
+			FreeFiles:  stat.Ffree,
+		}
+	}
+	return info
+}
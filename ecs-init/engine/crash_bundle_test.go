@@ -0,0 +1,195 @@
+//go:build test
+// +build test
+
+// Copyright 2015 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	godocker "github.com/fsouza/go-dockerclient"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+// writeAgentStateDB writes an Agent state database holding the metadata
+func writeAgentStateDB(t *testing.T, metadata map[string]string) string {
+	stateDB := filepath.Join(t.TempDir(), "agent.db")
+	db, err := bolt.Open(stateDB, 0600, nil)
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucket([]byte(stateDBMetadataBucketName))
+		if err != nil {
+			return err
+		}
+		for key, value := range metadata {
+			if err := bucket.Put([]byte(key), []byte(value)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	return stateDB
+}
+
+// readCrashBundle returns the entries of the crash bundle
+func readCrashBundle(t *testing.T, bundle string) map[string]string {
+	file, err := os.Open(bundle)
+	require.NoError(t, err)
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	tarReader := tar.NewReader(gzipReader)
+	entries := make(map[string]string)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return entries
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tarReader)
+		require.NoError(t, err)
+		entries[header.Name] = string(data)
+	}
+}
+
+func TestCrashBundleWrite(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	mockDocker.EXPECT().GetContainerLogTail(crashBundleLogWindowSize).Return("agent log line\n")
+	mockDocker.EXPECT().InspectAgentContainer().Return(&godocker.Container{ID: "agent-id"}, nil)
+
+	bundles := &crashBundles{
+		dir:        filepath.Join(t.TempDir(), "crash-bundles"),
+		maxBundles: 10,
+		stateDB:    writeAgentStateDB(t, map[string]string{"cluster-name": "test-cluster"}),
+	}
+	bundle, err := bundles.Write(mockDocker, 139)
+	require.NoError(t, err)
+	assert.Equal(t, bundles.dir, filepath.Dir(bundle))
+
+	entries := readCrashBundle(t, bundle)
+	assert.Equal(t, "agent log line\n", entries[crashBundleLogEntryName])
+	assert.NotContains(t, entries, crashBundleErrorsEntryName)
+
+	var exit crashBundleExit
+	require.NoError(t, json.Unmarshal([]byte(entries[crashBundleExitEntryName]), &exit))
+	assert.Equal(t, 139, exit.ExitCode)
+
+	var container godocker.Container
+	require.NoError(t, json.Unmarshal([]byte(entries[crashBundleContainerEntryName]), &container))
+	assert.Equal(t, "agent-id", container.ID)
+
+	var stateDB stateDBInfo
+	require.NoError(t, json.Unmarshal([]byte(entries[crashBundleStateDBEntryName]), &stateDB))
+	assert.Equal(t, bundles.stateDB, stateDB.Path)
+	assert.NotZero(t, stateDB.SizeBytes)
+	assert.Equal(t, map[string]string{"cluster-name": "test-cluster"}, stateDB.Metadata)
+
+	assert.Contains(t, entries, crashBundleHostEntryName)
+}
+
+func TestCrashBundleWriteRecordsCollectionErrors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	mockDocker.EXPECT().GetContainerLogTail(gomock.Any()).Return("")
+	mockDocker.EXPECT().InspectAgentContainer().Return(nil, errors.New("docker unavailable"))
+
+	bundles := &crashBundles{
+		dir:        t.TempDir(),
+		maxBundles: 10,
+		stateDB:    filepath.Join(t.TempDir(), "agent.db"),
+	}
+	bundle, err := bundles.Write(mockDocker, 2)
+	require.NoError(t, err)
+
+	entries := readCrashBundle(t, bundle)
+	assert.NotContains(t, entries, crashBundleContainerEntryName)
+	assert.NotContains(t, entries, crashBundleStateDBEntryName)
+	assert.Contains(t, entries[crashBundleErrorsEntryName], "docker unavailable")
+	assert.Contains(t, entries[crashBundleErrorsEntryName], crashBundleStateDBEntryName)
+	assert.Contains(t, entries, crashBundleExitEntryName)
+}
+
+func TestCrashBundleWriteInvalidStateDB(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	mockDocker.EXPECT().GetContainerLogTail(gomock.Any()).Return("")
+	mockDocker.EXPECT().InspectAgentContainer().Return(&godocker.Container{ID: "agent-id"}, nil)
+
+	stateDB := filepath.Join(t.TempDir(), "agent.db")
+	require.NoError(t, os.WriteFile(stateDB, make([]byte, 4096), 0600))
+	bundles := &crashBundles{
+		dir:        t.TempDir(),
+		maxBundles: 10,
+		stateDB:    stateDB,
+	}
+	bundle, err := bundles.Write(mockDocker, 1)
+	require.NoError(t, err)
+
+	entries := readCrashBundle(t, bundle)
+	assert.NotContains(t, entries, crashBundleStateDBEntryName)
+	assert.Contains(t, entries[crashBundleErrorsEntryName], "could not open "+stateDB)
+	assert.Contains(t, entries, crashBundleContainerEntryName)
+}
+
+func TestCrashBundleRotation(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	mockDocker.EXPECT().GetContainerLogTail(gomock.Any()).AnyTimes()
+	mockDocker.EXPECT().InspectAgentContainer().AnyTimes()
+
+	dir := t.TempDir()
+	oldBundles := []string{
+		filepath.Join(dir, crashBundlePrefix+"20200101T000000Z-1"+crashBundleSuffix),
+		filepath.Join(dir, crashBundlePrefix+"20200102T000000Z-1"+crashBundleSuffix),
+	}
+	for _, bundle := range oldBundles {
+		require.NoError(t, os.WriteFile(bundle, nil, 0600))
+	}
+	otherFile := filepath.Join(dir, "other.txt")
+	require.NoError(t, os.WriteFile(otherFile, nil, 0600))
+
+	bundles := &crashBundles{
+		dir:        dir,
+		maxBundles: 2,
+		stateDB:    filepath.Join(dir, "agent.db"),
+	}
+	bundle, err := bundles.Write(mockDocker, 1)
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, oldBundles[0], "Expect the oldest crash bundle to be removed")
+	assert.FileExists(t, oldBundles[1])
+	assert.FileExists(t, bundle)
+	assert.FileExists(t, otherFile)
+}
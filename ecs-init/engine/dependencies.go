@@ -17,6 +17,8 @@ import (
 	"io"
 
 	"github.com/aws/amazon-ecs-agent/ecs-init/cache"
+
+	godocker "github.com/fsouza/go-dockerclient"
 )
 
 //go:generate mockgen.sh $GOPACKAGE $GOFILE
@@ -33,10 +35,14 @@ type downloader interface {
 type dockerClient interface {
 	GetContainerLogTail(logWindowSize string) string
 	IsAgentImageLoaded() (bool, error)
+	AgentImageID() (string, error)
 	LoadImage(image io.Reader) error
+	SaveAgentImage() error
+	RestorePreviousAgentImage() error
 	RemoveExistingAgentContainer() error
 	StartAgent() (int, error)
 	StopAgent() error
+	InspectAgentContainer() (*godocker.Container, error)
 	LoadEnvVars() map[string]string
 }
 
@@ -53,3 +59,11 @@ type credentialsProxyRoute interface {
 type ipv6RouterAdvertisements interface {
 	Disable() error
 }
+
+type volumePlugin interface {
+	Reconcile() error
+}
+
+type crashBundleWriter interface {
+	Write(docker dockerClient, agentExitCode int) (string, error)
+}
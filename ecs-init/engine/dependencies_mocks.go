@@ -22,6 +22,7 @@ import (
 	reflect "reflect"
 
 	cache "github.com/aws/amazon-ecs-agent/ecs-init/cache"
+	docker "github.com/fsouza/go-dockerclient"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -157,6 +158,21 @@ func (m *MockdockerClient) EXPECT() *MockdockerClientMockRecorder {
 	return m.recorder
 }
 
+// AgentImageID mocks base method.
+func (m *MockdockerClient) AgentImageID() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AgentImageID")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AgentImageID indicates an expected call of AgentImageID.
+func (mr *MockdockerClientMockRecorder) AgentImageID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AgentImageID", reflect.TypeOf((*MockdockerClient)(nil).AgentImageID))
+}
+
 // GetContainerLogTail mocks base method.
 func (m *MockdockerClient) GetContainerLogTail(logWindowSize string) string {
 	m.ctrl.T.Helper()
@@ -171,6 +187,21 @@ func (mr *MockdockerClientMockRecorder) GetContainerLogTail(logWindowSize interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerLogTail", reflect.TypeOf((*MockdockerClient)(nil).GetContainerLogTail), logWindowSize)
 }
 
+// InspectAgentContainer mocks base method.
+func (m *MockdockerClient) InspectAgentContainer() (*docker.Container, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InspectAgentContainer")
+	ret0, _ := ret[0].(*docker.Container)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InspectAgentContainer indicates an expected call of InspectAgentContainer.
+func (mr *MockdockerClientMockRecorder) InspectAgentContainer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InspectAgentContainer", reflect.TypeOf((*MockdockerClient)(nil).InspectAgentContainer))
+}
+
 // IsAgentImageLoaded mocks base method.
 func (m *MockdockerClient) IsAgentImageLoaded() (bool, error) {
 	m.ctrl.T.Helper()
@@ -228,6 +259,34 @@ func (mr *MockdockerClientMockRecorder) RemoveExistingAgentContainer() *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveExistingAgentContainer", reflect.TypeOf((*MockdockerClient)(nil).RemoveExistingAgentContainer))
 }
 
+// RestorePreviousAgentImage mocks base method.
+func (m *MockdockerClient) RestorePreviousAgentImage() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestorePreviousAgentImage")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestorePreviousAgentImage indicates an expected call of RestorePreviousAgentImage.
+func (mr *MockdockerClientMockRecorder) RestorePreviousAgentImage() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestorePreviousAgentImage", reflect.TypeOf((*MockdockerClient)(nil).RestorePreviousAgentImage))
+}
+
+// SaveAgentImage mocks base method.
+func (m *MockdockerClient) SaveAgentImage() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAgentImage")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAgentImage indicates an expected call of SaveAgentImage.
+func (mr *MockdockerClientMockRecorder) SaveAgentImage() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAgentImage", reflect.TypeOf((*MockdockerClient)(nil).SaveAgentImage))
+}
+
 // StartAgent mocks base method.
 func (m *MockdockerClient) StartAgent() (int, error) {
 	m.ctrl.T.Helper()
@@ -395,3 +454,78 @@ func (mr *Mockipv6RouterAdvertisementsMockRecorder) Disable() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*Mockipv6RouterAdvertisements)(nil).Disable))
 }
+
+// MockvolumePlugin is a mock of volumePlugin interface.
+type MockvolumePlugin struct {
+	ctrl     *gomock.Controller
+	recorder *MockvolumePluginMockRecorder
+}
+
+// MockvolumePluginMockRecorder is the mock recorder for MockvolumePlugin.
+type MockvolumePluginMockRecorder struct {
+	mock *MockvolumePlugin
+}
+
+// NewMockvolumePlugin creates a new mock instance.
+func NewMockvolumePlugin(ctrl *gomock.Controller) *MockvolumePlugin {
+	mock := &MockvolumePlugin{ctrl: ctrl}
+	mock.recorder = &MockvolumePluginMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockvolumePlugin) EXPECT() *MockvolumePluginMockRecorder {
+	return m.recorder
+}
+
+// Reconcile mocks base method.
+func (m *MockvolumePlugin) Reconcile() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reconcile indicates an expected call of Reconcile.
+func (mr *MockvolumePluginMockRecorder) Reconcile() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockvolumePlugin)(nil).Reconcile))
+}
+
+// MockcrashBundleWriter is a mock of crashBundleWriter interface.
+type MockcrashBundleWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockcrashBundleWriterMockRecorder
+}
+
+// MockcrashBundleWriterMockRecorder is the mock recorder for MockcrashBundleWriter.
+type MockcrashBundleWriterMockRecorder struct {
+	mock *MockcrashBundleWriter
+}
+
+// NewMockcrashBundleWriter creates a new mock instance.
+func NewMockcrashBundleWriter(ctrl *gomock.Controller) *MockcrashBundleWriter {
+	mock := &MockcrashBundleWriter{ctrl: ctrl}
+	mock.recorder = &MockcrashBundleWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockcrashBundleWriter) EXPECT() *MockcrashBundleWriterMockRecorder {
+	return m.recorder
+}
+
+// Write mocks base method.
+func (m *MockcrashBundleWriter) Write(docker dockerClient, agentExitCode int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Write", docker, agentExitCode)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Write indicates an expected call of Write.
+func (mr *MockcrashBundleWriterMockRecorder) Write(docker, agentExitCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockcrashBundleWriter)(nil).Write), docker, agentExitCode)
+}
@@ -41,10 +41,7 @@ const (
 	TerminalFailureAgentExitCode  = 5
 	DefaultInitErrorExitCode      = -1
 	upgradeAgentExitCode          = 42
-	serviceStartMinRetryTime      = time.Millisecond * 500
-	serviceStartMaxRetryTime      = time.Second * 15
 	serviceStartRetryJitter       = 0.10
-	serviceStartRetryMultiplier   = 2.0
 	serviceStartMaxRetries        = math.MaxInt64 // essentially retry forever
 	failedContainerLogWindowSize  = "200"         // as string for log config
 	mountFilePermission           = 0755
@@ -70,6 +67,14 @@ type Engine struct {
 	credentialsProxyRoute    credentialsProxyRoute
 	ipv6RouterAdvertisements ipv6RouterAdvertisements
 	nvidiaGPUManager         gpu.GPUManager
+	upgrades                 *upgradeTracker
+	supervision              config.SupervisionPolicy
+	// crashBundles writes a crash bundle on unexpected exits of the Agent.
+	// Crash bundles are disabled if it's nil.
+	crashBundles crashBundleWriter
+	// volumePlugin reconciles the state of the volume plugin before the Agent
+	// starts. The reconciliation is skipped if it's nil.
+	volumePlugin volumePlugin
 }
 
 type TerminalError struct {
@@ -107,6 +112,10 @@ func New() (*Engine, error) {
 		credentialsProxyRoute:    credentialsProxyRoute,
 		ipv6RouterAdvertisements: ipv6RouterAdvertisements,
 		nvidiaGPUManager:         gpu.NewNvidiaGPUManager(),
+		upgrades:                 newUpgradeTracker(),
+		supervision:              config.AgentSupervisionPolicy(),
+		crashBundles:             newCrashBundleWriter(),
+		volumePlugin:             newVolumePluginReconciler(cmdExec),
 	}, nil
 }
 
@@ -149,6 +158,17 @@ func (e *Engine) PreStart() error {
 		// If directory creation fails, set ECS_EBSTA_SUPPORTED=false in docker/docker.go
 		log.Error("could not create EBS mount directory", err)
 	}
+	// Reconcile the volume plugin state with the host mount table while no
+	// task can use the plugin
+	if e.volumePlugin != nil {
+		log.Info("pre-start: reconciling volume plugin state")
+		err = e.volumePlugin.Reconcile()
+		if err != nil {
+			// Log error and continue, as the volumes that couldn't be
+			// repaired don't prevent the Agent from running other tasks
+			log.Errorf("could not reconcile volume plugin state: %v", err)
+		}
+	}
 
 	docker, err := getDockerClient()
 	if err != nil {
@@ -173,6 +193,12 @@ func (e *Engine) PreStart() error {
 		log.Info("pre-start: reloading agent")
 		return e.load(docker, e.downloader.LoadCachedAgent)
 
+	// The cached Agent failed verification and must not be loaded,
+	// replace it with a verified Agent.
+	case cache.StatusVerificationFailed:
+		log.Warn("pre-start: cached agent failed verification, downloading agent")
+		return e.downloadAndLoadCache(docker)
+
 	// Agent is cached, respect the already loaded Agent.
 	case cache.StatusCached:
 		if imageLoaded {
@@ -263,27 +289,48 @@ func (e *Engine) load(docker dockerClient, agentLoader func() (io.ReadCloser, er
 	return e.downloader.RecordCachedAgent()
 }
 
-// StartSupervised starts the ECS Agent and ensures it stays running, except for terminal errors (indicated by an agent exit code of 5)
+// StartSupervised starts the ECS Agent and ensures it stays running, except for terminal errors (indicated by an agent exit code of 5
+// or one of the terminal exit codes of the supervision policy) and restarts beyond the limit of the supervision policy.
+// An upgraded Agent is on probation until it has been healthy for the probation window, and the upgrade is rolled back if
+// the Agent fails during probation. A crash bundle is written each time the Agent exits unexpectedly.
 func (e *Engine) StartSupervised() error {
 	docker, err := getDockerClient()
 	if err != nil {
 		return dockerError(err)
 	}
+	e.upgrades.load()
 	agentExitCode := -1
-	retryBackoff := backoff.NewBackoff(serviceStartMinRetryTime, serviceStartMaxRetryTime,
-		serviceStartRetryJitter, serviceStartRetryMultiplier, serviceStartMaxRetries)
+	retryBackoff := backoff.NewBackoff(e.supervision.MinBackoff, e.supervision.MaxBackoff,
+		serviceStartRetryJitter, e.supervision.BackoffMultiplier, serviceStartMaxRetries)
+	var restarts []time.Time
 	for {
 		err := docker.RemoveExistingAgentContainer()
 		if err != nil {
 			return engineError("could not remove existing Agent container", err)
 		}
 
+		var probation *probationWatch
+		if e.upgrades.inProbation() {
+			probation = e.upgrades.watch(docker)
+		}
 		log.Info("Starting Amazon Elastic Container Service Agent")
 		agentExitCode, err = docker.StartAgent()
+		healthCheckFailure := ""
+		if probation != nil {
+			healthCheckFailure = probation.stop()
+		}
 		if err != nil {
 			return engineError("could not start Agent", err)
 		}
 		log.Infof("Agent exited with code %d", agentExitCode)
+		if isAgentCrash(agentExitCode) {
+			e.writeCrashBundle(docker, agentExitCode)
+		}
+
+		if probation != nil && e.checkProbation(docker, agentExitCode, healthCheckFailure) {
+			// continuing here because the previous agent can be started right away
+			continue
+		}
 
 		switch agentExitCode {
 		case upgradeAgentExitCode:
@@ -307,15 +354,131 @@ func (e *Engine) StartSupervised() error {
 		case terminalSuccessAgentExitCode:
 			return nil
 		}
+		if e.supervision.IsTerminalExitCode(agentExitCode) {
+			return &TerminalError{
+				err:      "agent exited with exit code configured as terminal",
+				exitCode: TerminalFailureAgentExitCode,
+			}
+		}
+		restarts = e.recordRestart(restarts)
+		if e.supervision.MaxRestarts > 0 && len(restarts) > e.supervision.MaxRestarts {
+			return &TerminalError{
+				err: fmt.Sprintf("agent exceeded %d restarts within %s, last exit code %d",
+					e.supervision.MaxRestarts, e.supervision.RestartWindow, agentExitCode),
+				exitCode: TerminalFailureAgentExitCode,
+			}
+		}
 		d := retryBackoff.Duration()
 		log.Warnf("ECS Agent failed to start, retrying in %s", d)
 		time.Sleep(d)
 	}
 }
 
+// recordRestart adds a restart of the Agent to the restarts within the restart
+// window of the supervision policy, and drops the restarts that fell out of it
+func (e *Engine) recordRestart(restarts []time.Time) []time.Time {
+	now := time.Now()
+	kept := restarts[:0]
+	for _, restart := range restarts {
+		if now.Sub(restart) < e.supervision.RestartWindow {
+			kept = append(kept, restart)
+		}
+	}
+	return append(kept, now)
+}
+
+// isAgentCrash returns whether the Agent exited unexpectedly, that is for any
+// reason other than a terminal success or a request to be upgraded. Panics,
+// signals, errors and terminal failures are all crashes.
+func isAgentCrash(agentExitCode int) bool {
+	return agentExitCode != terminalSuccessAgentExitCode && agentExitCode != upgradeAgentExitCode
+}
+
+// writeCrashBundle writes a crash bundle for the Agent that crashed.
+// Failures are logged, as they must not prevent restarts.
+func (e *Engine) writeCrashBundle(docker dockerClient, agentExitCode int) {
+	if e.crashBundles == nil {
+		return
+	}
+	bundle, err := e.crashBundles.Write(docker, agentExitCode)
+	if err != nil {
+		log.Warnf("Could not write agent crash bundle: %v", err)
+		return
+	}
+	log.Infof("Wrote agent crash bundle to %s", bundle)
+}
+
+// upgradeAgent loads the desired Agent image and puts the upgraded Agent on
+// probation. The Agent image is saved first so that the upgrade can be rolled
+// back, unless the Agent is already on probation, in which case the image
+// saved before the upgrade being probated is kept.
 func (e *Engine) upgradeAgent(docker dockerClient) error {
+	previousImageID, inProbation := e.upgrades.probationPreviousImageID()
+	if !inProbation {
+		var err error
+		previousImageID, err = docker.AgentImageID()
+		if err != nil {
+			return engineError("could not inspect Amazon Elastic Container Service Agent image", err)
+		}
+		log.Info("Saving Amazon Elastic Container Service Agent image for rollback")
+		err = docker.SaveAgentImage()
+		if err != nil {
+			return engineError("could not save Amazon Elastic Container Service Agent image", err)
+		}
+	}
+
 	log.Info("Loading new desired Amazon Elastic Container Service Agent into Docker")
-	return e.load(docker, e.downloader.LoadDesiredAgent)
+	err := e.load(docker, e.downloader.LoadDesiredAgent)
+	if err != nil {
+		return err
+	}
+	imageID, err := docker.AgentImageID()
+	if err != nil {
+		return engineError("could not inspect Amazon Elastic Container Service Agent image", err)
+	}
+	if e.upgrades.rolledBack(imageID) {
+		log.Warnf("Upgrade to agent image %s was rolled back before, restoring agent image %s", imageID, previousImageID)
+		err = docker.RestorePreviousAgentImage()
+		if err != nil {
+			return engineError("could not restore previous Amazon Elastic Container Service Agent image", err)
+		}
+		return fmt.Errorf("upgrade to agent image %s was rolled back before", imageID)
+	}
+	if imageID == previousImageID {
+		return nil
+	}
+	log.Infof("Upgraded agent image from %s to %s, starting probation", previousImageID, imageID)
+	return e.upgrades.start(previousImageID, imageID)
+}
+
+// checkProbation rolls back the upgrade of the Agent on probation if the Agent
+// failed its health checks or exited for any reason other than a terminal
+// success or an upgrade, terminal exit codes included, and returns whether it did
+func (e *Engine) checkProbation(docker dockerClient, agentExitCode int, healthCheckFailure string) bool {
+	reason := healthCheckFailure
+	if reason == "" {
+		switch agentExitCode {
+		case terminalSuccessAgentExitCode, upgradeAgentExitCode:
+			return false
+		}
+		if !e.upgrades.inProbation() {
+			// the upgrade was committed while the Agent was running
+			return false
+		}
+		reason = fmt.Sprintf("agent exited with exit code %d during probation", agentExitCode)
+	}
+
+	log.Warnf("Rolling back Amazon Elastic Container Service Agent upgrade: %s", reason)
+	err := docker.RestorePreviousAgentImage()
+	if err != nil {
+		log.Errorf("Could not restore previous Amazon Elastic Container Service Agent image: %v", err)
+		return false
+	}
+	err = e.upgrades.rollBack(reason)
+	if err != nil {
+		log.Errorf("Could not record agent upgrade state: %v", err)
+	}
+	return true
 }
 
 // PreStop sends commands to Docker to stop the ECS Agent
@@ -23,9 +23,11 @@ import (
 	"io"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/aws/amazon-ecs-agent/ecs-init/apparmor"
 	"github.com/aws/amazon-ecs-agent/ecs-init/cache"
+	"github.com/aws/amazon-ecs-agent/ecs-init/config"
 	"github.com/aws/amazon-ecs-agent/ecs-init/gpu"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -79,6 +81,38 @@ func TestPreStartImageAlreadyCachedAndLoaded(t *testing.T) {
 	}
 }
 
+func TestPreStartVolumePluginReconcileFailure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	defer getDockerClientMock(mockDocker)()
+	mockDownloader := NewMockdownloader(mockCtrl)
+
+	mockDocker.EXPECT().LoadEnvVars().Return(nil)
+	mockDocker.EXPECT().IsAgentImageLoaded().Return(true, nil)
+	mockDownloader.EXPECT().AgentCacheStatus().Return(cache.StatusCached)
+
+	mockLoopbackRouting := NewMockloopbackRouting(mockCtrl)
+	mockLoopbackRouting.EXPECT().Enable().Return(nil)
+	mockIpv6RouterAdvertisements := NewMockipv6RouterAdvertisements(mockCtrl)
+	mockIpv6RouterAdvertisements.EXPECT().Disable().Return(nil)
+	mockRoute := NewMockcredentialsProxyRoute(mockCtrl)
+	mockRoute.EXPECT().Create().Return(nil)
+	// A failed reconciliation doesn't prevent the Agent from starting
+	mockVolumePlugin := NewMockvolumePlugin(mockCtrl)
+	mockVolumePlugin.EXPECT().Reconcile().Return(errors.New("volume vol could not be mounted"))
+
+	engine := &Engine{
+		downloader:               mockDownloader,
+		loopbackRouting:          mockLoopbackRouting,
+		ipv6RouterAdvertisements: mockIpv6RouterAdvertisements,
+		credentialsProxyRoute:    mockRoute,
+		volumePlugin:             mockVolumePlugin,
+	}
+	assert.NoError(t, engine.PreStart())
+}
+
 func TestPreStartReloadNeeded(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -189,6 +223,44 @@ func TestPreStartImageNotCached(t *testing.T) {
 	}
 }
 
+func TestPreStartCachedImageVerificationFailed(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	cachedAgentBuffer := io.NopCloser(&bytes.Buffer{})
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	defer getDockerClientMock(mockDocker)()
+	mockDownloader := NewMockdownloader(mockCtrl)
+
+	mockDocker.EXPECT().LoadEnvVars().Return(nil)
+	// The loaded image is replaced when the cached image fails verification
+	mockDocker.EXPECT().IsAgentImageLoaded().Return(true, nil)
+	mockDownloader.EXPECT().AgentCacheStatus().Return(cache.StatusVerificationFailed)
+	mockDownloader.EXPECT().DownloadAgent()
+	mockDownloader.EXPECT().LoadCachedAgent().Return(cachedAgentBuffer, nil)
+	mockDocker.EXPECT().LoadImage(cachedAgentBuffer)
+	mockDownloader.EXPECT().RecordCachedAgent()
+
+	mockLoopbackRouting := NewMockloopbackRouting(mockCtrl)
+	mockLoopbackRouting.EXPECT().Enable().Return(nil)
+	mockIpv6RouterAdvertisements := NewMockipv6RouterAdvertisements(mockCtrl)
+	mockIpv6RouterAdvertisements.EXPECT().Disable().Return(nil)
+	mockRoute := NewMockcredentialsProxyRoute(mockCtrl)
+	mockRoute.EXPECT().Create().Return(nil)
+
+	engine := &Engine{
+		downloader:               mockDownloader,
+		loopbackRouting:          mockLoopbackRouting,
+		ipv6RouterAdvertisements: mockIpv6RouterAdvertisements,
+		credentialsProxyRoute:    mockRoute,
+	}
+	err := engine.PreStart()
+	if err != nil {
+		t.Errorf("engine pre-start error: %v", err)
+	}
+}
+
 func TestPreStartGPUSetupSuccessful(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -257,7 +329,9 @@ func TestStartSupervisedCannotStart(t *testing.T) {
 	mockDocker.EXPECT().RemoveExistingAgentContainer()
 	mockDocker.EXPECT().StartAgent().Return(0, errors.New("test error"))
 
-	engine := &Engine{}
+	engine := &Engine{
+		upgrades: newTestUpgradeTracker(t),
+	}
 	err := engine.StartSupervised()
 	if err == nil {
 		t.Error("Expected error to be returned but was nil")
@@ -282,7 +356,9 @@ func TestStartSupervisedExitsWhenTerminalFailure(t *testing.T) {
 		mockDocker.EXPECT().StartAgent().Return(TerminalFailureAgentExitCode, nil),
 	)
 
-	engine := &Engine{}
+	engine := &Engine{
+		upgrades: newTestUpgradeTracker(t),
+	}
 	err := engine.StartSupervised()
 
 	if err == nil {
@@ -295,6 +371,120 @@ func TestStartSupervisedExitsWhenTerminalFailure(t *testing.T) {
 	}
 }
 
+func TestStartSupervisedExitsWhenConfiguredTerminalExitCode(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	defer getDockerClientMock(mockDocker)()
+	gomock.InOrder(
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(1, nil),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(3, nil),
+	)
+
+	engine := &Engine{
+		upgrades:    newTestUpgradeTracker(t),
+		supervision: config.SupervisionPolicy{TerminalExitCodes: []int{3}},
+	}
+	err := engine.StartSupervised()
+	var terminalErr *TerminalError
+	assert.ErrorAs(t, err, &terminalErr)
+}
+
+func TestStartSupervisedExitsWhenRestartLimitExceeded(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	defer getDockerClientMock(mockDocker)()
+	gomock.InOrder(
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(1, nil),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(1, nil),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(1, nil),
+	)
+
+	engine := &Engine{
+		upgrades: newTestUpgradeTracker(t),
+		supervision: config.SupervisionPolicy{
+			MaxRestarts:   2,
+			RestartWindow: time.Hour,
+		},
+	}
+	err := engine.StartSupervised()
+	var terminalErr *TerminalError
+	assert.ErrorAs(t, err, &terminalErr)
+	assert.Contains(t, err.Error(), "exceeded 2 restarts")
+}
+
+func TestRecordRestartDropsRestartsOutsideWindow(t *testing.T) {
+	engine := &Engine{
+		supervision: config.SupervisionPolicy{RestartWindow: time.Minute},
+	}
+	restarts := []time.Time{time.Now().Add(-2 * time.Minute), time.Now().Add(-time.Second)}
+	restarts = engine.recordRestart(restarts)
+	assert.Len(t, restarts, 2)
+}
+
+func TestStartSupervisedWritesCrashBundles(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	defer getDockerClientMock(mockDocker)()
+	mockCrashBundles := NewMockcrashBundleWriter(mockCtrl)
+	gomock.InOrder(
+		// The Agent exiting with an error is unexpected
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(1, nil),
+		mockCrashBundles.EXPECT().Write(mockDocker, 1).Return("/bundle-1.tar.gz", nil),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(2, nil),
+		// A failure to write the crash bundle doesn't prevent restarts
+		mockCrashBundles.EXPECT().Write(mockDocker, 2).Return("", errors.New("disk full")),
+		mockDocker.EXPECT().GetContainerLogTail(gomock.Any()),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		// The Agent was killed, for instance by the OOM killer
+		mockDocker.EXPECT().StartAgent().Return(137, nil),
+		mockCrashBundles.EXPECT().Write(mockDocker, 137).Return("/bundle-137.tar.gz", nil),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(TerminalFailureAgentExitCode, nil),
+		mockCrashBundles.EXPECT().Write(mockDocker, TerminalFailureAgentExitCode).Return("/bundle-5.tar.gz", nil),
+	)
+
+	engine := &Engine{
+		upgrades:     newTestUpgradeTracker(t),
+		crashBundles: mockCrashBundles,
+	}
+	err := engine.StartSupervised()
+	var terminalErr *TerminalError
+	assert.ErrorAs(t, err, &terminalErr)
+}
+
+func TestStartSupervisedNoCrashBundleOnExpectedExit(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	defer getDockerClientMock(mockDocker)()
+	mockCrashBundles := NewMockcrashBundleWriter(mockCtrl)
+	mockCrashBundles.EXPECT().Write(gomock.Any(), gomock.Any()).Times(0)
+	gomock.InOrder(
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(terminalSuccessAgentExitCode, nil),
+	)
+
+	engine := &Engine{
+		upgrades:     newTestUpgradeTracker(t),
+		crashBundles: mockCrashBundles,
+	}
+	assert.NoError(t, engine.StartSupervised())
+}
+
 func TestLogContainerFailureAgentExitCodeFailure(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -307,7 +497,9 @@ func TestLogContainerFailureAgentExitCodeFailure(t *testing.T) {
 	mockDocker.EXPECT().RemoveExistingAgentContainer()
 	mockDocker.EXPECT().StartAgent().Return(0, errors.New("test error"))
 
-	engine := &Engine{}
+	engine := &Engine{
+		upgrades: newTestUpgradeTracker(t),
+	}
 	err := engine.StartSupervised()
 	if err == nil {
 		t.Error("Expected error to be returned but was nil")
@@ -331,7 +523,9 @@ func TestStartSupervisedExitsWhenTerminalSuccess(t *testing.T) {
 		mockDocker.EXPECT().StartAgent().Return(terminalSuccessAgentExitCode, nil),
 	)
 
-	engine := &Engine{}
+	engine := &Engine{
+		upgrades: newTestUpgradeTracker(t),
+	}
 	err := engine.StartSupervised()
 	if err != nil {
 		t.Error("Expected error to be nil but was returned")
@@ -349,6 +543,8 @@ func TestStartSupervisedUpgradeOpenFailure(t *testing.T) {
 	gomock.InOrder(
 		mockDocker.EXPECT().RemoveExistingAgentContainer(),
 		mockDocker.EXPECT().StartAgent().Return(upgradeAgentExitCode, nil),
+		mockDocker.EXPECT().AgentImageID().Return(previousImageID, nil),
+		mockDocker.EXPECT().SaveAgentImage(),
 		mockDownloader.EXPECT().LoadDesiredAgent().Return(nil, errors.New("test error")),
 		mockDocker.EXPECT().RemoveExistingAgentContainer(),
 		mockDocker.EXPECT().StartAgent().Return(terminalSuccessAgentExitCode, nil),
@@ -356,6 +552,7 @@ func TestStartSupervisedUpgradeOpenFailure(t *testing.T) {
 
 	engine := &Engine{
 		downloader: mockDownloader,
+		upgrades:   newTestUpgradeTracker(t),
 	}
 	err := engine.StartSupervised()
 	if err != nil {
@@ -374,6 +571,8 @@ func TestStartSupervisedUpgradeLoadFailure(t *testing.T) {
 	gomock.InOrder(
 		mockDocker.EXPECT().RemoveExistingAgentContainer(),
 		mockDocker.EXPECT().StartAgent().Return(upgradeAgentExitCode, nil),
+		mockDocker.EXPECT().AgentImageID().Return(previousImageID, nil),
+		mockDocker.EXPECT().SaveAgentImage(),
 		mockDownloader.EXPECT().LoadDesiredAgent().Return(&os.File{}, nil),
 		mockDocker.EXPECT().LoadImage(gomock.Any()).Return(errors.New("test error")),
 		mockDocker.EXPECT().RemoveExistingAgentContainer(),
@@ -382,6 +581,7 @@ func TestStartSupervisedUpgradeLoadFailure(t *testing.T) {
 
 	engine := &Engine{
 		downloader: mockDownloader,
+		upgrades:   newTestUpgradeTracker(t),
 	}
 	err := engine.StartSupervised()
 	if err != nil {
@@ -400,20 +600,29 @@ func TestStartSupervisedUpgrade(t *testing.T) {
 	gomock.InOrder(
 		mockDocker.EXPECT().RemoveExistingAgentContainer(),
 		mockDocker.EXPECT().StartAgent().Return(upgradeAgentExitCode, nil),
+		mockDocker.EXPECT().AgentImageID().Return(previousImageID, nil),
+		mockDocker.EXPECT().SaveAgentImage(),
 		mockDownloader.EXPECT().LoadDesiredAgent().Return(&os.File{}, nil),
 		mockDocker.EXPECT().LoadImage(gomock.Any()),
 		mockDownloader.EXPECT().RecordCachedAgent(),
+		mockDocker.EXPECT().AgentImageID().Return(upgradedImageID, nil),
 		mockDocker.EXPECT().RemoveExistingAgentContainer(),
 		mockDocker.EXPECT().StartAgent().Return(terminalSuccessAgentExitCode, nil),
 	)
 
+	upgrades := newTestUpgradeTracker(t)
 	engine := &Engine{
 		downloader: mockDownloader,
+		upgrades:   upgrades,
 	}
 	err := engine.StartSupervised()
 	if err != nil {
 		t.Error("Expected error to be nil but was returned")
 	}
+	state := readUpgradeState(t, upgrades)
+	assert.Equal(t, upgradeStatusProbation, state.Status)
+	assert.Equal(t, previousImageID, state.PreviousImageID)
+	assert.Equal(t, upgradedImageID, state.ImageID)
 }
 
 func TestPreStop(t *testing.T) {
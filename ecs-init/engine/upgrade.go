@@ -0,0 +1,264 @@
+// Copyright 2015-2016 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/ecs-init/config"
+
+	log "github.com/cihub/seelog"
+)
+
+const (
+	// upgradeProbationWindow is how long an upgraded Agent must stay healthy
+	// before the upgrade is committed
+	upgradeProbationWindow = 10 * time.Minute
+	// agentHealthCheckFailureThreshold is the number of consecutive failed
+	// health checks during probation after which the upgrade is rolled back
+	agentHealthCheckFailureThreshold = 8
+	agentHealthCheckInterval         = 15 * time.Second
+	agentHealthCheckTimeout          = 5 * time.Second
+	agentHealthCheckURL              = "http://localhost:51678/v1/metadata"
+	upgradeStateFilePermission       = 0600
+)
+
+type upgradeStatus string
+
+const (
+	// upgradeStatusProbation indicates that the upgraded Agent is watched and
+	// will be rolled back if it fails
+	upgradeStatusProbation upgradeStatus = "PROBATION"
+	// upgradeStatusCommitted indicates that the upgraded Agent stayed healthy
+	// during probation
+	upgradeStatusCommitted upgradeStatus = "COMMITTED"
+	// upgradeStatusRolledBack indicates that the upgraded Agent failed during
+	// probation and the previous Agent image was restored
+	upgradeStatusRolledBack upgradeStatus = "ROLLED_BACK"
+)
+
+// upgradeState is the state of the last Agent upgrade. It's persisted so that
+// probation and rollbacks survive restarts of ecs-init and reboots.
+type upgradeState struct {
+	Status          upgradeStatus `json:"status"`
+	PreviousImageID string        `json:"previousImageID"`
+	ImageID         string        `json:"imageID"`
+	StartedAt       time.Time     `json:"startedAt"`
+	CompletedAt     *time.Time    `json:"completedAt,omitempty"`
+	RollbackReason  string        `json:"rollbackReason,omitempty"`
+}
+
+// upgradeTracker tracks the probation of upgraded Agents
+type upgradeTracker struct {
+	stateFile           string
+	probationWindow     time.Duration
+	healthCheckInterval time.Duration
+	healthCheck         func() error
+
+	lock  sync.Mutex
+	state *upgradeState
+	// probationStart is when the current probation started. A restart of
+	// ecs-init restarts the probation window.
+	probationStart time.Time
+}
+
+func newUpgradeTracker() *upgradeTracker {
+	return &upgradeTracker{
+		stateFile:           config.AgentUpgradeStateFile(),
+		probationWindow:     upgradeProbationWindow,
+		healthCheckInterval: agentHealthCheckInterval,
+		healthCheck:         agentHealthCheck,
+	}
+}
+
+// agentHealthCheck checks that the Agent serves its metadata
+func agentHealthCheck() error {
+	client := http.Client{Timeout: agentHealthCheckTimeout}
+	resp, err := client.Get(agentHealthCheckURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, agentHealthCheckURL)
+	}
+	return nil
+}
+
+// load reads the state of the last Agent upgrade from disk
+func (t *upgradeTracker) load() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.state = nil
+	data, err := os.ReadFile(t.stateFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("Could not read agent upgrade state: %v", err)
+		}
+		return
+	}
+	state := &upgradeState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		log.Warnf("Could not parse agent upgrade state: %v", err)
+		return
+	}
+	t.state = state
+	if state.Status == upgradeStatusProbation {
+		log.Infof("Resuming probation of agent upgrade from image %s to %s", state.PreviousImageID, state.ImageID)
+		t.probationStart = time.Now()
+	}
+}
+
+func (t *upgradeTracker) save() error {
+	data, err := json.Marshal(t.state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(t.stateFile, data, upgradeStateFilePermission)
+}
+
+// start records the upgrade of the Agent image and starts its probation
+func (t *upgradeTracker) start(previousImageID, imageID string) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := time.Now()
+	t.state = &upgradeState{
+		Status:          upgradeStatusProbation,
+		PreviousImageID: previousImageID,
+		ImageID:         imageID,
+		StartedAt:       now,
+	}
+	t.probationStart = now
+	return t.save()
+}
+
+// inProbation returns whether the Agent is an upgraded Agent in probation
+func (t *upgradeTracker) inProbation() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.state != nil && t.state.Status == upgradeStatusProbation
+}
+
+// probationPreviousImageID returns the ID of the Agent image replaced by the
+// upgrade on probation, and whether an upgrade is on probation
+func (t *upgradeTracker) probationPreviousImageID() (string, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.state == nil || t.state.Status != upgradeStatusProbation {
+		return "", false
+	}
+	return t.state.PreviousImageID, true
+}
+
+// rolledBack returns whether an upgrade to the image was rolled back
+func (t *upgradeTracker) rolledBack(imageID string) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.state != nil && t.state.Status == upgradeStatusRolledBack && t.state.ImageID == imageID
+}
+
+// commit ends the probation of the upgraded Agent if the probation window has
+// elapsed, and returns whether it did
+func (t *upgradeTracker) commit() (bool, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.state == nil || t.state.Status != upgradeStatusProbation || time.Since(t.probationStart) < t.probationWindow {
+		return false, nil
+	}
+	now := time.Now()
+	t.state.Status = upgradeStatusCommitted
+	t.state.CompletedAt = &now
+	return true, t.save()
+}
+
+// rollBack records that the upgrade was rolled back and why
+func (t *upgradeTracker) rollBack(reason string) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := time.Now()
+	t.state.Status = upgradeStatusRolledBack
+	t.state.CompletedAt = &now
+	t.state.RollbackReason = reason
+	return t.save()
+}
+
+// probationWatch health checks the upgraded Agent while it runs
+type probationWatch struct {
+	done    chan struct{}
+	stopped chan struct{}
+	failure string
+}
+
+// watch health checks the Agent until stop is called. The upgrade is
+// committed once the probation window has elapsed and the Agent is healthy.
+// The Agent is stopped if it fails agentHealthCheckFailureThreshold
+// consecutive health checks.
+func (t *upgradeTracker) watch(docker dockerClient) *probationWatch {
+	w := &probationWatch{
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go func() {
+		defer close(w.stopped)
+		ticker := time.NewTicker(t.healthCheckInterval)
+		defer ticker.Stop()
+		failures := 0
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-ticker.C:
+			}
+
+			err := t.healthCheck()
+			if err != nil {
+				failures++
+				log.Warnf("Upgraded agent failed health check (%d/%d): %v",
+					failures, agentHealthCheckFailureThreshold, err)
+				if failures >= agentHealthCheckFailureThreshold {
+					w.failure = fmt.Sprintf("agent failed %d consecutive health checks: %v", failures, err)
+					if err := docker.StopAgent(); err != nil {
+						log.Errorf("Could not stop unhealthy upgraded agent: %v", err)
+					}
+					return
+				}
+				continue
+			}
+			failures = 0
+
+			committed, err := t.commit()
+			if err != nil {
+				log.Errorf("Could not record agent upgrade state: %v", err)
+			}
+			if committed {
+				log.Info("Upgraded agent completed probation, committing upgrade")
+				return
+			}
+		}
+	}()
+	return w
+}
+
+// stop stops watching the Agent and returns why the Agent was stopped, if it
+// was stopped for failing health checks
+func (w *probationWatch) stop() string {
+	close(w.done)
+	<-w.stopped
+	return w.failure
+}
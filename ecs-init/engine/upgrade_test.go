@@ -0,0 +1,282 @@
+//go:build test
+// +build test
+
+// Copyright 2015 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	previousImageID = "sha256:previous"
+	upgradedImageID = "sha256:upgraded"
+)
+
+// newTestUpgradeTracker returns an upgrade tracker persisting its state in a
+// temporary directory, whose health checks succeed
+func newTestUpgradeTracker(t *testing.T) *upgradeTracker {
+	return &upgradeTracker{
+		stateFile:           filepath.Join(t.TempDir(), "upgrade-state.json"),
+		probationWindow:     time.Hour,
+		healthCheckInterval: time.Millisecond,
+		healthCheck:         func() error { return nil },
+	}
+}
+
+func readUpgradeState(t *testing.T, upgrades *upgradeTracker) upgradeState {
+	data, err := os.ReadFile(upgrades.stateFile)
+	require.NoError(t, err)
+	var state upgradeState
+	require.NoError(t, json.Unmarshal(data, &state))
+	return state
+}
+
+// expectUpgrade sets up the expectations of an upgrade of the Agent
+func expectUpgrade(mockDocker *MockdockerClient, mockDownloader *Mockdownloader) []*gomock.Call {
+	return []*gomock.Call{
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(upgradeAgentExitCode, nil),
+		mockDocker.EXPECT().AgentImageID().Return(previousImageID, nil),
+		mockDocker.EXPECT().SaveAgentImage(),
+		mockDownloader.EXPECT().LoadDesiredAgent().Return(&os.File{}, nil),
+		mockDocker.EXPECT().LoadImage(gomock.Any()),
+		mockDownloader.EXPECT().RecordCachedAgent(),
+		mockDocker.EXPECT().AgentImageID().Return(upgradedImageID, nil),
+	}
+}
+
+func TestStartSupervisedUpgradeRollbackOnExit(t *testing.T) {
+	testCases := []struct {
+		name     string
+		exitCode int
+	}{
+		{"unexpected exit", 1},
+		{"terminal failure", TerminalFailureAgentExitCode},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			mockDocker := NewMockdockerClient(mockCtrl)
+			defer getDockerClientMock(mockDocker)()
+			mockDownloader := NewMockdownloader(mockCtrl)
+
+			calls := expectUpgrade(mockDocker, mockDownloader)
+			calls = append(calls,
+				mockDocker.EXPECT().RemoveExistingAgentContainer(),
+				mockDocker.EXPECT().StartAgent().Return(tc.exitCode, nil),
+				mockDocker.EXPECT().RestorePreviousAgentImage(),
+				// The previous agent is started right away
+				mockDocker.EXPECT().RemoveExistingAgentContainer(),
+				mockDocker.EXPECT().StartAgent().Return(terminalSuccessAgentExitCode, nil))
+			gomock.InOrder(calls...)
+
+			upgrades := newTestUpgradeTracker(t)
+			engine := &Engine{
+				downloader: mockDownloader,
+				upgrades:   upgrades,
+			}
+			assert.NoError(t, engine.StartSupervised())
+
+			state := readUpgradeState(t, upgrades)
+			assert.Equal(t, upgradeStatusRolledBack, state.Status)
+			assert.Contains(t, state.RollbackReason, fmt.Sprintf("exit code %d during probation", tc.exitCode))
+			assert.NotNil(t, state.CompletedAt)
+		})
+	}
+}
+
+func TestStartSupervisedUpgradeRollbackOnHealthCheckFailures(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	defer getDockerClientMock(mockDocker)()
+	mockDownloader := NewMockdownloader(mockCtrl)
+
+	agentStopped := make(chan struct{})
+	calls := expectUpgrade(mockDocker, mockDownloader)
+	calls = append(calls,
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().DoAndReturn(func() (int, error) {
+			// The agent runs until it's stopped for failing its health checks
+			<-agentStopped
+			return terminalSuccessAgentExitCode, nil
+		}),
+		mockDocker.EXPECT().RestorePreviousAgentImage(),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(terminalSuccessAgentExitCode, nil))
+	gomock.InOrder(calls...)
+	mockDocker.EXPECT().StopAgent().DoAndReturn(func() error {
+		close(agentStopped)
+		return nil
+	})
+
+	upgrades := newTestUpgradeTracker(t)
+	upgrades.healthCheck = func() error { return errors.New("connection refused") }
+	engine := &Engine{
+		downloader: mockDownloader,
+		upgrades:   upgrades,
+	}
+	assert.NoError(t, engine.StartSupervised())
+
+	state := readUpgradeState(t, upgrades)
+	assert.Equal(t, upgradeStatusRolledBack, state.Status)
+	assert.Contains(t, state.RollbackReason, "consecutive health checks")
+}
+
+func TestStartSupervisedUpgradeCommit(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	defer getDockerClientMock(mockDocker)()
+	mockDownloader := NewMockdownloader(mockCtrl)
+
+	upgrades := newTestUpgradeTracker(t)
+	upgrades.probationWindow = 0
+	calls := expectUpgrade(mockDocker, mockDownloader)
+	calls = append(calls,
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().DoAndReturn(func() (int, error) {
+			// The agent runs until the upgrade is committed
+			for upgrades.inProbation() {
+				time.Sleep(time.Millisecond)
+			}
+			return 1, nil
+		}),
+		// The agent isn't on probation anymore and is restarted after a backoff
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(terminalSuccessAgentExitCode, nil))
+	gomock.InOrder(calls...)
+
+	engine := &Engine{
+		downloader: mockDownloader,
+		upgrades:   upgrades,
+	}
+	assert.NoError(t, engine.StartSupervised())
+
+	state := readUpgradeState(t, upgrades)
+	assert.Equal(t, upgradeStatusCommitted, state.Status)
+	assert.Empty(t, state.RollbackReason)
+}
+
+func TestStartSupervisedUpgradeToRolledBackImage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	defer getDockerClientMock(mockDocker)()
+	mockDownloader := NewMockdownloader(mockCtrl)
+
+	upgrades := newTestUpgradeTracker(t)
+	upgrades.state = &upgradeState{
+		Status:          upgradeStatusRolledBack,
+		PreviousImageID: previousImageID,
+		ImageID:         upgradedImageID,
+		RollbackReason:  "agent failed 8 consecutive health checks",
+	}
+	require.NoError(t, upgrades.save())
+
+	calls := expectUpgrade(mockDocker, mockDownloader)
+	calls = append(calls,
+		mockDocker.EXPECT().RestorePreviousAgentImage(),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(terminalSuccessAgentExitCode, nil))
+	gomock.InOrder(calls...)
+
+	engine := &Engine{
+		downloader: mockDownloader,
+		upgrades:   upgrades,
+	}
+	assert.NoError(t, engine.StartSupervised())
+
+	state := readUpgradeState(t, upgrades)
+	assert.Equal(t, upgradeStatusRolledBack, state.Status)
+	assert.Equal(t, "agent failed 8 consecutive health checks", state.RollbackReason)
+}
+
+func TestStartSupervisedUpgradeDuringProbation(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockDocker := NewMockdockerClient(mockCtrl)
+	defer getDockerClientMock(mockDocker)()
+	mockDownloader := NewMockdownloader(mockCtrl)
+
+	upgrades := newTestUpgradeTracker(t)
+	require.NoError(t, upgrades.start(previousImageID, upgradedImageID))
+
+	// The image saved before the upgrade on probation is kept for rollback
+	gomock.InOrder(
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(upgradeAgentExitCode, nil),
+		mockDownloader.EXPECT().LoadDesiredAgent().Return(&os.File{}, nil),
+		mockDocker.EXPECT().LoadImage(gomock.Any()),
+		mockDownloader.EXPECT().RecordCachedAgent(),
+		mockDocker.EXPECT().AgentImageID().Return("sha256:latest", nil),
+		mockDocker.EXPECT().RemoveExistingAgentContainer(),
+		mockDocker.EXPECT().StartAgent().Return(terminalSuccessAgentExitCode, nil),
+	)
+
+	engine := &Engine{
+		downloader: mockDownloader,
+		upgrades:   upgrades,
+	}
+	assert.NoError(t, engine.StartSupervised())
+
+	state := readUpgradeState(t, upgrades)
+	assert.Equal(t, upgradeStatusProbation, state.Status)
+	assert.Equal(t, previousImageID, state.PreviousImageID)
+	assert.Equal(t, "sha256:latest", state.ImageID)
+}
+
+func TestUpgradeTrackerLoad(t *testing.T) {
+	upgrades := newTestUpgradeTracker(t)
+	require.NoError(t, upgrades.start(previousImageID, upgradedImageID))
+
+	// The probation survives a restart
+	restarted := newTestUpgradeTracker(t)
+	restarted.stateFile = upgrades.stateFile
+	restarted.load()
+	assert.True(t, restarted.inProbation())
+
+	require.NoError(t, restarted.rollBack("test reason"))
+	restarted.load()
+	assert.False(t, restarted.inProbation())
+	assert.True(t, restarted.rolledBack(upgradedImageID))
+	assert.False(t, restarted.rolledBack(previousImageID))
+}
+
+func TestUpgradeTrackerLoadInvalidState(t *testing.T) {
+	upgrades := newTestUpgradeTracker(t)
+	require.NoError(t, os.WriteFile(upgrades.stateFile, []byte("invalid"), upgradeStateFilePermission))
+
+	upgrades.load()
+	assert.False(t, upgrades.inProbation())
+	assert.Nil(t, upgrades.state)
+}
@@ -0,0 +1,71 @@
+// Copyright 2015-2025 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/ecs-init/config"
+	"github.com/aws/amazon-ecs-agent/ecs-init/exec"
+
+	log "github.com/cihub/seelog"
+)
+
+const (
+	// volumePluginReconcileFlag makes the volume plugin reconcile its state
+	// with the host mount table and exit
+	volumePluginReconcileFlag = "-reconcile"
+	// volumePluginReconcileRepairedExitCode is the exit status of the volume
+	// plugin when it repaired all the discrepancies it found
+	volumePluginReconcileRepairedExitCode = 3
+)
+
+// volumePluginReconciler runs the reconciliation of the Amazon ECS volume
+// plugin
+type volumePluginReconciler struct {
+	cmdExec    exec.Exec
+	executable string
+}
+
+func newVolumePluginReconciler(cmdExec exec.Exec) volumePlugin {
+	return &volumePluginReconciler{
+		cmdExec:    cmdExec,
+		executable: config.VolumePluginExecutable,
+	}
+}
+
+// Reconcile runs the volume plugin once to reconcile its state with the host
+// mount table, and returns an error if the reconciliation failed. Nothing is
+// done if the volume plugin isn't installed.
+func (r *volumePluginReconciler) Reconcile() error {
+	if _, err := os.Stat(r.executable); os.IsNotExist(err) {
+		log.Debugf("Volume plugin %s is not installed, skipping its reconciliation", r.executable)
+		return nil
+	}
+	output, err := r.cmdExec.Command(r.executable, volumePluginReconcileFlag).CombinedOutput()
+	if err == nil {
+		log.Info("Volume plugin state is consistent with the host mount table")
+		return nil
+	}
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == volumePluginReconcileRepairedExitCode {
+		log.Warn("Volume plugin repaired discrepancies between its state and the host mount table, " +
+			"see the volume plugin logs for details")
+		return nil
+	}
+	return fmt.Errorf("volume plugin reconciliation failed: %v: %s", err, strings.TrimSpace(string(output)))
+}
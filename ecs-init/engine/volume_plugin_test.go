@@ -0,0 +1,87 @@
+//go:build test
+// +build test
+
+// Copyright 2015-2025 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-init/exec/sysctl"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exitCodeError is the error of a command that exited with an exit code
+type exitCodeError int
+
+func (e exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+func (e exitCodeError) ExitCode() int {
+	return int(e)
+}
+
+func TestVolumePluginReconcile(t *testing.T) {
+	testCases := []struct {
+		name        string
+		err         error
+		expectError bool
+	}{
+		{"consistent", nil, false},
+		{"repaired", exitCodeError(volumePluginReconcileRepairedExitCode), false},
+		{"failed", exitCodeError(1), true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			executable := filepath.Join(t.TempDir(), "amazon-ecs-volume-plugin")
+			require.NoError(t, os.WriteFile(executable, nil, 0755))
+			mockExec := sysctl.NewMockExec(mockCtrl)
+			mockCmd := sysctl.NewMockCmd(mockCtrl)
+			mockExec.EXPECT().Command(executable, volumePluginReconcileFlag).Return(mockCmd)
+			mockCmd.EXPECT().CombinedOutput().Return([]byte("volume vol could not be mounted\n"), tc.err)
+
+			reconciler := &volumePluginReconciler{cmdExec: mockExec, executable: executable}
+			err := reconciler.Reconcile()
+			if tc.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "volume vol could not be mounted")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestVolumePluginReconcileNotInstalled(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// The volume plugin must not be run
+	mockExec := sysctl.NewMockExec(mockCtrl)
+	reconciler := &volumePluginReconciler{
+		cmdExec:    mockExec,
+		executable: filepath.Join(t.TempDir(), "amazon-ecs-volume-plugin"),
+	}
+	assert.NoError(t, reconciler.Reconcile())
+}
@@ -14,6 +14,7 @@
 package main
 
 import (
+	"flag"
 	"os"
 	"os/user"
 	"strconv"
@@ -25,11 +26,28 @@ import (
 )
 
 func main() {
+	reconcileOnly := flag.Bool("reconcile", false, "Reconcile the plugin state with the host mount table and exit. "+
+		"Exits with 0 if they're consistent, 3 if discrepancies were repaired and 1 otherwise.")
+	flag.Parse()
+
 	plugin := volumes.NewAmazonECSVolumePlugin()
 	logger.Setup()
 	defer seelog.Flush()
 	if err := plugin.LoadState(); err != nil {
-		os.Exit(1)
+		seelog.Flush()
+		os.Exit(volumes.ReconcileExitCodeFailed)
+	}
+	report, err := plugin.Reconcile()
+	if err != nil {
+		seelog.Errorf("Could not reconcile plugin state: %v", err)
+	}
+	if *reconcileOnly {
+		exitCode := volumes.ReconcileExitCodeFailed
+		if report != nil {
+			exitCode = report.ExitCode()
+		}
+		seelog.Flush()
+		os.Exit(exitCode)
 	}
 	handler := volume.NewHandler(plugin)
 	rootUser, _ := user.Lookup("root")
@@ -33,6 +33,13 @@ type VolumeDriver interface {
 	IsMounted(volumeName string) bool
 }
 
+// VolumeCleaner is implemented by volume drivers that keep host resources for
+// a volume while it's not mounted, such as a backing file
+type VolumeCleaner interface {
+	// Cleanup releases the host resources of the volume once it's removed.
+	Cleanup(volumeName string, volume *types.Volume) error
+}
+
 // CreateRequest holds fields necessary for creating a volume
 type CreateRequest struct {
 	Name    string
@@ -37,13 +37,23 @@ const (
 // ECSVolumeDriver holds mount helper and methods for different Volume Mounts
 type ECSVolumeDriver struct {
 	volumeMounts map[string]*MountHelper
-	lock         sync.RWMutex
+	// mountHelper returns the mount helper of a volume from its name and
+	// options. It returns an error if the options are invalid.
+	mountHelper func(name string, options map[string]string) (*MountHelper, error)
+	lock        sync.RWMutex
 }
 
 // NewECSVolumeDriver initializes fields for volume mounts
 func NewECSVolumeDriver() *ECSVolumeDriver {
+	return newMountVolumeDriver(func(name string, options map[string]string) (*MountHelper, error) {
+		return setOptions(options), nil
+	})
+}
+
+func newMountVolumeDriver(mountHelper func(string, map[string]string) (*MountHelper, error)) *ECSVolumeDriver {
 	return &ECSVolumeDriver{
 		volumeMounts: make(map[string]*MountHelper),
+		mountHelper:  mountHelper,
 	}
 }
 
@@ -55,7 +65,15 @@ func (e *ECSVolumeDriver) Setup(name string, v *types.Volume) {
 	if _, ok := e.volumeMounts[name]; ok {
 		seelog.Warnf("Volume %s mount already exists", name)
 	}
-	mnt := setOptions(v.Options)
+	mnt, err := e.mountHelper(name, v.Options)
+	if err != nil {
+		// The options were valid when the volume was mounted, keep what
+		// can be used to unmount it
+		seelog.Warnf("Volume %s has invalid options: %v", name, err)
+	}
+	if mnt == nil {
+		mnt = &MountHelper{}
+	}
 
 	mnt.Target = v.Path
 	e.volumeMounts[name] = mnt
@@ -66,16 +84,18 @@ func (e *ECSVolumeDriver) Create(r *driver.CreateRequest) error {
 	e.lock.Lock()
 	defer e.lock.Unlock()
 
-	mnt := setOptions(r.Options)
-	mnt.Target = r.Path
-
 	seelog.Infof("Validating create options for volume %s", r.Name)
+	mnt, err := e.mountHelper(r.Name, r.Options)
+	if err != nil {
+		return err
+	}
+	mnt.Target = r.Path
 	if err := mnt.Validate(); err != nil {
 		return err
 	}
 
 	seelog.Infof("Mounting volume %s of type %s at path %s", r.Name, mnt.MountType, mnt.Target)
-	err := mnt.Mount()
+	err = mnt.Mount()
 	if err != nil {
 		return fmt.Errorf("mounting volume failed: %v", err)
 	}
@@ -45,7 +45,10 @@ type AmazonECSVolumePlugin struct {
 func NewAmazonECSVolumePlugin() *AmazonECSVolumePlugin {
 	plugin := &AmazonECSVolumePlugin{
 		volumeDrivers: map[string]driver.VolumeDriver{
-			"efs": NewECSVolumeDriver(),
+			"efs":      NewECSVolumeDriver(),
+			"nfs":      NewNFSVolumeDriver(),
+			"tmpfs":    NewTmpfsVolumeDriver(),
+			"loopback": NewLoopbackVolumeDriver(),
 		},
 		volumes: make(map[string]*types.Volume),
 		state:   NewStateManager(),
@@ -88,11 +91,12 @@ func (a *AmazonECSVolumePlugin) LoadState() error {
 			return fmt.Errorf("could not load plugin state: %v", err)
 		}
 		volume := &types.Volume{
-			Type:      vol.Type,
-			Path:      vol.Path,
-			Options:   vol.Options,
-			CreatedAt: vol.CreatedAt,
-			Mounts:    vol.Mounts,
+			Type:          vol.Type,
+			Path:          vol.Path,
+			Options:       vol.Options,
+			CreatedAt:     vol.CreatedAt,
+			Mounts:        vol.Mounts,
+			MountsTracked: vol.MountsTracked,
 		}
 		a.volumes[volName] = volume
 		voldriver.Setup(volName, volume)
@@ -153,11 +157,12 @@ func (a *AmazonECSVolumePlugin) Create(r *volume.CreateRequest) error {
 	}
 
 	vol := &types.Volume{
-		Type:      driverType,
-		Path:      target,
-		Options:   r.Options,
-		CreatedAt: time.Now().Format(time.RFC3339Nano),
-		Mounts:    map[string]int{},
+		Type:          driverType,
+		Path:          target,
+		Options:       r.Options,
+		CreatedAt:     time.Now().Format(time.RFC3339Nano),
+		Mounts:        map[string]int{},
+		MountsTracked: true,
 	}
 	// record the volume information
 	a.volumes[r.Name] = vol
@@ -359,6 +364,14 @@ func (a *AmazonECSVolumePlugin) Remove(r *volume.RemoveRequest) error {
 		}
 	}
 
+	// release the host resources kept for the volume by its driver
+	if cleaner, ok := volDriver.(driver.VolumeCleaner); ok {
+		if err := cleaner.Cleanup(r.Name, vol); err != nil {
+			seelog.Errorf("Volume %s cleanup failure: %v", r.Name, err)
+			return fmt.Errorf("failed to clean up volume %s: %w", r.Name, err)
+		}
+	}
+
 	// remove the volume information
 	delete(a.volumes, r.Name)
 	// cleanup the volume's host mount path
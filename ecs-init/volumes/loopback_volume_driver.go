@@ -0,0 +1,162 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/driver"
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/types"
+	"github.com/cihub/seelog"
+)
+
+const (
+	// LoopbackImagePathPrefix is the host path where the file system images
+	// backing loopback volumes are stored
+	LoopbackImagePathPrefix = "/var/lib/ecs/volume-images/"
+	loopbackImageSuffix     = ".img"
+	defaultLoopbackFSType   = "ext4"
+)
+
+var loopbackMkfsArgs = map[string][]string{
+	"ext4": {"-q", "-F"},
+	"xfs":  {"-q"},
+}
+
+// LoopbackVolumeDriver mounts scratch volumes backed by a file system image
+// on the host. The size of the image is the quota of the volume. The image is
+// created on the first mount of the volume and kept until the volume is
+// removed, so that the data of the volume survives unmounts. Volumes are
+// created with the options:
+//   - size: the size of the volume, such as 10g
+//   - fstype: the file system of the volume, ext4 or xfs. Defaults to ext4.
+//   - o: additional mount options, such as prjquota
+type LoopbackVolumeDriver struct {
+	*ECSVolumeDriver
+	imageDir string
+}
+
+// NewLoopbackVolumeDriver returns a volume driver mounting loopback volumes
+func NewLoopbackVolumeDriver() *LoopbackVolumeDriver {
+	d := &LoopbackVolumeDriver{imageDir: LoopbackImagePathPrefix}
+	d.ECSVolumeDriver = newMountVolumeDriver(d.mountHelper)
+	return d
+}
+
+func (d *LoopbackVolumeDriver) imagePath(name string) string {
+	return filepath.Join(d.imageDir, name+loopbackImageSuffix)
+}
+
+// loopbackOptions returns the file system and the size of the loopback volume
+func loopbackOptions(name string, options map[string]string) (string, uint64, error) {
+	fsType := options["fstype"]
+	if fsType == "" {
+		fsType = defaultLoopbackFSType
+	}
+	if _, ok := loopbackMkfsArgs[fsType]; !ok {
+		return "", 0, fmt.Errorf("unsupported file system %q for loopback volume %s", fsType, name)
+	}
+	size, ok := options["size"]
+	if !ok {
+		return "", 0, fmt.Errorf("missing size of loopback volume %s", name)
+	}
+	sizeBytes, err := parseSize(size)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid size of loopback volume %s: %v", name, err)
+	}
+	return fsType, sizeBytes, nil
+}
+
+func (d *LoopbackVolumeDriver) mountHelper(name string, options map[string]string) (*MountHelper, error) {
+	fsType, _, err := loopbackOptions(name, options)
+	if err != nil {
+		return nil, err
+	}
+	return &MountHelper{
+		MountType: fsType,
+		Device:    d.imagePath(name),
+		Options:   joinMountOptions("loop", options["o"]),
+	}, nil
+}
+
+// Create creates the file system image of the volume if it doesn't exist yet
+// and mounts it. An image created by a failed Create is removed, while an
+// existing image is kept with the data of the volume.
+func (d *LoopbackVolumeDriver) Create(r *driver.CreateRequest) error {
+	fsType, size, err := loopbackOptions(r.Name, r.Options)
+	if err != nil {
+		return err
+	}
+	path := d.imagePath(r.Name)
+	created, err := d.createImage(path, fsType, size)
+	if err != nil {
+		return fmt.Errorf("creating image of volume %s failed: %v", r.Name, err)
+	}
+	err = d.ECSVolumeDriver.Create(r)
+	if err != nil && created {
+		if removeErr := os.Remove(path); removeErr != nil {
+			seelog.Warnf("Unable to remove image %s of volume %s: %v", path, r.Name, removeErr)
+		}
+	}
+	return err
+}
+
+// createImage creates the file system image if it doesn't exist yet, and
+// returns whether it did
+func (d *LoopbackVolumeDriver) createImage(path, fsType string, size uint64) (bool, error) {
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	}
+	if err := os.MkdirAll(d.imageDir, FilePerm); err != nil {
+		return false, err
+	}
+	image, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, FilePerm)
+	if err != nil {
+		return false, err
+	}
+	// The image is a sparse file, so that only the data written to the volume
+	// uses space on the host
+	err = image.Truncate(int64(size))
+	if closeErr := image.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		seelog.Infof("Creating %s file system in %s", fsType, path)
+		err = runMkfs(fsType, path)
+	}
+	if err != nil {
+		os.Remove(path)
+		return false, err
+	}
+	return true, nil
+}
+
+var runMkfs = runMkfsCommand
+
+func runMkfsCommand(fsType, path string) error {
+	args := append(append([]string{}, loopbackMkfsArgs[fsType]...), path)
+	return runCmd(exec.Command("mkfs."+fsType, args...))
+}
+
+// Cleanup removes the file system image of the volume
+func (d *LoopbackVolumeDriver) Cleanup(name string, v *types.Volume) error {
+	err := os.Remove(d.imagePath(name))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
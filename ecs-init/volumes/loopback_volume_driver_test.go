@@ -0,0 +1,174 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/driver"
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/types"
+	"github.com/docker/go-plugins-helpers/volume"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLoopbackVolumeDriver(t *testing.T) *LoopbackVolumeDriver {
+	d := NewLoopbackVolumeDriver()
+	d.imageDir = filepath.Join(t.TempDir(), "volume-images")
+	return d
+}
+
+func TestLoopbackVolumeDriverCreate(t *testing.T) {
+	var mkfsCalls [][]string
+	runMkfs = func(fsType, path string) error {
+		mkfsCalls = append(mkfsCalls, []string{fsType, path})
+		return nil
+	}
+	var mountArgs []string
+	runMount = func(args []string) error {
+		mountArgs = args
+		return nil
+	}
+	runUnmount = func(string, string) error {
+		return nil
+	}
+	defer func() {
+		runMkfs = runMkfsCommand
+		runMount = runMountCommand
+		runUnmount = runUnmountCommand
+	}()
+
+	d := newTestLoopbackVolumeDriver(t)
+	image := filepath.Join(d.imageDir, "vol.img")
+	req := &driver.CreateRequest{
+		Name:    "vol",
+		Path:    "/mnt/vol",
+		Options: map[string]string{"type": "loopback", "size": "1g", "fstype": "xfs", "o": "prjquota"},
+	}
+	require.NoError(t, d.Create(req))
+	assert.Equal(t, [][]string{{"xfs", image}}, mkfsCalls)
+	assert.Equal(t, []string{"-t", "xfs", "-o", "loop,prjquota", image, "/mnt/vol"}, mountArgs)
+	info, err := os.Stat(image)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1<<30, info.Size())
+
+	// The image and its data are kept when the volume is mounted again
+	require.NoError(t, d.Remove(&driver.RemoveRequest{Name: "vol"}))
+	require.NoError(t, d.Create(req))
+	assert.Len(t, mkfsCalls, 1)
+
+	require.NoError(t, d.Cleanup("vol", &types.Volume{}))
+	assert.NoFileExists(t, image)
+	assert.NoError(t, d.Cleanup("vol", &types.Volume{}), "Expect no error when the image is already removed")
+}
+
+func TestLoopbackVolumeDriverCreateMkfsFailure(t *testing.T) {
+	runMkfs = func(fsType, path string) error {
+		return errors.New("mkfs failed")
+	}
+	runMount = func([]string) error {
+		t.Fatal("Expect no mount when the file system can't be created")
+		return nil
+	}
+	defer func() {
+		runMkfs = runMkfsCommand
+		runMount = runMountCommand
+	}()
+
+	d := newTestLoopbackVolumeDriver(t)
+	assert.Error(t, d.Create(&driver.CreateRequest{
+		Name:    "vol",
+		Path:    "/mnt/vol",
+		Options: map[string]string{"type": "loopback", "size": "10m"},
+	}))
+	assert.NoFileExists(t, filepath.Join(d.imageDir, "vol.img"))
+	assert.False(t, d.IsMounted("vol"))
+}
+
+func TestLoopbackVolumeDriverCreateMountFailure(t *testing.T) {
+	runMkfs = func(fsType, path string) error {
+		return nil
+	}
+	runMount = func([]string) error {
+		return errors.New("mount failed")
+	}
+	defer func() {
+		runMkfs = runMkfsCommand
+		runMount = runMountCommand
+	}()
+
+	d := newTestLoopbackVolumeDriver(t)
+	req := &driver.CreateRequest{
+		Name:    "vol",
+		Path:    "/mnt/vol",
+		Options: map[string]string{"type": "loopback", "size": "10m"},
+	}
+	image := filepath.Join(d.imageDir, "vol.img")
+	assert.Error(t, d.Create(req))
+	assert.NoFileExists(t, image, "Expect the image created for the volume to be removed")
+	assert.False(t, d.IsMounted("vol"))
+
+	// An existing image holds the data of the volume and is kept
+	require.NoError(t, os.WriteFile(image, []byte("data"), FilePerm))
+	assert.Error(t, d.Create(req))
+	assert.FileExists(t, image)
+}
+
+func TestLoopbackVolumeDriverCreateInvalidOptions(t *testing.T) {
+	d := newTestLoopbackVolumeDriver(t)
+	assert.Error(t, d.Create(&driver.CreateRequest{
+		Name:    "vol",
+		Path:    "/mnt/vol",
+		Options: map[string]string{"type": "loopback"},
+	}), "Expect an error when the size is missing")
+	assert.Error(t, d.Create(&driver.CreateRequest{
+		Name:    "vol",
+		Path:    "/mnt/vol",
+		Options: map[string]string{"type": "loopback", "size": "1g", "fstype": "btrfs"},
+	}), "Expect an error when the file system is unsupported")
+	assert.NoDirExists(t, d.imageDir)
+}
+
+func TestVolumeRemoveCleansUpLoopbackImage(t *testing.T) {
+	d := newTestLoopbackVolumeDriver(t)
+	require.NoError(t, os.MkdirAll(d.imageDir, FilePerm))
+	image := filepath.Join(d.imageDir, "vol.img")
+	require.NoError(t, os.WriteFile(image, nil, FilePerm))
+
+	plugin := &AmazonECSVolumePlugin{
+		volumeDrivers: map[string]driver.VolumeDriver{
+			"loopback": d,
+		},
+		volumes: map[string]*types.Volume{
+			"vol": {Type: "loopback", Path: VolumeMountPathPrefix + "vol"},
+		},
+		state: NewStateManager(),
+	}
+	removeMountPath = func(path string) error {
+		return nil
+	}
+	saveStateToDisk = func(b []byte) error {
+		return nil
+	}
+	defer func() {
+		removeMountPath = deleteMountPath
+		saveStateToDisk = saveState
+	}()
+	assert.NoError(t, plugin.Remove(&volume.RemoveRequest{Name: "vol"}))
+	assert.NoFileExists(t, image)
+	assert.Len(t, plugin.volumes, 0)
+}
@@ -0,0 +1,94 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// mountInfoPath is the mount table of the plugin's mount namespace
+var mountInfoPath = "/proc/self/mountinfo"
+
+// hostMount is a mount of the host mount table
+type hostMount struct {
+	MountPoint string
+	FSType     string
+	Source     string
+}
+
+func readMountInfo() ([]hostMount, error) {
+	file, err := os.Open(mountInfoPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseMountInfo(file)
+}
+
+// parseMountInfo parses the mount table in the format of /proc/self/mountinfo,
+// see proc(5)
+func parseMountInfo(r io.Reader) ([]hostMount, error) {
+	var mounts []hostMount
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		// The optional fields are terminated by a single hyphen
+		fields, postFields, ok := strings.Cut(line, " - ")
+		if !ok {
+			return nil, fmt.Errorf("invalid mountinfo line %q", line)
+		}
+		pre := strings.Fields(fields)
+		post := strings.Fields(postFields)
+		if len(pre) < 6 || len(post) < 2 {
+			return nil, fmt.Errorf("invalid mountinfo line %q", line)
+		}
+		mounts = append(mounts, hostMount{
+			MountPoint: unescapeMountInfo(pre[4]),
+			FSType:     post[0],
+			Source:     unescapeMountInfo(post[1]),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return mounts, nil
+}
+
+// unescapeMountInfo replaces the octal escapes of spaces, tabs, newlines and
+// backslashes in mountinfo fields
+func unescapeMountInfo(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+4 <= len(field) {
+			if c, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(field[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,41 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMountInfo(t *testing.T) {
+	mountInfo := `22 1 259:1 / / rw,noatime shared:1 - xfs /dev/nvme0n1p1 rw,attr2,inode64
+98 22 0:50 / /var/lib/ecs/volumes/vol rw,relatime shared:45 - nfs4 127.0.0.1:/ rw,vers=4.1
+99 22 0:51 / /var/lib/ecs/volumes/with\040space rw,relatime - tmpfs tmpfs rw,size=1024k
+`
+	mounts, err := parseMountInfo(strings.NewReader(mountInfo))
+	require.NoError(t, err)
+	assert.Equal(t, []hostMount{
+		{MountPoint: "/", FSType: "xfs", Source: "/dev/nvme0n1p1"},
+		{MountPoint: "/var/lib/ecs/volumes/vol", FSType: "nfs4", Source: "127.0.0.1:/"},
+		{MountPoint: "/var/lib/ecs/volumes/with space", FSType: "tmpfs", Source: "tmpfs"},
+	}, mounts)
+}
+
+func TestParseMountInfoInvalid(t *testing.T) {
+	_, err := parseMountInfo(strings.NewReader("22 1 259:1 / / rw,noatime\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	nfsMountType = "nfs"
+	// nfsVersionOption is the volume option selecting the NFS protocol version
+	nfsVersionOption = "nfsvers"
+)
+
+var supportedNFSVersions = map[string]bool{
+	"3":   true,
+	"4":   true,
+	"4.0": true,
+	"4.1": true,
+	"4.2": true,
+}
+
+// NewNFSVolumeDriver returns a volume driver mounting NFS exports. Volumes are
+// created with the options:
+//   - device: the export to mount, as server:/path
+//   - nfsvers: the NFS protocol version, 3, 4, 4.0, 4.1 or 4.2. The version is
+//     negotiated with the server if it's not set.
+//   - o: additional mount options
+func NewNFSVolumeDriver() *ECSVolumeDriver {
+	return newMountVolumeDriver(nfsMountHelper)
+}
+
+func nfsMountHelper(name string, options map[string]string) (*MountHelper, error) {
+	device := options["device"]
+	if device != "" && !strings.Contains(device, ":/") {
+		return nil, fmt.Errorf("invalid NFS device %q for volume %s, expected server:/path", device, name)
+	}
+	mountOptions := options["o"]
+	if version, ok := options[nfsVersionOption]; ok {
+		if !supportedNFSVersions[version] {
+			return nil, fmt.Errorf("unsupported NFS version %q for volume %s", version, name)
+		}
+		mountOptions = joinMountOptions("vers="+version, mountOptions)
+	}
+	return &MountHelper{
+		MountType: nfsMountType,
+		Device:    device,
+		Options:   mountOptions,
+	}, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/driver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNFSVolumeDriverCreate(t *testing.T) {
+	var cases = []struct {
+		name         string
+		options      map[string]string
+		expectedArgs []string
+	}{
+		{
+			name:         "version negotiated",
+			options:      map[string]string{"type": "nfs", "device": "10.0.0.1:/export"},
+			expectedArgs: []string{"-t", "nfs", "10.0.0.1:/export", "/mnt/vol"},
+		},
+		{
+			name:         "nfs v3",
+			options:      map[string]string{"type": "nfs", "device": "10.0.0.1:/export", "nfsvers": "3", "o": "ro,nolock"},
+			expectedArgs: []string{"-t", "nfs", "-o", "vers=3,ro,nolock", "10.0.0.1:/export", "/mnt/vol"},
+		},
+		{
+			name:         "nfs v4.1",
+			options:      map[string]string{"type": "nfs", "device": "server:/", "nfsvers": "4.1"},
+			expectedArgs: []string{"-t", "nfs", "-o", "vers=4.1", "server:/", "/mnt/vol"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var args []string
+			runMount = func(a []string) error {
+				args = a
+				return nil
+			}
+			defer func() {
+				runMount = runMountCommand
+			}()
+
+			e := NewNFSVolumeDriver()
+			assert.NoError(t, e.Create(&driver.CreateRequest{Name: "vol", Path: "/mnt/vol", Options: c.options}))
+			assert.Equal(t, c.expectedArgs, args)
+			assert.True(t, e.IsMounted("vol"))
+		})
+	}
+}
+
+func TestNFSVolumeDriverCreateInvalidOptions(t *testing.T) {
+	var cases = []struct {
+		name    string
+		options map[string]string
+	}{
+		{"missing device", map[string]string{"type": "nfs"}},
+		{"invalid device", map[string]string{"type": "nfs", "device": "fs-123"}},
+		{"unsupported version", map[string]string{"type": "nfs", "device": "server:/export", "nfsvers": "2"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			runMount = func([]string) error {
+				t.Fatal("Expect no mount with invalid options")
+				return nil
+			}
+			defer func() {
+				runMount = runMountCommand
+			}()
+
+			e := NewNFSVolumeDriver()
+			assert.Error(t, e.Create(&driver.CreateRequest{Name: "vol", Path: "/mnt/vol", Options: c.options}))
+			assert.False(t, e.IsMounted("vol"))
+		})
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/driver"
+	"github.com/cihub/seelog"
+)
+
+const (
+	// ReconcileExitCodeConsistent is the exit status of a reconciliation that
+	// found the plugin state consistent with the host mount table
+	ReconcileExitCodeConsistent = 0
+	// ReconcileExitCodeFailed is the exit status of a reconciliation that
+	// could not run or could not repair all the discrepancies it found
+	ReconcileExitCodeFailed = 1
+	// ReconcileExitCodeRepaired is the exit status of a reconciliation that
+	// repaired all the discrepancies it found
+	ReconcileExitCodeRepaired = 3
+)
+
+// ReconcileReport lists the discrepancies between the plugin state and the
+// host mount table found by a reconciliation, and how they were handled
+type ReconcileReport struct {
+	// Remounted are the volumes in use that were not mounted and were
+	// mounted again
+	Remounted []string
+	// Unmounted are the volumes not in use that were still mounted and were
+	// unmounted
+	Unmounted []string
+	// Skipped are the volumes from older state files that are mounted without
+	// recorded mounts. They may still be in use, so they are left mounted.
+	Skipped []string
+	// OrphansUnmounted are the mount points under the plugin's mount root
+	// that don't belong to any volume and were unmounted
+	OrphansUnmounted []string
+	// Failures are the discrepancies that could not be repaired
+	Failures []string
+}
+
+// Discrepancies returns the number of discrepancies found
+func (r *ReconcileReport) Discrepancies() int {
+	return len(r.Remounted) + len(r.Unmounted) + len(r.OrphansUnmounted) + len(r.Skipped) + len(r.Failures)
+}
+
+// ExitCode returns the exit status reporting the outcome of the
+// reconciliation. Skipped volumes are left as they are, so they don't count as
+// repairs.
+func (r *ReconcileReport) ExitCode() int {
+	switch {
+	case len(r.Failures) > 0:
+		return ReconcileExitCodeFailed
+	case len(r.Remounted)+len(r.Unmounted)+len(r.OrphansUnmounted) > 0:
+		return ReconcileExitCodeRepaired
+	default:
+		return ReconcileExitCodeConsistent
+	}
+}
+
+// Reconcile compares the loaded plugin state with the host mount table. It
+// mounts volumes in use that are not mounted, unmounts volumes known not to
+// be in use that are mounted, and unmounts mount points under the plugin's mount root
+// that don't belong to any volume, which are left behind when the plugin
+// crashes between mounting a volume and saving its state.
+func (a *AmazonECSVolumePlugin) Reconcile() (*ReconcileReport, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	mounts, err := readMountInfo()
+	if err != nil {
+		return nil, fmt.Errorf("could not read host mount table: %w", err)
+	}
+	mounted := make(map[string]bool)
+	for _, mount := range mounts {
+		mounted[filepath.Clean(mount.MountPoint)] = true
+	}
+
+	report := &ReconcileReport{}
+	volumePaths := make(map[string]bool)
+	names := make([]string, 0, len(a.volumes))
+	for name := range a.volumes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		vol := a.volumes[name]
+		path := filepath.Clean(vol.Path)
+		volumePaths[path] = true
+		inUse := len(vol.Mounts) > 0
+		if inUse == mounted[path] {
+			continue
+		}
+
+		if !inUse && !vol.MountsTracked {
+			seelog.Warnf("Volume %s is mounted at %s but its mounts were not tracked by the plugin version "+
+				"that created it, leaving it mounted", name, vol.Path)
+			report.Skipped = append(report.Skipped, name)
+			continue
+		}
+
+		volDriver, err := a.getVolumeDriver(vol.Type)
+		if err != nil {
+			report.fail("volume %s: %v", name, err)
+			continue
+		}
+		if inUse {
+			seelog.Warnf("Volume %s is in use but not mounted at %s, mounting it", name, vol.Path)
+			err := volDriver.Create(&driver.CreateRequest{Name: name, Path: vol.Path, Options: vol.Options})
+			if err != nil {
+				report.fail("volume %s could not be mounted: %v", name, err)
+				continue
+			}
+			report.Remounted = append(report.Remounted, name)
+		} else {
+			seelog.Warnf("Volume %s is not in use but mounted at %s, unmounting it", name, vol.Path)
+			if err := volDriver.Remove(&driver.RemoveRequest{Name: name}); err != nil {
+				report.fail("volume %s could not be unmounted: %v", name, err)
+				continue
+			}
+			report.Unmounted = append(report.Unmounted, name)
+		}
+	}
+
+	mountRoot := filepath.Clean(VolumeMountPathPrefix)
+	for _, mount := range mounts {
+		mountPoint := filepath.Clean(mount.MountPoint)
+		if !strings.HasPrefix(mountPoint, mountRoot+string(filepath.Separator)) ||
+			belongsToVolume(mountPoint, volumePaths) {
+			continue
+		}
+		seelog.Warnf("Mount point %s of %s does not belong to any volume, unmounting it", mountPoint, mount.Source)
+		if err := unmountPath(mountPoint); err != nil {
+			report.fail("orphaned mount point %s could not be unmounted: %v", mountPoint, err)
+			continue
+		}
+		report.OrphansUnmounted = append(report.OrphansUnmounted, mountPoint)
+	}
+
+	if report.Discrepancies() == 0 {
+		seelog.Info("Volume plugin state is consistent with the host mount table")
+	} else {
+		seelog.Warnf("Reconciled volume plugin state with the host mount table: remounted %v, unmounted %v, "+
+			"unmounted orphaned mount points %v, skipped %v, failures %v",
+			report.Remounted, report.Unmounted, report.OrphansUnmounted, report.Skipped, report.Failures)
+	}
+	return report, nil
+}
+
+func (r *ReconcileReport) fail(format string, args ...interface{}) {
+	failure := fmt.Sprintf(format, args...)
+	seelog.Error(failure)
+	r.Failures = append(r.Failures, failure)
+}
+
+// belongsToVolume returns whether the mount point is the path of a volume or
+// is nested under one
+func belongsToVolume(mountPoint string, volumePaths map[string]bool) bool {
+	for path := mountPoint; ; path = filepath.Dir(path) {
+		if volumePaths[path] {
+			return true
+		}
+		if path == filepath.Dir(path) {
+			return false
+		}
+	}
+}
+
+func unmountPath(path string) error {
+	umount, err := lookPath(UnmountBinary)
+	if err != nil {
+		return err
+	}
+	return runUnmount(umount, path)
+}
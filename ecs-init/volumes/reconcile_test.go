@@ -0,0 +1,179 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/driver"
+	mock_driver "github.com/aws/amazon-ecs-agent/ecs-init/volumes/driver/mock"
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setMountInfo replaces the host mount table with the mountinfo lines
+func setMountInfo(t *testing.T, mountInfo string) {
+	path := filepath.Join(t.TempDir(), "mountinfo")
+	require.NoError(t, os.WriteFile(path, []byte(mountInfo), 0600))
+	mountInfoPath = path
+	t.Cleanup(func() {
+		mountInfoPath = "/proc/self/mountinfo"
+	})
+}
+
+func TestReconcileConsistent(t *testing.T) {
+	setMountInfo(t, `22 1 259:1 / / rw - xfs /dev/nvme0n1p1 rw
+98 22 0:50 / /var/lib/ecs/volumes/used rw - nfs4 127.0.0.1:/ rw
+99 98 0:51 / /var/lib/ecs/volumes/used/nested rw - tmpfs tmpfs rw
+`)
+	plugin := &AmazonECSVolumePlugin{
+		volumeDrivers: map[string]driver.VolumeDriver{
+			"efs": NewTestVolumeDriver(),
+		},
+		volumes: map[string]*types.Volume{
+			"used":   {Type: "efs", Path: VolumeMountPathPrefix + "used", Mounts: map[string]int{"id": 1}},
+			"unused": {Type: "efs", Path: VolumeMountPathPrefix + "unused"},
+		},
+		state: NewStateManager(),
+	}
+	report, err := plugin.Reconcile()
+	require.NoError(t, err)
+	assert.Zero(t, report.Discrepancies())
+	assert.Equal(t, ReconcileExitCodeConsistent, report.ExitCode())
+}
+
+func TestReconcileRepairsDiscrepancies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	setMountInfo(t, `22 1 259:1 / / rw - xfs /dev/nvme0n1p1 rw
+98 22 0:50 / /var/lib/ecs/volumes/unused rw - nfs4 127.0.0.1:/ rw
+99 22 0:51 / /var/lib/ecs/volumes/orphan rw - nfs4 127.0.0.1:/ rw
+100 22 0:52 / /var/lib/ecs/other rw - tmpfs tmpfs rw
+`)
+	var unmounted []string
+	lookPath = func(binary string) (string, error) {
+		return "/bin/umount", nil
+	}
+	runUnmount = func(path string, target string) error {
+		unmounted = append(unmounted, target)
+		return nil
+	}
+	defer func() {
+		lookPath = getPath
+		runUnmount = runUnmountCommand
+	}()
+
+	efsDriver := mock_driver.NewMockVolumeDriver(ctrl)
+	usedOptions := map[string]string{"type": "efs", "device": "fs-123"}
+	efsDriver.EXPECT().Create(&driver.CreateRequest{
+		Name:    "used",
+		Path:    VolumeMountPathPrefix + "used",
+		Options: usedOptions,
+	}).Return(nil)
+	efsDriver.EXPECT().Remove(&driver.RemoveRequest{Name: "unused"}).Return(nil)
+
+	plugin := &AmazonECSVolumePlugin{
+		volumeDrivers: map[string]driver.VolumeDriver{
+			"efs": efsDriver,
+		},
+		volumes: map[string]*types.Volume{
+			"used": {
+				Type:    "efs",
+				Path:    VolumeMountPathPrefix + "used",
+				Options: usedOptions,
+				Mounts:  map[string]int{"id": 1},
+			},
+			"unused": {Type: "efs", Path: VolumeMountPathPrefix + "unused", MountsTracked: true},
+		},
+		state: NewStateManager(),
+	}
+	report, err := plugin.Reconcile()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"used"}, report.Remounted)
+	assert.Equal(t, []string{"unused"}, report.Unmounted)
+	assert.Equal(t, []string{VolumeMountPathPrefix + "orphan"}, report.OrphansUnmounted)
+	assert.Equal(t, []string{VolumeMountPathPrefix + "orphan"}, unmounted,
+		"Expect only mount points under the plugin's mount root to be unmounted")
+	assert.Empty(t, report.Failures)
+	assert.Equal(t, ReconcileExitCodeRepaired, report.ExitCode())
+}
+
+func TestReconcileLeavesUntrackedVolumesMounted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	setMountInfo(t, `22 1 259:1 / / rw - xfs /dev/nvme0n1p1 rw
+98 22 0:50 / /var/lib/ecs/volumes/legacy rw - nfs4 127.0.0.1:/ rw
+`)
+	// The driver must not be asked to unmount a volume whose mounts were not tracked
+	efsDriver := mock_driver.NewMockVolumeDriver(ctrl)
+
+	plugin := &AmazonECSVolumePlugin{
+		volumeDrivers: map[string]driver.VolumeDriver{
+			"efs": efsDriver,
+		},
+		volumes: map[string]*types.Volume{
+			"legacy": {Type: "efs", Path: VolumeMountPathPrefix + "legacy"},
+		},
+		state: NewStateManager(),
+	}
+	report, err := plugin.Reconcile()
+	require.NoError(t, err)
+	assert.Empty(t, report.Unmounted)
+	assert.Empty(t, report.OrphansUnmounted)
+	assert.Equal(t, []string{"legacy"}, report.Skipped)
+	assert.Empty(t, report.Failures)
+	assert.Equal(t, ReconcileExitCodeConsistent, report.ExitCode(), "Expect skipped volumes not to count as repairs")
+}
+
+func TestReconcileRemountFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	setMountInfo(t, "22 1 259:1 / / rw - xfs /dev/nvme0n1p1 rw\n")
+	efsDriver := mock_driver.NewMockVolumeDriver(ctrl)
+	efsDriver.EXPECT().Create(gomock.Any()).Return(errors.New("mount failed"))
+
+	plugin := &AmazonECSVolumePlugin{
+		volumeDrivers: map[string]driver.VolumeDriver{
+			"efs": efsDriver,
+		},
+		volumes: map[string]*types.Volume{
+			"used": {Type: "efs", Path: VolumeMountPathPrefix + "used", Mounts: map[string]int{"id": 1}},
+		},
+		state: NewStateManager(),
+	}
+	report, err := plugin.Reconcile()
+	require.NoError(t, err)
+	assert.Empty(t, report.Remounted)
+	require.Len(t, report.Failures, 1)
+	assert.Contains(t, report.Failures[0], "mount failed")
+	assert.Equal(t, ReconcileExitCodeFailed, report.ExitCode())
+}
+
+func TestReconcileMountInfoUnavailable(t *testing.T) {
+	mountInfoPath = filepath.Join(t.TempDir(), "missing")
+	defer func() {
+		mountInfoPath = "/proc/self/mountinfo"
+	}()
+	plugin := NewAmazonECSVolumePlugin()
+	_, err := plugin.Reconcile()
+	assert.Error(t, err)
+}
@@ -49,11 +49,12 @@ type VolumeState struct {
 
 // VolumeInfo contains the information of managed volumes
 type VolumeInfo struct {
-	Type      string            `json:"type,omitempty"`
-	Path      string            `json:"path,omitempty"`
-	Options   map[string]string `json:"options,omitempty"`
-	CreatedAt string            `json:"createdAt,omitempty"`
-	Mounts    map[string]int    `json:"mounts,omitempty"`
+	Type          string            `json:"type,omitempty"`
+	Path          string            `json:"path,omitempty"`
+	Options       map[string]string `json:"options,omitempty"`
+	CreatedAt     string            `json:"createdAt,omitempty"`
+	Mounts        map[string]int    `json:"mounts,omitempty"`
+	MountsTracked bool              `json:"mountsTracked,omitempty"`
 }
 
 // NewStateManager initializes the state manager of volume plugin
@@ -73,11 +74,12 @@ func (s *StateManager) recordVolume(volName string, vol *types.Volume) error {
 	}
 
 	s.VolState.Volumes[volName] = &VolumeInfo{
-		Type:      vol.Type,
-		Path:      vol.Path,
-		Options:   vol.Options,
-		CreatedAt: vol.CreatedAt,
-		Mounts:    mountsCopy,
+		Type:          vol.Type,
+		Path:          vol.Path,
+		Options:       vol.Options,
+		CreatedAt:     vol.CreatedAt,
+		Mounts:        mountsCopy,
+		MountsTracked: vol.MountsTracked,
 	}
 	return s.save()
 }
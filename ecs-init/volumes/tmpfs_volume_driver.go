@@ -0,0 +1,46 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const tmpfsMountType = "tmpfs"
+
+// NewTmpfsVolumeDriver returns a volume driver mounting memory backed tmpfs
+// volumes. Volumes are created with the options:
+//   - size: the size limit of the volume, such as 64m. It's required so that a
+//     volume can't use up the memory of the host.
+//   - o: additional mount options, such as mode=1777
+func NewTmpfsVolumeDriver() *ECSVolumeDriver {
+	return newMountVolumeDriver(tmpfsMountHelper)
+}
+
+func tmpfsMountHelper(name string, options map[string]string) (*MountHelper, error) {
+	size, ok := options["size"]
+	if !ok {
+		return nil, fmt.Errorf("missing size of tmpfs volume %s", name)
+	}
+	sizeBytes, err := parseSize(size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid size of tmpfs volume %s: %v", name, err)
+	}
+	return &MountHelper{
+		MountType: tmpfsMountType,
+		Device:    tmpfsMountType,
+		Options:   joinMountOptions("size="+strconv.FormatUint(sizeBytes, 10), options["o"]),
+	}, nil
+}
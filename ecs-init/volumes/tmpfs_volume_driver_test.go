@@ -0,0 +1,78 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/driver"
+	"github.com/aws/amazon-ecs-agent/ecs-init/volumes/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTmpfsVolumeDriverCreate(t *testing.T) {
+	var args []string
+	runMount = func(a []string) error {
+		args = a
+		return nil
+	}
+	defer func() {
+		runMount = runMountCommand
+	}()
+
+	e := NewTmpfsVolumeDriver()
+	assert.NoError(t, e.Create(&driver.CreateRequest{
+		Name:    "vol",
+		Path:    "/mnt/vol",
+		Options: map[string]string{"type": "tmpfs", "size": "64m", "o": "mode=1777"},
+	}))
+	assert.Equal(t, []string{"-t", "tmpfs", "-o", "size=67108864,mode=1777", "tmpfs", "/mnt/vol"}, args)
+}
+
+func TestTmpfsVolumeDriverCreateInvalidSize(t *testing.T) {
+	e := NewTmpfsVolumeDriver()
+	assert.Error(t, e.Create(&driver.CreateRequest{
+		Name:    "vol",
+		Path:    "/mnt/vol",
+		Options: map[string]string{"type": "tmpfs"},
+	}), "Expect an error when the size limit is missing")
+	assert.Error(t, e.Create(&driver.CreateRequest{
+		Name:    "vol",
+		Path:    "/mnt/vol",
+		Options: map[string]string{"type": "tmpfs", "size": "lots"},
+	}), "Expect an error when the size limit is invalid")
+	assert.False(t, e.IsMounted("vol"))
+}
+
+func TestTmpfsVolumeDriverSetupAndRemove(t *testing.T) {
+	var target string
+	runUnmount = func(path string, t string) error {
+		target = t
+		return nil
+	}
+	defer func() {
+		runUnmount = runUnmountCommand
+	}()
+
+	e := NewTmpfsVolumeDriver()
+	e.Setup("vol", &types.Volume{
+		Type:    "tmpfs",
+		Path:    "/mnt/vol",
+		Options: map[string]string{"type": "tmpfs", "size": "64m"},
+	})
+	assert.True(t, e.IsMounted("vol"))
+	assert.NoError(t, e.Remove(&driver.RemoveRequest{Name: "vol"}))
+	assert.Equal(t, "/mnt/vol", target)
+	assert.False(t, e.IsMounted("vol"))
+}
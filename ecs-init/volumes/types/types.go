@@ -20,6 +20,10 @@ type Volume struct {
 	Options   map[string]string
 	CreatedAt string
 	Mounts    map[string]int
+	// MountsTracked is set on volumes created by a plugin version that tracks
+	// their mounts. Volumes from older state files may be in use even when
+	// Mounts is empty.
+	MountsTracked bool
 }
 
 // Adds a new mount to the volume.
@@ -0,0 +1,59 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var sizeSuffixes = map[byte]uint64{
+	'k': 1 << 10,
+	'm': 1 << 20,
+	'g': 1 << 30,
+	't': 1 << 40,
+}
+
+// parseSize parses a size in bytes with an optional binary k, m, g or t
+// suffix, such as "512m"
+func parseSize(size string) (uint64, error) {
+	value := strings.ToLower(strings.TrimSpace(size))
+	multiplier := uint64(1)
+	if len(value) > 0 {
+		if m, ok := sizeSuffixes[value[len(value)-1]]; ok {
+			multiplier = m
+			value = value[:len(value)-1]
+		}
+	}
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || parsed == 0 {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	if parsed > ^uint64(0)/multiplier {
+		return 0, fmt.Errorf("size %q is too large", size)
+	}
+	return parsed * multiplier, nil
+}
+
+// joinMountOptions joins mount options, ignoring empty ones
+func joinMountOptions(options ...string) string {
+	var nonEmpty []string
+	for _, option := range options {
+		if option != "" {
+			nonEmpty = append(nonEmpty, option)
+		}
+	}
+	return strings.Join(nonEmpty, ",")
+}
@@ -0,0 +1,47 @@
+// Copyright 2019 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package volumes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSize(t *testing.T) {
+	var cases = []struct {
+		size     string
+		expected uint64
+	}{
+		{"1024", 1024},
+		{"4k", 4 << 10},
+		{"512M", 512 << 20},
+		{"10g", 10 << 30},
+		{"1t", 1 << 40},
+	}
+	for _, c := range cases {
+		t.Run(c.size, func(t *testing.T) {
+			size, err := parseSize(c.size)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, size)
+		})
+	}
+
+	for _, size := range []string{"", "0", "-1m", "1.5g", "g", "10x", "99999999999t"} {
+		t.Run(size, func(t *testing.T) {
+			_, err := parseSize(size)
+			assert.Error(t, err)
+		})
+	}
+}